@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/prompt"
+	"github.com/wellywell/gophkeeper/internal/config"
+)
+
+func runDeleteAccount(token string, cli *client.Client, conf *config.ClientConfig, args []string) error {
+	flags := flag.NewFlagSet("delete-account", flag.ContinueOnError)
+	status := flags.Bool("status", false, "Show when the account will be deleted")
+	cancel := flags.Bool("cancel", false, "Cancel scheduled deletion")
+	yes := flags.Bool("yes", false, "Do not ask for confirmation")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || (*status && *cancel) {
+		return errors.New("usage: delete-account [--yes | --status | --cancel]")
+	}
+
+	switch {
+	case *status:
+		deleteAt, err := cli.AccountDeletion(token)
+		if err != nil {
+			return err
+		}
+		if deleteAt == nil {
+			fmt.Println("Deletion is not scheduled")
+			return nil
+		}
+		fmt.Printf("Account will be deleted on %s\n", deleteAt.Local().Format(time.RFC3339))
+		return nil
+	case *cancel:
+		err = cli.CancelAccountDeletion(token)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Deletion cancelled")
+		return nil
+	}
+
+	if !*yes {
+		ok, err := prompt.Confirm("Delete your account with all records, attachments and shares?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+	// пароль подтверждает удаление ещё раз, даже если токен уже есть
+	password := conf.Password
+	if password == "" {
+		password, err = prompt.EnterSecret("Password: ")
+		if err != nil {
+			return err
+		}
+	}
+	deleteAt, err := cli.DeleteAccount(token, password)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Account will be deleted on %s. Run `delete-account --cancel` before that to keep it\n",
+		deleteAt.Local().Format(time.RFC3339))
+	return nil
+}
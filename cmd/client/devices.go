@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/devices"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+const devicesUsage = `usage:
+  devices [list] [--json]          list devices you are signed in on
+  devices sign-out ID              sign out a device
+  devices sign-out-others          sign out every device except this one
+  devices trusted [--json]         list devices allowed to log in
+  devices pending [--json]         list logins from new devices waiting for approval
+  devices approve ID FINGERPRINT   approve a login, FINGERPRINT is shown on the new device
+  devices reject ID                reject a login
+  devices forget ID                sign out a trusted device and require approval for its next login`
+
+func runDevices(token string, pass string, cli *client.Client, args []string) error {
+	flags := flag.NewFlagSet("devices", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print devices as JSON")
+	rest, err := parseInterleaved(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		rest = []string{"list"}
+	}
+
+	switch {
+	case rest[0] == "list" && len(rest) == 1:
+		sessions, err := cli.Sessions(token)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return json.NewEncoder(os.Stdout).Encode(sessions)
+		}
+		for _, s := range sessions {
+			fmt.Println(s.String())
+		}
+		return nil
+	case rest[0] == "sign-out" && len(rest) == 2:
+		id, err := strconv.Atoi(rest[1])
+		if err != nil {
+			return errors.New(devicesUsage)
+		}
+		return cli.DeleteSession(token, id)
+	case rest[0] == "sign-out-others" && len(rest) == 1:
+		n, err := cli.DeleteOtherSessions(token)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Signed out %d other devices\n", n)
+		return nil
+	case (rest[0] == "trusted" || rest[0] == "pending") && len(rest) == 1:
+		status := types.DeviceApproved
+		if rest[0] == "pending" {
+			status = types.DevicePending
+		}
+		all, err := cli.Devices(token)
+		if err != nil {
+			return err
+		}
+		list := []types.Device{}
+		for _, d := range all {
+			if d.Status == status {
+				list = append(list, d)
+			}
+		}
+		if *asJSON {
+			return json.NewEncoder(os.Stdout).Encode(list)
+		}
+		for _, d := range list {
+			fmt.Println(d.String())
+		}
+		return nil
+	case rest[0] == "approve" && len(rest) == 3:
+		id, err := strconv.Atoi(rest[1])
+		if err != nil {
+			return errors.New(devicesUsage)
+		}
+		device, err := devices.Find(token, cli, id)
+		if err != nil {
+			return err
+		}
+		// без сверки отпечатка можно подтвердить вход того, кто подобрал пароль
+		if !strings.EqualFold(types.DeviceFingerprint(device.PublicKey), strings.TrimSpace(rest[2])) {
+			return fmt.Errorf("fingerprint does not match: device %d has %s", id, types.DeviceFingerprint(device.PublicKey))
+		}
+		return devices.Approve(token, pass, cli, *device)
+	case rest[0] == "reject" && len(rest) == 2:
+		id, err := strconv.Atoi(rest[1])
+		if err != nil {
+			return errors.New(devicesUsage)
+		}
+		return cli.RejectDevice(token, id)
+	case rest[0] == "forget" && len(rest) == 2:
+		id, err := strconv.Atoi(rest[1])
+		if err != nil {
+			return errors.New(devicesUsage)
+		}
+		return cli.DeleteDevice(token, id)
+	}
+	return errors.New(devicesUsage)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/emergency"
+	"github.com/wellywell/gophkeeper/internal/client/menu"
+)
+
+const emergencyUsage = `usage:
+  emergency add USER [--wait DAYS]  designate USER as your trusted contact (default wait 7 days)
+  emergency list                    list your trusted contacts and vaults you are trusted with
+  emergency accept ID               agree to be a trusted contact
+  emergency request ID              request access to a vault, the owner can reject it during the waiting period
+  emergency approve ID              grant a pending request without waiting
+  emergency reject ID               reject a pending request
+  emergency remove ID               revoke a trusted contact or step down as one
+  emergency view ID [KEY]           list records of a vault you were granted access to, or show one`
+
+func runEmergency(token string, pass string, cli *client.Client, args []string) error {
+	if len(args) == 0 {
+		return errors.New(emergencyUsage)
+	}
+	flags := flag.NewFlagSet("emergency", flag.ContinueOnError)
+	wait := flags.Int("wait", 7, "Days the grantee waits before access is released")
+	rest, err := parseInterleaved(flags, args[1:])
+	if err != nil {
+		return err
+	}
+
+	if args[0] == "add" && len(rest) == 1 {
+		err = emergency.Designate(token, pass, cli, rest[0], *wait)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Designated %s as your trusted contact, waiting period %d days\n", rest[0], *wait)
+		return nil
+	}
+	if args[0] == "list" && len(rest) == 0 {
+		contacts, err := cli.EmergencyContacts(token)
+		if err != nil {
+			return err
+		}
+		grants, err := cli.EmergencyGrants(token)
+		if err != nil {
+			return err
+		}
+		for _, e := range append(contacts, grants...) {
+			fmt.Println(e.String())
+		}
+		return nil
+	}
+	if len(rest) < 1 || len(rest) > 2 || (len(rest) == 2 && args[0] != "view") {
+		return errors.New(emergencyUsage)
+	}
+	id, err := strconv.Atoi(rest[0])
+	if err != nil {
+		return errors.New(emergencyUsage)
+	}
+
+	switch args[0] {
+	case "accept":
+		return cli.AcceptEmergencyAccess(token, id)
+	case "request":
+		return cli.RequestEmergencyAccess(token, id)
+	case "approve":
+		return cli.ApproveEmergencyAccess(token, id)
+	case "reject":
+		return cli.RejectEmergencyAccess(token, id)
+	case "remove":
+		return cli.DeleteEmergencyAccess(token, id)
+	case "view":
+		if len(rest) == 2 {
+			record, err := emergency.LoadKey(token, pass, cli, id, rest[1])
+			if err != nil {
+				return err
+			}
+			menu.ShowRecord(record)
+			return nil
+		}
+		records, err := emergency.Load(token, pass, cli, id)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			fmt.Println(r.Item.String())
+		}
+		return nil
+	}
+	return errors.New(emergencyUsage)
+}
@@ -2,10 +2,26 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/breach"
+	"github.com/wellywell/gophkeeper/internal/client/devices"
+	"github.com/wellywell/gophkeeper/internal/client/health"
 	"github.com/wellywell/gophkeeper/internal/client/menu"
+	"github.com/wellywell/gophkeeper/internal/client/passgen"
+	"github.com/wellywell/gophkeeper/internal/client/prompt"
+	"github.com/wellywell/gophkeeper/internal/client/recovery"
+	"github.com/wellywell/gophkeeper/internal/client/reminders"
+	"github.com/wellywell/gophkeeper/internal/client/sharing"
+	"github.com/wellywell/gophkeeper/internal/client/sshagent"
 	"github.com/wellywell/gophkeeper/internal/config"
 )
 
@@ -17,23 +33,344 @@ var (
 
 func main() {
 
-	fmt.Printf("Build version: %s\nBuild date: %s\nBuild commit: %s", buildVersion, buildDate, buildCommit)
+	// в stderr, чтобы не смешивать с машиночитаемым выводом неинтерактивных режимов
+	fmt.Fprintf(os.Stderr, "Build version: %s\nBuild date: %s\nBuild commit: %s\n", buildVersion, buildDate, buildCommit)
 
 	conf, err := config.NewClientConfig()
 	if err != nil {
 		panic(err)
 	}
 
+	// генератору паролей не нужны ни сервер, ни авторизация
+	if flag.Arg(0) == "generate" {
+		err = runGenerate(flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	cli, err := client.NewClient(conf)
 	if err != nil {
 		fmt.Println(err.Error())
 		return
 	}
+	cli.SetVersion(buildVersion)
+	// по ключу устройства сервер узнаёт его при входе, вход с нового устройства нужно подтвердить
+	deviceKeys, err := devices.LoadOrCreateKey(conf.DeviceKey)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	cli.SetDeviceKey(devices.PublicKey(deviceKeys), deviceKeys.Private[:])
+
+	// одноразовую ссылку может открыть и тот, у кого нет учётной записи
+	if flag.Arg(0) == "send" && flag.Arg(1) == "open" {
+		err = runSendOpen(cli, flag.Args()[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
 
-	token, pass, err := menu.Authenticate(cli)
+	// восстановление доступа - для тех, кто не помнит пароль
+	if flag.Arg(0) == "recover" {
+		err = runRecover(cli, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	var checker *breach.Checker
+	if conf.BreachFile != "" {
+		checker, err = breach.Open(conf.BreachFile)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer checker.Close()
+		prompt.BreachCheck = checker.Count
+	}
+
+	token, pass, err := authenticate(cli, conf, deviceKeys)
 	if err != nil {
 		fmt.Println(err.Error())
 		return
 	}
-	menu.MainMenu(token, pass, cli)
+	// ключевая пара нужна, чтобы другие пользователи могли делиться с нами записями
+	_, err = sharing.EnsureKeys(token, pass, cli)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: could not set up sharing keys:", err.Error())
+	}
+
+	switch flag.Arg(0) {
+	case "ssh-agent":
+		err = runSSHAgent(token, pass, cli, conf.SSHAgentSocket)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	case "due-soon":
+		err = runDueSoon(token, pass, cli, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "audit":
+		err = runAudit(token, pass, cli, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "audit-log":
+		err = runAuditLog(token, cli, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "devices":
+		err = runDevices(token, pass, cli, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "delete-account":
+		err = runDeleteAccount(token, cli, conf, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "import":
+		err = runImport(token, pass, cli, conf, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "export":
+		err = runExport(token, pass, cli, conf, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "share":
+		err = runShare(token, pass, cli, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "org":
+		err = runOrg(token, pass, cli, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "send":
+		err = runSend(token, pass, cli, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "emergency":
+		err = runEmergency(token, pass, cli, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "recovery-kit":
+		err = runRecoveryKit(token, pass, cli, conf.Login, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	case "breach-check":
+		err = runBreachCheck(token, pass, cli, checker, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	default:
+		menu.MainMenu(token, pass, cli)
+	}
+}
+
+// authenticate логин без промптов, если логин и пароль заданы в окружении, иначе интерактивная авторизация
+func authenticate(cli *client.Client, conf *config.ClientConfig, deviceKeys *sharing.KeyPair) (string, string, error) {
+	if conf.Login != "" && conf.Password != "" {
+		token, escrowed, err := devices.Login(cli, deviceKeys, cli.Login, conf.Login, conf.Password, os.Stderr)
+		if err != nil {
+			return "", "", err
+		}
+		if escrowed != "" {
+			return token, escrowed, nil
+		}
+		pass, err := recovery.Unlock(token, conf.Password, cli, os.Stderr)
+		return token, pass, err
+	}
+	return menu.Authenticate(cli, deviceKeys)
+}
+
+func runDueSoon(token string, pass string, cli *client.Client, args []string) error {
+	flags := flag.NewFlagSet("due-soon", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print report as JSON")
+	days := flags.Int("days", menu.DueSoonDays, "Report items due within this number of days")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	entries, err := reminders.Report(token, pass, cli, time.Now(), *days)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(entries)
+	}
+	for _, e := range entries {
+		fmt.Println(e.String())
+	}
+	return nil
+}
+
+func runAudit(token string, pass string, cli *client.Client, args []string) error {
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print report as JSON")
+	maxAge := flags.Int("max-age", health.DefaultMaxAgeDays, "Report passwords not changed for more than this number of days")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	groups, err := health.Report(token, pass, cli, time.Now(), *maxAge)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(groups)
+	}
+	for _, g := range groups {
+		fmt.Printf("Severity %s:\n", g.Severity)
+		for _, f := range g.Findings {
+			fmt.Println("  " + f.String())
+		}
+	}
+	return nil
+}
+
+func runAuditLog(token string, cli *client.Client, args []string) error {
+	flags := flag.NewFlagSet("audit-log", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print events as JSON")
+	page := flags.Int("page", 1, "Page of events, newest first")
+	limit := flags.Int("limit", 50, "Events per page")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	events, err := cli.AuditEvents(token, *page, *limit)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(events)
+	}
+	for _, e := range events {
+		fmt.Println(e.String())
+	}
+	return nil
+}
+
+func runBreachCheck(token string, pass string, cli *client.Client, checker *breach.Checker, args []string) error {
+	flags := flag.NewFlagSet("breach-check", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print report as JSON")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if checker == nil {
+		return fmt.Errorf("hash file is not set, use -breach-file or BREACH_FILE")
+	}
+
+	findings, err := breach.Scan(token, pass, cli, checker)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(findings)
+	}
+	for _, f := range findings {
+		fmt.Println(f.String())
+	}
+	return nil
+}
+
+func runGenerate(args []string) error {
+	defaults := passgen.DefaultOptions()
+	phraseDefaults := passgen.DefaultPassphraseOptions()
+
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	length := flags.Int("length", defaults.Length, "Password length")
+	noLower := flags.Bool("no-lower", false, "Do not use lowercase letters")
+	noUpper := flags.Bool("no-upper", false, "Do not use uppercase letters")
+	noDigits := flags.Bool("no-digits", false, "Do not use digits")
+	noSymbols := flags.Bool("no-symbols", false, "Do not use symbols")
+	noAmbiguous := flags.Bool("no-ambiguous", false, "Exclude ambiguous characters (l, 1, O, 0...)")
+	passphrase := flags.Bool("passphrase", false, "Generate a passphrase of random words instead of a password")
+	words := flags.Int("words", phraseDefaults.Words, "Number of words in passphrase")
+	separator := flags.String("separator", phraseDefaults.Separator, "Passphrase words separator")
+	capitalize := flags.Bool("capitalize", false, "Capitalize passphrase words")
+	number := flags.Bool("number", false, "Add a digit to passphrase")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	var password string
+	if *passphrase {
+		password, err = passgen.Passphrase(passgen.PassphraseOptions{
+			Words:      *words,
+			Separator:  *separator,
+			Capitalize: *capitalize,
+			AddNumber:  *number,
+		})
+	} else {
+		password, err = passgen.Password(passgen.Options{
+			Length:           *length,
+			Lower:            !*noLower,
+			Upper:            !*noUpper,
+			Digits:           !*noDigits,
+			Symbols:          !*noSymbols,
+			ExcludeAmbiguous: *noAmbiguous,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(password)
+	return nil
+}
+
+func runSSHAgent(token string, pass string, cli *client.Client, socket string) error {
+	keys, err := sshagent.LoadKeys(token, pass, cli)
+	if err != nil {
+		return err
+	}
+	keyring, err := sshagent.NewKeyring(keys)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := sshagent.Listen(socket)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nLoaded %d keys\nSSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK\n", len(keys), listener.Path)
+	return sshagent.Serve(ctx, listener, keyring)
 }
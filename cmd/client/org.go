@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/menu"
+	"github.com/wellywell/gophkeeper/internal/client/orgs"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+const orgUsage = `usage:
+  org create NAME                         create an organization, you become its owner
+  org list                                list your organizations and invites
+  org accept ORG                          accept an invite
+  org delete ORG                          delete an organization with all its records (owner)
+  org members ORG                         list members
+  org invite ORG USER [--role ROLE]       invite USER as owner, admin, member (default) or readonly
+  org role ORG USER ROLE                  change role of USER (owner)
+  org collections ORG USER [NAME...]      limit member USER to collections NAME, without names - to all (admin)
+  org remove ORG USER                     remove USER and rotate the organization key (admin)
+  org leave ORG                           leave an organization or decline an invite
+  org items ORG [--collection NAME]       list records
+  org show ORG KEY                        show a record
+  org add ORG KEY [--collection NAME]     copy record KEY from your vault to the organization
+  org rm ORG KEY                          delete a record`
+
+func runOrg(token string, pass string, cli *client.Client, args []string) error {
+	if len(args) == 0 {
+		return errors.New(orgUsage)
+	}
+	flags := flag.NewFlagSet("org", flag.ContinueOnError)
+	role := flags.String("role", string(types.RoleMember), "Role of invited user")
+	collection := flags.String("collection", "", "Collection inside the organization")
+	rest, err := parseInterleaved(flags, args[1:])
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "create" && len(rest) == 1:
+		err = orgs.Create(token, pass, cli, rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created organization %s\n", rest[0])
+		return nil
+	case args[0] == "list" && len(rest) == 0:
+		memberships, err := cli.ListOrgs(token)
+		if err != nil {
+			return err
+		}
+		for _, m := range memberships {
+			fmt.Println(m.String())
+		}
+		return nil
+	case args[0] == "accept" && len(rest) == 1:
+		return cli.AcceptInvite(token, rest[0])
+	case args[0] == "delete" && len(rest) == 1:
+		return cli.DeleteOrg(token, rest[0])
+	case args[0] == "members" && len(rest) == 1:
+		members, err := cli.OrgMembers(token, rest[0])
+		if err != nil {
+			return err
+		}
+		for _, m := range members {
+			fmt.Println(m.String())
+		}
+		return nil
+	case args[0] == "role" && len(rest) == 3:
+		if !types.Role(rest[2]).Valid() {
+			return fmt.Errorf("unknown role %s", rest[2])
+		}
+		return cli.SetMemberRole(token, rest[0], rest[1], types.Role(rest[2]))
+	case args[0] == "collections" && len(rest) >= 2:
+		return cli.SetMemberCollections(token, rest[0], rest[1], rest[2:])
+	case args[0] == "rm" && len(rest) == 2:
+		return cli.DeleteOrgItem(token, rest[0], rest[1])
+	}
+
+	// остальным командам нужен расшифрованный ключ организации
+	if len(rest) == 0 {
+		return errors.New(orgUsage)
+	}
+	vault, err := orgs.Open(token, pass, cli, rest[0])
+	if err != nil {
+		return err
+	}
+	switch {
+	case args[0] == "invite" && len(rest) == 2:
+		if !types.Role(*role).Valid() {
+			return fmt.Errorf("unknown role %s", *role)
+		}
+		err = vault.Invite(token, cli, rest[1], types.Role(*role))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Invited %s, they need to run `org accept %s`\n", rest[1], rest[0])
+	case args[0] == "remove" && len(rest) == 2:
+		err = vault.Remove(token, cli, rest[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s, organization key rotated\n", rest[1])
+	case args[0] == "leave" && len(rest) == 1:
+		return vault.Remove(token, cli, vault.Membership.Username)
+	case args[0] == "items" && len(rest) == 1:
+		items, err := vault.Items(token, cli, *collection)
+		if err != nil {
+			return err
+		}
+		for _, i := range items {
+			fmt.Println(i.String())
+		}
+	case args[0] == "show" && len(rest) == 2:
+		item, err := vault.Get(token, cli, rest[1])
+		if err != nil {
+			return err
+		}
+		menu.ShowRecord(item.Record)
+	case args[0] == "add" && len(rest) == 2:
+		return vault.Copy(token, pass, cli, rest[1], *collection)
+	default:
+		return errors.New(orgUsage)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/prompt"
+	"github.com/wellywell/gophkeeper/internal/client/recovery"
+	"github.com/wellywell/gophkeeper/internal/totp"
+)
+
+const recoverUsage = `usage:
+  recover LOGIN [--qr IMAGE]...  set a new password using the recovery key or enough recovery shares,
+                                 read from QR code images or typed in`
+
+func runRecover(cli *client.Client, args []string) error {
+	var images []string
+	flags := flag.NewFlagSet("recover", flag.ContinueOnError)
+	flags.Func("qr", "Image with QR code of the recovery key or a share, can be repeated", func(s string) error {
+		images = append(images, s)
+		return nil
+	})
+	rest, err := parseInterleaved(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return errors.New(recoverUsage)
+	}
+
+	var texts []string
+	for _, image := range images {
+		text, err := totp.ReadQR(image)
+		if err != nil {
+			return fmt.Errorf("%s: %w", image, err)
+		}
+		texts = append(texts, text)
+	}
+	vaultKey, err := recoveryKey(texts)
+	if err != nil {
+		return err
+	}
+	password, err := prompt.EnterNewSecret("New password: ")
+	if err != nil {
+		return err
+	}
+	_, err = recovery.Recover(cli, rest[0], vaultKey, password)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Password changed, log in with the new one. Your recovery kit stays valid")
+	return nil
+}
+
+// recoveryKey собирает ключ восстановления из прочитанных QR-кодов и введённых строк:
+// либо ключ целиком, либо доли, пока их не хватит для восстановления
+func recoveryKey(texts []string) (string, error) {
+	for {
+		var shares []string
+		for _, t := range texts {
+			if !recovery.IsShare(t) {
+				return strings.TrimSpace(t), nil
+			}
+			shares = append(shares, t)
+		}
+		if len(shares) > 0 {
+			key, err := recovery.Combined(shares)
+			if !errors.Is(err, recovery.ErrNotEnoughShares) {
+				return key, err
+			}
+			fmt.Println(err.Error())
+		}
+
+		text, err := prompt.EnterSecret("Recovery key or share: ")
+		if err != nil {
+			return "", err
+		}
+		if recovery.IsShare(text) {
+			_, err = recovery.ParseShare(text)
+			if err != nil {
+				fmt.Println(err.Error())
+				continue
+			}
+		}
+		texts = append(texts, text)
+	}
+}
+
+func runRecoveryKit(token string, pass string, cli *client.Client, login string, args []string) error {
+	flags := flag.NewFlagSet("recovery-kit", flag.ContinueOnError)
+	shares := flags.Int("shares", 0, "Split recovery key into this number of shares")
+	threshold := flags.Int("threshold", 0, "Number of shares needed to recover")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if (*shares == 0) != (*threshold == 0) {
+		return errors.New("--shares and --threshold go together")
+	}
+
+	err = recovery.Enable(token, pass, cli)
+	if err != nil {
+		return err
+	}
+	return recovery.WriteKit(os.Stdout, login, pass, *shares, *threshold)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/export"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/client/prompt"
+	"github.com/wellywell/gophkeeper/internal/client/send"
+)
+
+const sendUsage = `usage:
+  send create [--views N] [--expires DURATION] [--password] [--item KEY | TEXT]
+                          create a one-time link; the secret is TEXT, record KEY or stdin
+  send list               list your active links
+  send delete ID          delete a link before it expires
+  send open LINK          open a link, no account needed`
+
+func runSend(token string, pass string, cli *client.Client, args []string) error {
+	if len(args) == 0 {
+		return errors.New(sendUsage)
+	}
+	flags := flag.NewFlagSet("send", flag.ContinueOnError)
+	views := flags.Int("views", 1, "Number of views before the secret is deleted")
+	expires := flags.Duration("expires", 24*time.Hour, "Time until the secret is deleted")
+	withPassword := flags.Bool("password", false, "Ask for a password the recipient must enter")
+	item := flags.String("item", "", "Send record KEY from your vault")
+	rest, err := parseInterleaved(flags, args[1:])
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "create" && len(rest) <= 1:
+		text, err := sendText(token, pass, cli, *item, rest)
+		if err != nil {
+			return err
+		}
+		opts := send.Options{MaxViews: *views, TTL: *expires}
+		if *withPassword {
+			opts.Password, err = prompt.EnterNewSecret("Password for the link: ")
+			if err != nil {
+				return err
+			}
+		}
+		link, created, err := send.Create(token, cli, text, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, created.String())
+		fmt.Println(link)
+	case args[0] == "list" && len(rest) == 0:
+		sends, err := cli.ListSends(token)
+		if err != nil {
+			return err
+		}
+		for _, s := range sends {
+			fmt.Println(s.String())
+		}
+	case args[0] == "delete" && len(rest) == 1:
+		return cli.DeleteSend(token, rest[0])
+	default:
+		return errors.New(sendUsage)
+	}
+	return nil
+}
+
+// runSendOpen открывает одноразовую ссылку, авторизация для этого не нужна
+func runSendOpen(cli *client.Client, args []string) error {
+	if len(args) != 1 {
+		return errors.New(sendUsage)
+	}
+	id, _, err := send.ParseLink(args[0])
+	if err != nil {
+		return err
+	}
+	info, err := cli.SendInfo(id)
+	if err != nil {
+		return err
+	}
+	password := ""
+	if info.HasPassword {
+		password, err = prompt.EnterSecret("Password for the link: ")
+		if err != nil {
+			return err
+		}
+	}
+	text, left, err := send.Open(cli, args[0], password)
+	if err != nil {
+		return err
+	}
+	fmt.Println(text)
+	if left == 0 {
+		fmt.Fprintln(os.Stderr, "This was the last view, the secret has been deleted from the server")
+	} else {
+		fmt.Fprintf(os.Stderr, "The secret can be viewed %d more time(s)\n", left)
+	}
+	return nil
+}
+
+func sendText(token string, pass string, cli *client.Client, key string, args []string) (string, error) {
+	switch {
+	case key != "" && len(args) > 0:
+		return "", fmt.Errorf("use either --item or TEXT")
+	case key != "":
+		record, err := export.LoadKey(token, pass, cli, key)
+		if err != nil {
+			return "", err
+		}
+		return recordText(record)
+	case len(args) == 1:
+		return args[0], nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimRight(string(data), "\n")
+	if text == "" {
+		return "", fmt.Errorf("nothing to send")
+	}
+	return text, nil
+}
+
+// recordText секрет записи в виде текста для получателя
+func recordText(r importer.Record) (string, error) {
+	var text string
+	switch {
+	case r.Login != nil:
+		text = r.Login.String()
+	case r.Card != nil:
+		text = r.Card.Reveal()
+	case r.Text != nil:
+		text = string(*r.Text)
+	case r.SSHKey != nil:
+		text = r.SSHKey.PrivateKey
+		if r.SSHKey.Passphrase != "" {
+			text += "\nPassphrase: " + r.SSHKey.Passphrase
+		}
+	case r.TOTP != nil:
+		text = r.TOTP.Secret
+	default:
+		return "", fmt.Errorf("%s: binary records cannot be sent", r.Item.Key)
+	}
+	return strings.TrimSpace(text), nil
+}
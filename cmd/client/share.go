@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/sharing"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+const shareUsage = `usage:
+  share add KEY USER [--write]  share record KEY with USER (read-only unless --write)
+  share revoke KEY USER         revoke access of USER to record KEY
+  share list [KEY]              list users a record is shared with, or records shared with you
+  share pull KEY USER           apply changes USER made to record KEY to your vault`
+
+func runShare(token string, pass string, cli *client.Client, args []string) error {
+	if len(args) == 0 {
+		return errors.New(shareUsage)
+	}
+	flags := flag.NewFlagSet("share", flag.ContinueOnError)
+	write := flags.Bool("write", false, "Allow recipient to change the record")
+	rest, err := parseInterleaved(flags, args[1:])
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "add" && len(rest) == 2:
+		permission := types.PermissionRead
+		if *write {
+			permission = types.PermissionWrite
+		}
+		err = sharing.Share(token, pass, cli, rest[0], rest[1], permission)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Shared %s with %s (%s)\n", rest[0], rest[1], permission)
+	case args[0] == "revoke" && len(rest) == 2:
+		err = cli.RevokeShare(token, rest[0], rest[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Revoked access of %s to %s\n", rest[1], rest[0])
+	case args[0] == "list" && len(rest) == 1:
+		shares, err := cli.ItemShares(token, rest[0])
+		if err != nil {
+			return err
+		}
+		for _, s := range shares {
+			fmt.Println(s.String())
+		}
+	case args[0] == "list" && len(rest) == 0:
+		shares, err := cli.SharedWithMe(token)
+		if err != nil {
+			return err
+		}
+		for _, s := range shares {
+			fmt.Println(s.String())
+		}
+	case args[0] == "pull" && len(rest) == 2:
+		err = sharing.Pull(token, pass, cli, rest[0], rest[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied changes of %s to %s\n", rest[1], rest[0])
+	default:
+		return errors.New(shareUsage)
+	}
+	return nil
+}
+
+// parseInterleaved разбирает флаги, стоящие в любом месте среди позиционных аргументов,
+// и возвращает позиционные аргументы
+func parseInterleaved(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/archive"
+	"github.com/wellywell/gophkeeper/internal/client/export"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/client/kdbx"
+	"github.com/wellywell/gophkeeper/internal/client/plaintext"
+	"github.com/wellywell/gophkeeper/internal/client/prompt"
+	"github.com/wellywell/gophkeeper/internal/config"
+)
+
+// форматы, которые поддерживаются и для импорта, и для экспорта
+const (
+	// formatKDBX база KeePass
+	formatKDBX = "kdbx"
+	// formatArchive зашифрованная резервная копия gophkeeper
+	formatArchive = "archive"
+)
+
+// форматы незашифрованной выгрузки, только для экспорта
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+var errPlaintextCancelled = errors.New("plaintext export cancelled")
+
+func runImport(token string, pass string, cli *client.Client, conf *config.ClientConfig, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", fmt.Sprintf("Export format, one of %v, %s or %s", importer.Formats, formatKDBX, formatArchive))
+	onConflict := flags.String("on-conflict", string(importer.ConflictSkip), "What to do when key already exists: skip, rename or overwrite")
+	fresh := flags.Bool("fresh", false, "Restore into an empty account only, keeping all keys as is")
+	dryRun := flags.Bool("dry-run", false, "Show what would be imported without changing anything")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: import --format FORMAT [--on-conflict MODE | --fresh] [--dry-run] FILE")
+	}
+	mode, err := importer.ParseConflict(*onConflict)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var records []importer.Record
+	switch *format {
+	case formatKDBX:
+		password, err := filePassword(conf, false)
+		if err != nil {
+			return err
+		}
+		records, err = kdbx.Read(bytes.NewReader(data), password)
+		if err != nil {
+			return err
+		}
+	case formatArchive:
+		password, err := filePassword(conf, false)
+		if err != nil {
+			return err
+		}
+		content, err := archive.Read(bytes.NewReader(data), password)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backup created at %s\n", content.CreatedAt.Format(time.RFC3339))
+		records = content.Records
+	default:
+		records, err = importer.Parse(importer.Format(*format), data)
+		if err != nil {
+			return err
+		}
+	}
+
+	var summary *importer.Summary
+	if *fresh {
+		summary, err = importer.Restore(token, pass, cli, records, *dryRun)
+	} else {
+		summary, err = importer.Run(token, pass, cli, records, mode, *dryRun)
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range summary.Entries {
+		fmt.Println(e.String())
+	}
+	if *dryRun {
+		fmt.Println("Dry run, nothing was changed")
+	}
+	fmt.Println(summary.String())
+	return nil
+}
+
+func runExport(token string, pass string, cli *client.Client, conf *config.ClientConfig, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", formatKDBX, fmt.Sprintf("Export format: %s, %s, %s or %s", formatKDBX, formatArchive, formatJSON, formatCSV))
+	cipher := flags.String("cipher", string(kdbx.CipherChaCha20), "KeePass database cipher: chacha20 or aes")
+	binaryDir := flags.String("binary-dir", "", "Plaintext JSON only: write binary items to files in this directory instead of base64")
+	confirmed := flags.Bool("yes-plaintext", false, "Do not ask for confirmation of plaintext export")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: export [--format kdbx|archive|json|csv] [--cipher chacha20|aes] [--binary-dir DIR] FILE")
+	}
+	name := flags.Arg(0)
+
+	var write func(io.Writer, []importer.Record) error
+	switch *format {
+	case formatKDBX, formatArchive:
+		password, err := filePassword(conf, true)
+		if err != nil {
+			return err
+		}
+		write = func(w io.Writer, records []importer.Record) error {
+			if *format == formatArchive {
+				return archive.Write(w, records, password, time.Now())
+			}
+			return kdbx.Write(w, records, password, kdbx.Cipher(*cipher))
+		}
+	case formatJSON, formatCSV:
+		err = confirmPlaintext(name, *binaryDir, *confirmed)
+		if err != nil {
+			return err
+		}
+		write = func(w io.Writer, records []importer.Record) error {
+			if *format == formatJSON {
+				return plaintext.WriteJSON(w, records, time.Now(), *binaryDir)
+			}
+			skipped, err := plaintext.WriteCSV(w, records)
+			if skipped > 0 {
+				fmt.Printf("Skipped %d records: CSV holds only logins with passwords and credit cards\n", skipped)
+			}
+			withAttachments := 0
+			for _, r := range records {
+				if len(r.Attachments) > 0 {
+					withAttachments++
+				}
+			}
+			if withAttachments > 0 {
+				fmt.Fprintf(os.Stderr, "WARNING: attachments of %d records are not exported to CSV, use --format json or archive\n", withAttachments)
+			}
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown export format %s", *format)
+	}
+
+	records, err := export.Load(token, pass, cli)
+	if err != nil {
+		return err
+	}
+	err = export.Attachments(token, pass, cli, records)
+	if err != nil {
+		return err
+	}
+	err = createFile(name, func(w io.Writer) error {
+		return write(w, records)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d records to %s\n", len(records), name)
+	if *format == formatJSON || *format == formatCSV {
+		fmt.Fprintf(os.Stderr, "WARNING: %s is NOT encrypted. Move it where it is needed and delete it as soon as possible\n", name)
+	}
+	return nil
+}
+
+// confirmPlaintext предупреждает, что выгрузка не зашифрована, проверяет каталог назначения и запрашивает подтверждение
+func confirmPlaintext(name string, binaryDir string, confirmed bool) error {
+	err := plaintext.CheckLocation(name)
+	if err != nil {
+		return err
+	}
+	if binaryDir != "" {
+		err = plaintext.CheckLocation(binaryDir)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(os.Stderr, "WARNING: plaintext export writes ALL your passwords, cards and files UNENCRYPTED to disk.")
+	fmt.Fprintln(os.Stderr, "WARNING: anyone who can read the file, its backups or the disk will see every secret.")
+	if confirmed {
+		return nil
+	}
+	ok, err := prompt.Confirm("Write an unencrypted export?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errPlaintextCancelled
+	}
+	return nil
+}
+
+// createFile создаёт новый файл, доступный только владельцу. Существующие файлы не перезаписываются,
+// при ошибке записи недописанный файл удаляется
+func createFile(name string, write func(io.Writer) error) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	err = write(file)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(name)
+		return err
+	}
+	return file.Close()
+}
+
+// filePassword пароль от файла импорта или экспорта: из окружения, иначе запрашивается у пользователя
+func filePassword(conf *config.ClientConfig, isNew bool) (string, error) {
+	if conf.ExportPassword != "" {
+		return conf.ExportPassword, nil
+	}
+	if isNew {
+		return prompt.EnterNewSecret("File password: ")
+	}
+	return prompt.EnterSecret("File password: ")
+}
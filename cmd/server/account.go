@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// runDeleteUser удаление учётной записи администратором: по умолчанию с тем же сроком на отмену, что и по запросу
+// пользователя, с --now - сразу
+func runDeleteUser(conf *config.ServerConfig, args []string) error {
+	flags := flag.NewFlagSet("delete-user", flag.ContinueOnError)
+	now := flags.Bool("now", false, "Delete immediately instead of scheduling deletion")
+	cancel := flags.Bool("cancel", false, "Cancel scheduled deletion")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || (*now && *cancel) {
+		return fmt.Errorf("usage: delete-user [--now | --cancel] USERNAME")
+	}
+	username := flags.Arg(0)
+
+	database, err := db.Connect(conf.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+
+	ctx := context.Background()
+	userID, err := database.GetUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+	switch {
+	case *now:
+		err = deleteUser(ctx, database, userID, "admin")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("User %s deleted\n", username)
+	case *cancel:
+		err = database.CancelUserDeletion(ctx, userID)
+		if err != nil {
+			return err
+		}
+		appendAudit(ctx, database, userID, types.AuditDeletionCancel)
+		fmt.Printf("Deletion of user %s cancelled\n", username)
+	default:
+		deleteAt, err := database.ScheduleUserDeletion(ctx, userID, time.Now().Add(types.AccountDeletionGrace))
+		if err != nil {
+			return err
+		}
+		appendAudit(ctx, database, userID, types.AuditDeletionRequest)
+		fmt.Printf("User %s will be deleted at %s\n", username, deleteAt.Local().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// deleteUser удаляет пользователя со всеми данными и записывает удаление в его журнал аудита от имени actor
+func deleteUser(ctx context.Context, database *db.Database, userID int, actor string) error {
+	err := database.DeleteUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return database.AppendAuditEvent(ctx, types.AuditEvent{UserID: &userID, Actor: actor, Action: types.AuditAccountDelete})
+}
+
+func appendAudit(ctx context.Context, database *db.Database, userID int, action types.AuditAction) {
+	err := database.AppendAuditEvent(ctx, types.AuditEvent{UserID: &userID, Actor: "admin", Action: action})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
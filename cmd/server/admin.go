@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/admin"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+const adminUsage = `usage: admin [--server URL] COMMAND
+commands:
+  users [--json]        list users with item counts and storage
+  stats [--json]        show server statistics
+  disable USERNAME      forbid logins and sign out all sessions
+  enable USERNAME       allow logins again
+  logout USERNAME       sign out all sessions
+  reset-2fa USERNAME    forget approved devices, print a one-time code that approves the next device
+  delete [--now] USERNAME  schedule deletion, or delete immediately with --now
+  invite [--uses N] [--expires DURATION] [--note TEXT]  create an invite code for registration
+  invites [--json]      list active invites
+  revoke-invite ID      revoke an invite`
+
+// runAdmin команды администратора. Работают через API администратора запущенного сервера с токеном ADMIN_TOKEN
+func runAdmin(conf *config.ServerConfig, args []string) error {
+	flags := flag.NewFlagSet("admin", flag.ContinueOnError)
+	server := flags.String("server", "https://"+conf.RunAddress, "Server address")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New(adminUsage)
+	}
+	if conf.AdminToken == "" {
+		return errors.New("admin token is not set, use ADMIN_TOKEN or -admin-token")
+	}
+
+	cli, err := admin.NewClient(*server, conf.AdminToken, conf.SSLCert)
+	if err != nil {
+		return err
+	}
+
+	command, args := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "users", "stats", "invites":
+		sub := flag.NewFlagSet(command, flag.ContinueOnError)
+		asJSON := sub.Bool("json", false, "Print JSON")
+		err = sub.Parse(args)
+		if err != nil {
+			return err
+		}
+		switch command {
+		case "users":
+			return printUsers(cli, *asJSON)
+		case "invites":
+			return printInvites(cli, *asJSON)
+		}
+		return printStats(cli, *asJSON)
+	case "invite":
+		sub := flag.NewFlagSet(command, flag.ContinueOnError)
+		uses := sub.Int("uses", 1, "How many accounts can be registered with the invite")
+		expires := sub.Duration("expires", types.DefaultInviteTTL, "How long the invite is valid")
+		note := sub.String("note", "", "Who the invite is for")
+		err = sub.Parse(args)
+		if err != nil {
+			return err
+		}
+		created, err := cli.CreateInvite(types.NewInvite{MaxUses: *uses, ExpiresIn: expires.String(), Note: *note})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Invite #%d, valid until %s:\n%s\n", created.ID, created.ExpiresAt.Local().Format(time.RFC3339), created.Code)
+		return nil
+	case "delete":
+		sub := flag.NewFlagSet(command, flag.ContinueOnError)
+		now := sub.Bool("now", false, "Delete immediately instead of scheduling deletion")
+		err = sub.Parse(args)
+		if err != nil {
+			return err
+		}
+		if sub.NArg() != 1 {
+			return errors.New(adminUsage)
+		}
+		deletion, err := cli.Delete(sub.Arg(0), *now)
+		if err != nil {
+			return err
+		}
+		if deletion == nil {
+			fmt.Printf("User %s deleted\n", sub.Arg(0))
+			return nil
+		}
+		fmt.Printf("User %s will be deleted at %s\n", sub.Arg(0), deletion.DeleteAt.Local().Format(time.RFC3339))
+		return nil
+	}
+
+	if len(args) != 1 {
+		return errors.New(adminUsage)
+	}
+	username := args[0]
+	switch command {
+	case "disable":
+		err = cli.Disable(username)
+		if err == nil {
+			fmt.Printf("User %s disabled\n", username)
+		}
+	case "enable":
+		err = cli.Enable(username)
+		if err == nil {
+			fmt.Printf("User %s enabled\n", username)
+		}
+	case "logout":
+		var affected int64
+		affected, err = cli.Logout(username)
+		if err == nil {
+			fmt.Printf("Signed out %d sessions of user %s\n", affected, username)
+		}
+	case "revoke-invite":
+		var id int
+		id, err = strconv.Atoi(username)
+		if err != nil {
+			return errors.New(adminUsage)
+		}
+		err = cli.RevokeInvite(id)
+		if err == nil {
+			fmt.Printf("Invite #%d revoked\n", id)
+		}
+	case "reset-2fa":
+		var enrollment types.DeviceEnrollment
+		enrollment, err = cli.ResetDevices(username)
+		if err == nil {
+			fmt.Printf("Forgot %d devices of user %s. Enrollment code for the next device, valid until %s:\n%s\n",
+				enrollment.Affected, username, enrollment.ExpiresAt.Local().Format(time.RFC3339), enrollment.Code)
+		}
+	default:
+		return errors.New(adminUsage)
+	}
+	return err
+}
+
+func printUsers(cli *admin.Client, asJSON bool) error {
+	users, err := cli.Users()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return json.NewEncoder(os.Stdout).Encode(users)
+	}
+	for _, u := range users {
+		fmt.Println(u)
+	}
+	return nil
+}
+
+func printInvites(cli *admin.Client, asJSON bool) error {
+	invites, err := cli.Invites()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return json.NewEncoder(os.Stdout).Encode(invites)
+	}
+	for _, i := range invites {
+		fmt.Println(i)
+	}
+	return nil
+}
+
+func printStats(cli *admin.Client, asJSON bool) error {
+	stats, err := cli.Stats()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return json.NewEncoder(os.Stdout).Encode(stats)
+	}
+	fmt.Printf("Users: %d (%d disabled, %d pending deletion)\n", stats.Users, stats.DisabledUsers, stats.PendingDeletions)
+	fmt.Printf("Items: %d, %d bytes\n", stats.Items, stats.StorageBytes)
+	fmt.Printf("Active sessions: %d, approved devices: %d\n", stats.Sessions, stats.Devices)
+	fmt.Printf("Shares: %d, organizations: %d, sends: %d\n", stats.Shares, stats.Organizations, stats.Sends)
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/db"
+)
+
+// runAuditVerify проверяет цепочку хешей журнала аудита. Хеш последнего события стоит сохранять
+// вне сервера: удаление событий с конца журнала видно только по расхождению с ним.
+// Персональные данные удалённых пользователей стёрты, но хеши таких событий сходятся
+func runAuditVerify(conf *config.ServerConfig, args []string) error {
+	flags := flag.NewFlagSet("audit-verify", flag.ContinueOnError)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("usage: audit-verify")
+	}
+
+	database, err := db.Connect(conf.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+
+	status, err := database.VerifyAuditChain(context.Background())
+	if err != nil {
+		return fmt.Errorf("%d events verified: %w", status.Checked, err)
+	}
+	fmt.Printf("Audit chain OK: %d events, last hash %s\n", status.Checked, status.Head)
+	if status.Unverified > 0 {
+		fmt.Printf("%d erased events written before salted hashes are only checked for their place in the chain\n",
+			status.Unverified)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/db"
+)
+
+// runBackup делает полную или инкрементальную резервную копию работающей БД
+func runBackup(conf *config.ServerConfig, args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	base := flags.String("base", "", "Previous backup: make an incremental backup of changes since it")
+	since := flags.String("since", "", "Make an incremental backup of changes since this time (RFC 3339)")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || (*base != "" && *since != "") {
+		return fmt.Errorf("usage: backup [--base FILE | --since TIME] FILE")
+	}
+
+	var from *time.Time
+	switch {
+	case *base != "":
+		manifest, err := readManifest(*base)
+		if err != nil {
+			return err
+		}
+		t := manifest.CreatedAt.Add(-db.IncrementalOverlap)
+		from = &t
+	case *since != "":
+		t, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			return err
+		}
+		from = &t
+	}
+
+	database, err := db.Connect(conf.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+
+	name := flags.Arg(0)
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	manifest, err := database.Backup(context.Background(), file, from)
+	if err == nil {
+		err = file.Close()
+	} else {
+		_ = file.Close()
+	}
+	if err != nil {
+		_ = os.Remove(name)
+		return err
+	}
+
+	kind := "full"
+	if manifest.Incremental() {
+		kind = "incremental since " + manifest.Since.Format(time.RFC3339)
+	}
+	fmt.Printf("Backup %s: %s, schema version %d, snapshot at %s\n", name, kind, manifest.SchemaVersion, manifest.CreatedAt.Format(time.RFC3339))
+	return nil
+}
+
+// runRestore восстанавливает полную копию и, по порядку, инкрементальные копии после неё.
+// Перед восстановлением БД мигрируется до версии схемы копии, после - до последней версии
+func runRestore(conf *config.ServerConfig, args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: restore FULL_BACKUP [INCREMENTAL_BACKUP...]")
+	}
+
+	readers := make([]*db.BackupReader, 0, flags.NArg())
+	for _, name := range flags.Args() {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader, err := db.NewBackupReader(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		defer reader.Close()
+		if reader.Manifest.Incremental() != (len(readers) > 0) {
+			return fmt.Errorf("%s: the first backup must be full and the rest incremental", name)
+		}
+		readers = append(readers, reader)
+	}
+
+	latest, err := db.LatestVersion()
+	if err != nil {
+		return err
+	}
+	current, err := db.CurrentVersion(conf.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	first := readers[0].Manifest.SchemaVersion
+	if first > latest {
+		return fmt.Errorf("%w: backup %d is newer than this server (%d)", db.ErrSchemaVersion, first, latest)
+	}
+	if current > first {
+		return fmt.Errorf("%w: database %d is newer than backup %d", db.ErrSchemaVersion, current, first)
+	}
+
+	database, err := db.Connect(conf.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+
+	var restoredAt *time.Time
+	for i, reader := range readers {
+		manifest := reader.Manifest
+		if manifest.SchemaVersion > latest {
+			return fmt.Errorf("%w: backup %d is newer than this server (%d)", db.ErrSchemaVersion, manifest.SchemaVersion, latest)
+		}
+		err = db.MigrateTo(conf.DatabaseDSN, manifest.SchemaVersion)
+		if err != nil {
+			return err
+		}
+		err = database.Restore(context.Background(), reader, restoredAt)
+		if err != nil {
+			return fmt.Errorf("%s: %w", flags.Arg(i), err)
+		}
+		restoredAt = &manifest.CreatedAt
+		fmt.Printf("Restored %s (snapshot at %s)\n", flags.Arg(i), manifest.CreatedAt.Format(time.RFC3339))
+	}
+	return db.Migrate(conf.DatabaseDSN)
+}
+
+func readManifest(name string) (*db.BackupManifest, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := db.NewBackupReader(file)
+	if err != nil {
+		if errors.Is(err, db.ErrNotBackup) {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return nil, err
+	}
+	return &reader.Manifest, nil
+}
@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -12,11 +13,13 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 
+	"github.com/wellywell/gophkeeper/internal/auth"
 	"github.com/wellywell/gophkeeper/internal/config"
 	"github.com/wellywell/gophkeeper/internal/db"
 	"github.com/wellywell/gophkeeper/internal/handlers"
 	"github.com/wellywell/gophkeeper/internal/logging"
 	"github.com/wellywell/gophkeeper/internal/router"
+	"github.com/wellywell/gophkeeper/internal/types"
 )
 
 var (
@@ -39,6 +42,41 @@ func main() {
 		panic(err)
 	}
 
+	switch flag.Arg(0) {
+	case "backup", "restore":
+		run := runBackup
+		if flag.Arg(0) == "restore" {
+			run = runRestore
+		}
+		err = run(conf, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "audit-verify":
+		err = runAuditVerify(conf, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "delete-user":
+		err = runDeleteUser(conf, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "admin":
+		err = runAdmin(conf, flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	database, err := db.NewDatabase(conf.DatabaseDSN)
 
 	if err != nil {
@@ -52,6 +90,10 @@ func main() {
 	}()
 
 	hndl := handlers.NewHandlerSet(conf.Secret, database)
+	hndl.SetRegistration(handlers.Registration{
+		Mode:     types.RegistrationMode(conf.Registration),
+		Password: auth.PasswordPolicy{MinLength: conf.PasswordMinLength, MinScore: conf.PasswordMinScore},
+	})
 
 	s := router.NewServer(*conf, *hndl, logger)
 
@@ -62,6 +104,11 @@ func main() {
 	// Server run context
 	serverCtx, serverStopCtx := context.WithCancel(context.Background())
 
+	go sweepSends(serverCtx, database)
+	go sweepSessions(serverCtx, database)
+	go sweepDevices(serverCtx, database)
+	go sweepDeletedUsers(serverCtx, database)
+
 	go func() {
 		<-sig
 		// Trigger graceful shutdown
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/db"
+)
+
+// sendSweepInterval как часто удаляются просроченные одноразовые ссылки
+const sendSweepInterval = time.Minute
+
+// sweepSends удаляет просроченные одноразовые ссылки, пока не отменён ctx. Открыть просроченную ссылку
+// нельзя и без этого, уборщик нужен, чтобы шифротекст не лежал в БД дольше срока
+func sweepSends(ctx context.Context, database *db.Database) {
+	ticker := time.NewTicker(sendSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := database.DeleteExpiredSends(ctx)
+			if err != nil {
+				log.Println("sweeping sends:", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("deleted %d expired sends", deleted)
+			}
+		}
+	}
+}
+
+// sessionSweepInterval как часто удаляются истёкшие сессии
+const sessionSweepInterval = time.Hour
+
+// sweepSessions удаляет истёкшие сессии, пока не отменён ctx. Токен истёкшей сессии и так не принимается,
+// уборщик нужен, чтобы список устройств не разрастался
+func sweepSessions(ctx context.Context, database *db.Database) {
+	ticker := time.NewTicker(sessionSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := database.DeleteIdleSessions(ctx)
+			if err != nil {
+				log.Println("sweeping sessions:", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("deleted %d idle sessions", deleted)
+			}
+		}
+	}
+}
+
+// deviceSweepInterval как часто удаляются истёкшие и отклонённые запросы на вход
+const deviceSweepInterval = 10 * time.Minute
+
+// sweepDevices удаляет истёкшие и отклонённые запросы на вход с новых устройств, пока не отменён ctx
+func sweepDevices(ctx context.Context, database *db.Database) {
+	ticker := time.NewTicker(deviceSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := database.DeleteStaleDevices(ctx)
+			if err != nil {
+				log.Println("sweeping devices:", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("deleted %d stale device requests", deleted)
+			}
+		}
+	}
+}
+
+// deletionSweepInterval как часто удаляются учётные записи, время удаления которых наступило
+const deletionSweepInterval = time.Hour
+
+// sweepDeletedUsers удаляет учётные записи по истечении срока на отмену удаления, пока не отменён ctx
+func sweepDeletedUsers(ctx context.Context, database *db.Database) {
+	ticker := time.NewTicker(deletionSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ids, err := database.DueUserDeletions(ctx)
+			if err != nil {
+				log.Println("sweeping deleted users:", err)
+				continue
+			}
+			for _, id := range ids {
+				err = deleteUser(ctx, database, id, "server")
+				if err != nil {
+					log.Printf("deleting user %d: %s", id, err)
+					continue
+				}
+				log.Printf("deleted user %d", id)
+			}
+		}
+	}
+}
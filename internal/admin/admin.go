@@ -0,0 +1,160 @@
+// Package admin клиент API администратора сервера.
+//
+// API администратора защищено отдельным токеном (ADMIN_TOKEN) и отдаёт только метаданные учётных записей:
+// число и объём записей, сессии, состояние. Расшифровать данные пользователей через него нельзя
+package admin
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// ErrNotFound пользователь не найден или API администратора выключено на сервере
+var ErrNotFound = errors.New("not found")
+
+// Client клиент API администратора
+type Client struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+// NewClient создаёт клиент для сервера address. Сертификат сервера проверяется по файлу certPath
+func NewClient(address string, token string, certPath string) (*Client, error) {
+	cert, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(cert) {
+		return nil, fmt.Errorf("no certificates in %s", certPath)
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		},
+	}
+	return &Client{address: address, token: token, client: client}, nil
+}
+
+// Users список учётных записей
+func (c *Client) Users() ([]types.AdminUser, error) {
+	var users []types.AdminUser
+	err := c.request(http.MethodGet, "/api/admin/users", nil, &users)
+	return users, err
+}
+
+// Stats сводка по серверу
+func (c *Client) Stats() (types.AdminStats, error) {
+	var stats types.AdminStats
+	err := c.request(http.MethodGet, "/api/admin/stats", nil, &stats)
+	return stats, err
+}
+
+// Disable отключает учётную запись и завершает её сессии
+func (c *Client) Disable(username string) error {
+	return c.request(http.MethodPost, userPath(username)+"/disable", nil, nil)
+}
+
+// Enable снова разрешает вход отключённому пользователю
+func (c *Client) Enable(username string) error {
+	return c.request(http.MethodPost, userPath(username)+"/enable", nil, nil)
+}
+
+// Logout завершает все сессии пользователя и возвращает их число
+func (c *Client) Logout(username string) (int64, error) {
+	var result types.AdminResult
+	err := c.request(http.MethodDelete, userPath(username)+"/sessions", nil, &result)
+	return result.Affected, err
+}
+
+// ResetDevices забывает подтверждённые устройства пользователя. Возвращает их число и одноразовый код,
+// без которого следующее устройство пользователя не подтвердится
+func (c *Client) ResetDevices(username string) (types.DeviceEnrollment, error) {
+	var enrollment types.DeviceEnrollment
+	err := c.request(http.MethodDelete, userPath(username)+"/devices", nil, &enrollment)
+	return enrollment, err
+}
+
+// Delete планирует удаление учётной записи и возвращает его время, с now - удаляет сразу и возвращает nil
+func (c *Client) Delete(username string, now bool) (*types.AccountDeletion, error) {
+	if now {
+		return nil, c.request(http.MethodDelete, userPath(username)+"?now=true", nil, nil)
+	}
+	var deletion types.AccountDeletion
+	err := c.request(http.MethodDelete, userPath(username), nil, &deletion)
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// CreateInvite создаёт приглашение для регистрации. Код показывается только в ответе
+func (c *Client) CreateInvite(invite types.NewInvite) (types.CreatedInvite, error) {
+	var created types.CreatedInvite
+	err := c.request(http.MethodPost, "/api/admin/invites", invite, &created)
+	return created, err
+}
+
+// Invites действующие приглашения
+func (c *Client) Invites() ([]types.Invite, error) {
+	var invites []types.Invite
+	err := c.request(http.MethodGet, "/api/admin/invites", nil, &invites)
+	return invites, err
+}
+
+// RevokeInvite отзывает приглашение id
+func (c *Client) RevokeInvite(id int) error {
+	return c.request(http.MethodDelete, fmt.Sprintf("/api/admin/invites/%d", id), nil, nil)
+}
+
+func userPath(username string) string {
+	return "/api/admin/users/" + url.PathEscape(username)
+}
+
+func (c *Client) request(method string, path string, body any, out any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("could not serialize data")
+		}
+	}
+	req, err := http.NewRequest(method, c.address+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(auth.AdminTokenHeader, c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not make request %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, bytes.TrimSpace(respBody))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error %s %s %s", method, resp.Status, bytes.TrimSpace(respBody))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
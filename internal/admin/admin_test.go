@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	certPath := filepath.Join(t.TempDir(), "server.crt")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(certPath, cert, 0o600))
+
+	cli, err := NewClient(server.URL, "admin-secret", certPath)
+	require.NoError(t, err)
+	return cli
+}
+
+func TestClient_Users(t *testing.T) {
+	cli := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "admin-secret", r.Header.Get(auth.AdminTokenHeader))
+		assert.Equal(t, "/api/admin/users", r.URL.Path)
+		_, _ = w.Write([]byte(`[{"id":1,"username":"alice","items":3,"storage_bytes":100,"disabled":true}]`))
+	})
+
+	users, err := cli.Users()
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "alice", users[0].Username)
+	assert.Equal(t, int64(100), users[0].StorageBytes)
+	assert.True(t, users[0].Disabled)
+}
+
+func TestClient_Delete(t *testing.T) {
+	var query string
+	cli := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/api/admin/users/bob", r.URL.Path)
+		query = r.URL.RawQuery
+		if query == "" {
+			_, _ = w.Write([]byte(`{"delete_at":"2030-01-02T03:04:05Z"}`))
+		}
+	})
+
+	deletion, err := cli.Delete("bob", false)
+	require.NoError(t, err)
+	assert.Equal(t, 2030, deletion.DeleteAt.Year())
+
+	deletion, err = cli.Delete("bob", true)
+	require.NoError(t, err)
+	assert.Nil(t, deletion)
+	assert.Equal(t, "now=true", query)
+}
+
+func TestClient_Errors(t *testing.T) {
+	cli := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/admin/users/bob/disable" {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Admin not authenticated", http.StatusUnauthorized)
+	})
+
+	err := cli.Disable("bob")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	_, err = cli.Logout("bob")
+	assert.ErrorContains(t, err, "Admin not authenticated")
+}
+
+func TestClient_CreateInvite(t *testing.T) {
+	cli := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/admin/invites", r.URL.Path)
+		var invite types.NewInvite
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&invite))
+		assert.Equal(t, types.NewInvite{MaxUses: 3, ExpiresIn: "48h"}, invite)
+		_, _ = w.Write([]byte(`{"id":4,"code":"abc","expires_at":"2030-01-02T03:04:05Z"}`))
+	})
+
+	created, err := cli.CreateInvite(types.NewInvite{MaxUses: 3, ExpiresIn: "48h"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, created.ID)
+	assert.Equal(t, "abc", created.Code)
+}
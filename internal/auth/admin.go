@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// AdminTokenHeader заголовок с токеном администратора
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminMiddleware миддлвара для API администратора. Токен администратора не связан с учётными записями
+// пользователей и задаётся в настройках сервера
+type AdminMiddleware struct {
+	// Token токен администратора, пустой - API администратора выключено
+	Token string
+}
+
+// Handle пропускает запрос, только если в нём передан токен администратора
+func (m AdminMiddleware) Handle(next http.Handler) http.Handler {
+
+	authenticate := func(w http.ResponseWriter, r *http.Request) {
+
+		if m.Token == "" {
+			http.NotFound(w, r)
+			return
+		}
+		token := r.Header.Get(AdminTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(m.Token)) != 1 {
+			http.Error(w, "Admin not authenticated", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+
+	}
+	return http.HandlerFunc(authenticate)
+}
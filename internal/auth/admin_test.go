@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminMiddleware_Handle(t *testing.T) {
+	tests := []struct {
+		name               string
+		configured         string
+		token              string
+		expectedStatusCode int
+	}{
+		{"good token", "admin-secret", "admin-secret", http.StatusOK},
+		{"wrong token", "admin-secret", "secret", http.StatusUnauthorized},
+		{"no token", "admin-secret", "", http.StatusUnauthorized},
+		{"disabled", "", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+			if tt.token != "" {
+				r.Header.Set(AdminTokenHeader, tt.token)
+			}
+			w := httptest.NewRecorder()
+			AdminMiddleware{Token: tt.configured}.Handle(next).ServeHTTP(w, r)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedStatusCode == http.StatusOK, called)
+		})
+	}
+}
@@ -5,28 +5,46 @@ import (
 	"net/http"
 )
 
+// SessionChecker проверяет, что сессия пользователя не завершена, и отмечает её активность
+type SessionChecker interface {
+	TouchSession(ctx context.Context, username string, session string, remoteAddr string) error
+}
+
 // AuthenticateMiddleware миддлвара для аутентификации пользователя
 type AuthenticateMiddleware struct {
 	Secret []byte
+	// Sessions проверка сессий, nil - принимается любой подписанный токен
+	Sessions SessionChecker
 }
 
 // UserKey ключ для хранения в контексте запроса имени пользователя
 type UserKey string
 
-const contextKey UserKey = "username"
+const (
+	contextKey UserKey = "username"
+	sessionKey UserKey = "session"
+)
 
 // Handle хедер, проверяющий токен юзера и сохраняющий в контексте запроса юзернейм
 func (m AuthenticateMiddleware) Handle(next http.Handler) http.Handler {
 
 	authenticate := func(w http.ResponseWriter, r *http.Request) {
 
-		user, err := VerifyUser(r, m.Secret)
+		claims, err := VerifyClaims(r, m.Secret)
 		if err != nil {
 			http.Error(w, "User not authenticated", http.StatusUnauthorized)
 			return
 		}
+		if m.Sessions != nil {
+			err = m.Sessions.TouchSession(r.Context(), claims.Username, claims.ID, r.RemoteAddr)
+			if err != nil {
+				http.Error(w, "Session expired or signed out", http.StatusUnauthorized)
+				return
+			}
+		}
 
-		ctx := context.WithValue(r.Context(), contextKey, user)
+		ctx := context.WithValue(r.Context(), contextKey, claims.Username)
+		ctx = context.WithValue(ctx, sessionKey, claims.ID)
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r)
 
@@ -40,3 +58,9 @@ func GetAuthenticatedUser(req *http.Request) (string, bool) {
 	return user, ok
 
 }
+
+// GetSessionID получает id сессии из контекста запроса
+func GetSessionID(req *http.Request) (string, bool) {
+	session, ok := req.Context().Value(sessionKey).(string)
+	return session, ok && session != ""
+}
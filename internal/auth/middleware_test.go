@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -119,3 +120,48 @@ func TestAuthenticateMiddleware_Handle(t *testing.T) {
 		})
 	}
 }
+
+type testSessions struct {
+	active string
+}
+
+func (s testSessions) TouchSession(_ context.Context, _ string, session string, _ string) error {
+	if session != s.active {
+		return fmt.Errorf("session %s signed out", session)
+	}
+	return nil
+}
+
+func TestAuthenticateMiddleware_Sessions(t *testing.T) {
+	secret := []byte("secret")
+	current, _ := BuildSessionJWTString("user", "5", secret)
+	revoked, _ := BuildSessionJWTString("user", "6", secret)
+	legacy, _ := BuildJWTString("user", secret)
+
+	tests := []struct {
+		name               string
+		token              string
+		expectedStatusCode int
+	}{
+		{"active", current, http.StatusOK},
+		{"revoked", revoked, http.StatusUnauthorized},
+		{"noSession", legacy, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var session string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				session, _ = GetSessionID(r)
+			})
+			m := AuthenticateMiddleware{Secret: secret, Sessions: testSessions{active: "5"}}
+			r := httptest.NewRequest(http.MethodGet, "/api/", nil)
+			r.Header.Set(AuthHeader, tt.token)
+			w := httptest.NewRecorder()
+			m.Handle(next).ServeHTTP(w, r)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, "5", session)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nbutton23/zxcvbn-go"
+)
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 64
+	// maxPasswordBytes bcrypt не принимает пароли длиннее
+	maxPasswordBytes = 72
+)
+
+// ErrUsernameInvalid логин не подходит под правила
+var ErrUsernameInvalid = fmt.Errorf("login must be %d-%d characters: latin letters, digits, '.', '_', '-' or '@'",
+	minUsernameLength, maxUsernameLength)
+
+// NormalizeUsername приводит логин к каноническому виду: без пробелов по краям и в нижнем регистре,
+// чтобы Alice и alice не оказались разными пользователями. Логин из других символов - ErrUsernameInvalid
+func NormalizeUsername(username string) (string, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if len(username) < minUsernameLength || len(username) > maxUsernameLength {
+		return "", ErrUsernameInvalid
+	}
+	for _, r := range username {
+		valid := (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || strings.ContainsRune("._-@", r)
+		if !valid {
+			return "", ErrUsernameInvalid
+		}
+	}
+	return username, nil
+}
+
+// PasswordPolicy требования к паролю. Нулевое значение принимает любой непустой пароль
+type PasswordPolicy struct {
+	// MinLength минимальная длина в символах
+	MinLength int
+	// MinScore минимальная оценка стойкости zxcvbn от 0 до 4
+	MinScore int
+}
+
+// PasswordPolicyError пароль не подходит под требования, Reason объясняет почему
+type PasswordPolicyError struct {
+	Reason string
+}
+
+// Error стандартный метод интерфейса error
+func (e *PasswordPolicyError) Error() string {
+	return e.Reason
+}
+
+// Check проверяет пароль пользователя username
+func (p PasswordPolicy) Check(password string, username string) error {
+	if len(password) > maxPasswordBytes {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("Password is too long: at most %d bytes", maxPasswordBytes)}
+	}
+	if len([]rune(password)) < p.MinLength {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("Password is too short: at least %d characters", p.MinLength)}
+	}
+	if p.MinScore <= 0 {
+		return nil
+	}
+	result := zxcvbn.PasswordStrength(password, []string{username})
+	if result.Score < p.MinScore {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("Password is too weak: it can be guessed in %s, "+
+			"use a longer passphrase", result.CrackTimeDisplay)}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     string
+		wantErr  bool
+	}{
+		{"lower", "alice", "alice", false},
+		{"upper and spaces", "  Alice.Smith@example.com ", "alice.smith@example.com", false},
+		{"too short", "al", "", true},
+		{"spaces inside", "alice smith", "", true},
+		{"not latin", "алиса", "", true},
+		{"too long", string(make([]byte, 65)), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeUsername(tt.username)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUsernameInvalid)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 10, MinScore: 3}
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		wantErr  string
+	}{
+		{"strong", policy, "correct horse battery staple", ""},
+		{"short", policy, "x7#Kq", "too short"},
+		{"weak", policy, "password123", "too weak"},
+		{"username", policy, "alicealice1", "too weak"},
+		{"too long", policy, string(make([]byte, 73)), "too long"},
+		{"no policy", PasswordPolicy{}, "pass", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.password, "alice")
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var policyErr *PasswordPolicyError
+			assert.ErrorAs(t, err, &policyErr)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -18,22 +18,32 @@ const AuthHeader = "X-Auth-Token"
 
 // VerifyUser проверяет корректность токена и получает имя пользователя из него
 func VerifyUser(r *http.Request, secret []byte) (string, error) {
+	claims, err := VerifyClaims(r, secret)
+	if err != nil {
+		return "", err
+	}
+	return claims.Username, nil
+}
+
+// VerifyClaims проверяет корректность токена из заголовка запроса и возвращает его содержимое
+func VerifyClaims(r *http.Request, secret []byte) (*Claims, error) {
 	token := r.Header.Get(AuthHeader)
-	if token != "" {
-		user, err := GetUser(token, secret)
-		if err != nil {
-			return user, err
-		}
-		return user, nil
+	if token == "" {
+		return nil, fmt.Errorf("no auth token")
 	}
-	return "", fmt.Errorf("no auth token")
+	return GetClaims(token, secret)
 }
 
 // BuildJWTString создаёт JWT-токен, содержащий имя пользователя
 func BuildJWTString(user string, secret []byte) (string, error) {
+	return BuildSessionJWTString(user, "", secret)
+}
+
+// BuildSessionJWTString создаёт JWT-токен, содержащий имя пользователя и id сессии
+func BuildSessionJWTString(user string, session string, secret []byte) (string, error) {
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
-		RegisteredClaims: jwt.RegisteredClaims{},
+		RegisteredClaims: jwt.RegisteredClaims{ID: session},
 
 		Username: user,
 	})
@@ -48,6 +58,15 @@ func BuildJWTString(user string, secret []byte) (string, error) {
 
 // GetUser парсит токен, проверяет его подпись и извлекает имя пользователя
 func GetUser(tokenString string, secret []byte) (string, error) {
+	claims, err := GetClaims(tokenString, secret)
+	if err != nil {
+		return "", err
+	}
+	return claims.Username, nil
+}
+
+// GetClaims парсит токен, проверяет его подпись и возвращает его содержимое
+func GetClaims(tokenString string, secret []byte) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims,
 		func(t *jwt.Token) (interface{}, error) {
@@ -57,14 +76,14 @@ func GetUser(tokenString string, secret []byte) (string, error) {
 			return secret, nil
 		})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if !token.Valid {
-		return "", fmt.Errorf("token invalid")
+		return nil, fmt.Errorf("token invalid")
 	}
 
-	return claims.Username, nil
+	return claims, nil
 }
 
 // SetToken устанавливает заголовк авторизации с токеном
@@ -77,3 +96,14 @@ func SetToken(username string, w http.ResponseWriter, secret []byte) error {
 	w.Header().Set(AuthHeader, token)
 	return nil
 }
+
+// SetSessionToken устанавливает заголовок авторизации с токеном сессии
+func SetSessionToken(username string, session string, w http.ResponseWriter, secret []byte) error {
+
+	token, err := BuildSessionJWTString(username, session, secret)
+	if err != nil {
+		return err
+	}
+	w.Header().Set(AuthHeader, token)
+	return nil
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// DeleteAccount запрос на удаление учётной записи, подтверждённый паролем. Возвращает время удаления:
+// до него удаление можно отменить
+func (c *Client) DeleteAccount(token string, password string) (time.Time, error) {
+	var deletion types.AccountDeletion
+	err := c.requestJSON(token, http.MethodDelete, "/api/user", types.DeleteAccount{Password: password}, http.StatusOK, &deletion)
+	return deletion.DeleteAt, err
+}
+
+// AccountDeletion время запланированного удаления учётной записи, nil - удаление не запланировано
+func (c *Client) AccountDeletion(token string) (*time.Time, error) {
+	var deletion types.AccountDeletion
+	err := c.requestJSON(token, http.MethodGet, "/api/user/deletion", nil, http.StatusOK, &deletion)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deletion.DeleteAt, nil
+}
+
+// CancelAccountDeletion отмена запланированного удаления учётной записи
+func (c *Client) CancelAccountDeletion(token string) error {
+	return c.requestJSON(token, http.MethodDelete, "/api/user/deletion", nil, http.StatusOK, nil)
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func TestClient_AccountDeletion(t *testing.T) {
+	deleteAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	var scheduled bool
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/user":
+			var request types.DeleteAccount
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			assert.Equal(t, "pass", request.Password)
+			scheduled = true
+			_ = json.NewEncoder(w).Encode(types.AccountDeletion{DeleteAt: deleteAt})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/user/deletion":
+			if !scheduled {
+				http.Error(w, "Deletion is not scheduled", http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(types.AccountDeletion{DeleteAt: deleteAt})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer svr.Close()
+
+	c, _ := NewClient(conf)
+	c.address = svr.URL
+
+	got, err := c.AccountDeletion("token")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	at, err := c.DeleteAccount("token", "pass")
+	require.NoError(t, err)
+	assert.True(t, deleteAt.Equal(at))
+
+	got, err = c.AccountDeletion("token")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, deleteAt.Equal(*got))
+}
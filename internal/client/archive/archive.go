@@ -0,0 +1,157 @@
+// Package archive резервная копия всех записей пользователя в одном переносимом файле.
+// Содержимое (JSON, сжатый gzip) шифруется AES-256-GCM ключом, полученным из парольной фразы через scrypt.
+// Заголовок файла не шифруется, но защищён от подмены как дополнительные данные GCM
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+)
+
+// magic сигнатура файла архива
+const magic = "GKBACKUP"
+
+// version версия формата архива
+const version = 1
+
+// параметры scrypt: N = 2^scryptLogN
+const (
+	scryptLogN = 16
+	scryptR    = 8
+	scryptP    = 1
+	// maxLogN ограничение при чтении, чтобы испорченный заголовок не заставил выделить гигабайты памяти
+	maxLogN = 22
+	keySize = 32
+)
+
+const (
+	saltSize   = 16
+	nonceSize  = 12
+	headerSize = len(magic) + 4 + saltSize + nonceSize
+)
+
+var (
+	// ErrNotArchive файл не является архивом gophkeeper
+	ErrNotArchive = errors.New("not a gophkeeper backup archive")
+	// ErrVersion архив создан более новой версией клиента
+	ErrVersion = errors.New("unsupported backup archive version")
+	// ErrDecrypt неверная парольная фраза или архив повреждён
+	ErrDecrypt = errors.New("wrong passphrase or corrupted archive")
+)
+
+// Content расшифрованное содержимое архива
+type Content struct {
+	CreatedAt time.Time         `json:"created_at"`
+	Records   []importer.Record `json:"records"`
+}
+
+// Write шифрует записи парольной фразой и записывает архив
+func Write(w io.Writer, records []importer.Record, passphrase string, now time.Time) error {
+	var plain bytes.Buffer
+	zw := gzip.NewWriter(&plain)
+	err := json.NewEncoder(zw).Encode(Content{CreatedAt: now.UTC(), Records: records})
+	if err != nil {
+		return err
+	}
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	header[len(magic)] = version
+	header[len(magic)+1] = scryptLogN
+	header[len(magic)+2] = scryptR
+	header[len(magic)+3] = scryptP
+	_, err = rand.Read(header[len(magic)+4:])
+	if err != nil {
+		return err
+	}
+
+	aead, err := newAEAD(passphrase, header)
+	if err != nil {
+		return err
+	}
+	nonce := header[headerSize-nonceSize:]
+	sealed := aead.Seal(nil, nonce, plain.Bytes(), header)
+
+	_, err = w.Write(header)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sealed)
+	return err
+}
+
+// Read читает и расшифровывает архив
+func Read(r io.Reader, passphrase string) (*Content, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize || string(data[:len(magic)]) != magic {
+		return nil, ErrNotArchive
+	}
+	header := data[:headerSize]
+	if header[len(magic)] != version {
+		return nil, fmt.Errorf("%w: %d", ErrVersion, header[len(magic)])
+	}
+
+	aead, err := newAEAD(passphrase, header)
+	if err != nil {
+		return nil, err
+	}
+	nonce := header[headerSize-nonceSize:]
+	plain, err := aead.Open(nil, nonce, data[headerSize:], header)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(plain))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var content Content
+	err = json.NewDecoder(zr).Decode(&content)
+	if err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+// newAEAD получает ключ из парольной фразы с параметрами scrypt и солью из заголовка
+func newAEAD(passphrase string, header []byte) (cipher.AEAD, error) {
+	logN := header[len(magic)+1]
+	if logN == 0 || logN > maxLogN {
+		return nil, ErrNotArchive
+	}
+	// память scrypt растёт как 128*r*N, а время ещё и с p, поэтому r и p не берутся из заголовка как есть
+	if header[len(magic)+2] != scryptR || header[len(magic)+3] != scryptP {
+		return nil, ErrNotArchive
+	}
+	salt := header[len(magic)+4 : len(magic)+4+saltSize]
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<logN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,76 @@
+package archive
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func TestRoundTrip(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	note := types.TextData("some note")
+	file := types.BinaryData{0, 1, 2, 255}
+	records := []importer.Record{
+		{
+			Item:  types.Item{Key: "mail", Type: types.TypeLogoPass, Info: "info", ExpiresAt: "2030-01-31", RotateDays: "90", UpdatedAt: &now},
+			Login: &types.LoginPassword{Login: "me", Password: "pw", TOTP: "JBSWY3DPEHPK3PXP"},
+			Attachments: append(importer.Notes("https://mail.example", "pin"),
+				importer.Attachment{Name: "scan.png", MimeType: "image/png", Data: []byte{0, 1, 2, 255}}),
+		},
+		{
+			Item: types.Item{Key: "visa", Type: types.TypeCreditCard},
+			Card: &types.CreditCardData{Number: "4111111111111111", Name: "J DOE", ValidMonth: "7", ValidYear: "2030", CVC: "123"},
+		},
+		{Item: types.Item{Key: "note", Type: types.TypeText}, Text: &note},
+		{Item: types.Item{Key: "file", Type: types.TypeBinary}, Binary: &file, Meta: types.NewBinaryMeta("a.bin", file)},
+		{Item: types.Item{Key: "ssh", Type: types.TypeSSHKey}, SSHKey: &types.SSHKeyData{PrivateKey: "private", Comment: "c"}},
+		{Item: types.Item{Key: "2fa", Type: types.TypeTOTP}, TOTP: &types.TOTPData{Secret: "JBSWY3DPEHPK3PXP"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, records, "correct horse", now))
+	assert.NotContains(t, buf.String(), "4111111111111111")
+
+	content, err := Read(bytes.NewReader(buf.Bytes()), "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, now, content.CreatedAt)
+	assert.Equal(t, records, content.Records)
+
+	_, err = Read(bytes.NewReader(buf.Bytes()), "wrong")
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	// заголовок защищён от подмены
+	tampered := bytes.Clone(buf.Bytes())
+	tampered[len(magic)+4] ^= 1
+	_, err = Read(bytes.NewReader(tampered), "correct horse")
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestReadNotArchive(t *testing.T) {
+	_, err := Read(bytes.NewReader([]byte("hello")), "x")
+	assert.ErrorIs(t, err, ErrNotArchive)
+
+	data := make([]byte, headerSize+16)
+	copy(data, magic)
+	data[len(magic)] = version + 1
+	_, err = Read(bytes.NewReader(data), "x")
+	assert.ErrorIs(t, err, ErrVersion)
+
+	// завышенные параметры scrypt отклоняются до вычисления ключа
+	data[len(magic)] = version
+	data[len(magic)+1] = scryptLogN
+	data[len(magic)+2] = 255
+	data[len(magic)+3] = scryptP
+	_, err = Read(bytes.NewReader(data), "x")
+	assert.ErrorIs(t, err, ErrNotArchive)
+	data[len(magic)+2] = scryptR
+	data[len(magic)+3] = 255
+	_, err = Read(bytes.NewReader(data), "x")
+	assert.ErrorIs(t, err, ErrNotArchive)
+}
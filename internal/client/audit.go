@@ -0,0 +1,15 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// AuditEvents получение страницы событий журнала аудита пользователя, от новых к старым
+func (c *Client) AuditEvents(token string, page int, limit int) ([]types.AuditEvent, error) {
+	var events []types.AuditEvent
+	err := c.requestJSON(token, http.MethodGet, fmt.Sprintf("/api/audit?page=%d&limit=%d", page, limit), nil, http.StatusOK, &events)
+	return events, err
+}
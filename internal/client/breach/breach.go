@@ -0,0 +1,224 @@
+// Package breach проверяет пароли по локальному списку утёкших хешей в формате Have I Been Pwned
+// (строки HASH:COUNT, отсортированные по хешу). Поддерживаются SHA-1 и NTLM.
+// Файл не загружается в память - поиск идёт бинарным поиском по смещениям в файле, сеть не нужна
+package breach
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// Kind тип хешей в файле
+type Kind string
+
+const (
+	KindSHA1 Kind = "sha1"
+	KindNTLM Kind = "ntlm"
+)
+
+// ErrFormat файл не похож на список хешей HIBP
+var ErrFormat = errors.New("unknown hash file format: expected sorted SHA-1 or NTLM hashes")
+
+// maxLine строки HIBP намного короче: 40 символов хеша, двоеточие и счётчик
+const maxLine = 128
+
+// Checker проверка паролей по открытому файлу хешей
+type Checker struct {
+	file *os.File
+	size int64
+	kind Kind
+}
+
+// Open открывает файл хешей и определяет тип хешей по первой строке
+func Open(path string) (*Checker, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	c := &Checker{file: file, size: stat.Size()}
+	line, _, err := c.readLine(0)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	hash, _ := splitLine(line)
+	switch len(hash) {
+	case 2 * sha1.Size:
+		c.kind = KindSHA1
+	case 2 * md4.Size:
+		c.kind = KindNTLM
+	default:
+		_ = file.Close()
+		return nil, ErrFormat
+	}
+	return c, nil
+}
+
+// Close закрывает файл
+func (c *Checker) Close() error {
+	return c.file.Close()
+}
+
+// Kind тип хешей в файле
+func (c *Checker) Kind() Kind {
+	return c.kind
+}
+
+// Count сколько раз пароль встречался в утечках. 0 - пароль в списке не найден
+func (c *Checker) Count(password string) (int, error) {
+	return c.lookup(Hash(c.kind, password))
+}
+
+// Hash хеш пароля в том виде, в котором он хранится в файлах HIBP (hex в верхнем регистре)
+func Hash(kind Kind, password string) string {
+	if kind == KindNTLM {
+		h := md4.New()
+		for _, r := range utf16.Encode([]rune(password)) {
+			_, _ = h.Write([]byte{byte(r), byte(r >> 8)})
+		}
+		return strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+	}
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// lookup бинарный поиск строки с хешем по смещениям в файле. На каждом шаге сравнивается
+// первая строка, начинающаяся не раньше середины интервала [lo, hi)
+func (c *Checker) lookup(hash string) (int, error) {
+	lo, hi := int64(0), c.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, err := c.lineStart(mid)
+		if err != nil {
+			return 0, err
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+		line, end, err := c.readLine(start)
+		if err != nil {
+			return 0, err
+		}
+		found, count := splitLine(line)
+		switch cmp := strings.Compare(strings.ToUpper(found), hash); {
+		case cmp == 0:
+			return count, nil
+		case cmp < 0:
+			lo = end
+		default:
+			hi = mid
+		}
+	}
+	return 0, nil
+}
+
+// lineStart начало первой строки, начинающейся не раньше pos
+func (c *Checker) lineStart(pos int64) (int64, error) {
+	if pos == 0 {
+		return 0, nil
+	}
+	buf := make([]byte, maxLine)
+	n, err := c.file.ReadAt(buf, pos-1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	i := bytes.IndexByte(buf[:n], '\n')
+	if i < 0 {
+		return c.size, nil
+	}
+	return pos + int64(i), nil
+}
+
+// readLine читает строку, начинающуюся в start. Возвращает строку и начало следующей
+func (c *Checker) readLine(start int64) (string, int64, error) {
+	buf := make([]byte, maxLine)
+	n, err := c.file.ReadAt(buf, start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", 0, err
+	}
+	i := bytes.IndexByte(buf[:n], '\n')
+	if i < 0 {
+		if start+int64(n) < c.size {
+			return "", 0, fmt.Errorf("line at offset %d is too long: %w", start, ErrFormat)
+		}
+		return string(buf[:n]), c.size, nil
+	}
+	return string(buf[:i]), start + int64(i) + 1, nil
+}
+
+// splitLine разбирает строку HASH:COUNT. Без счётчика считается, что пароль встречался один раз
+func splitLine(line string) (string, int) {
+	line = strings.TrimRight(line, "\r")
+	hash, countStr, ok := strings.Cut(line, ":")
+	if !ok {
+		return hash, 1
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count < 1 {
+		count = 1
+	}
+	return hash, count
+}
+
+// Finding запись, пароль которой найден в утечках
+type Finding struct {
+	Key   string `json:"key"`
+	Login string `json:"login"`
+	Count int    `json:"count"`
+}
+
+// String строковое представление, показываемое пользователю
+func (f Finding) String() string {
+	return fmt.Sprintf("%s (%s): password seen %d times in data breaches", f.Key, f.Login, f.Count)
+}
+
+// Scan проверяет пароли всех записей логинов и паролей пользователя
+func Scan(token string, pass string, cli *client.Client, checker *Checker) ([]Finding, error) {
+	items, err := cli.AllRecords(token, pass)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Finding{}
+	for _, item := range items {
+		if item.Type != types.TypeLogoPass {
+			continue
+		}
+		data, err := cli.GetItem(token, item.Key)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := types.ParseItem[*types.LoginPassword](data, pass)
+		if err != nil {
+			return nil, err
+		}
+		count, err := checker.Count(parsed.Data.Password)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			result = append(result, Finding{Key: item.Key, Login: parsed.Data.Login, Count: count})
+		}
+	}
+	return result, nil
+}
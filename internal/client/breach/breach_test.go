@@ -0,0 +1,123 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func writeHashFile(t *testing.T, lines []string, eol string) string {
+	sort.Strings(lines)
+	path := filepath.Join(t.TempDir(), "hashes.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, eol)+eol), 0600))
+	return path
+}
+
+func TestHash(t *testing.T) {
+	assert.Equal(t, "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8", Hash(KindSHA1, "password"))
+	assert.Equal(t, "8846F7EAEE8FB117AD06BDD830B7586C", Hash(KindNTLM, "password"))
+}
+
+func TestCount(t *testing.T) {
+	for _, eol := range []string{"\n", "\r\n"} {
+		lines := []string{}
+		for i := 0; i < 1000; i++ {
+			sum := sha1.Sum([]byte(fmt.Sprintf("pw%d", i)))
+			lines = append(lines, fmt.Sprintf("%s:%d", strings.ToUpper(hex.EncodeToString(sum[:])), i+1))
+		}
+		path := writeHashFile(t, lines, eol)
+
+		checker, err := Open(path)
+		require.NoError(t, err)
+		assert.Equal(t, KindSHA1, checker.Kind())
+
+		for i := 0; i < 1000; i++ {
+			count, err := checker.Count(fmt.Sprintf("pw%d", i))
+			require.NoError(t, err)
+			assert.Equal(t, i+1, count)
+		}
+		for i := 1000; i < 1100; i++ {
+			count, err := checker.Count(fmt.Sprintf("pw%d", i))
+			require.NoError(t, err)
+			assert.Equal(t, 0, count)
+		}
+		require.NoError(t, checker.Close())
+	}
+}
+
+func TestNTLM(t *testing.T) {
+	path := writeHashFile(t, []string{"8846F7EAEE8FB117AD06BDD830B7586C:42", "00000000000000000000000000000000:1"}, "\n")
+	checker, err := Open(path)
+	require.NoError(t, err)
+	defer checker.Close()
+
+	assert.Equal(t, KindNTLM, checker.Kind())
+	count, err := checker.Count("password")
+	require.NoError(t, err)
+	assert.Equal(t, 42, count)
+}
+
+func TestOpenBadFormat(t *testing.T) {
+	path := writeHashFile(t, []string{"not a hash"}, "\n")
+	_, err := Open(path)
+	assert.ErrorIs(t, err, ErrFormat)
+}
+
+func TestScan(t *testing.T) {
+	pass := "secret"
+	path := writeHashFile(t, []string{Hash(KindSHA1, "123456") + ":37359195"}, "\n")
+	checker, err := Open(path)
+	require.NoError(t, err)
+	defer checker.Close()
+
+	items := []types.Item{
+		{Key: "weak", Type: types.TypeLogoPass},
+		{Key: "good", Type: types.TypeLogoPass},
+		{Key: "note", Type: types.TypeText},
+	}
+	for i := range items {
+		require.NoError(t, items[i].Encrypt(pass))
+	}
+	weak := types.LoginPassword{Login: "me", Password: "123456"}
+	require.NoError(t, weak.Encrypt(pass))
+	good := types.LoginPassword{Login: "me", Password: "Tz7#q!Lm0v@R2x^W"}
+	require.NoError(t, good.Encrypt(pass))
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/item/list":
+			_ = json.NewEncoder(w).Encode(items)
+		case "/api/item/weak":
+			_ = json.NewEncoder(w).Encode(types.LoginPasswordItem{Item: items[0], Data: &weak})
+		case "/api/item/good":
+			_ = json.NewEncoder(w).Encode(types.LoginPasswordItem{Item: items[1], Data: &good})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	conf, _ := config.NewClientConfig()
+	conf.ServerAddress = svr.URL
+	conf.SSLKey = "../../../.ssl/ca.key"
+	cli, err := client.NewClient(conf)
+	require.NoError(t, err)
+
+	got, err := Scan("token", pass, cli, checker)
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{{Key: "weak", Login: "me", Count: 37359195}}, got)
+}
@@ -6,24 +6,70 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
+	"net/url"
 	"os"
+	"runtime"
+	"strconv"
 
 	"github.com/wellywell/gophkeeper/internal/config"
 	"github.com/wellywell/gophkeeper/internal/types"
 )
 
-const Token = "X-Auth-Token"
+const (
+	Token                = "X-Auth-Token"
+	SizeHeader           = "X-Content-Size"
+	SHA256Header         = "X-Content-Sha256"
+	DeviceHeader         = "X-Device-Name"
+	VersionHeader        = "X-Client-Version"
+	DeviceKeyHeader      = "X-Device-Key"
+	DeviceNonceHeader    = "X-Device-Nonce"
+	DeviceProofHeader    = "X-Device-Proof"
+	EnrollmentHeader     = "X-Device-Enrollment"
+	AttachmentNameHeader = "X-Attachment-Name"
+	AttachmentTypeHeader = "X-Attachment-Type"
+)
 
 // Client тип для работы с http-клиетом
 type Client struct {
 	address string
 	client  *http.Client
+	// vault путь, по которому читаются записи: личное хранилище или хранилище, открытое через экстренный доступ
+	vault string
+	// device и version описывают устройство в сессии, которую сервер заводит при входе
+	device  string
+	version string
+	// deviceKey открытый ключ устройства: по нему сервер узнаёт устройство, вход с нового ждёт подтверждения
+	deviceKey string
+	// devicePrivate закрытый ключ устройства, им устройство отвечает на вызов сервера при входе
+	devicePrivate []byte
+	// invite код приглашения, который отправляется при регистрации
+	invite string
+	// enrollment код администратора, который подтверждает первое устройство после сброса устройств
+	enrollment string
+}
+
+// ErrInviteRequired сервер регистрирует только по приглашению
+var ErrInviteRequired = errors.New("registration requires an invite")
+
+// ErrEnrollmentRequired устройства сброшены администратором, для входа нужен выданный им код
+var ErrEnrollmentRequired = errors.New("devices were reset by the administrator, login requires the enrollment code")
+
+// RejectedError сервер отклонил логин или пароль, Message - объяснение сервера, которое стоит показать пользователю
+type RejectedError struct {
+	Message string
+}
+
+// Error стандартный метод интерфейса error
+func (e *RejectedError) Error() string {
+	return e.Message
 }
 
 // NewClient инициализирует клиент
@@ -43,13 +89,41 @@ func NewClient(conf *config.ClientConfig) (*Client, error) {
 			},
 		},
 	}
+	device, err := os.Hostname()
+	if err != nil {
+		device = "unknown"
+	}
 	return &Client{
 		address: conf.ServerAddress,
 		client:  client,
+		vault:   "/api/item",
+		device:  fmt.Sprintf("%s (%s/%s)", device, runtime.GOOS, runtime.GOARCH),
 	}, nil
 
 }
 
+// SetVersion задаёт версию клиента, которую сервер показывает в списке сессий
+func (c *Client) SetVersion(version string) {
+	c.version = version
+}
+
+// SetDeviceKey задаёт открытый ключ устройства в base64, который отправляется при входе, и закрытый ключ,
+// которым устройство доказывает, что ключ его
+func (c *Client) SetDeviceKey(publicKey string, privateKey []byte) {
+	c.deviceKey = publicKey
+	c.devicePrivate = privateKey
+}
+
+// SetInvite задаёт код приглашения для регистрации
+func (c *Client) SetInvite(code string) {
+	c.invite = code
+}
+
+// SetEnrollment задаёт код администратора для входа с первого устройства после сброса устройств
+func (c *Client) SetEnrollment(code string) {
+	c.enrollment = code
+}
+
 // Login авторизация пользователя на сервере и получение токена для последующих запросов
 func (c *Client) Login(login string, password string) (string, error) {
 	return c.getAuthToken(login, password, "login")
@@ -85,10 +159,20 @@ func (c *Client) CreateTextItem(data []byte, headers map[string]string) (*http.R
 	return c.doRequest(fmt.Sprintf("%s/api/item/text", c.address), http.MethodPost, data, headers)
 }
 
+// CreateSSHKeyItem сохранение на сервере SSH-ключа
+func (c *Client) CreateSSHKeyItem(data []byte, headers map[string]string) (*http.Response, error) {
+	return c.doRequest(fmt.Sprintf("%s/api/item/ssh_key", c.address), http.MethodPost, data, headers)
+}
+
+// CreateTOTPItem сохранение на сервере TOTP-секрета
+func (c *Client) CreateTOTPItem(data []byte, headers map[string]string) (*http.Response, error) {
+	return c.doRequest(fmt.Sprintf("%s/api/item/totp", c.address), http.MethodPost, data, headers)
+}
+
 // GetItem получение с сервера данных произвольного типа (из числа поддерживаемых)
 func (c *Client) GetItem(token string, key string) (data []byte, err error) {
 
-	resp, err := c.doRequest(fmt.Sprintf("%s/api/item/%s", c.address, key), http.MethodGet, nil, map[string]string{Token: token})
+	resp, err := c.doRequest(fmt.Sprintf("%s%s/%s", c.address, c.vault, key), http.MethodGet, nil, map[string]string{Token: token})
 	if err != nil {
 		return nil, fmt.Errorf("could not make request %w", err)
 	}
@@ -107,7 +191,7 @@ func (c *Client) GetItem(token string, key string) (data []byte, err error) {
 // SeeRecords получение списка записей, хранимых на сервере
 func (c *Client) SeeRecords(token string, pass string, page int, pageSize int) ([]types.Item, error) {
 
-	resp, err := c.doRequest(fmt.Sprintf("%s/api/item/list?page=%d&limit=%d", c.address, page, pageSize), http.MethodGet, nil, map[string]string{Token: token})
+	resp, err := c.doRequest(fmt.Sprintf("%s%s/list?page=%d&limit=%d", c.address, c.vault, page, pageSize), http.MethodGet, nil, map[string]string{Token: token})
 	if err != nil {
 		return nil, fmt.Errorf("could not make request %w", err)
 	}
@@ -126,30 +210,56 @@ func (c *Client) SeeRecords(token string, pass string, page int, pageSize int) (
 	if err != nil {
 		return nil, err
 	}
+	for i := range items {
+		err = items[i].Decrypt(pass)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return items, nil
 }
 
 // DownloadBinaryData cкачивание бинарных данных с сервера
 func (c *Client) DownloadBinaryData(token string, pass string, key string) (data []byte, err error) {
-	resp, err := c.doRequest(fmt.Sprintf("%s/api/item/binary/%s/download", c.address, key), http.MethodGet, nil, map[string]string{Token: token})
+	data, _, err = c.DownloadBinaryItem(token, pass, key)
+	return data, err
+}
+
+// DownloadBinaryItem cкачивание бинарных данных с сервера вместе с метаданными исходного файла.
+// Если метаданные были сохранены, после расшифровки проверяется целостность данных
+func (c *Client) DownloadBinaryItem(token string, pass string, key string) ([]byte, *types.BinaryMeta, error) {
+	resp, err := c.doRequest(fmt.Sprintf("%s%s/binary/%s/download", c.address, c.vault, key), http.MethodGet, nil, map[string]string{Token: token})
 	if err != nil {
-		return nil, fmt.Errorf("could not make request %w", err)
+		return nil, nil, fmt.Errorf("could not make request %w", err)
 	}
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error fetching item %s %s", resp.Status, bodyBytes)
+		return nil, nil, fmt.Errorf("error fetching item %s %s", resp.Status, bodyBytes)
 	}
 
 	result := types.BinaryData(bodyBytes)
 	err = result.Decrypt(pass)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return result, nil
+
+	meta, err := parseBinaryMeta(resp.Header)
+	if err != nil || meta == nil {
+		return result, nil, err
+	}
+	err = meta.Decrypt(pass)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = meta.Verify(result)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, meta, nil
 }
 
 // DeleteItem удаление данных с сервера
@@ -171,6 +281,149 @@ func (c *Client) DeleteItem(token string, key string) error {
 	return nil
 }
 
+// UploadAttachment прикрепляет к записи key вложение, предварительно зашифровав его данные, имя и MIME-тип.
+// Возвращает идентификатор вложения на сервере
+func (c *Client) UploadAttachment(token string, pass string, key string, attachment types.Attachment, data []byte) (int, error) {
+	enc, headers, err := attachmentRequest(token, pass, attachment, data)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.doRequest(fmt.Sprintf("%s/api/item/%s/attachments", c.address, url.PathEscape(key)), http.MethodPost, enc, headers)
+	if err != nil {
+		return 0, fmt.Errorf("could not make request %w", err)
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return 0, fmt.Errorf("error uploading attachment %s %s", resp.Status, bodyBytes)
+	}
+
+	var created types.CreatedAttachment
+	err = json.Unmarshal(bodyBytes, &created)
+	if err != nil {
+		return 0, err
+	}
+	return created.ID, nil
+}
+
+// ReplaceAttachment заменяет вложение id записи key, предварительно зашифровав его данные, имя и MIME-тип
+func (c *Client) ReplaceAttachment(token string, pass string, key string, attachment types.Attachment, data []byte) error {
+	enc, headers, err := attachmentRequest(token, pass, attachment, data)
+	if err != nil {
+		return err
+	}
+	resp, err := c.doRequest(c.attachmentURL(key, attachment.ID), http.MethodPut, enc, headers)
+	if err != nil {
+		return fmt.Errorf("could not make request %w", err)
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error replacing attachment %s %s", resp.Status, bodyBytes)
+	}
+	return nil
+}
+
+// attachmentRequest шифрует данные вложения и готовит заголовки с зашифрованными именем и MIME-типом
+func attachmentRequest(token string, pass string, attachment types.Attachment, data []byte) ([]byte, map[string]string, error) {
+	enc := types.BinaryData(data)
+	err := enc.Encrypt(pass)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not encrypt %w", err)
+	}
+	err = attachment.Encrypt(pass)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not encrypt %w", err)
+	}
+
+	headers := map[string]string{
+		Token:                token,
+		"Content-Type":       "application/octet-stream",
+		AttachmentNameHeader: attachment.Name,
+		AttachmentTypeHeader: attachment.MimeType,
+	}
+	return enc, headers, nil
+}
+
+// SeeAttachments получение списка вложений записи key с расшифровкой имён и MIME-типов
+func (c *Client) SeeAttachments(token string, pass string, key string) ([]types.Attachment, error) {
+	resp, err := c.doRequest(fmt.Sprintf("%s/api/item/%s/attachments", c.address, url.PathEscape(key)), http.MethodGet, nil, map[string]string{Token: token})
+	if err != nil {
+		return nil, fmt.Errorf("could not make request %w", err)
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching attachments %s %s", resp.Status, bodyBytes)
+	}
+
+	var attachments []types.Attachment
+
+	err = json.Unmarshal(bodyBytes, &attachments)
+	if err != nil {
+		return nil, err
+	}
+	for i := range attachments {
+		err = attachments[i].Decrypt(pass)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return attachments, nil
+}
+
+// DownloadAttachment скачивание и расшифровка вложения id записи key
+func (c *Client) DownloadAttachment(token string, pass string, key string, id int) (data []byte, err error) {
+	resp, err := c.doRequest(c.attachmentURL(key, id), http.MethodGet, nil, map[string]string{Token: token})
+	if err != nil {
+		return nil, fmt.Errorf("could not make request %w", err)
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching attachment %s %s", resp.Status, bodyBytes)
+	}
+
+	result := types.BinaryData(bodyBytes)
+	err = result.Decrypt(pass)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteAttachment удаление вложения id записи key
+func (c *Client) DeleteAttachment(token string, key string, id int) error {
+	resp, err := c.doRequest(c.attachmentURL(key, id), http.MethodDelete, nil, map[string]string{Token: token})
+	if err != nil {
+		return fmt.Errorf("could not make request %w", err)
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error deleting attachment %s %s", resp.Status, bodyBytes)
+	}
+	return nil
+}
+
 // UpdateLogoPassData обновление логина и пароля, хранимых на сервере
 func (c *Client) UpdateLogoPassData(data []byte, headers map[string]string) (*http.Response, error) {
 	return c.doRequest(fmt.Sprintf("%s/api/item/login_password", c.address), http.MethodPut, data, headers)
@@ -186,6 +439,32 @@ func (c *Client) UpdateTextData(data []byte, headers map[string]string) (*http.R
 	return c.doRequest(fmt.Sprintf("%s/api/item/text", c.address), http.MethodPut, data, headers)
 }
 
+// UpdateSSHKeyData обновление SSH-ключа
+func (c *Client) UpdateSSHKeyData(data []byte, headers map[string]string) (*http.Response, error) {
+	return c.doRequest(fmt.Sprintf("%s/api/item/ssh_key", c.address), http.MethodPut, data, headers)
+}
+
+// UpdateTOTPData обновление TOTP-секрета
+func (c *Client) UpdateTOTPData(data []byte, headers map[string]string) (*http.Response, error) {
+	return c.doRequest(fmt.Sprintf("%s/api/item/totp", c.address), http.MethodPut, data, headers)
+}
+
+// AllRecords получение списка всех записей пользователя, постранично запрашивая их с сервера
+func (c *Client) AllRecords(token string, pass string) ([]types.Item, error) {
+	pageSize := 100
+	var result []types.Item
+	for page := 1; ; page++ {
+		items, err := c.SeeRecords(token, pass, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+		if len(items) < pageSize {
+			return result, nil
+		}
+	}
+}
+
 // UpdateItem обобщенный метод для обновления данных типа T
 func UpdateItem[T types.ItemData](token string, pass string, newItem types.GenericItem[T], method func([]byte, map[string]string) (*http.Response, error)) error {
 
@@ -237,17 +516,25 @@ func (c *Client) getAuthToken(login string, password string, method string) (str
 	data := struct {
 		Login    string `json:"login"`
 		Password string `json:"password"`
+		Invite   string `json:"invite,omitempty"`
 	}{
 		Login:    login,
 		Password: password,
 	}
+	if method == "register" {
+		data.Invite = c.invite
+	}
 
 	request, err := json.Marshal(data)
 	if err != nil {
 		return "", fmt.Errorf("could not serialize data")
 	}
 
-	resp, err := c.client.Post(fmt.Sprintf("%s/api/user/%s", c.address, method), "application/json", bytes.NewBuffer(request))
+	headers, err := c.provenSessionHeaders()
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.doRequest(fmt.Sprintf("%s/api/user/%s", c.address, method), http.MethodPost, request, headers)
 
 	if err != nil {
 		log.Fatal(err)
@@ -257,7 +544,22 @@ func (c *Client) getAuthToken(login string, password string, method string) (str
 	if err != nil {
 		log.Fatal(err)
 	}
+	if resp.StatusCode == http.StatusAccepted {
+		return "", approvalRequired(resp.Body)
+	}
 	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		message := string(bytes.TrimSpace(bodyBytes))
+		switch {
+		case resp.StatusCode == http.StatusForbidden && message == types.InviteRequired:
+			return "", ErrInviteRequired
+		case resp.StatusCode == http.StatusForbidden && message == types.EnrollmentRequired:
+			return "", ErrEnrollmentRequired
+		case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusConflict:
+			return "", &RejectedError{Message: message}
+		case message != "":
+			return "", fmt.Errorf("not authenticated: %s", message)
+		}
 		return "", fmt.Errorf("not authenticated")
 	}
 
@@ -271,6 +573,10 @@ func (c *Client) getAuthToken(login string, password string, method string) (str
 func saveJSONItem[T types.ItemData](token string, pass string, newItem types.GenericItem[T], method func([]byte, map[string]string) (*http.Response, error)) (*http.Response, error) {
 	err := newItem.Data.Encrypt(pass)
 
+	if err != nil {
+		return nil, fmt.Errorf("could not encrypt %w", err)
+	}
+	err = newItem.Item.Encrypt(pass)
 	if err != nil {
 		return nil, fmt.Errorf("could not encrypt %w", err)
 	}
@@ -288,6 +594,10 @@ func saveJSONItem[T types.ItemData](token string, pass string, newItem types.Gen
 func saveBinaryItem[T types.BinaryData](token string, pass string, newItem types.GenericItem[*types.BinaryData], method func([]byte, map[string]string) (*http.Response, error)) (*http.Response, error) {
 	err := newItem.Data.Encrypt(pass)
 
+	if err != nil {
+		return nil, fmt.Errorf("could not encrypt %w", err)
+	}
+	err = newItem.Item.Encrypt(pass)
 	if err != nil {
 		return nil, fmt.Errorf("could not encrypt %w", err)
 	}
@@ -324,6 +634,29 @@ func saveBinaryItem[T types.BinaryData](token string, pass string, newItem types
 	if err != nil {
 		return nil, err
 	}
+
+	if newItem.Meta != nil {
+		err = newItem.Meta.Encrypt(pass)
+		if err != nil {
+			return nil, fmt.Errorf("could not encrypt %w", err)
+		}
+		meta, err := json.Marshal(newItem.Meta)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert %w", err)
+		}
+		// File metadata part
+		metaHeader := textproto.MIMEHeader{}
+		metaHeader.Set("Content-Type", "application/json")
+		metaHeader.Set("Content-Disposition", `form-data; name="meta"`)
+		metaPart, err := writer.CreatePart(metaHeader)
+		if err != nil {
+			return nil, err
+		}
+		_, err = metaPart.Write(meta)
+		if err != nil {
+			return nil, err
+		}
+	}
 	writer.Close()
 
 	headers := map[string]string{
@@ -352,6 +685,43 @@ func saveItem[T types.ItemData](token string, pass string, newItem types.Generic
 	return resp, err
 }
 
+func parseBinaryMeta(header http.Header) (*types.BinaryMeta, error) {
+	sum := header.Get(SHA256Header)
+	if sum == "" {
+		return nil, nil
+	}
+	_, params, err := mime.ParseMediaType(header.Get("Content-Disposition"))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse content disposition %w", err)
+	}
+	size, err := strconv.ParseInt(header.Get(SizeHeader), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse content size %w", err)
+	}
+	return &types.BinaryMeta{
+		FileName:    params["filename"],
+		ContentType: header.Get("Content-Type"),
+		Size:        size,
+		SHA256:      sum,
+	}, nil
+}
+
+func (c *Client) attachmentURL(key string, id int) string {
+	return fmt.Sprintf("%s/api/item/%s/attachments/%d", c.address, url.PathEscape(key), id)
+}
+
+// sessionHeaders заголовки запросов, после которых сервер заводит сессию
+func (c *Client) sessionHeaders() map[string]string {
+	headers := map[string]string{"Content-Type": "application/json", DeviceHeader: c.device, VersionHeader: c.version}
+	if c.deviceKey != "" {
+		headers[DeviceKeyHeader] = c.deviceKey
+	}
+	if c.enrollment != "" {
+		headers[EnrollmentHeader] = c.enrollment
+	}
+	return headers
+}
+
 func (c *Client) doRequest(URL string, method string, data []byte, headers map[string]string) (*http.Response, error) {
 	body := bytes.NewBuffer(data)
 
@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/encrypt"
 	"github.com/wellywell/gophkeeper/internal/types"
 )
 
@@ -125,6 +129,48 @@ func TestClient_Register(t *testing.T) {
 	}
 }
 
+func TestClient_Register_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		serverCode int
+		body       string
+		check      func(t *testing.T, err error)
+	}{
+		{"inviteRequired", http.StatusForbidden, types.InviteRequired, func(t *testing.T, err error) {
+			assert.ErrorIs(t, err, ErrInviteRequired)
+		}},
+		{"weakPassword", http.StatusBadRequest, "Password is too short: at least 10 characters", func(t *testing.T, err error) {
+			var rejected *RejectedError
+			assert.ErrorAs(t, err, &rejected)
+			assert.Equal(t, "Password is too short: at least 10 characters", rejected.Message)
+		}},
+		{"closed", http.StatusForbidden, "Registration is closed", func(t *testing.T, err error) {
+			assert.EqualError(t, err, "not authenticated: Registration is closed")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var invite string
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var data struct {
+					Invite string `json:"invite"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&data)
+				invite = data.Invite
+				http.Error(w, tt.body, tt.serverCode)
+			}))
+			defer svr.Close()
+
+			c, _ := NewClient(conf)
+			c.address = svr.URL
+			c.SetInvite("code")
+			_, err := c.Register("user", "pass")
+			tt.check(t, err)
+			assert.Equal(t, "code", invite)
+		})
+	}
+}
+
 func TestClient_CreateBinaryItem(t *testing.T) {
 
 	type args struct {
@@ -987,3 +1033,218 @@ func TestCreateItem_Binary(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_UploadDownloadAttachment(t *testing.T) {
+
+	var stored []byte
+	var listed types.Attachment
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("X-Auth-Token"))
+		switch r.Method {
+		case http.MethodPost:
+			assert.Equal(t, "/api/item/111/attachments", r.URL.Path)
+			listed = types.Attachment{ID: 5, Name: r.Header.Get(AttachmentNameHeader), MimeType: r.Header.Get(AttachmentTypeHeader), Encrypted: true}
+			stored, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":5}`))
+		case http.MethodGet:
+			if r.URL.Path == "/api/item/111/attachments" {
+				_ = json.NewEncoder(w).Encode([]types.Attachment{listed})
+				return
+			}
+			assert.Equal(t, "/api/item/111/attachments/5", r.URL.Path)
+			_, _ = w.Write(stored)
+		}
+	}))
+	defer svr.Close()
+
+	c, _ := NewClient(conf)
+	c.address = svr.URL
+
+	attachment := types.Attachment{Name: "doc.pdf", MimeType: "application/pdf", Size: 4}
+	id, err := c.UploadAttachment("token", "secret", "111", attachment, []byte("test"))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, id)
+	assert.NotEqual(t, []byte("test"), stored)
+	assert.NotContains(t, listed.Name, "doc")
+	assert.NotContains(t, listed.MimeType, "pdf")
+
+	attachments, err := c.SeeAttachments("token", "secret", "111")
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Attachment{{ID: 5, Name: "doc.pdf", MimeType: "application/pdf"}}, attachments)
+
+	data, err := c.DownloadAttachment("token", "secret", "111", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("test"), data)
+}
+
+func TestClient_SeeAttachments(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		respBody string
+		respCode int
+		want     []types.Attachment
+		wantErr  bool
+	}{
+		{"legacy", `[{"id":5,"name":"doc.pdf","mime_type":"application/pdf","size":4,"encrypted":false}]`, http.StatusOK, []types.Attachment{{ID: 5, Name: "doc.pdf", MimeType: "application/pdf", Size: 4}}, false},
+		{"notFound", "Not found", http.StatusNotFound, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/item/111/attachments", r.URL.Path)
+				w.WriteHeader(tt.respCode)
+				_, _ = w.Write([]byte(tt.respBody))
+			}))
+			defer svr.Close()
+
+			c, _ := NewClient(conf)
+			c.address = svr.URL
+			got, err := c.SeeAttachments("token", "secret", "111")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.SeeAttachments() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_DeleteAttachment(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		wantErr  bool
+		respCode int
+	}{
+		{"ok", false, http.StatusOK},
+		{"notFound", true, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/item/111/attachments/5", r.URL.Path)
+				assert.Equal(t, http.MethodDelete, r.Method)
+				w.WriteHeader(tt.respCode)
+			}))
+			defer svr.Close()
+
+			c, _ := NewClient(conf)
+			c.address = svr.URL
+			if err := c.DeleteAttachment("token", "111", 5); (err != nil) != tt.wantErr {
+				t.Errorf("Client.DeleteAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClient_ReplaceAttachment(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		wantErr  bool
+		respCode int
+	}{
+		{"ok", false, http.StatusOK},
+		{"notFound", true, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/item/111/attachments/5", r.URL.Path)
+				assert.Equal(t, http.MethodPut, r.Method)
+				name, err := encrypt.Decrypt(r.Header.Get(AttachmentNameHeader), "pass")
+				assert.NoError(t, err)
+				assert.Equal(t, "doc.pdf", name)
+				w.WriteHeader(tt.respCode)
+			}))
+			defer svr.Close()
+
+			c, _ := NewClient(conf)
+			c.address = svr.URL
+			attachment := types.Attachment{ID: 5, Name: "doc.pdf", MimeType: "application/pdf"}
+			if err := c.ReplaceAttachment("token", "pass", "111", attachment, []byte("data")); (err != nil) != tt.wantErr {
+				t.Errorf("Client.ReplaceAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClient_DownloadBinaryItem(t *testing.T) {
+
+	plain := []byte("some text")
+	meta := types.NewBinaryMeta("dir/doc.txt", plain)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantMeta bool
+		wantErr  error
+	}{
+		{"ok", plain, true, nil},
+		{"corrupted", []byte("other text"), false, types.ErrChecksumMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encMeta := *meta
+			_ = encMeta.Encrypt("secret")
+			enc := types.BinaryData(append([]byte{}, tt.data...))
+			_ = enc.Encrypt("secret")
+
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", encMeta.ContentType)
+				w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": encMeta.FileName}))
+				w.Header().Set(SizeHeader, strconv.FormatInt(encMeta.Size, 10))
+				w.Header().Set(SHA256Header, encMeta.SHA256)
+				_, _ = w.Write(enc)
+			}))
+			defer svr.Close()
+
+			c, _ := NewClient(conf)
+			c.address = svr.URL
+			data, gotMeta, err := c.DownloadBinaryItem("token", "secret", "111")
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantMeta {
+				assert.Equal(t, plain, data)
+				assert.Equal(t, meta, gotMeta)
+				assert.Equal(t, "doc.txt", gotMeta.FileName)
+			}
+		})
+	}
+}
+
+func TestCreateItem_BinaryWithMeta(t *testing.T) {
+
+	plain := []byte("some text")
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		var gotMeta *types.BinaryMeta
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			if part.FormName() == "meta" {
+				_ = json.NewDecoder(part).Decode(&gotMeta)
+			}
+		}
+		assert.NotNil(t, gotMeta)
+		assert.Equal(t, "text/plain; charset=utf-8", gotMeta.ContentType)
+		assert.NotEqual(t, "doc.txt", gotMeta.FileName)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer svr.Close()
+
+	c, _ := NewClient(conf)
+	c.address = svr.URL
+
+	data := types.BinaryData(plain)
+	item := types.GenericItem[*types.BinaryData]{Item: types.Item{Key: "111", Type: types.TypeBinary}, Data: &data, Meta: types.NewBinaryMeta("doc.txt", plain)}
+	err := CreateItem("token", "secret", item, c.CreateBinaryItem)
+	assert.NoError(t, err)
+}
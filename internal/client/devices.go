@@ -0,0 +1,138 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+var (
+	// ErrApprovalPending вход с нового устройства ещё не подтверждён
+	ErrApprovalPending = errors.New("login is waiting for approval")
+	// ErrLoginRejected вход с нового устройства отклонён
+	ErrLoginRejected = errors.New("login was rejected on another device")
+)
+
+// ApprovalRequiredError пароль верный, но вход с этого устройства нужно подтвердить на устройстве,
+// где уже выполнен вход. Request - запрос, по которому устройство узнаёт решение
+type ApprovalRequiredError struct {
+	Request types.DeviceRequest
+}
+
+// Error стандартный метод интерфейса error
+func (e *ApprovalRequiredError) Error() string {
+	return "login from this device needs approval on a device where you are already logged in"
+}
+
+func approvalRequired(body io.Reader) error {
+	var request types.DeviceRequest
+	err := json.NewDecoder(body).Decode(&request)
+	if err != nil {
+		return fmt.Errorf("could not read login request %w", err)
+	}
+	return &ApprovalRequiredError{Request: request}
+}
+
+// provenSessionHeaders заголовки входа вместе с ответом на вызов сервера: им устройство доказывает,
+// что у него есть закрытый ключ, а не только открытый
+func (c *Client) provenSessionHeaders() (map[string]string, error) {
+	headers := c.sessionHeaders()
+	if c.deviceKey == "" || c.devicePrivate == nil {
+		return headers, nil
+	}
+	resp, err := c.doRequest(c.address+"/api/device/challenge", http.MethodGet, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not make request %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("could not get device challenge %s %s", resp.Status, bodyBytes)
+	}
+	var challenge types.DeviceChallenge
+	err = json.NewDecoder(resp.Body).Decode(&challenge)
+	if err != nil {
+		return nil, fmt.Errorf("could not read device challenge %w", err)
+	}
+	serverKey, err := base64.StdEncoding.DecodeString(challenge.ServerKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrong server key in device challenge %w", err)
+	}
+	proof, err := types.DeviceProof(c.devicePrivate, serverKey, challenge.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	headers[DeviceNonceHeader] = challenge.Nonce
+	headers[DeviceProofHeader] = proof
+	return headers, nil
+}
+
+// PollDevice проверяет, подтверждён ли вход с этого устройства. Возвращает токен и ключ хранилища, зашифрованный
+// ключом устройства, если его передали. Пока решения нет - ErrApprovalPending, после отказа - ErrLoginRejected,
+// истёкший запрос - ErrNotFound
+func (c *Client) PollDevice(request types.DeviceRequest) (string, string, error) {
+	data, err := json.Marshal(types.DevicePoll{Secret: request.Secret})
+	if err != nil {
+		return "", "", fmt.Errorf("could not serialize data")
+	}
+	resp, err := c.doRequest(fmt.Sprintf("%s/api/device/%d/poll", c.address, request.ID), http.MethodPost, data, c.sessionHeaders())
+	if err != nil {
+		return "", "", fmt.Errorf("could not make request %w", err)
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusAccepted:
+		return "", "", ErrApprovalPending
+	case http.StatusForbidden:
+		return "", "", ErrLoginRejected
+	case http.StatusNotFound:
+		return "", "", fmt.Errorf("%w: %s", ErrNotFound, bodyBytes)
+	default:
+		return "", "", fmt.Errorf("error polling login request %s %s", resp.Status, bodyBytes)
+	}
+
+	token := resp.Header.Get(Token)
+	if token == "" {
+		return "", "", fmt.Errorf("empty token")
+	}
+	var approval types.DeviceApproval
+	err = json.Unmarshal(bodyBytes, &approval)
+	if err != nil {
+		return "", "", err
+	}
+	return token, approval.Escrow, nil
+}
+
+// Devices подтверждённые устройства пользователя и ожидающие подтверждения запросы на вход
+func (c *Client) Devices(token string) ([]types.Device, error) {
+	var devices []types.Device
+	err := c.requestJSON(token, http.MethodGet, "/api/user/devices", nil, http.StatusOK, &devices)
+	return devices, err
+}
+
+// ApproveDevice подтверждение входа с нового устройства. Escrow - ключ хранилища, зашифрованный ключом устройства
+func (c *Client) ApproveDevice(token string, id int, escrow string) error {
+	return c.requestJSON(token, http.MethodPost, fmt.Sprintf("/api/user/devices/%d/approve", id),
+		types.DeviceApproval{Escrow: escrow}, http.StatusOK, nil)
+}
+
+// RejectDevice отказ во входе с нового устройства
+func (c *Client) RejectDevice(token string, id int) error {
+	return c.requestJSON(token, http.MethodPost, fmt.Sprintf("/api/user/devices/%d/reject", id), nil, http.StatusOK, nil)
+}
+
+// DeleteDevice забыть устройство: его сессии завершаются
+func (c *Client) DeleteDevice(token string, id int) error {
+	return c.requestJSON(token, http.MethodDelete, fmt.Sprintf("/api/user/devices/%d", id), nil, http.StatusOK, nil)
+}
@@ -0,0 +1,161 @@
+// Package devices подтверждение входа с новых устройств.
+//
+// У каждой установки клиента есть своя ключевая пара X25519, приватный ключ лежит в файле рядом с настройками.
+// Открытый ключ отправляется при входе: по нему сервер узнаёт устройство. Вход с незнакомого устройства ждёт,
+// пока его подтвердят на устройстве, где уже выполнен вход. Пользователь сверяет отпечаток ключа на обоих
+// экранах, а подтверждающее устройство заодно передаёт новому ключ хранилища, зашифрованный ключом устройства
+package devices
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/sharing"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// PollInterval как часто новое устройство спрашивает сервер о решении
+var PollInterval = 3 * time.Second
+
+// ErrRequestExpired запрос на вход не подтвердили вовремя
+var ErrRequestExpired = errors.New("login request expired, log in again")
+
+// LoadOrCreateKey читает ключевую пару устройства из файла path, а если файла нет - создаёт её
+func LoadOrCreateKey(path string) (*sharing.KeyPair, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return createKey(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	private, err := sharing.DecodeKey(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("device key %s is corrupted: %w", path, err)
+	}
+	return keyPair(private)
+}
+
+func createKey(path string) (*sharing.KeyPair, error) {
+	var private [32]byte
+	_, err := rand.Read(private[:])
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(private[:])+"\n"), 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return keyPair(&private)
+}
+
+func keyPair(private *[32]byte) (*sharing.KeyPair, error) {
+	raw, err := curve25519.X25519(private[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	var public [32]byte
+	copy(public[:], raw)
+	return &sharing.KeyPair{Public: &public, Private: private}, nil
+}
+
+// PublicKey открытый ключ устройства в том виде, в котором его получает сервер
+func PublicKey(keys *sharing.KeyPair) string {
+	return base64.StdEncoding.EncodeToString(keys.Public[:])
+}
+
+// Login вызывает method (вход или регистрацию), а если вход с этого устройства нужно подтвердить,
+// ждёт решения. Возвращает токен и ключ хранилища, если его передали при подтверждении, иначе пустую строку
+func Login(cli *client.Client, keys *sharing.KeyPair, method func(string, string) (string, error),
+	login string, password string, out io.Writer) (string, string, error) {
+
+	token, err := method(login, password)
+	var approval *client.ApprovalRequiredError
+	if errors.As(err, &approval) {
+		return WaitForApproval(cli, keys, approval.Request, out)
+	}
+	return token, "", err
+}
+
+// WaitForApproval показывает отпечаток ключа устройства и ждёт, пока вход подтвердят или отклонят
+func WaitForApproval(cli *client.Client, keys *sharing.KeyPair, request types.DeviceRequest, out io.Writer) (string, string, error) {
+	fmt.Fprintf(out, "This device is new. Approve the login on a device where you are already logged in.\n"+
+		"Check that it shows the same fingerprint: %s\nWaiting for approval...\n", request.Fingerprint)
+	for {
+		token, escrow, err := cli.PollDevice(request)
+		switch {
+		case errors.Is(err, client.ErrApprovalPending):
+			time.Sleep(PollInterval)
+			continue
+		case errors.Is(err, client.ErrNotFound):
+			return "", "", ErrRequestExpired
+		case err != nil:
+			return "", "", err
+		}
+		if escrow == "" {
+			return token, "", nil
+		}
+		vaultKey, err := sharing.UnwrapKey(escrow, keys)
+		if err != nil {
+			return "", "", err
+		}
+		return token, string(vaultKey), nil
+	}
+}
+
+// Pending запросы на вход с новых устройств, ожидающие подтверждения
+func Pending(token string, cli *client.Client) ([]types.Device, error) {
+	devices, err := cli.Devices(token)
+	if err != nil {
+		return nil, err
+	}
+	var pending []types.Device
+	for _, d := range devices {
+		if d.Status == types.DevicePending {
+			pending = append(pending, d)
+		}
+	}
+	return pending, nil
+}
+
+// Approve подтверждает вход с нового устройства и передаёт ему ключ хранилища pass,
+// зашифрованный открытым ключом устройства
+func Approve(token string, pass string, cli *client.Client, device types.Device) error {
+	public, err := sharing.DecodeKey(device.PublicKey)
+	if err != nil {
+		return err
+	}
+	escrow, err := sharing.WrapKey([]byte(pass), public)
+	if err != nil {
+		return err
+	}
+	return cli.ApproveDevice(token, device.ID, escrow)
+}
+
+// Find ищет устройство id среди устройств пользователя
+func Find(token string, cli *client.Client, id int) (*types.Device, error) {
+	devices, err := cli.Devices(token)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range devices {
+		if d.ID == id {
+			return &d, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: device %d", client.ErrNotFound, id)
+}
@@ -0,0 +1,100 @@
+package devices
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func TestLoadOrCreateKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gophkeeper", "device.key")
+	keys, err := LoadOrCreateKey(path)
+	require.NoError(t, err)
+
+	loaded, err := LoadOrCreateKey(path)
+	require.NoError(t, err)
+	assert.Equal(t, keys, loaded)
+}
+
+func TestApproveAndLogin(t *testing.T) {
+	keys, err := LoadOrCreateKey(filepath.Join(t.TempDir(), "device.key"))
+	require.NoError(t, err)
+	device := types.Device{ID: 3, PublicKey: PublicKey(keys), Status: types.DevicePending}
+
+	var (
+		mu     sync.Mutex
+		escrow string
+	)
+	serverPrivate := bytes.Repeat([]byte{7}, curve25519.ScalarSize)
+	serverKey, err := curve25519.X25519(serverPrivate, curve25519.Basepoint)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/device/challenge", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(types.DeviceChallenge{Nonce: "nonce",
+			ServerKey: base64.StdEncoding.EncodeToString(serverKey)})
+	})
+	mux.HandleFunc("POST /api/user/login", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, device.PublicKey, r.Header.Get(client.DeviceKeyHeader))
+		proof, err := types.DeviceProof(serverPrivate, keys.Public[:], "nonce")
+		require.NoError(t, err)
+		assert.Equal(t, "nonce", r.Header.Get(client.DeviceNonceHeader))
+		assert.Equal(t, proof, r.Header.Get(client.DeviceProofHeader))
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(types.DeviceRequest{ID: 3, Secret: "secret",
+			Fingerprint: types.DeviceFingerprint(device.PublicKey)})
+	})
+	mux.HandleFunc("POST /api/user/devices/3/approve", func(w http.ResponseWriter, r *http.Request) {
+		var approval types.DeviceApproval
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&approval))
+		mu.Lock()
+		escrow = approval.Escrow
+		mu.Unlock()
+	})
+	mux.HandleFunc("POST /api/device/3/poll", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if escrow == "" {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set(client.Token, "token")
+		_ = json.NewEncoder(w).Encode(types.DeviceApproval{Escrow: escrow})
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	conf, _ := config.NewClientConfig()
+	conf.ServerAddress = svr.URL
+	conf.SSLKey = "../../../.ssl/ca.key"
+	cli, err := client.NewClient(conf)
+	require.NoError(t, err)
+	cli.SetDeviceKey(device.PublicKey, keys.Private[:])
+
+	PollInterval = time.Millisecond
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		assert.NoError(t, Approve("other", "vault key", cli, device))
+	}()
+
+	var out bytes.Buffer
+	token, vaultKey, err := Login(cli, keys, cli.Login, "user", "pass", &out)
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+	assert.Equal(t, "vault key", vaultKey)
+	assert.Contains(t, out.String(), types.DeviceFingerprint(device.PublicKey))
+}
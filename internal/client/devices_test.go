@@ -0,0 +1,93 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func TestClient_LoginApprovalRequired(t *testing.T) {
+	request := types.DeviceRequest{ID: 3, Secret: "secret", Fingerprint: "AAAA-BBBB-CCCC-DDDD"}
+	polls := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key", r.Header.Get(DeviceKeyHeader))
+		switch r.URL.Path {
+		case "/api/user/login":
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(request)
+		case "/api/device/3/poll":
+			var poll types.DevicePoll
+			_ = json.NewDecoder(r.Body).Decode(&poll)
+			assert.Equal(t, "secret", poll.Secret)
+			polls++
+			if polls == 1 {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.Header().Set(Token, "token")
+			_ = json.NewEncoder(w).Encode(types.DeviceApproval{Escrow: "wrapped"})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer svr.Close()
+
+	c, _ := NewClient(conf)
+	c.address = svr.URL
+	c.SetDeviceKey("key", nil)
+
+	_, err := c.Login("user", "pass")
+	var approval *ApprovalRequiredError
+	require.True(t, errors.As(err, &approval))
+	assert.Equal(t, request, approval.Request)
+
+	_, _, err = c.PollDevice(approval.Request)
+	assert.ErrorIs(t, err, ErrApprovalPending)
+
+	token, escrow, err := c.PollDevice(approval.Request)
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+	assert.Equal(t, "wrapped", escrow)
+}
+
+func TestClient_PollDeviceRejected(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Login rejected", http.StatusForbidden)
+	}))
+	defer svr.Close()
+
+	c, _ := NewClient(conf)
+	c.address = svr.URL
+
+	_, _, err := c.PollDevice(types.DeviceRequest{ID: 3, Secret: "secret"})
+	assert.ErrorIs(t, err, ErrLoginRejected)
+}
+
+func TestClient_LoginEnrollmentRequired(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(EnrollmentHeader) != "code" {
+			http.Error(w, types.EnrollmentRequired, http.StatusForbidden)
+			return
+		}
+		w.Header().Set(Token, "token")
+	}))
+	defer svr.Close()
+
+	c, _ := NewClient(conf)
+	c.address = svr.URL
+
+	_, err := c.Login("user", "pass")
+	assert.ErrorIs(t, err, ErrEnrollmentRequired)
+
+	c.SetEnrollment("code")
+	token, err := c.Login("user", "pass")
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+}
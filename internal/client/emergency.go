@@ -0,0 +1,71 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// CreateEmergencyAccess назначение доверенного лица
+func (c *Client) CreateEmergencyAccess(token string, access types.NewEmergencyAccess) error {
+	return c.requestJSON(token, http.MethodPost, "/api/emergency", access, http.StatusCreated, nil)
+}
+
+// EmergencyContacts получение доверенных лиц, назначенных пользователем
+func (c *Client) EmergencyContacts(token string) ([]types.EmergencyAccess, error) {
+	var contacts []types.EmergencyAccess
+	err := c.requestJSON(token, http.MethodGet, "/api/emergency/contacts", nil, http.StatusOK, &contacts)
+	return contacts, err
+}
+
+// EmergencyGrants получение хранилищ, для которых пользователь назначен доверенным лицом
+func (c *Client) EmergencyGrants(token string) ([]types.EmergencyAccess, error) {
+	var grants []types.EmergencyAccess
+	err := c.requestJSON(token, http.MethodGet, "/api/emergency/grants", nil, http.StatusOK, &grants)
+	return grants, err
+}
+
+// AcceptEmergencyAccess согласие доверенного лица на назначение
+func (c *Client) AcceptEmergencyAccess(token string, id int) error {
+	return c.emergencyAction(token, id, "accept")
+}
+
+// RequestEmergencyAccess запрос экстренного доступа доверенным лицом
+func (c *Client) RequestEmergencyAccess(token string, id int) error {
+	return c.emergencyAction(token, id, "request")
+}
+
+// ApproveEmergencyAccess досрочная выдача экстренного доступа владельцем
+func (c *Client) ApproveEmergencyAccess(token string, id int) error {
+	return c.emergencyAction(token, id, "approve")
+}
+
+// RejectEmergencyAccess отклонение запроса экстренного доступа владельцем
+func (c *Client) RejectEmergencyAccess(token string, id int) error {
+	return c.emergencyAction(token, id, "reject")
+}
+
+// DeleteEmergencyAccess отзыв экстренного доступа владельцем или отказ доверенного лица
+func (c *Client) DeleteEmergencyAccess(token string, id int) error {
+	return c.requestJSON(token, http.MethodDelete, fmt.Sprintf("/api/emergency/%d", id), nil, http.StatusOK, nil)
+}
+
+// EmergencyKey получение выданного ключа хранилища владельца
+func (c *Client) EmergencyKey(token string, id int) (string, error) {
+	var key types.EmergencyKey
+	err := c.requestJSON(token, http.MethodGet, fmt.Sprintf("/api/emergency/%d/key", id), nil, http.StatusOK, &key)
+	return key.Escrow, err
+}
+
+// EmergencyVault клиент, который читает записи хранилища владельца через выданный экстренный доступ id.
+// Работают только методы чтения записей: GetItem, SeeRecords, AllRecords и скачивание бинарных данных
+func (c *Client) EmergencyVault(id int) *Client {
+	vault := *c
+	vault.vault = fmt.Sprintf("/api/emergency/%d/item", id)
+	return &vault
+}
+
+func (c *Client) emergencyAction(token string, id int, action string) error {
+	return c.requestJSON(token, http.MethodPost, fmt.Sprintf("/api/emergency/%d/%s", id, action), nil, http.StatusOK, nil)
+}
@@ -0,0 +1,83 @@
+// Package emergency экстренный доступ доверенного лица к хранилищу. Владелец заранее шифрует ключ
+// своего хранилища открытым ключом доверенного лица (см. пакет sharing), сервер хранит только шифротекст
+// и отдаёт его доверенному лицу, когда владелец одобрил запрос или не отклонил его за период ожидания.
+// Получив ключ, доверенное лицо читает записи владельца через сервер и расшифровывает их у себя
+package emergency
+
+import (
+	"errors"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/export"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/client/recovery"
+	"github.com/wellywell/gophkeeper/internal/client/sharing"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// ErrNoVaultKey у учётной записи ещё нет ключа хранилища, отдельного от пароля
+var ErrNoVaultKey = errors.New("vault key is not set up yet, log in again to create it")
+
+// Designate назначает пользователя grantee доверенным лицом с периодом ожидания waitDays дней.
+// Доверенному лицу передаётся только ключ хранилища vaultKey, но не пароль: у учётных записей,
+// созданных раньше, ключом хранилища был пароль, и пока клиент не заменил его (см. recovery.Unlock),
+// назначение отклоняется
+func Designate(token string, vaultKey string, cli *client.Client, grantee string, waitDays int) error {
+	key, err := cli.GetVaultKey(token)
+	if errors.Is(err, client.ErrNotFound) {
+		return ErrNoVaultKey
+	}
+	if err != nil {
+		return err
+	}
+	// пароль, зашифрованный самим собой, - ключ хранилища старого образца
+	legacy, err := recovery.Unwrap(key.Wrapped, vaultKey)
+	if err == nil && legacy == vaultKey {
+		return ErrNoVaultKey
+	}
+
+	public, err := sharing.PublicKey(token, cli, grantee)
+	if err != nil {
+		return err
+	}
+	escrow, err := sharing.WrapKey([]byte(vaultKey), public)
+	if err != nil {
+		return err
+	}
+	return cli.CreateEmergencyAccess(token, types.NewEmergencyAccess{Grantee: grantee, WaitDays: waitDays, Escrow: escrow})
+}
+
+// Open расшифровывает выданный ключ хранилища владельца и возвращает клиент для чтения его записей
+func Open(token string, pass string, cli *client.Client, id int) (*client.Client, string, error) {
+	keys, err := sharing.EnsureKeys(token, pass, cli)
+	if err != nil {
+		return nil, "", err
+	}
+	escrow, err := cli.EmergencyKey(token, id)
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := sharing.UnwrapKey(escrow, keys)
+	if err != nil {
+		return nil, "", err
+	}
+	return cli.EmergencyVault(id), string(key), nil
+}
+
+// Load загружает и расшифровывает все записи владельца по выданному экстренному доступу id
+func Load(token string, pass string, cli *client.Client, id int) ([]importer.Record, error) {
+	vault, key, err := Open(token, pass, cli, id)
+	if err != nil {
+		return nil, err
+	}
+	return export.Load(token, key, vault)
+}
+
+// LoadKey загружает и расшифровывает запись владельца key по выданному экстренному доступу id
+func LoadKey(token string, pass string, cli *client.Client, id int, key string) (importer.Record, error) {
+	vault, vaultKey, err := Open(token, pass, cli, id)
+	if err != nil {
+		return importer.Record{}, err
+	}
+	return export.LoadKey(token, vaultKey, vault, key)
+}
@@ -0,0 +1,123 @@
+package emergency
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/client/recovery"
+	"github.com/wellywell/gophkeeper/internal/client/sharing"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// fakeServer хранит ключи доверенного лица bob и хранилище владельца alice
+type fakeServer struct {
+	keys    *types.UserKeys
+	access  *types.NewEmergencyAccess
+	granted bool
+	items   []types.Item
+	note    types.TextData
+	// vaultKey ключ хранилища alice, зашифрованный её паролем
+	vaultKey string
+}
+
+func (f *fakeServer) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/user/keys", func(w http.ResponseWriter, r *http.Request) {
+		if f.keys == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.keys)
+	})
+	mux.HandleFunc("PUT /api/user/keys", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&f.keys))
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /api/user/vault_key", func(w http.ResponseWriter, r *http.Request) {
+		if f.vaultKey == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(types.VaultKey{Wrapped: f.vaultKey})
+	})
+	mux.HandleFunc("GET /api/user/bob/public_key", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(types.UserKeys{PublicKey: f.keys.PublicKey})
+	})
+	mux.HandleFunc("POST /api/emergency", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&f.access))
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /api/emergency/5/key", func(w http.ResponseWriter, r *http.Request) {
+		if !f.granted {
+			http.Error(w, "Not allowed while emergency access is requested", http.StatusConflict)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(types.EmergencyKey{Escrow: f.access.Escrow})
+	})
+	mux.HandleFunc("GET /api/emergency/5/item/list", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(f.items)
+	})
+	mux.HandleFunc("GET /api/emergency/5/item/note", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(types.TextItem{Item: f.items[0], Data: f.note})
+	})
+	return mux
+}
+
+func TestEmergencyAccess(t *testing.T) {
+	// alicePass ключ хранилища alice, aliceLogin - её пароль
+	alicePass := "alice secret"
+	aliceLogin := "alice password"
+	bobPass := "bob secret"
+
+	fake := &fakeServer{items: []types.Item{{Key: "note", Type: types.TypeText}}, note: types.TextData("will")}
+	require.NoError(t, fake.items[0].Encrypt(alicePass))
+	require.NoError(t, fake.note.Encrypt(alicePass))
+	svr := httptest.NewServer(fake.handler(t))
+	defer svr.Close()
+
+	conf, _ := config.NewClientConfig()
+	conf.ServerAddress = svr.URL
+	conf.SSLKey = "../../../.ssl/ca.key"
+	cli, err := client.NewClient(conf)
+	require.NoError(t, err)
+
+	_, err = sharing.EnsureKeys("bob", bobPass, cli)
+	require.NoError(t, err)
+
+	// пароль вместо ключа хранилища доверенному лицу не передаётся
+	assert.ErrorIs(t, Designate("alice", aliceLogin, cli, "bob", 7), ErrNoVaultKey)
+	fake.vaultKey, err = recovery.Wrap(aliceLogin, aliceLogin)
+	require.NoError(t, err)
+	assert.ErrorIs(t, Designate("alice", aliceLogin, cli, "bob", 7), ErrNoVaultKey)
+	assert.Nil(t, fake.access)
+
+	fake.vaultKey, err = recovery.Wrap(alicePass, aliceLogin)
+	require.NoError(t, err)
+	require.NoError(t, Designate("alice", alicePass, cli, "bob", 7))
+	require.NotNil(t, fake.access)
+	assert.Equal(t, "bob", fake.access.Grantee)
+	assert.Equal(t, 7, fake.access.WaitDays)
+	assert.NotContains(t, fake.access.Escrow, alicePass)
+
+	// пока доступ не выдан, ключ хранилища недоступен
+	_, err = Load("bob", bobPass, cli, 5)
+	assert.Error(t, err)
+
+	fake.granted = true
+	records, err := Load("bob", bobPass, cli, 5)
+	require.NoError(t, err)
+	will := types.TextData("will")
+	assert.Equal(t, []importer.Record{{Item: types.Item{Key: "note", Type: types.TypeText}, Text: &will}}, records)
+
+	record, err := LoadKey("bob", bobPass, cli, 5, "note")
+	require.NoError(t, err)
+	assert.Equal(t, &will, record.Text)
+}
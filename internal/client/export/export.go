@@ -0,0 +1,124 @@
+// Package export выгружает все записи пользователя с сервера и расшифровывает их на клиенте
+// для сохранения в других форматах
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// Load загружает с сервера и расшифровывает все записи пользователя. Записи возвращаются в том же виде,
+// в котором их принимает импорт, поэтому выгруженное можно загрузить обратно
+func Load(token string, pass string, cli *client.Client) ([]importer.Record, error) {
+	items, err := cli.AllRecords(token, pass)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]importer.Record, 0, len(items))
+	for _, item := range items {
+		record, err := load(token, pass, cli, item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", item.Key, err)
+		}
+		result = append(result, record)
+	}
+	return result, nil
+}
+
+// Attachments загружает и расшифровывает вложения записей records. Записи дополняются на месте
+func Attachments(token string, pass string, cli *client.Client, records []importer.Record) error {
+	for i := range records {
+		key := records[i].Item.Key
+		list, err := cli.SeeAttachments(token, pass, key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		for _, a := range list {
+			data, err := cli.DownloadAttachment(token, pass, key, a.ID)
+			if err != nil {
+				return fmt.Errorf("%s: attachment %s: %w", key, a.Name, err)
+			}
+			records[i].Attachments = append(records[i].Attachments, importer.Attachment{Name: a.Name, MimeType: a.MimeType, Data: data})
+		}
+	}
+	return nil
+}
+
+// LoadKey загружает с сервера и расшифровывает одну запись key
+func LoadKey(token string, pass string, cli *client.Client, key string) (importer.Record, error) {
+	data, err := cli.GetItem(token, key)
+	if err != nil {
+		return importer.Record{}, err
+	}
+	var item types.AnyItem
+	err = json.Unmarshal(data, &item)
+	if err != nil {
+		return importer.Record{}, err
+	}
+	err = item.Item.Decrypt(pass)
+	if err != nil {
+		return importer.Record{}, err
+	}
+	return load(token, pass, cli, item.Item)
+}
+
+func load(token string, pass string, cli *client.Client, item types.Item) (importer.Record, error) {
+	record := importer.Record{Item: item}
+
+	if item.Type == types.TypeBinary {
+		data, meta, err := cli.DownloadBinaryItem(token, pass, item.Key)
+		if err != nil {
+			return record, err
+		}
+		binary := types.BinaryData(data)
+		record.Binary = &binary
+		record.Meta = meta
+		return record, nil
+	}
+
+	data, err := cli.GetItem(token, item.Key)
+	if err != nil {
+		return record, err
+	}
+
+	switch item.Type {
+	case types.TypeLogoPass:
+		parsed, err := types.ParseItem[*types.LoginPassword](data, pass)
+		if err != nil {
+			return record, err
+		}
+		record.Login = parsed.Data
+	case types.TypeCreditCard:
+		parsed, err := types.ParseItem[*types.CreditCardData](data, pass)
+		if err != nil {
+			return record, err
+		}
+		record.Card = parsed.Data
+	case types.TypeText:
+		parsed, err := types.ParseItem[*types.TextData](data, pass)
+		if err != nil {
+			return record, err
+		}
+		record.Text = parsed.Data
+	case types.TypeSSHKey:
+		parsed, err := types.ParseItem[*types.SSHKeyData](data, pass)
+		if err != nil {
+			return record, err
+		}
+		record.SSHKey = parsed.Data
+	case types.TypeTOTP:
+		parsed, err := types.ParseItem[*types.TOTPData](data, pass)
+		if err != nil {
+			return record, err
+		}
+		record.TOTP = parsed.Data
+	default:
+		return record, fmt.Errorf("unknown item type %s", item.Type)
+	}
+	return record, nil
+}
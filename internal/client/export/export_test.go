@@ -0,0 +1,76 @@
+package export
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func TestLoad(t *testing.T) {
+	pass := "secret"
+	items := []types.Item{
+		{Key: "site", Type: types.TypeLogoPass},
+		{Key: "note", Type: types.TypeText},
+	}
+	for i := range items {
+		require.NoError(t, items[i].Encrypt(pass))
+	}
+	logopass := types.LoginPassword{Login: "me", Password: "pw"}
+	require.NoError(t, logopass.Encrypt(pass))
+	text := types.TextData("hello")
+	require.NoError(t, text.Encrypt(pass))
+	meta := types.Attachment{ID: 3, Name: importer.NotesAttachment, MimeType: "text/plain"}
+	require.NoError(t, meta.Encrypt(pass))
+	content := types.BinaryData("pin 1234")
+	require.NoError(t, content.Encrypt(pass))
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/item/list":
+			_ = json.NewEncoder(w).Encode(items)
+		case "/api/item/site":
+			_ = json.NewEncoder(w).Encode(types.LoginPasswordItem{Item: items[0], Data: &logopass})
+		case "/api/item/note":
+			_ = json.NewEncoder(w).Encode(types.TextItem{Item: items[1], Data: text})
+		case "/api/item/site/attachments":
+			_ = json.NewEncoder(w).Encode([]types.Attachment{meta})
+		case "/api/item/site/attachments/3":
+			_, _ = w.Write(content)
+		case "/api/item/note/attachments":
+			_ = json.NewEncoder(w).Encode([]types.Attachment{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	conf, _ := config.NewClientConfig()
+	conf.ServerAddress = svr.URL
+	conf.SSLKey = "../../../.ssl/ca.key"
+	cli, err := client.NewClient(conf)
+	require.NoError(t, err)
+
+	got, err := Load("token", pass, cli)
+	require.NoError(t, err)
+	hello := types.TextData("hello")
+	assert.Equal(t, []importer.Record{
+		{Item: types.Item{Key: "site", Type: types.TypeLogoPass}, Login: &types.LoginPassword{Login: "me", Password: "pw"}},
+		{Item: types.Item{Key: "note", Type: types.TypeText}, Text: &hello},
+	}, got)
+
+	// вложения загружаются отдельно, для полной резервной копии
+	require.NoError(t, Attachments("token", pass, cli, got))
+	assert.Equal(t, []importer.Attachment{{Name: importer.NotesAttachment, MimeType: "text/plain", Data: []byte("pin 1234")}}, got[0].Attachments)
+	assert.Empty(t, got[1].Attachments)
+
+	missing := []importer.Record{{Item: types.Item{Key: "missing"}}}
+	assert.Error(t, Attachments("token", pass, cli, missing))
+}
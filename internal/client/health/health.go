@@ -0,0 +1,164 @@
+// Package health формирует отчёт о состоянии хранилища: слабые, повторяющиеся и давно не менявшиеся пароли.
+// Пароли расшифровываются и анализируются только локально на клиенте
+package health
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nbutton23/zxcvbn-go"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// Severity важность найденной проблемы
+type Severity string
+
+const (
+	SeverityHigh   Severity = "high"
+	SeverityMedium Severity = "medium"
+	SeverityLow    Severity = "low"
+)
+
+// severities порядок вывода групп в отчёте
+var severities = []Severity{SeverityHigh, SeverityMedium, SeverityLow}
+
+// Issue тип найденной проблемы
+type Issue string
+
+const (
+	IssueWeak   Issue = "weak"
+	IssueReused Issue = "reused"
+	IssueOld    Issue = "old"
+)
+
+// DefaultMaxAgeDays через сколько дней без изменений пароль считается старым
+const DefaultMaxAgeDays = 365
+
+const day = 24 * time.Hour
+
+// Credential расшифрованная запись логина и пароля
+type Credential struct {
+	Item types.Item
+	Data types.LoginPassword
+}
+
+// Finding найденная проблема. Key - ключ записи, по которому её можно отредактировать
+type Finding struct {
+	Key      string   `json:"key"`
+	Severity Severity `json:"severity"`
+	Issue    Issue    `json:"issue"`
+	Details  string   `json:"details"`
+}
+
+// String строковое представление, показываемое пользователю
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s password, %s", f.Key, f.Issue, f.Details)
+}
+
+// Group проблемы одной важности
+type Group struct {
+	Severity Severity  `json:"severity"`
+	Findings []Finding `json:"findings"`
+}
+
+// Strength оценка стойкости пароля от 0 (очень слабый) до 4 (стойкий) в духе zxcvbn.
+// userInputs - слова, которые не должны встречаться в пароле (логин, ключ записи)
+func Strength(password string, userInputs []string) (int, string) {
+	result := zxcvbn.PasswordStrength(password, userInputs)
+	return result.Score, result.CrackTimeDisplay
+}
+
+// Check проверяет пароли на стойкость, повторное использование и возраст.
+// Пароли, не менявшиеся дольше maxAgeDays дней от now, считаются старыми
+func Check(credentials []Credential, now time.Time, maxAgeDays int) []Finding {
+	result := []Finding{}
+
+	byPassword := make(map[string][]string)
+	for _, c := range credentials {
+		if c.Data.Password != "" {
+			byPassword[c.Data.Password] = append(byPassword[c.Data.Password], c.Item.Key)
+		}
+	}
+
+	for _, c := range credentials {
+		score, crackTime := Strength(c.Data.Password, []string{c.Item.Key, c.Data.Login})
+		switch {
+		case score <= 1:
+			result = append(result, Finding{Key: c.Item.Key, Severity: SeverityHigh, Issue: IssueWeak,
+				Details: fmt.Sprintf("strength %d/4, cracked in %s", score, crackTime)})
+		case score == 2:
+			result = append(result, Finding{Key: c.Item.Key, Severity: SeverityMedium, Issue: IssueWeak,
+				Details: fmt.Sprintf("strength %d/4, cracked in %s", score, crackTime)})
+		}
+
+		if keys := byPassword[c.Data.Password]; len(keys) > 1 {
+			others := make([]string, 0, len(keys)-1)
+			for _, k := range keys {
+				if k != c.Item.Key {
+					others = append(others, k)
+				}
+			}
+			result = append(result, Finding{Key: c.Item.Key, Severity: SeverityHigh, Issue: IssueReused,
+				Details: "also used in " + strings.Join(others, ", ")})
+		}
+
+		if changed := c.Item.SecretChanged(); changed != nil {
+			age := int(now.Sub(*changed) / day)
+			if age > maxAgeDays {
+				result = append(result, Finding{Key: c.Item.Key, Severity: SeverityLow, Issue: IssueOld,
+					Details: fmt.Sprintf("not changed for %d days", age)})
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// Groups группирует проблемы по важности, начиная с самых важных. Пустые группы пропускаются
+func Groups(findings []Finding) []Group {
+	result := []Group{}
+	for _, s := range severities {
+		group := Group{Severity: s}
+		for _, f := range findings {
+			if f.Severity == s {
+				group.Findings = append(group.Findings, f)
+			}
+		}
+		if len(group.Findings) > 0 {
+			result = append(result, group)
+		}
+	}
+	return result
+}
+
+// Report загружает с сервера все записи логинов и паролей пользователя, расшифровывает их и проверяет
+func Report(token string, pass string, cli *client.Client, now time.Time, maxAgeDays int) ([]Group, error) {
+	items, err := cli.AllRecords(token, pass)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := []Credential{}
+	for _, item := range items {
+		if item.Type != types.TypeLogoPass {
+			continue
+		}
+		data, err := cli.GetItem(token, item.Key)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := types.ParseItem[*types.LoginPassword](data, pass)
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, Credential{Item: item, Data: *parsed.Data})
+	}
+	return Groups(Check(credentials, now, maxAgeDays)), nil
+}
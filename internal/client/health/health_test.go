@@ -0,0 +1,109 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+var now = time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+
+const strong = "correct-Horse7-battery-Staple!"
+
+func TestStrength(t *testing.T) {
+	weak, _ := Strength("password", nil)
+	assert.Equal(t, 0, weak)
+
+	good, _ := Strength(strong, nil)
+	assert.Equal(t, 4, good)
+
+	// логин в пароле снижает оценку
+	withLogin, _ := Strength("wellywell2024", []string{"wellywell"})
+	withoutLogin, _ := Strength("wellywell2024", nil)
+	assert.LessOrEqual(t, withLogin, withoutLogin)
+}
+
+func TestCheck(t *testing.T) {
+	recent := now.AddDate(0, -1, 0)
+	old := now.AddDate(-2, 0, 0)
+
+	credentials := []Credential{
+		{Item: types.Item{Key: "mail", UpdatedAt: &recent}, Data: types.LoginPassword{Login: "me", Password: strong}},
+		{Item: types.Item{Key: "bank", UpdatedAt: &recent}, Data: types.LoginPassword{Login: "me", Password: strong}},
+		{Item: types.Item{Key: "forum", UpdatedAt: &old}, Data: types.LoginPassword{Login: "me", Password: "qwerty"}},
+		{Item: types.Item{Key: "good"}, Data: types.LoginPassword{Login: "me", Password: "Tz7#q!Lm0v@R2x^W"}},
+		// описание правили недавно, но сам пароль давно не менялся
+		{Item: types.Item{Key: "notes", UpdatedAt: &recent, SecretChangedAt: &old}, Data: types.LoginPassword{Login: "me", Password: "Xk4$w9!Pq2@Lz7^N"}},
+	}
+
+	got := Check(credentials, now, DefaultMaxAgeDays)
+	assert.Equal(t, []Finding{
+		{Key: "bank", Severity: SeverityHigh, Issue: IssueReused, Details: "also used in mail"},
+		{Key: "forum", Severity: SeverityHigh, Issue: IssueWeak, Details: got[1].Details},
+		{Key: "forum", Severity: SeverityLow, Issue: IssueOld, Details: "not changed for 731 days"},
+		{Key: "mail", Severity: SeverityHigh, Issue: IssueReused, Details: "also used in bank"},
+		{Key: "notes", Severity: SeverityLow, Issue: IssueOld, Details: "not changed for 731 days"},
+	}, got)
+	assert.Contains(t, got[1].Details, "strength 0/4")
+}
+
+func TestGroups(t *testing.T) {
+	findings := []Finding{
+		{Key: "a", Severity: SeverityLow, Issue: IssueOld},
+		{Key: "b", Severity: SeverityHigh, Issue: IssueWeak},
+		{Key: "c", Severity: SeverityHigh, Issue: IssueReused},
+	}
+	assert.Equal(t, []Group{
+		{Severity: SeverityHigh, Findings: []Finding{findings[1], findings[2]}},
+		{Severity: SeverityLow, Findings: []Finding{findings[0]}},
+	}, Groups(findings))
+
+	assert.Equal(t, []Group{}, Groups(nil))
+}
+
+func TestReport(t *testing.T) {
+	pass := "secret"
+	items := []types.Item{
+		{Key: "site", Type: types.TypeLogoPass},
+		{Key: "note", Type: types.TypeText},
+	}
+	for i := range items {
+		require.NoError(t, items[i].Encrypt(pass))
+	}
+	logopass := types.LoginPassword{Login: "me", Password: "123456"}
+	require.NoError(t, logopass.Encrypt(pass))
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/item/list":
+			_ = json.NewEncoder(w).Encode(items)
+		case "/api/item/site":
+			_ = json.NewEncoder(w).Encode(types.LoginPasswordItem{Item: items[0], Data: &logopass})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	conf, _ := config.NewClientConfig()
+	conf.ServerAddress = svr.URL
+	conf.SSLKey = "../../../.ssl/ca.key"
+	cli, err := client.NewClient(conf)
+	require.NoError(t, err)
+
+	got, err := Report("token", pass, cli, now, DefaultMaxAgeDays)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, SeverityHigh, got[0].Severity)
+	require.Len(t, got[0].Findings, 1)
+	assert.Equal(t, "site", got[0].Findings[0].Key)
+	assert.Equal(t, IssueWeak, got[0].Findings[0].Issue)
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// типы записей Bitwarden
+const (
+	bitwardenLogin    = 1
+	bitwardenNote     = 2
+	bitwardenCard     = 3
+	bitwardenIdentity = 4
+)
+
+// ErrEncryptedExport экспорт зашифрован и не может быть прочитан
+var ErrEncryptedExport = errors.New("encrypted export is not supported, export unencrypted JSON")
+
+type bitwardenExport struct {
+	Encrypted bool            `json:"encrypted"`
+	Items     []bitwardenItem `json:"items"`
+}
+
+type bitwardenItem struct {
+	Type  int    `json:"type"`
+	Name  string `json:"name"`
+	Notes string `json:"notes"`
+	Login *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName string `json:"cardholderName"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	} `json:"card"`
+	Identity map[string]interface{} `json:"identity"`
+}
+
+// parseBitwarden разбирает незашифрованный JSON-экспорт Bitwarden
+func parseBitwarden(data []byte) ([]Record, error) {
+	var export bitwardenExport
+	err := json.Unmarshal(data, &export)
+	if err != nil {
+		return nil, err
+	}
+	if export.Encrypted {
+		return nil, ErrEncryptedExport
+	}
+
+	var result []Record
+	for _, item := range export.Items {
+		switch {
+		case item.Type == bitwardenLogin && item.Login != nil:
+			var uri string
+			if len(item.Login.URIs) > 0 {
+				uri = item.Login.URIs[0].URI
+			}
+			result = append(result, loginRecord(item.Name, uri, item.Login.Username, item.Login.Password, item.Login.TOTP, item.Notes))
+		case item.Type == bitwardenCard && item.Card != nil:
+			result = append(result, cardRecord(item.Name, item.Card.CardholderName, item.Card.Number,
+				item.Card.ExpMonth, item.Card.ExpYear, item.Card.Code, item.Notes))
+		case item.Type == bitwardenIdentity:
+			result = append(result, textRecord(item.Name, identityText(item.Identity, item.Notes)))
+		case item.Type == bitwardenNote:
+			result = append(result, textRecord(item.Name, item.Notes))
+		}
+	}
+	return result, nil
+}
+
+// identityText личные данные сохраняются текстом, по строке на каждое заполненное поле
+func identityText(identity map[string]interface{}, notes string) string {
+	fields := make([]string, 0, len(identity))
+	for name, value := range identity {
+		if s, ok := value.(string); ok && s != "" {
+			fields = append(fields, fmt.Sprintf("%s: %s", name, s))
+		}
+	}
+	sort.Strings(fields)
+	if notes != "" {
+		fields = append(fields, notes)
+	}
+	return strings.Join(fields, "\n")
+}
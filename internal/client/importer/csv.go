@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// csvColumns названия колонок CSV-экспорта. Для каждого поля перечислены возможные варианты названия,
+// пустой список - поля в формате нет
+type csvColumns struct {
+	title, url, login, password, notes, totp, kind []string
+}
+
+// csvFormats колонки CSV-экспортов поддерживаемых менеджеров паролей
+var csvFormats = map[Format]csvColumns{
+	FormatBitwardenCSV: {
+		title:    []string{"name"},
+		url:      []string{"login_uri"},
+		login:    []string{"login_username"},
+		password: []string{"login_password"},
+		notes:    []string{"notes"},
+		totp:     []string{"login_totp"},
+		kind:     []string{"type"},
+	},
+	// KeePassXC и KeePass 2.x
+	FormatKeePassCSV: {
+		title:    []string{"title", "account"},
+		url:      []string{"url", "web site"},
+		login:    []string{"username", "login name"},
+		password: []string{"password"},
+		notes:    []string{"notes", "comments"},
+		totp:     []string{"totp"},
+	},
+	FormatChrome: {
+		title:    []string{"name"},
+		url:      []string{"url"},
+		login:    []string{"username"},
+		password: []string{"password"},
+		notes:    []string{"note"},
+	},
+	// у Firefox нет названия записи, ключом становится хост
+	FormatFirefox: {
+		url:      []string{"url"},
+		login:    []string{"username"},
+		password: []string{"password"},
+	},
+	Format1PasswordCSV: {
+		title:    []string{"title"},
+		url:      []string{"url", "website"},
+		login:    []string{"username"},
+		password: []string{"password"},
+		notes:    []string{"notes"},
+		totp:     []string{"otpauth", "one-time password"},
+	},
+}
+
+// parseCSV разбирает CSV с заголовком. Колонка с паролем обязательна, строки без логина и пароля
+// с заметкой импортируются как текст
+func parseCSV(data []byte, columns csvColumns) ([]Record, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty file")
+	}
+
+	header := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	find := func(names []string) int {
+		for _, name := range names {
+			if i, ok := header[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+	if find(columns.password) < 0 {
+		return nil, fmt.Errorf("column %q not found, check export format", columns.password[0])
+	}
+
+	title, uri, login, password := find(columns.title), find(columns.url), find(columns.login), find(columns.password)
+	notes, totp, kind := find(columns.notes), find(columns.totp), find(columns.kind)
+
+	var result []Record
+	for _, row := range rows[1:] {
+		get := func(i int) string {
+			if i < 0 || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+		if len(row) == 1 && get(0) == "" {
+			continue
+		}
+
+		isNote := get(kind) == "note" || (get(login) == "" && get(password) == "" && get(notes) != "")
+		if isNote {
+			result = append(result, textRecord(get(title), get(notes)))
+			continue
+		}
+		result = append(result, loginRecord(get(title), get(uri), get(login), get(password), get(totp), get(notes)))
+	}
+	return result, nil
+}
@@ -0,0 +1,452 @@
+// Package importer импортирует записи из экспортов других менеджеров паролей:
+// Bitwarden (JSON и CSV), KeePass (CSV), Chrome, Firefox и 1Password (1PUX и CSV).
+// Файлы разбираются на клиенте, записи шифруются паролем пользователя перед отправкой на сервер
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// Format формат импортируемого файла
+type Format string
+
+const (
+	FormatBitwarden    Format = "bitwarden"
+	FormatBitwardenCSV Format = "bitwarden-csv"
+	FormatKeePassCSV   Format = "keepass-csv"
+	FormatChrome       Format = "chrome"
+	FormatFirefox      Format = "firefox"
+	Format1Password    Format = "1password"
+	Format1PasswordCSV Format = "1password-csv"
+)
+
+const (
+	// defaultKey ключ для записей без названия и URL
+	defaultKey = "imported"
+	// renamePattern ключ переименованной записи: исходный ключ и номер
+	renamePattern = "%s (%d)"
+)
+
+// Formats поддерживаемые форматы
+var Formats = []Format{FormatBitwarden, FormatBitwardenCSV, FormatKeePassCSV, FormatChrome, FormatFirefox, Format1Password, Format1PasswordCSV}
+
+// ErrUnknownFormat формат не поддерживается
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Conflict что делать, если запись с таким ключом уже есть
+type Conflict string
+
+const (
+	ConflictSkip      Conflict = "skip"
+	ConflictRename    Conflict = "rename"
+	ConflictOverwrite Conflict = "overwrite"
+)
+
+// ErrUnknownConflict неизвестный режим обработки конфликтов
+var ErrUnknownConflict = errors.New("unknown conflict mode, expected skip, rename or overwrite")
+
+// Action что будет сделано с записью
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionRename    Action = "rename"
+	ActionOverwrite Action = "overwrite"
+	ActionSkip      Action = "skip"
+)
+
+// Record импортируемая запись. Заполнено ровно одно из полей с данными
+type Record struct {
+	Item        types.Item            `json:"item"`
+	Login       *types.LoginPassword  `json:"login,omitempty"`
+	Card        *types.CreditCardData `json:"card,omitempty"`
+	Text        *types.TextData       `json:"text,omitempty"`
+	Binary      *types.BinaryData     `json:"binary,omitempty"`
+	Meta        *types.BinaryMeta     `json:"meta,omitempty"`
+	SSHKey      *types.SSHKeyData     `json:"ssh_key,omitempty"`
+	TOTP        *types.TOTPData       `json:"totp,omitempty"`
+	Attachments []Attachment          `json:"attachments,omitempty"`
+}
+
+// Attachment вложение записи, шифруется вместе с ней
+type Attachment struct {
+	Name     string `json:"name"`
+	MimeType string `json:"mime_type"`
+	Data     []byte `json:"data"`
+}
+
+// NotesAttachment имя вложения с заметками и URL импортированной записи. Info хранится на сервере открыто,
+// а в заметках менеджеров паролей бывают коды восстановления и ответы на секретные вопросы
+const NotesAttachment = "notes.txt"
+
+// Notes вложение с заметками и URL записи, без них - nil
+func Notes(uri, notes string) []Attachment {
+	content := info(uri, notes)
+	if content == "" {
+		return nil
+	}
+	return []Attachment{{Name: NotesAttachment, MimeType: "text/plain; charset=utf-8", Data: []byte(content)}}
+}
+
+// SplitNotes обратная к Notes операция: находит вложение с заметками и разбирает его на URL и текст
+func SplitNotes(attachments []Attachment) (uri, notes string, ok bool) {
+	for _, a := range attachments {
+		if a.Name != NotesAttachment {
+			continue
+		}
+		notes = string(a.Data)
+		if rest, found := strings.CutPrefix(notes, "URL: "); found {
+			uri, notes, _ = strings.Cut(rest, "\n")
+		}
+		return uri, notes, true
+	}
+	return "", "", false
+}
+
+// Entry строка отчёта об импорте
+type Entry struct {
+	Source string         `json:"source"`
+	Key    string         `json:"key"`
+	Type   types.ItemType `json:"type"`
+	Action Action         `json:"action"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// String строковое представление, показываемое пользователю
+func (e Entry) String() string {
+	var result string
+	switch e.Action {
+	case ActionRename:
+		result = fmt.Sprintf("%s: %s -> %s (%s)", e.Action, e.Source, e.Key, e.Type)
+	case ActionSkip:
+		result = fmt.Sprintf("%s: %s (%s), key already exists", e.Action, e.Source, e.Type)
+	default:
+		result = fmt.Sprintf("%s: %s (%s)", e.Action, e.Key, e.Type)
+	}
+	if e.Error != "" {
+		result += ": failed: " + e.Error
+	}
+	return result
+}
+
+// Summary результат импорта
+type Summary struct {
+	Entries     []Entry `json:"entries"`
+	Created     int     `json:"created"`
+	Renamed     int     `json:"renamed"`
+	Overwritten int     `json:"overwritten"`
+	Skipped     int     `json:"skipped"`
+	Failed      int     `json:"failed"`
+}
+
+// String итоговая строка отчёта
+func (s Summary) String() string {
+	return fmt.Sprintf("created: %d, renamed: %d, overwritten: %d, skipped: %d, failed: %d",
+		s.Created, s.Renamed, s.Overwritten, s.Skipped, s.Failed)
+}
+
+// ParseConflict проверяет режим обработки конфликтов
+func ParseConflict(s string) (Conflict, error) {
+	switch c := Conflict(s); c {
+	case ConflictSkip, ConflictRename, ConflictOverwrite:
+		return c, nil
+	}
+	return "", ErrUnknownConflict
+}
+
+// Parse разбирает файл экспорта в указанном формате
+func Parse(format Format, data []byte) ([]Record, error) {
+	switch format {
+	case FormatBitwarden:
+		return parseBitwarden(data)
+	case Format1Password:
+		return parse1PUX(data)
+	}
+	columns, ok := csvFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	return parseCSV(data, columns)
+}
+
+// Plan определяет, что будет сделано с каждой записью, с учётом ключей, уже существующих на сервере.
+// Совпадения ключей внутри самого файла при перезаписи переименовываются, а не затирают только что созданные записи
+func Plan(records []Record, existing []string, mode Conflict) []Entry {
+	onServer := make(map[string]bool, len(existing))
+	taken := make(map[string]bool, len(existing)+len(records))
+	for _, k := range existing {
+		onServer[k] = true
+		taken[k] = true
+	}
+
+	result := make([]Entry, 0, len(records))
+	for _, r := range records {
+		entry := Entry{Source: r.Item.Key, Key: r.Item.Key, Type: r.Item.Type, Action: ActionCreate}
+		switch {
+		case !taken[entry.Key]:
+		case mode == ConflictSkip:
+			entry.Action = ActionSkip
+		case mode == ConflictOverwrite && onServer[entry.Key]:
+			entry.Action = ActionOverwrite
+			// перезаписываем только один раз
+			delete(onServer, entry.Key)
+		default:
+			entry.Action = ActionRename
+			for n := 2; taken[entry.Key]; n++ {
+				entry.Key = fmt.Sprintf(renamePattern, entry.Source, n)
+			}
+		}
+		taken[entry.Key] = true
+		result = append(result, entry)
+	}
+	return result
+}
+
+// ErrNotEmpty восстановление в новый аккаунт, а в нём уже есть записи
+var ErrNotEmpty = errors.New("account is not empty, restore into a fresh account or merge")
+
+// Run импортирует записи. При dryRun на сервере ничего не меняется, возвращается только план
+func Run(token string, pass string, cli *client.Client, records []Record, mode Conflict, dryRun bool) (*Summary, error) {
+	existing, err := cli.AllRecords(token, pass)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(existing))
+	kinds := make(map[string]types.ItemType, len(existing))
+	for _, item := range existing {
+		keys = append(keys, item.Key)
+		kinds[item.Key] = item.Type
+	}
+	return run(token, pass, cli, records, Plan(records, keys, mode), kinds, dryRun), nil
+}
+
+// Restore восстанавливает записи в пустой аккаунт без изменений ключей. Если в аккаунте уже есть записи,
+// возвращается ErrNotEmpty
+func Restore(token string, pass string, cli *client.Client, records []Record, dryRun bool) (*Summary, error) {
+	existing, err := cli.AllRecords(token, pass)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, ErrNotEmpty
+	}
+	return run(token, pass, cli, records, Plan(records, nil, ConflictRename), nil, dryRun), nil
+}
+
+func run(token string, pass string, cli *client.Client, records []Record, plan []Entry, existing map[string]types.ItemType, dryRun bool) *Summary {
+	summary := &Summary{Entries: plan}
+	for i := range summary.Entries {
+		entry := &summary.Entries[i]
+		if !dryRun && entry.Action != ActionSkip {
+			err := save(token, pass, cli, records[i], entry, existing)
+			if err != nil {
+				entry.Error = err.Error()
+				summary.Failed++
+				continue
+			}
+		}
+		switch entry.Action {
+		case ActionCreate:
+			summary.Created++
+		case ActionRename:
+			summary.Renamed++
+		case ActionOverwrite:
+			summary.Overwritten++
+		case ActionSkip:
+			summary.Skipped++
+		}
+	}
+	return summary
+}
+
+func save(token string, pass string, cli *client.Client, r Record, entry *Entry, existing map[string]types.ItemType) error {
+	r = clone(r, entry.Key)
+	if entry.Action != ActionOverwrite {
+		err := create(token, pass, cli, r)
+		if err != nil {
+			return err
+		}
+		return uploadAttachments(token, pass, cli, entry.Key, r.Attachments, nil)
+	}
+
+	// запись обновляется, а не пересоздаётся, иначе пропали бы её вложения и выданные доступы
+	if t := existing[entry.Key]; t != r.Item.Type {
+		return fmt.Errorf("item %s has type %s, cannot overwrite it with %s", entry.Key, t, r.Item.Type)
+	}
+	err := Update(token, pass, cli, r)
+	if err != nil {
+		return err
+	}
+	var current []types.Attachment
+	if len(r.Attachments) > 0 {
+		current, err = cli.SeeAttachments(token, pass, entry.Key)
+		if err != nil {
+			return err
+		}
+	}
+	return uploadAttachments(token, pass, cli, entry.Key, r.Attachments, current)
+}
+
+// uploadAttachments загружает вложения. Вложение с тем же именем, что у одного из current, заменяется
+func uploadAttachments(token string, pass string, cli *client.Client, key string, attachments []Attachment, current []types.Attachment) error {
+	for _, a := range attachments {
+		// данные шифруются на месте
+		data := append([]byte{}, a.Data...)
+		meta := types.Attachment{Name: a.Name, MimeType: a.MimeType, Size: len(data)}
+		var err error
+		if i := slices.IndexFunc(current, func(c types.Attachment) bool { return c.Name == a.Name }); i >= 0 {
+			meta.ID = current[i].ID
+			err = cli.ReplaceAttachment(token, pass, key, meta, data)
+		} else {
+			_, err = cli.UploadAttachment(token, pass, key, meta, data)
+		}
+		if err != nil {
+			return fmt.Errorf("attachment %s: %w", a.Name, err)
+		}
+	}
+	return nil
+}
+
+// clone копия записи под ключом key. Данные шифруются на месте, поэтому сохраняем копии,
+// чтобы не испортить разобранные записи
+func clone(r Record, key string) Record {
+	c := r
+	c.Item.Key = key
+	switch {
+	case r.Login != nil:
+		data := *r.Login
+		c.Login = &data
+	case r.Card != nil:
+		data := *r.Card
+		c.Card = &data
+	case r.Text != nil:
+		data := *r.Text
+		c.Text = &data
+	case r.Binary != nil:
+		data := append(types.BinaryData{}, *r.Binary...)
+		c.Binary = &data
+		if r.Meta != nil {
+			meta := *r.Meta
+			c.Meta = &meta
+		}
+	case r.SSHKey != nil:
+		data := *r.SSHKey
+		c.SSHKey = &data
+	case r.TOTP != nil:
+		data := *r.TOTP
+		c.TOTP = &data
+	}
+	return c
+}
+
+func create(token string, pass string, cli *client.Client, r Record) error {
+	switch {
+	case r.Login != nil:
+		return client.CreateItem(token, pass, types.GenericItem[*types.LoginPassword]{Item: r.Item, Data: r.Login}, cli.CreateLoginPasswordItem)
+	case r.Card != nil:
+		return client.CreateItem(token, pass, types.GenericItem[*types.CreditCardData]{Item: r.Item, Data: r.Card}, cli.CreateCreditCardItem)
+	case r.Text != nil:
+		return client.CreateItem(token, pass, types.GenericItem[*types.TextData]{Item: r.Item, Data: r.Text}, cli.CreateTextItem)
+	case r.Binary != nil:
+		return client.CreateItem(token, pass, types.GenericItem[*types.BinaryData]{Item: r.Item, Data: r.Binary, Meta: r.Meta}, cli.CreateBinaryItem)
+	case r.SSHKey != nil:
+		return client.CreateItem(token, pass, types.GenericItem[*types.SSHKeyData]{Item: r.Item, Data: r.SSHKey}, cli.CreateSSHKeyItem)
+	case r.TOTP != nil:
+		return client.CreateItem(token, pass, types.GenericItem[*types.TOTPData]{Item: r.Item, Data: r.TOTP}, cli.CreateTOTPItem)
+	}
+	return fmt.Errorf("record %s has no data", r.Item.Key)
+}
+
+// Update обновляет запись в хранилище. Запись не пересоздаётся: при удалении пропали бы выданные доступы
+func Update(token string, pass string, cli *client.Client, r Record) error {
+	switch {
+	case r.Login != nil:
+		return client.UpdateItem(token, pass, types.GenericItem[*types.LoginPassword]{Item: r.Item, Data: r.Login}, cli.UpdateLogoPassData)
+	case r.Card != nil:
+		return client.UpdateItem(token, pass, types.GenericItem[*types.CreditCardData]{Item: r.Item, Data: r.Card}, cli.UpdateCreditCardData)
+	case r.Text != nil:
+		return client.UpdateItem(token, pass, types.GenericItem[*types.TextData]{Item: r.Item, Data: r.Text}, cli.UpdateTextData)
+	case r.SSHKey != nil:
+		return client.UpdateItem(token, pass, types.GenericItem[*types.SSHKeyData]{Item: r.Item, Data: r.SSHKey}, cli.UpdateSSHKeyData)
+	case r.TOTP != nil:
+		return client.UpdateItem(token, pass, types.GenericItem[*types.TOTPData]{Item: r.Item, Data: r.TOTP}, cli.UpdateTOTPData)
+	case r.Binary != nil:
+		return client.UpdateItem(token, pass, types.GenericItem[*types.BinaryData]{Item: r.Item, Data: r.Binary, Meta: r.Meta}, cli.UpdateBinaryItem)
+	}
+	return fmt.Errorf("no data for item type %s", r.Item.Type)
+}
+
+// loginRecord запись логина и пароля. Если названия нет, ключом становится хост из URL
+func loginRecord(title, uri, login, password, totp, notes string) Record {
+	return Record{
+		Item:        types.Item{Key: recordKey(title, uri), Type: types.TypeLogoPass},
+		Login:       &types.LoginPassword{Login: login, Password: password, TOTP: totp},
+		Attachments: Notes(uri, notes),
+	}
+}
+
+// textRecord текстовая запись, например защищённая заметка
+func textRecord(title, text string) Record {
+	data := types.TextData(text)
+	return Record{
+		Item: types.Item{Key: recordKey(title, ""), Type: types.TypeText},
+		Text: &data,
+	}
+}
+
+// cardRecord запись кредитной карты
+func cardRecord(title, holder, number, month, year, cvc, notes string) Record {
+	return Record{
+		Item:        types.Item{Key: recordKey(title, ""), Type: types.TypeCreditCard},
+		Attachments: Notes("", notes),
+		Card: &types.CreditCardData{
+			Number:     types.NormalizeCardNumber(number),
+			Name:       holder,
+			ValidMonth: strings.TrimLeft(month, "0"),
+			ValidYear:  year,
+			CVC:        cvc,
+		},
+	}
+}
+
+// binaryRecord запись с содержимым файла
+func binaryRecord(title, filename string, content []byte, notes string) Record {
+	data := types.BinaryData(content)
+	return Record{
+		Item:        types.Item{Key: recordKey(title, filename), Type: types.TypeBinary},
+		Binary:      &data,
+		Meta:        types.NewBinaryMeta(filename, content),
+		Attachments: Notes("", notes),
+	}
+}
+
+func recordKey(title, uri string) string {
+	if title = strings.TrimSpace(title); title != "" {
+		return title
+	}
+	if u, err := url.Parse(uri); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	if uri != "" {
+		return uri
+	}
+	return defaultKey
+}
+
+func info(uri, notes string) string {
+	var lines []string
+	if uri != "" {
+		lines = append(lines, "URL: "+uri)
+	}
+	if notes != "" {
+		lines = append(lines, notes)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,312 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func text(s string) *types.TextData {
+	t := types.TextData(s)
+	return &t
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		data   string
+		want   []Record
+	}{
+		{
+			name:   "chrome",
+			format: FormatChrome,
+			data:   "name,url,username,password,note\nexample.com,https://example.com/login,me,secret,\n",
+			want: []Record{{
+				Item:        types.Item{Key: "example.com", Type: types.TypeLogoPass},
+				Login:       &types.LoginPassword{Login: "me", Password: "secret"},
+				Attachments: Notes("https://example.com/login", ""),
+			}},
+		},
+		{
+			name:   "firefox without title",
+			format: FormatFirefox,
+			data: `"url","username","password","httpRealm","formActionOrigin","guid","timeCreated","timeLastUsed","timePasswordChanged"
+"https://mail.example.org","me","pw","","https://mail.example.org","{1}","1","1","1"
+`,
+			want: []Record{{
+				Item:        types.Item{Key: "mail.example.org", Type: types.TypeLogoPass},
+				Login:       &types.LoginPassword{Login: "me", Password: "pw"},
+				Attachments: Notes("https://mail.example.org", ""),
+			}},
+		},
+		{
+			name:   "keepassxc with note",
+			format: FormatKeePassCSV,
+			data: "\xef\xbb\xbf\"Group\",\"Title\",\"Username\",\"Password\",\"URL\",\"Notes\",\"TOTP\"\n" +
+				"\"Root\",\"Bank\",\"u\",\"p\",\"\",\"pin 1234\",\"otpauth://totp/Bank?secret=JBSWY3DPEHPK3PXP\"\n" +
+				"\"Root\",\"Wifi\",\"\",\"\",\"\",\"guest network\",\"\"\n",
+			want: []Record{
+				{
+					Item:        types.Item{Key: "Bank", Type: types.TypeLogoPass},
+					Login:       &types.LoginPassword{Login: "u", Password: "p", TOTP: "otpauth://totp/Bank?secret=JBSWY3DPEHPK3PXP"},
+					Attachments: Notes("", "pin 1234"),
+				},
+				{Item: types.Item{Key: "Wifi", Type: types.TypeText}, Text: text("guest network")},
+			},
+		},
+		{
+			name:   "bitwarden csv",
+			format: FormatBitwardenCSV,
+			data: "folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp\n" +
+				",,note,Memo,remember this,,0,,,,\n" +
+				",1,login,Git,,,0,https://git.example.com,dev,pw,\n",
+			want: []Record{
+				{Item: types.Item{Key: "Memo", Type: types.TypeText}, Text: text("remember this")},
+				{
+					Item:        types.Item{Key: "Git", Type: types.TypeLogoPass},
+					Login:       &types.LoginPassword{Login: "dev", Password: "pw"},
+					Attachments: Notes("https://git.example.com", ""),
+				},
+			},
+		},
+		{
+			name:   "1password csv",
+			format: Format1PasswordCSV,
+			data:   "Title,Website,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes\nShop,,buyer,pw,,false,false,,\n",
+			want: []Record{{
+				Item:  types.Item{Key: "Shop", Type: types.TypeLogoPass},
+				Login: &types.LoginPassword{Login: "buyer", Password: "pw"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.format, []byte(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseCSVWrongFormat(t *testing.T) {
+	_, err := Parse(FormatBitwardenCSV, []byte("name,url,username,password,note\n"))
+	assert.Error(t, err)
+
+	_, err = Parse("lastpass", []byte(""))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestParseBitwarden(t *testing.T) {
+	data := `{"encrypted": false, "items": [
+		{"type": 1, "name": "Mail", "notes": null, "login": {"username": "me", "password": "pw", "totp": "JBSWY3DPEHPK3PXP", "uris": [{"uri": "https://mail.example.com"}]}},
+		{"type": 2, "name": "Note", "notes": "text"},
+		{"type": 3, "name": "Visa", "card": {"cardholderName": "J DOE", "number": "4111 1111 1111 1111", "expMonth": "07", "expYear": "2030", "code": "123"}},
+		{"type": 4, "name": "Me", "identity": {"firstName": "John", "lastName": "Doe", "email": null}}
+	]}`
+	got, err := Parse(FormatBitwarden, []byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, []Record{
+		{
+			Item:        types.Item{Key: "Mail", Type: types.TypeLogoPass},
+			Login:       &types.LoginPassword{Login: "me", Password: "pw", TOTP: "JBSWY3DPEHPK3PXP"},
+			Attachments: Notes("https://mail.example.com", ""),
+		},
+		{Item: types.Item{Key: "Note", Type: types.TypeText}, Text: text("text")},
+		{
+			Item: types.Item{Key: "Visa", Type: types.TypeCreditCard},
+			Card: &types.CreditCardData{Number: "4111111111111111", Name: "J DOE", ValidMonth: "7", ValidYear: "2030", CVC: "123"},
+		},
+		{Item: types.Item{Key: "Me", Type: types.TypeText}, Text: text("firstName: John\nlastName: Doe")},
+	}, got)
+
+	_, err = Parse(FormatBitwarden, []byte(`{"encrypted": true, "data": "..."}`))
+	assert.ErrorIs(t, err, ErrEncryptedExport)
+}
+
+func make1PUX(t *testing.T, export string, files map[string]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create(onePasswordData)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(export))
+	require.NoError(t, err)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestParse1PUX(t *testing.T) {
+	export := `{"accounts": [{"vaults": [{"items": [
+		{"categoryUuid": "001", "overview": {"title": "Site", "url": "https://site.example"},
+		 "details": {"loginFields": [{"designation": "username", "value": "me"}, {"designation": "password", "value": "pw"}],
+		             "sections": [{"fields": [{"id": "TOTP_1", "value": {"totp": "otpauth://totp/Site?secret=JBSWY3DPEHPK3PXP"}}]}]}},
+		{"categoryUuid": "002", "overview": {"title": "Card"},
+		 "details": {"sections": [{"fields": [
+			{"id": "cardholder", "value": {"string": "J DOE"}},
+			{"id": "ccnum", "value": {"creditCardNumber": "4111111111111111"}},
+			{"id": "cvv", "value": {"concealed": "321"}},
+			{"id": "expiry", "value": {"monthYear": 202612}}]}]}},
+		{"categoryUuid": "003", "overview": {"title": "Memo"}, "details": {"notesPlain": "hello"}},
+		{"categoryUuid": "006", "overview": {"title": "Passport scan"},
+		 "details": {"documentAttributes": {"fileName": "passport.txt", "documentId": "abc"}}},
+		{"categoryUuid": "004", "overview": {"title": "Identity"}, "details": {}}
+	]}]}]}`
+	data := make1PUX(t, export, map[string]string{"files/abc__passport.txt": "scan"})
+
+	got, err := Parse(Format1Password, data)
+	require.NoError(t, err)
+	require.Len(t, got, 4)
+	assert.Equal(t, Record{
+		Item:        types.Item{Key: "Site", Type: types.TypeLogoPass},
+		Login:       &types.LoginPassword{Login: "me", Password: "pw", TOTP: "otpauth://totp/Site?secret=JBSWY3DPEHPK3PXP"},
+		Attachments: Notes("https://site.example", ""),
+	}, got[0])
+	assert.Equal(t, &types.CreditCardData{Number: "4111111111111111", Name: "J DOE", ValidMonth: "12", ValidYear: "2026", CVC: "321"}, got[1].Card)
+	assert.Equal(t, text("hello"), got[2].Text)
+	assert.Equal(t, types.TypeBinary, got[3].Item.Type)
+	assert.Equal(t, "scan", string(*got[3].Binary))
+	assert.Equal(t, "passport.txt", got[3].Meta.FileName)
+}
+
+func TestPlan(t *testing.T) {
+	records := []Record{
+		{Item: types.Item{Key: "a", Type: types.TypeText}},
+		{Item: types.Item{Key: "b", Type: types.TypeText}},
+		{Item: types.Item{Key: "b", Type: types.TypeText}},
+	}
+	existing := []string{"a", "a (2)"}
+
+	assert.Equal(t, []Entry{
+		{Source: "a", Key: "a", Type: types.TypeText, Action: ActionSkip},
+		{Source: "b", Key: "b", Type: types.TypeText, Action: ActionCreate},
+		{Source: "b", Key: "b", Type: types.TypeText, Action: ActionSkip},
+	}, Plan(records, existing, ConflictSkip))
+
+	assert.Equal(t, []Entry{
+		{Source: "a", Key: "a (3)", Type: types.TypeText, Action: ActionRename},
+		{Source: "b", Key: "b", Type: types.TypeText, Action: ActionCreate},
+		{Source: "b", Key: "b (2)", Type: types.TypeText, Action: ActionRename},
+	}, Plan(records, existing, ConflictRename))
+
+	assert.Equal(t, []Entry{
+		{Source: "a", Key: "a", Type: types.TypeText, Action: ActionOverwrite},
+		{Source: "b", Key: "b", Type: types.TypeText, Action: ActionCreate},
+		{Source: "b", Key: "b (2)", Type: types.TypeText, Action: ActionRename},
+	}, Plan(records, existing, ConflictOverwrite))
+}
+
+func TestRun(t *testing.T) {
+	pass := "secret"
+	existing := []types.Item{{Key: "mail", Type: types.TypeLogoPass}, {Key: "pin", Type: types.TypeText}}
+	for i := range existing {
+		require.NoError(t, existing[i].Encrypt(pass))
+	}
+	mailNotes := types.Attachment{ID: 7, Name: NotesAttachment, MimeType: "text/plain"}
+	require.NoError(t, mailNotes.Encrypt(pass))
+
+	var mu sync.Mutex
+	var requests []string
+	var created, updated []types.LoginPasswordItem
+	var notes []byte
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/api/item/list":
+			_ = json.NewEncoder(w).Encode(existing)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/item/mail/attachments":
+			_ = json.NewEncoder(w).Encode([]types.Attachment{mailNotes})
+		case r.URL.Path == "/api/item/mail/attachments/7":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/api/item/bank/attachments" || r.URL.Path == "/api/item/mail/attachments":
+			notes, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(types.CreatedAttachment{ID: 1})
+		case r.Method == http.MethodPost || r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			var item types.LoginPasswordItem
+			_ = json.Unmarshal(body, &item)
+			if r.Method == http.MethodPut {
+				updated = append(updated, item)
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			created = append(created, item)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	conf, _ := config.NewClientConfig()
+	conf.ServerAddress = svr.URL
+	conf.SSLKey = "../../../.ssl/ca.key"
+	cli, err := client.NewClient(conf)
+	require.NoError(t, err)
+
+	records := []Record{
+		loginRecord("mail", "", "me", "pw", "", "new notes"),
+		loginRecord("bank", "https://bank.example", "me", "pw2", "", "pin 1234"),
+		loginRecord("pin", "", "me", "pw3", "", ""),
+	}
+
+	summary, err := Run("token", pass, cli, records, ConflictRename, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Created)
+	assert.Equal(t, 2, summary.Renamed)
+	assert.Equal(t, []string{"GET /api/item/list"}, requests)
+	assert.Empty(t, created)
+
+	requests = nil
+	summary, err = Run("token", pass, cli, records, ConflictOverwrite, false)
+	require.NoError(t, err)
+	assert.Equal(t, "created: 1, renamed: 0, overwritten: 1, skipped: 0, failed: 1", summary.String())
+	// существующая запись обновляется на месте, её вложение с заметками заменяется
+	assert.Equal(t, []string{"GET /api/item/list", "PUT /api/item/login_password", "GET /api/item/mail/attachments",
+		"PUT /api/item/mail/attachments/7", "POST /api/item/login_password", "POST /api/item/bank/attachments"}, requests)
+	// тип записи при перезаписи не меняется
+	assert.Contains(t, summary.Entries[2].Error, "cannot overwrite")
+
+	require.Len(t, updated, 1)
+	assert.Equal(t, "mail", updated[0].Item.Key)
+	require.NoError(t, updated[0].Data.Decrypt(pass))
+	assert.Equal(t, "pw", updated[0].Data.Password)
+	require.Len(t, created, 1)
+	// заметки и URL уходят на сервер только зашифрованными
+	assert.Empty(t, created[0].Item.Info)
+	decrypted := types.BinaryData(notes)
+	require.NoError(t, decrypted.Decrypt(pass))
+	assert.Equal(t, "URL: https://bank.example\npin 1234", string(decrypted))
+	// исходные записи не зашифрованы повторно
+	assert.Equal(t, "pw", records[0].Login.Password)
+
+	// восстановление возможно только в пустую учётную запись
+	_, err = Restore("token", pass, cli, records, false)
+	assert.ErrorIs(t, err, ErrNotEmpty)
+
+	existing = nil
+	created = nil
+	summary, err = Restore("token", pass, cli, records, false)
+	require.NoError(t, err)
+	assert.Equal(t, "created: 3, renamed: 0, overwritten: 0, skipped: 0, failed: 0", summary.String())
+	require.Len(t, created, 3)
+}
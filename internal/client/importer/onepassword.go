@@ -0,0 +1,181 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// категории записей 1Password
+const (
+	onePasswordLogin    = "001"
+	onePasswordCard     = "002"
+	onePasswordNote     = "003"
+	onePasswordPassword = "005"
+	onePasswordDocument = "006"
+)
+
+// onePasswordData файл с записями внутри архива 1PUX, вложенные файлы лежат в files/
+const onePasswordData = "export.data"
+
+type onePasswordExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Items []onePasswordItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type onePasswordItem struct {
+	CategoryUUID string `json:"categoryUuid"`
+	Overview     struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+	} `json:"overview"`
+	Details struct {
+		LoginFields []struct {
+			Designation string `json:"designation"`
+			Value       string `json:"value"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Password   string `json:"password"`
+		Sections   []struct {
+			Fields []struct {
+				ID    string           `json:"id"`
+				Value onePasswordValue `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+		DocumentAttributes *struct {
+			FileName   string `json:"fileName"`
+			DocumentID string `json:"documentId"`
+		} `json:"documentAttributes"`
+	} `json:"details"`
+}
+
+// onePasswordValue значение поля, заполнен только один вариант в зависимости от типа поля
+type onePasswordValue struct {
+	String           string `json:"string"`
+	Concealed        string `json:"concealed"`
+	CreditCardNumber string `json:"creditCardNumber"`
+	TOTP             string `json:"totp"`
+	MonthYear        int    `json:"monthYear"`
+}
+
+func (v onePasswordValue) text() string {
+	for _, s := range []string{v.String, v.Concealed, v.CreditCardNumber, v.TOTP} {
+		if s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// parse1PUX разбирает архив 1PUX: логины, пароли, кредитные карты, заметки и документы
+func parse1PUX(data []byte) ([]Record, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a 1PUX archive: %w", err)
+	}
+
+	var export onePasswordExport
+	err = readJSON(archive, onePasswordData, &export)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Record
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for _, item := range vault.Items {
+				record, ok, err := onePasswordRecord(archive, item)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					result = append(result, record)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func onePasswordRecord(archive *zip.Reader, item onePasswordItem) (Record, bool, error) {
+	details := item.Details
+	fields := make(map[string]string)
+	var totp string
+	var month, year string
+	for _, section := range details.Sections {
+		for _, f := range section.Fields {
+			fields[f.ID] = f.Value.text()
+			if f.Value.TOTP != "" && totp == "" {
+				totp = f.Value.TOTP
+			}
+			if f.Value.MonthYear > 0 {
+				// формат YYYYMM
+				year = strconv.Itoa(f.Value.MonthYear / 100)
+				month = strconv.Itoa(f.Value.MonthYear % 100)
+			}
+		}
+	}
+
+	switch item.CategoryUUID {
+	case onePasswordLogin, onePasswordPassword:
+		var login string
+		password := details.Password
+		for _, f := range details.LoginFields {
+			switch f.Designation {
+			case "username":
+				login = f.Value
+			case "password":
+				password = f.Value
+			}
+		}
+		return loginRecord(item.Overview.Title, item.Overview.URL, login, password, totp, details.NotesPlain), true, nil
+	case onePasswordCard:
+		return cardRecord(item.Overview.Title, fields["cardholder"], fields["ccnum"], month, year, fields["cvv"], details.NotesPlain), true, nil
+	case onePasswordDocument:
+		if details.DocumentAttributes == nil {
+			return Record{}, false, nil
+		}
+		attrs := details.DocumentAttributes
+		content, err := readFile(archive, "files/"+attrs.DocumentID)
+		if err != nil {
+			return Record{}, false, err
+		}
+		return binaryRecord(item.Overview.Title, attrs.FileName, content, details.NotesPlain), true, nil
+	}
+	if details.NotesPlain != "" || item.CategoryUUID == onePasswordNote {
+		return textRecord(item.Overview.Title, details.NotesPlain), true, nil
+	}
+	return Record{}, false, nil
+}
+
+func readJSON(archive *zip.Reader, name string, v interface{}) error {
+	data, err := readFile(archive, name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// readFile читает файл из архива. Документы 1Password называются files/<documentId>__<имя файла>,
+// поэтому ищется первый файл с таким префиксом
+func readFile(archive *zip.Reader, prefix string) ([]byte, error) {
+	for _, f := range archive.File {
+		if !strings.HasPrefix(f.Name, prefix) {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	}
+	return nil, fmt.Errorf("%s not found in archive", prefix)
+}
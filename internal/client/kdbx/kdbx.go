@@ -0,0 +1,325 @@
+// Package kdbx читает и записывает базы KeePass в формате KDBX 4 (KDF Argon2, шифрование ChaCha20 или AES,
+// защита паролей внутренним потоком шифрования). Группы KeePass соответствуют префиксам ключей через "/":
+// запись "Title" в группе "Work/Mail" становится записью с ключом "Work/Mail/Title" и наоборот.
+// Корневая группа и корзина в ключи не попадают
+package kdbx
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tobischo/gokeepasslib/v3"
+	w "github.com/tobischo/gokeepasslib/v3/wrappers"
+
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// Cipher алгоритм шифрования базы
+type Cipher string
+
+const (
+	CipherChaCha20 Cipher = "chacha20"
+	CipherAES      Cipher = "aes"
+)
+
+// параметры Argon2 как у KeePassXC по умолчанию
+const (
+	argon2Memory     = 64 * 1024 * 1024
+	argon2Iterations = 10
+)
+
+// rootGroupName имя корневой группы при выгрузке
+const rootGroupName = "gophkeeper"
+
+// groupSeparator разделитель групп в ключе
+const groupSeparator = "/"
+
+// стандартные поля записи KeePass
+const (
+	fieldTitle    = "Title"
+	fieldUserName = "UserName"
+	fieldPassword = "Password"
+	fieldURL      = "URL"
+	fieldNotes    = "Notes"
+	// otp поле KeePassXC с otpauth:// URI
+	fieldOTP = "otp"
+)
+
+// дополнительные поля для типов, которых нет в KeePass
+const (
+	fieldCardNumber = "Card number"
+	fieldCardHolder = "Cardholder"
+	fieldCardExpiry = "Expiry"
+	fieldCardCVC    = "CVC"
+	fieldPrivateKey = "Private key"
+	fieldPublicKey  = "Public key"
+	fieldPassphrase = "Passphrase"
+	fieldComment    = "Comment"
+)
+
+// urlPrefix строка с адресом в заметках записей, импортированных из других менеджеров паролей
+const urlPrefix = "URL: "
+
+// Read читает базу KDBX и преобразует записи. Вложения записей становятся отдельными бинарными записями
+// с ключом "<ключ записи>/<имя файла>"
+func Read(r io.Reader, password string) ([]importer.Record, error) {
+	db := gokeepasslib.NewDatabase()
+	db.Credentials = gokeepasslib.NewPasswordCredentials(password)
+	err := gokeepasslib.NewDecoder(r).Decode(db)
+	if err != nil {
+		return nil, fmt.Errorf("could not open KeePass database: %w", err)
+	}
+	err = db.UnlockProtectedEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var recycleBin *gokeepasslib.UUID
+	if db.Content.Meta != nil && db.Content.Meta.RecycleBinEnabled.Bool {
+		recycleBin = &db.Content.Meta.RecycleBinUUID
+	}
+
+	var result []importer.Record
+	for _, root := range db.Content.Root.Groups {
+		records, err := readGroup(db, root, "", recycleBin)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, records...)
+	}
+	return result, nil
+}
+
+func readGroup(db *gokeepasslib.Database, group gokeepasslib.Group, prefix string, recycleBin *gokeepasslib.UUID) ([]importer.Record, error) {
+	var result []importer.Record
+	for _, entry := range group.Entries {
+		records, err := readEntry(db, entry, prefix)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, records...)
+	}
+	for _, sub := range group.Groups {
+		if recycleBin != nil && sub.UUID.Compare(*recycleBin) {
+			continue
+		}
+		records, err := readGroup(db, sub, prefix+sub.Name+groupSeparator, recycleBin)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, records...)
+	}
+	return result, nil
+}
+
+func readEntry(db *gokeepasslib.Database, entry gokeepasslib.Entry, prefix string) ([]importer.Record, error) {
+	title := entry.GetTitle()
+	if title == "" {
+		title = entry.GetContent(fieldURL)
+	}
+	item := types.Item{Key: prefix + title}
+	if entry.Times.Expires.Bool && entry.Times.ExpiryTime != nil {
+		item.ExpiresAt = entry.Times.ExpiryTime.Time.Format(types.DateFormat)
+	}
+
+	login := entry.GetContent(fieldUserName)
+	password := entry.GetPassword()
+	notes := entry.GetContent(fieldNotes)
+	otp := entry.GetContent(fieldOTP)
+
+	var attachments []importer.Record
+	for _, ref := range entry.Binaries {
+		binary := db.FindBinary(ref.Value.ID)
+		if binary == nil {
+			return nil, fmt.Errorf("%s: attachment %s not found", item.Key, ref.Name)
+		}
+		content, err := binary.GetContentBytes()
+		if err != nil {
+			return nil, err
+		}
+		data := types.BinaryData(content)
+		attachments = append(attachments, importer.Record{
+			Item:   types.Item{Key: item.Key + groupSeparator + ref.Name, Type: types.TypeBinary},
+			Binary: &data,
+			Meta:   types.NewBinaryMeta(ref.Name, content),
+		})
+	}
+
+	var record importer.Record
+	switch {
+	case entry.GetContent(fieldCardNumber) != "":
+		item.Type = types.TypeCreditCard
+		month, year, _ := strings.Cut(entry.GetContent(fieldCardExpiry), "/")
+		record = importer.Record{Item: item, Attachments: importer.Notes("", notes), Card: &types.CreditCardData{
+			Number:     types.NormalizeCardNumber(entry.GetContent(fieldCardNumber)),
+			Name:       entry.GetContent(fieldCardHolder),
+			ValidMonth: strings.TrimLeft(month, "0"),
+			ValidYear:  year,
+			CVC:        entry.GetContent(fieldCardCVC),
+		}}
+	case entry.GetContent(fieldPrivateKey) != "":
+		item.Type = types.TypeSSHKey
+		record = importer.Record{Item: item, Attachments: importer.Notes("", notes), SSHKey: &types.SSHKeyData{
+			PrivateKey: entry.GetContent(fieldPrivateKey),
+			PublicKey:  entry.GetContent(fieldPublicKey),
+			Passphrase: entry.GetContent(fieldPassphrase),
+			Comment:    entry.GetContent(fieldComment),
+		}}
+	case login == "" && password == "" && otp != "":
+		item.Type = types.TypeTOTP
+		record = importer.Record{Item: item, Attachments: importer.Notes("", notes), TOTP: &types.TOTPData{Secret: otp}}
+	case login == "" && password == "" && len(attachments) == 1:
+		// запись с единственным вложением - это бинарные данные под ключом самой записи
+		attachments[0].Item.Key = item.Key
+		attachments[0].Attachments = importer.Notes("", notes)
+		attachments[0].Item.ExpiresAt = item.ExpiresAt
+		return attachments, nil
+	case login == "" && password == "" && notes != "":
+		item.Type = types.TypeText
+		text := types.TextData(notes)
+		record = importer.Record{Item: item, Text: &text}
+	case login == "" && password == "":
+		// пустые записи, у которых есть только вложения
+		return attachments, nil
+	default:
+		item.Type = types.TypeLogoPass
+		record = importer.Record{
+			Item:        item,
+			Attachments: importer.Notes(entry.GetContent(fieldURL), notes),
+			Login:       &types.LoginPassword{Login: login, Password: password, TOTP: otp},
+		}
+	}
+	return append([]importer.Record{record}, attachments...), nil
+}
+
+// Write записывает записи в новую базу KDBX 4, защищённую паролем
+func Write(out io.Writer, records []importer.Record, password string, cipher Cipher) error {
+	db := gokeepasslib.NewDatabase(gokeepasslib.WithDatabaseKDBXVersion4())
+	db.Credentials = gokeepasslib.NewPasswordCredentials(password)
+	headers := db.Header.FileHeaders
+	headers.KdfParameters.Memory = argon2Memory
+	headers.KdfParameters.Iterations = argon2Iterations
+	switch cipher {
+	case CipherChaCha20, "":
+	case CipherAES:
+		headers.CipherID = gokeepasslib.CipherAES
+		// AES-CBC использует 16-байтовый IV, ChaCha20 - 12-байтовый
+		headers.EncryptionIV = make([]byte, 16)
+		_, err := rand.Read(headers.EncryptionIV)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown cipher %s, expected %s or %s", cipher, CipherChaCha20, CipherAES)
+	}
+
+	root := gokeepasslib.NewGroup(gokeepasslib.WithGroupFormattedTime(false))
+	root.Name = rootGroupName
+	for _, r := range records {
+		var path []string
+		title := r.Item.Key
+		if i := strings.LastIndex(r.Item.Key, groupSeparator); i > 0 && i < len(r.Item.Key)-1 {
+			path = strings.Split(r.Item.Key[:i], groupSeparator)
+			title = r.Item.Key[i+1:]
+		}
+		group := ensureGroup(&root, path)
+		group.Entries = append(group.Entries, writeEntry(db, r, title))
+	}
+	db.Content.Root.Groups = []gokeepasslib.Group{root}
+
+	err := db.LockProtectedEntries()
+	if err != nil {
+		return err
+	}
+	return gokeepasslib.NewEncoder(out).Encode(db)
+}
+
+// ensureGroup находит или создаёт вложенную группу. Указатель действителен только до следующего изменения дерева
+func ensureGroup(group *gokeepasslib.Group, path []string) *gokeepasslib.Group {
+	if len(path) == 0 {
+		return group
+	}
+	for i := range group.Groups {
+		if group.Groups[i].Name == path[0] {
+			return ensureGroup(&group.Groups[i], path[1:])
+		}
+	}
+	sub := gokeepasslib.NewGroup(gokeepasslib.WithGroupFormattedTime(false))
+	sub.Name = path[0]
+	group.Groups = append(group.Groups, sub)
+	return ensureGroup(&group.Groups[len(group.Groups)-1], path[1:])
+}
+
+func writeEntry(db *gokeepasslib.Database, r importer.Record, title string) gokeepasslib.Entry {
+	entry := gokeepasslib.NewEntry(gokeepasslib.WithEntryFormattedTime(false))
+	add := func(key, value string, protected bool) {
+		if value == "" && key != fieldTitle && key != fieldPassword {
+			return
+		}
+		v := gokeepasslib.V{Content: value}
+		if protected {
+			v.Protected = w.NewBoolWrapper(true)
+		}
+		entry.Values = append(entry.Values, gokeepasslib.ValueData{Key: key, Value: v})
+	}
+	add(fieldTitle, title, false)
+
+	if expires, err := time.Parse(types.DateFormat, r.Item.ExpiresAt); err == nil {
+		entry.Times.Expires = w.NewBoolWrapper(true)
+		entry.Times.ExpiryTime = &w.TimeWrapper{Time: expires}
+	}
+
+	// заметки и URL хранятся во вложении, у старых записей - в Info
+	notes := r.Item.Info
+	url := ""
+	if u, n, ok := importer.SplitNotes(r.Attachments); ok {
+		url, notes = u, n
+	} else if rest, ok := strings.CutPrefix(notes, urlPrefix); ok {
+		url, notes, _ = strings.Cut(rest, "\n")
+	}
+	switch {
+	case r.Login != nil:
+		add(fieldURL, url, false)
+		add(fieldUserName, r.Login.Login, false)
+		add(fieldPassword, r.Login.Password, true)
+		add(fieldOTP, r.Login.TOTP, true)
+	case r.Card != nil:
+		add(fieldCardNumber, r.Card.Number, true)
+		add(fieldCardHolder, r.Card.Name, false)
+		if r.Card.ValidMonth != "" || r.Card.ValidYear != "" {
+			add(fieldCardExpiry, r.Card.ValidMonth+"/"+r.Card.ValidYear, false)
+		}
+		add(fieldCardCVC, r.Card.CVC, true)
+	case r.Text != nil:
+		// у текстовой записи Info не сохраняется, текст и есть заметка
+		notes = string(*r.Text)
+	case r.SSHKey != nil:
+		add(fieldPrivateKey, r.SSHKey.PrivateKey, true)
+		add(fieldPublicKey, r.SSHKey.PublicKey, false)
+		add(fieldPassphrase, r.SSHKey.Passphrase, true)
+		add(fieldComment, r.SSHKey.Comment, false)
+	case r.TOTP != nil:
+		add(fieldOTP, r.TOTP.Secret, true)
+	case r.Binary != nil:
+		name := title
+		if r.Meta != nil && r.Meta.FileName != "" {
+			name = r.Meta.FileName
+		}
+		binary := db.AddBinary(*r.Binary)
+		entry.Binaries = append(entry.Binaries, binary.CreateReference(name))
+	}
+	add(fieldNotes, notes, false)
+	// остальные вложения записи сохраняются вложениями KeePass
+	for _, a := range r.Attachments {
+		if a.Name == importer.NotesAttachment {
+			continue
+		}
+		binary := db.AddBinary(a.Data)
+		entry.Binaries = append(entry.Binaries, binary.CreateReference(a.Name))
+	}
+	return entry
+}
@@ -3,17 +3,41 @@ package menu
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
+	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/devices"
+	"github.com/wellywell/gophkeeper/internal/client/emergency"
+	"github.com/wellywell/gophkeeper/internal/client/health"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
 	"github.com/wellywell/gophkeeper/internal/client/prompt"
+	"github.com/wellywell/gophkeeper/internal/client/recovery"
+	"github.com/wellywell/gophkeeper/internal/client/reminders"
+	"github.com/wellywell/gophkeeper/internal/client/sharing"
+	"github.com/wellywell/gophkeeper/internal/client/sshagent"
+	"github.com/wellywell/gophkeeper/internal/totp"
 	"github.com/wellywell/gophkeeper/internal/types"
 )
 
 // MainMenu корневое меню для выбора основных действий, доступных пользователю
 func MainMenu(token string, pass string, cli *client.Client) {
 
+	// вход с нового устройства ждёт подтверждения, пока пользователь не ответит здесь или запрос не истечёт
+	err := approveDevices(token, pass, cli)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	err = remindDeletion(token, cli)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+
 	for {
 		record, err := prompt.Menu()
 		if err != nil {
@@ -53,12 +77,52 @@ func MainMenu(token string, pass string, cli *client.Client) {
 			if err != nil {
 				fmt.Println(err.Error())
 			}
+		case prompt.ATTACHMENTS:
+			err = manageAttachments(token, pass, cli)
+			if err != nil {
+				fmt.Println(err.Error())
+			}
+		case prompt.DUE_SOON:
+			err = dueSoon(token, pass, cli)
+			if err != nil {
+				fmt.Println(err.Error())
+			}
+		case prompt.HEALTH:
+			err = vaultHealth(token, pass, cli)
+			if err != nil {
+				fmt.Println(err.Error())
+			}
+		case prompt.SHARED:
+			err = sharedWithMe(token, pass, cli)
+			if err != nil {
+				fmt.Println(err.Error())
+			}
+		case prompt.EMERGENCY:
+			err = emergencyAccess(token, pass, cli)
+			if err != nil {
+				fmt.Println(err.Error())
+			}
+		case prompt.AUDIT_LOG:
+			err = auditLog(token, cli)
+			if err != nil {
+				fmt.Println(err.Error())
+			}
+		case prompt.DEVICES:
+			err = manageDevices(token, pass, cli)
+			if err != nil {
+				fmt.Println(err.Error())
+			}
+		case prompt.DELETE_ME:
+			err = deleteAccount(token, cli)
+			if err != nil {
+				fmt.Println(err.Error())
+			}
 		}
 	}
 }
 
 // Authenticate аутентификация пользователя - авторизация существующего, либо регистрация нового
-func Authenticate(cli *client.Client) (string, string, error) {
+func Authenticate(cli *client.Client, deviceKeys *sharing.KeyPair) (string, string, error) {
 	authMethod, err := prompt.ChooseLoginOrRegister()
 	if err != nil {
 		fmt.Println(err.Error())
@@ -69,15 +133,74 @@ func Authenticate(cli *client.Client) (string, string, error) {
 
 	switch authMethod {
 	case prompt.LOGIN:
-		method = cli.Login
+		method = func(login string, password string) (string, error) {
+			token, err := cli.Login(login, password)
+			if !errors.Is(err, client.ErrEnrollmentRequired) {
+				return token, err
+			}
+			code, err := prompt.EnterEnrollmentCode()
+			if err != nil {
+				return "", err
+			}
+			cli.SetEnrollment(code)
+			return cli.Login(login, password)
+		}
 	case prompt.REGISTER:
-		method = cli.Register
+		method = func(login string, password string) (string, error) {
+			token, err := cli.Register(login, password)
+			if !errors.Is(err, client.ErrInviteRequired) {
+				return token, err
+			}
+			code, err := prompt.EnterInvite()
+			if err != nil {
+				return "", err
+			}
+			cli.SetInvite(code)
+			return cli.Register(login, password)
+		}
 	default:
 		fmt.Println("Error authenticating")
 		return "", "", err
 	}
 
-	return prompt.Authenticate(method)
+	// ключ хранилища, переданный устройством, подтвердившим вход
+	var escrowed string
+	login, token, password, err := prompt.Authenticate(func(login string, password string) (string, error) {
+		token, vaultKey, err := devices.Login(cli, deviceKeys, method, login, password, os.Stdout)
+		escrowed = vaultKey
+		return token, err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if escrowed != "" {
+		return token, escrowed, nil
+	}
+	// записи шифруются ключом хранилища, пароль только открывает его
+	if authMethod == prompt.LOGIN {
+		pass, err := recovery.Unlock(token, password, cli, os.Stdout)
+		return token, pass, err
+	}
+	pass, err := recovery.Setup(token, password, cli)
+	if err != nil {
+		return "", "", err
+	}
+	err = ShowRecoveryKit(login, pass)
+	return token, pass, err
+}
+
+// ShowRecoveryKit выводит ключ восстановления целиком или долями, по выбору пользователя
+func ShowRecoveryKit(login string, vaultKey string) error {
+	shares, threshold, err := prompt.EnterRecoveryShares()
+	if err != nil {
+		return err
+	}
+	err = recovery.WriteKit(os.Stdout, login, vaultKey, shares, threshold)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Without the password or this kit your vault can not be restored. Run `recover %s` to set a new password\n", login)
+	return nil
 }
 
 func addRecord(token string, pass string, cli *client.Client) {
@@ -147,7 +270,32 @@ func addRecord(token string, pass string, cli *client.Client) {
 			return
 		}
 		data := types.BinaryData(dat)
-		err = client.CreateItem(token, pass, types.GenericItem[*types.BinaryData]{Item: *item, Data: &data}, cli.CreateBinaryItem)
+		meta := types.NewBinaryMeta(filename, dat)
+		err = client.CreateItem(token, pass, types.GenericItem[*types.BinaryData]{Item: *item, Data: &data, Meta: meta}, cli.CreateBinaryItem)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+	case prompt.SSH_KEY:
+		item.Type = types.TypeSSHKey
+		key, err := enterSSHKey(types.SSHKeyData{})
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		err = client.CreateItem(token, pass, types.GenericItem[*types.SSHKeyData]{Item: *item, Data: key}, cli.CreateSSHKeyItem)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+	case prompt.TOTP:
+		item.Type = types.TypeTOTP
+		secret, err := prompt.EnterTOTP()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		err = client.CreateItem(token, pass, types.GenericItem[*types.TOTPData]{Item: *item, Data: secret}, cli.CreateTOTPItem)
 		if err != nil {
 			fmt.Println(err.Error())
 			return
@@ -167,6 +315,10 @@ func seeRecord(token string, pass string, key string, cli *client.Client) error
 	if err != nil {
 		return err
 	}
+	err = i.Item.Decrypt(pass)
+	if err != nil {
+		return err
+	}
 	fmt.Println(i.Item.String())
 	switch i.Item.Type {
 	case types.TypeLogoPass:
@@ -175,19 +327,49 @@ func seeRecord(token string, pass string, key string, cli *client.Client) error
 			return err
 		}
 		fmt.Println(logopassItem.Data.String())
+		if logopassItem.Data.TOTP != "" {
+			showTOTPCode(logopassItem.Data.TOTP)
+		}
+	case types.TypeTOTP:
+		secret, err := types.ParseItem[*types.TOTPData](data, pass)
+		if err != nil {
+			return err
+		}
+		fmt.Println(secret.Data.String())
+		showTOTPCode(secret.Data.Secret)
 	case types.TypeCreditCard:
 		card, err := types.ParseItem[*types.CreditCardData](data, pass)
 		if err != nil {
 			return err
 		}
 		fmt.Println(card.Data.String())
+		reveal, err := prompt.Confirm("Reveal card number and CVC?")
+		if err != nil {
+			return err
+		}
+		if reveal {
+			fmt.Println(card.Data.Reveal())
+		}
 	case types.TypeText:
 		text, err := types.ParseItem[*types.TextData](data, pass)
 		if err != nil {
 			return err
 		}
 		fmt.Println(text.Data.String())
+	case types.TypeSSHKey:
+		key, err := types.ParseItem[*types.SSHKeyData](data, pass)
+		if err != nil {
+			return err
+		}
+		fmt.Println(key.Data.String())
 	case types.TypeBinary:
+		binary, err := types.ParseItem[*types.BinaryData](data, pass)
+		if err != nil {
+			return err
+		}
+		if binary.Meta != nil {
+			fmt.Println(binary.Meta.String())
+		}
 		fmt.Println("to download binary content use download menu")
 	}
 	return nil
@@ -225,6 +407,184 @@ func listRecords(token string, pass string, cli *client.Client) error {
 	return nil
 }
 
+// manageDevices показывает устройства, на которых выполнен вход, и позволяет выйти на любом из них.
+// Сначала предлагает ответить на запросы входа с новых устройств
+func manageDevices(token string, pass string, cli *client.Client) error {
+	err := approveDevices(token, pass, cli)
+	if err != nil {
+		return err
+	}
+	sessions, err := cli.Sessions(token)
+	if err != nil {
+		return err
+	}
+	options := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		options = append(options, s.String())
+	}
+	choice, err := prompt.ChooseSession(options)
+	if err != nil || choice == prompt.CANCEL {
+		return err
+	}
+	if choice == prompt.SIGN_OUT_OTHERS {
+		n, err := cli.DeleteOtherSessions(token)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Signed out %d other devices\n", n)
+		return nil
+	}
+
+	for _, s := range sessions {
+		if s.String() != choice {
+			continue
+		}
+		ok, err := prompt.ConfirmSignOut(s.Device)
+		if err != nil || !ok {
+			return err
+		}
+		forget := false
+		if s.DeviceID != nil {
+			forget, err = prompt.ConfirmForgetDevice(s.Device)
+			if err != nil {
+				return err
+			}
+		}
+		// забытое устройство теряет все свои сессии
+		if forget {
+			err = cli.DeleteDevice(token, *s.DeviceID)
+		} else {
+			err = cli.DeleteSession(token, s.ID)
+		}
+		if err != nil {
+			return err
+		}
+		if s.Current {
+			fmt.Println("Signed out")
+			os.Exit(0)
+		}
+		fmt.Printf("Signed out %s\n", s.Device)
+	}
+	return nil
+}
+
+// approveDevices предлагает подтвердить или отклонить каждый ожидающий запрос на вход с нового устройства
+func approveDevices(token string, pass string, cli *client.Client) error {
+	pending, err := devices.Pending(token, cli)
+	if err != nil {
+		return err
+	}
+	for _, d := range pending {
+		action, err := prompt.ChooseDeviceRequest(fmt.Sprintf("%s (%s)", d.Name, d.RemoteAddr), types.DeviceFingerprint(d.PublicKey))
+		if err != nil {
+			return err
+		}
+		switch action {
+		case prompt.DEVICE_APPROVE:
+			err = devices.Approve(token, pass, cli, d)
+			if err == nil {
+				fmt.Printf("Approved login from %s\n", d.Name)
+			}
+		case prompt.DEVICE_REJECT:
+			err = cli.RejectDevice(token, d.ID)
+			if err == nil {
+				fmt.Printf("Rejected login from %s. If it was not you, change your password\n", d.Name)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteAccount удаляет учётную запись после подтверждения паролем, а если удаление уже запланировано,
+// предлагает его отменить
+func deleteAccount(token string, cli *client.Client) error {
+	deleteAt, err := cli.AccountDeletion(token)
+	if err != nil {
+		return err
+	}
+	if deleteAt != nil {
+		return cancelDeletion(token, cli, *deleteAt)
+	}
+
+	ok, err := prompt.Confirm(fmt.Sprintf("Delete your account with all records, attachments and shares? "+
+		"You can cancel within %d days", int(types.AccountDeletionGrace.Hours()/24)))
+	if err != nil || !ok {
+		return err
+	}
+	password, err := prompt.EnterSecret("Password: ")
+	if err != nil {
+		return err
+	}
+	at, err := cli.DeleteAccount(token, password)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Your account will be deleted on %s. Log in before that to cancel\n", at.Local().Format(time.RFC1123))
+	return nil
+}
+
+// remindDeletion напоминает о запланированном удалении учётной записи и предлагает его отменить
+func remindDeletion(token string, cli *client.Client) error {
+	deleteAt, err := cli.AccountDeletion(token)
+	if err != nil || deleteAt == nil {
+		return err
+	}
+	return cancelDeletion(token, cli, *deleteAt)
+}
+
+func cancelDeletion(token string, cli *client.Client, deleteAt time.Time) error {
+	cancel, err := prompt.Confirm(fmt.Sprintf("Your account will be deleted on %s. Cancel deletion?",
+		deleteAt.Local().Format(time.RFC1123)))
+	if err != nil || !cancel {
+		return err
+	}
+	err = cli.CancelAccountDeletion(token)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Deletion cancelled")
+	return nil
+}
+
+// auditLog показывает постранично журнал аудита: входы, попытки входа и действия с записями
+func auditLog(token string, cli *client.Client) error {
+
+	pageSize := 20
+	page := 1
+	for {
+		events, err := cli.AuditEvents(token, page, pageSize)
+		if err != nil {
+			return err
+		}
+		if page == 1 && len(events) == 0 {
+			fmt.Println("No activity recorded yet")
+		}
+		for _, e := range events {
+			fmt.Println(e.String())
+		}
+		if len(events) < pageSize {
+			break
+		}
+
+		action, err := prompt.NextBackExit()
+		if err != nil {
+			return err
+		}
+		switch action {
+		case prompt.EXIT:
+			os.Exit(0)
+		case prompt.CANCEL:
+			return nil
+		case prompt.NEXT:
+			page += 1
+		}
+	}
+	return nil
+}
+
 func showItems(items []types.Item) {
 	for _, i := range items {
 		fmt.Println(i.String())
@@ -236,6 +596,10 @@ func editRecord(token string, pass string, cli *client.Client) error {
 	if err != nil {
 		return err
 	}
+	return editRecordByKey(token, pass, key, cli)
+}
+
+func editRecordByKey(token string, pass string, key string, cli *client.Client) error {
 	data, err := cli.GetItem(token, key)
 	if err != nil {
 		return err
@@ -246,6 +610,10 @@ func editRecord(token string, pass string, cli *client.Client) error {
 	if err != nil {
 		return err
 	}
+	err = i.Item.Decrypt(pass)
+	if err != nil {
+		return err
+	}
 	fmt.Println(i.Item.String())
 
 	result, err := prompt.ChooseEditOrDelete()
@@ -266,34 +634,69 @@ func editRecord(token string, pass string, cli *client.Client) error {
 		fmt.Println("Deleted")
 		return nil
 	case prompt.EDIT:
-		switch i.Item.Type {
-		case types.TypeLogoPass:
-			logopassItem, err := types.ParseItem[*types.LoginPassword](data, pass)
-			if err != nil {
-				return err
-			}
-			return updateLogoPassData(token, pass, logopassItem, cli)
-		case types.TypeCreditCard:
-			card, err := types.ParseItem[*types.CreditCardData](data, pass)
-			if err != nil {
-				return err
-			}
-			return updateCreditCardData(token, pass, card, cli)
+		err = editData(token, pass, i.Item.Type, data, cli)
+		if err != nil {
+			return err
+		}
+		syncShares(token, pass, key, cli)
+	}
+	return nil
+}
 
-		case types.TypeText:
-			text, err := types.ParseItem[*types.TextData](data, pass)
-			if err != nil {
-				return err
-			}
-			return updateTextData(token, pass, text, cli)
+// syncShares после изменения записи владельцем обновляет её копии у пользователей, с которыми ею поделились
+func syncShares(token string, pass string, key string, cli *client.Client) {
+	count, err := sharing.Sync(token, pass, cli, key)
+	if err != nil {
+		fmt.Println("Could not update shared copies:", err.Error())
+		return
+	}
+	if count > 0 {
+		fmt.Printf("Updated %d shared copies\n", count)
+	}
+}
 
-		case types.TypeBinary:
-			data, err := types.ParseItem[*types.BinaryData](data, pass)
-			if err != nil {
-				return err
-			}
-			return updateBinaryData(token, pass, data, cli)
+func editData(token string, pass string, itemType types.ItemType, data []byte, cli *client.Client) error {
+	switch itemType {
+	case types.TypeLogoPass:
+		logopassItem, err := types.ParseItem[*types.LoginPassword](data, pass)
+		if err != nil {
+			return err
+		}
+		return updateLogoPassData(token, pass, logopassItem, cli)
+	case types.TypeCreditCard:
+		card, err := types.ParseItem[*types.CreditCardData](data, pass)
+		if err != nil {
+			return err
 		}
+		return updateCreditCardData(token, pass, card, cli)
+
+	case types.TypeText:
+		text, err := types.ParseItem[*types.TextData](data, pass)
+		if err != nil {
+			return err
+		}
+		return updateTextData(token, pass, text, cli)
+
+	case types.TypeSSHKey:
+		key, err := types.ParseItem[*types.SSHKeyData](data, pass)
+		if err != nil {
+			return err
+		}
+		return updateSSHKeyData(token, pass, key, cli)
+
+	case types.TypeTOTP:
+		secret, err := types.ParseItem[*types.TOTPData](data, pass)
+		if err != nil {
+			return err
+		}
+		return updateTOTPData(token, pass, secret, cli)
+
+	case types.TypeBinary:
+		data, err := types.ParseItem[*types.BinaryData](data, pass)
+		if err != nil {
+			return err
+		}
+		return updateBinaryData(token, pass, data, cli)
 	}
 	return nil
 }
@@ -303,12 +706,17 @@ func downloadData(token string, pass string, cli *client.Client) error {
 	if err != nil {
 		return err
 	}
-	data, err := cli.DownloadBinaryData(token, pass, key)
+	data, meta, err := cli.DownloadBinaryItem(token, pass, key)
 	if err != nil {
 		return err
 	}
 	fmt.Println("Download succeeded")
-	filename, err := prompt.EnterFile()
+	defaultName := ""
+	if meta != nil {
+		fmt.Println("Checksum verified")
+		defaultName = meta.FileName
+	}
+	filename, err := prompt.EnterFile(defaultName)
 	if err != nil {
 		return err
 	}
@@ -320,8 +728,137 @@ func downloadData(token string, pass string, cli *client.Client) error {
 	return nil
 }
 
+// DueSoonDays за сколько дней до наступления срока запись попадает в отчёт "Due soon"
+const DueSoonDays = 30
+
+func dueSoon(token string, pass string, cli *client.Client) error {
+	entries, err := reminders.Report(token, pass, cli, time.Now(), DueSoonDays)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Printf("Nothing due in the next %d days\n", DueSoonDays)
+	}
+	for _, e := range entries {
+		fmt.Println(e.String())
+	}
+	return nil
+}
+
+func vaultHealth(token string, pass string, cli *client.Client) error {
+	groups, err := health.Report(token, pass, cli, time.Now(), health.DefaultMaxAgeDays)
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		fmt.Println("No weak, reused or old passwords found")
+		return nil
+	}
+
+	keys := []string{}
+	seen := make(map[string]bool)
+	for _, g := range groups {
+		fmt.Printf("Severity %s:\n", g.Severity)
+		for _, f := range g.Findings {
+			fmt.Println("  " + f.String())
+			if !seen[f.Key] {
+				seen[f.Key] = true
+				keys = append(keys, f.Key)
+			}
+		}
+	}
+
+	for {
+		key, err := prompt.ChooseKeyToFix(keys)
+		if err != nil {
+			return err
+		}
+		if key == prompt.CANCEL {
+			return nil
+		}
+		err = editRecordByKey(token, pass, key, cli)
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+}
+
+func manageAttachments(token string, pass string, cli *client.Client) error {
+	key, err := prompt.EnterKey("")
+	if err != nil {
+		return err
+	}
+	attachments, err := cli.SeeAttachments(token, pass, key)
+	if err != nil {
+		return err
+	}
+	if len(attachments) == 0 {
+		fmt.Println("No attachments")
+	}
+	for _, a := range attachments {
+		fmt.Println(a.String())
+	}
+
+	action, err := prompt.ChooseAttachmentAction()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case prompt.ATTACHMENT_ADD:
+		filename, err := prompt.EnterFileName()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		mimeType := mime.TypeByExtension(filepath.Ext(filename))
+		if mimeType == "" {
+			mimeType = http.DetectContentType(data)
+		}
+		attachment := types.Attachment{Name: filepath.Base(filename), MimeType: mimeType, Size: len(data)}
+		_, err = cli.UploadAttachment(token, pass, key, attachment, data)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Attached")
+	case prompt.ATTACHMENT_DOWNLOAD, prompt.ATTACHMENT_REMOVE:
+		if len(attachments) == 0 {
+			return nil
+		}
+		attachment, err := prompt.ChooseAttachment(attachments)
+		if err != nil {
+			return err
+		}
+		if action == prompt.ATTACHMENT_REMOVE {
+			err = cli.DeleteAttachment(token, key, attachment.ID)
+			if err != nil {
+				return err
+			}
+			fmt.Println("Deleted")
+			return nil
+		}
+		data, err := cli.DownloadAttachment(token, pass, key, attachment.ID)
+		if err != nil {
+			return err
+		}
+		filename, err := prompt.EnterFile(attachment.Name)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(filename, data, 0600)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Saved to file")
+	}
+	return nil
+}
+
 func updateBinaryData(token string, pass string, data *types.GenericItem[*types.BinaryData], cli *client.Client) error {
-	meta, err := prompt.EnterMetadata(data.Item.Info)
+	item, err := prompt.EditItem(data.Item)
 	if err != nil {
 		return err
 	}
@@ -337,14 +874,14 @@ func updateBinaryData(token string, pass string, data *types.GenericItem[*types.
 	}
 	d := types.BinaryData(dat)
 
-	newItem := types.GenericItem[*types.BinaryData]{Item: types.Item{Key: data.Item.Key, Info: meta, Type: data.Item.Type}, Data: &d}
+	newItem := types.GenericItem[*types.BinaryData]{Item: item, Data: &d, Meta: types.NewBinaryMeta(filename, dat)}
 
 	return client.UpdateItem(token, pass, newItem, cli.UpdateBinaryItem)
 }
 
 func updateLogoPassData(token string, pass string, logopass *types.GenericItem[*types.LoginPassword], cli *client.Client) error {
 
-	meta, err := prompt.EnterMetadata(logopass.Item.Info)
+	item, err := prompt.EditItem(logopass.Item)
 	if err != nil {
 		return err
 	}
@@ -352,17 +889,17 @@ func updateLogoPassData(token string, pass string, logopass *types.GenericItem[*
 	if err != nil {
 		return err
 	}
-	if meta == logopass.Item.Info && *newLogoPass == *logopass.Data {
+	if item == logopass.Item && *newLogoPass == *logopass.Data {
 		return fmt.Errorf("nothing changed")
 	}
-	newItem := types.GenericItem[*types.LoginPassword]{Item: types.Item{Key: logopass.Item.Key, Info: meta}, Data: newLogoPass}
+	newItem := types.GenericItem[*types.LoginPassword]{Item: item, Data: newLogoPass}
 
 	return client.UpdateItem(token, pass, newItem, cli.UpdateLogoPassData)
 }
 
 func updateCreditCardData(token string, pass string, card *types.GenericItem[*types.CreditCardData], cli *client.Client) error {
 
-	meta, err := prompt.EnterMetadata(card.Item.Info)
+	item, err := prompt.EditItem(card.Item)
 	if err != nil {
 		return err
 	}
@@ -370,17 +907,17 @@ func updateCreditCardData(token string, pass string, card *types.GenericItem[*ty
 	if err != nil {
 		return err
 	}
-	if meta == card.Item.Info && *newData == *card.Data {
+	if item == card.Item && *newData == *card.Data {
 		return fmt.Errorf("nothing changed")
 	}
-	newItem := types.GenericItem[*types.CreditCardData]{Item: types.Item{Key: card.Item.Key, Info: meta}, Data: newData}
+	newItem := types.GenericItem[*types.CreditCardData]{Item: item, Data: newData}
 
 	return client.UpdateItem(token, pass, newItem, cli.UpdateCreditCardData)
 }
 
 func updateTextData(token string, pass string, text *types.GenericItem[*types.TextData], cli *client.Client) error {
 
-	meta, err := prompt.EnterMetadata(text.Item.Info)
+	item, err := prompt.EditItem(text.Item)
 	if err != nil {
 		return err
 	}
@@ -388,10 +925,264 @@ func updateTextData(token string, pass string, text *types.GenericItem[*types.Te
 	if err != nil {
 		return err
 	}
-	if meta == text.Item.Info && newData == *text.Data {
+	if item == text.Item && newData == *text.Data {
 		return fmt.Errorf("nothing changed")
 	}
-	newItem := types.GenericItem[*types.TextData]{Item: types.Item{Key: text.Item.Key, Info: meta}, Data: &newData}
+	newItem := types.GenericItem[*types.TextData]{Item: item, Data: &newData}
 
 	return client.UpdateItem(token, pass, newItem, cli.UpdateTextData)
 }
+
+func updateSSHKeyData(token string, pass string, key *types.GenericItem[*types.SSHKeyData], cli *client.Client) error {
+
+	item, err := prompt.EditItem(key.Item)
+	if err != nil {
+		return err
+	}
+	newData, err := enterSSHKey(*key.Data)
+	if err != nil {
+		return err
+	}
+	if item == key.Item && *newData == *key.Data {
+		return fmt.Errorf("nothing changed")
+	}
+	newItem := types.GenericItem[*types.SSHKeyData]{Item: item, Data: newData}
+
+	return client.UpdateItem(token, pass, newItem, cli.UpdateSSHKeyData)
+}
+
+func enterSSHKey(key types.SSHKeyData) (*types.SSHKeyData, error) {
+	newKey, err := prompt.EnterSSHKey(key)
+	if err != nil {
+		return nil, err
+	}
+	public, err := sshagent.PublicKey(*newKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	newKey.PublicKey = public
+	return newKey, nil
+}
+
+func updateTOTPData(token string, pass string, secret *types.GenericItem[*types.TOTPData], cli *client.Client) error {
+
+	item, err := prompt.EditItem(secret.Item)
+	if err != nil {
+		return err
+	}
+	newData, err := prompt.EnterTOTP()
+	if err != nil {
+		return err
+	}
+	if item == secret.Item && *newData == *secret.Data {
+		return fmt.Errorf("nothing changed")
+	}
+	newItem := types.GenericItem[*types.TOTPData]{Item: item, Data: newData}
+
+	return client.UpdateItem(token, pass, newItem, cli.UpdateTOTPData)
+}
+
+func showTOTPCode(secret string) {
+	key, err := totp.Parse(secret)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	now := time.Now()
+	code, err := key.Code(now)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Current code: %s (%d seconds left)\n", code, key.Remaining(now))
+}
+
+func sharedWithMe(token string, pass string, cli *client.Client) error {
+	shares, err := sharing.Received(token, pass, cli)
+	if err != nil {
+		return err
+	}
+	if len(shares) == 0 {
+		fmt.Println("Nobody has shared records with you yet")
+		return nil
+	}
+
+	options := make([]string, 0, len(shares))
+	for _, s := range shares {
+		options = append(options, s.String())
+	}
+	choice, err := prompt.ChooseShared(options)
+	if err != nil || choice == prompt.CANCEL {
+		return err
+	}
+	var shared sharing.Shared
+	for _, s := range shares {
+		if s.String() == choice {
+			shared = s
+		}
+	}
+
+	ShowRecord(shared.Record)
+	if shared.Permission != types.PermissionWrite || shared.Record.Binary != nil {
+		return nil
+	}
+	edit, err := prompt.Confirm("Edit shared record?")
+	if err != nil || !edit {
+		return err
+	}
+	record, err := editShared(shared.Record)
+	if err != nil {
+		return err
+	}
+	err = sharing.Update(token, cli, shared, record)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Saved. Ask %s to run `share pull %s %s` to apply your changes to their vault\n", shared.Owner, shared.Key, shared.Recipient)
+	return nil
+}
+
+func emergencyAccess(token string, pass string, cli *client.Client) error {
+	contacts, err := cli.EmergencyContacts(token)
+	if err != nil {
+		return err
+	}
+	grants, err := cli.EmergencyGrants(token)
+	if err != nil {
+		return err
+	}
+
+	entries := append(contacts, grants...)
+	options := make([]string, 0, len(entries))
+	for _, e := range entries {
+		options = append(options, e.String())
+	}
+	choice, err := prompt.ChooseEmergency(options)
+	if err != nil || choice == prompt.CANCEL {
+		return err
+	}
+	if choice == prompt.EMERGENCY_ADD {
+		grantee, waitDays, err := prompt.EnterEmergencyContact()
+		if err != nil {
+			return err
+		}
+		err = emergency.Designate(token, pass, cli, grantee, waitDays)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s can request access to your vault once they accept\n", grantee)
+		return nil
+	}
+
+	var access types.EmergencyAccess
+	grantor := false
+	for i, e := range entries {
+		if e.String() == choice {
+			access = e
+			grantor = i < len(contacts)
+		}
+	}
+
+	var actions []string
+	switch {
+	case grantor && access.Status == types.EmergencyRequested:
+		actions = []string{prompt.EMERGENCY_APPROVE, prompt.EMERGENCY_REJECT}
+	case !grantor && access.Status == types.EmergencyInvited:
+		actions = []string{prompt.EMERGENCY_ACCEPT}
+	case !grantor && access.Status == types.EmergencyAccepted:
+		actions = []string{prompt.EMERGENCY_REQUEST}
+	case !grantor && access.Status == types.EmergencyGranted:
+		actions = []string{prompt.EMERGENCY_VIEW}
+	}
+	action, err := prompt.ChooseEmergencyAction(append(actions, prompt.EMERGENCY_REMOVE))
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case prompt.EMERGENCY_ACCEPT:
+		return cli.AcceptEmergencyAccess(token, access.ID)
+	case prompt.EMERGENCY_REQUEST:
+		err = cli.RequestEmergencyAccess(token, access.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Requested. Access is released in %d days unless %s rejects it\n", access.WaitDays, access.Grantor)
+	case prompt.EMERGENCY_APPROVE:
+		return cli.ApproveEmergencyAccess(token, access.ID)
+	case prompt.EMERGENCY_REJECT:
+		return cli.RejectEmergencyAccess(token, access.ID)
+	case prompt.EMERGENCY_REMOVE:
+		return cli.DeleteEmergencyAccess(token, access.ID)
+	case prompt.EMERGENCY_VIEW:
+		records, err := emergency.Load(token, pass, cli, access.ID)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			fmt.Println(r.Item.String())
+		}
+		key, err := prompt.EnterKey("")
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			if r.Item.Key == key {
+				ShowRecord(r)
+				return nil
+			}
+		}
+		fmt.Printf("%s not found in vault of %s\n", key, access.Grantor)
+	}
+	return nil
+}
+
+// ShowRecord выводит расшифрованную запись, полученную не из личного хранилища
+func ShowRecord(r importer.Record) {
+	fmt.Println(r.Item.String())
+	switch {
+	case r.Login != nil:
+		fmt.Println(r.Login.String())
+		if r.Login.TOTP != "" {
+			showTOTPCode(r.Login.TOTP)
+		}
+	case r.Card != nil:
+		fmt.Println(r.Card.String())
+		reveal, err := prompt.Confirm("Reveal card number and CVC?")
+		if err == nil && reveal {
+			fmt.Println(r.Card.Reveal())
+		}
+	case r.Text != nil:
+		fmt.Println(r.Text.String())
+	case r.SSHKey != nil:
+		fmt.Println(r.SSHKey.String())
+	case r.TOTP != nil:
+		fmt.Println(r.TOTP.String())
+		showTOTPCode(r.TOTP.Secret)
+	case r.Binary != nil:
+		if r.Meta != nil {
+			fmt.Println(r.Meta.String())
+		}
+		fmt.Printf("%d bytes of binary data\n", len(*r.Binary))
+	}
+}
+
+// editShared редактирование данных общей записи. Название записи принадлежит владельцу и не меняется
+func editShared(r importer.Record) (importer.Record, error) {
+	var err error
+	switch {
+	case r.Login != nil:
+		r.Login, err = prompt.EnterLoginPassword(*r.Login)
+	case r.Card != nil:
+		r.Card, err = prompt.EnterCreditCardData(*r.Card)
+	case r.Text != nil:
+		var text types.TextData
+		text, err = prompt.EnterText(string(*r.Text))
+		r.Text = &text
+	case r.SSHKey != nil:
+		r.SSHKey, err = enterSSHKey(*r.SSHKey)
+	case r.TOTP != nil:
+		r.TOTP, err = prompt.EnterTOTP()
+	}
+	return r, err
+}
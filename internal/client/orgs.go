@@ -0,0 +1,94 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// CreateOrg создание организации. orgKey - ключ организации, зашифрованный открытым ключом пользователя
+func (c *Client) CreateOrg(token string, name string, orgKey string) error {
+	return c.requestJSON(token, http.MethodPost, "/api/org", types.Membership{Org: name, OrgKey: orgKey}, http.StatusCreated, nil)
+}
+
+// ListOrgs получение организаций пользователя и приглашений в них
+func (c *Client) ListOrgs(token string) ([]types.Membership, error) {
+	var memberships []types.Membership
+	err := c.requestJSON(token, http.MethodGet, "/api/org", nil, http.StatusOK, &memberships)
+	return memberships, err
+}
+
+// DeleteOrg удаление организации со всеми записями
+func (c *Client) DeleteOrg(token string, org string) error {
+	return c.requestJSON(token, http.MethodDelete, orgPath(org, ""), nil, http.StatusOK, nil)
+}
+
+// AcceptInvite подтверждение приглашения в организацию
+func (c *Client) AcceptInvite(token string, org string) error {
+	return c.requestJSON(token, http.MethodPost, orgPath(org, "/accept"), nil, http.StatusOK, nil)
+}
+
+// OrgMembers получение участников организации с их открытыми ключами
+func (c *Client) OrgMembers(token string, org string) ([]types.Membership, error) {
+	var members []types.Membership
+	err := c.requestJSON(token, http.MethodGet, orgPath(org, "/members"), nil, http.StatusOK, &members)
+	return members, err
+}
+
+// InviteMember приглашение пользователя invite.Username в организацию
+func (c *Client) InviteMember(token string, org string, invite types.Membership) error {
+	return c.requestJSON(token, http.MethodPost, orgPath(org, "/members"), invite, http.StatusCreated, nil)
+}
+
+// SetMemberRole смена роли участника организации
+func (c *Client) SetMemberRole(token string, org string, username string, role types.Role) error {
+	return c.requestJSON(token, http.MethodPut, orgPath(org, "/members/"+url.PathEscape(username)), types.Membership{Role: role}, http.StatusOK, nil)
+}
+
+// SetMemberCollections ограничение доступа участника коллекциями collections, пустой список снимает ограничение
+func (c *Client) SetMemberCollections(token string, org string, username string, collections []string) error {
+	path := orgPath(org, "/members/"+url.PathEscape(username)+"/collections")
+	return c.requestJSON(token, http.MethodPut, path, types.MemberCollections{Collections: collections}, http.StatusOK, nil)
+}
+
+// RemoveMember исключение участника из организации с ротацией ключа
+func (c *Client) RemoveMember(token string, org string, username string, rotation types.KeyRotation) error {
+	return c.requestJSON(token, http.MethodDelete, orgPath(org, "/members/"+url.PathEscape(username)), rotation, http.StatusOK, nil)
+}
+
+// OrgItems получение записей организации, если collection не пустая - только из этой коллекции
+func (c *Client) OrgItems(token string, org string, collection string) ([]types.OrgItem, error) {
+	path := orgPath(org, "/items")
+	if collection != "" {
+		path += "?collection=" + url.QueryEscape(collection)
+	}
+	var items []types.OrgItem
+	err := c.requestJSON(token, http.MethodGet, path, nil, http.StatusOK, &items)
+	return items, err
+}
+
+// GetOrgItem получение записи организации по ключу
+func (c *Client) GetOrgItem(token string, org string, key string) (*types.OrgItem, error) {
+	var item types.OrgItem
+	err := c.requestJSON(token, http.MethodGet, orgPath(org, "/items/"+url.PathEscape(key)), nil, http.StatusOK, &item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// PutOrgItem сохранение или замена записи организации
+func (c *Client) PutOrgItem(token string, org string, item types.OrgItem) error {
+	return c.requestJSON(token, http.MethodPut, orgPath(org, "/items"), item, http.StatusOK, nil)
+}
+
+// DeleteOrgItem удаление записи организации
+func (c *Client) DeleteOrgItem(token string, org string, key string) error {
+	return c.requestJSON(token, http.MethodDelete, orgPath(org, "/items/"+url.PathEscape(key)), nil, http.StatusOK, nil)
+}
+
+func orgPath(org string, path string) string {
+	return fmt.Sprintf("/api/org/%s%s", url.PathEscape(org), path)
+}
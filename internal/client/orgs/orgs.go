@@ -0,0 +1,207 @@
+// Package orgs общие хранилища команд. У организации есть симметричный ключ, им шифруются записи
+// организации. Каждому участнику ключ организации выдаётся зашифрованным его открытым ключом X25519
+// (см. пакет sharing), поэтому сервер хранит только шифротекст. При исключении участника ключ меняется:
+// клиент создаёт новый ключ, заново шифрует его для оставшихся участников и перешифровывает все записи
+package orgs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/export"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/client/sharing"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// ErrNotMember пользователь не состоит в организации
+var ErrNotMember = errors.New("you are not a member of this organization")
+
+// ErrRestrictedLeave участник, ограниченный частью коллекций, знает ключ организации, но не может перешифровать
+// все записи. Исключить его с ротацией ключа может только администратор
+var ErrRestrictedLeave = errors.New("restricted members cannot leave on their own, ask an administrator to remove you")
+
+// Vault открытое хранилище организации с расшифрованным ключом
+type Vault struct {
+	Membership types.Membership
+	key        []byte
+}
+
+// Item запись организации вместе с расшифрованными данными
+type Item struct {
+	types.OrgItem
+	Record importer.Record
+}
+
+// Create создаёт организацию, пользователь становится её владельцем
+func Create(token string, pass string, cli *client.Client, name string) error {
+	keys, err := sharing.EnsureKeys(token, pass, cli)
+	if err != nil {
+		return err
+	}
+	orgKey, err := sharing.NewDataKey()
+	if err != nil {
+		return err
+	}
+	wrapped, err := sharing.WrapKey(orgKey, keys.Public)
+	if err != nil {
+		return err
+	}
+	return cli.CreateOrg(token, name, wrapped)
+}
+
+// Open расшифровывает ключ организации org
+func Open(token string, pass string, cli *client.Client, org string) (*Vault, error) {
+	keys, err := sharing.EnsureKeys(token, pass, cli)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := cli.ListOrgs(token)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range memberships {
+		if m.Org != org {
+			continue
+		}
+		key, err := sharing.UnwrapKey(m.OrgKey, keys)
+		if err != nil {
+			return nil, err
+		}
+		return &Vault{Membership: m, key: key}, nil
+	}
+	return nil, fmt.Errorf("%s: %w", org, ErrNotMember)
+}
+
+// Invite приглашает пользователя username с ролью role
+func (v *Vault) Invite(token string, cli *client.Client, username string, role types.Role) error {
+	public, err := sharing.PublicKey(token, cli, username)
+	if err != nil {
+		return err
+	}
+	wrapped, err := sharing.WrapKey(v.key, public)
+	if err != nil {
+		return err
+	}
+	return cli.InviteMember(token, v.Membership.Org, types.Membership{Username: username, Role: role, OrgKey: wrapped})
+}
+
+// Remove исключает участника username (или самого пользователя - выход из организации) с ротацией ключа.
+// Без ротации выходит только приглашённый, не подтвердивший членство. Участник, ограниченный частью коллекций,
+// не может перешифровать все записи, поэтому выйти сам не может, получает ErrRestrictedLeave
+func (v *Vault) Remove(token string, cli *client.Client, username string) error {
+	if username == v.Membership.Username && !v.Membership.Accepted {
+		return cli.RemoveMember(token, v.Membership.Org, username, types.KeyRotation{KeyVersion: v.Membership.KeyVersion})
+	}
+	if username == v.Membership.Username && v.Membership.Restricted() {
+		return ErrRestrictedLeave
+	}
+	members, err := cli.OrgMembers(token, v.Membership.Org)
+	if err != nil {
+		return err
+	}
+	items, err := v.Items(token, cli, "")
+	if err != nil {
+		return err
+	}
+
+	newKey, err := sharing.NewDataKey()
+	if err != nil {
+		return err
+	}
+	rotation := types.KeyRotation{
+		KeyVersion: v.Membership.KeyVersion,
+		Keys:       make(map[string]string, len(members)),
+		Items:      make([]types.OrgItem, 0, len(items)),
+	}
+	for _, m := range members {
+		if m.Username == username {
+			continue
+		}
+		if m.PublicKey == nil {
+			return fmt.Errorf("%s: %w", m.Username, sharing.ErrNoKeys)
+		}
+		public, err := sharing.DecodeKey(*m.PublicKey)
+		if err != nil {
+			return err
+		}
+		rotation.Keys[m.Username], err = sharing.WrapKey(newKey, public)
+		if err != nil {
+			return err
+		}
+	}
+	for _, i := range items {
+		i.OrgItem.Data, err = sharing.SealRecord(newKey, i.Record)
+		if err != nil {
+			return err
+		}
+		rotation.Items = append(rotation.Items, i.OrgItem)
+	}
+
+	err = cli.RemoveMember(token, v.Membership.Org, username, rotation)
+	if err != nil {
+		return err
+	}
+	v.key = newKey
+	v.Membership.KeyVersion++
+	return nil
+}
+
+// Items расшифрованные записи организации, если collection не пустая - только из этой коллекции
+func (v *Vault) Items(token string, cli *client.Client, collection string) ([]Item, error) {
+	stored, err := cli.OrgItems(token, v.Membership.Org, collection)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Item, 0, len(stored))
+	for _, s := range stored {
+		item, err := v.open(s)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, nil
+}
+
+// Get расшифрованная запись организации по ключу
+func (v *Vault) Get(token string, cli *client.Client, key string) (*Item, error) {
+	stored, err := cli.GetOrgItem(token, v.Membership.Org, key)
+	if err != nil {
+		return nil, err
+	}
+	return v.open(*stored)
+}
+
+// Put шифрует запись ключом организации и сохраняет её в коллекцию collection
+func (v *Vault) Put(token string, cli *client.Client, collection string, record importer.Record) error {
+	data, err := sharing.SealRecord(v.key, record)
+	if err != nil {
+		return err
+	}
+	return cli.PutOrgItem(token, v.Membership.Org, types.OrgItem{
+		Key:        record.Item.Key,
+		Collection: collection,
+		Type:       record.Item.Type,
+		Data:       data,
+		KeyVersion: v.Membership.KeyVersion,
+	})
+}
+
+// Copy копирует запись key из личного хранилища пользователя в коллекцию collection организации
+func (v *Vault) Copy(token string, pass string, cli *client.Client, key string, collection string) error {
+	record, err := export.LoadKey(token, pass, cli, key)
+	if err != nil {
+		return err
+	}
+	return v.Put(token, cli, collection, record)
+}
+
+func (v *Vault) open(stored types.OrgItem) (*Item, error) {
+	record, err := sharing.OpenRecord(v.key, stored.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", stored.Key, err)
+	}
+	return &Item{OrgItem: stored, Record: *record}, nil
+}
@@ -0,0 +1,183 @@
+package orgs
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/box"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/client/sharing"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// fakeServer хранит одну организацию в памяти и отвечает от имени пользователя alice
+type fakeServer struct {
+	keys     *types.UserKeys
+	public   map[string]string
+	members  map[string]types.Membership
+	items    map[string]types.OrgItem
+	rotation *types.KeyRotation
+}
+
+func (f *fakeServer) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/user/keys", func(w http.ResponseWriter, r *http.Request) {
+		if f.keys == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.keys)
+	})
+	mux.HandleFunc("PUT /api/user/keys", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&f.keys))
+		f.public["alice"] = f.keys.PublicKey
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /api/user/{username}/public_key", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(types.UserKeys{PublicKey: f.public[r.PathValue("username")]})
+	})
+	mux.HandleFunc("POST /api/org", func(w http.ResponseWriter, r *http.Request) {
+		var m types.Membership
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		f.members["alice"] = types.Membership{Org: m.Org, Username: "alice", Role: types.RoleOwner, Accepted: true, OrgKey: m.OrgKey, KeyVersion: 1}
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /api/org", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]types.Membership{f.members["alice"]})
+	})
+	mux.HandleFunc("POST /api/org/team/members", func(w http.ResponseWriter, r *http.Request) {
+		var m types.Membership
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		m.Org = "team"
+		f.members[m.Username] = m
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /api/org/team/members", func(w http.ResponseWriter, r *http.Request) {
+		members := []types.Membership{}
+		for name, m := range f.members {
+			public := f.public[name]
+			m.PublicKey = &public
+			m.OrgKey = ""
+			members = append(members, m)
+		}
+		_ = json.NewEncoder(w).Encode(members)
+	})
+	mux.HandleFunc("DELETE /api/org/team/members/{username}", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&f.rotation))
+		delete(f.members, r.PathValue("username"))
+		for name, key := range f.rotation.Keys {
+			m := f.members[name]
+			m.OrgKey = key
+			m.KeyVersion++
+			f.members[name] = m
+		}
+		for _, i := range f.rotation.Items {
+			f.items[i.Key] = i
+		}
+	})
+	mux.HandleFunc("PUT /api/org/team/items", func(w http.ResponseWriter, r *http.Request) {
+		var i types.OrgItem
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&i))
+		f.items[i.Key] = i
+	})
+	mux.HandleFunc("GET /api/org/team/items", func(w http.ResponseWriter, r *http.Request) {
+		items := []types.OrgItem{}
+		for _, i := range f.items {
+			items = append(items, i)
+		}
+		_ = json.NewEncoder(w).Encode(items)
+	})
+	mux.HandleFunc("GET /api/org/team/items/{key}", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(f.items[r.PathValue("key")])
+	})
+	return mux
+}
+
+func newKeyPair(t *testing.T) *sharing.KeyPair {
+	public, private, err := box.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &sharing.KeyPair{Public: public, Private: private}
+}
+
+func TestVaultLifecycle(t *testing.T) {
+	bob := newKeyPair(t)
+	carol := newKeyPair(t)
+	fake := &fakeServer{
+		public:  map[string]string{},
+		members: map[string]types.Membership{},
+		items:   map[string]types.OrgItem{},
+	}
+	fake.public["bob"] = encodeKey(bob)
+	fake.public["carol"] = encodeKey(carol)
+	svr := httptest.NewServer(fake.handler(t))
+	defer svr.Close()
+
+	conf, _ := config.NewClientConfig()
+	conf.ServerAddress = svr.URL
+	conf.SSLKey = "../../../.ssl/ca.key"
+	cli, err := client.NewClient(conf)
+	require.NoError(t, err)
+
+	pass := "secret"
+	require.NoError(t, Create("token", pass, cli, "team"))
+	vault, err := Open("token", pass, cli, "team")
+	require.NoError(t, err)
+	assert.Equal(t, types.RoleOwner, vault.Membership.Role)
+
+	_, err = Open("token", pass, cli, "other")
+	assert.ErrorIs(t, err, ErrNotMember)
+
+	text := types.TextData("db password")
+	record := importer.Record{Item: types.Item{Key: "db", Type: types.TypeText}, Text: &text}
+	require.NoError(t, vault.Put("token", cli, "infra", record))
+	assert.NotContains(t, fake.items["db"].Data, "password")
+	assert.Equal(t, "infra", fake.items["db"].Collection)
+
+	got, err := vault.Get("token", cli, "db")
+	require.NoError(t, err)
+	assert.Equal(t, record, got.Record)
+
+	require.NoError(t, vault.Invite("token", cli, "bob", types.RoleMember))
+	require.NoError(t, vault.Invite("token", cli, "carol", types.RoleReadOnly))
+	oldKey, err := sharing.UnwrapKey(fake.members["carol"].OrgKey, carol)
+	require.NoError(t, err)
+
+	require.NoError(t, vault.Remove("token", cli, "carol"))
+	require.NotNil(t, fake.rotation)
+	assert.Equal(t, 1, fake.rotation.KeyVersion)
+	assert.Len(t, fake.rotation.Keys, 2)
+	assert.NotContains(t, fake.rotation.Keys, "carol")
+	assert.Equal(t, 2, vault.Membership.KeyVersion)
+
+	// старый ключ, который знает исключённый участник, новые данные не открывает
+	_, err = sharing.OpenRecord(oldKey, fake.items["db"].Data)
+	assert.ErrorIs(t, err, sharing.ErrDecrypt)
+
+	newKey, err := sharing.UnwrapKey(fake.members["bob"].OrgKey, bob)
+	require.NoError(t, err)
+	opened, err := sharing.OpenRecord(newKey, fake.items["db"].Data)
+	require.NoError(t, err)
+	assert.Equal(t, record, *opened)
+
+	items, err := vault.Items("token", cli, "")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, record, items[0].Record)
+
+	// ограниченный коллекциями участник сам не выходит, ключ за него меняет администратор
+	restricted := Vault{Membership: types.Membership{Org: "team", Username: "bob", Role: types.RoleMember, Accepted: true, Collections: []string{"infra"}}}
+	assert.ErrorIs(t, restricted.Remove("token", cli, "bob"), ErrRestrictedLeave)
+}
+
+func encodeKey(keys *sharing.KeyPair) string {
+	return base64.StdEncoding.EncodeToString(keys.Public[:])
+}
@@ -0,0 +1,191 @@
+// Package passgen генерирует случайные пароли и парольные фразы (в стиле diceware).
+// Для генерации используется только crypto/rand.
+//
+// Список слов для парольных фраз - EFF large wordlist (7776 слов),
+// https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases (CC BY 3.0 US)
+package passgen
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// Наборы символов для паролей
+const (
+	Lower   = "abcdefghijklmnopqrstuvwxyz"
+	Upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	Digits  = "0123456789"
+	Symbols = "!@#$%^&*()-_=+[]{};:,.<>/?~"
+	// Ambiguous символы, которые легко перепутать при чтении или ручном вводе
+	Ambiguous = "Il1O0o|`'\"{}[]()/\\;:.,"
+)
+
+const (
+	MinLength = 4
+	MaxLength = 128
+	MinWords  = 3
+	MaxWords  = 20
+)
+
+var (
+	ErrLength     = errors.New("password length must be from 4 to 128")
+	ErrNoClasses  = errors.New("at least one character class must be selected")
+	ErrWordsCount = errors.New("number of words must be from 3 to 20")
+)
+
+//go:embed wordlist.txt
+var wordlistData string
+
+var wordlist = strings.Fields(wordlistData)
+
+// Options параметры генерации пароля
+type Options struct {
+	Length           int
+	Lower            bool
+	Upper            bool
+	Digits           bool
+	Symbols          bool
+	ExcludeAmbiguous bool
+}
+
+// DefaultOptions параметры по умолчанию: 20 символов всех классов
+func DefaultOptions() Options {
+	return Options{Length: 20, Lower: true, Upper: true, Digits: true, Symbols: true}
+}
+
+// PassphraseOptions параметры генерации парольной фразы
+type PassphraseOptions struct {
+	Words      int
+	Separator  string
+	Capitalize bool
+	AddNumber  bool
+}
+
+// DefaultPassphraseOptions параметры по умолчанию: 6 слов через дефис
+func DefaultPassphraseOptions() PassphraseOptions {
+	return PassphraseOptions{Words: 6, Separator: "-"}
+}
+
+// Password генерирует пароль. В пароле гарантированно есть хотя бы один символ каждого выбранного класса
+func Password(opts Options) (string, error) {
+	if opts.Length < MinLength || opts.Length > MaxLength {
+		return "", ErrLength
+	}
+
+	var classes []string
+	for _, c := range []struct {
+		enabled bool
+		chars   string
+	}{{opts.Lower, Lower}, {opts.Upper, Upper}, {opts.Digits, Digits}, {opts.Symbols, Symbols}} {
+		if !c.enabled {
+			continue
+		}
+		chars := c.chars
+		if opts.ExcludeAmbiguous {
+			chars = removeChars(chars, Ambiguous)
+		}
+		classes = append(classes, chars)
+	}
+	if len(classes) == 0 {
+		return "", ErrNoClasses
+	}
+
+	all := strings.Join(classes, "")
+	result := make([]byte, 0, opts.Length)
+	for _, chars := range classes {
+		c, err := randomChar(chars)
+		if err != nil {
+			return "", err
+		}
+		result = append(result, c)
+	}
+	for len(result) < opts.Length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		result = append(result, c)
+	}
+
+	err := shuffle(result)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// Passphrase генерирует парольную фразу из случайных слов встроенного списка
+func Passphrase(opts PassphraseOptions) (string, error) {
+	if opts.Words < MinWords || opts.Words > MaxWords {
+		return "", ErrWordsCount
+	}
+
+	words := make([]string, opts.Words)
+	for i := range words {
+		n, err := randomInt(len(wordlist))
+		if err != nil {
+			return "", err
+		}
+		words[i] = wordlist[n]
+		if opts.Capitalize {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+	if opts.AddNumber {
+		n, err := randomInt(len(words))
+		if err != nil {
+			return "", err
+		}
+		d, err := randomChar(Digits)
+		if err != nil {
+			return "", err
+		}
+		words[n] += string(d)
+	}
+	return strings.Join(words, opts.Separator), nil
+}
+
+// WordlistSize количество слов во встроенном списке
+func WordlistSize() int {
+	return len(wordlist)
+}
+
+func randomInt(max int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return 0, err
+	}
+	return int(n.Int64()), nil
+}
+
+func randomChar(chars string) (byte, error) {
+	n, err := randomInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[n], nil
+}
+
+// shuffle перемешивание Фишера-Йетса
+func shuffle(b []byte) error {
+	for i := len(b) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return err
+		}
+		b[i], b[j] = b[j], b[i]
+	}
+	return nil
+}
+
+func removeChars(s string, remove string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(remove, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,79 @@
+package passgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{"default", DefaultOptions(), nil},
+		{"digits only", Options{Length: 6, Digits: true}, nil},
+		{"no ambiguous", Options{Length: 64, Lower: true, Upper: true, Digits: true, Symbols: true, ExcludeAmbiguous: true}, nil},
+		{"min length", Options{Length: 4, Lower: true, Upper: true, Digits: true, Symbols: true}, nil},
+		{"too short", Options{Length: 3, Lower: true}, ErrLength},
+		{"too long", Options{Length: 129, Lower: true}, ErrLength},
+		{"no classes", Options{Length: 10}, ErrNoClasses},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Password(tt.opts)
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Len(t, got, tt.opts.Length)
+
+			for _, c := range []struct {
+				enabled bool
+				chars   string
+			}{{tt.opts.Lower, Lower}, {tt.opts.Upper, Upper}, {tt.opts.Digits, Digits}, {tt.opts.Symbols, Symbols}} {
+				assert.Equal(t, c.enabled, strings.ContainsAny(got, c.chars), "character class %q", c.chars)
+			}
+			if tt.opts.ExcludeAmbiguous {
+				assert.False(t, strings.ContainsAny(got, Ambiguous))
+			}
+		})
+	}
+}
+
+func TestPassword_Random(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		p, err := Password(DefaultOptions())
+		require.NoError(t, err)
+		assert.False(t, seen[p])
+		seen[p] = true
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	assert.Equal(t, 7776, WordlistSize())
+
+	// в списке есть слова с дефисом (t-shirt), поэтому в тесте слова разделяются пробелом
+	opts := DefaultPassphraseOptions()
+	opts.Separator = " "
+	got, err := Passphrase(opts)
+	require.NoError(t, err)
+	words := strings.Split(got, " ")
+	assert.Len(t, words, 6)
+
+	got, err = Passphrase(PassphraseOptions{Words: 4, Separator: " ", Capitalize: true, AddNumber: true})
+	require.NoError(t, err)
+	words = strings.Split(got, " ")
+	assert.Len(t, words, 4)
+	for _, w := range words {
+		assert.Equal(t, strings.ToUpper(w[:1]), w[:1])
+	}
+	assert.True(t, strings.ContainsAny(got, Digits))
+
+	_, err = Passphrase(PassphraseOptions{Words: 2})
+	assert.ErrorIs(t, err, ErrWordsCount)
+}
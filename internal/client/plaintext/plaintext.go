@@ -0,0 +1,279 @@
+// Package plaintext выгружает записи без шифрования для переезда в другой менеджер паролей.
+//
+// JSON (версия схемы 1):
+//
+//	{
+//	  "format": "gophkeeper-plaintext",
+//	  "version": 1,
+//	  "exported_at": "2024-05-10T15:00:00Z",
+//	  "items": [
+//	    {
+//	      "key": "mail", "type": "logopass", "info": "...",
+//	      "expires_at": "2030-01-31", "rotate_days": "90", "updated_at": "...",
+//	      "login": {"login": "...", "password": "...", "totp": "..."},
+//	      "card": {"number": "...", "name": "...", "valid_month": "7", "valid_year": "2030", "cvc": "..."},
+//	      "text": "...",
+//	      "ssh_key": {"private_key": "...", "public_key": "...", "passphrase": "...", "comment": "..."},
+//	      "totp": {"secret": "..."},
+//	      "binary": {"file_name": "...", "content_type": "...", "size": 3, "sha256": "...", "data": "<base64>", "path": "..."},
+//	      "attachments": [{"name": "notes.txt", "mime_type": "text/plain", "size": 3, "data": "<base64>", "path": "..."}]
+//	    }
+//	  ]
+//	}
+//
+// У записи заполнено только поле, соответствующее её типу. Содержимое бинарной записи лежит либо в "data"
+// (base64), либо в отдельном файле, путь к которому указан в "path". Так же хранится содержимое вложений.
+//
+// CSV содержит только логины с паролями и кредитные карты без вложений, по записи на строку, с заголовком
+// type,key,login,password,totp,card_number,card_name,valid_month,valid_year,cvc,info,expires_at
+package plaintext
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// FormatName значение поля "format" в JSON
+const FormatName = "gophkeeper-plaintext"
+
+// Version версия схемы JSON
+const Version = 1
+
+// CSVHeader заголовок CSV
+var CSVHeader = []string{"type", "key", "login", "password", "totp", "card_number", "card_name",
+	"valid_month", "valid_year", "cvc", "info", "expires_at"}
+
+// ErrWorldReadable каталог для выгрузки доступен другим пользователям системы
+var ErrWorldReadable = errors.New("directory is accessible by other users")
+
+// Document корень JSON-выгрузки
+type Document struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Items      []Item    `json:"items"`
+}
+
+// Item одна запись JSON-выгрузки
+type Item struct {
+	Key         string               `json:"key"`
+	Type        types.ItemType       `json:"type"`
+	Info        string               `json:"info,omitempty"`
+	ExpiresAt   string               `json:"expires_at,omitempty"`
+	RotateDays  string               `json:"rotate_days,omitempty"`
+	UpdatedAt   *time.Time           `json:"updated_at,omitempty"`
+	Login       *types.LoginPassword `json:"login,omitempty"`
+	Card        *Card                `json:"card,omitempty"`
+	Text        *string              `json:"text,omitempty"`
+	SSHKey      *types.SSHKeyData    `json:"ssh_key,omitempty"`
+	TOTP        *types.TOTPData      `json:"totp,omitempty"`
+	Binary      *Binary              `json:"binary,omitempty"`
+	Attachments []Attachment         `json:"attachments,omitempty"`
+}
+
+// Card кредитная карта
+type Card struct {
+	Number     string `json:"number"`
+	Name       string `json:"name"`
+	ValidMonth string `json:"valid_month"`
+	ValidYear  string `json:"valid_year"`
+	CVC        string `json:"cvc"`
+}
+
+// Binary бинарные данные: содержимое в Data либо путь к отдельному файлу в Path
+type Binary struct {
+	FileName    string `json:"file_name,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256,omitempty"`
+	Data        []byte `json:"data,omitempty"`
+	Path        string `json:"path,omitempty"`
+}
+
+// Attachment вложение записи: содержимое в Data либо путь к отдельному файлу в Path
+type Attachment struct {
+	Name     string `json:"name"`
+	MimeType string `json:"mime_type,omitempty"`
+	Size     int64  `json:"size"`
+	Data     []byte `json:"data,omitempty"`
+	Path     string `json:"path,omitempty"`
+}
+
+// CheckLocation проверяет, что каталог, в котором будет создан файл path, недоступен другим пользователям
+func CheckLocation(path string) error {
+	dir := filepath.Dir(path)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0o007 != 0 {
+		return fmt.Errorf("%s: %w (mode %o), choose a private directory", dir, ErrWorldReadable, info.Mode().Perm())
+	}
+	return nil
+}
+
+// WriteJSON записывает записи в JSON. Если binaryDir не пустой, содержимое бинарных записей и вложений сохраняется
+// в отдельные файлы в этом каталоге (он будет создан с правами 0700), иначе встраивается в base64
+func WriteJSON(w io.Writer, records []importer.Record, now time.Time, binaryDir string) error {
+	if binaryDir != "" {
+		err := os.Mkdir(binaryDir, 0700)
+		if err != nil && !errors.Is(err, os.ErrExist) {
+			return err
+		}
+		err = CheckLocation(filepath.Join(binaryDir, "x"))
+		if err != nil {
+			return err
+		}
+	}
+
+	doc := Document{Format: FormatName, Version: Version, ExportedAt: now.UTC(), Items: make([]Item, 0, len(records))}
+	for i, r := range records {
+		item, err := convert(r)
+		if err != nil {
+			return fmt.Errorf("%s: %w", r.Item.Key, err)
+		}
+		if item.Binary != nil && binaryDir != "" {
+			item.Binary.Path = filepath.Join(binaryDir, sidecarName(i, r))
+			err = writeSidecar(item.Binary.Path, item.Binary.Data)
+			if err != nil {
+				return err
+			}
+			item.Binary.Data = nil
+		}
+		if binaryDir != "" {
+			err = attachmentSidecars(binaryDir, i, item.Attachments)
+			if err != nil {
+				return err
+			}
+		}
+		doc.Items = append(doc.Items, item)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// attachmentSidecars сохраняет содержимое вложений записи i в отдельные файлы в каталоге dir
+func attachmentSidecars(dir string, i int, attachments []Attachment) error {
+	for j := range attachments {
+		a := &attachments[j]
+		a.Path = filepath.Join(dir, strconv.Itoa(i+1)+"."+strconv.Itoa(j+1)+"_"+safeName(a.Name))
+		err := writeSidecar(a.Path, a.Data)
+		if err != nil {
+			return err
+		}
+		a.Data = nil
+	}
+	return nil
+}
+
+// WriteCSV записывает логины с паролями и кредитные карты в CSV. Возвращает число пропущенных записей других типов
+func WriteCSV(w io.Writer, records []importer.Record) (int, error) {
+	writer := csv.NewWriter(w)
+	err := writer.Write(CSVHeader)
+	if err != nil {
+		return 0, err
+	}
+	skipped := 0
+	for _, r := range records {
+		row := make([]string, len(CSVHeader))
+		row[0] = string(r.Item.Type)
+		row[1] = r.Item.Key
+		row[10] = r.Item.Info
+		row[11] = r.Item.ExpiresAt
+		switch {
+		case r.Login != nil:
+			row[2], row[3], row[4] = r.Login.Login, r.Login.Password, r.Login.TOTP
+		case r.Card != nil:
+			row[5], row[6], row[7], row[8], row[9] = r.Card.Number, r.Card.Name, r.Card.ValidMonth, r.Card.ValidYear, r.Card.CVC
+		default:
+			skipped++
+			continue
+		}
+		err = writer.Write(row)
+		if err != nil {
+			return skipped, err
+		}
+	}
+	writer.Flush()
+	return skipped, writer.Error()
+}
+
+func convert(r importer.Record) (Item, error) {
+	item := Item{
+		Key:        r.Item.Key,
+		Type:       r.Item.Type,
+		Info:       r.Item.Info,
+		ExpiresAt:  r.Item.ExpiresAt,
+		RotateDays: r.Item.RotateDays,
+		UpdatedAt:  r.Item.UpdatedAt,
+		Login:      r.Login,
+		SSHKey:     r.SSHKey,
+		TOTP:       r.TOTP,
+	}
+	for _, a := range r.Attachments {
+		item.Attachments = append(item.Attachments, Attachment{Name: a.Name, MimeType: a.MimeType, Size: int64(len(a.Data)), Data: a.Data})
+	}
+	switch {
+	case r.Card != nil:
+		item.Card = &Card{Number: r.Card.Number, Name: r.Card.Name, ValidMonth: r.Card.ValidMonth, ValidYear: r.Card.ValidYear, CVC: r.Card.CVC}
+	case r.Text != nil:
+		text := string(*r.Text)
+		item.Text = &text
+	case r.Binary != nil:
+		item.Binary = &Binary{Size: int64(len(*r.Binary)), Data: *r.Binary}
+		if r.Meta != nil {
+			item.Binary.FileName = r.Meta.FileName
+			item.Binary.ContentType = r.Meta.ContentType
+			item.Binary.SHA256 = r.Meta.SHA256
+		}
+	case r.Login == nil && r.SSHKey == nil && r.TOTP == nil:
+		return item, fmt.Errorf("no data for item type %s", r.Item.Type)
+	}
+	return item, nil
+}
+
+// sidecarName имя файла для содержимого бинарной записи: порядковый номер делает имена уникальными.
+// Файлы вложений называются "<номер записи>.<номер вложения>_<имя>" и с ними не пересекаются
+func sidecarName(i int, r importer.Record) string {
+	name := r.Item.Key
+	if r.Meta != nil && r.Meta.FileName != "" {
+		name = r.Meta.FileName
+	}
+	return strconv.Itoa(i+1) + "_" + safeName(name)
+}
+
+// safeName имя без разделителей каталогов и управляющих символов
+func safeName(name string) string {
+	return strings.Map(func(c rune) rune {
+		if c == '/' || c == '\\' || c == os.PathSeparator || c < ' ' {
+			return '_'
+		}
+		return c
+	}, name)
+}
+
+func writeSidecar(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
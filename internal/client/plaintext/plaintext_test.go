@@ -0,0 +1,107 @@
+package plaintext
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func testRecords() []importer.Record {
+	note := types.TextData("some note")
+	file := types.BinaryData("file content")
+	return []importer.Record{
+		{
+			Item:        types.Item{Key: "mail", Type: types.TypeLogoPass, Info: "info", ExpiresAt: "2030-01-31"},
+			Login:       &types.LoginPassword{Login: "me", Password: "pw"},
+			Attachments: importer.Notes("https://mail.example", "pin"),
+		},
+		{
+			Item: types.Item{Key: "visa", Type: types.TypeCreditCard},
+			Card: &types.CreditCardData{Number: "4111111111111111", Name: "J DOE", ValidMonth: "7", ValidYear: "2030", CVC: "123"},
+		},
+		{Item: types.Item{Key: "note", Type: types.TypeText}, Text: &note},
+		{Item: types.Item{Key: "docs/passport", Type: types.TypeBinary}, Binary: &file, Meta: types.NewBinaryMeta("passport.txt", file)},
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, testRecords(), now, ""))
+
+	var doc Document
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, FormatName, doc.Format)
+	assert.Equal(t, Version, doc.Version)
+	assert.Equal(t, now, doc.ExportedAt)
+	require.Len(t, doc.Items, 4)
+	assert.Equal(t, "pw", doc.Items[0].Login.Password)
+	assert.Equal(t, "123", doc.Items[1].Card.CVC)
+	assert.Equal(t, "some note", *doc.Items[2].Text)
+	assert.Equal(t, "file content", string(doc.Items[3].Binary.Data))
+	assert.Equal(t, "passport.txt", doc.Items[3].Binary.FileName)
+	assert.Contains(t, buf.String(), `"data": "ZmlsZSBjb250ZW50"`)
+	require.Len(t, doc.Items[0].Attachments, 1)
+	assert.Equal(t, importer.NotesAttachment, doc.Items[0].Attachments[0].Name)
+	assert.Equal(t, "URL: https://mail.example\npin", string(doc.Items[0].Attachments[0].Data))
+}
+
+func TestWriteJSONSidecar(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "files")
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, testRecords(), time.Now(), dir))
+
+	var doc Document
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	binary := doc.Items[3].Binary
+	assert.Empty(t, binary.Data)
+	assert.Equal(t, filepath.Join(dir, "4_passport.txt"), binary.Path)
+
+	content, err := os.ReadFile(binary.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "file content", string(content))
+	info, err := os.Stat(binary.Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	notes := doc.Items[0].Attachments[0]
+	assert.Empty(t, notes.Data)
+	assert.Equal(t, filepath.Join(dir, "1.1_notes.txt"), notes.Path)
+	content, err = os.ReadFile(notes.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "URL: https://mail.example\npin", string(content))
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	skipped, err := WriteCSV(&buf, testRecords())
+	require.NoError(t, err)
+	assert.Equal(t, 2, skipped)
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		CSVHeader,
+		{"logopass", "mail", "me", "pw", "", "", "", "", "", "", "info", "2030-01-31"},
+		{"credit_card", "visa", "", "", "", "4111111111111111", "J DOE", "7", "2030", "123", "", ""},
+	}, rows)
+}
+
+func TestCheckLocation(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Chmod(dir, 0700))
+	assert.NoError(t, CheckLocation(filepath.Join(dir, "out.json")))
+
+	require.NoError(t, os.Chmod(dir, 0755))
+	assert.ErrorIs(t, CheckLocation(filepath.Join(dir, "out.json")), ErrWorldReadable)
+}
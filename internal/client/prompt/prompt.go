@@ -4,10 +4,16 @@ package prompt
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/passgen"
+	"github.com/wellywell/gophkeeper/internal/totp"
 	"github.com/wellywell/gophkeeper/internal/types"
 )
 
@@ -22,6 +28,14 @@ const (
 	SEE_RECORDS = "List all records"
 	EDIT_RECORD = "Edit record"
 	DOWNLOAD    = "Download binary data"
+	ATTACHMENTS = "Manage attachments"
+	DUE_SOON    = "Due soon"
+	HEALTH      = "Vault health report"
+	SHARED      = "Shared with me"
+	EMERGENCY   = "Emergency access"
+	AUDIT_LOG   = "Account activity"
+	DEVICES     = "Devices"
+	DELETE_ME   = "Delete account"
 	EXIT        = "Exit"
 	CANCEL      = "Back to main menu"
 	NEXT        = "Next page"
@@ -32,6 +46,8 @@ const (
 	LOGIN_PASSWORD = "Login and password"
 	TEXT           = "Some text"
 	BINARY_DATA    = "Binary data"
+	SSH_KEY        = "SSH key"
+	TOTP           = "TOTP secret (2FA)"
 )
 
 const (
@@ -39,6 +55,51 @@ const (
 	DELETE = "delete"
 )
 
+const (
+	TOTP_FROM_TEXT = "Enter otpauth:// URI or secret"
+	TOTP_FROM_QR   = "Read QR code image"
+	TOTP_NONE      = "No TOTP"
+	TOTP_KEEP      = "Keep current TOTP"
+	TOTP_REMOVE    = "Remove TOTP"
+)
+
+const (
+	PASSWORD_TYPE       = "Type password"
+	PASSWORD_GENERATE   = "Generate password"
+	PASSPHRASE_GENERATE = "Generate passphrase"
+)
+
+const (
+	CLASS_LOWER   = "lowercase letters"
+	CLASS_UPPER   = "uppercase letters"
+	CLASS_DIGITS  = "digits"
+	CLASS_SYMBOLS = "symbols"
+)
+
+const (
+	EMERGENCY_ADD     = "Add trusted contact"
+	EMERGENCY_ACCEPT  = "Accept"
+	EMERGENCY_REQUEST = "Request access"
+	EMERGENCY_APPROVE = "Approve request now"
+	EMERGENCY_REJECT  = "Reject request"
+	EMERGENCY_VIEW    = "View vault"
+	EMERGENCY_REMOVE  = "Remove"
+)
+
+const SIGN_OUT_OTHERS = "Sign out all other devices"
+
+const (
+	DEVICE_APPROVE = "Approve, fingerprints match"
+	DEVICE_REJECT  = "Reject"
+	DEVICE_LATER   = "Decide later"
+)
+
+const (
+	ATTACHMENT_ADD      = "Attach a file"
+	ATTACHMENT_DOWNLOAD = "Download attachment"
+	ATTACHMENT_REMOVE   = "Remove attachment"
+)
+
 // EnterKey промпт для ввода названия записи для хранения на сервере
 func EnterKey(key string) (string, error) {
 
@@ -78,6 +139,67 @@ func EnterMetadata(info string) (string, error) {
 	return metadata, nil
 }
 
+// EnterExpiry предлагает ввести необязательные срок действия записи и период ротации в днях
+func EnterExpiry(expiresAt string, rotateDays string) (string, string, error) {
+	questions := []*survey.Question{
+		{
+			Name:   "ExpiresAt",
+			Prompt: &survey.Input{Message: "Expires at (YYYY-MM-DD, empty for none): ", Default: expiresAt},
+			Validate: func(val interface{}) error {
+				if val.(string) == "" {
+					return nil
+				}
+				_, err := time.Parse(types.DateFormat, val.(string))
+				if err != nil {
+					return errors.New("date must be in YYYY-MM-DD format")
+				}
+				return nil
+			},
+		},
+		{
+			Name:   "RotateDays",
+			Prompt: &survey.Input{Message: "Rotate every N days (empty for none): ", Default: rotateDays},
+			Validate: func(val interface{}) error {
+				if val.(string) == "" {
+					return nil
+				}
+				num, err := strconv.Atoi(val.(string))
+				if err != nil || num <= 0 {
+					return errors.New("positive number of days expected")
+				}
+				return nil
+			},
+		},
+	}
+	answers := struct {
+		ExpiresAt  string
+		RotateDays string
+	}{}
+
+	err := survey.Ask(questions, &answers)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", "", err
+	}
+	return answers.ExpiresAt, answers.RotateDays, nil
+}
+
+// EditItem предлагает изменить метаданные существующего объекта: дополнительную информацию, срок действия и период ротации
+func EditItem(item types.Item) (types.Item, error) {
+	info, err := EnterMetadata(item.Info)
+	if err != nil {
+		return item, err
+	}
+	expiresAt, rotateDays, err := EnterExpiry(item.ExpiresAt, item.RotateDays)
+	if err != nil {
+		return item, err
+	}
+	item.Info = info
+	item.ExpiresAt = expiresAt
+	item.RotateDays = rotateDays
+	return item, nil
+}
+
 // EnterLoginPassword предлагает ввести логин и пароль для сохранения на сервере
 func EnterLoginPassword(item types.LoginPassword) (*types.LoginPassword, error) {
 
@@ -87,11 +209,7 @@ func EnterLoginPassword(item types.LoginPassword) (*types.LoginPassword, error)
 			Prompt:   &survey.Input{Message: "Login: ", Default: item.Login},
 			Validate: survey.Required,
 		},
-		{
-			Name:     "password",
-			Prompt:   &survey.Password{Message: "Password: "},
-			Validate: survey.Required,
-		}}
+	}
 	answers := types.LoginPassword{}
 
 	err := survey.Ask(creds, &answers)
@@ -99,9 +217,226 @@ func EnterLoginPassword(item types.LoginPassword) (*types.LoginPassword, error)
 		fmt.Println(err.Error())
 		return nil, err
 	}
+
+	answers.Password, err = EnterPassword()
+	if err != nil {
+		return nil, err
+	}
+	answers.Password, err = checkBreached(answers.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []string{TOTP_NONE, TOTP_FROM_TEXT, TOTP_FROM_QR}
+	if item.TOTP != "" {
+		options = []string{TOTP_KEEP, TOTP_FROM_TEXT, TOTP_FROM_QR, TOTP_REMOVE}
+	}
+	var action string
+	err = survey.AskOne(&survey.Select{
+		Message: "TOTP seed for this login:",
+		Options: options,
+		Default: options[0],
+	}, &action)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return nil, err
+	}
+	switch action {
+	case TOTP_KEEP:
+		answers.TOTP = item.TOTP
+	case TOTP_FROM_TEXT, TOTP_FROM_QR:
+		answers.TOTP, err = enterTOTPSecret(action)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &answers, nil
 }
 
+// BreachCheck если задана, вызывается для каждого введённого пароля и возвращает,
+// сколько раз пароль встречался в известных утечках
+var BreachCheck func(password string) (int, error)
+
+// checkBreached предупреждает, если пароль найден в утечках, и предлагает ввести другой
+func checkBreached(password string) (string, error) {
+	for BreachCheck != nil {
+		count, err := BreachCheck(password)
+		if err != nil {
+			fmt.Println("Breach check failed:", err)
+			return password, nil
+		}
+		if count == 0 {
+			return password, nil
+		}
+		fmt.Printf("Warning: this password was seen %d times in data breaches\n", count)
+		keep, err := Confirm("Use it anyway?")
+		if err != nil {
+			return "", err
+		}
+		if keep {
+			return password, nil
+		}
+		password, err = EnterPassword()
+		if err != nil {
+			return "", err
+		}
+	}
+	return password, nil
+}
+
+// EnterPassword предлагает ввести пароль вручную, либо сгенерировать пароль или парольную фразу
+func EnterPassword() (string, error) {
+	var source string
+	err := survey.AskOne(&survey.Select{
+		Message: "Password:",
+		Options: []string{PASSWORD_TYPE, PASSWORD_GENERATE, PASSPHRASE_GENERATE},
+		Default: PASSWORD_TYPE,
+	}, &source)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+
+	switch source {
+	case PASSWORD_GENERATE:
+		return GeneratePassword()
+	case PASSPHRASE_GENERATE:
+		return GeneratePassphrase()
+	}
+
+	var password string
+	err = survey.AskOne(&survey.Password{Message: "Password: "}, &password, survey.WithValidator(survey.Required))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+	return password, nil
+}
+
+// GeneratePassword предлагает выбрать параметры генерации пароля и генерирует пароль,
+// пока пользователь не согласится его использовать
+func GeneratePassword() (string, error) {
+	defaults := passgen.DefaultOptions()
+	questions := []*survey.Question{
+		{
+			Name:   "Length",
+			Prompt: &survey.Input{Message: "Length: ", Default: strconv.Itoa(defaults.Length)},
+			Validate: func(val interface{}) error {
+				num, err := strconv.Atoi(val.(string))
+				if err != nil || num < passgen.MinLength || num > passgen.MaxLength {
+					return passgen.ErrLength
+				}
+				return nil
+			},
+		},
+		{
+			Name: "Classes",
+			Prompt: &survey.MultiSelect{
+				Message: "Character classes:",
+				Options: []string{CLASS_LOWER, CLASS_UPPER, CLASS_DIGITS, CLASS_SYMBOLS},
+				Default: []string{CLASS_LOWER, CLASS_UPPER, CLASS_DIGITS, CLASS_SYMBOLS},
+			},
+			Validate: survey.MinItems(1),
+		},
+		{
+			Name:   "ExcludeAmbiguous",
+			Prompt: &survey.Confirm{Message: "Exclude ambiguous characters (l, 1, O, 0...)?", Default: true},
+		},
+	}
+	answers := struct {
+		Length           string
+		Classes          []string
+		ExcludeAmbiguous bool
+	}{}
+	err := survey.Ask(questions, &answers)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+
+	opts := passgen.Options{ExcludeAmbiguous: answers.ExcludeAmbiguous}
+	opts.Length, _ = strconv.Atoi(answers.Length)
+	for _, class := range answers.Classes {
+		switch class {
+		case CLASS_LOWER:
+			opts.Lower = true
+		case CLASS_UPPER:
+			opts.Upper = true
+		case CLASS_DIGITS:
+			opts.Digits = true
+		case CLASS_SYMBOLS:
+			opts.Symbols = true
+		}
+	}
+	return acceptGenerated(func() (string, error) {
+		return passgen.Password(opts)
+	})
+}
+
+// GeneratePassphrase предлагает выбрать параметры и генерирует парольную фразу из случайных слов
+func GeneratePassphrase() (string, error) {
+	defaults := passgen.DefaultPassphraseOptions()
+	questions := []*survey.Question{
+		{
+			Name:   "Words",
+			Prompt: &survey.Input{Message: "Number of words: ", Default: strconv.Itoa(defaults.Words)},
+			Validate: func(val interface{}) error {
+				num, err := strconv.Atoi(val.(string))
+				if err != nil || num < passgen.MinWords || num > passgen.MaxWords {
+					return passgen.ErrWordsCount
+				}
+				return nil
+			},
+		},
+		{
+			Name:   "Separator",
+			Prompt: &survey.Input{Message: "Separator: ", Default: defaults.Separator},
+		},
+		{
+			Name:   "Capitalize",
+			Prompt: &survey.Confirm{Message: "Capitalize words?"},
+		},
+		{
+			Name:   "AddNumber",
+			Prompt: &survey.Confirm{Message: "Add a digit?"},
+		},
+	}
+	answers := struct {
+		Words      string
+		Separator  string
+		Capitalize bool
+		AddNumber  bool
+	}{}
+	err := survey.Ask(questions, &answers)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+
+	opts := passgen.PassphraseOptions{Separator: answers.Separator, Capitalize: answers.Capitalize, AddNumber: answers.AddNumber}
+	opts.Words, _ = strconv.Atoi(answers.Words)
+	return acceptGenerated(func() (string, error) {
+		return passgen.Passphrase(opts)
+	})
+}
+
+func acceptGenerated(generate func() (string, error)) (string, error) {
+	for {
+		password, err := generate()
+		if err != nil {
+			return "", err
+		}
+		fmt.Printf("Generated: %s\n", password)
+		accept, err := Confirm("Use this password? (no - generate another one)")
+		if err != nil {
+			return "", err
+		}
+		if accept {
+			return password, nil
+		}
+	}
+}
+
 // EnterText предлагает ввести текст для сохранения на сервере
 func EnterText(defaultText string) (types.TextData, error) {
 	var text string
@@ -132,9 +467,9 @@ func EnterFileName() (string, error) {
 }
 
 // EnterFile предлагает ввести имя нового файла для сохранения данных в нём
-func EnterFile() (string, error) {
+func EnterFile(defaultName string) (string, error) {
 	var file string
-	err := survey.AskOne(&survey.Input{Message: "Enter filename to write data to: "}, &file)
+	err := survey.AskOne(&survey.Input{Message: "Enter filename to write data to: ", Default: defaultName}, &file)
 	if err != nil {
 		fmt.Println("Error:", err)
 		return "", err
@@ -150,15 +485,7 @@ func EnterCreditCardData(card types.CreditCardData) (*types.CreditCardData, erro
 			Name:   "Number",
 			Prompt: &survey.Input{Message: "Card number: ", Default: card.Number},
 			Validate: func(val interface{}) error {
-				_, err := strconv.Atoi(val.(string))
-				if err != nil {
-					return errors.New("digits only")
-				}
-				err = survey.MaxLength(16)(val)
-				if err != nil {
-					return err
-				}
-				return survey.MinLength(15)(val)
+				return types.ValidateCardNumber(val.(string))
 			},
 		},
 		{
@@ -198,14 +525,7 @@ func EnterCreditCardData(card types.CreditCardData) (*types.CreditCardData, erro
 			Name:   "CVC",
 			Prompt: &survey.Password{Message: "CVC: "},
 			Validate: func(val interface{}) error {
-				num, err := strconv.Atoi(val.(string))
-				if err != nil {
-					return errors.New("invalid year (numbers only)")
-				}
-				if num < 100 || num > 999 {
-					return errors.New("3 digits only")
-				}
-				return nil
+				return types.ValidateCVC(val.(string))
 			},
 		},
 	}
@@ -216,16 +536,116 @@ func EnterCreditCardData(card types.CreditCardData) (*types.CreditCardData, erro
 		fmt.Println(err.Error())
 		return nil, err
 	}
+	answers.Number = types.NormalizeCardNumber(answers.Number)
+	fmt.Printf("Card brand: %s\n", answers.Brand())
 	return &answers, err
 }
 
+// EnterSSHKey предлагает указать файл с приватным SSH-ключом, его пароль и комментарий
+func EnterSSHKey(key types.SSHKeyData) (*types.SSHKeyData, error) {
+
+	file := ""
+	err := survey.AskOne(&survey.Input{
+		Message: "File to read private SSH key from...",
+		Suggest: func(toComplete string) []string {
+			files, _ := filepath.Glob(toComplete + "*")
+			return files
+		},
+	}, &file, survey.WithValidator(survey.Required))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return nil, err
+	}
+
+	private, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return nil, err
+	}
+
+	answers := struct {
+		Passphrase string
+		Comment    string
+	}{}
+	questions := []*survey.Question{
+		{
+			Name:   "Passphrase",
+			Prompt: &survey.Password{Message: "Key passphrase (leave empty if none): "},
+		},
+		{
+			Name:   "Comment",
+			Prompt: &survey.Input{Message: "Comment: ", Default: key.Comment},
+		},
+	}
+	err = survey.Ask(questions, &answers)
+	if err != nil {
+		fmt.Println(err.Error())
+		return nil, err
+	}
+	return &types.SSHKeyData{PrivateKey: string(private), Passphrase: answers.Passphrase, Comment: answers.Comment}, nil
+}
+
+// EnterTOTP предлагает ввести TOTP-секрет вручную, либо прочитать его из изображения с QR-кодом
+func EnterTOTP() (*types.TOTPData, error) {
+	var source string
+	err := survey.AskOne(&survey.Select{
+		Message: "Where to take TOTP secret from?",
+		Options: []string{TOTP_FROM_TEXT, TOTP_FROM_QR},
+		Default: TOTP_FROM_TEXT,
+	}, &source)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return nil, err
+	}
+	secret, err := enterTOTPSecret(source)
+	if err != nil {
+		return nil, err
+	}
+	return &types.TOTPData{Secret: secret}, nil
+}
+
+func enterTOTPSecret(source string) (string, error) {
+	var secret string
+	switch source {
+	case TOTP_FROM_QR:
+		file := ""
+		err := survey.AskOne(&survey.Input{
+			Message: "QR code image file...",
+			Suggest: func(toComplete string) []string {
+				files, _ := filepath.Glob(toComplete + "*")
+				return files
+			},
+		}, &file, survey.WithValidator(survey.Required))
+		if err != nil {
+			fmt.Println("Error:", err)
+			return "", err
+		}
+		secret, err = totp.ReadQR(file)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return "", err
+		}
+	default:
+		err := survey.AskOne(&survey.Password{Message: "otpauth:// URI or base32 secret: "}, &secret, survey.WithValidator(survey.Required))
+		if err != nil {
+			fmt.Println("Error:", err)
+			return "", err
+		}
+	}
+	if _, err := totp.Parse(secret); err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+	return secret, nil
+}
+
 // ChooseDataType предлагает выбрать, какой тип данных хочет сохранить пользователь
 func ChooseDataType() (string, error) {
 	var dataType string
 
 	err := survey.AskOne(&survey.Select{
 		Message: "What kind of data would you like to store?",
-		Options: []string{LOGIN_PASSWORD, CREDIT_CARD, TEXT, BINARY_DATA, CANCEL},
+		Options: []string{LOGIN_PASSWORD, CREDIT_CARD, TEXT, BINARY_DATA, SSH_KEY, TOTP, CANCEL},
 		Default: LOGIN_PASSWORD,
 	}, &dataType)
 	if err != nil {
@@ -270,6 +690,273 @@ func ChooseEditOrDelete() (string, error) {
 	return action, nil
 }
 
+// ChooseAttachmentAction предлагает выбрать действие над вложениями записи
+func ChooseAttachmentAction() (string, error) {
+
+	var action string
+
+	err := survey.AskOne(&survey.Select{
+		Message: "What do you want to do with attachments?",
+		Options: []string{ATTACHMENT_ADD, ATTACHMENT_DOWNLOAD, ATTACHMENT_REMOVE, CANCEL},
+		Default: ATTACHMENT_ADD,
+	}, &action)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+	return action, nil
+}
+
+// ChooseAttachment предлагает выбрать вложение из списка
+func ChooseAttachment(attachments []types.Attachment) (types.Attachment, error) {
+
+	var index int
+
+	options := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		options = append(options, a.String())
+	}
+
+	err := survey.AskOne(&survey.Select{
+		Message: "Choose attachment",
+		Options: options,
+	}, &index)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return types.Attachment{}, err
+	}
+	return attachments[index], nil
+}
+
+// ChooseKeyToFix предлагает выбрать запись из отчёта для редактирования
+func ChooseKeyToFix(keys []string) (string, error) {
+
+	var key string
+
+	err := survey.AskOne(&survey.Select{
+		Message: "Choose record to edit",
+		Options: append(keys, CANCEL),
+	}, &key)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+	return key, nil
+}
+
+// ChooseShared предлагает выбрать одну из записей, которыми поделились с пользователем
+func ChooseShared(shares []string) (string, error) {
+
+	var share string
+
+	err := survey.AskOne(&survey.Select{
+		Message: "Choose shared record",
+		Options: append(shares, CANCEL),
+	}, &share)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+	return share, nil
+}
+
+// ChooseEmergency предлагает выбрать экстренный доступ или назначить новое доверенное лицо
+func ChooseEmergency(entries []string) (string, error) {
+
+	var entry string
+
+	err := survey.AskOne(&survey.Select{
+		Message: "Choose trusted contact or vault",
+		Options: append(append(entries, EMERGENCY_ADD), CANCEL),
+	}, &entry)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+	return entry, nil
+}
+
+// ChooseSession предлагает выбрать устройство, на котором выполнен вход, или выйти на всех остальных
+func ChooseSession(sessions []string) (string, error) {
+
+	var session string
+
+	err := survey.AskOne(&survey.Select{
+		Message: "Choose device",
+		Options: append(append(sessions, SIGN_OUT_OTHERS), CANCEL),
+	}, &session)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+	return session, nil
+}
+
+// ConfirmSignOut спрашивает подтверждение выхода на устройстве
+func ConfirmSignOut(device string) (bool, error) {
+
+	var ok bool
+
+	err := survey.AskOne(&survey.Confirm{Message: fmt.Sprintf("Sign out %s?", device)}, &ok)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return false, err
+	}
+	return ok, nil
+}
+
+// ConfirmForgetDevice спрашивает, нужно ли при следующем входе с устройства снова подтверждать вход
+func ConfirmForgetDevice(device string) (bool, error) {
+
+	var ok bool
+
+	err := survey.AskOne(&survey.Confirm{Message: fmt.Sprintf("Require approval for the next login from %s?", device)}, &ok)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return false, err
+	}
+	return ok, nil
+}
+
+// ChooseDeviceRequest предлагает подтвердить или отклонить вход с нового устройства, сверив отпечаток его ключа
+func ChooseDeviceRequest(device string, fingerprint string) (string, error) {
+
+	var action string
+
+	err := survey.AskOne(&survey.Select{
+		Message: fmt.Sprintf("Login request from %s. Does the new device show fingerprint %s?", device, fingerprint),
+		Options: []string{DEVICE_APPROVE, DEVICE_REJECT, DEVICE_LATER},
+	}, &action)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+	return action, nil
+}
+
+// ChooseEmergencyAction предлагает выбрать одно из доступных действий с экстренным доступом
+func ChooseEmergencyAction(actions []string) (string, error) {
+
+	var action string
+
+	err := survey.AskOne(&survey.Select{
+		Message: "What do you want to do?",
+		Options: append(actions, CANCEL),
+	}, &action)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+	return action, nil
+}
+
+// EnterEmergencyContact промпт для ввода доверенного лица и периода ожидания в днях
+func EnterEmergencyContact() (string, int, error) {
+	questions := []*survey.Question{
+		{
+			Name:     "Grantee",
+			Prompt:   &survey.Input{Message: "Username of trusted contact: "},
+			Validate: survey.Required,
+		},
+		{
+			Name:   "WaitDays",
+			Prompt: &survey.Input{Message: "Waiting period in days: ", Default: "7"},
+			Validate: func(val interface{}) error {
+				num, err := strconv.Atoi(val.(string))
+				if err != nil || num < 1 || num > types.MaxEmergencyWaitDays {
+					return fmt.Errorf("number of days between 1 and %d expected", types.MaxEmergencyWaitDays)
+				}
+				return nil
+			},
+		},
+	}
+	answers := struct {
+		Grantee  string
+		WaitDays string
+	}{}
+
+	err := survey.Ask(questions, &answers)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", 0, err
+	}
+	waitDays, _ := strconv.Atoi(answers.WaitDays)
+	return answers.Grantee, waitDays, nil
+}
+
+// EnterRecoveryShares предлагает разделить ключ восстановления на доли и выбрать их число и порог.
+// Если делить не нужно, возвращает нули
+func EnterRecoveryShares() (int, int, error) {
+	split, err := Confirm("Split recovery key into Shamir shares (any K of N restore it)?")
+	if err != nil || !split {
+		return 0, 0, err
+	}
+	count := func(val interface{}) error {
+		num, err := strconv.Atoi(val.(string))
+		if err != nil || num < 2 || num > 255 {
+			return errors.New("number between 2 and 255 expected")
+		}
+		return nil
+	}
+	questions := []*survey.Question{
+		{Name: "Shares", Prompt: &survey.Input{Message: "Number of shares N: ", Default: "5"}, Validate: count},
+		{Name: "Threshold", Prompt: &survey.Input{Message: "Shares needed to recover K: ", Default: "3"}, Validate: count},
+	}
+	answers := struct {
+		Shares    string
+		Threshold string
+	}{}
+	err = survey.Ask(questions, &answers)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 0, 0, err
+	}
+	shares, _ := strconv.Atoi(answers.Shares)
+	threshold, _ := strconv.Atoi(answers.Threshold)
+	if threshold > shares {
+		return 0, 0, fmt.Errorf("K must not exceed N")
+	}
+	return shares, threshold, nil
+}
+
+// EnterSecret предлагает ввести пароль, например от файла импорта
+func EnterSecret(message string) (string, error) {
+	var secret string
+	err := survey.AskOne(&survey.Password{Message: message}, &secret, survey.WithValidator(survey.Required))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+	return secret, nil
+}
+
+// EnterNewSecret предлагает придумать пароль и ввести его повторно для проверки
+func EnterNewSecret(message string) (string, error) {
+	secret, err := EnterSecret(message)
+	if err != nil {
+		return "", err
+	}
+	repeat, err := EnterSecret("Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+	if secret != repeat {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	return secret, nil
+}
+
+// Confirm предлагает подтвердить действие
+func Confirm(message string) (bool, error) {
+	result := false
+	err := survey.AskOne(&survey.Confirm{Message: message}, &result)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return false, err
+	}
+	return result, nil
+}
+
 // Menu промпт корневого меню - предлагает набор действий пользователю - просмотреть записи,
 // отредактировать запись, получить запись по ключу, загрузить бинарные данные с сервера в файл
 func Menu() (string, error) {
@@ -278,7 +965,7 @@ func Menu() (string, error) {
 
 	err := survey.AskOne(&survey.Select{
 		Message: "What do you want to do?",
-		Options: []string{ADD_RECORD, SEE_RECORDS, SEE_RECORD, EDIT_RECORD, DOWNLOAD, EXIT},
+		Options: []string{ADD_RECORD, SEE_RECORDS, SEE_RECORD, EDIT_RECORD, DOWNLOAD, ATTACHMENTS, DUE_SOON, HEALTH, SHARED, EMERGENCY, AUDIT_LOG, DEVICES, DELETE_ME, EXIT},
 		Default: ADD_RECORD,
 	}, &action)
 	if err != nil {
@@ -289,7 +976,7 @@ func Menu() (string, error) {
 }
 
 // Authenticate аутентификация пользователя
-func Authenticate(method func(string, string) (string, error)) (string, string, error) {
+func Authenticate(method func(string, string) (string, error)) (string, string, string, error) {
 	creds := []*survey.Question{
 		{
 			Name:     "login",
@@ -303,14 +990,44 @@ func Authenticate(method func(string, string) (string, error)) (string, string,
 		}}
 	answers := types.LoginPassword{}
 
-	err := survey.Ask(creds, &answers)
+	for {
+		err := survey.Ask(creds, &answers)
+		if err != nil {
+			fmt.Println(err.Error())
+			return "", "", "", err
+		}
+		token, err := method(answers.Login, answers.Password)
+		// сервер объяснил, чем не подошли логин или пароль: можно ввести другие
+		var rejected *client.RejectedError
+		if errors.As(err, &rejected) {
+			fmt.Println(rejected.Message)
+			creds[0].Prompt = &survey.Input{Message: "Login: ", Default: answers.Login}
+			continue
+		}
+		return answers.Login, token, answers.Password, err
+	}
+}
+
+// EnterInvite предлагает ввести код приглашения, когда сервер регистрирует только по приглашениям
+func EnterInvite() (string, error) {
+	var code string
+	err := survey.AskOne(&survey.Input{Message: "Registration requires an invite. Invite code: "}, &code, survey.WithValidator(survey.Required))
 	if err != nil {
-		fmt.Println(err.Error())
-		return "", "", err
+		fmt.Println("Error:", err)
+		return "", err
 	}
-	token, err := method(answers.Login, answers.Password)
-	return token, answers.Password, err
+	return strings.TrimSpace(code), nil
+}
 
+// EnterEnrollmentCode предлагает ввести код администратора, когда устройства учётной записи сброшены
+func EnterEnrollmentCode() (string, error) {
+	var code string
+	err := survey.AskOne(&survey.Input{Message: "Your devices were reset by the administrator. Enrollment code: "}, &code, survey.WithValidator(survey.Required))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", err
+	}
+	return strings.TrimSpace(code), nil
 }
 
 // CreateBasicItem создаёт метаданные для любого типа данных
@@ -325,8 +1042,15 @@ func CreateBasicItem() (*types.Item, error) {
 		fmt.Println(err.Error())
 		return nil, err
 	}
+	expiresAt, rotateDays, err := EnterExpiry("", "")
+	if err != nil {
+		fmt.Println(err.Error())
+		return nil, err
+	}
 	return &types.Item{
-		Key:  key,
-		Info: meta,
+		Key:        key,
+		Info:       meta,
+		ExpiresAt:  expiresAt,
+		RotateDays: rotateDays,
 	}, nil
 }
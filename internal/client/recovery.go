@@ -0,0 +1,80 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// GetVaultKey получение ключа хранилища, зашифрованного паролем пользователя
+func (c *Client) GetVaultKey(token string) (*types.VaultKey, error) {
+	var key types.VaultKey
+	err := c.requestJSON(token, http.MethodGet, "/api/user/vault_key", nil, http.StatusOK, &key)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// MigrateVaultKey замена старого ключа хранилища новым
+func (c *Client) MigrateVaultKey(token string, migration types.VaultKeyMigration) error {
+	return c.requestJSON(token, http.MethodPost, "/api/user/vault_key/migrate", migration, http.StatusOK, nil)
+}
+
+// LegacyVault список записей и вложений, ещё зашифрованных старым ключом хранилища, и карт с открытым сроком действия
+func (c *Client) LegacyVault(token string) (*types.LegacyVault, error) {
+	var legacy types.LegacyVault
+	err := c.requestJSON(token, http.MethodGet, "/api/user/vault_key/legacy", nil, http.StatusOK, &legacy)
+	if err != nil {
+		return nil, err
+	}
+	return &legacy, nil
+}
+
+// FinishVaultKeyMigration удаление старого ключа хранилища после перешифрования всех записей
+func (c *Client) FinishVaultKeyMigration(token string) error {
+	return c.requestJSON(token, http.MethodDelete, "/api/user/vault_key/legacy", nil, http.StatusOK, nil)
+}
+
+// SetVaultKey сохранение ключа хранилища, зашифрованного паролем пользователя
+func (c *Client) SetVaultKey(token string, wrapped string) error {
+	return c.requestJSON(token, http.MethodPut, "/api/user/vault_key", types.VaultKey{Wrapped: wrapped}, http.StatusOK, nil)
+}
+
+// SetRecovery включение восстановления доступа по ключу восстановления
+func (c *Client) SetRecovery(token string, verifier string) error {
+	return c.requestJSON(token, http.MethodPut, "/api/user/recovery", types.RecoveryVerifier{Verifier: verifier}, http.StatusOK, nil)
+}
+
+// RecoverAccount смена пароля по ключу восстановления, авторизация не нужна. Возвращает токен, как при входе
+func (c *Client) RecoverAccount(recovery types.AccountRecovery) (string, error) {
+	data, err := json.Marshal(recovery)
+	if err != nil {
+		return "", fmt.Errorf("could not serialize data")
+	}
+	headers, err := c.provenSessionHeaders()
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.doRequest(c.address+"/api/user/recover", http.MethodPost, data, headers)
+	if err != nil {
+		return "", fmt.Errorf("could not make request %w", err)
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("could not recover account %s %s", resp.Status, bodyBytes)
+	}
+	token := resp.Header.Get(Token)
+	if token == "" {
+		return "", fmt.Errorf("empty token")
+	}
+	return token, nil
+}
@@ -0,0 +1,148 @@
+package recovery
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/export"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/client/sharing"
+	"github.com/wellywell/gophkeeper/internal/encrypt"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// migrate заменяет старый ключ хранилища, которым был пароль, случайным. Одним запросом на сервер уходят
+// новый ключ, старый ключ, зашифрованный новым, закрытый ключ пользователя и ключи для доверенных лиц,
+// перешифрованные новым ключом. Сервер помечает все записи зашифрованными старым ключом, поэтому перешифрование
+// можно прервать и продолжить при следующей разблокировке. previous - ключ, который сейчас лежит на сервере
+func migrate(token string, password string, previous string, cli *client.Client, out io.Writer) (string, error) {
+	vaultKey, err := NewVaultKey()
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := Wrap(vaultKey, password)
+	if err != nil {
+		return "", err
+	}
+	legacyKey, err := encrypt.Seal([]byte(vaultKey), []byte(password))
+	if err != nil {
+		return "", err
+	}
+	migration := types.VaultKeyMigration{Previous: previous, Wrapped: wrapped, LegacyKey: legacyKey}
+
+	keys, err := cli.GetUserKeys(token)
+	switch {
+	case errors.Is(err, client.ErrNotFound):
+	case err != nil:
+		return "", err
+	default:
+		private, _, err := sharing.OpenPrivateKey(keys.PrivateKey, password)
+		if err != nil {
+			return "", err
+		}
+		migration.PrivateKey, err = sharing.SealPrivateKey(private, vaultKey)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	contacts, err := cli.EmergencyContacts(token)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range contacts {
+		public, err := sharing.PublicKey(token, cli, c.Grantee)
+		if err != nil {
+			return "", err
+		}
+		escrow, err := sharing.WrapKey([]byte(vaultKey), public)
+		if err != nil {
+			return "", err
+		}
+		migration.Escrows = append(migration.Escrows, types.EmergencyEscrow{ID: c.ID, Escrow: escrow})
+	}
+
+	err = cli.MigrateVaultKey(token, migration)
+	if err != nil {
+		return "", err
+	}
+	_, err = fmt.Fprintln(out, "Your vault key was your password and has been replaced with a random one. "+
+		"Run `recovery-kit` to get a new recovery key")
+	if err != nil {
+		return "", err
+	}
+	return vaultKey, reencrypt(token, vaultKey, legacyKey, cli)
+}
+
+// reencrypt перешифровывает ключом хранилища vaultKey записи и вложения, ещё зашифрованные старым ключом,
+// и удаляет старый ключ с сервера
+func reencrypt(token string, vaultKey string, legacyKey string, cli *client.Client) error {
+	opened, err := encrypt.Open([]byte(vaultKey), legacyKey)
+	if err != nil {
+		return err
+	}
+	old := string(opened)
+
+	legacy, err := cli.LegacyVault(token)
+	if err != nil {
+		return err
+	}
+	for _, a := range legacy.Attachments {
+		err = reencryptAttachment(token, vaultKey, old, cli, a)
+		if err != nil {
+			return fmt.Errorf("%s: %w", a.Key, err)
+		}
+	}
+	for _, key := range legacy.Items {
+		record, err := export.LoadKey(token, old, cli, key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		err = importer.Update(token, vaultKey, cli, record)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	err = upgradeCards(token, vaultKey, cli, legacy.Cards)
+	if err != nil {
+		return err
+	}
+	return cli.FinishVaultKeyMigration(token)
+}
+
+// upgradeCards пересохраняет кредитные карты keys, сохранённые до появления шифрования срока действия:
+// сервер хранит их срок открытым текстом, а при сохранении он шифруется вместе с остальными полями
+func upgradeCards(token string, vaultKey string, cli *client.Client, keys []string) error {
+	for _, key := range keys {
+		record, err := export.LoadKey(token, vaultKey, cli, key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		err = importer.Update(token, vaultKey, cli, record)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func reencryptAttachment(token string, vaultKey string, old string, cli *client.Client, legacy types.LegacyAttachment) error {
+	// имена остальных вложений записи могут быть уже перешифрованы, нужно только это
+	attachments, err := cli.SeeAttachments(token, old, legacy.Key)
+	if err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		if a.ID != legacy.ID {
+			continue
+		}
+		data, err := cli.DownloadAttachment(token, old, legacy.Key, a.ID)
+		if err != nil {
+			return err
+		}
+		return cli.ReplaceAttachment(token, vaultKey, legacy.Key, a, data)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package recovery
+
+import (
+	"strings"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// QR рисует QR-код с текстом text символами псевдографики: каждый символ - две строки модулей.
+// Тёмные модули выводятся пробелами на светлом фоне, поэтому код читается и в тёмном терминале
+func QR(text string) (string, error) {
+	hints := map[gozxing.EncodeHintType]interface{}{gozxing.EncodeHintType_MARGIN: 2}
+	// размер 1x1 - QR-код без масштабирования, один пиксель на модуль
+	matrix, err := qrcode.NewQRCodeWriter().Encode(text, gozxing.BarcodeFormat_QR_CODE, 1, 1, hints)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for y := 0; y < matrix.GetHeight(); y += 2 {
+		for x := 0; x < matrix.GetWidth(); x++ {
+			top := matrix.Get(x, y)
+			bottom := y+1 < matrix.GetHeight() && matrix.Get(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteRune(' ')
+			case top:
+				b.WriteRune('▄')
+			case bottom:
+				b.WriteRune('▀')
+			default:
+				b.WriteRune('█')
+			}
+		}
+		b.WriteRune('\n')
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,220 @@
+// Package recovery ключ хранилища и его восстановление. Записи шифруются ключом хранилища, а не паролем:
+// на сервере ключ лежит зашифрованным ключом, выведенным из пароля (Argon2id), поэтому пароль можно сменить,
+// не перешифровывая записи. Сам ключ хранилища и есть ключ восстановления: его показывают при регистрации,
+// по желанию разделив на доли по схеме Шамира. Сервер хранит только хеш проверочного значения ключа
+// и по нему разрешает задать новый пароль тому, кто ключ восстановил.
+// У учётных записей, созданных раньше, ключом хранилища был пароль. При первой разблокировке он заменяется
+// случайным ключом, и записи перешифровываются (см. migrate.go)
+package recovery
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/encrypt"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+const (
+	// vaultKeyBytes 24 случайных байта дают 32 символа base64 - ровно ключ AES-256 для пакета encrypt
+	vaultKeyBytes = 24
+	saltBytes     = 16
+	// параметры Argon2id, рекомендованные для интерактивного входа
+	argonTime    = 1
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	verifierTag  = "gophkeeper recovery\x00"
+)
+
+// ErrWrongPassword ключ хранилища не расшифровывается паролем
+var ErrWrongPassword = errors.New("could not unlock vault key with this password")
+
+// NewVaultKey создаёт случайный ключ хранилища
+func NewVaultKey() (string, error) {
+	key := make([]byte, vaultKeyBytes)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// Wrap шифрует ключ хранилища ключом, выведенным из пароля
+func Wrap(vaultKey string, password string) (string, error) {
+	salt := make([]byte, saltBytes)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := encrypt.Seal(passwordKey(password, salt), []byte(vaultKey))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(salt) + "." + sealed, nil
+}
+
+// Unwrap расшифровывает ключ хранилища паролем
+func Unwrap(wrapped string, password string) (string, error) {
+	encodedSalt, sealed, ok := strings.Cut(wrapped, ".")
+	if !ok {
+		return "", fmt.Errorf("malformed vault key")
+	}
+	salt, err := base64.StdEncoding.DecodeString(encodedSalt)
+	if err != nil {
+		return "", fmt.Errorf("malformed vault key %w", err)
+	}
+	vaultKey, err := encrypt.Open(passwordKey(password, salt), sealed)
+	if err != nil {
+		if errors.Is(err, encrypt.ErrAuthentication) {
+			return "", ErrWrongPassword
+		}
+		return "", err
+	}
+	return string(vaultKey), nil
+}
+
+// Verifier проверочное значение ключа хранилища для сервера. По нему нельзя восстановить сам ключ
+func Verifier(vaultKey string) string {
+	sum := sha256.Sum256([]byte(verifierTag + vaultKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// Setup создаёт ключ хранилища для новой учётной записи, сохраняет его зашифрованным паролем
+// и включает восстановление. Возвращённый ключ нужно показать пользователю как ключ восстановления
+func Setup(token string, password string, cli *client.Client) (string, error) {
+	vaultKey, err := NewVaultKey()
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := Wrap(vaultKey, password)
+	if err != nil {
+		return "", err
+	}
+	err = cli.SetVaultKey(token, wrapped)
+	if err != nil {
+		return "", err
+	}
+	return vaultKey, Enable(token, vaultKey, cli)
+}
+
+// Unlock расшифровывает ключ хранилища паролем после входа. Если ключом хранилища ещё был пароль (учётная запись
+// создана раньше), он заменяется случайным ключом, о чём в out выводится подсказка получить новый ключ восстановления.
+// Прерванное перешифрование записей продолжается, карты с открытым сроком действия пересохраняются (см. upgradeCards)
+func Unlock(token string, password string, cli *client.Client, out io.Writer) (string, error) {
+	key, err := cli.GetVaultKey(token)
+	if errors.Is(err, client.ErrNotFound) {
+		return migrate(token, password, "", cli, out)
+	}
+	if err != nil {
+		return "", err
+	}
+	vaultKey, err := Unwrap(key.Wrapped, password)
+	if err != nil {
+		return "", err
+	}
+	// так ключ сохраняли учётные записи, созданные раньше: пароль, зашифрованный самим собой
+	if vaultKey == password {
+		return migrate(token, password, key.Wrapped, cli, out)
+	}
+	if key.LegacyKey != "" {
+		return vaultKey, reencrypt(token, vaultKey, key.LegacyKey, cli)
+	}
+	legacy, err := cli.LegacyVault(token)
+	if err != nil {
+		return "", err
+	}
+	return vaultKey, upgradeCards(token, vaultKey, cli, legacy.Cards)
+}
+
+// Enable включает восстановление доступа по ключу хранилища vaultKey
+func Enable(token string, vaultKey string, cli *client.Client) error {
+	return cli.SetRecovery(token, Verifier(vaultKey))
+}
+
+// Recover задаёт пользователю login новый пароль, доказывая серверу знание ключа хранилища.
+// Записи остаются зашифрованными тем же ключом. Возвращает токен, как при входе
+func Recover(cli *client.Client, login string, vaultKey string, password string) (string, error) {
+	wrapped, err := Wrap(vaultKey, password)
+	if err != nil {
+		return "", err
+	}
+	return cli.RecoverAccount(types.AccountRecovery{Login: login, Verifier: Verifier(vaultKey), Password: password, Wrapped: wrapped})
+}
+
+// WriteKit выводит набор восстановления для пользователя login (если известен): ключ целиком или,
+// если shares больше нуля, shares долей с порогом threshold, каждую текстом и QR-кодом
+func WriteKit(w io.Writer, login string, vaultKey string, shares int, threshold int) error {
+	if login != "" {
+		_, err := fmt.Fprintf(w, "Login: %s\n", login)
+		if err != nil {
+			return err
+		}
+	}
+	if shares == 0 {
+		_, err := fmt.Fprintf(w, "Recovery key. Keep it offline, anyone who has it can read your vault:\n\n%s\n\n", vaultKey)
+		if err != nil {
+			return err
+		}
+		return writeQR(w, vaultKey)
+	}
+
+	parts, err := Split([]byte(vaultKey), shares, threshold)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Recovery kit: any %d of %d shares restore the vault key. Give each share to a different person or place:\n\n",
+		threshold, shares)
+	if err != nil {
+		return err
+	}
+	for i, p := range parts {
+		_, err = fmt.Fprintf(w, "Share %d of %d:\n%s\n\n", i+1, shares, p.String())
+		if err != nil {
+			return err
+		}
+		err = writeQR(w, p.String())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Combined восстанавливает ключ хранилища из текстов долей
+func Combined(texts []string) (string, error) {
+	shares := make([]Share, 0, len(texts))
+	for _, t := range texts {
+		s, err := ParseShare(t)
+		if err != nil {
+			return "", err
+		}
+		shares = append(shares, s)
+	}
+	secret, err := Combine(shares)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+func writeQR(w io.Writer, text string) error {
+	code, err := QR(text)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, code)
+	return err
+}
+
+func passwordKey(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, 32)
+}
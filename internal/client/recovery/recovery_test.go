@@ -0,0 +1,352 @@
+package recovery
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/box"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/sharing"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/encrypt"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// fakeServer хранит ключ хранилища и проверочное значение одного пользователя,
+// а для замены старого ключа - его закрытый ключ и записи
+type fakeServer struct {
+	wrapped   string
+	verifier  string
+	recovery  *types.AccountRecovery
+	migration *types.VaultKeyMigration
+	keys      *types.UserKeys
+	items     map[string][]byte
+	legacy    []string
+	// cards карты с открытым сроком действия
+	cards []string
+}
+
+func (f *fakeServer) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/user/vault_key", func(w http.ResponseWriter, r *http.Request) {
+		if f.wrapped == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		key := types.VaultKey{Wrapped: f.wrapped}
+		if f.migration != nil {
+			key.LegacyKey = f.migration.LegacyKey
+		}
+		_ = json.NewEncoder(w).Encode(key)
+	})
+	mux.HandleFunc("POST /api/user/vault_key/migrate", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&f.migration))
+		require.Equal(t, f.wrapped, f.migration.Previous)
+		f.wrapped = f.migration.Wrapped
+		for key := range f.items {
+			f.legacy = append(f.legacy, key)
+		}
+	})
+	mux.HandleFunc("GET /api/user/vault_key/legacy", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(types.LegacyVault{Items: f.legacy, Cards: f.cards})
+	})
+	mux.HandleFunc("DELETE /api/user/vault_key/legacy", func(w http.ResponseWriter, r *http.Request) {
+		require.Empty(t, f.legacy)
+		f.migration.LegacyKey = ""
+	})
+	mux.HandleFunc("GET /api/user/keys", func(w http.ResponseWriter, r *http.Request) {
+		if f.keys == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.keys)
+	})
+	mux.HandleFunc("GET /api/emergency/contacts", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]types.EmergencyAccess{})
+	})
+	mux.HandleFunc("GET /api/item/{key}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(f.items[r.PathValue("key")])
+	})
+	mux.HandleFunc("PUT /api/item/text", func(w http.ResponseWriter, r *http.Request) {
+		var item types.GenericItem[*types.TextData]
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &item))
+		f.items[item.Item.Key] = data
+		f.legacy = slices.DeleteFunc(f.legacy, func(key string) bool { return key == item.Item.Key })
+	})
+	mux.HandleFunc("PUT /api/item/credit_card", func(w http.ResponseWriter, r *http.Request) {
+		var item types.CreditCardItem
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &item))
+		f.items[item.Item.Key] = data
+		if item.Data.ValidDate.IsZero() {
+			f.cards = slices.DeleteFunc(f.cards, func(key string) bool { return key == item.Item.Key })
+		}
+	})
+	mux.HandleFunc("PUT /api/user/vault_key", func(w http.ResponseWriter, r *http.Request) {
+		var key types.VaultKey
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&key))
+		f.wrapped = key.Wrapped
+	})
+	mux.HandleFunc("PUT /api/user/recovery", func(w http.ResponseWriter, r *http.Request) {
+		var v types.RecoveryVerifier
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&v))
+		f.verifier = v.Verifier
+	})
+	mux.HandleFunc("POST /api/user/recover", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&f.recovery))
+		if f.recovery.Verifier != f.verifier {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.wrapped = f.recovery.Wrapped
+		w.Header().Set(client.Token, "recovered")
+	})
+	return mux
+}
+
+func newClient(t *testing.T, f *fakeServer) *client.Client {
+	svr := httptest.NewServer(f.handler(t))
+	t.Cleanup(svr.Close)
+	// NewClientConfig регистрирует флаги и может быть вызван только один раз
+	conf := &config.ClientConfig{ServerAddress: svr.URL, SSLKey: "../../../.ssl/ca.key"}
+	cli, err := client.NewClient(conf)
+	require.NoError(t, err)
+	return cli
+}
+
+func TestSetupAndRecover(t *testing.T) {
+	fake := &fakeServer{}
+	cli := newClient(t, fake)
+
+	vaultKey, err := Setup("token", "old password", cli)
+	require.NoError(t, err)
+	assert.Len(t, vaultKey, 32)
+	assert.NotContains(t, fake.wrapped, vaultKey)
+	assert.Equal(t, Verifier(vaultKey), fake.verifier)
+
+	unlocked, err := Unlock("token", "old password", cli, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, vaultKey, unlocked)
+	_, err = Unlock("token", "wrong", cli, io.Discard)
+	assert.ErrorIs(t, err, ErrWrongPassword)
+
+	_, err = Recover(cli, "user", "not the key", "new password")
+	assert.Error(t, err)
+
+	token, err := Recover(cli, "user", vaultKey, "new password")
+	require.NoError(t, err)
+	assert.Equal(t, "recovered", token)
+	assert.NotContains(t, fake.recovery.Verifier, vaultKey)
+
+	// после смены пароля ключ хранилища прежний, поэтому записи по-прежнему читаются
+	unlocked, err = Unlock("token", "new password", cli, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, vaultKey, unlocked)
+}
+
+func TestUnlockLegacyAccount(t *testing.T) {
+	// записи и закрытый ключ учётной записи, созданной до ключа хранилища, зашифрованы паролем
+	text := types.TextData("secret note")
+	item := types.GenericItem[*types.TextData]{Item: types.Item{Key: "note", Type: types.TypeText}, Data: &text}
+	require.NoError(t, item.Data.Encrypt("password"))
+	require.NoError(t, item.Item.Encrypt("password"))
+	stored, err := json.Marshal(item)
+	require.NoError(t, err)
+	public, private, err := box.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	// прежние версии клиента шифровали закрытый ключ без проверки целостности
+	legacyPrivate, err := encrypt.Encrypt(base64.StdEncoding.EncodeToString(private[:]), "password")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		wrapped func() string
+	}{
+		{"noVaultKey", func() string { return "" }},
+		{"passwordAsVaultKey", func() string {
+			wrapped, err := Wrap("password", "password")
+			require.NoError(t, err)
+			return wrapped
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeServer{
+				wrapped: tt.wrapped(),
+				keys:    &types.UserKeys{PublicKey: base64.StdEncoding.EncodeToString(public[:]), PrivateKey: legacyPrivate},
+				items:   map[string][]byte{"note": stored},
+			}
+			cli := newClient(t, fake)
+
+			var out bytes.Buffer
+			unlocked, err := Unlock("token", "password", cli, &out)
+			require.NoError(t, err)
+			assert.Len(t, unlocked, 32)
+			assert.NotEqual(t, "password", unlocked)
+			assert.Contains(t, out.String(), "recovery-kit")
+
+			// после перешифрования всех записей старый ключ удалён с сервера
+			assert.Empty(t, fake.legacy)
+			assert.Empty(t, fake.migration.LegacyKey)
+			decrypted, legacy, err := sharing.OpenPrivateKey(fake.migration.PrivateKey, unlocked)
+			require.NoError(t, err)
+			assert.False(t, legacy)
+			assert.Equal(t, private, decrypted)
+
+			migrated, err := types.ParseItem[*types.TextData](fake.items["note"], unlocked)
+			require.NoError(t, err)
+			assert.Equal(t, types.TextData("secret note"), *migrated.Data)
+
+			// при следующей разблокировке ключ прежний, записи больше не перешифровываются
+			out.Reset()
+			again, err := Unlock("token", "password", cli, &out)
+			require.NoError(t, err)
+			assert.Equal(t, unlocked, again)
+			assert.Empty(t, out.String())
+		})
+	}
+}
+
+func TestUnlockResumesMigration(t *testing.T) {
+	vaultKey, err := NewVaultKey()
+	require.NoError(t, err)
+	wrapped, err := Wrap(vaultKey, "password")
+	require.NoError(t, err)
+	legacyKey, err := encrypt.Seal([]byte(vaultKey), []byte("password"))
+	require.NoError(t, err)
+
+	text := types.TextData("secret note")
+	item := types.GenericItem[*types.TextData]{Item: types.Item{Key: "note", Type: types.TypeText}, Data: &text}
+	require.NoError(t, item.Data.Encrypt("password"))
+	require.NoError(t, item.Item.Encrypt("password"))
+	stored, err := json.Marshal(item)
+	require.NoError(t, err)
+
+	// перешифрование прервалось после замены ключа
+	fake := &fakeServer{
+		wrapped:   wrapped,
+		migration: &types.VaultKeyMigration{LegacyKey: legacyKey},
+		items:     map[string][]byte{"note": stored},
+		legacy:    []string{"note"},
+	}
+	cli := newClient(t, fake)
+
+	var out bytes.Buffer
+	unlocked, err := Unlock("token", "password", cli, &out)
+	require.NoError(t, err)
+	assert.Equal(t, vaultKey, unlocked)
+	assert.Empty(t, fake.legacy)
+	assert.Empty(t, fake.migration.LegacyKey)
+
+	migrated, err := types.ParseItem[*types.TextData](fake.items["note"], vaultKey)
+	require.NoError(t, err)
+	assert.Equal(t, types.TextData("secret note"), *migrated.Data)
+}
+
+func TestUpgradeCards(t *testing.T) {
+	vaultKey, err := NewVaultKey()
+	require.NoError(t, err)
+	wrapped, err := Wrap(vaultKey, "password")
+	require.NoError(t, err)
+
+	// карта сохранена до шифрования срока действия: срок лежит на сервере открытым текстом
+	card := types.CreditCardItem{Item: types.Item{Key: "card", Type: types.TypeCreditCard},
+		Data: &types.CreditCardData{Number: "4111111111111111", Name: "OWNER", CVC: "123"}}
+	require.NoError(t, card.Data.Encrypt(vaultKey))
+	require.NoError(t, card.Item.Encrypt(vaultKey))
+	card.Data.ValidMonth = ""
+	card.Data.ValidYear = ""
+	card.Data.ValidDate = time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC)
+	stored, err := json.Marshal(card)
+	require.NoError(t, err)
+
+	fake := &fakeServer{wrapped: wrapped, items: map[string][]byte{"card": stored}, cards: []string{"card"}}
+	cli := newClient(t, fake)
+
+	unlocked, err := Unlock("token", "password", cli, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, vaultKey, unlocked)
+	assert.Empty(t, fake.cards)
+
+	var saved types.CreditCardItem
+	require.NoError(t, json.Unmarshal(fake.items["card"], &saved))
+	assert.True(t, saved.Data.ValidDate.IsZero())
+	assert.NotEqual(t, "4", saved.Data.ValidMonth)
+	require.NoError(t, saved.Data.Decrypt(vaultKey))
+	assert.Equal(t, "4", saved.Data.ValidMonth)
+	assert.Equal(t, "2030", saved.Data.ValidYear)
+	assert.Equal(t, "4111111111111111", saved.Data.Number)
+}
+
+func TestWriteKit(t *testing.T) {
+	vaultKey, err := NewVaultKey()
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteKit(&out, "user", vaultKey, 3, 2))
+	var texts []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if IsShare(line) {
+			texts = append(texts, line)
+		}
+	}
+	require.Len(t, texts, 3)
+	got, err := Combined(texts[1:])
+	require.NoError(t, err)
+	assert.Equal(t, vaultKey, got)
+}
+
+func TestQR(t *testing.T) {
+	text := "GK1-0A1B2C3D-2-1-00FF-12345678"
+	code, err := QR(text)
+	require.NoError(t, err)
+
+	// обратно в изображение: каждый символ - две строки модулей, пробел - тёмные модули
+	lines := strings.Split(strings.TrimRight(code, "\n"), "\n")
+	const scale = 4
+	width := len([]rune(lines[0]))
+	img := image.NewGray(image.Rect(0, 0, width*scale, len(lines)*2*scale))
+	for y, line := range lines {
+		for x, r := range []rune(line) {
+			top := r == ' ' || r == '▄'
+			bottom := r == ' ' || r == '▀'
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetGray(x*scale+dx, 2*y*scale+dy, module(top))
+					img.SetGray(x*scale+dx, (2*y+1)*scale+dy, module(bottom))
+				}
+			}
+		}
+	}
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	require.NoError(t, err)
+	result, err := qrcode.NewQRCodeReader().Decode(bitmap, nil)
+	require.NoError(t, err)
+	assert.Equal(t, text, result.GetText())
+}
+
+func module(dark bool) color.Gray {
+	if dark {
+		return color.Gray{Y: 0}
+	}
+	return color.Gray{Y: 255}
+}
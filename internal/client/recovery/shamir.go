@@ -0,0 +1,183 @@
+package recovery
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+)
+
+// sharePrefix начало текстового представления доли, только заглавные буквы и цифры,
+// чтобы QR-код получался в компактном буквенно-цифровом режиме
+const sharePrefix = "GK1-"
+
+// ErrBadShare доля повреждена, из другого набора или её формат не распознан
+var ErrBadShare = errors.New("invalid recovery share")
+
+// ErrNotEnoughShares долей меньше порога
+var ErrNotEnoughShares = errors.New("not enough recovery shares")
+
+// Share доля секрета по схеме Шамира. Kit - случайный идентификатор набора, чтобы не смешать доли разных наборов,
+// Threshold - сколько долей нужно для восстановления, X - номер доли, Y - значения многочлена в точке X
+type Share struct {
+	Kit       string
+	Threshold int
+	X         byte
+	Y         []byte
+}
+
+// String текстовое представление доли с контрольной суммой для проверки при ручном вводе
+func (s Share) String() string {
+	body := fmt.Sprintf("%s%s-%d-%d-%X", sharePrefix, s.Kit, s.Threshold, s.X, s.Y)
+	return fmt.Sprintf("%s-%08X", body, crc32.ChecksumIEEE([]byte(body)))
+}
+
+// IsShare проверяет, похожа ли строка на долю, а не на ключ восстановления целиком
+func IsShare(s string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(s)), sharePrefix)
+}
+
+// ParseShare разбирает текстовое представление доли
+func ParseShare(s string) (Share, error) {
+	s = strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	sep := strings.LastIndex(s, "-")
+	if !strings.HasPrefix(s, sharePrefix) || sep < 0 {
+		return Share{}, ErrBadShare
+	}
+	body, checksum := s[:sep], s[sep+1:]
+	if fmt.Sprintf("%08X", crc32.ChecksumIEEE([]byte(body))) != checksum {
+		return Share{}, fmt.Errorf("%w: checksum mismatch, check for typos", ErrBadShare)
+	}
+	parts := strings.Split(strings.TrimPrefix(body, sharePrefix), "-")
+	if len(parts) != 4 {
+		return Share{}, ErrBadShare
+	}
+	threshold, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Share{}, ErrBadShare
+	}
+	x, err := strconv.Atoi(parts[2])
+	if err != nil || x < 1 || x > 255 {
+		return Share{}, ErrBadShare
+	}
+	y, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return Share{}, ErrBadShare
+	}
+	return Share{Kit: parts[0], Threshold: threshold, X: byte(x), Y: y}, nil
+}
+
+// Split делит секрет на n долей, из любых threshold которых его можно восстановить,
+// а из меньшего числа нельзя узнать о секрете ничего
+func Split(secret []byte, n int, threshold int) ([]Share, error) {
+	if threshold < 2 || threshold > n || n > 255 {
+		return nil, fmt.Errorf("threshold must be between 2 and number of shares, number of shares at most 255")
+	}
+	kit := make([]byte, 4)
+	_, err := rand.Read(kit)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{Kit: fmt.Sprintf("%X", kit), Threshold: threshold, X: byte(i + 1), Y: make([]byte, len(secret))}
+	}
+	// для каждого байта секрета свой случайный многочлен степени threshold-1 со свободным членом, равным байту
+	coefficients := make([]byte, threshold)
+	for b, value := range secret {
+		coefficients[0] = value
+		_, err = rand.Read(coefficients[1:])
+		if err != nil {
+			return nil, err
+		}
+		for i := range shares {
+			shares[i].Y[b] = evaluate(coefficients, shares[i].X)
+		}
+	}
+	return shares, nil
+}
+
+// Combine восстанавливает секрет из долей одного набора интерполяцией Лагранжа в нуле
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrNotEnoughShares
+	}
+	first := shares[0]
+	seen := map[byte]bool{}
+	var unique []Share
+	for _, s := range shares {
+		if s.Kit != first.Kit || s.Threshold != first.Threshold || len(s.Y) != len(first.Y) {
+			return nil, fmt.Errorf("%w: shares are from different kits", ErrBadShare)
+		}
+		if !seen[s.X] {
+			seen[s.X] = true
+			unique = append(unique, s)
+		}
+	}
+	if len(unique) < first.Threshold {
+		return nil, fmt.Errorf("%w: have %d of %d", ErrNotEnoughShares, len(unique), first.Threshold)
+	}
+	unique = unique[:first.Threshold]
+
+	secret := make([]byte, len(first.Y))
+	for i, si := range unique {
+		// базисный многочлен Лагранжа для точки si.X, вычисленный в нуле
+		basis := byte(1)
+		for j, sj := range unique {
+			if i != j {
+				basis = mul(basis, div(sj.X, sj.X^si.X))
+			}
+		}
+		for b := range secret {
+			secret[b] ^= mul(si.Y[b], basis)
+		}
+	}
+	return secret, nil
+}
+
+// evaluate значение многочлена в точке x по схеме Горнера
+func evaluate(coefficients []byte, x byte) byte {
+	result := byte(0)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = mul(result, x) ^ coefficients[i]
+	}
+	return result
+}
+
+// арифметика в поле GF(2^8) с многочленом x^8 + x^4 + x^3 + x + 1, как в AES
+var expTable, logTable = func() ([510]byte, [256]byte) {
+	var exp [510]byte
+	var log [256]byte
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		exp[i+255] = x
+		log[x] = byte(i)
+		// умножение на образующий элемент 3
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+	return exp, log
+}()
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+func div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
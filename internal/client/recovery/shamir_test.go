@@ -0,0 +1,82 @@
+package recovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("0123456789abcdefghijklmnopqrstuv")
+	shares, err := Split(secret, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	tests := []struct {
+		name    string
+		pick    []int
+		wantErr error
+	}{
+		{"first", []int{0, 1, 2}, nil},
+		{"last", []int{2, 3, 4}, nil},
+		{"mixed", []int{4, 0, 3}, nil},
+		{"all", []int{0, 1, 2, 3, 4}, nil},
+		{"tooFew", []int{1, 3}, ErrNotEnoughShares},
+		{"duplicates", []int{1, 1, 3}, ErrNotEnoughShares},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var picked []Share
+			for _, i := range tt.pick {
+				// доли проходят через текст, как при ручном вводе
+				parsed, err := ParseShare(shares[i].String())
+				require.NoError(t, err)
+				picked = append(picked, parsed)
+			}
+			got, err := Combine(picked)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, secret, got)
+		})
+	}
+}
+
+func TestCombineDifferentKits(t *testing.T) {
+	a, err := Split([]byte("secret"), 3, 2)
+	require.NoError(t, err)
+	b, err := Split([]byte("secret"), 3, 2)
+	require.NoError(t, err)
+	_, err = Combine([]Share{a[0], b[1]})
+	assert.ErrorIs(t, err, ErrBadShare)
+}
+
+func TestParseShare(t *testing.T) {
+	shares, err := Split([]byte("secret"), 2, 2)
+	require.NoError(t, err)
+	text := shares[0].String()
+
+	_, err = ParseShare(" " + text[:8] + " " + text[8:] + "\n")
+	assert.NoError(t, err, "whitespace is ignored")
+
+	typo := []byte(text)
+	typo[len(sharePrefix)+12] ^= 1
+	_, err = ParseShare(string(typo))
+	assert.ErrorIs(t, err, ErrBadShare)
+
+	_, err = ParseShare("not a share")
+	assert.ErrorIs(t, err, ErrBadShare)
+
+	assert.True(t, IsShare(text))
+	assert.False(t, IsShare("dGhpcyBpcyBhIHZhdWx0IGtleSEhISEh"))
+}
+
+func TestSplitThreshold(t *testing.T) {
+	_, err := Split([]byte("secret"), 3, 1)
+	assert.Error(t, err)
+	_, err = Split([]byte("secret"), 2, 3)
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+// Package reminders формирует отчёт о записях, срок действия которых скоро истекает,
+// и о паролях, которые пора сменить. Все данные расшифровываются локально на клиенте
+package reminders
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// Reason причина, по которой запись попала в отчёт
+type Reason string
+
+const (
+	ReasonExpires     Reason = "expires"
+	ReasonCardExpires Reason = "card_expires"
+	ReasonRotation    Reason = "rotation_due"
+)
+
+const day = 24 * time.Hour
+
+// Entry строка отчёта. DaysLeft отрицателен, если срок уже прошёл
+type Entry struct {
+	Key      string         `json:"key"`
+	Type     types.ItemType `json:"type"`
+	Reason   Reason         `json:"reason"`
+	DueDate  string         `json:"due_date"`
+	DaysLeft int            `json:"days_left"`
+}
+
+// String строковое представление, показываемое пользователю
+func (e Entry) String() string {
+	if e.DaysLeft < 0 {
+		return fmt.Sprintf("%s (%s): %s on %s, overdue by %d days", e.Key, e.Type, e.Reason, e.DueDate, -e.DaysLeft)
+	}
+	return fmt.Sprintf("%s (%s): %s on %s, %d days left", e.Key, e.Type, e.Reason, e.DueDate, e.DaysLeft)
+}
+
+// Check проверяет запись и (для кредитных карт) данные карты. Возвращает строки отчёта для сроков,
+// наступающих не позже чем через within дней от now, включая уже прошедшие
+func Check(item types.Item, card *types.CreditCardData, now time.Time, within int) []Entry {
+	today := truncateDay(now)
+	var result []Entry
+
+	add := func(reason Reason, due time.Time) {
+		daysLeft := int(truncateDay(due).Sub(today) / day)
+		if daysLeft > within {
+			return
+		}
+		result = append(result, Entry{
+			Key:      item.Key,
+			Type:     item.Type,
+			Reason:   reason,
+			DueDate:  due.Format(types.DateFormat),
+			DaysLeft: daysLeft,
+		})
+	}
+
+	if item.ExpiresAt != "" {
+		if due, err := time.Parse(types.DateFormat, item.ExpiresAt); err == nil {
+			add(ReasonExpires, due)
+		}
+	}
+	if card != nil {
+		if due, ok := cardExpiry(card); ok {
+			add(ReasonCardExpires, due)
+		}
+	}
+	if changed := item.SecretChanged(); item.RotateDays != "" && changed != nil {
+		if days, err := strconv.Atoi(item.RotateDays); err == nil && days > 0 {
+			add(ReasonRotation, changed.AddDate(0, 0, days))
+		}
+	}
+	return result
+}
+
+// Report загружает с сервера все записи пользователя и формирует отчёт, отсортированный по срокам
+func Report(token string, pass string, cli *client.Client, now time.Time, within int) ([]Entry, error) {
+	items, err := cli.AllRecords(token, pass)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Entry{}
+	for _, item := range items {
+		var card *types.CreditCardData
+		if item.Type == types.TypeCreditCard {
+			data, err := cli.GetItem(token, item.Key)
+			if err != nil {
+				return nil, err
+			}
+			parsed, err := types.ParseItem[*types.CreditCardData](data, pass)
+			if err != nil {
+				return nil, err
+			}
+			card = parsed.Data
+		}
+		result = append(result, Check(item, card, now, within)...)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DaysLeft < result[j].DaysLeft
+	})
+	return result, nil
+}
+
+// cardExpiry карта действительна до последнего дня указанного месяца включительно
+func cardExpiry(card *types.CreditCardData) (time.Time, bool) {
+	month, err := strconv.Atoi(card.ValidMonth)
+	if err != nil || month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+	year, err := strconv.Atoi(card.ValidYear)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC), true
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,87 @@
+package reminders
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+var now = time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+
+func TestCheck(t *testing.T) {
+	updated := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		item types.Item
+		card *types.CreditCardData
+		want []Entry
+	}{
+		{"nothing set", types.Item{Key: "a", Type: types.TypeText}, nil, nil},
+		{"expires soon", types.Item{Key: "a", Type: types.TypeText, ExpiresAt: "2024-05-20"}, nil,
+			[]Entry{{Key: "a", Type: types.TypeText, Reason: ReasonExpires, DueDate: "2024-05-20", DaysLeft: 10}}},
+		{"expires later", types.Item{Key: "a", Type: types.TypeText, ExpiresAt: "2024-12-20"}, nil, nil},
+		{"expired", types.Item{Key: "a", Type: types.TypeText, ExpiresAt: "2024-05-01"}, nil,
+			[]Entry{{Key: "a", Type: types.TypeText, Reason: ReasonExpires, DueDate: "2024-05-01", DaysLeft: -9}}},
+		{"card expires this month", types.Item{Key: "c", Type: types.TypeCreditCard}, &types.CreditCardData{ValidMonth: "5", ValidYear: "2024"},
+			[]Entry{{Key: "c", Type: types.TypeCreditCard, Reason: ReasonCardExpires, DueDate: "2024-05-31", DaysLeft: 21}}},
+		{"card valid", types.Item{Key: "c", Type: types.TypeCreditCard}, &types.CreditCardData{ValidMonth: "12", ValidYear: "2030"}, nil},
+		{"rotation overdue", types.Item{Key: "p", Type: types.TypeLogoPass, RotateDays: "90", UpdatedAt: &updated}, nil,
+			[]Entry{{Key: "p", Type: types.TypeLogoPass, Reason: ReasonRotation, DueDate: "2024-05-01", DaysLeft: -9}}},
+		{"rotation later", types.Item{Key: "p", Type: types.TypeLogoPass, RotateDays: "365", UpdatedAt: &updated}, nil, nil},
+		{"info edited after secret", types.Item{Key: "p", Type: types.TypeLogoPass, RotateDays: "90", UpdatedAt: &now, SecretChangedAt: &updated}, nil,
+			[]Entry{{Key: "p", Type: types.TypeLogoPass, Reason: ReasonRotation, DueDate: "2024-05-01", DaysLeft: -9}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Check(tt.item, tt.card, now, 30))
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	pass := "secret"
+
+	items := []types.Item{
+		{Key: "text", Type: types.TypeText, ExpiresAt: "2024-05-20"},
+		{Key: "card", Type: types.TypeCreditCard},
+	}
+	for i := range items {
+		require.NoError(t, items[i].Encrypt(pass))
+	}
+	card := types.CreditCardData{Number: "4111111111111111", ValidMonth: "5", ValidYear: "2024"}
+	require.NoError(t, card.Encrypt(pass))
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/item/list":
+			_ = json.NewEncoder(w).Encode(items)
+		case "/api/item/card":
+			_ = json.NewEncoder(w).Encode(types.CreditCardItem{Item: items[1], Data: &card})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	conf, _ := config.NewClientConfig()
+	conf.ServerAddress = svr.URL
+	conf.SSLKey = "../../../.ssl/ca.key"
+	cli, err := client.NewClient(conf)
+	require.NoError(t, err)
+
+	got, err := Report("token", pass, cli, now, 30)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Key: "text", Type: types.TypeText, Reason: ReasonExpires, DueDate: "2024-05-20", DaysLeft: 10},
+		{Key: "card", Type: types.TypeCreditCard, Reason: ReasonCardExpires, DueDate: "2024-05-31", DaysLeft: 21},
+	}, got)
+}
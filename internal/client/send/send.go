@@ -0,0 +1,86 @@
+// Package send одноразовые ссылки для передачи секрета тому, у кого нет учётной записи.
+// Секрет шифруется на клиенте случайным ключом, ключ кладётся во фрагмент ссылки (после #),
+// который браузер не отправляет на сервер. Сервер хранит только шифротекст и удаляет его
+// после последнего просмотра или по истечении срока
+package send
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/sharing"
+	"github.com/wellywell/gophkeeper/internal/encrypt"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// ErrBadLink ссылка не похожа на одноразовую ссылку gophkeeper
+var ErrBadLink = errors.New("not a send link: expected https://host/send/ID#KEY")
+
+// Options параметры одноразовой ссылки
+type Options struct {
+	MaxViews int
+	TTL      time.Duration
+	Password string
+}
+
+// Create шифрует text и создаёт одноразовую ссылку, возвращает ссылку вместе с ключом
+func Create(token string, cli *client.Client, text string, opts Options) (string, *types.Send, error) {
+	key, err := sharing.NewDataKey()
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := encrypt.Seal(key, []byte(text))
+	if err != nil {
+		return "", nil, err
+	}
+	created, err := cli.CreateSend(token, types.NewSend{
+		Data:      data,
+		MaxViews:  opts.MaxViews,
+		ExpiresAt: time.Now().Add(opts.TTL),
+		Password:  opts.Password,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	link := fmt.Sprintf("%s/send/%s#%s", strings.TrimRight(cli.Address(), "/"), created.ID, base64.RawURLEncoding.EncodeToString(key))
+	return link, created, nil
+}
+
+// ParseLink достаёт из ссылки идентификатор и ключ
+func ParseLink(link string) (string, []byte, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", nil, ErrBadLink
+	}
+	id, found := strings.CutPrefix(u.Path, "/send/")
+	if !found || id == "" || strings.Contains(id, "/") || u.Fragment == "" {
+		return "", nil, ErrBadLink
+	}
+	key, err := base64.RawURLEncoding.DecodeString(u.Fragment)
+	if err != nil {
+		return "", nil, ErrBadLink
+	}
+	return id, key, nil
+}
+
+// Open засчитывает просмотр и расшифровывает секрет по ссылке. Возвращает секрет и число оставшихся просмотров
+func Open(cli *client.Client, link string, password string) (string, int, error) {
+	id, key, err := ParseLink(link)
+	if err != nil {
+		return "", 0, err
+	}
+	content, err := cli.OpenSend(id, password)
+	if err != nil {
+		return "", 0, err
+	}
+	plain, err := encrypt.Open(key, content.Data)
+	if err != nil {
+		return "", content.ViewsLeft, fmt.Errorf("could not decrypt secret, the link is damaged: %w", err)
+	}
+	return string(plain), content.ViewsLeft, nil
+}
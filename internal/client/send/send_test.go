@@ -0,0 +1,100 @@
+package send
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func TestParseLink(t *testing.T) {
+	key := base64.RawURLEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	tests := []struct {
+		name    string
+		link    string
+		wantID  string
+		wantErr bool
+	}{
+		{"ok", "https://keeper.example/send/abc#" + key, "abc", false},
+		{"noKey", "https://keeper.example/send/abc", "", true},
+		{"badKey", "https://keeper.example/send/abc#!!", "", true},
+		{"otherPath", "https://keeper.example/api/item/abc#" + key, "", true},
+		{"nested", "https://keeper.example/send/abc/def#" + key, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, _, err := ParseLink(tt.link)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrBadLink)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, id)
+		})
+	}
+}
+
+func TestCreateOpen(t *testing.T) {
+	var stored types.NewSend
+	var requests []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.URL.String()+string(body))
+		switch r.URL.Path {
+		case "/api/send":
+			require.NoError(t, json.Unmarshal(body, &stored))
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(types.Send{ID: "abc", MaxViews: stored.MaxViews, ExpiresAt: stored.ExpiresAt})
+		case "/api/send/abc/open":
+			var access types.SendAccess
+			require.NoError(t, json.Unmarshal(body, &access))
+			if access.Password != "letmein" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(types.SendContent{Data: stored.Data, ViewsLeft: 1})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	conf, _ := config.NewClientConfig()
+	conf.ServerAddress = svr.URL
+	conf.SSLKey = "../../../.ssl/ca.key"
+	cli, err := client.NewClient(conf)
+	require.NoError(t, err)
+
+	link, created, err := Create("token", cli, "s3cr3t", Options{MaxViews: 2, TTL: time.Hour, Password: "letmein"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", created.ID)
+	assert.True(t, strings.HasPrefix(link, svr.URL+"/send/abc#"))
+	assert.Equal(t, 2, stored.MaxViews)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+
+	_, _, err = Open(cli, link, "guess")
+	assert.Error(t, err)
+
+	text, left, err := Open(cli, link, "letmein")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", text)
+	assert.Equal(t, 1, left)
+
+	// ни секрет, ни ключ из фрагмента не уходят на сервер
+	fragment := link[strings.Index(link, "#")+1:]
+	for _, r := range requests {
+		assert.NotContains(t, r, "s3cr3t")
+		assert.NotContains(t, r, fragment)
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// Address адрес сервера, с которым работает клиент
+func (c *Client) Address() string {
+	return c.address
+}
+
+// CreateSend создание одноразовой ссылки
+func (c *Client) CreateSend(token string, send types.NewSend) (*types.Send, error) {
+	var created types.Send
+	err := c.requestJSON(token, http.MethodPost, "/api/send", send, http.StatusCreated, &created)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// ListSends получение действующих одноразовых ссылок пользователя
+func (c *Client) ListSends(token string) ([]types.Send, error) {
+	var sends []types.Send
+	err := c.requestJSON(token, http.MethodGet, "/api/send", nil, http.StatusOK, &sends)
+	return sends, err
+}
+
+// DeleteSend досрочное удаление одноразовой ссылки
+func (c *Client) DeleteSend(token string, id string) error {
+	return c.requestJSON(token, http.MethodDelete, "/api/send/"+url.PathEscape(id), nil, http.StatusOK, nil)
+}
+
+// SendInfo сведения об одноразовой ссылке, авторизация не нужна
+func (c *Client) SendInfo(id string) (*types.Send, error) {
+	var send types.Send
+	err := c.requestJSON("", http.MethodGet, "/api/send/"+url.PathEscape(id), nil, http.StatusOK, &send)
+	if err != nil {
+		return nil, err
+	}
+	return &send, nil
+}
+
+// OpenSend получение содержимого одноразовой ссылки с засчитыванием просмотра, авторизация не нужна
+func (c *Client) OpenSend(id string, password string) (*types.SendContent, error) {
+	var content types.SendContent
+	err := c.requestJSON("", http.MethodPost, fmt.Sprintf("/api/send/%s/open", url.PathEscape(id)), types.SendAccess{Password: password}, http.StatusOK, &content)
+	if err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
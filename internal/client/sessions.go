@@ -0,0 +1,45 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// Sessions получение сессий пользователя: устройств, на которых выполнен вход
+func (c *Client) Sessions(token string) ([]types.Session, error) {
+	var sessions []types.Session
+	err := c.requestJSON(token, http.MethodGet, "/api/user/sessions", nil, http.StatusOK, &sessions)
+	return sessions, err
+}
+
+// DeleteSession выход из учётной записи на устройстве
+func (c *Client) DeleteSession(token string, id int) error {
+	return c.requestJSON(token, http.MethodDelete, fmt.Sprintf("/api/user/sessions/%d", id), nil, http.StatusOK, nil)
+}
+
+// DeleteOtherSessions выход из учётной записи на всех устройствах, кроме текущего. Возвращает число завершённых сессий
+func (c *Client) DeleteOtherSessions(token string) (int, error) {
+	sessions, err := c.Sessions(token)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, s := range sessions {
+		if s.Current {
+			continue
+		}
+		err = c.DeleteSession(token, s.ID)
+		// сессия могла истечь или быть завершена с другого устройства
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func TestClient_DeleteOtherSessions(t *testing.T) {
+	var deleted []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/user/sessions":
+			_ = json.NewEncoder(w).Encode([]types.Session{{ID: 5, Current: true}, {ID: 6}, {ID: 7}})
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+			if r.URL.Path == "/api/user/sessions/7" {
+				http.Error(w, "Session not found", http.StatusNotFound)
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer svr.Close()
+
+	c, _ := NewClient(conf)
+	c.address = svr.URL
+
+	n, err := c.DeleteOtherSessions("token")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"/api/user/sessions/6", "/api/user/sessions/7"}, deleted)
+}
+
+func TestClient_LoginSendsDevice(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get(DeviceHeader))
+		assert.Equal(t, "1.2.3", r.Header.Get(VersionHeader))
+		w.Header().Set(Token, "token")
+	}))
+	defer svr.Close()
+
+	c, _ := NewClient(conf)
+	c.address = svr.URL
+	c.SetVersion("1.2.3")
+
+	token, err := c.Login("user", "pass")
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// ErrNotFound сервер не нашёл запрошенный объект
+var ErrNotFound = errors.New("not found")
+
+// GetUserKeys получение с сервера ключевой пары пользователя. Если ключи ещё не созданы, возвращается ErrNotFound
+func (c *Client) GetUserKeys(token string) (*types.UserKeys, error) {
+	var keys types.UserKeys
+	err := c.requestJSON(token, http.MethodGet, "/api/user/keys", nil, http.StatusOK, &keys)
+	if err != nil {
+		return nil, err
+	}
+	return &keys, nil
+}
+
+// SetUserKeys публикация ключевой пары пользователя. Приватный ключ должен быть зашифрован
+func (c *Client) SetUserKeys(token string, keys types.UserKeys) error {
+	return c.requestJSON(token, http.MethodPut, "/api/user/keys", keys, http.StatusCreated, nil)
+}
+
+// SetPrivateKey замена на сервере зашифрованного приватного ключа пользователя
+func (c *Client) SetPrivateKey(token string, privateKey string) error {
+	return c.requestJSON(token, http.MethodPut, "/api/user/keys/private", types.UserKeys{PrivateKey: privateKey}, http.StatusOK, nil)
+}
+
+// GetPublicKey получение открытого ключа пользователя username
+func (c *Client) GetPublicKey(token string, username string) (string, error) {
+	var keys types.UserKeys
+	err := c.requestJSON(token, http.MethodGet, fmt.Sprintf("/api/user/%s/public_key", url.PathEscape(username)), nil, http.StatusOK, &keys)
+	if err != nil {
+		return "", err
+	}
+	return keys.PublicKey, nil
+}
+
+// ShareItem выдача доступа к записи key пользователю share.Recipient
+func (c *Client) ShareItem(token string, key string, share types.Share) error {
+	return c.requestJSON(token, http.MethodPost, fmt.Sprintf("/api/item/%s/share", url.PathEscape(key)), share, http.StatusCreated, nil)
+}
+
+// ItemShares получение списка выданных доступов к записи key
+func (c *Client) ItemShares(token string, key string) ([]types.Share, error) {
+	var shares []types.Share
+	err := c.requestJSON(token, http.MethodGet, fmt.Sprintf("/api/item/%s/shares", url.PathEscape(key)), nil, http.StatusOK, &shares)
+	return shares, err
+}
+
+// RevokeShare отзыв у пользователя username доступа к записи key
+func (c *Client) RevokeShare(token string, key string, username string) error {
+	return c.requestJSON(token, http.MethodDelete, fmt.Sprintf("/api/item/%s/share/%s", url.PathEscape(key), url.PathEscape(username)), nil, http.StatusOK, nil)
+}
+
+// SharedWithMe получение списка записей, которыми с пользователем поделились другие
+func (c *Client) SharedWithMe(token string) ([]types.Share, error) {
+	var shares []types.Share
+	err := c.requestJSON(token, http.MethodGet, "/api/shares", nil, http.StatusOK, &shares)
+	return shares, err
+}
+
+// UpdateShare замена зашифрованных данных общей записи
+func (c *Client) UpdateShare(token string, id int, data string) error {
+	return c.requestJSON(token, http.MethodPut, fmt.Sprintf("/api/shares/%d", id), types.Share{Data: data}, http.StatusOK, nil)
+}
+
+// requestJSON отправляет запрос с телом в JSON и разбирает JSON-ответ в out, если он передан
+func (c *Client) requestJSON(token string, method string, path string, body any, expected int, out any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("could not serialize data")
+		}
+	}
+	headers := map[string]string{
+		Token:          token,
+		"Content-Type": "application/json",
+	}
+	resp, err := c.doRequest(c.address+path, method, data, headers)
+	if err != nil {
+		return fmt.Errorf("could not make request %w", err)
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, bodyBytes)
+	}
+	if resp.StatusCode != expected {
+		return fmt.Errorf("error %s %s %s", method, resp.Status, bodyBytes)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(bodyBytes, out)
+}
@@ -0,0 +1,395 @@
+// Package sharing позволяет делиться записями с другими пользователями так, что сервер видит только шифротекст.
+//
+// У каждого пользователя есть ключевая пара X25519: открытый ключ публикуется на сервере, приватный хранится там же,
+// зашифрованный ключом хранилища пользователя (AES-GCM). Чтобы поделиться записью, клиент владельца создаёт случайный
+// ключ данных, шифрует им запись (AES-256-GCM) и зашифровывает сам ключ данных открытыми ключами получателя
+// и владельца (nacl/box, анонимный конверт). Получатель расшифровывает ключ данных своим приватным ключом.
+package sharing
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/export"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/encrypt"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+const (
+	keySize       = 32
+	dataKeySize   = 32
+	privateKeyTag = "gophkeeper private key\x00"
+)
+
+var (
+	// ErrDecrypt не удалось расшифровать ключ данных или запись
+	ErrDecrypt = errors.New("could not decrypt shared item")
+	// ErrNoKeys получатель ещё не создал ключевую пару
+	ErrNoKeys = errors.New("recipient has not set up sharing yet: they need to log in with an up-to-date client once")
+	// ErrPrivateKey приватный ключ не расшифровался ключом хранилища или не подходит к открытому
+	ErrPrivateKey = errors.New("could not decrypt private key with this vault key")
+	// ErrNotShared запись не выдана этому пользователю
+	ErrNotShared = errors.New("item is not shared with this user")
+)
+
+// KeyPair расшифрованная ключевая пара пользователя
+type KeyPair struct {
+	Public  *[keySize]byte
+	Private *[keySize]byte
+}
+
+// Shared общая запись вместе с расшифрованными данными
+type Shared struct {
+	types.Share
+	Record  importer.Record
+	dataKey []byte
+}
+
+// EnsureKeys загружает ключевую пару пользователя, а если её нет - создаёт и публикует
+func EnsureKeys(token string, pass string, cli *client.Client) (*KeyPair, error) {
+	keys, err := cli.GetUserKeys(token)
+	if errors.Is(err, client.ErrNotFound) {
+		return createKeys(token, pass, cli)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := DecodeKey(keys.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	private, legacy, err := OpenPrivateKey(keys.PrivateKey, pass)
+	if err != nil {
+		return nil, err
+	}
+	if !matches(public, private) {
+		return nil, ErrPrivateKey
+	}
+	if legacy {
+		wrapped, err := SealPrivateKey(private, pass)
+		if err != nil {
+			return nil, err
+		}
+		err = cli.SetPrivateKey(token, wrapped)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &KeyPair{Public: public, Private: private}, nil
+}
+
+// SealPrivateKey шифрует приватный ключ ключом хранилища pass (AES-GCM)
+func SealPrivateKey(private *[keySize]byte, pass string) (string, error) {
+	return encrypt.Seal(privateKeyKey(pass), []byte(encodeKey(private)))
+}
+
+// OpenPrivateKey расшифровывает приватный ключ, зашифрованный SealPrivateKey. Ключи, зашифрованные прежними
+// версиями клиента без проверки целостности, тоже расшифровываются, тогда legacy - true и ключ нужно перешифровать
+func OpenPrivateKey(wrapped string, pass string) (private *[keySize]byte, legacy bool, err error) {
+	plain, err := encrypt.Open(privateKeyKey(pass), wrapped)
+	if err == nil {
+		private, err = DecodeKey(string(plain))
+		return private, false, err
+	}
+	if !errors.Is(err, encrypt.ErrAuthentication) {
+		return nil, false, err
+	}
+	// encrypt.Decrypt не проверяет base64 и падает на повреждённом шифротексте
+	_, err = base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, false, ErrPrivateKey
+	}
+	encoded, err := encrypt.Decrypt(wrapped, pass)
+	if err != nil {
+		return nil, false, err
+	}
+	// без проверки целостности неверный ключ даёт мусор вместо ошибки
+	private, err = DecodeKey(encoded)
+	if err != nil {
+		return nil, false, ErrPrivateKey
+	}
+	return private, true, nil
+}
+
+func createKeys(token string, pass string, cli *client.Client) (*KeyPair, error) {
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := SealPrivateKey(private, pass)
+	if err != nil {
+		return nil, err
+	}
+	err = cli.SetUserKeys(token, types.UserKeys{
+		PublicKey:  encodeKey(public),
+		PrivateKey: wrapped,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{Public: public, Private: private}, nil
+}
+
+// matches проверяет, что приватный ключ соответствует открытому
+func matches(public *[keySize]byte, private *[keySize]byte) bool {
+	derived, err := curve25519.X25519(private[:], curve25519.Basepoint)
+	return err == nil && subtle.ConstantTimeCompare(derived, public[:]) == 1
+}
+
+// privateKeyKey ключ AES-256 для приватного ключа, выведенный из ключа хранилища
+func privateKeyKey(pass string) []byte {
+	sum := sha256.Sum256([]byte(privateKeyTag + pass))
+	return sum[:]
+}
+
+// Share делится записью key с пользователем recipient. Повторный вызов обновляет права и данные
+func Share(token string, pass string, cli *client.Client, key string, recipient string, permission types.Permission) error {
+	keys, err := EnsureKeys(token, pass, cli)
+	if err != nil {
+		return err
+	}
+	recipientKey, err := PublicKey(token, cli, recipient)
+	if err != nil {
+		return err
+	}
+	record, err := export.LoadKey(token, pass, cli, key)
+	if err != nil {
+		return err
+	}
+
+	share, err := Seal(record, keys.Public, recipientKey)
+	if err != nil {
+		return err
+	}
+	share.Recipient = recipient
+	share.Permission = permission
+	return cli.ShareItem(token, key, *share)
+}
+
+// PublicKey получает с сервера открытый ключ пользователя username
+func PublicKey(token string, cli *client.Client, username string) (*[keySize]byte, error) {
+	encoded, err := cli.GetPublicKey(token, username)
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			return nil, fmt.Errorf("%s: %w", username, ErrNoKeys)
+		}
+		return nil, err
+	}
+	return DecodeKey(encoded)
+}
+
+// Seal шифрует запись новым ключом данных и зашифровывает ключ данных для владельца и получателя
+func Seal(record importer.Record, owner *[keySize]byte, recipient *[keySize]byte) (*types.Share, error) {
+	dataKey, err := NewDataKey()
+	if err != nil {
+		return nil, err
+	}
+	data, err := SealRecord(dataKey, record)
+	if err != nil {
+		return nil, err
+	}
+	ownerKey, err := WrapKey(dataKey, owner)
+	if err != nil {
+		return nil, err
+	}
+	recipientKey, err := WrapKey(dataKey, recipient)
+	if err != nil {
+		return nil, err
+	}
+	return &types.Share{
+		OwnerKey:     ownerKey,
+		RecipientKey: recipientKey,
+		Data:         data,
+	}, nil
+}
+
+// NewDataKey создаёт случайный симметричный ключ для шифрования записей
+func NewDataKey() ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	_, err := rand.Read(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return dataKey, nil
+}
+
+// WrapKey зашифровывает симметричный ключ открытым ключом получателя (анонимный конверт nacl/box)
+func WrapKey(dataKey []byte, public *[keySize]byte) (string, error) {
+	sealed, err := box.SealAnonymous(nil, dataKey, public, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// UnwrapKey расшифровывает симметричный ключ, зашифрованный WrapKey, приватным ключом пользователя
+func UnwrapKey(wrapped string, keys *KeyPair) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	dataKey, ok := box.OpenAnonymous(nil, sealed, keys.Public, keys.Private)
+	if !ok {
+		return nil, ErrDecrypt
+	}
+	return dataKey, nil
+}
+
+// Open расшифровывает общую запись. Владелец открывает её своим экземпляром ключа данных, получатель - своим
+func Open(share types.Share, keys *KeyPair, asOwner bool) (*Shared, error) {
+	wrapped := share.RecipientKey
+	if asOwner {
+		wrapped = share.OwnerKey
+	}
+	dataKey, err := UnwrapKey(wrapped, keys)
+	if err != nil {
+		return nil, err
+	}
+	record, err := OpenRecord(dataKey, share.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &Shared{Share: share, Record: *record, dataKey: dataKey}, nil
+}
+
+// Received записи, которыми с пользователем поделились другие
+func Received(token string, pass string, cli *client.Client) ([]Shared, error) {
+	keys, err := EnsureKeys(token, pass, cli)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := cli.SharedWithMe(token)
+	if err != nil {
+		return nil, err
+	}
+	return openAll(shares, keys, false)
+}
+
+// Granted выданные владельцем доступы к записи key
+func Granted(token string, pass string, cli *client.Client, key string) ([]Shared, error) {
+	shares, err := cli.ItemShares(token, key)
+	if err != nil || len(shares) == 0 {
+		return nil, err
+	}
+	keys, err := EnsureKeys(token, pass, cli)
+	if err != nil {
+		return nil, err
+	}
+	return openAll(shares, keys, true)
+}
+
+func openAll(shares []types.Share, keys *KeyPair, asOwner bool) ([]Shared, error) {
+	result := make([]Shared, 0, len(shares))
+	for _, s := range shares {
+		opened, err := Open(s, keys, asOwner)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.String(), err)
+		}
+		result = append(result, *opened)
+	}
+	return result, nil
+}
+
+// Update шифрует новую версию записи ключом данных общей записи и отправляет на сервер
+func Update(token string, cli *client.Client, shared Shared, record importer.Record) error {
+	data, err := SealRecord(shared.dataKey, record)
+	if err != nil {
+		return err
+	}
+	return cli.UpdateShare(token, shared.ID, data)
+}
+
+// Sync обновляет данные во всех выданных доступах к записи key после её изменения владельцем.
+// Возвращает число обновлённых доступов
+func Sync(token string, pass string, cli *client.Client, key string) (int, error) {
+	granted, err := Granted(token, pass, cli, key)
+	if err != nil || len(granted) == 0 {
+		return 0, err
+	}
+	record, err := export.LoadKey(token, pass, cli, key)
+	if err != nil {
+		return 0, err
+	}
+	for i, shared := range granted {
+		err = Update(token, cli, shared, record)
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(granted), nil
+}
+
+// Pull переносит в хранилище владельца изменения, сделанные получателем recipient с правом записи,
+// и рассылает их остальным получателям
+func Pull(token string, pass string, cli *client.Client, key string, recipient string) error {
+	granted, err := Granted(token, pass, cli, key)
+	if err != nil {
+		return err
+	}
+	for _, shared := range granted {
+		if shared.Recipient != recipient {
+			continue
+		}
+		record := shared.Record
+		record.Item.Key = key
+		err = importer.Update(token, pass, cli, record)
+		if err != nil {
+			return err
+		}
+		_, err = Sync(token, pass, cli, key)
+		return err
+	}
+	return ErrNotShared
+}
+
+// SealRecord шифрует запись симметричным ключом (AES-256-GCM)
+func SealRecord(dataKey []byte, record importer.Record) (string, error) {
+	plain, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	return encrypt.Seal(dataKey, plain)
+}
+
+// OpenRecord расшифровывает запись, зашифрованную SealRecord
+func OpenRecord(dataKey []byte, data string) (*importer.Record, error) {
+	plain, err := encrypt.Open(dataKey, data)
+	if err != nil {
+		if errors.Is(err, encrypt.ErrAuthentication) {
+			return nil, ErrDecrypt
+		}
+		return nil, err
+	}
+	var record importer.Record
+	err = json.Unmarshal(plain, &record)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func encodeKey(key *[keySize]byte) string {
+	return base64.StdEncoding.EncodeToString(key[:])
+}
+
+// DecodeKey разбирает ключ X25519 в base64
+func DecodeKey(encoded string) (*[keySize]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != keySize {
+		return nil, fmt.Errorf("invalid key")
+	}
+	var key [keySize]byte
+	copy(key[:], raw)
+	return &key, nil
+}
@@ -0,0 +1,182 @@
+package sharing
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/box"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/client/importer"
+	"github.com/wellywell/gophkeeper/internal/config"
+	"github.com/wellywell/gophkeeper/internal/encrypt"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func newKeyPair(t *testing.T) *KeyPair {
+	public, private, err := box.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &KeyPair{Public: public, Private: private}
+}
+
+func TestSealOpen(t *testing.T) {
+	owner := newKeyPair(t)
+	recipient := newKeyPair(t)
+	stranger := newKeyPair(t)
+	record := importer.Record{
+		Item:  types.Item{Key: "site", Type: types.TypeLogoPass},
+		Login: &types.LoginPassword{Login: "me", Password: "pw"},
+	}
+
+	share, err := Seal(record, owner.Public, recipient.Public)
+	require.NoError(t, err)
+	raw, err := base64.StdEncoding.DecodeString(share.Data)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), `"pw"`)
+
+	opened, err := Open(*share, recipient, false)
+	require.NoError(t, err)
+	assert.Equal(t, record, opened.Record)
+
+	opened, err = Open(*share, owner, true)
+	require.NoError(t, err)
+	assert.Equal(t, record, opened.Record)
+
+	_, err = Open(*share, stranger, false)
+	assert.ErrorIs(t, err, ErrDecrypt)
+	_, err = Open(*share, owner, false)
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	share.Data = share.Data[:len(share.Data)-4] + "AAAA"
+	_, err = Open(*share, recipient, false)
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestShareFlow(t *testing.T) {
+	pass := "secret"
+	recipient := newKeyPair(t)
+	item := types.Item{Key: "note", Type: types.TypeText}
+	require.NoError(t, item.Encrypt(pass))
+	text := types.TextData("hello")
+	require.NoError(t, text.Encrypt(pass))
+
+	var stored *types.UserKeys
+	var shared *types.Share
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/user/keys" && r.Method == http.MethodGet:
+			if stored == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(stored)
+		case r.URL.Path == "/api/user/keys" && r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &stored))
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/api/user/bob/public_key":
+			_ = json.NewEncoder(w).Encode(types.UserKeys{PublicKey: encodeKey(recipient.Public)})
+		case r.URL.Path == "/api/item/note":
+			_ = json.NewEncoder(w).Encode(types.TextItem{Item: item, Data: text})
+		case r.URL.Path == "/api/item/note/share":
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &shared))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	conf, _ := config.NewClientConfig()
+	conf.ServerAddress = svr.URL
+	conf.SSLKey = "../../../.ssl/ca.key"
+	cli, err := client.NewClient(conf)
+	require.NoError(t, err)
+
+	keys, err := EnsureKeys("token", pass, cli)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.NotEqual(t, encodeKey(keys.Private), stored.PrivateKey)
+
+	again, err := EnsureKeys("token", pass, cli)
+	require.NoError(t, err)
+	assert.Equal(t, keys, again)
+
+	err = Share("token", pass, cli, "note", "alice", types.PermissionRead)
+	assert.ErrorIs(t, err, ErrNoKeys)
+
+	require.NoError(t, Share("token", pass, cli, "note", "bob", types.PermissionWrite))
+	require.NotNil(t, shared)
+	assert.Equal(t, "bob", shared.Recipient)
+	assert.Equal(t, types.PermissionWrite, shared.Permission)
+
+	opened, err := Open(*shared, recipient, false)
+	require.NoError(t, err)
+	hello := types.TextData("hello")
+	assert.Equal(t, &hello, opened.Record.Text)
+	assert.Equal(t, "note", opened.Record.Item.Key)
+}
+
+func TestEnsureKeysLegacy(t *testing.T) {
+	pass := "vault key"
+	keys := newKeyPair(t)
+	// прежние версии клиента шифровали приватный ключ без проверки целостности
+	legacy, err := encrypt.Encrypt(encodeKey(keys.Private), pass)
+	require.NoError(t, err)
+	stored := types.UserKeys{PublicKey: encodeKey(keys.Public), PrivateKey: legacy}
+
+	rewrapped := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/user/keys" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(stored)
+		case r.URL.Path == "/api/user/keys/private" && r.Method == http.MethodPut:
+			var body types.UserKeys
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			stored.PrivateKey = body.PrivateKey
+			rewrapped++
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+	// NewClientConfig регистрирует флаги и может быть вызван только один раз
+	cli, err := client.NewClient(&config.ClientConfig{ServerAddress: svr.URL, SSLKey: "../../../.ssl/ca.key"})
+	require.NoError(t, err)
+
+	got, err := EnsureKeys("token", pass, cli)
+	require.NoError(t, err)
+	assert.Equal(t, keys, got)
+	assert.Equal(t, 1, rewrapped)
+	private, wasLegacy, err := OpenPrivateKey(stored.PrivateKey, pass)
+	require.NoError(t, err)
+	assert.False(t, wasLegacy)
+	assert.Equal(t, keys.Private, private)
+
+	// перешифрованный ключ больше не перезаписывается
+	got, err = EnsureKeys("token", pass, cli)
+	require.NoError(t, err)
+	assert.Equal(t, keys, got)
+	assert.Equal(t, 1, rewrapped)
+
+	// подменённый или зашифрованный другим ключом приватный ключ не принимается
+	_, err = EnsureKeys("token", "other key", cli)
+	assert.ErrorIs(t, err, ErrPrivateKey)
+	tampered := []byte(stored.PrivateKey)
+	tampered[len(tampered)-2] ^= 1
+	stored.PrivateKey = string(tampered)
+	_, err = EnsureKeys("token", pass, cli)
+	assert.ErrorIs(t, err, ErrPrivateKey)
+	stored.PrivateKey = "not base64!"
+	_, err = EnsureKeys("token", pass, cli)
+	assert.ErrorIs(t, err, ErrPrivateKey)
+	assert.Equal(t, 1, rewrapped)
+}
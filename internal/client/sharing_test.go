@@ -0,0 +1,67 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func TestClient_GetUserKeys(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		respBody string
+		respCode int
+		want     *types.UserKeys
+		notFound bool
+	}{
+		{"ok", `{"public_key":"pub","private_key":"priv"}`, http.StatusOK, &types.UserKeys{PublicKey: "pub", PrivateKey: "priv"}, false},
+		{"notFound", "Not found", http.StatusNotFound, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/user/keys", r.URL.Path)
+				assert.Equal(t, "token", r.Header.Get(Token))
+				w.WriteHeader(tt.respCode)
+				_, _ = w.Write([]byte(tt.respBody))
+			}))
+			defer svr.Close()
+
+			c, _ := NewClient(conf)
+			c.address = svr.URL
+			got, err := c.GetUserKeys("token")
+			assert.Equal(t, tt.notFound, err != nil)
+			if tt.notFound {
+				assert.ErrorIs(t, err, ErrNotFound)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_ShareItem(t *testing.T) {
+	share := types.Share{Recipient: "bob", Permission: types.PermissionWrite, RecipientKey: "rk", OwnerKey: "ok", Data: "data"}
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/item/work/mail/share", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		var got types.Share
+		assert.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, share, got)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer svr.Close()
+
+	c, _ := NewClient(conf)
+	c.address = svr.URL
+	require.NoError(t, c.ShareItem("token", "work/mail", share))
+}
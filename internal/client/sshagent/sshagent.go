@@ -0,0 +1,173 @@
+// Package sshagent реализует режим клиента, в котором хранимые на сервере SSH-ключи
+// отдаются по протоколу ssh-agent через unix-сокет. Ключи расшифровываются и хранятся только в памяти
+package sshagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/wellywell/gophkeeper/internal/client"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// PublicKey вычисляет публичный ключ в формате authorized_keys по приватному ключу
+func PublicKey(key types.SSHKeyData) (string, error) {
+	private, err := parsePrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		return "", err
+	}
+	public := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	if key.Comment != "" {
+		public = fmt.Sprintf("%s %s", public, key.Comment)
+	}
+	return public, nil
+}
+
+// NewKeyring создаёт агент, хранящий в памяти переданные ключи
+func NewKeyring(keys []types.SSHKeyData) (agent.Agent, error) {
+	keyring := agent.NewKeyring()
+	for _, k := range keys {
+		private, err := parsePrivateKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse key %s: %w", k.Comment, err)
+		}
+		err = keyring.Add(agent.AddedKey{PrivateKey: private, Comment: k.Comment})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keyring, nil
+}
+
+// LoadKeys получает с сервера все SSH-ключи пользователя и расшифровывает их
+func LoadKeys(token string, pass string, cli *client.Client) ([]types.SSHKeyData, error) {
+	items, err := cli.AllRecords(token, pass)
+	if err != nil {
+		return nil, err
+	}
+	var keys []types.SSHKeyData
+	for _, i := range items {
+		if i.Type != types.TypeSSHKey {
+			continue
+		}
+		data, err := cli.GetItem(token, i.Key)
+		if err != nil {
+			return nil, err
+		}
+		key, err := types.ParseItem[*types.SSHKeyData](data, pass)
+		if err != nil {
+			return nil, err
+		}
+		if key.Data.Comment == "" {
+			key.Data.Comment = i.Key
+		}
+		keys = append(keys, *key.Data)
+	}
+	return keys, nil
+}
+
+// socketName имя сокета в каталоге, созданном Listen
+const socketName = "agent.sock"
+
+// Listener unix-сокет агента. Close закрывает его и удаляет вместе с каталогом, созданным для него
+type Listener struct {
+	net.Listener
+	// Path путь к сокету, его нужно передать в SSH_AUTH_SOCK
+	Path string
+	dir  string
+}
+
+// Listen создаёт unix-сокет агента. Сокет создаётся в новом каталоге с правами 0700 (os.MkdirTemp), поэтому
+// до chmod 0600 к нему никто другой не может подключиться. Если socketPath пустой, сокет остаётся в этом каталоге
+// во временной директории, иначе готовый сокет переносится в socketPath. Старый сокет по этому пути заменяется,
+// другой файл - нет
+func Listen(socketPath string) (*Listener, error) {
+	parent := ""
+	if socketPath != "" {
+		info, err := os.Lstat(socketPath)
+		if err == nil && info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", socketPath)
+		}
+		parent = filepath.Dir(socketPath)
+	}
+
+	dir, err := os.MkdirTemp(parent, "gophkeeper-agent-")
+	if err != nil {
+		return nil, err
+	}
+	l := &Listener{Path: filepath.Join(dir, socketName), dir: dir}
+	l.Listener, err = net.Listen("unix", l.Path)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	err = os.Chmod(l.Path, 0600)
+	if err == nil && socketPath != "" {
+		// сокет уже не удалить по старому пути, это делает Close
+		l.Listener.(*net.UnixListener).SetUnlinkOnClose(false)
+		err = os.Rename(l.Path, socketPath)
+		if err == nil {
+			l.Path = socketPath
+			err = os.Remove(dir)
+			l.dir = ""
+		}
+	}
+	if err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Close закрывает сокет и удаляет его
+func (l *Listener) Close() error {
+	err := l.Listener.Close()
+	_ = os.Remove(l.Path)
+	if l.dir != "" {
+		_ = os.RemoveAll(l.dir)
+	}
+	return err
+}
+
+// Serve обслуживает подключения к listener по протоколу ssh-agent до отмены контекста и закрывает listener
+func Serve(ctx context.Context, listener net.Listener, keyring agent.Agent) error {
+	defer listener.Close()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			_ = agent.ServeAgent(keyring, conn)
+		}()
+	}
+}
+
+func parsePrivateKey(key types.SSHKeyData) (interface{}, error) {
+	if key.Passphrase != "" {
+		return ssh.ParseRawPrivateKeyWithPassphrase([]byte(key.PrivateKey), []byte(key.Passphrase))
+	}
+	return ssh.ParseRawPrivateKey([]byte(key.PrivateKey))
+}
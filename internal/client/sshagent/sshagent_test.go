@@ -0,0 +1,153 @@
+package sshagent
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func generateKey(t *testing.T, passphrase string) (types.SSHKeyData, ssh.PublicKey) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	var block *pem.Block
+	if passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(private, "", []byte(passphrase))
+	} else {
+		block, err = ssh.MarshalPrivateKey(private, "")
+	}
+	require.NoError(t, err)
+
+	sshPublic, err := ssh.NewPublicKey(public)
+	require.NoError(t, err)
+
+	return types.SSHKeyData{PrivateKey: string(pem.EncodeToMemory(block)), Passphrase: passphrase, Comment: "test@host"}, sshPublic
+}
+
+func TestPublicKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+	}{
+		{"plain", ""},
+		{"with passphrase", "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, public := generateKey(t, tt.passphrase)
+			got, err := PublicKey(key)
+			assert.NoError(t, err)
+
+			parsed, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(got))
+			assert.NoError(t, err)
+			assert.Equal(t, public.Marshal(), parsed.Marshal())
+			assert.Equal(t, "test@host", comment)
+		})
+	}
+
+	key, _ := generateKey(t, "secret")
+	key.Passphrase = "wrong"
+	_, err := PublicKey(key)
+	assert.Error(t, err)
+}
+
+func TestServe(t *testing.T) {
+	key, public := generateKey(t, "secret")
+
+	keyring, err := NewKeyring([]types.SSHKeyData{key})
+	require.NoError(t, err)
+
+	listener, err := Listen("")
+	require.NoError(t, err)
+	socket := listener.Path
+	assertPrivate(t, socket, filepath.Dir(socket))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() {
+		done <- Serve(ctx, listener, keyring)
+	}()
+
+	var conn net.Conn
+	assert.Eventually(t, func() bool {
+		conn, err = net.Dial("unix", socket)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	defer conn.Close()
+
+	agentClient := agent.NewClient(conn)
+
+	keys, err := agentClient.List()
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, public.Marshal(), keys[0].Marshal())
+	assert.Equal(t, "test@host", keys[0].Comment)
+
+	signature, err := agentClient.Sign(public, []byte("data"))
+	assert.NoError(t, err)
+	assert.NoError(t, public.Verify([]byte("data"), signature))
+
+	cancel()
+	assert.NoError(t, <-done)
+	// сокет удаляется вместе со своим каталогом
+	assert.NoDirExists(t, filepath.Dir(socket))
+}
+
+func TestListenPath(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "agent.sock")
+
+	// сокет, оставшийся от предыдущего запуска, заменяется
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	listener, err := Listen(socket)
+	require.NoError(t, err)
+	assert.Equal(t, socket, listener.Path)
+	assertPrivate(t, socket, "")
+	// временный каталог удалён сразу после переноса сокета
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	conn, err := net.Dial("unix", socket)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	require.NoError(t, listener.Close())
+	assert.NoFileExists(t, socket)
+
+	// обычный файл на месте сокета не трогается
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, []byte("data"), 0600))
+	_, err = Listen(file)
+	assert.Error(t, err)
+	assert.FileExists(t, file)
+}
+
+// assertPrivate проверяет права сокета и, если задан, его каталога
+func assertPrivate(t *testing.T, socket string, dir string) {
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSocket)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	if dir != "" {
+		info, err = os.Stat(dir)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	}
+}
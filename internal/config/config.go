@@ -5,6 +5,8 @@ import (
 	"crypto/rand"
 	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/caarlos0/env/v6"
 )
@@ -13,25 +15,42 @@ import (
 адрес и порт запуска сервера: переменная окружения ОС RUN_ADDRESS или флаг -a;
 адрес подключения к базе данных: переменная окружения ОС DATABASE_URI или флаг -d;
 путь к серверному сертификату и ключу SSL_CERT_PATH и SSL_KEY_PATH или флаги -с -k
+токен администратора ADMIN_TOKEN или флаг -admin-token, без него API администратора выключено
+режим регистрации REGISTRATION или флаг -registration: open, invite (по приглашениям) или closed
+требования к паролю PASSWORD_MIN_LENGTH и PASSWORD_MIN_SCORE (оценка zxcvbn 0-4) или флаги -password-min-length -password-min-score
 
 для запуска клиента:
 адрес сервера env SERVER_ADDRESS или флаг -s
 путь к файлу ключа сертификата CA_KEY или флаг -ssl
+путь к unix-сокету для режима ssh-agent SSH_AGENT_SOCKET или флаг -agent-socket
+логин и пароль для неинтерактивного запуска (например, из cron) GOPHKEEPER_LOGIN и GOPHKEEPER_PASSWORD
+путь к локальному файлу хешей утёкших паролей (формат HIBP) BREACH_FILE или флаг -breach-file
+пароль от файлов импорта и экспорта (например, базы KeePass) GOPHKEEPER_EXPORT_PASSWORD
 */
 
 // ServerConfig структура с параметрами для сервера
 type ServerConfig struct {
-	RunAddress  string `env:"RUN_ADDRESS"`
-	DatabaseDSN string `env:"DATABASE_URI"`
-	Secret      []byte
-	SSLCert     string `env:"SSL_CERT_PATH"`
-	SSLKey      string `env:"SSL_KEY_PATH"`
+	RunAddress        string `env:"RUN_ADDRESS"`
+	DatabaseDSN       string `env:"DATABASE_URI"`
+	Secret            []byte
+	SSLCert           string `env:"SSL_CERT_PATH"`
+	SSLKey            string `env:"SSL_KEY_PATH"`
+	AdminToken        string `env:"ADMIN_TOKEN"`
+	Registration      string `env:"REGISTRATION"`
+	PasswordMinLength int    `env:"PASSWORD_MIN_LENGTH"`
+	PasswordMinScore  int    `env:"PASSWORD_MIN_SCORE"`
 }
 
 // ClientConfig структура с параметрами для клиента
 type ClientConfig struct {
-	ServerAddress string `env:"SERVER_ADDRESS"`
-	SSLKey        string `env:"CA_KEY"`
+	ServerAddress  string `env:"SERVER_ADDRESS"`
+	SSLKey         string `env:"CA_KEY"`
+	SSHAgentSocket string `env:"SSH_AGENT_SOCKET"`
+	Login          string `env:"GOPHKEEPER_LOGIN"`
+	Password       string `env:"GOPHKEEPER_PASSWORD"`
+	BreachFile     string `env:"BREACH_FILE"`
+	ExportPassword string `env:"GOPHKEEPER_EXPORT_PASSWORD"`
+	DeviceKey      string `env:"GOPHKEEPER_DEVICE_KEY"`
 }
 
 // NewServerConfig конструктор для создания конфига сервера
@@ -48,6 +67,10 @@ func NewServerConfig() (*ServerConfig, error) {
 	flag.StringVar(&commandLineParams.DatabaseDSN, "d", "postgres://postgres@localhost:5432/postgres?sslmode=disable", "Database DSN")
 	flag.StringVar(&commandLineParams.SSLCert, "c", "../../.ssl/server.crt", "Path to certificate")
 	flag.StringVar(&commandLineParams.SSLKey, "k", "../../.ssl/server.key", "Path to certificate key")
+	flag.StringVar(&commandLineParams.AdminToken, "admin-token", "", "Token for the admin API, empty disables it")
+	flag.StringVar(&commandLineParams.Registration, "registration", "open", "Who can register: open, invite or closed")
+	flag.IntVar(&commandLineParams.PasswordMinLength, "password-min-length", 10, "Minimum password length")
+	flag.IntVar(&commandLineParams.PasswordMinScore, "password-min-score", 2, "Minimum password strength from 0 to 4")
 	flag.Parse()
 
 	if params.RunAddress == "" {
@@ -62,6 +85,24 @@ func NewServerConfig() (*ServerConfig, error) {
 	if params.SSLKey == "" {
 		params.SSLKey = commandLineParams.SSLKey
 	}
+	if params.AdminToken == "" {
+		params.AdminToken = commandLineParams.AdminToken
+	}
+	if params.Registration == "" {
+		params.Registration = commandLineParams.Registration
+	}
+	// 0 - допустимое значение (например, PASSWORD_MIN_SCORE=0 отключает оценку), поэтому смотрим, задана ли переменная
+	if _, ok := os.LookupEnv("PASSWORD_MIN_LENGTH"); !ok {
+		params.PasswordMinLength = commandLineParams.PasswordMinLength
+	}
+	if _, ok := os.LookupEnv("PASSWORD_MIN_SCORE"); !ok {
+		params.PasswordMinScore = commandLineParams.PasswordMinScore
+	}
+	switch params.Registration {
+	case "open", "invite", "closed":
+	default:
+		return nil, fmt.Errorf("unknown registration mode %q, use open, invite or closed", params.Registration)
+	}
 
 	secret := make([]byte, 10)
 	_, err = rand.Read(secret)
@@ -86,6 +127,9 @@ func NewClientConfig() (*ClientConfig, error) {
 
 	flag.StringVar(&commandLineParams.ServerAddress, "s", "https://localhost:8080", "Server address")
 	flag.StringVar(&commandLineParams.SSLKey, "ssl", "../../.ssl/ca.key", "Path to certificate key")
+	flag.StringVar(&commandLineParams.SSHAgentSocket, "agent-socket", "", "Path to unix socket for ssh-agent mode (default: new private temp directory)")
+	flag.StringVar(&commandLineParams.BreachFile, "breach-file", "", "Path to sorted SHA-1 or NTLM hash file in Have I Been Pwned format")
+	flag.StringVar(&commandLineParams.DeviceKey, "device-key", defaultDeviceKey(), "Path to the key identifying this device, created on first run")
 	flag.Parse()
 
 	if params.ServerAddress == "" {
@@ -94,5 +138,23 @@ func NewClientConfig() (*ClientConfig, error) {
 	if params.SSLKey == "" {
 		params.SSLKey = commandLineParams.SSLKey
 	}
+	if params.SSHAgentSocket == "" {
+		params.SSHAgentSocket = commandLineParams.SSHAgentSocket
+	}
+	if params.BreachFile == "" {
+		params.BreachFile = commandLineParams.BreachFile
+	}
+	if params.DeviceKey == "" {
+		params.DeviceKey = commandLineParams.DeviceKey
+	}
 	return &params, nil
 }
+
+// defaultDeviceKey ключ устройства хранится в каталоге настроек пользователя
+func defaultDeviceKey() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "gophkeeper-device.key"
+	}
+	return filepath.Join(dir, "gophkeeper", "device.key")
+}
@@ -8,6 +8,8 @@ import (
 )
 
 func TestNewServerConfig(t *testing.T) {
+	// явный 0 не заменяется значением по умолчанию
+	t.Setenv("PASSWORD_MIN_SCORE", "0")
 	got, err := NewServerConfig()
 	assert.NoError(t, err)
 
@@ -15,6 +17,8 @@ func TestNewServerConfig(t *testing.T) {
 	assert.Equal(t, "postgres://postgres@localhost:5432/postgres?sslmode=disable", got.DatabaseDSN)
 	assert.Equal(t, "../../.ssl/server.key", got.SSLKey)
 	assert.Equal(t, "../../.ssl/server.crt", got.SSLCert)
+	assert.Equal(t, 10, got.PasswordMinLength)
+	assert.Equal(t, 0, got.PasswordMinScore)
 
 }
 
@@ -25,4 +29,6 @@ func TestNewClientConfig(t *testing.T) {
 
 	assert.Equal(t, "https://localhost:8080", got.ServerAddress)
 	assert.Equal(t, "../../.ssl/ca.key", got.SSLKey)
+	// пустой путь - сокет создаётся в новом закрытом каталоге
+	assert.Equal(t, "", got.SSHAgentSocket)
 }
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ScheduleUserDeletion планирует удаление пользователя на deleteAt и возвращает время удаления.
+// Повторный запрос не переносит уже запланированное удаление. Единственный владелец организации
+// с другими участниками сначала должен передать её - OrgOwnerError
+func (d *Database) ScheduleUserDeletion(ctx context.Context, userID int, deleteAt time.Time) (time.Time, error) {
+	var org string
+	query := `
+		SELECT o.name FROM organization o JOIN org_member m ON m.org_id = o.id
+		WHERE m.user_id = $1 AND m.role = 'owner'
+		AND NOT EXISTS (SELECT 1 FROM org_member x WHERE x.org_id = o.id AND x.role = 'owner' AND x.user_id <> $1)
+		AND EXISTS (SELECT 1 FROM org_member x WHERE x.org_id = o.id AND x.user_id <> $1)
+		ORDER BY o.name LIMIT 1
+	`
+	err := d.pool.QueryRow(ctx, query, userID).Scan(&org)
+	if err == nil {
+		return time.Time{}, &OrgOwnerError{Org: org}
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("%w", err)
+	}
+
+	err = d.pool.QueryRow(ctx, `UPDATE auth_user SET delete_at = COALESCE(delete_at, $2) WHERE id = $1 RETURNING delete_at`,
+		userID, deleteAt).Scan(&deleteAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, &UserNotFoundError{Username: strconv.Itoa(userID)}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w", err)
+	}
+	return deleteAt, nil
+}
+
+// GetUserDeletion время запланированного удаления пользователя, nil - удаление не запланировано
+func (d *Database) GetUserDeletion(ctx context.Context, userID int) (*time.Time, error) {
+	var deleteAt *time.Time
+	err := d.pool.QueryRow(ctx, `SELECT delete_at FROM auth_user WHERE id = $1`, userID).Scan(&deleteAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	return deleteAt, nil
+}
+
+// CancelUserDeletion отменяет запланированное удаление. Если удаление не запланировано - KeyNotFoundError
+func (d *Database) CancelUserDeletion(ctx context.Context, userID int) error {
+	tag, err := d.pool.Exec(ctx, `UPDATE auth_user SET delete_at = NULL WHERE id = $1 AND delete_at IS NOT NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: "deletion"}
+	}
+	return nil
+}
+
+// DueUserDeletions пользователи, время удаления которых наступило
+func (d *Database) DueUserDeletions(ctx context.Context) ([]int, error) {
+	rows, err := d.pool.Query(ctx, `SELECT id FROM auth_user WHERE delete_at <= now() ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return ids, nil
+}
+
+// DeleteUser удаляет пользователя и каскадом все его данные: записи с вложениями, ключи, выданные ему и им
+// общие записи, одноразовые ссылки, экстренный доступ, сессии и устройства. Организации, где он был
+// единственным участником, удаляются, а в организациях, оставшихся без владельца, владельцем становится
+// старейший участник. Персональные данные пользователя в журнале аудита стираются (см. redactAuditEvents)
+func (d *Database) DeleteUser(ctx context.Context, userID int) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var username string
+	err = tx.QueryRow(ctx, `SELECT username FROM auth_user WHERE id = $1`, userID).Scan(&username)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &UserNotFoundError{Username: strconv.Itoa(userID)}
+	}
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	err = redactAuditEvents(ctx, tx, userID, username)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		DELETE FROM organization o
+		WHERE o.id IN (SELECT org_id FROM org_member WHERE user_id = $1)
+		AND NOT EXISTS (SELECT 1 FROM org_member m WHERE m.org_id = o.id AND m.user_id <> $1)
+	`
+	_, err = tx.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	query = `
+		UPDATE org_member SET role = 'owner' WHERE id IN (
+			SELECT DISTINCT ON (m.org_id) m.id FROM org_member m
+			WHERE m.user_id <> $1
+			AND m.org_id IN (SELECT org_id FROM org_member WHERE user_id = $1 AND role = 'owner')
+			AND NOT EXISTS (SELECT 1 FROM org_member x WHERE x.org_id = m.org_id AND x.role = 'owner' AND x.user_id <> $1)
+			ORDER BY m.org_id, m.accepted DESC, m.role, m.created_at
+		)
+	`
+	_, err = tx.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM auth_user WHERE id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &UserNotFoundError{Username: strconv.Itoa(userID)}
+	}
+	return tx.Commit(ctx)
+}
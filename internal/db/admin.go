@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// storageQuery объём зашифрованных данных пользователя u: файлы, вложения и тексты
+const storageQuery = `
+	(SELECT COALESCE(sum(octet_length(b.data)), 0) FROM binary_data b JOIN item i ON i.id = b.item_id WHERE i.user_id = u.id)
+	+ (SELECT COALESCE(sum(octet_length(a.data)), 0) FROM attachment a JOIN item i ON i.id = a.item_id WHERE i.user_id = u.id)
+	+ (SELECT COALESCE(sum(octet_length(t.data)), 0) FROM text_data t JOIN item i ON i.id = t.item_id WHERE i.user_id = u.id)
+`
+
+// AdminListUsers все учётные записи с числом записей, объёмом данных и активными сессиями
+func (d *Database) AdminListUsers(ctx context.Context) ([]types.AdminUser, error) {
+	query := `
+		SELECT u.id, u.username, u.disabled, u.delete_at,
+			(SELECT count(*) FROM item i WHERE i.user_id = u.id) AS items,
+			` + storageQuery + ` AS storage_bytes,
+			(SELECT count(*) FROM user_session s WHERE s.user_id = u.id AND s.last_seen > now() - $1::interval) AS sessions,
+			(SELECT max(s.last_seen) FROM user_session s WHERE s.user_id = u.id) AS last_seen
+		FROM auth_user u ORDER BY u.username
+	`
+	rows, err := d.pool.Query(ctx, query, types.SessionIdleTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.AdminUser])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return users, nil
+}
+
+// AdminStats сводка по серверу
+func (d *Database) AdminStats(ctx context.Context) (types.AdminStats, error) {
+	query := `
+		SELECT
+			(SELECT count(*) FROM auth_user) AS users,
+			(SELECT count(*) FROM auth_user WHERE disabled) AS disabled_users,
+			(SELECT count(*) FROM auth_user WHERE delete_at IS NOT NULL) AS pending_deletions,
+			(SELECT count(*) FROM item) AS items,
+			(SELECT COALESCE(sum(octet_length(data)), 0) FROM binary_data)
+			+ (SELECT COALESCE(sum(octet_length(data)), 0) FROM attachment)
+			+ (SELECT COALESCE(sum(octet_length(data)), 0) FROM text_data) AS storage_bytes,
+			(SELECT count(*) FROM user_session WHERE last_seen > now() - $1::interval) AS sessions,
+			(SELECT count(*) FROM device WHERE status = 'approved') AS devices,
+			(SELECT count(*) FROM item_share) AS shares,
+			(SELECT count(*) FROM organization) AS organizations,
+			(SELECT count(*) FROM send) AS sends
+	`
+	rows, err := d.pool.Query(ctx, query, types.SessionIdleTimeout)
+	if err != nil {
+		return types.AdminStats{}, fmt.Errorf("%w", err)
+	}
+	stats, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.AdminStats])
+	if err != nil {
+		return types.AdminStats{}, fmt.Errorf("%w", err)
+	}
+	return stats, nil
+}
+
+// SetUserDisabled отключает или включает учётную запись. Отключённый пользователь не может войти,
+// его сессии завершаются сразу
+func (d *Database) SetUserDisabled(ctx context.Context, userID int, disabled bool) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(ctx, `UPDATE auth_user SET disabled = $2 WHERE id = $1`, userID, disabled)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &UserNotFoundError{Username: strconv.Itoa(userID)}
+	}
+	if disabled {
+		_, err = tx.Exec(ctx, `DELETE FROM user_session WHERE user_id = $1`, userID)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteUserSessions завершает все сессии пользователя и возвращает их число
+func (d *Database) DeleteUserSessions(ctx context.Context, userID int) (int64, error) {
+	tag, err := d.pool.Exec(ctx, `DELETE FROM user_session WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ResetUserDevices забывает все устройства пользователя вместе с их сессиями и возвращает их число.
+// Следующее устройство подтверждается автоматически, только если предъявит код администратора
+// с хешем enrollmentHash до expiresAt
+func (d *Database) ResetUserDevices(ctx context.Context, userID int, enrollmentHash string, expiresAt time.Time) (int64, error) {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = tx.Exec(ctx, `UPDATE auth_user SET device_enrollment_hash = $2, device_enrollment_expires = $3 WHERE id = $1`,
+		userID, enrollmentHash, expiresAt)
+	if err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM device WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+	return tag.RowsAffected(), tx.Commit(ctx)
+}
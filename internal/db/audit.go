@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// auditLock ключ advisory-блокировки, под которой события журнала аудита получают id и хеш предыдущего
+const auditLock = 0x617564697400
+
+// auditColumns колонки для выборки событий журнала аудита в types.AuditEvent
+const auditColumns = `id, user_id, actor, action, item_key, remote_addr, created_at, prev_hash, hash, salts, redacted`
+
+// AuditChainStatus итог проверки журнала аудита: число проверенных событий, из них стёртых событий,
+// хеш которых пересчитать нельзя (см. types.AuditEvent.Verifiable), и хеш последнего события
+type AuditChainStatus struct {
+	Checked    int
+	Unverified int
+	Head       string
+}
+
+// AppendAuditEvent дописывает событие в журнал аудита, сцепляя его с последним событием.
+// Id, время и хеши заполняются здесь
+func (d *Database) AppendAuditEvent(ctx context.Context, event types.AuditEvent) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// без блокировки два события могли бы сослаться на один и тот же предыдущий хеш
+	_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, auditLock)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	err = tx.QueryRow(ctx, `SELECT hash FROM audit_event ORDER BY id DESC LIMIT 1`).Scan(&event.PrevHash)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w", err)
+	}
+	err = tx.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('audit_event', 'id'))`).Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	event.Salts, err = types.NewAuditSalts()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	event.Hash = event.Digest()
+
+	query := `
+		INSERT INTO audit_event (id, user_id, actor, action, item_key, remote_addr, created_at, prev_hash, hash, salts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err = tx.Exec(ctx, query, event.ID, event.UserID, event.Actor, event.Action, event.Key, event.RemoteAddr,
+		event.CreatedAt, event.PrevHash, event.Hash, event.Salts)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// ListAuditEvents страница событий журнала аудита, относящихся к пользователю, от новых к старым
+func (d *Database) ListAuditEvents(ctx context.Context, userID int, limit int, offset int) ([]types.AuditEvent, error) {
+	query := `
+		SELECT ` + auditColumns + `
+		FROM audit_event WHERE user_id = $1
+		ORDER BY id DESC LIMIT $2 OFFSET $3
+	`
+	rows, err := d.pool.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.AuditEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return events, nil
+}
+
+// VerifyAuditChain проходит журнал аудита от первого события и пересчитывает хеши. При расхождении возвращает
+// AuditChainError с первым событием, на котором цепочка нарушена, и итог до него. Удаление событий с конца
+// журнала цепочкой не обнаруживается, для этого хеш последнего события нужно сверять с сохранённым ранее
+func (d *Database) VerifyAuditChain(ctx context.Context) (AuditChainStatus, error) {
+	query := `
+		SELECT ` + auditColumns + `
+		FROM audit_event ORDER BY id
+	`
+	var status AuditChainStatus
+	rows, err := d.pool.Query(ctx, query)
+	if err != nil {
+		return status, fmt.Errorf("%w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event, err := pgx.RowToStructByName[types.AuditEvent](rows)
+		if err != nil {
+			return status, fmt.Errorf("failed unpacking rows %w", err)
+		}
+		if event.PrevHash != status.Head {
+			return status, &AuditChainError{ID: event.ID, Reason: "previous event hash mismatch"}
+		}
+		if !event.Verifiable() {
+			status.Unverified++
+		} else if event.Digest() != event.Hash {
+			return status, &AuditChainError{ID: event.ID, Reason: "event hash mismatch"}
+		}
+		status.Head = event.Hash
+		status.Checked++
+	}
+	return status, rows.Err()
+}
+
+// redactAuditEvents стирает из журнала аудита персональные данные пользователя username с id userID:
+// в событиях его учётной записи - все персональные поля, в событиях чужих хранилищ, где он действовал
+// (экстренный доступ) - имя и адрес. Хеши событий не меняются, цепочка остаётся проверяемой
+func redactAuditEvents(ctx context.Context, tx pgx.Tx, userID int, username string) error {
+	query := `
+		SELECT ` + auditColumns + `
+		FROM audit_event WHERE user_id = $1 OR actor = $2
+		ORDER BY id FOR UPDATE
+	`
+	rows, err := tx.Query(ctx, query, userID, username)
+	if err != nil {
+		return fmt.Errorf("failed collecting rows %w", err)
+	}
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.AuditEvent])
+	if err != nil {
+		return fmt.Errorf("failed unpacking rows %w", err)
+	}
+
+	query = `
+		UPDATE audit_event
+		SET user_id = $2, actor = $3, item_key = $4, remote_addr = $5, salts = $6, redacted = $7, redacted_at = now()
+		WHERE id = $1
+	`
+	for _, e := range events {
+		if e.UserID != nil && *e.UserID == userID {
+			e.Redact(types.AuditFieldUserID, types.AuditFieldActor, types.AuditFieldKey, types.AuditFieldRemoteAddr)
+		} else {
+			e.Redact(types.AuditFieldActor, types.AuditFieldRemoteAddr)
+		}
+		_, err = tx.Exec(ctx, query, e.ID, e.UserID, e.Actor, e.Key, e.RemoteAddr, e.Salts, e.Redacted)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+	return nil
+}
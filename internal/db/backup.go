@@ -0,0 +1,512 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Формат резервной копии: поток gzip, внутри строка-сигнатура, строка с манифестом в JSON и секции таблиц.
+// Секция начинается строкой "\table <имя>" и содержит строки COPY в текстовом формате, заканчиваясь строкой "\.".
+// В текстовом формате COPY переводы строк внутри значений экранируются, поэтому граница секции однозначна
+const (
+	backupMagic   = "GKSERVERBACKUP"
+	backupVersion = 1
+	sectionPrefix = `\table `
+	sectionEnd    = `\.`
+)
+
+// liveItemsSection секция инкрементальной копии со списком id всех записей на момент снимка,
+// по ней при восстановлении удаляются записи, удалённые после предыдущей копии
+const liveItemsSection = "item:live"
+
+// IncrementalOverlap насколько раньше момента предыдущей копии начинается инкрементальная.
+// Запись, изменённая в транзакции, которая началась до снимка, а завершилась после, получает updated_at
+// раньше времени снимка и не видна в нём; перекрытие позволяет подобрать такие записи следующей копией
+const IncrementalOverlap = 10 * time.Minute
+
+// backupTable таблица в резервной копии
+type backupTable struct {
+	name string
+	// filter условие отбора строк для инкрементальной копии, %s - время, с которого отбираются изменения
+	filter string
+	// replace в инкрементальной копии таблица выгружается целиком и при восстановлении заменяется
+	replace bool
+	// appendOnly строки таблицы не меняются и не удаляются, при восстановлении уже имеющиеся пропускаются
+	appendOnly bool
+	// redacted в строках appendOnly таблицы могут стираться персональные данные: такие строки попадают
+	// в инкрементальную копию по redacted_at и при восстановлении заменяют уже имеющиеся
+	redacted bool
+}
+
+// itemFilter отбирает строки данных, принадлежащих изменённым записям
+const itemFilter = "item_id IN (SELECT id FROM item WHERE updated_at > %s)"
+
+// backupTables таблицы в порядке восстановления: родительские раньше зависимых.
+// Новую таблицу нужно добавить сюда, иначе Backup откажется работать
+var backupTables = []backupTable{
+	{name: "auth_user"},
+	{name: "item", filter: "updated_at > %s"},
+	{name: "credit_card", filter: itemFilter},
+	{name: "text_data", filter: itemFilter},
+	{name: "binary_data", filter: itemFilter},
+	{name: "logopass", filter: itemFilter},
+	{name: "ssh_key", filter: itemFilter},
+	{name: "totp", filter: itemFilter},
+	{name: "attachment", filter: itemFilter},
+	{name: "user_key", replace: true},
+	{name: "item_share", replace: true},
+	{name: "organization", replace: true},
+	{name: "org_member", replace: true},
+	{name: "org_member_collection", replace: true},
+	{name: "org_item", replace: true},
+	{name: "send", replace: true},
+	{name: "emergency_access", replace: true},
+	{name: "vault_key", replace: true},
+	{name: "device", replace: true},
+	{name: "user_session", replace: true},
+	{name: "invite", replace: true},
+	{name: "audit_event", filter: "created_at > %[1]s OR redacted_at > %[1]s", appendOnly: true, redacted: true},
+}
+
+var (
+	// ErrNotBackup файл не является резервной копией сервера
+	ErrNotBackup = errors.New("not a gophkeeper server backup")
+	// ErrSchemaVersion версия схемы резервной копии не подходит для восстановления
+	ErrSchemaVersion = errors.New("backup schema version does not match database")
+	// ErrNotEmpty полная копия восстанавливается только в пустую БД
+	ErrNotEmpty = errors.New("database is not empty, full backup can be restored only into an empty database")
+	// ErrBackupChain инкрементальная копия не продолжает уже восстановленные
+	ErrBackupChain = errors.New("incremental backup does not follow the previous one")
+)
+
+// BackupManifest описание резервной копии
+type BackupManifest struct {
+	Version       int           `json:"version"`
+	SchemaVersion uint          `json:"schema_version"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Since         *time.Time    `json:"since,omitempty"`
+	Tables        []BackupTable `json:"tables"`
+}
+
+// BackupTable таблица в резервной копии и её колонки в порядке строк COPY
+type BackupTable struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+}
+
+// Incremental копия содержит только изменения после Since
+func (m *BackupManifest) Incremental() bool {
+	return m.Since != nil
+}
+
+// Backup записывает согласованный снимок всех таблиц в w. Снимок делается в транзакции REPEATABLE READ
+// только для чтения, поэтому сервер продолжает работать. Если since не nil, копия инкрементальная:
+// в неё попадают все пользователи и только записи, изменённые после since
+func (d *Database) Backup(ctx context.Context, w io.Writer, since *time.Time) (*BackupManifest, error) {
+	tx, err := d.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	manifest := BackupManifest{Version: backupVersion}
+	if since != nil {
+		from := since.UTC()
+		manifest.Since = &from
+	}
+
+	var dirty bool
+	err = tx.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations").Scan(&manifest.SchemaVersion, &dirty)
+	if err != nil {
+		return nil, fmt.Errorf("could not read schema version %w", err)
+	}
+	if dirty {
+		return nil, fmt.Errorf("%w: migration %d is dirty", ErrSchemaVersion, manifest.SchemaVersion)
+	}
+	// now() в транзакции - время начала снимка
+	err = tx.QueryRow(ctx, "SELECT now()").Scan(&manifest.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	manifest.CreatedAt = manifest.CreatedAt.UTC()
+
+	columns, err := tableColumns(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range backupTables {
+		cols, ok := columns[t.name]
+		if !ok {
+			return nil, fmt.Errorf("table %s not found", t.name)
+		}
+		delete(columns, t.name)
+		manifest.Tables = append(manifest.Tables, BackupTable{Name: t.name, Columns: cols})
+	}
+	delete(columns, "schema_migrations")
+	if len(columns) > 0 {
+		unknown := make([]string, 0, len(columns))
+		for name := range columns {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("tables %s are not covered by backup", strings.Join(unknown, ", "))
+	}
+
+	zw := gzip.NewWriter(w)
+	header, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	_, err = fmt.Fprintf(zw, "%s %d\n%s\n", backupMagic, backupVersion, header)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, t := range backupTables {
+		query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoteAll(manifest.Tables[i].Columns), ", "), pgx.Identifier{t.name}.Sanitize())
+		if since != nil && t.filter != "" {
+			query += " WHERE " + fmt.Sprintf(t.filter, timestampLiteral(*manifest.Since))
+		}
+		// удалённые записи убираются до вставки изменённых: ключ удалённой записи мог быть занят заново
+		if since != nil && t.name == "item" {
+			err = copySection(ctx, tx, zw, liveItemsSection, "SELECT id FROM item")
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = copySection(ctx, tx, zw, t.name, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return &manifest, tx.Commit(ctx)
+}
+
+func copySection(ctx context.Context, tx pgx.Tx, w io.Writer, name string, query string) error {
+	_, err := fmt.Fprintf(w, "%s%s\n", sectionPrefix, name)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Conn().PgConn().CopyTo(ctx, w, fmt.Sprintf("COPY (%s) TO STDOUT", query))
+	if err != nil {
+		return fmt.Errorf("could not copy %s %w", name, err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", sectionEnd)
+	return err
+}
+
+// tableColumns колонки всех таблиц схемы public
+func tableColumns(ctx context.Context, tx pgx.Tx) (map[string][]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.table_name, c.column_name
+		FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = 'public' AND t.table_type = 'BASE TABLE'
+		ORDER BY c.table_name, c.ordinal_position
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		err = rows.Scan(&table, &column)
+		if err != nil {
+			return nil, err
+		}
+		result[table] = append(result[table], column)
+	}
+	return result, rows.Err()
+}
+
+func quoteAll(names []string) []string {
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = pgx.Identifier{name}.Sanitize()
+	}
+	return result
+}
+
+func timestampLiteral(t time.Time) string {
+	return "'" + t.UTC().Format(time.RFC3339Nano) + "'::timestamptz"
+}
+
+// BackupReader читает резервную копию, созданную Backup
+type BackupReader struct {
+	Manifest BackupManifest
+	zr       *gzip.Reader
+	r        *bufio.Reader
+}
+
+// NewBackupReader проверяет сигнатуру и читает манифест резервной копии
+func NewBackupReader(r io.Reader) (*BackupReader, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, ErrNotBackup
+	}
+	br := &BackupReader{zr: zr, r: bufio.NewReader(zr)}
+	line, err := br.readLine()
+	if err != nil || !strings.HasPrefix(line, backupMagic+" ") {
+		return nil, ErrNotBackup
+	}
+	if line != fmt.Sprintf("%s %d", backupMagic, backupVersion) {
+		return nil, fmt.Errorf("unsupported backup format %s", strings.TrimPrefix(line, backupMagic+" "))
+	}
+	line, err = br.readLine()
+	if err != nil {
+		return nil, ErrNotBackup
+	}
+	err = json.Unmarshal([]byte(line), &br.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotBackup, err)
+	}
+	return br, nil
+}
+
+func (b *BackupReader) readLine() (string, error) {
+	line, err := b.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// next читает заголовок следующей секции. Возвращает io.EOF после последней
+func (b *BackupReader) next() (string, *sectionReader, error) {
+	line, err := b.readLine()
+	if err != nil {
+		return "", nil, err
+	}
+	name, ok := strings.CutPrefix(line, sectionPrefix)
+	if !ok {
+		return "", nil, fmt.Errorf("%w: unexpected line %q", ErrNotBackup, line)
+	}
+	return name, &sectionReader{r: b.r}, nil
+}
+
+// Close освобождает ресурсы распаковки
+func (b *BackupReader) Close() error {
+	return b.zr.Close()
+}
+
+// sectionReader отдаёт строки COPY до конца секции
+type sectionReader struct {
+	r    *bufio.Reader
+	buf  []byte
+	done bool
+}
+
+func (s *sectionReader) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		line, err := s.r.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0, fmt.Errorf("%w: truncated", ErrNotBackup)
+			}
+			return 0, err
+		}
+		if bytes.Equal(line, []byte(sectionEnd+"\n")) {
+			s.done = true
+			continue
+		}
+		s.buf = line
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// drain дочитывает секцию до конца
+func (s *sectionReader) drain() error {
+	_, err := io.Copy(io.Discard, s)
+	return err
+}
+
+// Restore восстанавливает резервную копию в одной транзакции. Полная копия восстанавливается только в пустую БД
+// с той же версией схемы. Инкрементальная применяется поверх восстановленной ранее копии: изменённые записи
+// заменяются, удалённые удаляются, пользователи обновляются, удалённые пользователи удаляются. restoredAt - время снимка последней
+// восстановленной копии, инкрементальная копия должна начинаться не позже него
+func (d *Database) Restore(ctx context.Context, b *BackupReader, restoredAt *time.Time) error {
+	m := b.Manifest
+	if m.Incremental() && (restoredAt == nil || m.Since.After(*restoredAt)) {
+		return ErrBackupChain
+	}
+
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var version uint
+	var dirty bool
+	err = tx.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty)
+	if err != nil {
+		return fmt.Errorf("could not read schema version %w", err)
+	}
+	if dirty || version != m.SchemaVersion {
+		return fmt.Errorf("%w: backup %d, database %d", ErrSchemaVersion, m.SchemaVersion, version)
+	}
+
+	if !m.Incremental() {
+		var exists bool
+		err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM auth_user)").Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrNotEmpty
+		}
+	}
+
+	columns := make(map[string][]string, len(m.Tables))
+	for _, t := range m.Tables {
+		columns[t.Name] = t.Columns
+	}
+	tables := make(map[string]backupTable, len(backupTables))
+	for _, t := range backupTables {
+		tables[t.name] = t
+	}
+
+	for {
+		name, section, err := b.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if name == liveItemsSection {
+			err = restoreLiveItems(ctx, tx, section)
+		} else {
+			cols, ok := columns[name]
+			if !ok {
+				return fmt.Errorf("%w: unknown section %s", ErrNotBackup, name)
+			}
+			err = restoreTable(ctx, tx, name, cols, section, m.Incremental(), tables[name])
+		}
+		if err != nil {
+			return fmt.Errorf("could not restore %s %w", name, err)
+		}
+		err = section.drain()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, t := range m.Tables {
+		table := pgx.Identifier{t.Name}.Sanitize()
+		_, err = tx.Exec(ctx, fmt.Sprintf(
+			"SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM %s", t.Name, table))
+		if err != nil {
+			return fmt.Errorf("could not reset sequence of %s %w", t.Name, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func restoreTable(ctx context.Context, tx pgx.Tx, name string, columns []string, data io.Reader, incremental bool, t backupTable) error {
+	table := pgx.Identifier{name}.Sanitize()
+	cols := strings.Join(quoteAll(columns), ", ")
+
+	if incremental && t.replace {
+		_, err := tx.Exec(ctx, "DELETE FROM "+table)
+		if err != nil {
+			return err
+		}
+	}
+
+	// строки данных изменённых записей удалены каскадно вместе с самими записями, их можно просто вставить
+	if !t.appendOnly && (!incremental || (name != "auth_user" && name != "item")) {
+		_, err := tx.Conn().PgConn().CopyFrom(ctx, data, fmt.Sprintf("COPY %s (%s) FROM STDIN", table, cols))
+		return err
+	}
+
+	tmp := pgx.Identifier{"restore_" + name}.Sanitize()
+	_, err := tx.Exec(ctx, fmt.Sprintf("CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP", tmp, table))
+	if err != nil {
+		return err
+	}
+	_, err = tx.Conn().PgConn().CopyFrom(ctx, data, fmt.Sprintf("COPY %s (%s) FROM STDIN", tmp, cols))
+	if err != nil {
+		return err
+	}
+
+	// неизменяемые строки из перекрытия с предыдущей копией уже восстановлены, обновляются только стёртые после неё
+	if t.appendOnly {
+		conflict := "DO NOTHING"
+		if t.redacted {
+			set := make([]string, 0, len(columns))
+			for _, c := range quoteAll(columns) {
+				set = append(set, fmt.Sprintf("%s = EXCLUDED.%s", c, c))
+			}
+			conflict = fmt.Sprintf("DO UPDATE SET %s WHERE EXCLUDED.redacted_at > COALESCE(%s.redacted_at, '-infinity')",
+				strings.Join(set, ", "), table)
+		}
+		_, err = tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT (id) %s", table, cols, cols, tmp, conflict))
+		return err
+	}
+
+	if name == "auth_user" {
+		// в инкрементальной копии все пользователи на момент снимка: удалённые после предыдущей копии убираются
+		// вместе с их данными до вставки, иначе стёртая учётная запись вернулась бы, а занятый заново логин
+		// не дал бы восстановить копию
+		_, err = tx.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE id NOT IN (SELECT id FROM %s)", table, tmp))
+		if err != nil {
+			return err
+		}
+		set := make([]string, 0, len(columns))
+		for _, c := range quoteAll(columns) {
+			set = append(set, fmt.Sprintf("%s = EXCLUDED.%s", c, c))
+		}
+		_, err = tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT (id) DO UPDATE SET %s",
+			table, cols, cols, tmp, strings.Join(set, ", ")))
+		return err
+	}
+
+	_, err = tx.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE id IN (SELECT id FROM %s)", table, tmp))
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", table, cols, cols, tmp))
+	return err
+}
+
+// restoreLiveItems удаляет записи, которых не было на момент инкрементальной копии
+func restoreLiveItems(ctx context.Context, tx pgx.Tx, data io.Reader) error {
+	_, err := tx.Exec(ctx, "CREATE TEMP TABLE restore_live_item (id BIGINT PRIMARY KEY) ON COMMIT DROP")
+	if err != nil {
+		return err
+	}
+	_, err = tx.Conn().PgConn().CopyFrom(ctx, data, "COPY restore_live_item (id) FROM STDIN")
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, "DELETE FROM item WHERE id NOT IN (SELECT id FROM restore_live_item)")
+	return err
+}
@@ -0,0 +1,106 @@
+//go:build integration_tests
+// +build integration_tests
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	require.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "backupUser", "pass")
+	userID, err := d.GetUserID(ctx, "backupUser")
+	require.NoError(t, err)
+
+	text := types.TextData("text")
+	require.NoError(t, d.InsertLogoPass(ctx, userID, types.LoginPasswordItem{
+		Item: types.Item{Key: "b1", Type: types.TypeLogoPass}, Data: &types.LoginPassword{Login: "l", Password: "p"}}))
+	require.NoError(t, d.InsertText(ctx, userID, types.TextItem{Item: types.Item{Key: "b2", Type: types.TypeText}, Data: text}))
+
+	_ = d.CreateUser(ctx, "erasedUser", "pass")
+	erasedID, err := d.GetUserID(ctx, "erasedUser")
+	require.NoError(t, err)
+	require.NoError(t, d.InsertText(ctx, erasedID, types.TextItem{Item: types.Item{Key: "e1", Type: types.TypeText}, Data: text}))
+
+	var full bytes.Buffer
+	fullManifest, err := d.Backup(ctx, &full, nil)
+	require.NoError(t, err)
+	assert.False(t, fullManifest.Incremental())
+
+	// после полной копии одна запись удалена, ключ занят заново, добавлена новая
+	require.NoError(t, d.DeleteItem(ctx, userID, "b1"))
+	require.NoError(t, d.InsertLogoPass(ctx, userID, types.LoginPasswordItem{
+		Item: types.Item{Key: "b1", Type: types.TypeLogoPass}, Data: &types.LoginPassword{Login: "l2", Password: "p2"}}))
+	require.NoError(t, d.InsertText(ctx, userID, types.TextItem{Item: types.Item{Key: "b3", Type: types.TypeText}, Data: text}))
+
+	// стёртая учётная запись не возвращается при восстановлении, её логин занят заново
+	require.NoError(t, d.DeleteUser(ctx, erasedID))
+	require.NoError(t, d.CreateUser(ctx, "erasedUser", "other"))
+	reusedID, err := d.GetUserID(ctx, "erasedUser")
+	require.NoError(t, err)
+
+	since := fullManifest.CreatedAt.Add(-IncrementalOverlap)
+	var incremental bytes.Buffer
+	incManifest, err := d.Backup(ctx, &incremental, &since)
+	require.NoError(t, err)
+	assert.True(t, incManifest.Incremental())
+
+	fullReader, err := NewBackupReader(bytes.NewReader(full.Bytes()))
+	require.NoError(t, err)
+	assert.ErrorIs(t, d.Restore(ctx, fullReader, nil), ErrNotEmpty)
+
+	_, err = d.pool.Exec(ctx, "TRUNCATE auth_user, item RESTART IDENTITY CASCADE")
+	require.NoError(t, err)
+
+	incReader, err := NewBackupReader(bytes.NewReader(incremental.Bytes()))
+	require.NoError(t, err)
+	assert.ErrorIs(t, d.Restore(ctx, incReader, nil), ErrBackupChain)
+
+	fullReader, err = NewBackupReader(bytes.NewReader(full.Bytes()))
+	require.NoError(t, err)
+	require.NoError(t, d.Restore(ctx, fullReader, nil))
+
+	i, err := d.GetItem(ctx, userID, "b1")
+	require.NoError(t, err)
+	logopass, err := d.GetLogoPass(ctx, i.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "l", logopass.Login)
+	_, err = d.GetItem(ctx, userID, "b3")
+	assert.Error(t, err)
+
+	incReader, err = NewBackupReader(bytes.NewReader(incremental.Bytes()))
+	require.NoError(t, err)
+	require.NoError(t, d.Restore(ctx, incReader, &fullManifest.CreatedAt))
+
+	i, err = d.GetItem(ctx, userID, "b1")
+	require.NoError(t, err)
+	logopass, err = d.GetLogoPass(ctx, i.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "l2", logopass.Login)
+	_, err = d.GetItem(ctx, userID, "b3")
+	assert.NoError(t, err)
+
+	id, err := d.GetUserID(ctx, "erasedUser")
+	require.NoError(t, err)
+	assert.Equal(t, reusedID, id)
+	password, err := d.GetUserHashedPassword(ctx, "erasedUser")
+	require.NoError(t, err)
+	assert.Equal(t, "other", password)
+	_, err = d.GetItem(ctx, erasedID, "e1")
+	assert.Error(t, err)
+
+	// последовательности продолжаются после восстановленных id
+	require.NoError(t, d.InsertText(ctx, userID, types.TextItem{Item: types.Item{Key: "b4", Type: types.TypeText}, Data: text}))
+}
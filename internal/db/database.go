@@ -28,6 +28,11 @@ func NewDatabase(connString string) (*Database, error) {
 		return nil, fmt.Errorf("failed to migrate %w", err)
 	}
 
+	return Connect(connString)
+}
+
+// Connect подключается к БД без применения миграций
+func Connect(connString string) (*Database, error) {
 	ctx := context.Background()
 	p, err := pgxpool.New(ctx, connString)
 	if err != nil {
@@ -42,12 +47,20 @@ func NewDatabase(connString string) (*Database, error) {
 // CreateUser создание нового пользователя в БД
 func (d *Database) CreateUser(ctx context.Context, username string, password string) error {
 
-	query := `
-		INSERT INTO auth_user (username, password)
-		VALUES ($1, $2)
-		`
-	_, err := d.pool.Exec(ctx, query, username, password)
+	tag, err := d.pool.Exec(ctx, insertUserQuery, username, password)
 
+	return userInsertError(tag, err, username)
+}
+
+// insertUserQuery новый пользователь. Логины, заведённые до появления правил, хранятся как их ввели, поэтому
+// занятым считается и логин, отличающийся от существующего только регистром
+const insertUserQuery = `
+	INSERT INTO auth_user (username, password)
+	SELECT $1, $2
+	WHERE NOT EXISTS (SELECT 1 FROM auth_user WHERE lower(username) = lower($1))
+`
+
+func userInsertError(tag pgconn.CommandTag, err error, username string) error {
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
@@ -55,6 +68,9 @@ func (d *Database) CreateUser(ctx context.Context, username string, password str
 		}
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w", &UserExistsError{Username: username})
+	}
 	return nil
 }
 
@@ -98,11 +114,11 @@ func (d *Database) GetUserID(ctx context.Context, username string) (int, error)
 // InsertItem сохраняет в БД запись о метаданных Item
 func (d *Database) InsertItem(ctx context.Context, tx pgx.Tx, userID int, item types.Item) (int, error) {
 	query := `
-		INSERT INTO item(user_id, key, item_type, info)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO item(user_id, key, item_type, info, expires_at, rotate_days)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id `
 
-	row := tx.QueryRow(ctx, query, userID, item.Key, item.Type, item.Info)
+	row := tx.QueryRow(ctx, query, userID, item.Key, item.Type, item.Info, item.ExpiresAt, item.RotateDays)
 
 	var itemID int
 	if err := row.Scan(&itemID); err != nil {
@@ -115,24 +131,41 @@ func (d *Database) InsertItem(ctx context.Context, tx pgx.Tx, userID int, item t
 	return itemID, nil
 }
 
-// UpdateItem обновляет хранящуюся в БД запись с метаданными Item
-func (d *Database) UpdateItem(ctx context.Context, tx pgx.Tx, userID int, item types.Item) (int, error) {
+// UpdateItem обновляет хранящуюся в БД запись с метаданными Item. Возвращает id записи и то, была ли она
+// зашифрована старым ключом хранилища до обновления
+func (d *Database) UpdateItem(ctx context.Context, tx pgx.Tx, userID int, item types.Item) (int, bool, error) {
 	query := `
-		UPDATE item
-		SET info = $1
-		WHERE key = $2 AND user_id = $3
-		RETURNING id `
+		UPDATE item i
+		SET info = $1, expires_at = $2, rotate_days = $3, legacy = false, updated_at = now()
+		FROM item o
+		WHERE i.key = $4 AND i.user_id = $5 AND o.id = i.id
+		RETURNING i.id, o.legacy `
 
-	row := tx.QueryRow(ctx, query, item.Info, item.Key, userID)
+	row := tx.QueryRow(ctx, query, item.Info, item.ExpiresAt, item.RotateDays, item.Key, userID)
 
 	var itemID int
-	if err := row.Scan(&itemID); err != nil {
+	var legacy bool
+	if err := row.Scan(&itemID, &legacy); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, fmt.Errorf("%w", &KeyNotFoundError{Key: item.Key})
+			return 0, false, fmt.Errorf("%w", &KeyNotFoundError{Key: item.Key})
 		}
-		return 0, fmt.Errorf("unexpected db error %w", err)
+		return 0, false, fmt.Errorf("unexpected db error %w", err)
 	}
-	return itemID, nil
+	return itemID, legacy, nil
+}
+
+// touchSecret отмечает смену секрета записи. Данные шифруются детерминированно, поэтому правка описания,
+// сроков или вложений их не меняет. Перешифрование записи, зашифрованной старым ключом хранилища (legacy),
+// сменой секрета не считается
+func touchSecret(ctx context.Context, tx pgx.Tx, itemID int, changed bool, legacy bool) error {
+	if !changed || legacy {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `UPDATE item SET secret_changed_at = now() WHERE id = $1`, itemID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return nil
 }
 
 // InsertText сохраняет в БД текстовые данные
@@ -147,7 +180,7 @@ func (d *Database) InsertText(ctx context.Context, userID int, item types.TextIt
 			fmt.Println(err.Error())
 		}
 	}()
-	itemID, err := d.InsertItem(ctx, tx, userID, types.Item{Key: item.Item.Key, Type: types.TypeText, Info: item.Item.Info})
+	itemID, err := d.InsertItem(ctx, tx, userID, types.Item{Key: item.Item.Key, Type: types.TypeText, Info: item.Item.Info, ExpiresAt: item.Item.ExpiresAt, RotateDays: item.Item.RotateDays})
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
@@ -179,15 +212,15 @@ func (d *Database) InsertCreditCard(ctx context.Context, userID int, item types.
 			fmt.Println(err.Error())
 		}
 	}()
-	itemID, err := d.InsertItem(ctx, tx, userID, types.Item{Key: item.Item.Key, Type: types.TypeCreditCard, Info: item.Item.Info})
+	itemID, err := d.InsertItem(ctx, tx, userID, types.Item{Key: item.Item.Key, Type: types.TypeCreditCard, Info: item.Item.Info, ExpiresAt: item.Item.ExpiresAt, RotateDays: item.Item.RotateDays})
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
 	query := `
-		INSERT INTO credit_card (item_id, number, owner_name, valid_till, cvc)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO credit_card (item_id, number, owner_name, valid_month, valid_year, cvc)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err = tx.Exec(ctx, query, itemID, item.Data.Number, item.Data.Name, fmt.Sprintf("%s-%s-01", item.Data.ValidYear, item.Data.ValidMonth), item.Data.CVC)
+	_, err = tx.Exec(ctx, query, itemID, item.Data.Number, item.Data.Name, item.Data.ValidMonth, item.Data.ValidYear, item.Data.CVC)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
@@ -211,15 +244,16 @@ func (d *Database) InsertBinaryData(ctx context.Context, userID int, item types.
 			fmt.Println(err.Error())
 		}
 	}()
-	itemID, err := d.InsertItem(ctx, tx, userID, types.Item{Key: item.Item.Key, Type: types.TypeBinary, Info: item.Item.Info})
+	itemID, err := d.InsertItem(ctx, tx, userID, types.Item{Key: item.Item.Key, Type: types.TypeBinary, Info: item.Item.Info, ExpiresAt: item.Item.ExpiresAt, RotateDays: item.Item.RotateDays})
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
 	query := `
-		INSERT INTO binary_data (item_id, data)
-		VALUES ($1, $2)
+		INSERT INTO binary_data (item_id, data, file_name, content_type, size, sha256)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err = tx.Exec(ctx, query, itemID, item.Data)
+	fileName, contentType, size, sum := binaryMetaArgs(item.Meta)
+	_, err = tx.Exec(ctx, query, itemID, item.Data, fileName, contentType, size, sum)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
@@ -250,10 +284,74 @@ func (d *Database) InsertLogoPass(ctx context.Context, userID int, data types.Lo
 		return fmt.Errorf("%w", err)
 	}
 	query := `
-		INSERT INTO logopass (item_id, login, password)
-		VALUES ($1, $2, $3)
+		INSERT INTO logopass (item_id, login, password, totp)
+		VALUES ($1, $2, $3, $4)
 	`
-	_, err = tx.Exec(ctx, query, itemID, data.Data.Login, data.Data.Password)
+	_, err = tx.Exec(ctx, query, itemID, data.Data.Login, data.Data.Password, data.Data.TOTP)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// InsertSSHKey сохраняет в БД SSH-ключ
+func (d *Database) InsertSSHKey(ctx context.Context, userID int, data types.SSHKeyItem) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		err = tx.Rollback(ctx)
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+	}()
+	itemID, err := d.InsertItem(ctx, tx, userID, types.Item{Key: data.Item.Key, Type: types.TypeSSHKey, Info: data.Item.Info, ExpiresAt: data.Item.ExpiresAt, RotateDays: data.Item.RotateDays})
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	query := `
+		INSERT INTO ssh_key (item_id, private_key, public_key, passphrase, comment)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err = tx.Exec(ctx, query, itemID, data.Data.PrivateKey, data.Data.PublicKey, data.Data.Passphrase, data.Data.Comment)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// InsertTOTP сохраняет в БД TOTP-секрет
+func (d *Database) InsertTOTP(ctx context.Context, userID int, data types.TOTPItem) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		err = tx.Rollback(ctx)
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+	}()
+	itemID, err := d.InsertItem(ctx, tx, userID, types.Item{Key: data.Item.Key, Type: types.TypeTOTP, Info: data.Item.Info, ExpiresAt: data.Item.ExpiresAt, RotateDays: data.Item.RotateDays})
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	query := `
+		INSERT INTO totp (item_id, secret)
+		VALUES ($1, $2)
+	`
+	_, err = tx.Exec(ctx, query, itemID, data.Data.Secret)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
@@ -280,19 +378,26 @@ func (d *Database) UpdateLogoPass(ctx context.Context, userID int, data types.Lo
 		}
 	}()
 
-	itemID, err := d.UpdateItem(ctx, tx, userID, data.Item)
+	itemID, legacy, err := d.UpdateItem(ctx, tx, userID, data.Item)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
 	query := `
-		UPDATE logopass
-		SET login = $1, password = $2
-		WHERE item_id = $3
+		UPDATE logopass n
+		SET login = $1, password = $2, totp = $3
+		FROM logopass o
+		WHERE n.item_id = $4 AND o.id = n.id
+		RETURNING (o.login, o.password, o.totp) IS DISTINCT FROM (n.login, n.password, n.totp)
 	`
-	_, err = tx.Exec(ctx, query, data.Data.Login, data.Data.Password, itemID)
+	var changed bool
+	err = tx.QueryRow(ctx, query, data.Data.Login, data.Data.Password, data.Data.TOTP, itemID).Scan(&changed)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
+	err = touchSecret(ctx, tx, itemID, changed, legacy)
+	if err != nil {
+		return err
+	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
@@ -315,19 +420,110 @@ func (d *Database) UpdateCreditCard(ctx context.Context, userID int, data types.
 		}
 	}()
 
-	itemID, err := d.UpdateItem(ctx, tx, userID, data.Item)
+	itemID, legacy, err := d.UpdateItem(ctx, tx, userID, data.Item)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	query := `
+		UPDATE credit_card n
+		SET number = $1, owner_name = $2, valid_month = $3, valid_year = $4, valid_till = NULL, cvc = $5
+		FROM credit_card o
+		WHERE n.item_id = $6 AND o.id = n.id
+		RETURNING (o.number, o.cvc) IS DISTINCT FROM (n.number, n.cvc)
+	`
+	var changed bool
+	err = tx.QueryRow(ctx, query, data.Data.Number, data.Data.Name, data.Data.ValidMonth, data.Data.ValidYear, data.Data.CVC, itemID).Scan(&changed)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	err = touchSecret(ctx, tx, itemID, changed, legacy)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// UpdateSSHKey обновляет SSH-ключ
+func (d *Database) UpdateSSHKey(ctx context.Context, userID int, data types.SSHKeyItem) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	defer func() {
+		err = tx.Rollback(ctx)
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+	}()
+
+	itemID, legacy, err := d.UpdateItem(ctx, tx, userID, data.Item)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
 	query := `
-		UPDATE credit_card
-		SET number = $1, owner_name = $2, valid_till = $3, cvc = $4
-		WHERE item_id = $5
+		UPDATE ssh_key n
+		SET private_key = $1, public_key = $2, passphrase = $3, comment = $4
+		FROM ssh_key o
+		WHERE n.item_id = $5 AND o.id = n.id
+		RETURNING (o.private_key, o.passphrase) IS DISTINCT FROM (n.private_key, n.passphrase)
 	`
-	_, err = tx.Exec(ctx, query, data.Data.Number, data.Data.Name, fmt.Sprintf("%s-%s-01", data.Data.ValidYear, data.Data.ValidMonth), data.Data.CVC, itemID)
+	var changed bool
+	err = tx.QueryRow(ctx, query, data.Data.PrivateKey, data.Data.PublicKey, data.Data.Passphrase, data.Data.Comment, itemID).Scan(&changed)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
+	err = touchSecret(ctx, tx, itemID, changed, legacy)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// UpdateTOTP обновляет TOTP-секрет
+func (d *Database) UpdateTOTP(ctx context.Context, userID int, data types.TOTPItem) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	defer func() {
+		err = tx.Rollback(ctx)
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+	}()
+
+	itemID, legacy, err := d.UpdateItem(ctx, tx, userID, data.Item)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	query := `
+		UPDATE totp n
+		SET secret = $1
+		FROM totp o
+		WHERE n.item_id = $2 AND o.id = n.id
+		RETURNING o.secret IS DISTINCT FROM n.secret
+	`
+	var changed bool
+	err = tx.QueryRow(ctx, query, data.Data.Secret, itemID).Scan(&changed)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	err = touchSecret(ctx, tx, itemID, changed, legacy)
+	if err != nil {
+		return err
+	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
@@ -350,19 +546,27 @@ func (d *Database) UpdateBinaryData(ctx context.Context, userID int, data types.
 		}
 	}()
 
-	itemID, err := d.UpdateItem(ctx, tx, userID, data.Item)
+	itemID, legacy, err := d.UpdateItem(ctx, tx, userID, data.Item)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
 	query := `
-		UPDATE binary_data
-		SET data = $1
-		WHERE item_id = $2
+		UPDATE binary_data n
+		SET data = $1, file_name = $2, content_type = $3, size = $4, sha256 = $5
+		FROM binary_data o
+		WHERE n.item_id = $6 AND o.id = n.id
+		RETURNING o.data IS DISTINCT FROM n.data
 	`
-	_, err = tx.Exec(ctx, query, data.Data, itemID)
+	fileName, contentType, size, sum := binaryMetaArgs(data.Meta)
+	var changed bool
+	err = tx.QueryRow(ctx, query, data.Data, fileName, contentType, size, sum, itemID).Scan(&changed)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
+	err = touchSecret(ctx, tx, itemID, changed, legacy)
+	if err != nil {
+		return err
+	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
@@ -385,19 +589,26 @@ func (d *Database) UpdateText(ctx context.Context, userID int, data types.TextIt
 		}
 	}()
 
-	itemID, err := d.UpdateItem(ctx, tx, userID, data.Item)
+	itemID, legacy, err := d.UpdateItem(ctx, tx, userID, data.Item)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
 	query := `
-		UPDATE text_data
+		UPDATE text_data n
 		SET data = $1
-		WHERE item_id = $2
+		FROM text_data o
+		WHERE n.item_id = $2 AND o.id = n.id
+		RETURNING o.data IS DISTINCT FROM n.data
 	`
-	_, err = tx.Exec(ctx, query, data.Data, itemID)
+	var changed bool
+	err = tx.QueryRow(ctx, query, data.Data, itemID).Scan(&changed)
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
+	err = touchSecret(ctx, tx, itemID, changed, legacy)
+	if err != nil {
+		return err
+	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
@@ -420,43 +631,191 @@ func (d *Database) DeleteItem(ctx context.Context, userID int, key string) error
 	return nil
 }
 
-// GetBinaryData достаёт бинарные данные из БД
-func (d *Database) GetBinaryData(ctx context.Context, userID int, key string) ([]byte, error) {
+// GetBinaryData достаёт бинарные данные из БД вместе с метаданными (nil для данных, сохранённых без метаданных)
+func (d *Database) GetBinaryData(ctx context.Context, userID int, key string) ([]byte, *types.BinaryMeta, error) {
 
 	item, err := d.GetItem(ctx, userID, key)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if item.Type != types.TypeBinary {
-		return nil, &KeyNotFoundError{Key: key}
+		return nil, nil, &KeyNotFoundError{Key: key}
 	}
 
 	query := `
-		SELECT data
+		SELECT data, file_name, content_type, size, sha256
+		FROM binary_data
+		WHERE item_id = $1
+	`
+
+	var data []byte
+	var fileName, contentType, sum *string
+	var size *int64
+
+	err = d.pool.QueryRow(ctx, query, item.Id).Scan(&data, &fileName, &contentType, &size, &sum)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, &KeyNotFoundError{Key: key}
+		}
+		return nil, nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return data, binaryMeta(fileName, contentType, size, sum), nil
+}
+
+// GetBinaryMeta достаёт из БД метаданные бинарных данных (nil для данных, сохранённых без метаданных)
+func (d *Database) GetBinaryMeta(ctx context.Context, itemID int) (*types.BinaryMeta, error) {
+	query := `
+		SELECT file_name, content_type, size, sha256
 		FROM binary_data
 		WHERE item_id = $1
 	`
 
+	var fileName, contentType, sum *string
+	var size *int64
+
+	err := d.pool.QueryRow(ctx, query, itemID).Scan(&fileName, &contentType, &size, &sum)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	return binaryMeta(fileName, contentType, size, sum), nil
+}
+
+func binaryMetaArgs(meta *types.BinaryMeta) (fileName, contentType, size, sum any) {
+	if meta == nil {
+		return nil, nil, nil, nil
+	}
+	return meta.FileName, meta.ContentType, meta.Size, meta.SHA256
+}
+
+func binaryMeta(fileName, contentType *string, size *int64, sum *string) *types.BinaryMeta {
+	if fileName == nil || contentType == nil || size == nil || sum == nil {
+		return nil
+	}
+	return &types.BinaryMeta{FileName: *fileName, ContentType: *contentType, Size: *size, SHA256: *sum}
+}
+
+// InsertAttachment прикрепляет к записи key вложение с зашифрованными данными и возвращает его идентификатор
+func (d *Database) InsertAttachment(ctx context.Context, userID int, key string, attachment types.Attachment, data []byte) (int, error) {
+	item, err := d.GetItem(ctx, userID, key)
+	if err != nil {
+		return 0, err
+	}
+
+	// время изменения записи сдвигается, чтобы вложение попало в инкрементальную резервную копию
+	query := `
+		WITH touched AS (UPDATE item SET updated_at = now() WHERE id = $1)
+		INSERT INTO attachment (item_id, name, mime_type, encrypted, data)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	var id int
+	err = d.pool.QueryRow(ctx, query, item.Id, attachment.Name, attachment.MimeType, attachment.Encrypted, data).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+	return id, nil
+}
+
+// GetAttachments достаёт из БД список вложений записи key
+func (d *Database) GetAttachments(ctx context.Context, userID int, key string) ([]types.Attachment, error) {
+	item, err := d.GetItem(ctx, userID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, name, mime_type, octet_length(data) AS size, encrypted
+		FROM attachment
+		WHERE item_id = $1
+		ORDER BY id
+	`
+
 	rows, err := d.pool.Query(ctx, query, item.Id)
 	if err != nil {
 		return nil, fmt.Errorf("failed collecting rows %w", err)
 	}
 
-	data, err := pgx.CollectOneRow(rows, pgx.RowTo[[]byte])
+	attachments, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.Attachment])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return attachments, nil
+}
+
+// GetAttachment достаёт из БД вложение id записи key вместе с данными
+func (d *Database) GetAttachment(ctx context.Context, userID int, key string, id int) (*types.Attachment, []byte, error) {
+	item, err := d.GetItem(ctx, userID, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := `
+		SELECT name, mime_type, encrypted, data
+		FROM attachment
+		WHERE item_id = $1 AND id = $2
+	`
+
+	attachment := types.Attachment{ID: id}
+	var data []byte
+
+	err = d.pool.QueryRow(ctx, query, item.Id, id).Scan(&attachment.Name, &attachment.MimeType, &attachment.Encrypted, &data)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, &KeyNotFoundError{Key: key}
+			return nil, nil, &KeyNotFoundError{Key: strconv.Itoa(id)}
 		}
-		return nil, fmt.Errorf("failed unpacking rows %w", err)
+		return nil, nil, fmt.Errorf("%w", err)
+	}
+	attachment.Size = len(data)
+	return &attachment, data, nil
+}
+
+// ReplaceAttachment заменяет данные, имя и MIME-тип вложения id записи key
+func (d *Database) ReplaceAttachment(ctx context.Context, userID int, key string, id int, attachment types.Attachment, data []byte) error {
+	query := `
+		WITH replaced AS (
+			UPDATE attachment SET name = $4, mime_type = $5, encrypted = $6, data = $7, legacy = false
+			FROM item
+			WHERE attachment.item_id = item.id AND item.user_id = $1 AND item.key = $2 AND attachment.id = $3
+			RETURNING attachment.item_id
+		)
+		UPDATE item SET updated_at = now() WHERE id IN (SELECT item_id FROM replaced)
+	`
+	tag, err := d.pool.Exec(ctx, query, userID, key, id, attachment.Name, attachment.MimeType, attachment.Encrypted, data)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: strconv.Itoa(id)}
+	}
+	return nil
+}
+
+// DeleteAttachment удаляет вложение id записи key из БД
+func (d *Database) DeleteAttachment(ctx context.Context, userID int, key string, id int) error {
+	query := `
+		WITH deleted AS (
+			DELETE FROM attachment
+			USING item
+			WHERE attachment.item_id = item.id AND item.user_id = $1 AND item.key = $2 AND attachment.id = $3
+			RETURNING attachment.item_id
+		)
+		UPDATE item SET updated_at = now() WHERE id IN (SELECT item_id FROM deleted)
+	`
+	tag, err := d.pool.Exec(ctx, query, userID, key, id)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: strconv.Itoa(id)}
 	}
-	return data, nil
+	return nil
 }
 
 // GetItem достаёт запись с метаданным из БД
 func (d *Database) GetItem(ctx context.Context, userID int, key string) (*types.Item, error) {
 	query := `
-		SELECT id, item_type, info, key
+		SELECT id, item_type, info, key, expires_at, rotate_days, updated_at, secret_changed_at
 		FROM item
 		WHERE user_id = $1 AND key = $2
 	`
@@ -479,7 +838,7 @@ func (d *Database) GetItem(ctx context.Context, userID int, key string) (*types.
 // GetLogoPass достаёт данные типа "логин и пароль" из БД
 func (d *Database) GetLogoPass(ctx context.Context, itemID int) (*types.LoginPassword, error) {
 	query := `
-		SELECT login, password
+		SELECT login, password, totp
 		FROM logopass
 		WHERE item_id = $1
 	`
@@ -499,7 +858,10 @@ func (d *Database) GetLogoPass(ctx context.Context, itemID int) (*types.LoginPas
 // GetCreditCard достаёт данные о кредитной карте из БД
 func (d *Database) GetCreditCard(ctx context.Context, itemID int) (*types.CreditCardData, error) {
 	query := `
-		SELECT number, owner_name, valid_till, cvc
+		SELECT number, owner_name, cvc,
+			COALESCE(valid_month, '') AS valid_month,
+			COALESCE(valid_year, '') AS valid_year,
+			COALESCE(valid_till, '0001-01-01') AS valid_till
 		FROM credit_card
 		WHERE item_id = $1
 	`
@@ -513,8 +875,46 @@ func (d *Database) GetCreditCard(ctx context.Context, itemID int) (*types.Credit
 	if err != nil {
 		return nil, fmt.Errorf("failed unpacking rows %w", err)
 	}
-	item.ValidMonth = strconv.Itoa(int(item.ValidDate.Month()))
-	item.ValidYear = strconv.Itoa(item.ValidDate.Year())
+	return &item, nil
+}
+
+// GetSSHKey достаёт SSH-ключ из БД
+func (d *Database) GetSSHKey(ctx context.Context, itemID int) (*types.SSHKeyData, error) {
+	query := `
+		SELECT private_key, public_key, passphrase, comment
+		FROM ssh_key
+		WHERE item_id = $1
+	`
+
+	rows, err := d.pool.Query(ctx, query, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+
+	item, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[types.SSHKeyData])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return &item, nil
+}
+
+// GetTOTP достаёт TOTP-секрет из БД
+func (d *Database) GetTOTP(ctx context.Context, itemID int) (*types.TOTPData, error) {
+	query := `
+		SELECT secret
+		FROM totp
+		WHERE item_id = $1
+	`
+
+	rows, err := d.pool.Query(ctx, query, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+
+	item, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[types.TOTPData])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
 	return &item, nil
 }
 
@@ -541,7 +941,7 @@ func (d *Database) GetText(ctx context.Context, itemID int) (*types.TextData, er
 func (d *Database) GetItems(ctx context.Context, userID int, limit int, offset int) ([]types.Item, error) {
 
 	query := `
-		SELECT id, item_type, info, key
+		SELECT id, item_type, info, key, expires_at, rotate_days, updated_at, secret_changed_at
 		FROM item
 		WHERE user_id = $1
 		ORDER BY id
@@ -11,6 +11,7 @@ import (
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -98,7 +99,7 @@ func TestItemMethods(t *testing.T) {
 
 	tx, err = d.pool.Begin(context.Background())
 
-	_, err = d.UpdateItem(context.Background(), tx, userID, types.Item{Key: "1", Type: "text", Info: "new info"})
+	_, _, err = d.UpdateItem(context.Background(), tx, userID, types.Item{Key: "1", Type: "text", Info: "new info"})
 	assert.NoError(t, err)
 
 	_ = tx.Commit(context.Background())
@@ -107,6 +108,17 @@ func TestItemMethods(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.Equal(t, "new info", i.Info)
+	assert.NotNil(t, i.UpdatedAt)
+
+	tx, err = d.pool.Begin(context.Background())
+	_, _, err = d.UpdateItem(context.Background(), tx, userID, types.Item{Key: "1", Type: "text", ExpiresAt: "e", RotateDays: "r"})
+	assert.NoError(t, err)
+	_ = tx.Commit(context.Background())
+
+	i, err = d.GetItem(context.Background(), userID, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "e", i.ExpiresAt)
+	assert.Equal(t, "r", i.RotateDays)
 
 	defer func() {
 		err = tx.Rollback(context.Background())
@@ -145,6 +157,26 @@ func TestStoreDataMethods(t *testing.T) {
 
 	assert.Equal(t, "2005", card.ValidYear)
 
+	_, err = d.pool.Exec(ctx, "UPDATE credit_card SET valid_month = NULL, valid_year = NULL, valid_till = '2001-02-01' WHERE item_id = $1", i.Id)
+	assert.NoError(t, err)
+	card, err = d.GetCreditCard(ctx, i.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "", card.ValidMonth)
+	assert.Equal(t, 2001, card.ValidDate.Year())
+
+	// карта с открытым сроком действия ждёт пересохранения клиентом, после него срок хранится только зашифрованным
+	legacy, err := d.GetLegacyVault(ctx, userID)
+	assert.NoError(t, err)
+	assert.Contains(t, legacy.Cards, "2")
+	err = d.UpdateCreditCard(ctx, userID, types.CreditCardItem{Item: types.Item{Type: types.TypeCreditCard, Key: "2"}, Data: &types.CreditCardData{ValidMonth: "2", ValidYear: "2001"}})
+	assert.NoError(t, err)
+	legacy, err = d.GetLegacyVault(ctx, userID)
+	assert.NoError(t, err)
+	assert.NotContains(t, legacy.Cards, "2")
+	card, err = d.GetCreditCard(ctx, i.Id)
+	assert.NoError(t, err)
+	assert.True(t, card.ValidDate.IsZero())
+
 	err = d.InsertLogoPass(ctx, userID, types.LoginPasswordItem{Item: types.Item{Type: types.TypeLogoPass, Key: "3"}, Data: &types.LoginPassword{}})
 	assert.NoError(t, err)
 
@@ -185,9 +217,683 @@ func TestStoreDataMethods(t *testing.T) {
 	err = d.UpdateBinaryData(ctx, userID, types.BinaryItem{Item: types.Item{Type: types.TypeText, Key: "5"}, Data: []byte("www")})
 	assert.NoError(t, err)
 
-	data, err := d.GetBinaryData(ctx, userID, "5")
+	data, meta, err := d.GetBinaryData(ctx, userID, "5")
 	assert.NoError(t, err)
 
 	assert.Equal(t, "www", string(data))
+	assert.Nil(t, meta)
+
+	binaryMeta := &types.BinaryMeta{FileName: "f", ContentType: "text/plain", Size: 3, SHA256: "s"}
+	err = d.UpdateBinaryData(ctx, userID, types.BinaryItem{Item: types.Item{Type: types.TypeText, Key: "5"}, Data: []byte("www"), Meta: binaryMeta})
+	assert.NoError(t, err)
+
+	_, meta, err = d.GetBinaryData(ctx, userID, "5")
+	assert.NoError(t, err)
+	assert.Equal(t, binaryMeta, meta)
+
+	i, err = d.GetItem(ctx, userID, "5")
+	assert.NoError(t, err)
+	meta, err = d.GetBinaryMeta(ctx, i.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, binaryMeta, meta)
+
+	err = d.InsertSSHKey(ctx, userID, types.SSHKeyItem{Item: types.Item{Type: types.TypeSSHKey, Key: "6"}, Data: &types.SSHKeyData{PrivateKey: "private"}})
+	assert.NoError(t, err)
+
+	err = d.InsertSSHKey(ctx, userID, types.SSHKeyItem{Item: types.Item{Type: types.TypeSSHKey, Key: "6"}, Data: &types.SSHKeyData{PrivateKey: "private"}})
+	assert.Error(t, err)
+
+	err = d.UpdateSSHKey(ctx, userID, types.SSHKeyItem{Item: types.Item{Type: types.TypeSSHKey, Key: "6"}, Data: &types.SSHKeyData{PrivateKey: "new", Comment: "c"}})
+	assert.NoError(t, err)
+
+	i, err = d.GetItem(ctx, userID, "6")
+	assert.NoError(t, err)
+	assert.Equal(t, types.TypeSSHKey, i.Type)
+	key, err := d.GetSSHKey(ctx, i.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", key.PrivateKey)
+	assert.Equal(t, "c", key.Comment)
+
+	err = d.InsertTOTP(ctx, userID, types.TOTPItem{Item: types.Item{Type: types.TypeTOTP, Key: "7"}, Data: &types.TOTPData{Secret: "s"}})
+	assert.NoError(t, err)
+
+	err = d.UpdateTOTP(ctx, userID, types.TOTPItem{Item: types.Item{Type: types.TypeTOTP, Key: "7"}, Data: &types.TOTPData{Secret: "new"}})
+	assert.NoError(t, err)
+
+	i, err = d.GetItem(ctx, userID, "7")
+	assert.NoError(t, err)
+	secret, err := d.GetTOTP(ctx, i.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", secret.Secret)
+
+	err = d.UpdateLogoPass(ctx, userID, types.LoginPasswordItem{Item: types.Item{Type: types.TypeLogoPass, Key: "3"}, Data: &types.LoginPassword{Login: "a", TOTP: "t"}})
+	assert.NoError(t, err)
+	i, err = d.GetItem(ctx, userID, "3")
+	assert.NoError(t, err)
+	logopass, err = d.GetLogoPass(ctx, i.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "t", logopass.TOTP)
+
+	attachment := types.Attachment{Name: "ZW5jcnlwdGVk", MimeType: "ZW5jcnlwdGVk", Size: 4, Encrypted: true}
+	attachment.ID, err = d.InsertAttachment(ctx, userID, "7", attachment, []byte("data"))
+	assert.NoError(t, err)
+
+	// имена зашифрованы, одинаковые вложения различаются только идентификаторами
+	second, err := d.InsertAttachment(ctx, userID, "7", attachment, []byte("data"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, attachment.ID, second)
+
+	attachments, err := d.GetAttachments(ctx, userID, "7")
+	assert.NoError(t, err)
+	secondAttachment := attachment
+	secondAttachment.ID = second
+	assert.Equal(t, []types.Attachment{attachment, secondAttachment}, attachments)
+
+	gotAttachment, attachmentData, err := d.GetAttachment(ctx, userID, "7", attachment.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, attachment, *gotAttachment)
+	assert.Equal(t, []byte("data"), attachmentData)
+
+	err = d.DeleteAttachment(ctx, userID, "7", attachment.ID)
+	assert.NoError(t, err)
+
+	err = d.DeleteAttachment(ctx, userID, "7", attachment.ID)
+	var keyNotFound *KeyNotFoundError
+	assert.ErrorAs(t, err, &keyNotFound)
+
+	_, _, err = d.GetAttachment(ctx, userID, "7", attachment.ID)
+	assert.ErrorAs(t, err, &keyNotFound)
+
+	err = d.DeleteItem(ctx, userID, "7")
+	assert.NoError(t, err)
+	var count int
+	err = d.pool.QueryRow(ctx, "SELECT count(*) FROM attachment").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestAuditMethods(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "auditUser", "pass")
+	userID, err := d.GetUserID(ctx, "auditUser")
+	assert.NoError(t, err)
+
+	assert.NoError(t, d.AppendAuditEvent(ctx, types.AuditEvent{UserID: &userID, Actor: "auditUser", Action: types.AuditLogin}))
+	assert.NoError(t, d.AppendAuditEvent(ctx, types.AuditEvent{UserID: &userID, Actor: "auditUser", Action: types.AuditItemRead, Key: "k"}))
+	assert.NoError(t, d.AppendAuditEvent(ctx, types.AuditEvent{Actor: "nobody", Action: types.AuditLoginFailed}))
+
+	events, err := d.ListAuditEvents(ctx, userID, 10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, types.AuditItemRead, events[0].Action)
+	assert.Equal(t, events[1].Hash, events[0].PrevHash)
 
+	status, err := d.VerifyAuditChain(ctx)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, status.Checked, 3)
+	assert.NotEmpty(t, status.Head)
+
+	// журнал только дополняется, персональные поля можно только стереть
+	_, err = d.pool.Exec(ctx, "UPDATE audit_event SET actor = 'other' WHERE id = $1", events[0].ID)
+	assert.Error(t, err)
+	_, err = d.pool.Exec(ctx, "UPDATE audit_event SET actor = 'other', redacted_at = now() WHERE id = $1", events[0].ID)
+	assert.Error(t, err)
+	_, err = d.pool.Exec(ctx, "UPDATE audit_event SET hash = 'other', redacted_at = now() WHERE id = $1", events[0].ID)
+	assert.Error(t, err)
+	_, err = d.pool.Exec(ctx, "DELETE FROM audit_event WHERE id = $1", events[0].ID)
+	assert.Error(t, err)
+
+	// правка в обход триггера обнаруживается проверкой цепочки
+	_, err = d.pool.Exec(ctx, "ALTER TABLE audit_event DISABLE TRIGGER audit_event_no_update")
+	assert.NoError(t, err)
+	_, err = d.pool.Exec(ctx, "UPDATE audit_event SET actor = 'other' WHERE id = $1", events[1].ID)
+	assert.NoError(t, err)
+	_, err = d.VerifyAuditChain(ctx)
+	var chainErr *AuditChainError
+	assert.ErrorAs(t, err, &chainErr)
+	assert.Equal(t, events[1].ID, chainErr.ID)
+
+	_, err = d.pool.Exec(ctx, "UPDATE audit_event SET actor = 'auditUser' WHERE id = $1", events[1].ID)
+	assert.NoError(t, err)
+	_, err = d.pool.Exec(ctx, "ALTER TABLE audit_event ENABLE TRIGGER audit_event_no_update")
+	assert.NoError(t, err)
+}
+
+func TestSessionMethods(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "sessionUser", "pass")
+	userID, err := d.GetUserID(ctx, "sessionUser")
+	assert.NoError(t, err)
+
+	id, err := d.CreateSession(ctx, userID, types.NewSession{Device: "laptop", ClientVersion: "1.0", RemoteAddr: "10.0.0.1:5000"})
+	assert.NoError(t, err)
+	assert.NoError(t, d.TouchSession(ctx, "sessionUser", id, "10.0.0.2:5000"))
+
+	var keyNotFound *KeyNotFoundError
+	// чужую сессию не принять за свою
+	assert.ErrorAs(t, d.TouchSession(ctx, "otherUser", id, ""), &keyNotFound)
+
+	sessions, err := d.ListSessions(ctx, userID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "laptop", sessions[0].Device)
+
+	assert.NoError(t, d.DeleteSession(ctx, userID, id))
+	assert.ErrorAs(t, d.TouchSession(ctx, "sessionUser", id, ""), &keyNotFound)
+	assert.ErrorAs(t, d.DeleteSession(ctx, userID, id), &keyNotFound)
+}
+
+func TestDeviceMethods(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "deviceUser", "pass")
+	userID, err := d.GetUserID(ctx, "deviceUser")
+	assert.NoError(t, err)
+
+	// первое устройство подтверждается само
+	first, err := d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "first", Name: "laptop"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, types.DeviceApproved, first.Status)
+
+	var permissionDenied *PermissionDeniedError
+	_, err = d.LoginDevice(ctx, userID, types.NewDevice{}, false)
+	assert.ErrorAs(t, err, &permissionDenied)
+
+	second, err := d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "second", PollHash: "hash"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, types.DevicePending, second.Status)
+
+	var keyNotFound *KeyNotFoundError
+	_, err = d.PollDevice(ctx, second.ID, "other")
+	assert.ErrorAs(t, err, &keyNotFound)
+	poll, err := d.PollDevice(ctx, second.ID, "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, types.DevicePending, poll.Status)
+
+	devices, err := d.ListDevices(ctx, userID)
+	assert.NoError(t, err)
+	assert.Len(t, devices, 2)
+
+	assert.NoError(t, d.ApproveDevice(ctx, userID, second.ID, "escrow"))
+	poll, err = d.PollDevice(ctx, second.ID, "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, types.DevicePollResult{UserID: userID, Username: "deviceUser", Status: types.DeviceApproved, Escrow: "escrow"}, poll)
+	// ответ выдаётся один раз
+	_, err = d.PollDevice(ctx, second.ID, "hash")
+	assert.ErrorAs(t, err, &keyNotFound)
+	assert.ErrorAs(t, d.RejectDevice(ctx, userID, second.ID), &keyNotFound)
+
+	// забытое устройство завершает свои сессии
+	session, err := d.CreateSession(ctx, userID, types.NewSession{DeviceID: second.ID})
+	assert.NoError(t, err)
+	assert.NoError(t, d.DeleteDevice(ctx, userID, second.ID))
+	assert.ErrorAs(t, d.TouchSession(ctx, "deviceUser", session, ""), &keyNotFound)
+
+	third, err := d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "third", PollHash: "hash"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, types.DeviceApproved, third.Status)
+
+	// отклонённое устройство не может сразу запросить вход снова
+	rejected, err := d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "rejected", PollHash: "hash"}, false)
+	assert.NoError(t, err)
+	assert.NoError(t, d.RejectDevice(ctx, userID, rejected.ID))
+	var deviceRejected *DeviceRejectedError
+	_, err = d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "rejected", PollHash: "other"}, false)
+	assert.ErrorAs(t, err, &deviceRejected)
+
+	// число ожидающих запросов ограничено, повторный вход с ожидающего устройства не считается новым запросом
+	for i := 0; i < types.DevicePendingLimit; i++ {
+		_, err = d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: fmt.Sprintf("pending%d", i), PollHash: "hash"}, false)
+		assert.NoError(t, err)
+	}
+	_, err = d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "pending0", PollHash: "hash"}, false)
+	assert.NoError(t, err)
+	var requestsLimit *DeviceRequestsLimitError
+	_, err = d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "onemore", PollHash: "hash"}, false)
+	assert.ErrorAs(t, err, &requestsLimit)
+}
+
+func TestAccountDeletionMethods(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "deletedUser", "pass")
+	_ = d.CreateUser(ctx, "orgMember", "pass")
+	userID, err := d.GetUserID(ctx, "deletedUser")
+	assert.NoError(t, err)
+
+	assert.NoError(t, d.InsertText(ctx, userID, types.TextItem{Item: types.Item{Type: types.TypeText, Key: "note"}, Data: "secret"}))
+	_, err = d.CreateSession(ctx, userID, types.NewSession{})
+	assert.NoError(t, err)
+	assert.NoError(t, d.CreateOrg(ctx, userID, "deletedUserOrg", "key"))
+	memberships, err := d.ListMemberships(ctx, userID)
+	assert.NoError(t, err)
+	assert.Len(t, memberships, 1)
+	orgID := memberships[0].OrgID
+
+	// единственный владелец не может бросить участников
+	assert.NoError(t, d.AddMember(ctx, orgID, "orgMember", types.RoleAdmin, "key"))
+	var orgOwner *OrgOwnerError
+	_, err = d.ScheduleUserDeletion(ctx, userID, time.Now())
+	assert.ErrorAs(t, err, &orgOwner)
+
+	assert.NoError(t, d.SetMemberRole(ctx, orgID, "orgMember", types.RoleOwner))
+	deleteAt, err := d.ScheduleUserDeletion(ctx, userID, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	// повторный запрос не переносит срок
+	again, err := d.ScheduleUserDeletion(ctx, userID, time.Now().Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, deleteAt.Equal(again))
+
+	due, err := d.DueUserDeletions(ctx)
+	assert.NoError(t, err)
+	assert.NotContains(t, due, userID)
+
+	assert.NoError(t, d.CancelUserDeletion(ctx, userID))
+	var keyNotFound *KeyNotFoundError
+	assert.ErrorAs(t, d.CancelUserDeletion(ctx, userID), &keyNotFound)
+	scheduled, err := d.GetUserDeletion(ctx, userID)
+	assert.NoError(t, err)
+	assert.Nil(t, scheduled)
+
+	memberID, err := d.GetUserID(ctx, "orgMember")
+	assert.NoError(t, err)
+	assert.NoError(t, d.AppendAuditEvent(ctx, types.AuditEvent{UserID: &userID, Actor: "deletedUser", Action: types.AuditItemRead,
+		Key: "note", RemoteAddr: "10.0.0.1:1234"}))
+	assert.NoError(t, d.AppendAuditEvent(ctx, types.AuditEvent{UserID: &memberID, Actor: "deletedUser", Action: types.AuditItemRead,
+		Key: "shared", RemoteAddr: "10.0.0.1:1234"}))
+	own, err := d.ListAuditEvents(ctx, userID, 1, 0)
+	assert.NoError(t, err)
+	foreign, err := d.ListAuditEvents(ctx, memberID, 1, 0)
+	assert.NoError(t, err)
+
+	assert.NoError(t, d.DeleteUser(ctx, userID))
+	var userNotFound *UserNotFoundError
+	_, err = d.GetUserID(ctx, "deletedUser")
+	assert.ErrorAs(t, err, &userNotFound)
+	assert.ErrorAs(t, d.DeleteUser(ctx, userID), &userNotFound)
+
+	// персональные данные стёрты из журнала аудита, цепочка по-прежнему сходится
+	var actor, key, addr string
+	var owner *int
+	assert.NoError(t, d.pool.QueryRow(ctx, `SELECT user_id, actor, item_key, remote_addr FROM audit_event WHERE id = $1`,
+		own[0].ID).Scan(&owner, &actor, &key, &addr))
+	assert.Nil(t, owner)
+	assert.Equal(t, []string{"", "", ""}, []string{actor, key, addr})
+	assert.NoError(t, d.pool.QueryRow(ctx, `SELECT user_id, actor, item_key, remote_addr FROM audit_event WHERE id = $1`,
+		foreign[0].ID).Scan(&owner, &actor, &key, &addr))
+	assert.Equal(t, &memberID, owner)
+	assert.Equal(t, []string{"", "shared", ""}, []string{actor, key, addr})
+	_, err = d.VerifyAuditChain(ctx)
+	assert.NoError(t, err)
+	var items int
+	assert.NoError(t, d.pool.QueryRow(ctx, `SELECT count(*) FROM item WHERE user_id = $1`, userID).Scan(&items))
+	assert.Equal(t, 0, items)
+
+	members, err := d.ListMembers(ctx, orgID)
+	assert.NoError(t, err)
+	assert.Len(t, members, 1)
+	assert.Equal(t, types.RoleOwner, members[0].Role)
+}
+
+func TestAdminMethods(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "adminTarget", "pass")
+	userID, err := d.GetUserID(ctx, "adminTarget")
+	assert.NoError(t, err)
+	assert.NoError(t, d.InsertText(ctx, userID, types.TextItem{Item: types.Item{Type: types.TypeText, Key: "note"}, Data: "secret"}))
+	sessionID, err := d.CreateSession(ctx, userID, types.NewSession{})
+	assert.NoError(t, err)
+
+	users, err := d.AdminListUsers(ctx)
+	assert.NoError(t, err)
+	var found *types.AdminUser
+	for i := range users {
+		if users[i].ID == userID {
+			found = &users[i]
+		}
+	}
+	assert.NotNil(t, found)
+	assert.Equal(t, 1, found.Items)
+	assert.Equal(t, int64(len("secret")), found.StorageBytes)
+	assert.Equal(t, 1, found.Sessions)
+
+	stats, err := d.AdminStats(ctx)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, stats.Users, 1)
+
+	// отключённый пользователь теряет сессии и не входит
+	assert.NoError(t, d.SetUserDisabled(ctx, userID, true))
+	var keyNotFound *KeyNotFoundError
+	assert.ErrorAs(t, d.TouchSession(ctx, "adminTarget", sessionID, ""), &keyNotFound)
+	var userDisabled *UserDisabledError
+	_, err = d.LoginDevice(ctx, userID, types.NewDevice{}, false)
+	assert.ErrorAs(t, err, &userDisabled)
+
+	assert.NoError(t, d.SetUserDisabled(ctx, userID, false))
+	_, err = d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "adminTargetKey"}, false)
+	assert.NoError(t, err)
+	_, err = d.CreateSession(ctx, userID, types.NewSession{})
+	assert.NoError(t, err)
+
+	sessions, err := d.DeleteUserSessions(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), sessions)
+	devices, err := d.ResetUserDevices(ctx, userID, "enrollHash", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), devices)
+
+	// после сброса одного пароля мало: первое устройство предъявляет код администратора, и только один раз
+	var enrollmentRequired *EnrollmentRequiredError
+	_, err = d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "adminTargetKey"}, false)
+	assert.ErrorAs(t, err, &enrollmentRequired)
+	_, err = d.LoginDevice(ctx, userID, types.NewDevice{}, false)
+	assert.ErrorAs(t, err, &enrollmentRequired)
+	_, err = d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "adminTargetKey", EnrollmentHash: "wrong"}, false)
+	assert.ErrorAs(t, err, &enrollmentRequired)
+	login, err := d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "adminTargetKey", EnrollmentHash: "enrollHash"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, types.DeviceApproved, login.Status)
+	login, err = d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "otherKey", EnrollmentHash: "enrollHash", PollHash: "hash"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, types.DevicePending, login.Status)
+
+	// истёкший код не подходит
+	_, err = d.ResetUserDevices(ctx, userID, "expiredHash", time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	_, err = d.LoginDevice(ctx, userID, types.NewDevice{PublicKey: "adminTargetKey", EnrollmentHash: "expiredHash"}, false)
+	assert.ErrorAs(t, err, &enrollmentRequired)
+
+	var userNotFound *UserNotFoundError
+	assert.ErrorAs(t, d.SetUserDisabled(ctx, -1, true), &userNotFound)
+}
+
+func TestInviteMethods(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	id, err := d.CreateInvite(ctx, "inviteHash", 1, time.Now().Add(time.Hour), "for bob")
+	assert.NoError(t, err)
+	expired, err := d.CreateInvite(ctx, "expiredHash", 1, time.Now().Add(-time.Hour), "")
+	assert.NoError(t, err)
+
+	invites, err := d.ListInvites(ctx)
+	assert.NoError(t, err)
+	ids := make([]int, 0, len(invites))
+	for _, i := range invites {
+		ids = append(ids, i.ID)
+	}
+	assert.Contains(t, ids, id)
+	assert.NotContains(t, ids, expired)
+
+	var inviteInvalid *InviteInvalidError
+	assert.ErrorAs(t, d.CreateUserWithInvite(ctx, "invitedUser", "pass", "expiredHash"), &inviteInvalid)
+	assert.ErrorAs(t, d.CreateUserWithInvite(ctx, "invitedUser", "pass", "unknownHash"), &inviteInvalid)
+
+	// занятый логин не расходует приглашение
+	_ = d.CreateUser(ctx, "takenUser", "pass")
+	var userExists *UserExistsError
+	assert.ErrorAs(t, d.CreateUserWithInvite(ctx, "takenUser", "pass", "inviteHash"), &userExists)
+	// логин, отличающийся от занятого только регистром, тоже занят
+	assert.ErrorAs(t, d.CreateUserWithInvite(ctx, "takenuser", "pass", "inviteHash"), &userExists)
+	assert.ErrorAs(t, d.CreateUser(ctx, "takenuser", "pass"), &userExists)
+
+	assert.NoError(t, d.CreateUserWithInvite(ctx, "invitedUser", "pass", "inviteHash"))
+	_, err = d.GetUserID(ctx, "invitedUser")
+	assert.NoError(t, err)
+	assert.ErrorAs(t, d.CreateUserWithInvite(ctx, "invitedUser2", "pass", "inviteHash"), &inviteInvalid)
+
+	assert.NoError(t, d.DeleteInvite(ctx, expired))
+	var keyNotFound *KeyNotFoundError
+	assert.ErrorAs(t, d.DeleteInvite(ctx, expired), &keyNotFound)
+}
+
+func TestOrgCollections(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "orgOwner", "pass")
+	_ = d.CreateUser(ctx, "orgMember", "pass")
+	ownerID, err := d.GetUserID(ctx, "orgOwner")
+	assert.NoError(t, err)
+	memberID, err := d.GetUserID(ctx, "orgMember")
+	assert.NoError(t, err)
+
+	assert.NoError(t, d.CreateOrg(ctx, ownerID, "collectionsOrg", "ownerKey"))
+	owner, err := d.GetMembership(ctx, ownerID, "collectionsOrg")
+	assert.NoError(t, err)
+	assert.Nil(t, owner.AllowedCollections())
+
+	var keyNotFound *KeyNotFoundError
+	assert.ErrorAs(t, d.AcceptInvite(ctx, owner.OrgID, memberID), &keyNotFound)
+
+	assert.NoError(t, d.AddMember(ctx, owner.OrgID, "orgMember", types.RoleMember, "memberKey"))
+	assert.NoError(t, d.AcceptInvite(ctx, owner.OrgID, memberID))
+	assert.NoError(t, d.SetMemberCollections(ctx, owner.OrgID, "orgMember", []string{"ops"}))
+	member, err := d.GetMembership(ctx, memberID, "collectionsOrg")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ops"}, member.Collections)
+
+	for _, item := range []types.OrgItem{{Key: "db", Collection: "ops"}, {Key: "hr", Collection: "hr"}} {
+		item.Type, item.Data, item.KeyVersion = types.TypeText, "enc", 1
+		assert.NoError(t, d.PutOrgItem(ctx, owner.OrgID, item, nil))
+	}
+
+	items, err := d.ListOrgItems(ctx, owner.OrgID, "", member.AllowedCollections())
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "db", items[0].Key)
+
+	_, err = d.GetOrgItem(ctx, owner.OrgID, "hr", member.AllowedCollections())
+	assert.ErrorAs(t, err, &keyNotFound)
+	var permissionDenied *PermissionDeniedError
+	err = d.PutOrgItem(ctx, owner.OrgID, types.OrgItem{Key: "hr", Collection: "ops", Type: types.TypeText, Data: "enc", KeyVersion: 1}, member.AllowedCollections())
+	assert.ErrorAs(t, err, &permissionDenied)
+	assert.ErrorAs(t, d.DeleteOrgItem(ctx, owner.OrgID, "hr", member.AllowedCollections()), &keyNotFound)
+
+	// подтвердивший членство участник знает ключ и выходит только с ротацией, даже ограниченный коллекциями
+	var incomplete *IncompleteRotationError
+	assert.ErrorAs(t, d.LeaveOrg(ctx, owner.OrgID, memberID), &incomplete)
+	assert.NoError(t, d.SetMemberCollections(ctx, owner.OrgID, "orgMember", nil))
+	assert.ErrorAs(t, d.LeaveOrg(ctx, owner.OrgID, memberID), &incomplete)
+	assert.ErrorAs(t, d.LeaveOrg(ctx, owner.OrgID, ownerID), &incomplete)
+
+	// приглашённый, не подтвердивший членство, отказывается без ротации
+	_ = d.CreateUser(ctx, "orgInvitee", "pass")
+	inviteeID, err := d.GetUserID(ctx, "orgInvitee")
+	assert.NoError(t, err)
+	assert.NoError(t, d.AddMember(ctx, owner.OrgID, "orgInvitee", types.RoleMember, "inviteeKey"))
+	assert.NoError(t, d.LeaveOrg(ctx, owner.OrgID, inviteeID))
+
+	assert.NoError(t, d.DeleteOrg(ctx, owner.OrgID))
+}
+
+func TestSendPasswordAttempts(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "sendUser", "pass")
+	userID, err := d.GetUserID(ctx, "sendUser")
+	assert.NoError(t, err)
+
+	hash := "hash"
+	send := types.NewSend{Data: "enc", MaxViews: 3, ExpiresAt: time.Now().Add(time.Hour)}
+	assert.NoError(t, d.CreateSend(ctx, userID, "guessed", send, &hash))
+
+	wrong := func(*string) bool { return false }
+	var permissionDenied *PermissionDeniedError
+	for range types.SendPasswordAttempts {
+		_, err = d.OpenSend(ctx, "guessed", wrong)
+		assert.ErrorAs(t, err, &permissionDenied)
+	}
+
+	// после исчерпания попыток ссылка удалена, верный пароль уже не поможет
+	var keyNotFound *KeyNotFoundError
+	_, err = d.OpenSend(ctx, "guessed", func(*string) bool { return true })
+	assert.ErrorAs(t, err, &keyNotFound)
+}
+
+func TestRecoveryLockout(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "recoverUser", "pass")
+	userID, err := d.GetUserID(ctx, "recoverUser")
+	assert.NoError(t, err)
+	assert.NoError(t, d.SetVaultKey(ctx, userID, "wrapped"))
+	assert.NoError(t, d.SetRecoveryHash(ctx, userID, "hash"))
+
+	wrong := func(string) bool { return false }
+	var permissionDenied *PermissionDeniedError
+	for range types.RecoveryAttempts {
+		err = d.RecoverAccount(ctx, "recoverUser", wrong, "newpass", "rewrapped")
+		assert.ErrorAs(t, err, &permissionDenied)
+	}
+
+	// после исчерпания попыток даже верный ключ не принимается до конца блокировки
+	var recoveryLocked *RecoveryLockedError
+	err = d.RecoverAccount(ctx, "recoverUser", func(string) bool { return true }, "newpass", "rewrapped")
+	assert.ErrorAs(t, err, &recoveryLocked)
+
+	key, err := d.GetVaultKey(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, "wrapped", key.Wrapped)
+
+	// ключ хранилища не перезаписывается
+	var keyExists *KeyExistsError
+	assert.ErrorAs(t, d.SetVaultKey(ctx, userID, "replaced"), &keyExists)
+}
+
+func TestRecoverAccountEndsSessions(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "recoverSessionsUser", "pass")
+	userID, err := d.GetUserID(ctx, "recoverSessionsUser")
+	assert.NoError(t, err)
+	assert.NoError(t, d.SetVaultKey(ctx, userID, "wrapped"))
+	assert.NoError(t, d.SetRecoveryHash(ctx, userID, "hash"))
+	_, err = d.CreateSession(ctx, userID, types.NewSession{Device: "stolen laptop"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, d.RecoverAccount(ctx, "recoverSessionsUser", func(string) bool { return true }, "newpass", "rewrapped"))
+	sessions, err := d.ListSessions(ctx, userID)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+	key, err := d.GetVaultKey(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, "rewrapped", key.Wrapped)
+}
+
+func TestSecretChangedAt(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "secretChangedUser", "pass")
+	userID, err := d.GetUserID(ctx, "secretChangedUser")
+	assert.NoError(t, err)
+	assert.NoError(t, d.InsertText(ctx, userID, types.TextItem{Item: types.Item{Type: types.TypeText, Key: "note"}, Data: "old"}))
+	created, err := d.GetItem(ctx, userID, "note")
+	assert.NoError(t, err)
+	assert.NotNil(t, created.SecretChangedAt)
+
+	// правка описания и вложения не считаются сменой секрета
+	assert.NoError(t, d.UpdateText(ctx, userID, types.TextItem{Item: types.Item{Type: types.TypeText, Key: "note", Info: "new info"}, Data: "old"}))
+	_, err = d.InsertAttachment(ctx, userID, "note", types.Attachment{Name: "a.txt", MimeType: "text/plain", Encrypted: true}, []byte("a"))
+	assert.NoError(t, err)
+	item, err := d.GetItem(ctx, userID, "note")
+	assert.NoError(t, err)
+	assert.Equal(t, *created.SecretChangedAt, *item.SecretChangedAt)
+	assert.True(t, item.UpdatedAt.After(*created.UpdatedAt))
+
+	assert.NoError(t, d.UpdateText(ctx, userID, types.TextItem{Item: types.Item{Type: types.TypeText, Key: "note", Info: "new info"}, Data: "new"}))
+	item, err = d.GetItem(ctx, userID, "note")
+	assert.NoError(t, err)
+	assert.True(t, item.SecretChangedAt.After(*created.SecretChangedAt))
+	changed := *item.SecretChangedAt
+
+	// перешифрование новым ключом хранилища меняет данные, но не секрет
+	assert.NoError(t, d.SetVaultKey(ctx, userID, "password"))
+	assert.NoError(t, d.MigrateVaultKey(ctx, userID, types.VaultKeyMigration{Previous: "password", Wrapped: "new", LegacyKey: "legacy"}))
+	assert.NoError(t, d.UpdateText(ctx, userID, types.TextItem{Item: types.Item{Type: types.TypeText, Key: "note", Info: "new info"}, Data: "reencrypted"}))
+	item, err = d.GetItem(ctx, userID, "note")
+	assert.NoError(t, err)
+	assert.Equal(t, changed, *item.SecretChangedAt)
+}
+
+func TestMigrateVaultKey(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDatabase(DBDSN)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	_ = d.CreateUser(ctx, "legacyUser", "pass")
+	userID, err := d.GetUserID(ctx, "legacyUser")
+	assert.NoError(t, err)
+	assert.NoError(t, d.InsertText(ctx, userID, types.TextItem{Item: types.Item{Type: types.TypeText, Key: "note"}, Data: "old"}))
+	attachment := types.Attachment{Name: "a.txt", MimeType: "text/plain", Encrypted: true}
+	attachmentID, err := d.InsertAttachment(ctx, userID, "note", attachment, []byte("old"))
+	assert.NoError(t, err)
+	assert.NoError(t, d.SetVaultKey(ctx, userID, "password"))
+
+	var keyExists *KeyExistsError
+	err = d.MigrateVaultKey(ctx, userID, types.VaultKeyMigration{Previous: "other", Wrapped: "new", LegacyKey: "legacy"})
+	assert.ErrorAs(t, err, &keyExists)
+
+	assert.NoError(t, d.MigrateVaultKey(ctx, userID, types.VaultKeyMigration{Previous: "password", Wrapped: "new", LegacyKey: "legacy"}))
+	key, err := d.GetVaultKey(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.VaultKey{Wrapped: "new", LegacyKey: "legacy"}, *key)
+
+	// пока старый ключ не удалён, повторная замена запрещена
+	var incomplete *IncompleteRotationError
+	err = d.MigrateVaultKey(ctx, userID, types.VaultKeyMigration{Previous: "new", Wrapped: "newer", LegacyKey: "new"})
+	assert.ErrorAs(t, err, &incomplete)
+
+	legacy, err := d.GetLegacyVault(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"note"}, legacy.Items)
+	assert.Equal(t, []types.LegacyAttachment{{Key: "note", ID: attachmentID}}, legacy.Attachments)
+	assert.ErrorAs(t, d.FinishVaultKeyMigration(ctx, userID), &incomplete)
+
+	assert.NoError(t, d.ReplaceAttachment(ctx, userID, "note", attachmentID, attachment, []byte("new")))
+	assert.NoError(t, d.UpdateText(ctx, userID, types.TextItem{Item: types.Item{Type: types.TypeText, Key: "note"}, Data: "new"}))
+	legacy, err = d.GetLegacyVault(ctx, userID)
+	assert.NoError(t, err)
+	assert.Empty(t, legacy.Items)
+	assert.Empty(t, legacy.Attachments)
+
+	assert.NoError(t, d.FinishVaultKeyMigration(ctx, userID))
+	key, err = d.GetVaultKey(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.VaultKey{Wrapped: "new"}, *key)
 }
@@ -0,0 +1,227 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// LoginDevice решает, можно ли войти с устройства после проверки пароля. Известное подтверждённое устройство
+// входит сразу. Первое устройство пользователя и устройство, с которого восстановлен доступ (trust),
+// подтверждаются автоматически, любое другое ждёт подтверждения. Без ключа устройства (старый клиент)
+// войти можно, только пока у пользователя нет подтверждённых устройств, иначе - PermissionDeniedError.
+// После сброса устройств администратором первое устройство должно предъявить его код - EnrollmentRequiredError.
+// Отклонённое устройство не может отправить новый запрос, пока не истёк старый - DeviceRejectedError;
+// больше types.DevicePendingLimit ожидающих запросов - DeviceRequestsLimitError.
+// Отключённый пользователь не входит ни с какого устройства - UserDisabledError
+func (d *Database) LoginDevice(ctx context.Context, userID int, device types.NewDevice, trust bool) (types.DeviceLogin, error) {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return types.DeviceLogin{}, fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// одновременные входы одного пользователя не должны подтвердить два "первых" устройства
+	var (
+		disabled        bool
+		enrollmentHash  string
+		enrollmentValid bool
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT disabled, device_enrollment_hash, COALESCE(device_enrollment_expires > now(), false)
+		FROM auth_user WHERE id = $1 FOR UPDATE
+	`, userID).Scan(&disabled, &enrollmentHash, &enrollmentValid)
+	if err != nil {
+		return types.DeviceLogin{}, fmt.Errorf("%w", err)
+	}
+	if disabled {
+		return types.DeviceLogin{}, &UserDisabledError{Username: strconv.Itoa(userID)}
+	}
+	var approved int
+	err = tx.QueryRow(ctx, `SELECT count(*) FROM device WHERE user_id = $1 AND status = 'approved'`, userID).Scan(&approved)
+	if err != nil {
+		return types.DeviceLogin{}, fmt.Errorf("%w", err)
+	}
+
+	// после сброса одного пароля мало: первое устройство подтверждает код администратора или ключ восстановления
+	if enrollmentHash != "" && approved == 0 && !trust {
+		if device.PublicKey == "" || !enrollmentValid || device.EnrollmentHash != enrollmentHash {
+			return types.DeviceLogin{}, &EnrollmentRequiredError{}
+		}
+	}
+
+	if device.PublicKey == "" {
+		if approved > 0 {
+			return types.DeviceLogin{}, &PermissionDeniedError{Key: "device"}
+		}
+		return types.DeviceLogin{Status: types.DeviceApproved}, nil
+	}
+
+	login := types.DeviceLogin{}
+	var recent bool
+	err = tx.QueryRow(ctx, `
+		SELECT id, status, created_at > now() - $3::interval FROM device WHERE user_id = $1 AND public_key = $2
+	`, userID, device.PublicKey, types.DeviceRequestTimeout).Scan(&login.ID, &login.Status, &recent)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return types.DeviceLogin{}, fmt.Errorf("%w", err)
+	}
+	if login.Status == types.DeviceApproved {
+		_, err = tx.Exec(ctx, `UPDATE device SET name = $2, remote_addr = $3 WHERE id = $1`,
+			login.ID, device.Name, device.RemoteAddr)
+		if err != nil {
+			return types.DeviceLogin{}, fmt.Errorf("%w", err)
+		}
+		return login, tx.Commit(ctx)
+	}
+
+	login.Status = types.DevicePending
+	pollHash := device.PollHash
+	if trust || approved == 0 {
+		login.Status = types.DeviceApproved
+		pollHash = ""
+	} else {
+		// отказ действует, пока не истёк запрос: иначе каждый вход с паролем снова просил бы подтверждения
+		if login.Status == types.DeviceRejected && recent {
+			return types.DeviceLogin{}, &DeviceRejectedError{ID: login.ID}
+		}
+		var pending int
+		err = tx.QueryRow(ctx, `
+			SELECT count(*) FROM device
+			WHERE user_id = $1 AND public_key <> $2 AND status = 'pending' AND created_at > now() - $3::interval
+		`, userID, device.PublicKey, types.DeviceRequestTimeout).Scan(&pending)
+		if err != nil {
+			return types.DeviceLogin{}, fmt.Errorf("%w", err)
+		}
+		if pending >= types.DevicePendingLimit {
+			return types.DeviceLogin{}, &DeviceRequestsLimitError{Limit: types.DevicePendingLimit}
+		}
+	}
+	query := `
+		INSERT INTO device (user_id, public_key, name, remote_addr, status, poll_hash, approved_at)
+		VALUES ($1, $2, $3, $4, $5::text, $6, CASE WHEN $5::text = 'approved' THEN now() END)
+		ON CONFLICT (user_id, public_key) DO UPDATE SET name = EXCLUDED.name, remote_addr = EXCLUDED.remote_addr,
+			status = EXCLUDED.status, poll_hash = EXCLUDED.poll_hash, escrow = '', created_at = now(),
+			approved_at = EXCLUDED.approved_at
+		RETURNING id
+	`
+	err = tx.QueryRow(ctx, query, userID, device.PublicKey, device.Name, device.RemoteAddr, login.Status,
+		pollHash).Scan(&login.ID)
+	if err != nil {
+		return types.DeviceLogin{}, fmt.Errorf("%w", err)
+	}
+	// код одноразовый
+	if enrollmentHash != "" && login.Status == types.DeviceApproved {
+		_, err = tx.Exec(ctx, `
+			UPDATE auth_user SET device_enrollment_hash = '', device_enrollment_expires = NULL WHERE id = $1
+		`, userID)
+		if err != nil {
+			return types.DeviceLogin{}, fmt.Errorf("%w", err)
+		}
+	}
+	return login, tx.Commit(ctx)
+}
+
+// PollDevice состояние запроса на вход id для нового устройства, знающего секрет с хешем pollHash.
+// Ответ о подтверждении или отказе выдаётся один раз: секрет и переданный ключ хранилища после этого стираются.
+// Неизвестный, истёкший или уже полученный запрос - KeyNotFoundError
+func (d *Database) PollDevice(ctx context.Context, id int, pollHash string) (types.DevicePollResult, error) {
+	query := `
+		WITH d AS (
+			SELECT d.id, d.user_id, u.username, d.status, d.escrow FROM device d JOIN auth_user u ON u.id = d.user_id
+			WHERE d.id = $1 AND d.poll_hash = $2 AND d.poll_hash <> '' AND d.created_at > now() - $3::interval
+			AND NOT u.disabled
+		), done AS (
+			UPDATE device SET poll_hash = '', escrow = ''
+			WHERE id IN (SELECT id FROM d WHERE status <> 'pending')
+		)
+		SELECT user_id, username, status, escrow FROM d
+	`
+	var result types.DevicePollResult
+	err := d.pool.QueryRow(ctx, query, id, pollHash, types.DeviceRequestTimeout).Scan(
+		&result.UserID, &result.Username, &result.Status, &result.Escrow)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return result, &KeyNotFoundError{Key: strconv.Itoa(id)}
+	}
+	if err != nil {
+		return result, fmt.Errorf("%w", err)
+	}
+	return result, nil
+}
+
+// ApproveDevice подтверждает вход с нового устройства id. Escrow - ключ хранилища, зашифрованный ключом устройства
+func (d *Database) ApproveDevice(ctx context.Context, userID int, id int, escrow string) error {
+	query := `
+		UPDATE device SET status = 'approved', approved_at = now(), escrow = $3
+		WHERE id = $1 AND user_id = $2 AND status = 'pending' AND created_at > now() - $4::interval
+	`
+	return d.resolveDevice(ctx, query, userID, id, escrow, types.DeviceRequestTimeout)
+}
+
+// RejectDevice отклоняет вход с нового устройства id
+func (d *Database) RejectDevice(ctx context.Context, userID int, id int) error {
+	query := `
+		UPDATE device SET status = 'rejected'
+		WHERE id = $1 AND user_id = $2 AND status = 'pending' AND created_at > now() - $3::interval
+	`
+	return d.resolveDevice(ctx, query, userID, id, types.DeviceRequestTimeout)
+}
+
+func (d *Database) resolveDevice(ctx context.Context, query string, userID int, id int, args ...any) error {
+	tag, err := d.pool.Exec(ctx, query, append([]any{id, userID}, args...)...)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: strconv.Itoa(id)}
+	}
+	return nil
+}
+
+// ListDevices подтверждённые устройства пользователя и запросы на вход, ожидающие подтверждения
+func (d *Database) ListDevices(ctx context.Context, userID int) ([]types.Device, error) {
+	query := `
+		SELECT id, name, public_key, status, remote_addr, created_at, approved_at
+		FROM device WHERE user_id = $1
+		AND (status = 'approved' OR (status = 'pending' AND created_at > now() - $2::interval))
+		ORDER BY status, created_at DESC
+	`
+	rows, err := d.pool.Query(ctx, query, userID, types.DeviceRequestTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	devices, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.Device])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return devices, nil
+}
+
+// DeleteDevice забывает устройство пользователя: его сессии завершаются, следующий вход с него снова
+// потребует подтверждения
+func (d *Database) DeleteDevice(ctx context.Context, userID int, id int) error {
+	tag, err := d.pool.Exec(ctx, `DELETE FROM device WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: strconv.Itoa(id)}
+	}
+	return nil
+}
+
+// DeleteStaleDevices удаляет истёкшие и отклонённые запросы на вход и возвращает их число
+func (d *Database) DeleteStaleDevices(ctx context.Context) (int64, error) {
+	query := `DELETE FROM device WHERE status <> 'approved' AND created_at <= now() - $1::interval`
+	tag, err := d.pool.Exec(ctx, query, types.DeviceRequestTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+	return tag.RowsAffected(), nil
+}
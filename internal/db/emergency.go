@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// emergencyQuery выборка экстренного доступа в types.EmergencyAccess
+const emergencyQuery = `
+	SELECT e.id, g.username AS grantor, t.username AS grantee, e.wait_days, e.status::text AS status,
+		e.requested_at, e.rejected_at, e.requested_at + make_interval(days => e.wait_days) AS release_at
+	FROM emergency_access e
+	JOIN auth_user g ON g.id = e.grantor_id
+	JOIN auth_user t ON t.id = e.grantee_id`
+
+// emergencyTransitions допустимые переходы экстренного доступа: кто его совершает, из какого состояния
+// и что меняется в строке
+var emergencyTransitions = map[types.EmergencyStatus]struct {
+	column string
+	from   types.EmergencyStatus
+	set    string
+}{
+	types.EmergencyAccepted:  {"grantee_id", types.EmergencyInvited, `status = 'accepted'`},
+	types.EmergencyRequested: {"grantee_id", types.EmergencyAccepted, `status = 'requested', requested_at = now(), rejected_at = NULL`},
+	types.EmergencyGranted:   {"grantor_id", types.EmergencyRequested, `status = 'granted'`},
+}
+
+// CreateEmergencyAccess назначает пользователя granteeID доверенным лицом пользователя grantorID.
+// escrow - ключ хранилища, зашифрованный открытым ключом доверенного лица
+func (d *Database) CreateEmergencyAccess(ctx context.Context, grantorID int, granteeID int, waitDays int, escrow string) error {
+	query := `
+		INSERT INTO emergency_access (grantor_id, grantee_id, wait_days, escrow)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := d.pool.Exec(ctx, query, grantorID, granteeID, waitDays, escrow)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
+			return fmt.Errorf("%w", &KeyExistsError{Key: strconv.Itoa(granteeID)})
+		}
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// ListEmergencyContacts доверенные лица, назначенные пользователем
+func (d *Database) ListEmergencyContacts(ctx context.Context, grantorID int) ([]types.EmergencyAccess, error) {
+	return d.listEmergencyAccess(ctx, emergencyQuery+` WHERE e.grantor_id = $1 ORDER BY e.id`, grantorID)
+}
+
+// ListEmergencyGrants хранилища, для которых пользователь назначен доверенным лицом
+func (d *Database) ListEmergencyGrants(ctx context.Context, granteeID int) ([]types.EmergencyAccess, error) {
+	return d.listEmergencyAccess(ctx, emergencyQuery+` WHERE e.grantee_id = $1 ORDER BY e.id`, granteeID)
+}
+
+func (d *Database) listEmergencyAccess(ctx context.Context, query string, userID int) ([]types.EmergencyAccess, error) {
+	err := d.releaseEmergencyAccess(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	access, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.EmergencyAccess])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return access, nil
+}
+
+// MoveEmergencyAccess переводит экстренный доступ id в состояние to: доверенное лицо соглашается
+// или запрашивает доступ, владелец одобряет запрос досрочно. userID - тот, кто совершает переход
+func (d *Database) MoveEmergencyAccess(ctx context.Context, id int, userID int, to types.EmergencyStatus) error {
+	transition, ok := emergencyTransitions[to]
+	if !ok {
+		return fmt.Errorf("unknown emergency access status %s", to)
+	}
+	return d.moveEmergencyAccess(ctx, id, transition.column, userID, transition.from, transition.set)
+}
+
+// RejectEmergencyAccess владелец отклоняет запрос доступа, пока не истёк период ожидания.
+// Доверенное лицо может запросить доступ снова
+func (d *Database) RejectEmergencyAccess(ctx context.Context, id int, grantorID int) error {
+	return d.moveEmergencyAccess(ctx, id, "grantor_id", grantorID, types.EmergencyRequested,
+		`status = 'accepted', requested_at = NULL, rejected_at = now()`)
+}
+
+// DeleteEmergencyAccess удаляет экстренный доступ: владелец отзывает его, доверенное лицо отказывается
+func (d *Database) DeleteEmergencyAccess(ctx context.Context, id int, userID int) error {
+	tag, err := d.pool.Exec(ctx, `DELETE FROM emergency_access WHERE id = $1 AND (grantor_id = $2 OR grantee_id = $2)`, id, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: strconv.Itoa(id)}
+	}
+	return nil
+}
+
+// GetEmergencyGrant возвращает владельца хранилища и зашифрованный для доверенного лица ключ,
+// если экстренный доступ id выдан пользователю granteeID
+func (d *Database) GetEmergencyGrant(ctx context.Context, id int, granteeID int) (int, string, error) {
+	err := d.releaseEmergencyAccess(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	var grantorID int
+	var escrow string
+	var status types.EmergencyStatus
+	query := `SELECT grantor_id, escrow, status::text FROM emergency_access WHERE id = $1 AND grantee_id = $2`
+	err = d.pool.QueryRow(ctx, query, id, granteeID).Scan(&grantorID, &escrow, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, "", &KeyNotFoundError{Key: strconv.Itoa(id)}
+		}
+		return 0, "", fmt.Errorf("%w", err)
+	}
+	if status != types.EmergencyGranted {
+		return 0, "", &EmergencyStatusError{Status: string(status)}
+	}
+	return grantorID, escrow, nil
+}
+
+// releaseEmergencyAccess выдаёт доступ по запросам, период ожидания которых истёк
+func (d *Database) releaseEmergencyAccess(ctx context.Context) error {
+	query := `
+		UPDATE emergency_access SET status = 'granted', updated_at = now()
+		WHERE status = 'requested' AND requested_at + make_interval(days => wait_days) <= now()
+	`
+	_, err := d.pool.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// moveEmergencyAccess меняет строку экстренного доступа, если её участник в колонке column - userID
+// и она в состоянии from. Перед проверкой истёкшие запросы выдаются, поэтому отклонить запрос
+// после окончания периода ожидания нельзя
+func (d *Database) moveEmergencyAccess(ctx context.Context, id int, column string, userID int, from types.EmergencyStatus, set string) error {
+	err := d.releaseEmergencyAccess(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var status types.EmergencyStatus
+	query := `SELECT status::text FROM emergency_access WHERE id = $1 AND ` + column + ` = $2 FOR UPDATE`
+	err = tx.QueryRow(ctx, query, id, userID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &KeyNotFoundError{Key: strconv.Itoa(id)}
+		}
+		return fmt.Errorf("%w", err)
+	}
+	if status != from {
+		return &EmergencyStatusError{Status: string(status)}
+	}
+	_, err = tx.Exec(ctx, `UPDATE emergency_access SET `+set+`, updated_at = now() WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return tx.Commit(ctx)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"time"
 )
 
 // UserExistsError ошибка "такой пользовтель уже существует"
@@ -43,3 +44,123 @@ type KeyNotFoundError struct {
 func (e *KeyNotFoundError) Error() string {
 	return fmt.Sprintf("Key %s not found", e.Key)
 }
+
+// PermissionDeniedError ошибка при попытке изменить данные без прав на это
+type PermissionDeniedError struct {
+	Key string
+}
+
+// Error стандартный метод интерфейса error
+func (e *PermissionDeniedError) Error() string {
+	return fmt.Sprintf("Permission denied for %s", e.Key)
+}
+
+// KeyVersionError ошибка при попытке сохранить данные, зашифрованные устаревшим ключом организации
+type KeyVersionError struct {
+	Current int
+	Got     int
+}
+
+// Error стандартный метод интерфейса error
+func (e *KeyVersionError) Error() string {
+	return fmt.Sprintf("Organization key version is %d, got %d", e.Current, e.Got)
+}
+
+// IncompleteRotationError ротация ключа организации покрывает не всех участников или не все записи
+type IncompleteRotationError struct {
+	Reason string
+}
+
+// Error стандартный метод интерфейса error
+func (e *IncompleteRotationError) Error() string {
+	return fmt.Sprintf("Incomplete key rotation: %s", e.Reason)
+}
+
+// EmergencyStatusError переход экстренного доступа невозможен из текущего состояния
+type EmergencyStatusError struct {
+	Status string
+}
+
+// Error стандартный метод интерфейса error
+func (e *EmergencyStatusError) Error() string {
+	return fmt.Sprintf("Not allowed while emergency access is %s", e.Status)
+}
+
+// AuditChainError цепочка журнала аудита нарушена на событии ID
+type AuditChainError struct {
+	ID     int64
+	Reason string
+}
+
+// Error стандартный метод интерфейса error
+func (e *AuditChainError) Error() string {
+	return fmt.Sprintf("Audit chain broken at event %d: %s", e.ID, e.Reason)
+}
+
+// OrgOwnerError пользователь - единственный владелец организации, в которой есть другие участники
+type OrgOwnerError struct {
+	Org string
+}
+
+// Error стандартный метод интерфейса error
+func (e *OrgOwnerError) Error() string {
+	return fmt.Sprintf("User is the only owner of organization %s", e.Org)
+}
+
+// UserDisabledError учётная запись отключена администратором
+type UserDisabledError struct {
+	Username string
+}
+
+// Error стандартный метод интерфейса error
+func (e *UserDisabledError) Error() string {
+	return fmt.Sprintf("User %s is disabled", e.Username)
+}
+
+// InviteInvalidError приглашение не найдено, истекло или уже использовано нужное число раз
+type InviteInvalidError struct{}
+
+// Error стандартный метод интерфейса error
+func (e *InviteInvalidError) Error() string {
+	return "Invite is invalid, expired or used up"
+}
+
+// RecoveryLockedError восстановление доступа временно заблокировано после неверных попыток
+type RecoveryLockedError struct {
+	Username string
+	Until    time.Time
+}
+
+// Error стандартный метод интерфейса error
+func (e *RecoveryLockedError) Error() string {
+	return fmt.Sprintf("Recovery for %s is locked until %s", e.Username, e.Until.Format(time.RFC3339))
+}
+
+// DeviceRejectedError вход с этого устройства недавно отклонён, новый запрос можно отправить после истечения старого
+type DeviceRejectedError struct {
+	ID int
+}
+
+// Error стандартный метод интерфейса error
+func (e *DeviceRejectedError) Error() string {
+	return fmt.Sprintf("Login from device %d was rejected", e.ID)
+}
+
+// DeviceRequestsLimitError слишком много запросов на вход с новых устройств ждут подтверждения
+type DeviceRequestsLimitError struct {
+	Limit int
+}
+
+// Error стандартный метод интерфейса error
+func (e *DeviceRequestsLimitError) Error() string {
+	return fmt.Sprintf("More than %d device logins are waiting for approval", e.Limit)
+}
+
+// EnrollmentRequiredError устройства пользователя сброшены администратором: первое устройство после сброса
+// подтверждается только с действующим кодом администратора
+type EnrollmentRequiredError struct{}
+
+// Error стандартный метод интерфейса error
+func (e *EnrollmentRequiredError) Error() string {
+	return "Device enrollment code is missing, wrong or expired"
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// CreateInvite сохраняет приглашение с хешем кода codeHash и возвращает его id
+func (d *Database) CreateInvite(ctx context.Context, codeHash string, maxUses int, expiresAt time.Time, note string) (int, error) {
+	query := `
+		INSERT INTO invite (code_hash, max_uses, expires_at, note)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	var id int
+	err := d.pool.QueryRow(ctx, query, codeHash, maxUses, expiresAt, note).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+	return id, nil
+}
+
+// ListInvites действующие приглашения: не истёкшие и использованные меньше разрешённого числа раз
+func (d *Database) ListInvites(ctx context.Context) ([]types.Invite, error) {
+	query := `
+		SELECT id, note, max_uses, uses, created_at, expires_at
+		FROM invite WHERE expires_at > now() AND uses < max_uses
+		ORDER BY created_at DESC
+	`
+	rows, err := d.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	invites, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.Invite])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return invites, nil
+}
+
+// DeleteInvite отзывает приглашение id
+func (d *Database) DeleteInvite(ctx context.Context, id int) error {
+	tag, err := d.pool.Exec(ctx, `DELETE FROM invite WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: strconv.Itoa(id)}
+	}
+	return nil
+}
+
+// CreateUserWithInvite создаёт пользователя по приглашению с хешем кода codeHash. Приглашение засчитывается,
+// только если пользователь создан. Неизвестное, истёкшее или исчерпанное приглашение - InviteInvalidError
+func (d *Database) CreateUserWithInvite(ctx context.Context, username string, password string, codeHash string) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE invite SET uses = uses + 1
+		WHERE code_hash = $1 AND expires_at > now() AND uses < max_uses
+	`, codeHash)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &InviteInvalidError{}
+	}
+
+	tag, err = tx.Exec(ctx, insertUserQuery, username, password)
+	err = userInsertError(tag, err, username)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -3,6 +3,8 @@ package db
 import (
 	"embed"
 	"errors"
+	"fmt"
+	"os"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -32,3 +34,71 @@ func Migrate(dsn string) error {
 	}
 	return nil
 }
+
+// MigrateTo применяет миграции к БД до версии version включительно
+func MigrateTo(dsn string, version uint) error {
+	d, err := iofs.New(fs, "migrations")
+	if err != nil {
+		return err
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", d, dsn)
+	if err != nil {
+		return err
+	}
+	if err := m.Migrate(version); err != nil {
+		if errors.Is(err, migrate.ErrNoChange) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// LatestVersion номер последней миграции, известной этой сборке сервера
+func LatestVersion() (uint, error) {
+	d, err := iofs.New(fs, "migrations")
+	if err != nil {
+		return 0, err
+	}
+	defer d.Close()
+
+	version, err := d.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := d.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// CurrentVersion версия схемы БД, 0 если миграции ещё не применялись
+func CurrentVersion(dsn string) (uint, error) {
+	d, err := iofs.New(fs, "migrations")
+	if err != nil {
+		return 0, err
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", d, dsn)
+	if err != nil {
+		return 0, err
+	}
+	defer m.Close()
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("migration %d is dirty", version)
+	}
+	return version, nil
+}
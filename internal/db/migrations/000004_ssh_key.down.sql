@@ -0,0 +1,6 @@
+BEGIN;
+
+DELETE FROM item WHERE item_type = 'ssh_key';
+DROP TABLE ssh_key;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TYPE item_type ADD VALUE IF NOT EXISTS 'ssh_key';
+
+CREATE TABLE ssh_key (id SERIAL PRIMARY KEY, item_id BIGINT, private_key TEXT, public_key TEXT, passphrase TEXT, comment TEXT,
+    CONSTRAINT fk_ssh_key_item_id
+    FOREIGN KEY(item_id) 
+    REFERENCES item(id)
+    ON DELETE CASCADE);
+
+CREATE INDEX ssh_key_item_idx ON ssh_key(item_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE logopass DROP COLUMN totp;
+
+DELETE FROM item WHERE item_type = 'totp';
+DROP TABLE totp;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+ALTER TYPE item_type ADD VALUE IF NOT EXISTS 'totp';
+
+CREATE TABLE totp (id SERIAL PRIMARY KEY, item_id BIGINT, secret TEXT,
+    CONSTRAINT fk_totp_item_id
+    FOREIGN KEY(item_id) 
+    REFERENCES item(id)
+    ON DELETE CASCADE);
+
+CREATE INDEX totp_item_idx ON totp(item_id);
+
+ALTER TABLE logopass ADD COLUMN totp TEXT NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+CREATE TABLE attachment (id SERIAL PRIMARY KEY, item_id BIGINT, name VARCHAR(255), mime_type VARCHAR(255), data bytea,
+    CONSTRAINT fk_attachment_item_id
+    FOREIGN KEY(item_id) 
+    REFERENCES item(id)
+    ON DELETE CASCADE);
+
+CREATE UNIQUE INDEX attachment_item_name_idx ON attachment(item_id, name);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE binary_data DROP COLUMN file_name, DROP COLUMN content_type, DROP COLUMN size, DROP COLUMN sha256;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE binary_data ADD COLUMN file_name TEXT, ADD COLUMN content_type VARCHAR(255), ADD COLUMN size BIGINT, ADD COLUMN sha256 TEXT;
+
+COMMIT;
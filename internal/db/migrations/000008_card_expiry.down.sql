@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE credit_card DROP COLUMN valid_month, DROP COLUMN valid_year;
+
+COMMIT;
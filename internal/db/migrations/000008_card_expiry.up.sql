@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE credit_card ADD COLUMN valid_month TEXT, ADD COLUMN valid_year TEXT;
+ALTER TABLE credit_card ALTER COLUMN cvc TYPE TEXT, ALTER COLUMN number TYPE TEXT;
+
+COMMIT;
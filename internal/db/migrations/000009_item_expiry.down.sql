@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE item DROP COLUMN expires_at, DROP COLUMN rotate_days, DROP COLUMN created_at, DROP COLUMN updated_at;
+
+COMMIT;
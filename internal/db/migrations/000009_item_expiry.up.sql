@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE item
+    ADD COLUMN expires_at TEXT NOT NULL DEFAULT '',
+    ADD COLUMN rotate_days TEXT NOT NULL DEFAULT '',
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+COMMIT;
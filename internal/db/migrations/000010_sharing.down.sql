@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE item_share;
+DROP TYPE share_permission;
+DROP TABLE user_key;
+
+COMMIT;
@@ -0,0 +1,28 @@
+BEGIN;
+
+CREATE TABLE user_key (id BIGSERIAL PRIMARY KEY, user_id BIGINT NOT NULL, public_key TEXT NOT NULL, private_key TEXT NOT NULL,
+    CONSTRAINT fk_user_key_user_id
+    FOREIGN KEY(user_id)
+    REFERENCES auth_user(id)
+    ON DELETE CASCADE);
+
+CREATE UNIQUE INDEX user_key_user_idx ON user_key(user_id);
+
+CREATE TYPE share_permission AS ENUM ('read', 'write');
+
+CREATE TABLE item_share (id BIGSERIAL PRIMARY KEY, item_id BIGINT NOT NULL, recipient_id BIGINT NOT NULL,
+    permission share_permission NOT NULL, recipient_key TEXT NOT NULL, owner_key TEXT NOT NULL, data TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(), updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_share_item_id
+    FOREIGN KEY(item_id)
+    REFERENCES item(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_share_recipient_id
+    FOREIGN KEY(recipient_id)
+    REFERENCES auth_user(id)
+    ON DELETE CASCADE);
+
+CREATE UNIQUE INDEX share_item_recipient_idx ON item_share(item_id, recipient_id);
+CREATE INDEX share_recipient_idx ON item_share(recipient_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE org_item;
+DROP TABLE org_member;
+DROP TABLE organization;
+DROP TYPE org_role;
+
+COMMIT;
@@ -0,0 +1,35 @@
+BEGIN;
+
+CREATE TYPE org_role AS ENUM ('owner', 'admin', 'member', 'readonly');
+
+CREATE TABLE organization (id BIGSERIAL PRIMARY KEY, name TEXT NOT NULL, key_version INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now());
+
+CREATE UNIQUE INDEX organization_name_idx ON organization(name);
+
+CREATE TABLE org_member (id BIGSERIAL PRIMARY KEY, org_id BIGINT NOT NULL, user_id BIGINT NOT NULL,
+    role org_role NOT NULL, org_key TEXT NOT NULL, accepted BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_org_member_org_id
+    FOREIGN KEY(org_id)
+    REFERENCES organization(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_org_member_user_id
+    FOREIGN KEY(user_id)
+    REFERENCES auth_user(id)
+    ON DELETE CASCADE);
+
+CREATE UNIQUE INDEX org_member_org_user_idx ON org_member(org_id, user_id);
+CREATE INDEX org_member_user_idx ON org_member(user_id);
+
+CREATE TABLE org_item (id BIGSERIAL PRIMARY KEY, org_id BIGINT NOT NULL, key TEXT NOT NULL,
+    collection TEXT NOT NULL DEFAULT '', item_type item_type NOT NULL, data TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(), updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_org_item_org_id
+    FOREIGN KEY(org_id)
+    REFERENCES organization(id)
+    ON DELETE CASCADE);
+
+CREATE UNIQUE INDEX org_item_org_key_idx ON org_item(org_id, key);
+
+COMMIT;
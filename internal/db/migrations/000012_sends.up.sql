@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE send (id BIGSERIAL PRIMARY KEY, user_id BIGINT NOT NULL, slug TEXT NOT NULL, data TEXT NOT NULL,
+    password_hash TEXT, max_views INT NOT NULL, views INT NOT NULL DEFAULT 0, expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_send_user_id
+    FOREIGN KEY(user_id)
+    REFERENCES auth_user(id)
+    ON DELETE CASCADE);
+
+CREATE UNIQUE INDEX send_slug_idx ON send(slug);
+CREATE INDEX send_user_idx ON send(user_id);
+CREATE INDEX send_expires_at_idx ON send(expires_at);
+
+COMMIT;
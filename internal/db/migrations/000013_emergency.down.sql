@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE emergency_access;
+DROP TYPE emergency_status;
+
+COMMIT;
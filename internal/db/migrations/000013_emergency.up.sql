@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TYPE emergency_status AS ENUM ('invited', 'accepted', 'requested', 'granted');
+
+CREATE TABLE emergency_access (id BIGSERIAL PRIMARY KEY, grantor_id BIGINT NOT NULL, grantee_id BIGINT NOT NULL,
+    wait_days INT NOT NULL, status emergency_status NOT NULL DEFAULT 'invited', escrow TEXT NOT NULL,
+    requested_at TIMESTAMPTZ, rejected_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(), updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_emergency_grantor_id
+    FOREIGN KEY(grantor_id)
+    REFERENCES auth_user(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_emergency_grantee_id
+    FOREIGN KEY(grantee_id)
+    REFERENCES auth_user(id)
+    ON DELETE CASCADE);
+
+CREATE UNIQUE INDEX emergency_access_pair_idx ON emergency_access(grantor_id, grantee_id);
+CREATE INDEX emergency_access_grantee_idx ON emergency_access(grantee_id);
+CREATE INDEX emergency_access_requested_idx ON emergency_access(requested_at) WHERE status = 'requested';
+
+COMMIT;
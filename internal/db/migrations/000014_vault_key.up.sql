@@ -0,0 +1,12 @@
+BEGIN;
+
+CREATE TABLE vault_key (id BIGSERIAL PRIMARY KEY, user_id BIGINT NOT NULL, wrapped TEXT NOT NULL, recovery_hash TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_vault_key_user_id
+    FOREIGN KEY(user_id)
+    REFERENCES auth_user(id)
+    ON DELETE CASCADE);
+
+CREATE UNIQUE INDEX vault_key_user_idx ON vault_key(user_id);
+
+COMMIT;
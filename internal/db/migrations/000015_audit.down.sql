@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE audit_event;
+DROP FUNCTION audit_event_append_only;
+
+COMMIT;
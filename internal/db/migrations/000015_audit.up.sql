@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE audit_event (id BIGSERIAL PRIMARY KEY, user_id BIGINT, actor TEXT NOT NULL, action TEXT NOT NULL,
+    item_key TEXT NOT NULL DEFAULT '', remote_addr TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL);
+
+CREATE INDEX audit_event_user_idx ON audit_event(user_id, id);
+
+CREATE FUNCTION audit_event_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_event_no_update BEFORE UPDATE OR DELETE ON audit_event
+    FOR EACH ROW EXECUTE FUNCTION audit_event_append_only();
+
+CREATE TRIGGER audit_event_no_truncate BEFORE TRUNCATE ON audit_event
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_event_append_only();
+
+COMMIT;
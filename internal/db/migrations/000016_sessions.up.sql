@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE user_session (id BIGSERIAL PRIMARY KEY, user_id BIGINT NOT NULL,
+    device TEXT NOT NULL DEFAULT '', client_version TEXT NOT NULL DEFAULT '', remote_addr TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_user_session_user_id
+    FOREIGN KEY(user_id)
+    REFERENCES auth_user(id)
+    ON DELETE CASCADE);
+
+CREATE INDEX user_session_user_idx ON user_session(user_id);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE user_session DROP COLUMN device_id;
+
+DROP TABLE device;
+
+COMMIT;
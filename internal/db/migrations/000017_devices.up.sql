@@ -0,0 +1,21 @@
+BEGIN;
+
+CREATE TABLE device (id BIGSERIAL PRIMARY KEY, user_id BIGINT NOT NULL,
+    public_key TEXT NOT NULL, name TEXT NOT NULL DEFAULT '', remote_addr TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    poll_hash TEXT NOT NULL DEFAULT '', escrow TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    approved_at TIMESTAMPTZ,
+    CONSTRAINT fk_device_user_id
+    FOREIGN KEY(user_id)
+    REFERENCES auth_user(id)
+    ON DELETE CASCADE,
+    UNIQUE (user_id, public_key));
+
+ALTER TABLE user_session ADD COLUMN device_id BIGINT,
+    ADD CONSTRAINT fk_user_session_device_id
+    FOREIGN KEY(device_id)
+    REFERENCES device(id)
+    ON DELETE CASCADE;
+
+COMMIT;
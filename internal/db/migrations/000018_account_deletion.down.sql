@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE auth_user DROP COLUMN delete_at;
+
+ALTER TABLE item DROP CONSTRAINT fk_user_id,
+    ADD CONSTRAINT fk_user_id
+    FOREIGN KEY(user_id)
+    REFERENCES auth_user(id)
+    ON DELETE NO ACTION;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE item DROP CONSTRAINT fk_user_id,
+    ADD CONSTRAINT fk_user_id
+    FOREIGN KEY(user_id)
+    REFERENCES auth_user(id)
+    ON DELETE CASCADE;
+
+ALTER TABLE auth_user ADD COLUMN delete_at TIMESTAMPTZ;
+
+CREATE INDEX auth_user_delete_at_idx ON auth_user(delete_at) WHERE delete_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE auth_user DROP COLUMN disabled;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE auth_user ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT false;
+
+COMMIT;
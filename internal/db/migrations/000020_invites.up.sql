@@ -0,0 +1,8 @@
+BEGIN;
+
+CREATE TABLE invite (id BIGSERIAL PRIMARY KEY, code_hash TEXT NOT NULL UNIQUE, note TEXT NOT NULL DEFAULT '',
+    max_uses INT NOT NULL DEFAULT 1, uses INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE attachment DROP COLUMN encrypted,
+    ALTER COLUMN name TYPE VARCHAR(255), ALTER COLUMN mime_type TYPE VARCHAR(255);
+
+CREATE UNIQUE INDEX attachment_item_name_idx ON attachment(item_id, name);
+
+COMMIT;
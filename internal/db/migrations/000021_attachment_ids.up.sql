@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS attachment_item_name_idx;
+
+ALTER TABLE attachment ALTER COLUMN name TYPE TEXT, ALTER COLUMN mime_type TYPE TEXT,
+    ADD COLUMN encrypted BOOLEAN NOT NULL DEFAULT false;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+CREATE TABLE org_member_collection (member_id BIGINT NOT NULL, collection TEXT NOT NULL,
+    PRIMARY KEY (member_id, collection),
+    CONSTRAINT fk_org_member_collection_member_id
+    FOREIGN KEY(member_id)
+    REFERENCES org_member(id)
+    ON DELETE CASCADE);
+
+COMMIT;
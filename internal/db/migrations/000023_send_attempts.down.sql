@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE send DROP COLUMN IF EXISTS failed_attempts;
+
+COMMIT;
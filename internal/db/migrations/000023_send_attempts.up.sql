@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE send ADD COLUMN failed_attempts INT NOT NULL DEFAULT 0;
+
+COMMIT;
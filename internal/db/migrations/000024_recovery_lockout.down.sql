@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE vault_key DROP COLUMN IF EXISTS recovery_locked_until;
+ALTER TABLE vault_key DROP COLUMN IF EXISTS recovery_failed_attempts;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE vault_key ADD COLUMN recovery_failed_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE vault_key ADD COLUMN recovery_locked_until TIMESTAMPTZ;
+
+COMMIT;
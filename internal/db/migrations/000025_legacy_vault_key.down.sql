@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE attachment DROP COLUMN IF EXISTS legacy;
+ALTER TABLE item DROP COLUMN IF EXISTS legacy;
+ALTER TABLE vault_key DROP COLUMN IF EXISTS legacy_key;
+
+COMMIT;
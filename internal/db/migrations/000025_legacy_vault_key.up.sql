@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE vault_key ADD COLUMN legacy_key TEXT;
+ALTER TABLE item ADD COLUMN legacy BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE attachment ADD COLUMN legacy BOOLEAN NOT NULL DEFAULT false;
+
+COMMIT;
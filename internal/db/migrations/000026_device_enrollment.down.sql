@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE auth_user DROP COLUMN IF EXISTS device_enrollment_expires;
+ALTER TABLE auth_user DROP COLUMN IF EXISTS device_enrollment_hash;
+
+COMMIT;
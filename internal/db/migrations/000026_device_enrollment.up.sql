@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE auth_user ADD COLUMN device_enrollment_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE auth_user ADD COLUMN device_enrollment_expires TIMESTAMPTZ;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE item DROP COLUMN IF EXISTS secret_changed_at;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE item ADD COLUMN secret_changed_at TIMESTAMPTZ NOT NULL DEFAULT now();
+UPDATE item SET secret_changed_at = updated_at;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE OR REPLACE FUNCTION audit_event_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE audit_event DROP COLUMN IF EXISTS salts,
+    DROP COLUMN IF EXISTS redacted,
+    DROP COLUMN IF EXISTS redacted_at;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+ALTER TABLE audit_event ADD COLUMN salts JSONB,
+    ADD COLUMN redacted JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN redacted_at TIMESTAMPTZ;
+
+CREATE OR REPLACE FUNCTION audit_event_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF NEW.id = OLD.id AND NEW.action = OLD.action AND NEW.created_at = OLD.created_at
+            AND NEW.prev_hash = OLD.prev_hash AND NEW.hash = OLD.hash
+            AND (NEW.user_id IS NULL OR NEW.user_id = OLD.user_id)
+            AND NEW.actor IN (OLD.actor, '') AND NEW.item_key IN (OLD.item_key, '')
+            AND NEW.remote_addr IN (OLD.remote_addr, '')
+            AND NEW.redacted @> OLD.redacted AND NEW.redacted_at IS NOT NULL THEN
+            RETURN NEW;
+        END IF;
+    END IF;
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,407 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// membershipQuery выборка членства в организации в types.Membership
+const membershipQuery = `
+	SELECT m.org_id, o.name AS org, u.username, m.role, m.accepted, m.org_key, o.key_version, k.public_key,
+		(SELECT array_agg(c.collection ORDER BY c.collection) FROM org_member_collection c WHERE c.member_id = m.id) AS collections
+	FROM org_member m
+	JOIN organization o ON o.id = m.org_id
+	JOIN auth_user u ON u.id = m.user_id
+	LEFT JOIN user_key k ON k.user_id = m.user_id`
+
+// CreateOrg создаёт организацию, её создатель становится владельцем. orgKey - ключ организации,
+// зашифрованный открытым ключом создателя
+func (d *Database) CreateOrg(ctx context.Context, userID int, name string, orgKey string) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var orgID int
+	err = tx.QueryRow(ctx, `INSERT INTO organization (name) VALUES ($1) RETURNING id`, name).Scan(&orgID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
+			return fmt.Errorf("%w", &KeyExistsError{Key: name})
+		}
+		return fmt.Errorf("%w", err)
+	}
+	query := `
+		INSERT INTO org_member (org_id, user_id, role, org_key, accepted)
+		VALUES ($1, $2, $3, $4, true)
+	`
+	_, err = tx.Exec(ctx, query, orgID, userID, types.RoleOwner, orgKey)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteOrg удаляет организацию вместе с участниками и записями
+func (d *Database) DeleteOrg(ctx context.Context, orgID int) error {
+	_, err := d.pool.Exec(ctx, `DELETE FROM organization WHERE id = $1`, orgID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// GetMembership достаёт членство пользователя в организации org, в том числе неподтверждённое приглашение
+func (d *Database) GetMembership(ctx context.Context, userID int, org string) (*types.Membership, error) {
+	rows, err := d.pool.Query(ctx, membershipQuery+` WHERE m.user_id = $1 AND o.name = $2`, userID, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	membership, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[types.Membership])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &KeyNotFoundError{Key: org}
+		}
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return &membership, nil
+}
+
+// ListMemberships достаёт все организации пользователя, включая приглашения
+func (d *Database) ListMemberships(ctx context.Context, userID int) ([]types.Membership, error) {
+	return d.queryMemberships(ctx, membershipQuery+` WHERE m.user_id = $1 ORDER BY o.name`, userID)
+}
+
+// ListMembers достаёт участников организации вместе с их открытыми ключами
+func (d *Database) ListMembers(ctx context.Context, orgID int) ([]types.Membership, error) {
+	return d.queryMemberships(ctx, membershipQuery+` WHERE m.org_id = $1 ORDER BY u.username`, orgID)
+}
+
+func (d *Database) queryMemberships(ctx context.Context, query string, arg int) ([]types.Membership, error) {
+	rows, err := d.pool.Query(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	memberships, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.Membership])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return memberships, nil
+}
+
+// AddMember приглашает пользователя username в организацию. Участником он становится после AcceptInvite
+func (d *Database) AddMember(ctx context.Context, orgID int, username string, role types.Role, orgKey string) error {
+	userID, err := d.GetUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO org_member (org_id, user_id, role, org_key)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err = d.pool.Exec(ctx, query, orgID, userID, role, orgKey)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
+			return fmt.Errorf("%w", &KeyExistsError{Key: username})
+		}
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// AcceptInvite подтверждает приглашение пользователя в организацию
+func (d *Database) AcceptInvite(ctx context.Context, orgID int, userID int) error {
+	tag, err := d.pool.Exec(ctx, `UPDATE org_member SET accepted = true WHERE org_id = $1 AND user_id = $2`, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: fmt.Sprint(orgID)}
+	}
+	return nil
+}
+
+// SetMemberCollections ограничивает доступ участника username коллекциями collections, пустой список снимает ограничение
+func (d *Database) SetMemberCollections(ctx context.Context, orgID int, username string, collections []string) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var memberID int
+	query := `
+		SELECT m.id FROM org_member m
+		JOIN auth_user u ON u.id = m.user_id
+		WHERE m.org_id = $1 AND u.username = $2
+		FOR UPDATE
+	`
+	err = tx.QueryRow(ctx, query, orgID, username).Scan(&memberID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &KeyNotFoundError{Key: username}
+		}
+		return fmt.Errorf("%w", err)
+	}
+	_, err = tx.Exec(ctx, `DELETE FROM org_member_collection WHERE member_id = $1`, memberID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO org_member_collection (member_id, collection)
+		SELECT $1, c FROM unnest($2::text[]) AS c
+		ON CONFLICT DO NOTHING`, memberID, collections)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// LeaveOrg удаляет членство пользователя без ротации ключа. Подходит только приглашённым, не подтвердившим
+// членство. Ключ организации, зашифрованный для них, выдаётся вместе с приглашением, поэтому приглашённый
+// может его знать; если ему не доверяют, приглашение отзывает администратор через RemoveMember с ротацией.
+// Подтвердившие членство участники, в том числе ограниченные частью коллекций, выходят только с ротацией ключа
+func (d *Database) LeaveOrg(ctx context.Context, orgID int, userID int) error {
+	query := `
+		DELETE FROM org_member m
+		WHERE m.org_id = $1 AND m.user_id = $2 AND NOT m.accepted
+	`
+	tag, err := d.pool.Exec(ctx, query, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &IncompleteRotationError{Reason: "member who accepted the organization key must rotate it to leave"}
+	}
+	return nil
+}
+
+// SetMemberRole меняет роль участника организации
+func (d *Database) SetMemberRole(ctx context.Context, orgID int, username string, role types.Role) error {
+	query := `
+		UPDATE org_member m SET role = $3
+		FROM auth_user u
+		WHERE m.user_id = u.id AND m.org_id = $1 AND u.username = $2
+	`
+	tag, err := d.pool.Exec(ctx, query, orgID, username, role)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: username}
+	}
+	return nil
+}
+
+// RemoveMember исключает участника из организации и в той же транзакции применяет ротацию ключа:
+// новый ключ для каждого оставшегося участника и все записи, перешифрованные новым ключом.
+// Ротация должна покрывать всех оставшихся участников и все записи, иначе участник не исключается
+func (d *Database) RemoveMember(ctx context.Context, orgID int, username string, rotation types.KeyRotation) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	err = checkKeyVersion(ctx, tx, orgID, rotation.KeyVersion, "FOR UPDATE")
+	if err != nil {
+		return err
+	}
+
+	query := `
+		DELETE FROM org_member m
+		USING auth_user u
+		WHERE m.user_id = u.id AND m.org_id = $1 AND u.username = $2
+	`
+	tag, err := tx.Exec(ctx, query, orgID, username)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: username}
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT u.username FROM org_member m JOIN auth_user u ON u.id = m.user_id WHERE m.org_id = $1`, orgID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	members, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	keys := make([]string, 0, len(rotation.Keys))
+	for member := range rotation.Keys {
+		keys = append(keys, member)
+	}
+	if !sameSet(members, keys) {
+		return &IncompleteRotationError{Reason: "keys must be given for every remaining member"}
+	}
+
+	rows, err = tx.Query(ctx, `SELECT key FROM org_item WHERE org_id = $1`, orgID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	stored, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	items := make([]string, 0, len(rotation.Items))
+	for _, i := range rotation.Items {
+		items = append(items, i.Key)
+	}
+	if !sameSet(stored, items) {
+		return &IncompleteRotationError{Reason: "every item must be re-encrypted"}
+	}
+
+	batch := &pgx.Batch{}
+	for member, key := range rotation.Keys {
+		batch.Queue(`
+			UPDATE org_member m SET org_key = $3
+			FROM auth_user u
+			WHERE m.user_id = u.id AND m.org_id = $1 AND u.username = $2`, orgID, member, key)
+	}
+	for _, i := range rotation.Items {
+		batch.Queue(`UPDATE org_item SET data = $3, updated_at = now() WHERE org_id = $1 AND key = $2`, orgID, i.Key, i.Data)
+	}
+	batch.Queue(`UPDATE organization SET key_version = key_version + 1 WHERE id = $1`, orgID)
+	err = tx.SendBatch(ctx, batch).Close()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// ListOrgItems достаёт записи организации, если collection не пустая - только из этой коллекции.
+// allowed - коллекции, доступные участнику, nil - все
+func (d *Database) ListOrgItems(ctx context.Context, orgID int, collection string, allowed []string) ([]types.OrgItem, error) {
+	query := `
+		SELECT i.key, i.collection, i.item_type, i.data, o.key_version, i.updated_at
+		FROM org_item i
+		JOIN organization o ON o.id = i.org_id
+		WHERE i.org_id = $1 AND ($2 = '' OR i.collection = $2) AND ($3::text[] IS NULL OR i.collection = ANY($3))
+		ORDER BY i.collection, i.key
+	`
+	rows, err := d.pool.Query(ctx, query, orgID, collection, allowed)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	items, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.OrgItem])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return items, nil
+}
+
+// GetOrgItem достаёт запись организации по ключу. Запись из недоступной коллекции выглядит как несуществующая
+func (d *Database) GetOrgItem(ctx context.Context, orgID int, key string, allowed []string) (*types.OrgItem, error) {
+	query := `
+		SELECT i.key, i.collection, i.item_type, i.data, o.key_version, i.updated_at
+		FROM org_item i
+		JOIN organization o ON o.id = i.org_id
+		WHERE i.org_id = $1 AND i.key = $2 AND ($3::text[] IS NULL OR i.collection = ANY($3))
+	`
+	rows, err := d.pool.Query(ctx, query, orgID, key, allowed)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	item, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[types.OrgItem])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &KeyNotFoundError{Key: key}
+		}
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return &item, nil
+}
+
+// PutOrgItem сохраняет или заменяет запись организации. Данные должны быть зашифрованы текущим ключом организации.
+// Запись из коллекции, не входящей в allowed, заменить нельзя
+func (d *Database) PutOrgItem(ctx context.Context, orgID int, item types.OrgItem, allowed []string) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// FOR SHARE не даёт ротации ключа пройти между проверкой версии и записью
+	err = checkKeyVersion(ctx, tx, orgID, item.KeyVersion, "FOR SHARE")
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO org_item (org_id, key, collection, item_type, data)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (org_id, key) DO UPDATE
+		SET collection = EXCLUDED.collection, item_type = EXCLUDED.item_type, data = EXCLUDED.data, updated_at = now()
+		WHERE $6::text[] IS NULL OR org_item.collection = ANY($6)
+	`
+	tag, err := tx.Exec(ctx, query, orgID, item.Key, item.Collection, item.Type, item.Data, allowed)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &PermissionDeniedError{Key: item.Key}
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteOrgItem удаляет запись организации из коллекции, входящей в allowed
+func (d *Database) DeleteOrgItem(ctx context.Context, orgID int, key string, allowed []string) error {
+	query := `DELETE FROM org_item WHERE org_id = $1 AND key = $2 AND ($3::text[] IS NULL OR collection = ANY($3))`
+	tag, err := d.pool.Exec(ctx, query, orgID, key, allowed)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: key}
+	}
+	return nil
+}
+
+func checkKeyVersion(ctx context.Context, tx pgx.Tx, orgID int, version int, lock string) error {
+	var current int
+	err := tx.QueryRow(ctx, `SELECT key_version FROM organization WHERE id = $1 `+lock, orgID).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &KeyNotFoundError{Key: fmt.Sprint(orgID)}
+		}
+		return fmt.Errorf("%w", err)
+	}
+	if current != version {
+		return &KeyVersionError{Current: current, Got: version}
+	}
+	return nil
+}
+
+func sameSet(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,302 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// SetVaultKey сохраняет ключ хранилища пользователя, зашифрованный его паролем. Ключ задаётся один раз:
+// иначе украденный токен позволил бы подменить ключ и прочитать записи, сохранённые после этого.
+// Если ключ уже есть, возвращается KeyExistsError
+func (d *Database) SetVaultKey(ctx context.Context, userID int, wrapped string) error {
+	query := `
+		INSERT INTO vault_key (user_id, wrapped)
+		VALUES ($1, $2)
+	`
+	_, err := d.pool.Exec(ctx, query, userID, wrapped)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
+			return fmt.Errorf("%w", &KeyExistsError{Key: "vault key"})
+		}
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// GetVaultKey достаёт ключ хранилища пользователя, зашифрованный его паролем, и старый ключ, если записи
+// ещё перешифровываются
+func (d *Database) GetVaultKey(ctx context.Context, userID int) (*types.VaultKey, error) {
+	var key types.VaultKey
+	err := d.pool.QueryRow(ctx, `SELECT wrapped, COALESCE(legacy_key, '') FROM vault_key WHERE user_id = $1`, userID).
+		Scan(&key.Wrapped, &key.LegacyKey)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &KeyNotFoundError{Key: "vault key"}
+		}
+		return nil, fmt.Errorf("%w", err)
+	}
+	return &key, nil
+}
+
+// MigrateVaultKey заменяет старый ключ хранилища новым. Все записи и вложения пользователя помечаются
+// зашифрованными старым ключом, пока клиент их не перешифрует. Восстановление по старому ключу отключается.
+// Если ключ на сервере уже не migration.Previous, возвращается KeyExistsError
+func (d *Database) MigrateVaultKey(ctx context.Context, userID int, migration types.VaultKeyMigration) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var current string
+	var legacyKey *string
+	err = tx.QueryRow(ctx, `SELECT wrapped, legacy_key FROM vault_key WHERE user_id = $1 FOR UPDATE`, userID).
+		Scan(&current, &legacyKey)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w", err)
+	}
+	if current != migration.Previous {
+		return &KeyExistsError{Key: "vault key"}
+	}
+	if legacyKey != nil {
+		return &IncompleteRotationError{Reason: "previous vault key migration is not finished"}
+	}
+
+	if current == "" {
+		query := `
+			INSERT INTO vault_key (user_id, wrapped, legacy_key)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id) DO NOTHING
+		`
+		tag, err := tx.Exec(ctx, query, userID, migration.Wrapped, migration.LegacyKey)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return &KeyExistsError{Key: "vault key"}
+		}
+	} else {
+		query := `
+			UPDATE vault_key
+			SET wrapped = $2, legacy_key = $3, recovery_hash = NULL, recovery_failed_attempts = 0,
+				recovery_locked_until = NULL, updated_at = now()
+			WHERE user_id = $1
+		`
+		_, err = tx.Exec(ctx, query, userID, migration.Wrapped, migration.LegacyKey)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+
+	var hasKeys bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM user_key WHERE user_id = $1)`, userID).Scan(&hasKeys)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if hasKeys != (migration.PrivateKey != "") {
+		return &IncompleteRotationError{Reason: "private key must be re-encrypted"}
+	}
+	if hasKeys {
+		_, err = tx.Exec(ctx, `UPDATE user_key SET private_key = $2 WHERE user_id = $1`, userID, migration.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+
+	ids := make([]int, 0, len(migration.Escrows))
+	escrows := make([]string, 0, len(migration.Escrows))
+	for _, e := range migration.Escrows {
+		ids = append(ids, e.ID)
+		escrows = append(escrows, e.Escrow)
+	}
+	query := `
+		UPDATE emergency_access a SET escrow = e.escrow, updated_at = now()
+		FROM unnest($2::bigint[], $3::text[]) AS e(id, escrow)
+		WHERE a.id = e.id AND a.grantor_id = $1
+	`
+	tag, err := tx.Exec(ctx, query, userID, ids, escrows)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	var contacts int64
+	err = tx.QueryRow(ctx, `SELECT count(*) FROM emergency_access WHERE grantor_id = $1`, userID).Scan(&contacts)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() != contacts {
+		return &IncompleteRotationError{Reason: "every emergency contact must get the new key"}
+	}
+
+	// время изменения сдвигается, чтобы пометки попали в инкрементальную резервную копию
+	_, err = tx.Exec(ctx, `UPDATE item SET legacy = true, updated_at = now() WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE attachment SET legacy = true
+		WHERE item_id IN (SELECT id FROM item WHERE user_id = $1)`, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// GetLegacyVault достаёт ключи записей и вложения, ещё зашифрованные старым ключом хранилища,
+// и ключи карт с открытым сроком действия
+func (d *Database) GetLegacyVault(ctx context.Context, userID int) (*types.LegacyVault, error) {
+	rows, err := d.pool.Query(ctx, `SELECT key FROM item WHERE user_id = $1 AND legacy ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	items, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+
+	query := `
+		SELECT i.key, a.id
+		FROM attachment a
+		JOIN item i ON i.id = a.item_id
+		WHERE i.user_id = $1 AND a.legacy
+		ORDER BY a.id
+	`
+	rows, err = d.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	attachments, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.LegacyAttachment])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+
+	// карты, зашифрованные старым ключом, пересохраняются вместе с остальными такими записями
+	query = `
+		SELECT i.key
+		FROM credit_card c
+		JOIN item i ON i.id = c.item_id
+		WHERE i.user_id = $1 AND c.valid_till IS NOT NULL AND NOT i.legacy
+		ORDER BY i.id
+	`
+	rows, err = d.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	cards, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return &types.LegacyVault{Items: items, Attachments: attachments, Cards: cards}, nil
+}
+
+// FinishVaultKeyMigration удаляет старый ключ хранилища, когда им не зашифрована ни одна запись и ни одно вложение
+func (d *Database) FinishVaultKeyMigration(ctx context.Context, userID int) error {
+	query := `
+		UPDATE vault_key SET legacy_key = NULL, updated_at = now()
+		WHERE user_id = $1
+			AND NOT EXISTS (SELECT 1 FROM item WHERE user_id = $1 AND legacy)
+			AND NOT EXISTS (SELECT 1 FROM attachment a JOIN item i ON i.id = a.item_id WHERE i.user_id = $1 AND a.legacy)
+	`
+	tag, err := d.pool.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &IncompleteRotationError{Reason: "every item must be re-encrypted"}
+	}
+	return nil
+}
+
+// SetRecoveryHash сохраняет хеш проверочного значения ключа восстановления. Ключ хранилища должен быть уже сохранён
+func (d *Database) SetRecoveryHash(ctx context.Context, userID int, recoveryHash string) error {
+	tag, err := d.pool.Exec(ctx, `UPDATE vault_key SET recovery_hash = $2, updated_at = now() WHERE user_id = $1`, userID, recoveryHash)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: "vault key"}
+	}
+	return nil
+}
+
+// RecoverAccount задаёт пользователю username новый пароль и ключ хранилища, зашифрованный новым паролем.
+// authorize проверяет проверочное значение по сохранённому хешу. Строка блокируется на время проверки.
+// После types.RecoveryAttempts неверных попыток подряд восстановление блокируется на types.RecoveryLockout.
+// При успешном восстановлении все сессии пользователя удаляются
+func (d *Database) RecoverAccount(ctx context.Context, username string, authorize func(recoveryHash string) bool, passwordHash string, wrapped string) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		SELECT u.id, k.recovery_hash, k.recovery_failed_attempts, k.recovery_locked_until
+		FROM auth_user u
+		JOIN vault_key k ON k.user_id = u.id
+		WHERE u.username = $1 AND k.recovery_hash IS NOT NULL
+		FOR UPDATE
+	`
+	var userID int
+	var recoveryHash string
+	var failedAttempts int
+	var lockedUntil *time.Time
+	err = tx.QueryRow(ctx, query, username).Scan(&userID, &recoveryHash, &failedAttempts, &lockedUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &KeyNotFoundError{Key: username}
+		}
+		return fmt.Errorf("%w", err)
+	}
+	if lockedUntil != nil && lockedUntil.After(time.Now()) {
+		return &RecoveryLockedError{Username: username, Until: *lockedUntil}
+	}
+	if !authorize(recoveryHash) {
+		// попытки считаются заново после каждой блокировки
+		if failedAttempts+1 >= types.RecoveryAttempts {
+			_, err = tx.Exec(ctx, `
+				UPDATE vault_key SET recovery_failed_attempts = 0, recovery_locked_until = $2
+				WHERE user_id = $1`, userID, time.Now().Add(types.RecoveryLockout))
+		} else {
+			_, err = tx.Exec(ctx, `
+				UPDATE vault_key SET recovery_failed_attempts = recovery_failed_attempts + 1
+				WHERE user_id = $1`, userID)
+		}
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		err = tx.Commit(ctx)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		return &PermissionDeniedError{Key: username}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE auth_user SET password = $2 WHERE id = $1`, userID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE vault_key SET wrapped = $2, recovery_failed_attempts = 0, recovery_locked_until = NULL, updated_at = now()
+		WHERE user_id = $1`, userID, wrapped)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	// сессии, открытые со старым паролем, завершаются: возможно, им и воспользовались
+	_, err = tx.Exec(ctx, `DELETE FROM user_session WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// sendColumns колонки для выборки одноразовых ссылок в types.Send
+const sendColumns = `slug, max_views, views, expires_at, password_hash IS NOT NULL AS has_password`
+
+// CreateSend сохраняет одноразовую ссылку. passwordHash - хеш пароля для открытия ссылки или nil
+func (d *Database) CreateSend(ctx context.Context, userID int, slug string, send types.NewSend, passwordHash *string) error {
+	query := `
+		INSERT INTO send (user_id, slug, data, password_hash, max_views, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := d.pool.Exec(ctx, query, userID, slug, send.Data, passwordHash, send.MaxViews, send.ExpiresAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
+			return fmt.Errorf("%w", &KeyExistsError{Key: slug})
+		}
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// ListSends достаёт действующие одноразовые ссылки пользователя
+func (d *Database) ListSends(ctx context.Context, userID int) ([]types.Send, error) {
+	query := `SELECT ` + sendColumns + `
+		FROM send
+		WHERE user_id = $1 AND expires_at > now()
+		ORDER BY expires_at
+	`
+	rows, err := d.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	sends, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.Send])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return sends, nil
+}
+
+// GetSend достаёт сведения о действующей одноразовой ссылке без содержимого, просмотр не засчитывается
+func (d *Database) GetSend(ctx context.Context, slug string) (*types.Send, error) {
+	query := `SELECT ` + sendColumns + `
+		FROM send
+		WHERE slug = $1 AND expires_at > now()
+	`
+	rows, err := d.pool.Query(ctx, query, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	send, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[types.Send])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &KeyNotFoundError{Key: slug}
+		}
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return &send, nil
+}
+
+// DeleteSend удаляет одноразовую ссылку пользователя досрочно
+func (d *Database) DeleteSend(ctx context.Context, userID int, slug string) error {
+	tag, err := d.pool.Exec(ctx, `DELETE FROM send WHERE user_id = $1 AND slug = $2`, userID, slug)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: slug}
+	}
+	return nil
+}
+
+// OpenSend засчитывает просмотр и возвращает содержимое одноразовой ссылки. authorize проверяет пароль
+// по его хешу (nil, если пароль не задан). После последнего просмотра ссылка удаляется, как и после
+// types.SendPasswordAttempts неверных паролей. Строка блокируется на время проверки, поэтому параллельные
+// запросы не получат больше просмотров и попыток, чем разрешено
+func (d *Database) OpenSend(ctx context.Context, slug string, authorize func(passwordHash *string) bool) (*types.SendContent, error) {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		SELECT data, password_hash, max_views - views - 1, failed_attempts
+		FROM send
+		WHERE slug = $1 AND expires_at > now() AND views < max_views
+		FOR UPDATE
+	`
+	var content types.SendContent
+	var passwordHash *string
+	var failedAttempts int
+	err = tx.QueryRow(ctx, query, slug).Scan(&content.Data, &passwordHash, &content.ViewsLeft, &failedAttempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &KeyNotFoundError{Key: slug}
+		}
+		return nil, fmt.Errorf("%w", err)
+	}
+	if !authorize(passwordHash) {
+		if failedAttempts+1 >= types.SendPasswordAttempts {
+			_, err = tx.Exec(ctx, `DELETE FROM send WHERE slug = $1`, slug)
+		} else {
+			_, err = tx.Exec(ctx, `UPDATE send SET failed_attempts = failed_attempts + 1 WHERE slug = $1`, slug)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+		err = tx.Commit(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+		return nil, &PermissionDeniedError{Key: slug}
+	}
+
+	if content.ViewsLeft == 0 {
+		_, err = tx.Exec(ctx, `DELETE FROM send WHERE slug = $1`, slug)
+	} else {
+		_, err = tx.Exec(ctx, `UPDATE send SET views = views + 1 WHERE slug = $1`, slug)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	return &content, nil
+}
+
+// DeleteExpiredSends удаляет просроченные одноразовые ссылки и возвращает их число
+func (d *Database) DeleteExpiredSends(ctx context.Context) (int64, error) {
+	tag, err := d.pool.Exec(ctx, `DELETE FROM send WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// CreateSession заводит сессию пользователя на устройстве и возвращает её id
+func (d *Database) CreateSession(ctx context.Context, userID int, session types.NewSession) (int, error) {
+	query := `
+		INSERT INTO user_session (user_id, device, client_version, remote_addr, device_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0))
+		RETURNING id
+	`
+	var id int
+	err := d.pool.QueryRow(ctx, query, userID, session.Device, session.ClientVersion, session.RemoteAddr, session.DeviceID).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+	return id, nil
+}
+
+// ListSessions действующие сессии пользователя, начиная с последней активной
+func (d *Database) ListSessions(ctx context.Context, userID int) ([]types.Session, error) {
+	query := `
+		SELECT id, device_id, device, client_version, remote_addr, created_at, last_seen
+		FROM user_session WHERE user_id = $1 AND last_seen > now() - $2::interval
+		ORDER BY last_seen DESC
+	`
+	rows, err := d.pool.Query(ctx, query, userID, types.SessionIdleTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	sessions, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.Session])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return sessions, nil
+}
+
+// DeleteSession завершает сессию пользователя: её токен больше не принимается
+func (d *Database) DeleteSession(ctx context.Context, userID int, id int) error {
+	tag, err := d.pool.Exec(ctx, `DELETE FROM user_session WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: strconv.Itoa(id)}
+	}
+	return nil
+}
+
+// TouchSession проверяет, что сессия id пользователя username не завершена и не истекла, а пользователь не отключён,
+// и не чаще раза в минуту обновляет время и адрес последнего запроса. Иначе - KeyNotFoundError
+func (d *Database) TouchSession(ctx context.Context, username string, id int, remoteAddr string) error {
+	query := `
+		WITH s AS (
+			SELECT s.id, s.last_seen FROM user_session s JOIN auth_user u ON u.id = s.user_id
+			WHERE s.id = $1 AND u.username = $2 AND s.last_seen > now() - $4::interval AND NOT u.disabled
+		), touched AS (
+			UPDATE user_session SET last_seen = now(), remote_addr = $3
+			WHERE id IN (SELECT id FROM s WHERE last_seen < now() - interval '1 minute')
+		)
+		SELECT count(*) FROM s
+	`
+	var found int
+	err := d.pool.QueryRow(ctx, query, id, username, remoteAddr, types.SessionIdleTimeout).Scan(&found)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if found == 0 {
+		return &KeyNotFoundError{Key: strconv.Itoa(id)}
+	}
+	return nil
+}
+
+// DeleteIdleSessions удаляет истёкшие сессии и возвращает их число
+func (d *Database) DeleteIdleSessions(ctx context.Context) (int64, error) {
+	tag, err := d.pool.Exec(ctx, `DELETE FROM user_session WHERE last_seen <= now() - $1::interval`, types.SessionIdleTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// shareColumns колонки для выборки общих записей в types.Share
+const shareColumns = `
+	s.id, owner.username AS owner, recipient.username AS recipient, i.key, i.item_type,
+	s.permission, s.recipient_key, s.owner_key, s.data, s.updated_at`
+
+// shareJoins связывает общую запись с записью владельца и пользователями
+const shareJoins = `
+	FROM item_share s
+	JOIN item i ON i.id = s.item_id
+	JOIN auth_user owner ON owner.id = i.user_id
+	JOIN auth_user recipient ON recipient.id = s.recipient_id`
+
+// SetUserKeys сохраняет ключевую пару пользователя. Заменить сохранённые ключи нельзя:
+// по ним зашифрованы ключи данных уже выданных общих записей
+func (d *Database) SetUserKeys(ctx context.Context, userID int, keys types.UserKeys) error {
+	query := `
+		INSERT INTO user_key (user_id, public_key, private_key)
+		VALUES ($1, $2, $3)
+	`
+	_, err := d.pool.Exec(ctx, query, userID, keys.PublicKey, keys.PrivateKey)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
+			return fmt.Errorf("%w", &KeyExistsError{Key: "user keys"})
+		}
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// SetPrivateKey заменяет зашифрованный приватный ключ пользователя, например перешифрованный клиентом.
+// Открытый ключ не меняется
+func (d *Database) SetPrivateKey(ctx context.Context, userID int, privateKey string) error {
+	query := `
+		UPDATE user_key SET private_key = $2
+		WHERE user_id = $1
+	`
+	tag, err := d.pool.Exec(ctx, query, userID, privateKey)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: "user keys"}
+	}
+	return nil
+}
+
+// GetUserKeys достаёт ключевую пару пользователя
+func (d *Database) GetUserKeys(ctx context.Context, userID int) (*types.UserKeys, error) {
+	query := `
+		SELECT public_key, private_key
+		FROM user_key
+		WHERE user_id = $1
+	`
+	var keys types.UserKeys
+	err := d.pool.QueryRow(ctx, query, userID).Scan(&keys.PublicKey, &keys.PrivateKey)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &KeyNotFoundError{Key: "user keys"}
+		}
+		return nil, fmt.Errorf("%w", err)
+	}
+	return &keys, nil
+}
+
+// GetPublicKey достаёт открытый ключ пользователя username
+func (d *Database) GetPublicKey(ctx context.Context, username string) (string, error) {
+	query := `
+		SELECT k.public_key
+		FROM auth_user u
+		LEFT JOIN user_key k ON k.user_id = u.id
+		WHERE u.username = $1
+	`
+	var key *string
+	err := d.pool.QueryRow(ctx, query, username).Scan(&key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", &UserNotFoundError{Username: username}
+		}
+		return "", fmt.Errorf("%w", err)
+	}
+	if key == nil {
+		return "", &KeyNotFoundError{Key: username}
+	}
+	return *key, nil
+}
+
+// ShareItem делится записью key владельца ownerID с пользователем share.Recipient.
+// Если запись уже выдана этому пользователю, обновляются права и данные
+func (d *Database) ShareItem(ctx context.Context, ownerID int, key string, share types.Share) error {
+	item, err := d.GetItem(ctx, ownerID, key)
+	if err != nil {
+		return err
+	}
+	recipientID, err := d.GetUserID(ctx, share.Recipient)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO item_share (item_id, recipient_id, permission, recipient_key, owner_key, data)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (item_id, recipient_id) DO UPDATE
+		SET permission = EXCLUDED.permission, recipient_key = EXCLUDED.recipient_key,
+			owner_key = EXCLUDED.owner_key, data = EXCLUDED.data, updated_at = now()
+	`
+	_, err = d.pool.Exec(ctx, query, item.Id, recipientID, share.Permission, share.RecipientKey, share.OwnerKey, share.Data)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
+
+// GetItemShares достаёт список пользователей, с которыми владелец поделился записью key
+func (d *Database) GetItemShares(ctx context.Context, ownerID int, key string) ([]types.Share, error) {
+	item, err := d.GetItem(ctx, ownerID, key)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + shareColumns + shareJoins + `
+		WHERE s.item_id = $1
+		ORDER BY recipient.username
+	`
+	return d.queryShares(ctx, query, item.Id)
+}
+
+// GetSharedWithUser достаёт записи, которыми с пользователем поделились другие
+func (d *Database) GetSharedWithUser(ctx context.Context, userID int) ([]types.Share, error) {
+	query := `SELECT ` + shareColumns + shareJoins + `
+		WHERE s.recipient_id = $1
+		ORDER BY owner.username, i.key
+	`
+	return d.queryShares(ctx, query, userID)
+}
+
+func (d *Database) queryShares(ctx context.Context, query string, arg int) ([]types.Share, error) {
+	rows, err := d.pool.Query(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed collecting rows %w", err)
+	}
+	shares, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.Share])
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rows %w", err)
+	}
+	return shares, nil
+}
+
+// RevokeShare отзывает доступ пользователя recipient к записи key
+func (d *Database) RevokeShare(ctx context.Context, ownerID int, key string, recipient string) error {
+	query := `
+		DELETE FROM item_share s
+		USING item i, auth_user u
+		WHERE s.item_id = i.id AND s.recipient_id = u.id AND i.user_id = $1 AND i.key = $2 AND u.username = $3
+	`
+	tag, err := d.pool.Exec(ctx, query, ownerID, key, recipient)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &KeyNotFoundError{Key: key}
+	}
+	return nil
+}
+
+// UpdateShare заменяет зашифрованные данные общей записи. Менять данные может владелец записи
+// и получатель с правом записи
+func (d *Database) UpdateShare(ctx context.Context, userID int, shareID int, data string) error {
+	query := `
+		SELECT i.user_id, s.recipient_id, s.permission
+		FROM item_share s
+		JOIN item i ON i.id = s.item_id
+		WHERE s.id = $1
+	`
+	var ownerID, recipientID int
+	var permission types.Permission
+	err := d.pool.QueryRow(ctx, query, shareID).Scan(&ownerID, &recipientID, &permission)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &KeyNotFoundError{Key: fmt.Sprint(shareID)}
+		}
+		return fmt.Errorf("%w", err)
+	}
+	if userID != ownerID && userID != recipientID {
+		return &KeyNotFoundError{Key: fmt.Sprint(shareID)}
+	}
+	if userID == recipientID && permission != types.PermissionWrite {
+		return &PermissionDeniedError{Key: fmt.Sprint(shareID)}
+	}
+
+	_, err = d.pool.Exec(ctx, `UPDATE item_share SET data = $1, updated_at = now() WHERE id = $2`, data, shareID)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package encrypt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	other := []byte("fedcba9876543210fedcba9876543210")
+
+	sealed, err := Seal(key, []byte("текст"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	again, _ := Seal(key, []byte("текст"))
+	if sealed == again {
+		t.Errorf("Seal() must use random nonce")
+	}
+
+	tests := []struct {
+		name    string
+		key     []byte
+		data    string
+		want    []byte
+		wantErr error
+	}{
+		{"ok", key, sealed, []byte("текст"), nil},
+		{"wrong key", other, sealed, nil, ErrAuthentication},
+		{"tampered", key, sealed[:len(sealed)-4] + "AAAA", nil, ErrAuthentication},
+		{"not base64", key, "!!!", nil, ErrAuthentication},
+		{"short", key, "AAAA", nil, ErrAuthentication},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Open(tt.key, tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Open() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Open() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
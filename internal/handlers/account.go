@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// HandleDeleteAccount планирует удаление учётной записи после types.AccountDeletionGrace. Пароль нужно ввести
+// ещё раз: токен мог попасть в чужие руки. До удаления запрос можно отменить
+func (h *HandlerSet) HandleDeleteAccount(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+	username, _ := auth.GetAuthenticatedUser(req)
+
+	var request types.DeleteAccount
+	err = decodeBody(req, &request)
+	if err != nil || request.Password == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+	hashed, err := h.database.GetUserHashedPassword(req.Context(), username)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if !auth.CheckPasswordHash(request.Password, hashed) {
+		http.Error(w, "Wrong password", http.StatusUnauthorized)
+		return
+	}
+
+	deleteAt, err := h.database.ScheduleUserDeletion(req.Context(), userID, time.Now().Add(types.AccountDeletionGrace))
+	if err != nil {
+		var orgOwner *db.OrgOwnerError
+		if errors.As(err, &orgOwner) {
+			http.Error(w, fmt.Sprintf("You are the only owner of organization %s, pass ownership first", orgOwner.Org),
+				http.StatusConflict)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	h.recordAudit(req, userID, types.AuditDeletionRequest, "")
+	writeJSON(w, types.AccountDeletion{DeleteAt: deleteAt})
+}
+
+// HandleAccountDeletion возвращает время запланированного удаления учётной записи, 404 - удаление не запланировано
+func (h *HandlerSet) HandleAccountDeletion(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	deleteAt, err := h.database.GetUserDeletion(req.Context(), userID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if deleteAt == nil {
+		http.Error(w, "Deletion is not scheduled", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, types.AccountDeletion{DeleteAt: *deleteAt})
+}
+
+// HandleCancelAccountDeletion отменяет запланированное удаление учётной записи
+func (h *HandlerSet) HandleCancelAccountDeletion(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	err = h.database.CancelUserDeletion(req.Context(), userID)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Deletion is not scheduled", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	h.recordAudit(req, userID, types.AuditDeletionCancel, "")
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+	"gotest.tools/assert"
+)
+
+func TestHandlerSet_HandleDeleteAccount(t *testing.T) {
+	hash, _ := auth.HashPassword("pass")
+	deleteAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name               string
+		body               string
+		err                error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{name: "ok", body: `{"password": "pass"}`, expectedStatusCode: http.StatusOK,
+			expectedBody: `{"delete_at":"2030-01-02T03:04:05Z"}`},
+		{name: "wrong password", body: `{"password": "other"}`, expectedStatusCode: http.StatusUnauthorized},
+		{name: "no password", body: `{}`, expectedStatusCode: http.StatusBadRequest},
+		{name: "org owner", body: `{"password": "pass"}`, err: &db.OrgOwnerError{Org: "acme"},
+			expectedStatusCode: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := &MockDatabase{}
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodDelete, "/api/user", []byte(tt.body))
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().GetUserHashedPassword(req.Context(), "user").Return(hash, nil)
+			mdb.EXPECT().ScheduleUserDeletion(req.Context(), 1, mock.MatchedBy(func(at time.Time) bool {
+				return at.After(time.Now().Add(types.AccountDeletionGrace - time.Minute))
+			})).Return(deleteAt, tt.err)
+
+			w := httptest.NewRecorder()
+			h.HandleDeleteAccount(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			if tt.expectedStatusCode == http.StatusUnauthorized || tt.expectedStatusCode == http.StatusBadRequest {
+				mdb.AssertNotCalled(t, "ScheduleUserDeletion", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandlerSet_HandleAccountDeletion(t *testing.T) {
+	deleteAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name               string
+		deleteAt           *time.Time
+		expectedStatusCode int
+	}{
+		{name: "scheduled", deleteAt: &deleteAt, expectedStatusCode: http.StatusOK},
+		{name: "not scheduled", expectedStatusCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := &MockDatabase{}
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodGet, "/api/user/deletion", nil)
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().GetUserDeletion(req.Context(), 1).Return(tt.deleteAt, nil)
+
+			w := httptest.NewRecorder()
+			h.HandleAccountDeletion(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleCancelAccountDeletion(t *testing.T) {
+	tests := []struct {
+		name               string
+		err                error
+		expectedStatusCode int
+	}{
+		{name: "ok", expectedStatusCode: http.StatusOK},
+		{name: "not scheduled", err: &db.KeyNotFoundError{Key: "deletion"}, expectedStatusCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := &MockDatabase{}
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodDelete, "/api/user/deletion", nil)
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().CancelUserDeletion(req.Context(), 1).Return(tt.err)
+
+			w := httptest.NewRecorder()
+			h.HandleCancelAccountDeletion(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// adminActor от чьего имени действия администратора записываются в журнал аудита пользователя
+const adminActor = "admin"
+
+// HandleAdminUsers список учётных записей для администратора: только метаданные, без ключей и содержимого записей
+func (h *HandlerSet) HandleAdminUsers(w http.ResponseWriter, req *http.Request) {
+
+	users, err := h.database.AdminListUsers(req.Context())
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, users)
+}
+
+// HandleAdminStats сводка по серверу
+func (h *HandlerSet) HandleAdminStats(w http.ResponseWriter, req *http.Request) {
+
+	stats, err := h.database.AdminStats(req.Context())
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+// HandleAdminDisableUser отключает учётную запись: вход запрещается, действующие сессии завершаются
+func (h *HandlerSet) HandleAdminDisableUser(w http.ResponseWriter, req *http.Request) {
+	h.setUserDisabled(w, req, true)
+}
+
+// HandleAdminEnableUser снова разрешает вход отключённому пользователю
+func (h *HandlerSet) HandleAdminEnableUser(w http.ResponseWriter, req *http.Request) {
+	h.setUserDisabled(w, req, false)
+}
+
+func (h *HandlerSet) setUserDisabled(w http.ResponseWriter, req *http.Request, disabled bool) {
+
+	userID, err := h.adminTargetUser(w, req)
+	if err != nil {
+		return
+	}
+
+	err = h.database.SetUserDisabled(req.Context(), userID, disabled)
+	if err != nil {
+		h.handleAdminError(w, err)
+		return
+	}
+	action := types.AuditAccountEnable
+	if disabled {
+		action = types.AuditAccountDisable
+	}
+	h.recordAdminAudit(req, userID, action, "")
+}
+
+// HandleAdminLogoutUser завершает все сессии пользователя
+func (h *HandlerSet) HandleAdminLogoutUser(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.adminTargetUser(w, req)
+	if err != nil {
+		return
+	}
+
+	affected, err := h.database.DeleteUserSessions(req.Context(), userID)
+	if err != nil {
+		h.handleAdminError(w, err)
+		return
+	}
+	h.recordAdminAudit(req, userID, types.AuditSessionRevoke, "all")
+	writeJSON(w, types.AdminResult{Affected: affected})
+}
+
+// HandleAdminResetDevices сбрасывает второй фактор пользователя - подтверждённые устройства. Нужен, когда
+// пользователь потерял все устройства. Следующее устройство подтвердится, только если предъявит одноразовый код
+// из ответа: администратор передаёт его пользователю, убедившись, что это он, - одного пароля недостаточно
+func (h *HandlerSet) HandleAdminResetDevices(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.adminTargetUser(w, req)
+	if err != nil {
+		return
+	}
+
+	code, err := newSecret()
+	if err != nil {
+		h.handleAdminError(w, err)
+		return
+	}
+	expiresAt := time.Now().Add(types.DeviceEnrollmentTTL)
+	affected, err := h.database.ResetUserDevices(req.Context(), userID, hashSecret(code), expiresAt)
+	if err != nil {
+		h.handleAdminError(w, err)
+		return
+	}
+	h.recordAdminAudit(req, userID, types.AuditDevicesReset, "")
+	writeJSON(w, types.DeviceEnrollment{Affected: affected, Code: code, ExpiresAt: expiresAt})
+}
+
+// HandleAdminDeleteUser планирует удаление учётной записи с обычным сроком на отмену, с now=true - удаляет сразу
+func (h *HandlerSet) HandleAdminDeleteUser(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.adminTargetUser(w, req)
+	if err != nil {
+		return
+	}
+
+	if req.URL.Query().Get("now") == "true" {
+		err = h.database.DeleteUser(req.Context(), userID)
+		if err != nil {
+			h.handleAdminError(w, err)
+			return
+		}
+		h.recordAdminAudit(req, userID, types.AuditAccountDelete, "")
+		return
+	}
+
+	deleteAt, err := h.database.ScheduleUserDeletion(req.Context(), userID, time.Now().Add(types.AccountDeletionGrace))
+	if err != nil {
+		h.handleAdminError(w, err)
+		return
+	}
+	h.recordAdminAudit(req, userID, types.AuditDeletionRequest, "")
+	writeJSON(w, types.AccountDeletion{DeleteAt: deleteAt})
+}
+
+func (h *HandlerSet) adminTargetUser(w http.ResponseWriter, req *http.Request) (int, error) {
+	userID, err := h.database.GetUserID(req.Context(), req.PathValue("username"))
+	if err != nil {
+		h.handleAdminError(w, err)
+		return 0, err
+	}
+	return userID, nil
+}
+
+func (h *HandlerSet) handleAdminError(w http.ResponseWriter, err error) {
+	var userNotFound *db.UserNotFoundError
+	if errors.As(err, &userNotFound) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	var orgOwner *db.OrgOwnerError
+	if errors.As(err, &orgOwner) {
+		http.Error(w, fmt.Sprintf("User is the only owner of organization %s", orgOwner.Org), http.StatusConflict)
+		return
+	}
+	fmt.Println(err.Error())
+	http.Error(w, "Something went wrong", http.StatusInternalServerError)
+}
+
+func (h *HandlerSet) recordAdminAudit(req *http.Request, userID int, action types.AuditAction, key string) {
+	h.appendAudit(req, types.AuditEvent{UserID: &userID, Actor: adminActor, Action: action, Key: key})
+}
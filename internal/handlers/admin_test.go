@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+	"gotest.tools/assert"
+)
+
+func adminRequest(method string, url string, username string) *http.Request {
+	req := httptest.NewRequest(method, url, nil)
+	req.SetPathValue("username", username)
+	return req
+}
+
+func TestHandlerSet_HandleAdminUsers(t *testing.T) {
+	mdb := &MockDatabase{}
+	h := &HandlerSet{secret: []byte("secret"), database: mdb}
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+
+	mdb.EXPECT().AdminListUsers(req.Context()).Return([]types.AdminUser{
+		{ID: 1, Username: "alice", Items: 3, StorageBytes: 100, Sessions: 1, Disabled: true}}, nil)
+
+	w := httptest.NewRecorder()
+	h.HandleAdminUsers(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `[{"id":1,"username":"alice","items":3,"storage_bytes":100,"sessions":1,"disabled":true}]`,
+		w.Body.String())
+}
+
+func TestHandlerSet_HandleAdminDisableUser(t *testing.T) {
+	tests := []struct {
+		name               string
+		userErr            error
+		expectedStatusCode int
+	}{
+		{"ok", nil, http.StatusOK},
+		{"unknown", &db.UserNotFoundError{Username: "bob"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := &MockDatabase{}
+			h := &HandlerSet{secret: []byte("secret"), database: mdb, audit: mdb}
+			req := adminRequest(http.MethodPost, "/api/admin/users/bob/disable", "bob")
+
+			mdb.EXPECT().GetUserID(req.Context(), "bob").Return(2, tt.userErr)
+			mdb.EXPECT().SetUserDisabled(req.Context(), 2, true).Return(nil)
+			mdb.EXPECT().AppendAuditEvent(mock.Anything, mock.MatchedBy(func(e types.AuditEvent) bool {
+				return *e.UserID == 2 && e.Actor == "admin" && e.Action == types.AuditAccountDisable
+			})).Return(nil)
+
+			w := httptest.NewRecorder()
+			h.HandleAdminDisableUser(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.userErr != nil {
+				mdb.AssertNotCalled(t, "SetUserDisabled", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandlerSet_HandleAdminLogoutUser(t *testing.T) {
+	mdb := &MockDatabase{}
+	h := &HandlerSet{secret: []byte("secret"), database: mdb}
+	req := adminRequest(http.MethodDelete, "/api/admin/users/bob/sessions", "bob")
+
+	mdb.EXPECT().GetUserID(req.Context(), "bob").Return(2, nil)
+	mdb.EXPECT().DeleteUserSessions(req.Context(), 2).Return(3, nil)
+
+	w := httptest.NewRecorder()
+	h.HandleAdminLogoutUser(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"affected":3}`, w.Body.String())
+}
+
+func TestHandlerSet_HandleAdminResetDevices(t *testing.T) {
+	mdb := &MockDatabase{}
+	h := &HandlerSet{secret: []byte("secret"), database: mdb}
+	req := adminRequest(http.MethodDelete, "/api/admin/users/bob/devices", "bob")
+
+	var enrollmentHash string
+	mdb.EXPECT().GetUserID(req.Context(), "bob").Return(2, nil)
+	mdb.EXPECT().ResetUserDevices(req.Context(), 2, mock.MatchedBy(func(hash string) bool {
+		enrollmentHash = hash
+		return hash != ""
+	}), mock.Anything).Return(2, nil)
+
+	w := httptest.NewRecorder()
+	h.HandleAdminResetDevices(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var enrollment types.DeviceEnrollment
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &enrollment))
+	assert.Equal(t, int64(2), enrollment.Affected)
+	// на сервере хранится только хеш кода
+	assert.Equal(t, enrollmentHash, hashSecret(enrollment.Code))
+	assert.Assert(t, enrollment.ExpiresAt.After(time.Now()))
+}
+
+func TestHandlerSet_HandleAdminDeleteUser(t *testing.T) {
+	deleteAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name               string
+		url                string
+		err                error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{"schedule", "/api/admin/users/bob", nil, http.StatusOK, `{"delete_at":"2030-01-02T03:04:05Z"}`},
+		{"org owner", "/api/admin/users/bob", &db.OrgOwnerError{Org: "acme"}, http.StatusConflict, ""},
+		{"now", "/api/admin/users/bob?now=true", nil, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := &MockDatabase{}
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := adminRequest(http.MethodDelete, tt.url, "bob")
+
+			mdb.EXPECT().GetUserID(req.Context(), "bob").Return(2, nil)
+			mdb.EXPECT().ScheduleUserDeletion(req.Context(), 2, mock.Anything).Return(deleteAt, tt.err)
+			mdb.EXPECT().DeleteUser(req.Context(), 2).Return(nil)
+
+			w := httptest.NewRecorder()
+			h.HandleAdminDeleteUser(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			if tt.name == "now" {
+				mdb.AssertNotCalled(t, "ScheduleUserDeletion", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				mdb.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// AuditLog журнал аудита, в который хендлеры записывают события
+type AuditLog interface {
+	AppendAuditEvent(context.Context, types.AuditEvent) error
+}
+
+// HandleAuditLog возвращает страницу событий журнала аудита, относящихся к пользователю
+func (h *HandlerSet) HandleAuditLog(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	page, limit := 1, 50
+	if s := req.URL.Query().Get("page"); s != "" {
+		page, err = strconv.Atoi(s)
+		if err != nil || page < 1 {
+			http.Error(w, "Error parsing page", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := req.URL.Query().Get("limit"); s != "" {
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit < 1 {
+			http.Error(w, "Error parsing limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := h.database.ListAuditEvents(req.Context(), userID, limit, (page-1)*limit)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if events == nil {
+		events = []types.AuditEvent{}
+	}
+	writeJSON(w, events)
+}
+
+// recordAudit записывает в журнал пользователя userID действие аутентифицированного пользователя над записью key
+func (h *HandlerSet) recordAudit(req *http.Request, userID int, action types.AuditAction, key string) {
+	actor, _ := auth.GetAuthenticatedUser(req)
+	h.appendAudit(req, types.AuditEvent{UserID: &userID, Actor: actor, Action: action, Key: key})
+}
+
+// recordAccountAudit записывает в журнал вход, регистрацию или восстановление доступа к учётной записи username.
+// Неудачная попытка входа под несуществующим именем записывается без владельца
+func (h *HandlerSet) recordAccountAudit(req *http.Request, username string, action types.AuditAction) {
+	if h.audit == nil {
+		return
+	}
+	event := types.AuditEvent{Actor: username, Action: action}
+	userID, err := h.database.GetUserID(req.Context(), username)
+	if err == nil {
+		event.UserID = &userID
+	}
+	h.appendAudit(req, event)
+}
+
+// appendAudit дописывает событие в журнал. Ошибка журнала не прерывает запрос, событие пишется,
+// даже если клиент уже отключился
+func (h *HandlerSet) appendAudit(req *http.Request, event types.AuditEvent) {
+	if h.audit == nil {
+		return
+	}
+	event.RemoteAddr = req.RemoteAddr
+	err := h.audit.AppendAuditEvent(context.WithoutCancel(req.Context()), event)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/types"
+	"gotest.tools/assert"
+)
+
+func TestHandlerSet_HandleAuditLog(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		query              string
+		limit              int
+		offset             int
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"default", "", 50, 0, nil, http.StatusOK},
+		{"page", "?page=3&limit=10", 10, 20, nil, http.StatusOK},
+		{"badPage", "?page=0", 50, 0, nil, http.StatusBadRequest},
+		{"dbError", "", 50, 0, fmt.Errorf("smth"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodGet, "/api/audit"+tt.query, nil)
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().ListAuditEvents(req.Context(), 1, tt.limit, tt.offset).Return(nil, tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleAuditLog(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, "[]", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerSet_RecordAudit(t *testing.T) {
+
+	t.Run("deleteItem", func(t *testing.T) {
+		mdb := &MockDatabase{}
+		h := &HandlerSet{secret: []byte("secret"), database: mdb, audit: mdb}
+		req := authorizedRequest(http.MethodDelete, "/api/item/111", nil)
+		req.SetPathValue("key", "111")
+
+		mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+		mdb.EXPECT().DeleteItem(req.Context(), 1, "111").Return(nil)
+		mdb.EXPECT().AppendAuditEvent(mock.Anything, mock.MatchedBy(func(e types.AuditEvent) bool {
+			return *e.UserID == 1 && e.Actor == "user" && e.Action == types.AuditItemDelete && e.Key == "111"
+		})).Return(nil)
+
+		w := httptest.NewRecorder()
+		h.HandleDeleteItem(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mdb.AssertNumberOfCalls(t, "AppendAuditEvent", 1)
+	})
+
+	t.Run("failedLogin", func(t *testing.T) {
+		mdb := &MockDatabase{}
+		h := &HandlerSet{secret: []byte("secret"), database: mdb, audit: mdb}
+		req, _ := http.NewRequest(http.MethodPost, "/api/user/login", bytes.NewBufferString(`{"login": "user", "password": "wrong"}`))
+		hash, _ := auth.HashPassword("pass")
+
+		mdb.EXPECT().GetUserHashedPassword(req.Context(), "user").Return(hash, nil)
+		mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+		// ошибка журнала не мешает ответить на запрос
+		mdb.EXPECT().AppendAuditEvent(mock.Anything, mock.MatchedBy(func(e types.AuditEvent) bool {
+			return *e.UserID == 1 && e.Actor == "user" && e.Action == types.AuditLoginFailed
+		})).Return(fmt.Errorf("smth"))
+
+		w := httptest.NewRecorder()
+		h.HandleLogin(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mdb.AssertNumberOfCalls(t, "AppendAuditEvent", 1)
+	})
+}
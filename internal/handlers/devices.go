@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+const (
+	// deviceKeySize размер открытого ключа X25519 устройства
+	deviceKeySize = 32
+	// secretBytes размер секрета, которым новое устройство узнаёт о подтверждении входа, и кода приглашения
+	secretBytes = 32
+)
+
+// HandleDeviceChallenge выдаёт вызов, на который устройство отвечает при входе, доказывая владение закрытым ключом.
+// Сервер ничего не хранит: nonce подписан секретом сервера, а одноразовый ключ сервера выводится из nonce
+func (h *HandlerSet) HandleDeviceChallenge(w http.ResponseWriter, req *http.Request) {
+
+	random, err := newSecret()
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	payload := strconv.FormatInt(time.Now().Unix(), 10) + "." + random
+	nonce := payload + "." + h.sign("device nonce", payload)
+
+	serverKey, err := curve25519.X25519(h.challengeKey(nonce), curve25519.Basepoint)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, types.DeviceChallenge{Nonce: nonce, ServerKey: base64.StdEncoding.EncodeToString(serverKey)})
+}
+
+// verifyDeviceProof проверяет, что вызов выдан этим сервером, не истёк и ответ вычислен закрытым ключом устройства
+func (h *HandlerSet) verifyDeviceProof(publicKey []byte, nonce string, proof string) bool {
+	idx := strings.LastIndex(nonce, ".")
+	if idx < 0 {
+		return false
+	}
+	payload := nonce[:idx]
+	if !hmac.Equal([]byte(nonce[idx+1:]), []byte(h.sign("device nonce", payload))) {
+		return false
+	}
+	issued, err := strconv.ParseInt(strings.SplitN(payload, ".", 2)[0], 10, 64)
+	if err != nil || time.Since(time.Unix(issued, 0)) > types.DeviceChallengeTimeout {
+		return false
+	}
+	expected, err := types.DeviceProof(h.challengeKey(nonce), publicKey, nonce)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(proof), []byte(expected))
+}
+
+// challengeKey одноразовый закрытый ключ X25519 сервера для вызова
+func (h *HandlerSet) challengeKey(nonce string) []byte {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte("device key\x00" + nonce))
+	return mac.Sum(nil)
+}
+
+func (h *HandlerSet) sign(purpose string, payload string) string {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(purpose + "\x00" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// HandlePollDevice отвечает новому устройству, подтверждён ли его вход: 202 - ещё ждёт, 403 - отклонён.
+// После подтверждения выдаёт токен сессии и переданный устройству ключ хранилища
+func (h *HandlerSet) HandlePollDevice(w http.ResponseWriter, req *http.Request) {
+
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Wrong id", http.StatusBadRequest)
+		return
+	}
+	var poll types.DevicePoll
+	err = decodeBody(req, &poll)
+	if err != nil || poll.Secret == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.database.PollDevice(req.Context(), id, hashSecret(poll.Secret))
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Login request not found or expired", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	switch result.Status {
+	case types.DevicePending:
+		w.WriteHeader(http.StatusAccepted)
+		return
+	case types.DeviceRejected:
+		http.Error(w, "Login rejected", http.StatusForbidden)
+		return
+	}
+
+	err = h.createSession(w, req, result.UserID, result.Username, id)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	h.appendAudit(req, types.AuditEvent{UserID: &result.UserID, Actor: result.Username, Action: types.AuditLogin})
+	writeJSON(w, types.DeviceApproval{Escrow: result.Escrow})
+}
+
+// HandleDevices возвращает подтверждённые устройства пользователя и ожидающие подтверждения запросы на вход
+func (h *HandlerSet) HandleDevices(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	devices, err := h.database.ListDevices(req.Context(), userID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if devices == nil {
+		devices = []types.Device{}
+	}
+	writeJSON(w, devices)
+}
+
+// HandleApproveDevice подтверждает вход с нового устройства. В теле может быть ключ хранилища,
+// зашифрованный ключом устройства
+func (h *HandlerSet) HandleApproveDevice(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Wrong id", http.StatusBadRequest)
+		return
+	}
+	var approval types.DeviceApproval
+	err = decodeBody(req, &approval)
+	if err != nil {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.ApproveDevice(req.Context(), userID, id, approval.Escrow)
+	if !h.handleDeviceError(w, err) {
+		return
+	}
+	h.recordAudit(req, userID, types.AuditDeviceApprove, strconv.Itoa(id))
+}
+
+// HandleRejectDevice отклоняет вход с нового устройства
+func (h *HandlerSet) HandleRejectDevice(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Wrong id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.RejectDevice(req.Context(), userID, id)
+	if !h.handleDeviceError(w, err) {
+		return
+	}
+	h.recordAudit(req, userID, types.AuditDeviceReject, strconv.Itoa(id))
+}
+
+// HandleDeleteDevice забывает устройство пользователя и завершает его сессии
+func (h *HandlerSet) HandleDeleteDevice(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Wrong id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.DeleteDevice(req.Context(), userID, id)
+	if !h.handleDeviceError(w, err) {
+		return
+	}
+	h.recordAudit(req, userID, types.AuditDeviceDelete, strconv.Itoa(id))
+}
+
+func (h *HandlerSet) handleDeviceError(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return true
+	}
+	var keyNotFound *db.KeyNotFoundError
+	if errors.As(err, &keyNotFound) {
+		http.Error(w, "Device not found", http.StatusNotFound)
+		return false
+	}
+	fmt.Println(err.Error())
+	http.Error(w, "Something went wrong", http.StatusInternalServerError)
+	return false
+}
+
+func writeDeviceRequest(w http.ResponseWriter, request types.DeviceRequest) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_, err = w.Write(data)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+func newSecret() (string, error) {
+	secret := make([]byte, secretBytes)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashSecret в БД хранится только хеш секрета (устройства или приглашения): случайного секрета такой длины достаточно
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+	"golang.org/x/crypto/curve25519"
+	"gotest.tools/assert"
+)
+
+// proveDevice отвечает на вызов сервера закрытым ключом устройства, как это делает клиент
+func proveDevice(t *testing.T, h *HandlerSet, req *http.Request, private []byte) {
+	w := httptest.NewRecorder()
+	h.HandleDeviceChallenge(w, httptest.NewRequest(http.MethodGet, "/api/device/challenge", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var challenge types.DeviceChallenge
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &challenge))
+	serverKey, err := base64.StdEncoding.DecodeString(challenge.ServerKey)
+	assert.NilError(t, err)
+	proof, err := types.DeviceProof(private, serverKey, challenge.Nonce)
+	assert.NilError(t, err)
+	req.Header.Set(DeviceNonceHeader, challenge.Nonce)
+	req.Header.Set(DeviceProofHeader, proof)
+}
+
+func TestHandlerSet_StartSession_Devices(t *testing.T) {
+	private := bytes.Repeat([]byte{1}, curve25519.ScalarSize)
+	public, err := curve25519.X25519(private, curve25519.Basepoint)
+	assert.NilError(t, err)
+	publicKey := base64.StdEncoding.EncodeToString(public)
+
+	t.Run("pending", func(t *testing.T) {
+		mdb := &MockDatabase{}
+		h := &HandlerSet{secret: []byte("secret"), database: mdb}
+		req, _ := http.NewRequest(http.MethodPost, "/api/user/login", nil)
+		req.Header.Set(DeviceKeyHeader, publicKey)
+		proveDevice(t, h, req, private)
+
+		var pollHash string
+		mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+		mdb.EXPECT().LoginDevice(req.Context(), 1, mock.MatchedBy(func(d types.NewDevice) bool {
+			pollHash = d.PollHash
+			return d.PublicKey == publicKey && d.PollHash != ""
+		}), false).Return(types.DeviceLogin{ID: 3, Status: types.DevicePending}, nil)
+
+		w := httptest.NewRecorder()
+		started, err := h.startSession(w, req, "user", false)
+		assert.NilError(t, err)
+		assert.Assert(t, !started)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "", w.Header().Get(auth.AuthHeader))
+
+		var request types.DeviceRequest
+		assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &request))
+		assert.Equal(t, 3, request.ID)
+		assert.Equal(t, types.DeviceFingerprint(publicKey), request.Fingerprint)
+		assert.Equal(t, pollHash, hashSecret(request.Secret))
+	})
+
+	t.Run("approved device", func(t *testing.T) {
+		mdb := &MockDatabase{}
+		h := &HandlerSet{secret: []byte("secret"), database: mdb}
+		req, _ := http.NewRequest(http.MethodPost, "/api/user/login", nil)
+		req.Header.Set(DeviceKeyHeader, publicKey)
+		proveDevice(t, h, req, private)
+
+		mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+		mdb.EXPECT().LoginDevice(req.Context(), 1, mock.Anything, false).Return(types.DeviceLogin{ID: 3, Status: types.DeviceApproved}, nil)
+		mdb.EXPECT().CreateSession(req.Context(), 1, types.NewSession{DeviceID: 3}).Return(5, nil)
+
+		w := httptest.NewRecorder()
+		started, err := h.startSession(w, req, "user", false)
+		assert.NilError(t, err)
+		assert.Assert(t, started)
+		assert.Equal(t, token, w.Header().Get(auth.AuthHeader))
+	})
+
+	t.Run("key required", func(t *testing.T) {
+		mdb := &MockDatabase{}
+		h := &HandlerSet{secret: []byte("secret"), database: mdb}
+		req, _ := http.NewRequest(http.MethodPost, "/api/user/login", nil)
+
+		mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+		mdb.EXPECT().LoginDevice(req.Context(), 1, types.NewDevice{}, false).Return(types.DeviceLogin{}, &db.PermissionDeniedError{Key: "device"})
+
+		w := httptest.NewRecorder()
+		started, err := h.startSession(w, req, "user", false)
+		assert.NilError(t, err)
+		assert.Assert(t, !started)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		mdb := &MockDatabase{}
+		h := &HandlerSet{secret: []byte("secret"), database: mdb}
+		req, _ := http.NewRequest(http.MethodPost, "/api/user/login", nil)
+
+		mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+		mdb.EXPECT().LoginDevice(req.Context(), 1, types.NewDevice{}, false).Return(types.DeviceLogin{}, &db.UserDisabledError{Username: "user"})
+
+		w := httptest.NewRecorder()
+		started, err := h.startSession(w, req, "user", false)
+		assert.NilError(t, err)
+		assert.Assert(t, !started)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("enrollment", func(t *testing.T) {
+		mdb := &MockDatabase{}
+		h := &HandlerSet{secret: []byte("secret"), database: mdb}
+		req, _ := http.NewRequest(http.MethodPost, "/api/user/login", nil)
+		req.Header.Set(DeviceKeyHeader, publicKey)
+		req.Header.Set(DeviceEnrollmentHeader, "code")
+		proveDevice(t, h, req, private)
+
+		mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+		mdb.EXPECT().LoginDevice(req.Context(), 1, mock.MatchedBy(func(d types.NewDevice) bool {
+			return d.EnrollmentHash == hashSecret("code")
+		}), false).Return(types.DeviceLogin{}, &db.EnrollmentRequiredError{})
+
+		w := httptest.NewRecorder()
+		started, err := h.startSession(w, req, "user", false)
+		assert.NilError(t, err)
+		assert.Assert(t, !started)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, types.EnrollmentRequired+"\n", w.Body.String())
+	})
+
+	t.Run("rejected or too many requests", func(t *testing.T) {
+		for _, tt := range []struct {
+			err  error
+			code int
+		}{
+			{err: &db.DeviceRejectedError{ID: 3}, code: http.StatusForbidden},
+			{err: &db.DeviceRequestsLimitError{Limit: types.DevicePendingLimit}, code: http.StatusTooManyRequests},
+		} {
+			mdb := &MockDatabase{}
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req, _ := http.NewRequest(http.MethodPost, "/api/user/login", nil)
+			req.Header.Set(DeviceKeyHeader, publicKey)
+			proveDevice(t, h, req, private)
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().LoginDevice(req.Context(), 1, mock.Anything, false).Return(types.DeviceLogin{}, tt.err)
+
+			w := httptest.NewRecorder()
+			started, err := h.startSession(w, req, "user", false)
+			assert.NilError(t, err)
+			assert.Assert(t, !started)
+			assert.Equal(t, tt.code, w.Code)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		mdb := &MockDatabase{}
+		h := &HandlerSet{secret: []byte("secret"), database: mdb}
+		req, _ := http.NewRequest(http.MethodPost, "/api/user/login", nil)
+		req.Header.Set(DeviceKeyHeader, "short")
+
+		mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+
+		w := httptest.NewRecorder()
+		started, err := h.startSession(w, req, "user", false)
+		assert.NilError(t, err)
+		assert.Assert(t, !started)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mdb.AssertNotCalled(t, "LoginDevice", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("key not proven", func(t *testing.T) {
+		for name, prove := range map[string]func(h *HandlerSet, req *http.Request){
+			"no proof": func(h *HandlerSet, req *http.Request) {},
+			"other private key": func(h *HandlerSet, req *http.Request) {
+				proveDevice(t, h, req, bytes.Repeat([]byte{2}, curve25519.ScalarSize))
+			},
+			"nonce of other server": func(h *HandlerSet, req *http.Request) {
+				proveDevice(t, &HandlerSet{secret: []byte("other")}, req, private)
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				mdb := &MockDatabase{}
+				h := &HandlerSet{secret: []byte("secret"), database: mdb}
+				req, _ := http.NewRequest(http.MethodPost, "/api/user/login", nil)
+				req.Header.Set(DeviceKeyHeader, publicKey)
+				prove(h, req)
+
+				mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+
+				w := httptest.NewRecorder()
+				started, err := h.startSession(w, req, "user", false)
+				assert.NilError(t, err)
+				assert.Assert(t, !started)
+				assert.Equal(t, http.StatusUnauthorized, w.Code)
+				mdb.AssertNotCalled(t, "LoginDevice", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
+
+func TestHandlerSet_HandlePollDevice(t *testing.T) {
+	tests := []struct {
+		name               string
+		id                 string
+		body               string
+		result             types.DevicePollResult
+		err                error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{name: "pending", id: "3", body: `{"secret": "s"}`, result: types.DevicePollResult{Status: types.DevicePending},
+			expectedStatusCode: http.StatusAccepted},
+		{name: "approved", id: "3", body: `{"secret": "s"}`,
+			result:             types.DevicePollResult{UserID: 1, Username: "user", Status: types.DeviceApproved, Escrow: "wrapped"},
+			expectedStatusCode: http.StatusOK, expectedBody: `{"escrow":"wrapped"}`},
+		{name: "rejected", id: "3", body: `{"secret": "s"}`, result: types.DevicePollResult{Status: types.DeviceRejected},
+			expectedStatusCode: http.StatusForbidden},
+		{name: "not found", id: "3", body: `{"secret": "s"}`, err: &db.KeyNotFoundError{Key: "3"},
+			expectedStatusCode: http.StatusNotFound},
+		{name: "no secret", id: "3", body: `{}`, expectedStatusCode: http.StatusBadRequest},
+		{name: "wrong id", id: "x", body: `{"secret": "s"}`, expectedStatusCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := &MockDatabase{}
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req, _ := http.NewRequest(http.MethodPost, "/api/device/"+tt.id+"/poll", bytes.NewBufferString(tt.body))
+			req.SetPathValue("id", tt.id)
+
+			mdb.EXPECT().PollDevice(req.Context(), 3, hashSecret("s")).Return(tt.result, tt.err)
+			mdb.EXPECT().CreateSession(req.Context(), 1, types.NewSession{DeviceID: 3}).Return(5, nil)
+
+			w := httptest.NewRecorder()
+			h.HandlePollDevice(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+				assert.Equal(t, token, w.Header().Get(auth.AuthHeader))
+			} else {
+				mdb.AssertNotCalled(t, "CreateSession", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandlerSet_HandleDevices(t *testing.T) {
+	mdb := &MockDatabase{}
+	h := &HandlerSet{secret: []byte("secret"), database: mdb}
+	req := authorizedRequest(http.MethodGet, "/api/user/devices", nil)
+
+	devices := []types.Device{{ID: 3, Name: "laptop", PublicKey: "key", Status: types.DevicePending}}
+	mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+	mdb.EXPECT().ListDevices(req.Context(), 1).Return(devices, nil)
+
+	w := httptest.NewRecorder()
+	h.HandleDevices(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var got []types.Device
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.DeepEqual(t, devices, got)
+}
+
+func TestHandlerSet_HandleApproveDevice(t *testing.T) {
+	tests := []struct {
+		name               string
+		id                 string
+		body               string
+		err                error
+		expectedStatusCode int
+	}{
+		{name: "ok", id: "3", body: `{"escrow": "wrapped"}`, expectedStatusCode: http.StatusOK},
+		{name: "not pending", id: "3", body: `{"escrow": "wrapped"}`, err: &db.KeyNotFoundError{Key: "3"},
+			expectedStatusCode: http.StatusNotFound},
+		{name: "wrong body", id: "3", body: `{`, expectedStatusCode: http.StatusBadRequest},
+		{name: "wrong id", id: "x", body: `{}`, expectedStatusCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := &MockDatabase{}
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPost, "/api/user/devices/"+tt.id+"/approve", []byte(tt.body))
+			req.SetPathValue("id", tt.id)
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().ApproveDevice(req.Context(), 1, 3, "wrapped").Return(tt.err)
+
+			w := httptest.NewRecorder()
+			h.HandleApproveDevice(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleRejectDevice(t *testing.T) {
+	mdb := &MockDatabase{}
+	h := &HandlerSet{secret: []byte("secret"), database: mdb}
+	req := authorizedRequest(http.MethodPost, "/api/user/devices/3/reject", nil)
+	req.SetPathValue("id", "3")
+
+	mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+	mdb.EXPECT().RejectDevice(req.Context(), 1, 3).Return(nil)
+
+	w := httptest.NewRecorder()
+	h.HandleRejectDevice(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// HandleCreateEmergencyAccess назначает доверенное лицо. Тело запроса - types.NewEmergencyAccess
+func (h *HandlerSet) HandleCreateEmergencyAccess(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	var access types.NewEmergencyAccess
+	err = decodeBody(req, &access)
+	if err != nil || access.Grantee == "" || access.Escrow == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+	if access.WaitDays < 1 || access.WaitDays > types.MaxEmergencyWaitDays {
+		http.Error(w, fmt.Sprintf("wait_days must be between 1 and %d", types.MaxEmergencyWaitDays), http.StatusBadRequest)
+		return
+	}
+
+	granteeID, err := h.database.GetUserID(req.Context(), access.Grantee)
+	if err != nil {
+		var userNotFound *db.UserNotFoundError
+		if errors.As(err, &userNotFound) {
+			http.Error(w, "Grantee not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if granteeID == userID {
+		http.Error(w, "Can not designate yourself", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.CreateEmergencyAccess(req.Context(), userID, granteeID, access.WaitDays, access.Escrow)
+	if err != nil {
+		var keyExistsError *db.KeyExistsError
+		if errors.As(err, &keyExistsError) {
+			http.Error(w, "Grantee already designated", http.StatusConflict)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// HandleEmergencyContacts возвращает доверенных лиц, назначенных пользователем
+func (h *HandlerSet) HandleEmergencyContacts(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	contacts, err := h.database.ListEmergencyContacts(req.Context(), userID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if contacts == nil {
+		contacts = []types.EmergencyAccess{}
+	}
+	writeJSON(w, contacts)
+}
+
+// HandleEmergencyGrants возвращает хранилища, для которых пользователь назначен доверенным лицом
+func (h *HandlerSet) HandleEmergencyGrants(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	grants, err := h.database.ListEmergencyGrants(req.Context(), userID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if grants == nil {
+		grants = []types.EmergencyAccess{}
+	}
+	writeJSON(w, grants)
+}
+
+// HandleAcceptEmergencyAccess доверенное лицо соглашается на назначение
+func (h *HandlerSet) HandleAcceptEmergencyAccess(w http.ResponseWriter, req *http.Request) {
+	h.moveEmergencyAccess(w, req, types.EmergencyAccepted)
+}
+
+// HandleRequestEmergencyAccess доверенное лицо запрашивает доступ, начинается период ожидания
+func (h *HandlerSet) HandleRequestEmergencyAccess(w http.ResponseWriter, req *http.Request) {
+	h.moveEmergencyAccess(w, req, types.EmergencyRequested)
+}
+
+// HandleApproveEmergencyAccess владелец выдаёт доступ, не дожидаясь конца периода ожидания
+func (h *HandlerSet) HandleApproveEmergencyAccess(w http.ResponseWriter, req *http.Request) {
+	h.moveEmergencyAccess(w, req, types.EmergencyGranted)
+}
+
+// HandleRejectEmergencyAccess владелец отклоняет запрос доступа
+func (h *HandlerSet) HandleRejectEmergencyAccess(w http.ResponseWriter, req *http.Request) {
+
+	userID, id, err := h.handleEmergencyID(w, req)
+	if err != nil {
+		return
+	}
+	err = h.database.RejectEmergencyAccess(req.Context(), id, userID)
+	if err != nil {
+		h.handleEmergencyError(w, err)
+		return
+	}
+}
+
+// HandleDeleteEmergencyAccess владелец отзывает экстренный доступ или доверенное лицо от него отказывается
+func (h *HandlerSet) HandleDeleteEmergencyAccess(w http.ResponseWriter, req *http.Request) {
+
+	userID, id, err := h.handleEmergencyID(w, req)
+	if err != nil {
+		return
+	}
+	err = h.database.DeleteEmergencyAccess(req.Context(), id, userID)
+	if err != nil {
+		h.handleEmergencyError(w, err)
+		return
+	}
+}
+
+// HandleEmergencyKey возвращает доверенному лицу ключ хранилища владельца, если доступ выдан
+func (h *HandlerSet) HandleEmergencyKey(w http.ResponseWriter, req *http.Request) {
+
+	userID, id, err := h.handleEmergencyID(w, req)
+	if err != nil {
+		return
+	}
+	_, escrow, err := h.database.GetEmergencyGrant(req.Context(), id, userID)
+	if err != nil {
+		h.handleEmergencyError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, types.EmergencyKey{Escrow: escrow})
+}
+
+// HandleEmergencyItemList возвращает доверенному лицу страницу списка записей владельца
+func (h *HandlerSet) HandleEmergencyItemList(w http.ResponseWriter, req *http.Request) {
+	grantorID, err := h.handleAuthorizeGrantee(w, req)
+	if err != nil {
+		return
+	}
+	h.itemList(w, req, grantorID)
+}
+
+// HandleEmergencyGetItem возвращает доверенному лицу запись владельца
+func (h *HandlerSet) HandleEmergencyGetItem(w http.ResponseWriter, req *http.Request) {
+	grantorID, err := h.handleAuthorizeGrantee(w, req)
+	if err != nil {
+		return
+	}
+	h.getItem(w, req, grantorID)
+}
+
+// HandleEmergencyDownloadBinaryItem возвращает доверенному лицу бинарные данные владельца
+func (h *HandlerSet) HandleEmergencyDownloadBinaryItem(w http.ResponseWriter, req *http.Request) {
+	grantorID, err := h.handleAuthorizeGrantee(w, req)
+	if err != nil {
+		return
+	}
+	h.downloadBinaryItem(w, req, grantorID)
+}
+
+func (h *HandlerSet) moveEmergencyAccess(w http.ResponseWriter, req *http.Request, to types.EmergencyStatus) {
+
+	userID, id, err := h.handleEmergencyID(w, req)
+	if err != nil {
+		return
+	}
+	err = h.database.MoveEmergencyAccess(req.Context(), id, userID, to)
+	if err != nil {
+		h.handleEmergencyError(w, err)
+		return
+	}
+}
+
+// handleAuthorizeGrantee проверяет, что пользователю выдан экстренный доступ, и возвращает владельца хранилища
+func (h *HandlerSet) handleAuthorizeGrantee(w http.ResponseWriter, req *http.Request) (int, error) {
+
+	userID, id, err := h.handleEmergencyID(w, req)
+	if err != nil {
+		return 0, err
+	}
+	grantorID, _, err := h.database.GetEmergencyGrant(req.Context(), id, userID)
+	if err != nil {
+		h.handleEmergencyError(w, err)
+		return 0, err
+	}
+	return grantorID, nil
+}
+
+func (h *HandlerSet) handleEmergencyID(w http.ResponseWriter, req *http.Request) (int, int, error) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Wrong id", http.StatusBadRequest)
+		return 0, 0, err
+	}
+	return userID, id, nil
+}
+
+func (h *HandlerSet) handleEmergencyError(w http.ResponseWriter, err error) {
+	var keyNotFound *db.KeyNotFoundError
+	if errors.As(err, &keyNotFound) {
+		http.Error(w, "Emergency access not found", http.StatusNotFound)
+		return
+	}
+	var statusError *db.EmergencyStatusError
+	if errors.As(err, &statusError) {
+		http.Error(w, statusError.Error(), http.StatusConflict)
+		return
+	}
+	fmt.Println(err.Error())
+	http.Error(w, "Something went wrong", http.StatusInternalServerError)
+}
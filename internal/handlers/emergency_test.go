@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+	"gotest.tools/assert"
+)
+
+func TestHandlerSet_HandleCreateEmergencyAccess(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		body               string
+		granteeID          int
+		granteeErr         error
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", `{"grantee": "bob", "wait_days": 7, "escrow": "wrapped"}`, 2, nil, nil, http.StatusCreated},
+		{"noEscrow", `{"grantee": "bob", "wait_days": 7}`, 2, nil, nil, http.StatusBadRequest},
+		{"noWait", `{"grantee": "bob", "wait_days": 0, "escrow": "wrapped"}`, 2, nil, nil, http.StatusBadRequest},
+		{"tooLong", `{"grantee": "bob", "wait_days": 365, "escrow": "wrapped"}`, 2, nil, nil, http.StatusBadRequest},
+		{"unknownGrantee", `{"grantee": "bob", "wait_days": 7, "escrow": "wrapped"}`, 0, &db.UserNotFoundError{Username: "bob"}, nil, http.StatusNotFound},
+		{"self", `{"grantee": "bob", "wait_days": 7, "escrow": "wrapped"}`, 1, nil, nil, http.StatusBadRequest},
+		{"exists", `{"grantee": "bob", "wait_days": 7, "escrow": "wrapped"}`, 2, nil, &db.KeyExistsError{Key: "2"}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPost, "/api/emergency", []byte(tt.body))
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().GetUserID(req.Context(), "bob").Return(tt.granteeID, tt.granteeErr)
+			mdb.EXPECT().CreateEmergencyAccess(req.Context(), 1, 2, 7, "wrapped").Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleCreateEmergencyAccess(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleRequestEmergencyAccess(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		id                 string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", "5", nil, http.StatusOK},
+		{"badID", "five", nil, http.StatusBadRequest},
+		{"notGrantee", "5", &db.KeyNotFoundError{Key: "5"}, http.StatusNotFound},
+		{"notAccepted", "5", &db.EmergencyStatusError{Status: "invited"}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPost, "/api/emergency/"+tt.id+"/request", nil)
+			req.SetPathValue("id", tt.id)
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().MoveEmergencyAccess(req.Context(), 5, 1, types.EmergencyRequested).Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleRequestEmergencyAccess(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleEmergencyItemList(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		grantErr           error
+		expectedStatusCode int
+	}{
+		{"granted", nil, http.StatusOK},
+		{"waiting", &db.EmergencyStatusError{Status: "requested"}, http.StatusConflict},
+		{"notGrantee", &db.KeyNotFoundError{Key: "5"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodGet, "/api/emergency/5/item/list", nil)
+			req.SetPathValue("id", "5")
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().GetEmergencyGrant(req.Context(), 5, 1).Return(3, "wrapped", tt.grantErr)
+			// записи берутся из хранилища владельца, а не доверенного лица
+			mdb.EXPECT().GetItems(req.Context(), 3, 10, 0).Return([]types.Item{}, nil)
+
+			w := httptest.NewRecorder()
+			h.HandleEmergencyItemList(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.grantErr == nil {
+				mdb.AssertCalled(t, "GetItems", req.Context(), 3, 10, 0)
+			} else {
+				mdb.AssertNotCalled(t, "GetItems", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
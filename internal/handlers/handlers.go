@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/wellywell/gophkeeper/internal/auth"
 	"github.com/wellywell/gophkeeper/internal/db"
@@ -24,6 +25,7 @@ import (
 type Database interface {
 	GetUserHashedPassword(context.Context, string) (string, error)
 	CreateUser(context.Context, string, string) error
+	CreateUserWithInvite(context.Context, string, string, string) error
 	GetUserID(context.Context, string) (int, error)
 	InsertLogoPass(context.Context, int, types.LoginPasswordItem) error
 	InsertCreditCard(context.Context, int, types.CreditCardItem) error
@@ -32,7 +34,8 @@ type Database interface {
 	UpdateBinaryData(context.Context, int, types.BinaryItem) error
 	GetItem(context.Context, int, string) (*types.Item, error)
 	GetItems(context.Context, int, int, int) ([]types.Item, error)
-	GetBinaryData(context.Context, int, string) ([]byte, error)
+	GetBinaryData(context.Context, int, string) ([]byte, *types.BinaryMeta, error)
+	GetBinaryMeta(context.Context, int) (*types.BinaryMeta, error)
 	GetLogoPass(context.Context, int) (*types.LoginPassword, error)
 	GetCreditCard(context.Context, int) (*types.CreditCardData, error)
 	GetText(context.Context, int) (*types.TextData, error)
@@ -40,14 +43,107 @@ type Database interface {
 	UpdateLogoPass(context.Context, int, types.LoginPasswordItem) error
 	UpdateCreditCard(context.Context, int, types.CreditCardItem) error
 	UpdateText(context.Context, int, types.TextItem) error
+	InsertSSHKey(context.Context, int, types.SSHKeyItem) error
+	UpdateSSHKey(context.Context, int, types.SSHKeyItem) error
+	GetSSHKey(context.Context, int) (*types.SSHKeyData, error)
+	InsertTOTP(context.Context, int, types.TOTPItem) error
+	UpdateTOTP(context.Context, int, types.TOTPItem) error
+	GetTOTP(context.Context, int) (*types.TOTPData, error)
+	InsertAttachment(context.Context, int, string, types.Attachment, []byte) (int, error)
+	GetAttachments(context.Context, int, string) ([]types.Attachment, error)
+	GetAttachment(context.Context, int, string, int) (*types.Attachment, []byte, error)
+	DeleteAttachment(context.Context, int, string, int) error
+	ReplaceAttachment(context.Context, int, string, int, types.Attachment, []byte) error
+	SetUserKeys(context.Context, int, types.UserKeys) error
+	GetUserKeys(context.Context, int) (*types.UserKeys, error)
+	SetPrivateKey(context.Context, int, string) error
+	GetPublicKey(context.Context, string) (string, error)
+	ShareItem(context.Context, int, string, types.Share) error
+	GetItemShares(context.Context, int, string) ([]types.Share, error)
+	GetSharedWithUser(context.Context, int) ([]types.Share, error)
+	RevokeShare(context.Context, int, string, string) error
+	UpdateShare(context.Context, int, int, string) error
+	CreateOrg(context.Context, int, string, string) error
+	DeleteOrg(context.Context, int) error
+	GetMembership(context.Context, int, string) (*types.Membership, error)
+	ListMemberships(context.Context, int) ([]types.Membership, error)
+	ListMembers(context.Context, int) ([]types.Membership, error)
+	AddMember(context.Context, int, string, types.Role, string) error
+	AcceptInvite(context.Context, int, int) error
+	SetMemberRole(context.Context, int, string, types.Role) error
+	RemoveMember(context.Context, int, string, types.KeyRotation) error
+	SetMemberCollections(context.Context, int, string, []string) error
+	LeaveOrg(context.Context, int, int) error
+	ListOrgItems(context.Context, int, string, []string) ([]types.OrgItem, error)
+	GetOrgItem(context.Context, int, string, []string) (*types.OrgItem, error)
+	PutOrgItem(context.Context, int, types.OrgItem, []string) error
+	DeleteOrgItem(context.Context, int, string, []string) error
+	CreateSend(context.Context, int, string, types.NewSend, *string) error
+	ListSends(context.Context, int) ([]types.Send, error)
+	GetSend(context.Context, string) (*types.Send, error)
+	DeleteSend(context.Context, int, string) error
+	OpenSend(context.Context, string, func(*string) bool) (*types.SendContent, error)
+	CreateEmergencyAccess(context.Context, int, int, int, string) error
+	ListEmergencyContacts(context.Context, int) ([]types.EmergencyAccess, error)
+	ListEmergencyGrants(context.Context, int) ([]types.EmergencyAccess, error)
+	MoveEmergencyAccess(context.Context, int, int, types.EmergencyStatus) error
+	RejectEmergencyAccess(context.Context, int, int) error
+	DeleteEmergencyAccess(context.Context, int, int) error
+	GetEmergencyGrant(context.Context, int, int) (int, string, error)
+	SetVaultKey(context.Context, int, string) error
+	GetVaultKey(context.Context, int) (*types.VaultKey, error)
+	MigrateVaultKey(context.Context, int, types.VaultKeyMigration) error
+	GetLegacyVault(context.Context, int) (*types.LegacyVault, error)
+	FinishVaultKeyMigration(context.Context, int) error
+	SetRecoveryHash(context.Context, int, string) error
+	RecoverAccount(context.Context, string, func(string) bool, string, string) error
+	AppendAuditEvent(context.Context, types.AuditEvent) error
+	ListAuditEvents(context.Context, int, int, int) ([]types.AuditEvent, error)
+	CreateSession(context.Context, int, types.NewSession) (int, error)
+	ListSessions(context.Context, int) ([]types.Session, error)
+	DeleteSession(context.Context, int, int) error
+	TouchSession(context.Context, string, int, string) error
+	LoginDevice(context.Context, int, types.NewDevice, bool) (types.DeviceLogin, error)
+	PollDevice(context.Context, int, string) (types.DevicePollResult, error)
+	ApproveDevice(context.Context, int, int, string) error
+	RejectDevice(context.Context, int, int) error
+	ListDevices(context.Context, int) ([]types.Device, error)
+	DeleteDevice(context.Context, int, int) error
+	ScheduleUserDeletion(context.Context, int, time.Time) (time.Time, error)
+	GetUserDeletion(context.Context, int) (*time.Time, error)
+	CancelUserDeletion(context.Context, int) error
+	DeleteUser(context.Context, int) error
+	AdminListUsers(context.Context) ([]types.AdminUser, error)
+	AdminStats(context.Context) (types.AdminStats, error)
+	SetUserDisabled(context.Context, int, bool) error
+	DeleteUserSessions(context.Context, int) (int64, error)
+	ResetUserDevices(context.Context, int, string, time.Time) (int64, error)
+	CreateInvite(context.Context, string, int, time.Time, string) (int, error)
+	ListInvites(context.Context) ([]types.Invite, error)
+	DeleteInvite(context.Context, int) error
 }
 
 // HandlerSet структура для работы с хендлерами
 type HandlerSet struct {
 	secret   []byte
 	database Database
+	// audit журнал аудита, nil - события не записываются
+	audit AuditLog
+	// registration правила регистрации, нулевое значение - регистрация открыта, к паролю требований нет
+	registration Registration
 }
 
+const (
+	BinarySizeHeader   = "X-Content-Size"
+	BinarySHA256Header = "X-Content-Sha256"
+	// AttachmentNameHeader и AttachmentTypeHeader зашифрованные на клиенте имя и MIME-тип вложения
+	AttachmentNameHeader = "X-Attachment-Name"
+	AttachmentTypeHeader = "X-Attachment-Type"
+)
+
+// MaxAttachmentSize наибольший размер зашифрованного вложения в байтах
+const MaxAttachmentSize = 32 << 20
+
 var (
 	ErrCouldNotParseBody = errors.New("could not parse body")
 	ErrAuthDataEmpty     = errors.New("login or password cannot be empty")
@@ -58,6 +154,7 @@ func NewHandlerSet(secret []byte, database Database) *HandlerSet {
 	return &HandlerSet{
 		secret:   secret,
 		database: database,
+		audit:    database,
 	}
 }
 
@@ -71,17 +168,19 @@ func (h *HandlerSet) HandleLogin(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	username, password, err := h.parseAuthData(body)
+	data, err := h.parseAuthData(body)
 
 	if err != nil {
 		h.handleAuthErrors(err, w)
 		return
 	}
+	username, password := h.canonicalUsername(req.Context(), data.Username), data.Password
 
 	passwordInDB, err := h.database.GetUserHashedPassword(req.Context(), username)
 	if err != nil {
 		var userNotFound *db.UserNotFoundError
 		if errors.As(err, &userNotFound) {
+			h.recordAccountAudit(req, username, types.AuditLoginFailed)
 			http.Error(w, "User not found", http.StatusUnauthorized)
 			return
 		}
@@ -90,15 +189,22 @@ func (h *HandlerSet) HandleLogin(w http.ResponseWriter, req *http.Request) {
 	}
 
 	if !auth.CheckPasswordHash(password, passwordInDB) {
+		h.recordAccountAudit(req, username, types.AuditLoginFailed)
 		http.Error(w, "Wrong password", http.StatusUnauthorized)
 		return
 	}
 
-	err = auth.SetToken(username, w, h.secret)
+	started, err := h.startSession(w, req, username, false)
 	if err != nil {
+		fmt.Println(err.Error())
 		http.Error(w, "Something went wrong",
 			http.StatusInternalServerError)
+		return
+	}
+	if !started {
+		return
 	}
+	h.recordAccountAudit(req, username, types.AuditLogin)
 
 	w.Header().Set("content-type", "text/plain")
 
@@ -119,35 +225,54 @@ func (h *HandlerSet) HandleRegisterUser(w http.ResponseWriter, req *http.Request
 		return
 	}
 
-	username, password, err := h.parseAuthData(body)
+	data, err := h.parseAuthData(body)
 
 	if err != nil {
 		h.handleAuthErrors(err, w)
 		return
 	}
+	username, ok := h.checkRegistration(w, data)
+	if !ok {
+		return
+	}
 
-	hashed, err := auth.HashPassword(password)
+	hashed, err := auth.HashPassword(data.Password)
 	if err != nil {
 		http.Error(w, "Something went wrong",
 			http.StatusInternalServerError)
 		return
 	}
 
-	err = h.database.CreateUser(req.Context(), username, hashed)
+	if h.registration.Mode == types.RegistrationInvite {
+		err = h.database.CreateUserWithInvite(req.Context(), username, hashed, hashSecret(data.Invite))
+	} else {
+		err = h.database.CreateUser(req.Context(), username, hashed)
+	}
 	if err != nil {
 		var userExists *db.UserExistsError
 		if errors.As(err, &userExists) {
 			http.Error(w, "User exists", http.StatusConflict)
 			return
 		}
+		var inviteInvalid *db.InviteInvalidError
+		if errors.As(err, &inviteInvalid) {
+			http.Error(w, inviteInvalid.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.recordAccountAudit(req, username, types.AuditRegister)
 
-	err = auth.SetToken(username, w, h.secret)
+	started, err := h.startSession(w, req, username, false)
 	if err != nil {
+		fmt.Println(err.Error())
 		http.Error(w, "Something went wrong",
 			http.StatusInternalServerError)
+		return
+	}
+	if !started {
+		return
 	}
 
 	w.Header().Set("Content-Type", "text/plain")
@@ -186,6 +311,7 @@ func (h *HandlerSet) HandleStoreLoginAndPassword(w http.ResponseWriter, req *htt
 		return
 	}
 
+	h.recordAudit(req, userID, types.AuditItemCreate, logopass.Item.Key)
 	w.WriteHeader(http.StatusCreated)
 }
 
@@ -214,6 +340,7 @@ func (h *HandlerSet) HandleUpdateLoginAndPassword(w http.ResponseWriter, req *ht
 			http.StatusInternalServerError)
 		return
 	}
+	h.recordAudit(req, userID, types.AuditItemUpdate, logopass.Item.Key)
 }
 
 // HandleUpdateCreditCard хендлер, обрабатывающий запрос на изменени данных, хранимых не сервере типа "кредитная карта"
@@ -240,6 +367,7 @@ func (h *HandlerSet) HandleUpdateCreditCard(w http.ResponseWriter, req *http.Req
 			http.StatusInternalServerError)
 		return
 	}
+	h.recordAudit(req, userID, types.AuditItemUpdate, card.Item.Key)
 }
 
 // HandleUpdateText обрабатывает запрос на обновление текстовых данных, хранимых на сервере
@@ -270,6 +398,7 @@ func (h *HandlerSet) HandleUpdateText(w http.ResponseWriter, req *http.Request)
 			http.StatusInternalServerError)
 		return
 	}
+	h.recordAudit(req, userID, types.AuditItemUpdate, text.Item.Key)
 }
 
 // HandleStoreText обрабатывает запрос на создание текстовых данных для хранения на сервере
@@ -301,6 +430,7 @@ func (h *HandlerSet) HandleStoreText(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	h.recordAudit(req, userID, types.AuditItemCreate, text.Item.Key)
 	w.WriteHeader(http.StatusCreated)
 }
 
@@ -333,9 +463,130 @@ func (h *HandlerSet) HandleStoreCreditCard(w http.ResponseWriter, req *http.Requ
 		return
 	}
 
+	h.recordAudit(req, userID, types.AuditItemCreate, card.Item.Key)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// HandleStoreSSHKey обрабатывает запрос на сохранение SSH-ключа на сервере
+func (h *HandlerSet) HandleStoreSSHKey(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+
+	if err != nil {
+		return
+	}
+
+	key, err := h.prepareSSHKeyItem(w, req)
+	if err != nil {
+		return
+	}
+	err = h.database.InsertSSHKey(req.Context(), userID, *key)
+
+	if err != nil {
+		var keyExistsError *db.KeyExistsError
+		if errors.As(err, &keyExistsError) {
+			http.Error(w, "Key exists", http.StatusConflict)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+		return
+	}
+
+	h.recordAudit(req, userID, types.AuditItemCreate, key.Item.Key)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// HandleUpdateSSHKey обрабатывает запрос на обновление SSH-ключа, хранимого на сервере
+func (h *HandlerSet) HandleUpdateSSHKey(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+
+	if err != nil {
+		return
+	}
+
+	key, err := h.prepareSSHKeyItem(w, req)
+	if err != nil {
+		return
+	}
+	err = h.database.UpdateSSHKey(req.Context(), userID, *key)
+
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Could not update",
+			http.StatusInternalServerError)
+		return
+	}
+	h.recordAudit(req, userID, types.AuditItemUpdate, key.Item.Key)
+}
+
+// HandleStoreTOTP обрабатывает запрос на сохранение TOTP-секрета на сервере
+func (h *HandlerSet) HandleStoreTOTP(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+
+	if err != nil {
+		return
+	}
+
+	secret, err := h.prepareTOTPItem(w, req)
+	if err != nil {
+		return
+	}
+	err = h.database.InsertTOTP(req.Context(), userID, *secret)
+
+	if err != nil {
+		var keyExistsError *db.KeyExistsError
+		if errors.As(err, &keyExistsError) {
+			http.Error(w, "Key exists", http.StatusConflict)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+		return
+	}
+
+	h.recordAudit(req, userID, types.AuditItemCreate, secret.Item.Key)
 	w.WriteHeader(http.StatusCreated)
 }
 
+// HandleUpdateTOTP обрабатывает запрос на обновление TOTP-секрета, хранимого на сервере
+func (h *HandlerSet) HandleUpdateTOTP(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+
+	if err != nil {
+		return
+	}
+
+	secret, err := h.prepareTOTPItem(w, req)
+	if err != nil {
+		return
+	}
+	err = h.database.UpdateTOTP(req.Context(), userID, *secret)
+
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Could not update",
+			http.StatusInternalServerError)
+		return
+	}
+	h.recordAudit(req, userID, types.AuditItemUpdate, secret.Item.Key)
+}
+
 // HandleStoreBinaryItem обрабатывает запрос на сохранение бинарных данных на сервере
 func (h *HandlerSet) HandleStoreBinaryItem(w http.ResponseWriter, req *http.Request) {
 
@@ -365,6 +616,7 @@ func (h *HandlerSet) HandleStoreBinaryItem(w http.ResponseWriter, req *http.Requ
 			http.StatusInternalServerError)
 		return
 	}
+	h.recordAudit(req, userID, types.AuditItemCreate, item.Item.Key)
 	w.WriteHeader(http.StatusCreated)
 }
 
@@ -398,6 +650,7 @@ func (h *HandlerSet) HandleUpdateBinaryItem(w http.ResponseWriter, req *http.Req
 			http.StatusInternalServerError)
 		return
 	}
+	h.recordAudit(req, userID, types.AuditItemUpdate, item.Item.Key)
 }
 
 // HandleDownloadBinaryItem обрабатывает запрос на скачивание бинарных данных
@@ -407,6 +660,11 @@ func (h *HandlerSet) HandleDownloadBinaryItem(w http.ResponseWriter, req *http.R
 	if err != nil {
 		return
 	}
+	h.downloadBinaryItem(w, req, userID)
+}
+
+// downloadBinaryItem отдаёт бинарные данные записи пользователя userID
+func (h *HandlerSet) downloadBinaryItem(w http.ResponseWriter, req *http.Request, userID int) {
 
 	idString := req.PathValue("key")
 
@@ -414,19 +672,172 @@ func (h *HandlerSet) HandleDownloadBinaryItem(w http.ResponseWriter, req *http.R
 		http.Error(w, "Key not passed", http.StatusBadRequest)
 		return
 	}
-	data, err := h.database.GetBinaryData(req.Context(), userID, idString)
+	data, meta, err := h.database.GetBinaryData(req.Context(), userID, idString)
+
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+		fmt.Println(err.Error())
+		return
+	}
+	h.recordAudit(req, userID, types.AuditItemDownload, idString)
+
+	w.Header().Set("content-type", "application/octet-stream")
+	if meta != nil {
+		// имя файла и контрольная сумма зашифрованы, клиент расшифровывает их сам
+		w.Header().Set("content-type", meta.ContentType)
+		w.Header().Set("content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": meta.FileName}))
+		w.Header().Set(BinarySizeHeader, strconv.FormatInt(meta.Size, 10))
+		w.Header().Set(BinarySHA256Header, meta.SHA256)
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+	}
+}
+
+// HandleStoreAttachment обрабатывает запрос на прикрепление вложения к записи. Тело запроса - зашифрованные
+// на клиенте данные вложения, имя и MIME-тип передаются зашифрованными в заголовках. В ответе - идентификатор вложения
+func (h *HandlerSet) HandleStoreAttachment(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+
+	if err != nil {
+		return
+	}
+
+	key := req.PathValue("key")
+	name := req.Header.Get(AttachmentNameHeader)
+	mimeType := req.Header.Get(AttachmentTypeHeader)
+
+	if key == "" || name == "" || mimeType == "" {
+		http.Error(w, "Key, name or type not passed", http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, req.Body, MaxAttachmentSize))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(w, "Attachment too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+		return
+	}
+
+	attachment := types.Attachment{Name: name, MimeType: mimeType, Size: len(data), Encrypted: true}
+
+	id, err := h.database.InsertAttachment(req.Context(), userID, key, attachment, data)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+		return
+	}
+	h.recordAudit(req, userID, types.AuditAttachmentCreate, attachmentAuditKey(key, id))
+
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(types.CreatedAttachment{ID: id})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+// HandleAttachmentList обрабатывает запрос на получение списка вложений записи
+func (h *HandlerSet) HandleAttachmentList(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+
+	if err != nil {
+		return
+	}
+
+	key := req.PathValue("key")
+
+	if key == "" {
+		http.Error(w, "Key not passed", http.StatusBadRequest)
+		return
+	}
 
+	attachments, err := h.database.GetAttachments(req.Context(), userID, key)
 	if err != nil {
 		var keyNotFound *db.KeyNotFoundError
 		if errors.As(err, &keyNotFound) {
 			http.Error(w, "Not found", http.StatusNotFound)
 			return
 		}
+		fmt.Println(err.Error())
 		http.Error(w, "Something went wrong",
 			http.StatusInternalServerError)
+		return
+	}
+	if attachments == nil {
+		attachments = []types.Attachment{}
+	}
+
+	data, err := json.Marshal(attachments)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	_, err = w.Write(data)
+	if err != nil {
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+	}
+}
+
+// HandleGetAttachment обрабатывает запрос на скачивание вложения. Имя и MIME-тип клиент берёт из списка вложений
+func (h *HandlerSet) HandleGetAttachment(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+
+	if err != nil {
+		return
+	}
+
+	key := req.PathValue("key")
+	if key == "" {
+		http.Error(w, "Key not passed", http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Wrong id", http.StatusBadRequest)
+		return
+	}
+
+	_, data, err := h.database.GetAttachment(req.Context(), userID, key, id)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
 		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
 		return
 	}
+	h.recordAudit(req, userID, types.AuditAttachmentRead, attachmentAuditKey(key, id))
 
 	w.Header().Set("content-type", "application/octet-stream")
 	_, err = w.Write(data)
@@ -437,6 +848,98 @@ func (h *HandlerSet) HandleDownloadBinaryItem(w http.ResponseWriter, req *http.R
 	}
 }
 
+// HandleDeleteAttachment обрабатывает запрос на удаление вложения
+func (h *HandlerSet) HandleDeleteAttachment(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+
+	if err != nil {
+		return
+	}
+
+	key := req.PathValue("key")
+	if key == "" {
+		http.Error(w, "Key not passed", http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Wrong id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.DeleteAttachment(req.Context(), userID, key, id)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+		return
+	}
+	h.recordAudit(req, userID, types.AuditAttachmentDelete, attachmentAuditKey(key, id))
+}
+
+// HandleReplaceAttachment обрабатывает запрос на замену вложения, например перешифрованного новым ключом хранилища.
+// Тело запроса и заголовки - как при прикреплении вложения
+func (h *HandlerSet) HandleReplaceAttachment(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+
+	if err != nil {
+		return
+	}
+
+	key := req.PathValue("key")
+	name := req.Header.Get(AttachmentNameHeader)
+	mimeType := req.Header.Get(AttachmentTypeHeader)
+
+	if key == "" || name == "" || mimeType == "" {
+		http.Error(w, "Key, name or type not passed", http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Wrong id", http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, req.Body, MaxAttachmentSize))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(w, "Attachment too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+		return
+	}
+
+	attachment := types.Attachment{ID: id, Name: name, MimeType: mimeType, Size: len(data), Encrypted: true}
+
+	err = h.database.ReplaceAttachment(req.Context(), userID, key, id, attachment, data)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+		return
+	}
+	h.recordAudit(req, userID, types.AuditAttachmentUpdate, attachmentAuditKey(key, id))
+}
+
+func attachmentAuditKey(key string, id int) string {
+	return key + "/" + strconv.Itoa(id)
+}
+
 // HandleItemList обрабатывает запрос на получение списка метаданных о записях, хранимых на сервере
 func (h *HandlerSet) HandleItemList(w http.ResponseWriter, req *http.Request) {
 
@@ -445,10 +948,16 @@ func (h *HandlerSet) HandleItemList(w http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		return
 	}
+	h.itemList(w, req, userID)
+}
+
+// itemList отдаёт страницу списка записей пользователя userID
+func (h *HandlerSet) itemList(w http.ResponseWriter, req *http.Request, userID int) {
 	s := req.URL.Query().Get("page")
 
 	var page int
 	var limit int
+	var err error
 
 	if s == "" {
 		page = 1
@@ -505,6 +1014,11 @@ func (h *HandlerSet) HandleGetItem(w http.ResponseWriter, req *http.Request) {
 			http.StatusUnauthorized)
 		return
 	}
+	h.getItem(w, req, userID)
+}
+
+// getItem отдаёт запись пользователя userID
+func (h *HandlerSet) getItem(w http.ResponseWriter, req *http.Request, userID int) {
 
 	idString := req.PathValue("key")
 
@@ -568,6 +1082,34 @@ func (h *HandlerSet) HandleGetItem(w http.ResponseWriter, req *http.Request) {
 			http.Error(w, "Something went wrong", http.StatusInternalServerError)
 			return
 		}
+	case types.TypeSSHKey:
+		key, err := h.database.GetSSHKey(req.Context(), item.Id)
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "Something went wrong", http.StatusInternalServerError)
+			return
+		}
+		result := types.SSHKeyItem{Item: *item, Data: key}
+		data, err = json.Marshal(result)
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "Something went wrong", http.StatusInternalServerError)
+			return
+		}
+	case types.TypeTOTP:
+		secret, err := h.database.GetTOTP(req.Context(), item.Id)
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "Something went wrong", http.StatusInternalServerError)
+			return
+		}
+		result := types.TOTPItem{Item: *item, Data: secret}
+		data, err = json.Marshal(result)
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "Something went wrong", http.StatusInternalServerError)
+			return
+		}
 	case types.TypeBinary:
 		// only metadata in this handler
 		item, err := h.database.GetItem(req.Context(), userID, item.Key)
@@ -576,7 +1118,13 @@ func (h *HandlerSet) HandleGetItem(w http.ResponseWriter, req *http.Request) {
 			http.Error(w, "Something went wrong", http.StatusInternalServerError)
 			return
 		}
-		result := types.BinaryItem{Item: *item, Data: []byte{}}
+		meta, err := h.database.GetBinaryMeta(req.Context(), item.Id)
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "Something went wrong", http.StatusInternalServerError)
+			return
+		}
+		result := types.BinaryItem{Item: *item, Data: []byte{}, Meta: meta}
 		data, err = json.Marshal(result)
 		if err != nil {
 			fmt.Println(err.Error())
@@ -584,6 +1132,7 @@ func (h *HandlerSet) HandleGetItem(w http.ResponseWriter, req *http.Request) {
 			return
 		}
 	}
+	h.recordAudit(req, userID, types.AuditItemRead, idString)
 	w.Header().Set("content-type", "application/json")
 	_, err = w.Write(data)
 	if err != nil {
@@ -616,6 +1165,7 @@ func (h *HandlerSet) HandleDeleteItem(w http.ResponseWriter, req *http.Request)
 			http.StatusInternalServerError)
 		return
 	}
+	h.recordAudit(req, userID, types.AuditItemDelete, idString)
 }
 
 func (h *HandlerSet) prepareLoginAndPasswordItem(w http.ResponseWriter, req *http.Request) (*types.LoginPasswordItem, error) {
@@ -678,6 +1228,46 @@ func (h *HandlerSet) prepareCreditCardItem(w http.ResponseWriter, req *http.Requ
 	return card, nil
 }
 
+func (h *HandlerSet) prepareSSHKeyItem(w http.ResponseWriter, req *http.Request) (*types.SSHKeyItem, error) {
+
+	var key *types.SSHKeyItem
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+		return nil, err
+	}
+	err = json.Unmarshal(body, &key)
+
+	if err != nil || key == nil || key.Data == nil {
+		http.Error(w, "Could not unmarshal body",
+			http.StatusBadRequest)
+		return nil, fmt.Errorf("could not unmarshal body %w", err)
+	}
+	return key, nil
+}
+
+func (h *HandlerSet) prepareTOTPItem(w http.ResponseWriter, req *http.Request) (*types.TOTPItem, error) {
+
+	var secret *types.TOTPItem
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+		return nil, err
+	}
+	err = json.Unmarshal(body, &secret)
+
+	if err != nil || secret == nil || secret.Data == nil {
+		http.Error(w, "Could not unmarshal body",
+			http.StatusBadRequest)
+		return nil, fmt.Errorf("could not unmarshal body %w", err)
+	}
+	return secret, nil
+}
+
 func (h *HandlerSet) prepareBinaryItem(w http.ResponseWriter, r *http.Request) (*types.BinaryItem, error) {
 
 	contentType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
@@ -708,6 +1298,14 @@ func (h *HandlerSet) prepareBinaryItem(w http.ResponseWriter, r *http.Request) (
 		}
 		switch part.Header.Get("Content-Type") {
 		case "application/json":
+			if part.FormName() == "meta" {
+				err = json.Unmarshal(fileBytes, &item.Meta)
+				if err != nil {
+					http.Error(w, "failed to read file metadata", http.StatusBadRequest)
+					return nil, err
+				}
+				break
+			}
 			err = json.Unmarshal(fileBytes, &item.Item)
 			if err != nil {
 				http.Error(w, "failed to read metadata", http.StatusBadRequest)
@@ -738,23 +1336,20 @@ func (h *HandlerSet) handleAuthorizeUser(w http.ResponseWriter, req *http.Reques
 
 }
 
-func (h *HandlerSet) parseAuthData(body []byte) (username string, password string, err error) {
+func (h *HandlerSet) parseAuthData(body []byte) (authData, error) {
 
-	var data struct {
-		Username string `json:"login"`
-		Password string `json:"password"`
-	}
+	var data authData
 
-	err = json.Unmarshal(body, &data)
+	err := json.Unmarshal(body, &data)
 	if err != nil {
-		return "", "", ErrCouldNotParseBody
+		return authData{}, ErrCouldNotParseBody
 	}
 
 	if data.Username == "" || data.Password == "" {
-		return "", "", ErrAuthDataEmpty
+		return authData{}, ErrAuthDataEmpty
 	}
 
-	return data.Username, data.Password, nil
+	return data, nil
 
 }
 
@@ -4,6 +4,7 @@ package handlers
 
 import (
 	context "context"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 	types "github.com/wellywell/gophkeeper/internal/types"
@@ -22,16 +23,16 @@ func (_m *MockDatabase) EXPECT() *MockDatabase_Expecter {
 	return &MockDatabase_Expecter{mock: &_m.Mock}
 }
 
-// CreateUser provides a mock function with given fields: _a0, _a1, _a2
-func (_m *MockDatabase) CreateUser(_a0 context.Context, _a1 string, _a2 string) error {
+// AcceptInvite provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) AcceptInvite(_a0 context.Context, _a1 int, _a2 int) error {
 	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateUser")
+		panic("no return value specified for AcceptInvite")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
@@ -40,47 +41,47 @@ func (_m *MockDatabase) CreateUser(_a0 context.Context, _a1 string, _a2 string)
 	return r0
 }
 
-// MockDatabase_CreateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateUser'
-type MockDatabase_CreateUser_Call struct {
+// MockDatabase_AcceptInvite_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AcceptInvite'
+type MockDatabase_AcceptInvite_Call struct {
 	*mock.Call
 }
 
-// CreateUser is a helper method to define mock.On call
+// AcceptInvite is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 string
-//   - _a2 string
-func (_e *MockDatabase_Expecter) CreateUser(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_CreateUser_Call {
-	return &MockDatabase_CreateUser_Call{Call: _e.mock.On("CreateUser", _a0, _a1, _a2)}
+//   - _a1 int
+//   - _a2 int
+func (_e *MockDatabase_Expecter) AcceptInvite(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_AcceptInvite_Call {
+	return &MockDatabase_AcceptInvite_Call{Call: _e.mock.On("AcceptInvite", _a0, _a1, _a2)}
 }
 
-func (_c *MockDatabase_CreateUser_Call) Run(run func(_a0 context.Context, _a1 string, _a2 string)) *MockDatabase_CreateUser_Call {
+func (_c *MockDatabase_AcceptInvite_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int)) *MockDatabase_AcceptInvite_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_CreateUser_Call) Return(_a0 error) *MockDatabase_CreateUser_Call {
+func (_c *MockDatabase_AcceptInvite_Call) Return(_a0 error) *MockDatabase_AcceptInvite_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_CreateUser_Call) RunAndReturn(run func(context.Context, string, string) error) *MockDatabase_CreateUser_Call {
+func (_c *MockDatabase_AcceptInvite_Call) RunAndReturn(run func(context.Context, int, int) error) *MockDatabase_AcceptInvite_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteItem provides a mock function with given fields: _a0, _a1, _a2
-func (_m *MockDatabase) DeleteItem(_a0 context.Context, _a1 int, _a2 string) error {
-	ret := _m.Called(_a0, _a1, _a2)
+// AddMember provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *MockDatabase) AddMember(_a0 context.Context, _a1 int, _a2 string, _a3 types.Role, _a4 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteItem")
+		panic("no return value specified for AddMember")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, types.Role, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -88,59 +89,61 @@ func (_m *MockDatabase) DeleteItem(_a0 context.Context, _a1 int, _a2 string) err
 	return r0
 }
 
-// MockDatabase_DeleteItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteItem'
-type MockDatabase_DeleteItem_Call struct {
+// MockDatabase_AddMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddMember'
+type MockDatabase_AddMember_Call struct {
 	*mock.Call
 }
 
-// DeleteItem is a helper method to define mock.On call
+// AddMember is a helper method to define mock.On call
 //   - _a0 context.Context
 //   - _a1 int
 //   - _a2 string
-func (_e *MockDatabase_Expecter) DeleteItem(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_DeleteItem_Call {
-	return &MockDatabase_DeleteItem_Call{Call: _e.mock.On("DeleteItem", _a0, _a1, _a2)}
+//   - _a3 types.Role
+//   - _a4 string
+func (_e *MockDatabase_Expecter) AddMember(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}, _a4 interface{}) *MockDatabase_AddMember_Call {
+	return &MockDatabase_AddMember_Call{Call: _e.mock.On("AddMember", _a0, _a1, _a2, _a3, _a4)}
 }
 
-func (_c *MockDatabase_DeleteItem_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_DeleteItem_Call {
+func (_c *MockDatabase_AddMember_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 types.Role, _a4 string)) *MockDatabase_AddMember_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(string))
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(types.Role), args[4].(string))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_DeleteItem_Call) Return(_a0 error) *MockDatabase_DeleteItem_Call {
+func (_c *MockDatabase_AddMember_Call) Return(_a0 error) *MockDatabase_AddMember_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_DeleteItem_Call) RunAndReturn(run func(context.Context, int, string) error) *MockDatabase_DeleteItem_Call {
+func (_c *MockDatabase_AddMember_Call) RunAndReturn(run func(context.Context, int, string, types.Role, string) error) *MockDatabase_AddMember_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetBinaryData provides a mock function with given fields: _a0, _a1, _a2
-func (_m *MockDatabase) GetBinaryData(_a0 context.Context, _a1 int, _a2 string) ([]byte, error) {
-	ret := _m.Called(_a0, _a1, _a2)
+// AdminListUsers provides a mock function with given fields: _a0
+func (_m *MockDatabase) AdminListUsers(_a0 context.Context) ([]types.AdminUser, error) {
+	ret := _m.Called(_a0)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetBinaryData")
+		panic("no return value specified for AdminListUsers")
 	}
 
-	var r0 []byte
+	var r0 []types.AdminUser
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, string) ([]byte, error)); ok {
-		return rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(context.Context) ([]types.AdminUser, error)); ok {
+		return rf(_a0)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, string) []byte); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(context.Context) []types.AdminUser); ok {
+		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).([]types.AdminUser)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
-		r1 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -148,59 +151,55 @@ func (_m *MockDatabase) GetBinaryData(_a0 context.Context, _a1 int, _a2 string)
 	return r0, r1
 }
 
-// MockDatabase_GetBinaryData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBinaryData'
-type MockDatabase_GetBinaryData_Call struct {
+// MockDatabase_AdminListUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AdminListUsers'
+type MockDatabase_AdminListUsers_Call struct {
 	*mock.Call
 }
 
-// GetBinaryData is a helper method to define mock.On call
+// AdminListUsers is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 int
-//   - _a2 string
-func (_e *MockDatabase_Expecter) GetBinaryData(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_GetBinaryData_Call {
-	return &MockDatabase_GetBinaryData_Call{Call: _e.mock.On("GetBinaryData", _a0, _a1, _a2)}
+func (_e *MockDatabase_Expecter) AdminListUsers(_a0 interface{}) *MockDatabase_AdminListUsers_Call {
+	return &MockDatabase_AdminListUsers_Call{Call: _e.mock.On("AdminListUsers", _a0)}
 }
 
-func (_c *MockDatabase_GetBinaryData_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_GetBinaryData_Call {
+func (_c *MockDatabase_AdminListUsers_Call) Run(run func(_a0 context.Context)) *MockDatabase_AdminListUsers_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(string))
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_GetBinaryData_Call) Return(_a0 []byte, _a1 error) *MockDatabase_GetBinaryData_Call {
+func (_c *MockDatabase_AdminListUsers_Call) Return(_a0 []types.AdminUser, _a1 error) *MockDatabase_AdminListUsers_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockDatabase_GetBinaryData_Call) RunAndReturn(run func(context.Context, int, string) ([]byte, error)) *MockDatabase_GetBinaryData_Call {
+func (_c *MockDatabase_AdminListUsers_Call) RunAndReturn(run func(context.Context) ([]types.AdminUser, error)) *MockDatabase_AdminListUsers_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetCreditCard provides a mock function with given fields: _a0, _a1
-func (_m *MockDatabase) GetCreditCard(_a0 context.Context, _a1 int) (*types.CreditCardData, error) {
-	ret := _m.Called(_a0, _a1)
+// AdminStats provides a mock function with given fields: _a0
+func (_m *MockDatabase) AdminStats(_a0 context.Context) (types.AdminStats, error) {
+	ret := _m.Called(_a0)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetCreditCard")
+		panic("no return value specified for AdminStats")
 	}
 
-	var r0 *types.CreditCardData
+	var r0 types.AdminStats
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.CreditCardData, error)); ok {
-		return rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context) (types.AdminStats, error)); ok {
+		return rf(_a0)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) *types.CreditCardData); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context) types.AdminStats); ok {
+		r0 = rf(_a0)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*types.CreditCardData)
-		}
+		r0 = ret.Get(0).(types.AdminStats)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(_a0, _a1)
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -208,238 +207,248 @@ func (_m *MockDatabase) GetCreditCard(_a0 context.Context, _a1 int) (*types.Cred
 	return r0, r1
 }
 
-// MockDatabase_GetCreditCard_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCreditCard'
-type MockDatabase_GetCreditCard_Call struct {
+// MockDatabase_AdminStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AdminStats'
+type MockDatabase_AdminStats_Call struct {
 	*mock.Call
 }
 
-// GetCreditCard is a helper method to define mock.On call
+// AdminStats is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 int
-func (_e *MockDatabase_Expecter) GetCreditCard(_a0 interface{}, _a1 interface{}) *MockDatabase_GetCreditCard_Call {
-	return &MockDatabase_GetCreditCard_Call{Call: _e.mock.On("GetCreditCard", _a0, _a1)}
+func (_e *MockDatabase_Expecter) AdminStats(_a0 interface{}) *MockDatabase_AdminStats_Call {
+	return &MockDatabase_AdminStats_Call{Call: _e.mock.On("AdminStats", _a0)}
 }
 
-func (_c *MockDatabase_GetCreditCard_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetCreditCard_Call {
+func (_c *MockDatabase_AdminStats_Call) Run(run func(_a0 context.Context)) *MockDatabase_AdminStats_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_GetCreditCard_Call) Return(_a0 *types.CreditCardData, _a1 error) *MockDatabase_GetCreditCard_Call {
+func (_c *MockDatabase_AdminStats_Call) Return(_a0 types.AdminStats, _a1 error) *MockDatabase_AdminStats_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockDatabase_GetCreditCard_Call) RunAndReturn(run func(context.Context, int) (*types.CreditCardData, error)) *MockDatabase_GetCreditCard_Call {
+func (_c *MockDatabase_AdminStats_Call) RunAndReturn(run func(context.Context) (types.AdminStats, error)) *MockDatabase_AdminStats_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetItem provides a mock function with given fields: _a0, _a1, _a2
-func (_m *MockDatabase) GetItem(_a0 context.Context, _a1 int, _a2 string) (*types.Item, error) {
-	ret := _m.Called(_a0, _a1, _a2)
+// AppendAuditEvent provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) AppendAuditEvent(_a0 context.Context, _a1 types.AuditEvent) error {
+	ret := _m.Called(_a0, _a1)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetItem")
+		panic("no return value specified for AppendAuditEvent")
 	}
 
-	var r0 *types.Item
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, string) (*types.Item, error)); ok {
-		return rf(_a0, _a1, _a2)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, string) *types.Item); ok {
-		r0 = rf(_a0, _a1, _a2)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*types.Item)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
-		r1 = rf(_a0, _a1, _a2)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, types.AuditEvent) error); ok {
+		r0 = rf(_a0, _a1)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// MockDatabase_GetItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetItem'
-type MockDatabase_GetItem_Call struct {
+// MockDatabase_AppendAuditEvent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AppendAuditEvent'
+type MockDatabase_AppendAuditEvent_Call struct {
 	*mock.Call
 }
 
-// GetItem is a helper method to define mock.On call
+// AppendAuditEvent is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 int
-//   - _a2 string
-func (_e *MockDatabase_Expecter) GetItem(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_GetItem_Call {
-	return &MockDatabase_GetItem_Call{Call: _e.mock.On("GetItem", _a0, _a1, _a2)}
+//   - _a1 types.AuditEvent
+func (_e *MockDatabase_Expecter) AppendAuditEvent(_a0 interface{}, _a1 interface{}) *MockDatabase_AppendAuditEvent_Call {
+	return &MockDatabase_AppendAuditEvent_Call{Call: _e.mock.On("AppendAuditEvent", _a0, _a1)}
 }
 
-func (_c *MockDatabase_GetItem_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_GetItem_Call {
+func (_c *MockDatabase_AppendAuditEvent_Call) Run(run func(_a0 context.Context, _a1 types.AuditEvent)) *MockDatabase_AppendAuditEvent_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(string))
+		run(args[0].(context.Context), args[1].(types.AuditEvent))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_GetItem_Call) Return(_a0 *types.Item, _a1 error) *MockDatabase_GetItem_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockDatabase_AppendAuditEvent_Call) Return(_a0 error) *MockDatabase_AppendAuditEvent_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_GetItem_Call) RunAndReturn(run func(context.Context, int, string) (*types.Item, error)) *MockDatabase_GetItem_Call {
+func (_c *MockDatabase_AppendAuditEvent_Call) RunAndReturn(run func(context.Context, types.AuditEvent) error) *MockDatabase_AppendAuditEvent_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetItems provides a mock function with given fields: _a0, _a1, _a2, _a3
-func (_m *MockDatabase) GetItems(_a0 context.Context, _a1 int, _a2 int, _a3 int) ([]types.Item, error) {
+// ApproveDevice provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) ApproveDevice(_a0 context.Context, _a1 int, _a2 int, _a3 string) error {
 	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetItems")
+		panic("no return value specified for ApproveDevice")
 	}
 
-	var r0 []types.Item
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]types.Item, error)); ok {
-		return rf(_a0, _a1, _a2, _a3)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []types.Item); ok {
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, string) error); ok {
 		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]types.Item)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) error); ok {
-		r1 = rf(_a0, _a1, _a2, _a3)
-	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// MockDatabase_GetItems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetItems'
-type MockDatabase_GetItems_Call struct {
+// MockDatabase_ApproveDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ApproveDevice'
+type MockDatabase_ApproveDevice_Call struct {
 	*mock.Call
 }
 
-// GetItems is a helper method to define mock.On call
+// ApproveDevice is a helper method to define mock.On call
 //   - _a0 context.Context
 //   - _a1 int
 //   - _a2 int
-//   - _a3 int
-func (_e *MockDatabase_Expecter) GetItems(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_GetItems_Call {
-	return &MockDatabase_GetItems_Call{Call: _e.mock.On("GetItems", _a0, _a1, _a2, _a3)}
+//   - _a3 string
+func (_e *MockDatabase_Expecter) ApproveDevice(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_ApproveDevice_Call {
+	return &MockDatabase_ApproveDevice_Call{Call: _e.mock.On("ApproveDevice", _a0, _a1, _a2, _a3)}
 }
 
-func (_c *MockDatabase_GetItems_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int, _a3 int)) *MockDatabase_GetItems_Call {
+func (_c *MockDatabase_ApproveDevice_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int, _a3 string)) *MockDatabase_ApproveDevice_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(string))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_GetItems_Call) Return(_a0 []types.Item, _a1 error) *MockDatabase_GetItems_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockDatabase_ApproveDevice_Call) Return(_a0 error) *MockDatabase_ApproveDevice_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_GetItems_Call) RunAndReturn(run func(context.Context, int, int, int) ([]types.Item, error)) *MockDatabase_GetItems_Call {
+func (_c *MockDatabase_ApproveDevice_Call) RunAndReturn(run func(context.Context, int, int, string) error) *MockDatabase_ApproveDevice_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetLogoPass provides a mock function with given fields: _a0, _a1
-func (_m *MockDatabase) GetLogoPass(_a0 context.Context, _a1 int) (*types.LoginPassword, error) {
+// CancelUserDeletion provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) CancelUserDeletion(_a0 context.Context, _a1 int) error {
 	ret := _m.Called(_a0, _a1)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetLogoPass")
+		panic("no return value specified for CancelUserDeletion")
 	}
 
-	var r0 *types.LoginPassword
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.LoginPassword, error)); ok {
-		return rf(_a0, _a1)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) *types.LoginPassword); ok {
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
 		r0 = rf(_a0, _a1)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*types.LoginPassword)
-		}
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(_a0, _a1)
+	return r0
+}
+
+// MockDatabase_CancelUserDeletion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelUserDeletion'
+type MockDatabase_CancelUserDeletion_Call struct {
+	*mock.Call
+}
+
+// CancelUserDeletion is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) CancelUserDeletion(_a0 interface{}, _a1 interface{}) *MockDatabase_CancelUserDeletion_Call {
+	return &MockDatabase_CancelUserDeletion_Call{Call: _e.mock.On("CancelUserDeletion", _a0, _a1)}
+}
+
+func (_c *MockDatabase_CancelUserDeletion_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_CancelUserDeletion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_CancelUserDeletion_Call) Return(_a0 error) *MockDatabase_CancelUserDeletion_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_CancelUserDeletion_Call) RunAndReturn(run func(context.Context, int) error) *MockDatabase_CancelUserDeletion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateEmergencyAccess provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *MockDatabase) CreateEmergencyAccess(_a0 context.Context, _a1 int, _a2 int, _a3 int, _a4 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateEmergencyAccess")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// MockDatabase_GetLogoPass_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLogoPass'
-type MockDatabase_GetLogoPass_Call struct {
+// MockDatabase_CreateEmergencyAccess_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateEmergencyAccess'
+type MockDatabase_CreateEmergencyAccess_Call struct {
 	*mock.Call
 }
 
-// GetLogoPass is a helper method to define mock.On call
+// CreateEmergencyAccess is a helper method to define mock.On call
 //   - _a0 context.Context
 //   - _a1 int
-func (_e *MockDatabase_Expecter) GetLogoPass(_a0 interface{}, _a1 interface{}) *MockDatabase_GetLogoPass_Call {
-	return &MockDatabase_GetLogoPass_Call{Call: _e.mock.On("GetLogoPass", _a0, _a1)}
+//   - _a2 int
+//   - _a3 int
+//   - _a4 string
+func (_e *MockDatabase_Expecter) CreateEmergencyAccess(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}, _a4 interface{}) *MockDatabase_CreateEmergencyAccess_Call {
+	return &MockDatabase_CreateEmergencyAccess_Call{Call: _e.mock.On("CreateEmergencyAccess", _a0, _a1, _a2, _a3, _a4)}
 }
 
-func (_c *MockDatabase_GetLogoPass_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetLogoPass_Call {
+func (_c *MockDatabase_CreateEmergencyAccess_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int, _a3 int, _a4 string)) *MockDatabase_CreateEmergencyAccess_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int), args[4].(string))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_GetLogoPass_Call) Return(_a0 *types.LoginPassword, _a1 error) *MockDatabase_GetLogoPass_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockDatabase_CreateEmergencyAccess_Call) Return(_a0 error) *MockDatabase_CreateEmergencyAccess_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_GetLogoPass_Call) RunAndReturn(run func(context.Context, int) (*types.LoginPassword, error)) *MockDatabase_GetLogoPass_Call {
+func (_c *MockDatabase_CreateEmergencyAccess_Call) RunAndReturn(run func(context.Context, int, int, int, string) error) *MockDatabase_CreateEmergencyAccess_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetText provides a mock function with given fields: _a0, _a1
-func (_m *MockDatabase) GetText(_a0 context.Context, _a1 int) (*types.TextData, error) {
-	ret := _m.Called(_a0, _a1)
+// CreateInvite provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *MockDatabase) CreateInvite(_a0 context.Context, _a1 string, _a2 int, _a3 time.Time, _a4 string) (int, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetText")
+		panic("no return value specified for CreateInvite")
 	}
 
-	var r0 *types.TextData
+	var r0 int
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.TextData, error)); ok {
-		return rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, time.Time, string) (int, error)); ok {
+		return rf(_a0, _a1, _a2, _a3, _a4)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) *types.TextData); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, time.Time, string) int); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*types.TextData)
-		}
+		r0 = ret.Get(0).(int)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(_a0, _a1)
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, time.Time, string) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -447,159 +456,4296 @@ func (_m *MockDatabase) GetText(_a0 context.Context, _a1 int) (*types.TextData,
 	return r0, r1
 }
 
-// MockDatabase_GetText_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetText'
-type MockDatabase_GetText_Call struct {
+// MockDatabase_CreateInvite_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateInvite'
+type MockDatabase_CreateInvite_Call struct {
 	*mock.Call
 }
 
-// GetText is a helper method to define mock.On call
+// CreateInvite is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 int
-func (_e *MockDatabase_Expecter) GetText(_a0 interface{}, _a1 interface{}) *MockDatabase_GetText_Call {
-	return &MockDatabase_GetText_Call{Call: _e.mock.On("GetText", _a0, _a1)}
+//   - _a1 string
+//   - _a2 int
+//   - _a3 time.Time
+//   - _a4 string
+func (_e *MockDatabase_Expecter) CreateInvite(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}, _a4 interface{}) *MockDatabase_CreateInvite_Call {
+	return &MockDatabase_CreateInvite_Call{Call: _e.mock.On("CreateInvite", _a0, _a1, _a2, _a3, _a4)}
 }
 
-func (_c *MockDatabase_GetText_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetText_Call {
+func (_c *MockDatabase_CreateInvite_Call) Run(run func(_a0 context.Context, _a1 string, _a2 int, _a3 time.Time, _a4 string)) *MockDatabase_CreateInvite_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(time.Time), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_CreateInvite_Call) Return(_a0 int, _a1 error) *MockDatabase_CreateInvite_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_CreateInvite_Call) RunAndReturn(run func(context.Context, string, int, time.Time, string) (int, error)) *MockDatabase_CreateInvite_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateOrg provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) CreateOrg(_a0 context.Context, _a1 int, _a2 string, _a3 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOrg")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_CreateOrg_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateOrg'
+type MockDatabase_CreateOrg_Call struct {
+	*mock.Call
+}
+
+// CreateOrg is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 string
+func (_e *MockDatabase_Expecter) CreateOrg(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_CreateOrg_Call {
+	return &MockDatabase_CreateOrg_Call{Call: _e.mock.On("CreateOrg", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_CreateOrg_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 string)) *MockDatabase_CreateOrg_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_CreateOrg_Call) Return(_a0 error) *MockDatabase_CreateOrg_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_CreateOrg_Call) RunAndReturn(run func(context.Context, int, string, string) error) *MockDatabase_CreateOrg_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateSend provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *MockDatabase) CreateSend(_a0 context.Context, _a1 int, _a2 string, _a3 types.NewSend, _a4 *string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSend")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, types.NewSend, *string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_CreateSend_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateSend'
+type MockDatabase_CreateSend_Call struct {
+	*mock.Call
+}
+
+// CreateSend is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 types.NewSend
+//   - _a4 *string
+func (_e *MockDatabase_Expecter) CreateSend(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}, _a4 interface{}) *MockDatabase_CreateSend_Call {
+	return &MockDatabase_CreateSend_Call{Call: _e.mock.On("CreateSend", _a0, _a1, _a2, _a3, _a4)}
+}
+
+func (_c *MockDatabase_CreateSend_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 types.NewSend, _a4 *string)) *MockDatabase_CreateSend_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(types.NewSend), args[4].(*string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_CreateSend_Call) Return(_a0 error) *MockDatabase_CreateSend_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_CreateSend_Call) RunAndReturn(run func(context.Context, int, string, types.NewSend, *string) error) *MockDatabase_CreateSend_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateSession provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) CreateSession(_a0 context.Context, _a1 int, _a2 types.NewSession) (int, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSession")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.NewSession) (int, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.NewSession) int); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, types.NewSession) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_CreateSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateSession'
+type MockDatabase_CreateSession_Call struct {
+	*mock.Call
+}
+
+// CreateSession is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.NewSession
+func (_e *MockDatabase_Expecter) CreateSession(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_CreateSession_Call {
+	return &MockDatabase_CreateSession_Call{Call: _e.mock.On("CreateSession", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_CreateSession_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.NewSession)) *MockDatabase_CreateSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.NewSession))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_CreateSession_Call) Return(_a0 int, _a1 error) *MockDatabase_CreateSession_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_CreateSession_Call) RunAndReturn(run func(context.Context, int, types.NewSession) (int, error)) *MockDatabase_CreateSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateUser provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) CreateUser(_a0 context.Context, _a1 string, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_CreateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateUser'
+type MockDatabase_CreateUser_Call struct {
+	*mock.Call
+}
+
+// CreateUser is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 string
+//   - _a2 string
+func (_e *MockDatabase_Expecter) CreateUser(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_CreateUser_Call {
+	return &MockDatabase_CreateUser_Call{Call: _e.mock.On("CreateUser", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_CreateUser_Call) Run(run func(_a0 context.Context, _a1 string, _a2 string)) *MockDatabase_CreateUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_CreateUser_Call) Return(_a0 error) *MockDatabase_CreateUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_CreateUser_Call) RunAndReturn(run func(context.Context, string, string) error) *MockDatabase_CreateUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateUserWithInvite provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) CreateUserWithInvite(_a0 context.Context, _a1 string, _a2 string, _a3 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUserWithInvite")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_CreateUserWithInvite_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateUserWithInvite'
+type MockDatabase_CreateUserWithInvite_Call struct {
+	*mock.Call
+}
+
+// CreateUserWithInvite is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 string
+//   - _a2 string
+//   - _a3 string
+func (_e *MockDatabase_Expecter) CreateUserWithInvite(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_CreateUserWithInvite_Call {
+	return &MockDatabase_CreateUserWithInvite_Call{Call: _e.mock.On("CreateUserWithInvite", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_CreateUserWithInvite_Call) Run(run func(_a0 context.Context, _a1 string, _a2 string, _a3 string)) *MockDatabase_CreateUserWithInvite_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_CreateUserWithInvite_Call) Return(_a0 error) *MockDatabase_CreateUserWithInvite_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_CreateUserWithInvite_Call) RunAndReturn(run func(context.Context, string, string, string) error) *MockDatabase_CreateUserWithInvite_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteAttachment provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) DeleteAttachment(_a0 context.Context, _a1 int, _a2 string, _a3 int) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAttachment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_DeleteAttachment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAttachment'
+type MockDatabase_DeleteAttachment_Call struct {
+	*mock.Call
+}
+
+// DeleteAttachment is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 int
+func (_e *MockDatabase_Expecter) DeleteAttachment(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_DeleteAttachment_Call {
+	return &MockDatabase_DeleteAttachment_Call{Call: _e.mock.On("DeleteAttachment", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_DeleteAttachment_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 int)) *MockDatabase_DeleteAttachment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_DeleteAttachment_Call) Return(_a0 error) *MockDatabase_DeleteAttachment_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_DeleteAttachment_Call) RunAndReturn(run func(context.Context, int, string, int) error) *MockDatabase_DeleteAttachment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteDevice provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) DeleteDevice(_a0 context.Context, _a1 int, _a2 int) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDevice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_DeleteDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteDevice'
+type MockDatabase_DeleteDevice_Call struct {
+	*mock.Call
+}
+
+// DeleteDevice is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+func (_e *MockDatabase_Expecter) DeleteDevice(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_DeleteDevice_Call {
+	return &MockDatabase_DeleteDevice_Call{Call: _e.mock.On("DeleteDevice", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_DeleteDevice_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int)) *MockDatabase_DeleteDevice_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_DeleteDevice_Call) Return(_a0 error) *MockDatabase_DeleteDevice_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_DeleteDevice_Call) RunAndReturn(run func(context.Context, int, int) error) *MockDatabase_DeleteDevice_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteEmergencyAccess provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) DeleteEmergencyAccess(_a0 context.Context, _a1 int, _a2 int) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEmergencyAccess")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_DeleteEmergencyAccess_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteEmergencyAccess'
+type MockDatabase_DeleteEmergencyAccess_Call struct {
+	*mock.Call
+}
+
+// DeleteEmergencyAccess is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+func (_e *MockDatabase_Expecter) DeleteEmergencyAccess(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_DeleteEmergencyAccess_Call {
+	return &MockDatabase_DeleteEmergencyAccess_Call{Call: _e.mock.On("DeleteEmergencyAccess", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_DeleteEmergencyAccess_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int)) *MockDatabase_DeleteEmergencyAccess_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_DeleteEmergencyAccess_Call) Return(_a0 error) *MockDatabase_DeleteEmergencyAccess_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_DeleteEmergencyAccess_Call) RunAndReturn(run func(context.Context, int, int) error) *MockDatabase_DeleteEmergencyAccess_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteInvite provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) DeleteInvite(_a0 context.Context, _a1 int) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteInvite")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_DeleteInvite_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteInvite'
+type MockDatabase_DeleteInvite_Call struct {
+	*mock.Call
+}
+
+// DeleteInvite is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) DeleteInvite(_a0 interface{}, _a1 interface{}) *MockDatabase_DeleteInvite_Call {
+	return &MockDatabase_DeleteInvite_Call{Call: _e.mock.On("DeleteInvite", _a0, _a1)}
+}
+
+func (_c *MockDatabase_DeleteInvite_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_DeleteInvite_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_DeleteInvite_Call) Return(_a0 error) *MockDatabase_DeleteInvite_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_DeleteInvite_Call) RunAndReturn(run func(context.Context, int) error) *MockDatabase_DeleteInvite_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteItem provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) DeleteItem(_a0 context.Context, _a1 int, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteItem")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_DeleteItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteItem'
+type MockDatabase_DeleteItem_Call struct {
+	*mock.Call
+}
+
+// DeleteItem is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+func (_e *MockDatabase_Expecter) DeleteItem(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_DeleteItem_Call {
+	return &MockDatabase_DeleteItem_Call{Call: _e.mock.On("DeleteItem", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_DeleteItem_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_DeleteItem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_DeleteItem_Call) Return(_a0 error) *MockDatabase_DeleteItem_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_DeleteItem_Call) RunAndReturn(run func(context.Context, int, string) error) *MockDatabase_DeleteItem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteOrg provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) DeleteOrg(_a0 context.Context, _a1 int) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOrg")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_DeleteOrg_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteOrg'
+type MockDatabase_DeleteOrg_Call struct {
+	*mock.Call
+}
+
+// DeleteOrg is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) DeleteOrg(_a0 interface{}, _a1 interface{}) *MockDatabase_DeleteOrg_Call {
+	return &MockDatabase_DeleteOrg_Call{Call: _e.mock.On("DeleteOrg", _a0, _a1)}
+}
+
+func (_c *MockDatabase_DeleteOrg_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_DeleteOrg_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_DeleteOrg_Call) Return(_a0 error) *MockDatabase_DeleteOrg_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_DeleteOrg_Call) RunAndReturn(run func(context.Context, int) error) *MockDatabase_DeleteOrg_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteOrgItem provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) DeleteOrgItem(_a0 context.Context, _a1 int, _a2 string, _a3 []string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOrgItem")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, []string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_DeleteOrgItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteOrgItem'
+type MockDatabase_DeleteOrgItem_Call struct {
+	*mock.Call
+}
+
+// DeleteOrgItem is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 []string
+func (_e *MockDatabase_Expecter) DeleteOrgItem(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_DeleteOrgItem_Call {
+	return &MockDatabase_DeleteOrgItem_Call{Call: _e.mock.On("DeleteOrgItem", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_DeleteOrgItem_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 []string)) *MockDatabase_DeleteOrgItem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].([]string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_DeleteOrgItem_Call) Return(_a0 error) *MockDatabase_DeleteOrgItem_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_DeleteOrgItem_Call) RunAndReturn(run func(context.Context, int, string, []string) error) *MockDatabase_DeleteOrgItem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteSend provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) DeleteSend(_a0 context.Context, _a1 int, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSend")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_DeleteSend_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteSend'
+type MockDatabase_DeleteSend_Call struct {
+	*mock.Call
+}
+
+// DeleteSend is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+func (_e *MockDatabase_Expecter) DeleteSend(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_DeleteSend_Call {
+	return &MockDatabase_DeleteSend_Call{Call: _e.mock.On("DeleteSend", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_DeleteSend_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_DeleteSend_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_DeleteSend_Call) Return(_a0 error) *MockDatabase_DeleteSend_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_DeleteSend_Call) RunAndReturn(run func(context.Context, int, string) error) *MockDatabase_DeleteSend_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteSession provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) DeleteSession(_a0 context.Context, _a1 int, _a2 int) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_DeleteSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteSession'
+type MockDatabase_DeleteSession_Call struct {
+	*mock.Call
+}
+
+// DeleteSession is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+func (_e *MockDatabase_Expecter) DeleteSession(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_DeleteSession_Call {
+	return &MockDatabase_DeleteSession_Call{Call: _e.mock.On("DeleteSession", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_DeleteSession_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int)) *MockDatabase_DeleteSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_DeleteSession_Call) Return(_a0 error) *MockDatabase_DeleteSession_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_DeleteSession_Call) RunAndReturn(run func(context.Context, int, int) error) *MockDatabase_DeleteSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteUser provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) DeleteUser(_a0 context.Context, _a1 int) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
+type MockDatabase_DeleteUser_Call struct {
+	*mock.Call
+}
+
+// DeleteUser is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) DeleteUser(_a0 interface{}, _a1 interface{}) *MockDatabase_DeleteUser_Call {
+	return &MockDatabase_DeleteUser_Call{Call: _e.mock.On("DeleteUser", _a0, _a1)}
+}
+
+func (_c *MockDatabase_DeleteUser_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_DeleteUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_DeleteUser_Call) Return(_a0 error) *MockDatabase_DeleteUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_DeleteUser_Call) RunAndReturn(run func(context.Context, int) error) *MockDatabase_DeleteUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteUserSessions provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) DeleteUserSessions(_a0 context.Context, _a1 int) (int64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUserSessions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int64, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int64); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_DeleteUserSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUserSessions'
+type MockDatabase_DeleteUserSessions_Call struct {
+	*mock.Call
+}
+
+// DeleteUserSessions is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) DeleteUserSessions(_a0 interface{}, _a1 interface{}) *MockDatabase_DeleteUserSessions_Call {
+	return &MockDatabase_DeleteUserSessions_Call{Call: _e.mock.On("DeleteUserSessions", _a0, _a1)}
+}
+
+func (_c *MockDatabase_DeleteUserSessions_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_DeleteUserSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_DeleteUserSessions_Call) Return(_a0 int64, _a1 error) *MockDatabase_DeleteUserSessions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_DeleteUserSessions_Call) RunAndReturn(run func(context.Context, int) (int64, error)) *MockDatabase_DeleteUserSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FinishVaultKeyMigration provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) FinishVaultKeyMigration(_a0 context.Context, _a1 int) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinishVaultKeyMigration")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_FinishVaultKeyMigration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FinishVaultKeyMigration'
+type MockDatabase_FinishVaultKeyMigration_Call struct {
+	*mock.Call
+}
+
+// FinishVaultKeyMigration is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) FinishVaultKeyMigration(_a0 interface{}, _a1 interface{}) *MockDatabase_FinishVaultKeyMigration_Call {
+	return &MockDatabase_FinishVaultKeyMigration_Call{Call: _e.mock.On("FinishVaultKeyMigration", _a0, _a1)}
+}
+
+func (_c *MockDatabase_FinishVaultKeyMigration_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_FinishVaultKeyMigration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_FinishVaultKeyMigration_Call) Return(_a0 error) *MockDatabase_FinishVaultKeyMigration_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_FinishVaultKeyMigration_Call) RunAndReturn(run func(context.Context, int) error) *MockDatabase_FinishVaultKeyMigration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAttachment provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) GetAttachment(_a0 context.Context, _a1 int, _a2 string, _a3 int) (*types.Attachment, []byte, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttachment")
+	}
+
+	var r0 *types.Attachment
+	var r1 []byte
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int) (*types.Attachment, []byte, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int) *types.Attachment); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Attachment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, int) []byte); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, string, int) error); ok {
+		r2 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDatabase_GetAttachment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAttachment'
+type MockDatabase_GetAttachment_Call struct {
+	*mock.Call
+}
+
+// GetAttachment is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 int
+func (_e *MockDatabase_Expecter) GetAttachment(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_GetAttachment_Call {
+	return &MockDatabase_GetAttachment_Call{Call: _e.mock.On("GetAttachment", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_GetAttachment_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 int)) *MockDatabase_GetAttachment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetAttachment_Call) Return(_a0 *types.Attachment, _a1 []byte, _a2 error) *MockDatabase_GetAttachment_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockDatabase_GetAttachment_Call) RunAndReturn(run func(context.Context, int, string, int) (*types.Attachment, []byte, error)) *MockDatabase_GetAttachment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAttachments provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) GetAttachments(_a0 context.Context, _a1 int, _a2 string) ([]types.Attachment, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttachments")
+	}
+
+	var r0 []types.Attachment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) ([]types.Attachment, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) []types.Attachment); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.Attachment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetAttachments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAttachments'
+type MockDatabase_GetAttachments_Call struct {
+	*mock.Call
+}
+
+// GetAttachments is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+func (_e *MockDatabase_Expecter) GetAttachments(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_GetAttachments_Call {
+	return &MockDatabase_GetAttachments_Call{Call: _e.mock.On("GetAttachments", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_GetAttachments_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_GetAttachments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetAttachments_Call) Return(_a0 []types.Attachment, _a1 error) *MockDatabase_GetAttachments_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetAttachments_Call) RunAndReturn(run func(context.Context, int, string) ([]types.Attachment, error)) *MockDatabase_GetAttachments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBinaryData provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) GetBinaryData(_a0 context.Context, _a1 int, _a2 string) ([]byte, *types.BinaryMeta, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBinaryData")
+	}
+
+	var r0 []byte
+	var r1 *types.BinaryMeta
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) ([]byte, *types.BinaryMeta, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) []byte); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) *types.BinaryMeta); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*types.BinaryMeta)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, string) error); ok {
+		r2 = rf(_a0, _a1, _a2)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDatabase_GetBinaryData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBinaryData'
+type MockDatabase_GetBinaryData_Call struct {
+	*mock.Call
+}
+
+// GetBinaryData is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+func (_e *MockDatabase_Expecter) GetBinaryData(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_GetBinaryData_Call {
+	return &MockDatabase_GetBinaryData_Call{Call: _e.mock.On("GetBinaryData", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_GetBinaryData_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_GetBinaryData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetBinaryData_Call) Return(_a0 []byte, _a1 *types.BinaryMeta, _a2 error) *MockDatabase_GetBinaryData_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockDatabase_GetBinaryData_Call) RunAndReturn(run func(context.Context, int, string) ([]byte, *types.BinaryMeta, error)) *MockDatabase_GetBinaryData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBinaryMeta provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetBinaryMeta(_a0 context.Context, _a1 int) (*types.BinaryMeta, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBinaryMeta")
+	}
+
+	var r0 *types.BinaryMeta
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.BinaryMeta, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *types.BinaryMeta); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.BinaryMeta)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetBinaryMeta_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBinaryMeta'
+type MockDatabase_GetBinaryMeta_Call struct {
+	*mock.Call
+}
+
+// GetBinaryMeta is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) GetBinaryMeta(_a0 interface{}, _a1 interface{}) *MockDatabase_GetBinaryMeta_Call {
+	return &MockDatabase_GetBinaryMeta_Call{Call: _e.mock.On("GetBinaryMeta", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetBinaryMeta_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetBinaryMeta_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetBinaryMeta_Call) Return(_a0 *types.BinaryMeta, _a1 error) *MockDatabase_GetBinaryMeta_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetBinaryMeta_Call) RunAndReturn(run func(context.Context, int) (*types.BinaryMeta, error)) *MockDatabase_GetBinaryMeta_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCreditCard provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetCreditCard(_a0 context.Context, _a1 int) (*types.CreditCardData, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCreditCard")
+	}
+
+	var r0 *types.CreditCardData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.CreditCardData, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *types.CreditCardData); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.CreditCardData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetCreditCard_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCreditCard'
+type MockDatabase_GetCreditCard_Call struct {
+	*mock.Call
+}
+
+// GetCreditCard is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) GetCreditCard(_a0 interface{}, _a1 interface{}) *MockDatabase_GetCreditCard_Call {
+	return &MockDatabase_GetCreditCard_Call{Call: _e.mock.On("GetCreditCard", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetCreditCard_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetCreditCard_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetCreditCard_Call) Return(_a0 *types.CreditCardData, _a1 error) *MockDatabase_GetCreditCard_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetCreditCard_Call) RunAndReturn(run func(context.Context, int) (*types.CreditCardData, error)) *MockDatabase_GetCreditCard_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetEmergencyGrant provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) GetEmergencyGrant(_a0 context.Context, _a1 int, _a2 int) (int, string, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEmergencyGrant")
+	}
+
+	var r0 int
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (int, string, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) int); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) string); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
+		r2 = rf(_a0, _a1, _a2)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDatabase_GetEmergencyGrant_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetEmergencyGrant'
+type MockDatabase_GetEmergencyGrant_Call struct {
+	*mock.Call
+}
+
+// GetEmergencyGrant is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+func (_e *MockDatabase_Expecter) GetEmergencyGrant(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_GetEmergencyGrant_Call {
+	return &MockDatabase_GetEmergencyGrant_Call{Call: _e.mock.On("GetEmergencyGrant", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_GetEmergencyGrant_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int)) *MockDatabase_GetEmergencyGrant_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetEmergencyGrant_Call) Return(_a0 int, _a1 string, _a2 error) *MockDatabase_GetEmergencyGrant_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockDatabase_GetEmergencyGrant_Call) RunAndReturn(run func(context.Context, int, int) (int, string, error)) *MockDatabase_GetEmergencyGrant_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetItem provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) GetItem(_a0 context.Context, _a1 int, _a2 string) (*types.Item, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetItem")
+	}
+
+	var r0 *types.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) (*types.Item, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) *types.Item); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetItem'
+type MockDatabase_GetItem_Call struct {
+	*mock.Call
+}
+
+// GetItem is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+func (_e *MockDatabase_Expecter) GetItem(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_GetItem_Call {
+	return &MockDatabase_GetItem_Call{Call: _e.mock.On("GetItem", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_GetItem_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_GetItem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetItem_Call) Return(_a0 *types.Item, _a1 error) *MockDatabase_GetItem_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetItem_Call) RunAndReturn(run func(context.Context, int, string) (*types.Item, error)) *MockDatabase_GetItem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetItemShares provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) GetItemShares(_a0 context.Context, _a1 int, _a2 string) ([]types.Share, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetItemShares")
+	}
+
+	var r0 []types.Share
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) ([]types.Share, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) []types.Share); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.Share)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetItemShares_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetItemShares'
+type MockDatabase_GetItemShares_Call struct {
+	*mock.Call
+}
+
+// GetItemShares is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+func (_e *MockDatabase_Expecter) GetItemShares(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_GetItemShares_Call {
+	return &MockDatabase_GetItemShares_Call{Call: _e.mock.On("GetItemShares", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_GetItemShares_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_GetItemShares_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetItemShares_Call) Return(_a0 []types.Share, _a1 error) *MockDatabase_GetItemShares_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetItemShares_Call) RunAndReturn(run func(context.Context, int, string) ([]types.Share, error)) *MockDatabase_GetItemShares_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetItems provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) GetItems(_a0 context.Context, _a1 int, _a2 int, _a3 int) ([]types.Item, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetItems")
+	}
+
+	var r0 []types.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]types.Item, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []types.Item); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetItems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetItems'
+type MockDatabase_GetItems_Call struct {
+	*mock.Call
+}
+
+// GetItems is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+//   - _a3 int
+func (_e *MockDatabase_Expecter) GetItems(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_GetItems_Call {
+	return &MockDatabase_GetItems_Call{Call: _e.mock.On("GetItems", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_GetItems_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int, _a3 int)) *MockDatabase_GetItems_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetItems_Call) Return(_a0 []types.Item, _a1 error) *MockDatabase_GetItems_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetItems_Call) RunAndReturn(run func(context.Context, int, int, int) ([]types.Item, error)) *MockDatabase_GetItems_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetLegacyVault provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetLegacyVault(_a0 context.Context, _a1 int) (*types.LegacyVault, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLegacyVault")
+	}
+
+	var r0 *types.LegacyVault
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.LegacyVault, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *types.LegacyVault); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.LegacyVault)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetLegacyVault_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLegacyVault'
+type MockDatabase_GetLegacyVault_Call struct {
+	*mock.Call
+}
+
+// GetLegacyVault is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) GetLegacyVault(_a0 interface{}, _a1 interface{}) *MockDatabase_GetLegacyVault_Call {
+	return &MockDatabase_GetLegacyVault_Call{Call: _e.mock.On("GetLegacyVault", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetLegacyVault_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetLegacyVault_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetLegacyVault_Call) Return(_a0 *types.LegacyVault, _a1 error) *MockDatabase_GetLegacyVault_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetLegacyVault_Call) RunAndReturn(run func(context.Context, int) (*types.LegacyVault, error)) *MockDatabase_GetLegacyVault_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetLogoPass provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetLogoPass(_a0 context.Context, _a1 int) (*types.LoginPassword, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLogoPass")
+	}
+
+	var r0 *types.LoginPassword
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.LoginPassword, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *types.LoginPassword); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.LoginPassword)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetLogoPass_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLogoPass'
+type MockDatabase_GetLogoPass_Call struct {
+	*mock.Call
+}
+
+// GetLogoPass is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) GetLogoPass(_a0 interface{}, _a1 interface{}) *MockDatabase_GetLogoPass_Call {
+	return &MockDatabase_GetLogoPass_Call{Call: _e.mock.On("GetLogoPass", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetLogoPass_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetLogoPass_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetLogoPass_Call) Return(_a0 *types.LoginPassword, _a1 error) *MockDatabase_GetLogoPass_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetLogoPass_Call) RunAndReturn(run func(context.Context, int) (*types.LoginPassword, error)) *MockDatabase_GetLogoPass_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMembership provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) GetMembership(_a0 context.Context, _a1 int, _a2 string) (*types.Membership, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMembership")
+	}
+
+	var r0 *types.Membership
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) (*types.Membership, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) *types.Membership); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Membership)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetMembership_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMembership'
+type MockDatabase_GetMembership_Call struct {
+	*mock.Call
+}
+
+// GetMembership is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+func (_e *MockDatabase_Expecter) GetMembership(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_GetMembership_Call {
+	return &MockDatabase_GetMembership_Call{Call: _e.mock.On("GetMembership", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_GetMembership_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_GetMembership_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetMembership_Call) Return(_a0 *types.Membership, _a1 error) *MockDatabase_GetMembership_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetMembership_Call) RunAndReturn(run func(context.Context, int, string) (*types.Membership, error)) *MockDatabase_GetMembership_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrgItem provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) GetOrgItem(_a0 context.Context, _a1 int, _a2 string, _a3 []string) (*types.OrgItem, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrgItem")
+	}
+
+	var r0 *types.OrgItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, []string) (*types.OrgItem, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, []string) *types.OrgItem); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.OrgItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, []string) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetOrgItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrgItem'
+type MockDatabase_GetOrgItem_Call struct {
+	*mock.Call
+}
+
+// GetOrgItem is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 []string
+func (_e *MockDatabase_Expecter) GetOrgItem(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_GetOrgItem_Call {
+	return &MockDatabase_GetOrgItem_Call{Call: _e.mock.On("GetOrgItem", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_GetOrgItem_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 []string)) *MockDatabase_GetOrgItem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].([]string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetOrgItem_Call) Return(_a0 *types.OrgItem, _a1 error) *MockDatabase_GetOrgItem_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetOrgItem_Call) RunAndReturn(run func(context.Context, int, string, []string) (*types.OrgItem, error)) *MockDatabase_GetOrgItem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPublicKey provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetPublicKey(_a0 context.Context, _a1 string) (string, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPublicKey")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetPublicKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPublicKey'
+type MockDatabase_GetPublicKey_Call struct {
+	*mock.Call
+}
+
+// GetPublicKey is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 string
+func (_e *MockDatabase_Expecter) GetPublicKey(_a0 interface{}, _a1 interface{}) *MockDatabase_GetPublicKey_Call {
+	return &MockDatabase_GetPublicKey_Call{Call: _e.mock.On("GetPublicKey", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetPublicKey_Call) Run(run func(_a0 context.Context, _a1 string)) *MockDatabase_GetPublicKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetPublicKey_Call) Return(_a0 string, _a1 error) *MockDatabase_GetPublicKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetPublicKey_Call) RunAndReturn(run func(context.Context, string) (string, error)) *MockDatabase_GetPublicKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSSHKey provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetSSHKey(_a0 context.Context, _a1 int) (*types.SSHKeyData, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSSHKey")
+	}
+
+	var r0 *types.SSHKeyData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.SSHKeyData, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *types.SSHKeyData); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.SSHKeyData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetSSHKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSSHKey'
+type MockDatabase_GetSSHKey_Call struct {
+	*mock.Call
+}
+
+// GetSSHKey is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) GetSSHKey(_a0 interface{}, _a1 interface{}) *MockDatabase_GetSSHKey_Call {
+	return &MockDatabase_GetSSHKey_Call{Call: _e.mock.On("GetSSHKey", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetSSHKey_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetSSHKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetSSHKey_Call) Return(_a0 *types.SSHKeyData, _a1 error) *MockDatabase_GetSSHKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetSSHKey_Call) RunAndReturn(run func(context.Context, int) (*types.SSHKeyData, error)) *MockDatabase_GetSSHKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSend provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetSend(_a0 context.Context, _a1 string) (*types.Send, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSend")
+	}
+
+	var r0 *types.Send
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*types.Send, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *types.Send); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Send)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetSend_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSend'
+type MockDatabase_GetSend_Call struct {
+	*mock.Call
+}
+
+// GetSend is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 string
+func (_e *MockDatabase_Expecter) GetSend(_a0 interface{}, _a1 interface{}) *MockDatabase_GetSend_Call {
+	return &MockDatabase_GetSend_Call{Call: _e.mock.On("GetSend", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetSend_Call) Run(run func(_a0 context.Context, _a1 string)) *MockDatabase_GetSend_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetSend_Call) Return(_a0 *types.Send, _a1 error) *MockDatabase_GetSend_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetSend_Call) RunAndReturn(run func(context.Context, string) (*types.Send, error)) *MockDatabase_GetSend_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSharedWithUser provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetSharedWithUser(_a0 context.Context, _a1 int) ([]types.Share, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSharedWithUser")
+	}
+
+	var r0 []types.Share
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]types.Share, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []types.Share); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.Share)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetSharedWithUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSharedWithUser'
+type MockDatabase_GetSharedWithUser_Call struct {
+	*mock.Call
+}
+
+// GetSharedWithUser is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) GetSharedWithUser(_a0 interface{}, _a1 interface{}) *MockDatabase_GetSharedWithUser_Call {
+	return &MockDatabase_GetSharedWithUser_Call{Call: _e.mock.On("GetSharedWithUser", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetSharedWithUser_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetSharedWithUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetSharedWithUser_Call) Return(_a0 []types.Share, _a1 error) *MockDatabase_GetSharedWithUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetSharedWithUser_Call) RunAndReturn(run func(context.Context, int) ([]types.Share, error)) *MockDatabase_GetSharedWithUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTOTP provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetTOTP(_a0 context.Context, _a1 int) (*types.TOTPData, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTOTP")
+	}
+
+	var r0 *types.TOTPData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.TOTPData, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *types.TOTPData); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.TOTPData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTOTP'
+type MockDatabase_GetTOTP_Call struct {
+	*mock.Call
+}
+
+// GetTOTP is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) GetTOTP(_a0 interface{}, _a1 interface{}) *MockDatabase_GetTOTP_Call {
+	return &MockDatabase_GetTOTP_Call{Call: _e.mock.On("GetTOTP", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetTOTP_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetTOTP_Call) Return(_a0 *types.TOTPData, _a1 error) *MockDatabase_GetTOTP_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetTOTP_Call) RunAndReturn(run func(context.Context, int) (*types.TOTPData, error)) *MockDatabase_GetTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetText provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetText(_a0 context.Context, _a1 int) (*types.TextData, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetText")
+	}
+
+	var r0 *types.TextData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.TextData, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *types.TextData); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.TextData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetText_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetText'
+type MockDatabase_GetText_Call struct {
+	*mock.Call
+}
+
+// GetText is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) GetText(_a0 interface{}, _a1 interface{}) *MockDatabase_GetText_Call {
+	return &MockDatabase_GetText_Call{Call: _e.mock.On("GetText", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetText_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetText_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetText_Call) Return(_a0 *types.TextData, _a1 error) *MockDatabase_GetText_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetText_Call) RunAndReturn(run func(context.Context, int) (*types.TextData, error)) *MockDatabase_GetText_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserDeletion provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetUserDeletion(_a0 context.Context, _a1 int) (*time.Time, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserDeletion")
+	}
+
+	var r0 *time.Time
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*time.Time, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *time.Time); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*time.Time)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetUserDeletion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserDeletion'
+type MockDatabase_GetUserDeletion_Call struct {
+	*mock.Call
+}
+
+// GetUserDeletion is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) GetUserDeletion(_a0 interface{}, _a1 interface{}) *MockDatabase_GetUserDeletion_Call {
+	return &MockDatabase_GetUserDeletion_Call{Call: _e.mock.On("GetUserDeletion", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetUserDeletion_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetUserDeletion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetUserDeletion_Call) Return(_a0 *time.Time, _a1 error) *MockDatabase_GetUserDeletion_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetUserDeletion_Call) RunAndReturn(run func(context.Context, int) (*time.Time, error)) *MockDatabase_GetUserDeletion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserHashedPassword provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetUserHashedPassword(_a0 context.Context, _a1 string) (string, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserHashedPassword")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetUserHashedPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserHashedPassword'
+type MockDatabase_GetUserHashedPassword_Call struct {
+	*mock.Call
+}
+
+// GetUserHashedPassword is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 string
+func (_e *MockDatabase_Expecter) GetUserHashedPassword(_a0 interface{}, _a1 interface{}) *MockDatabase_GetUserHashedPassword_Call {
+	return &MockDatabase_GetUserHashedPassword_Call{Call: _e.mock.On("GetUserHashedPassword", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetUserHashedPassword_Call) Run(run func(_a0 context.Context, _a1 string)) *MockDatabase_GetUserHashedPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetUserHashedPassword_Call) Return(_a0 string, _a1 error) *MockDatabase_GetUserHashedPassword_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetUserHashedPassword_Call) RunAndReturn(run func(context.Context, string) (string, error)) *MockDatabase_GetUserHashedPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserID provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetUserID(_a0 context.Context, _a1 string) (int, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserID")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetUserID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserID'
+type MockDatabase_GetUserID_Call struct {
+	*mock.Call
+}
+
+// GetUserID is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 string
+func (_e *MockDatabase_Expecter) GetUserID(_a0 interface{}, _a1 interface{}) *MockDatabase_GetUserID_Call {
+	return &MockDatabase_GetUserID_Call{Call: _e.mock.On("GetUserID", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetUserID_Call) Run(run func(_a0 context.Context, _a1 string)) *MockDatabase_GetUserID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetUserID_Call) Return(_a0 int, _a1 error) *MockDatabase_GetUserID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetUserID_Call) RunAndReturn(run func(context.Context, string) (int, error)) *MockDatabase_GetUserID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserKeys provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetUserKeys(_a0 context.Context, _a1 int) (*types.UserKeys, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserKeys")
+	}
+
+	var r0 *types.UserKeys
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.UserKeys, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *types.UserKeys); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.UserKeys)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetUserKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserKeys'
+type MockDatabase_GetUserKeys_Call struct {
+	*mock.Call
+}
+
+// GetUserKeys is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) GetUserKeys(_a0 interface{}, _a1 interface{}) *MockDatabase_GetUserKeys_Call {
+	return &MockDatabase_GetUserKeys_Call{Call: _e.mock.On("GetUserKeys", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetUserKeys_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetUserKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetUserKeys_Call) Return(_a0 *types.UserKeys, _a1 error) *MockDatabase_GetUserKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetUserKeys_Call) RunAndReturn(run func(context.Context, int) (*types.UserKeys, error)) *MockDatabase_GetUserKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetVaultKey provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) GetVaultKey(_a0 context.Context, _a1 int) (*types.VaultKey, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVaultKey")
+	}
+
+	var r0 *types.VaultKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*types.VaultKey, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *types.VaultKey); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.VaultKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_GetVaultKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetVaultKey'
+type MockDatabase_GetVaultKey_Call struct {
+	*mock.Call
+}
+
+// GetVaultKey is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) GetVaultKey(_a0 interface{}, _a1 interface{}) *MockDatabase_GetVaultKey_Call {
+	return &MockDatabase_GetVaultKey_Call{Call: _e.mock.On("GetVaultKey", _a0, _a1)}
+}
+
+func (_c *MockDatabase_GetVaultKey_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_GetVaultKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_GetVaultKey_Call) Return(_a0 *types.VaultKey, _a1 error) *MockDatabase_GetVaultKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_GetVaultKey_Call) RunAndReturn(run func(context.Context, int) (*types.VaultKey, error)) *MockDatabase_GetVaultKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertAttachment provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *MockDatabase) InsertAttachment(_a0 context.Context, _a1 int, _a2 string, _a3 types.Attachment, _a4 []byte) (int, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertAttachment")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, types.Attachment, []byte) (int, error)); ok {
+		return rf(_a0, _a1, _a2, _a3, _a4)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, types.Attachment, []byte) int); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, types.Attachment, []byte) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_InsertAttachment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertAttachment'
+type MockDatabase_InsertAttachment_Call struct {
+	*mock.Call
+}
+
+// InsertAttachment is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 types.Attachment
+//   - _a4 []byte
+func (_e *MockDatabase_Expecter) InsertAttachment(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}, _a4 interface{}) *MockDatabase_InsertAttachment_Call {
+	return &MockDatabase_InsertAttachment_Call{Call: _e.mock.On("InsertAttachment", _a0, _a1, _a2, _a3, _a4)}
+}
+
+func (_c *MockDatabase_InsertAttachment_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 types.Attachment, _a4 []byte)) *MockDatabase_InsertAttachment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(types.Attachment), args[4].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_InsertAttachment_Call) Return(_a0 int, _a1 error) *MockDatabase_InsertAttachment_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_InsertAttachment_Call) RunAndReturn(run func(context.Context, int, string, types.Attachment, []byte) (int, error)) *MockDatabase_InsertAttachment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertBinaryData provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) InsertBinaryData(_a0 context.Context, _a1 int, _a2 types.BinaryItem) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertBinaryData")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.BinaryItem) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_InsertBinaryData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertBinaryData'
+type MockDatabase_InsertBinaryData_Call struct {
+	*mock.Call
+}
+
+// InsertBinaryData is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.BinaryItem
+func (_e *MockDatabase_Expecter) InsertBinaryData(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_InsertBinaryData_Call {
+	return &MockDatabase_InsertBinaryData_Call{Call: _e.mock.On("InsertBinaryData", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_InsertBinaryData_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.BinaryItem)) *MockDatabase_InsertBinaryData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.BinaryItem))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_InsertBinaryData_Call) Return(_a0 error) *MockDatabase_InsertBinaryData_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_InsertBinaryData_Call) RunAndReturn(run func(context.Context, int, types.BinaryItem) error) *MockDatabase_InsertBinaryData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertCreditCard provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) InsertCreditCard(_a0 context.Context, _a1 int, _a2 types.CreditCardItem) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertCreditCard")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.CreditCardItem) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_InsertCreditCard_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertCreditCard'
+type MockDatabase_InsertCreditCard_Call struct {
+	*mock.Call
+}
+
+// InsertCreditCard is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.CreditCardItem
+func (_e *MockDatabase_Expecter) InsertCreditCard(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_InsertCreditCard_Call {
+	return &MockDatabase_InsertCreditCard_Call{Call: _e.mock.On("InsertCreditCard", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_InsertCreditCard_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.CreditCardItem)) *MockDatabase_InsertCreditCard_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.CreditCardItem))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_InsertCreditCard_Call) Return(_a0 error) *MockDatabase_InsertCreditCard_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_InsertCreditCard_Call) RunAndReturn(run func(context.Context, int, types.CreditCardItem) error) *MockDatabase_InsertCreditCard_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertLogoPass provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) InsertLogoPass(_a0 context.Context, _a1 int, _a2 types.LoginPasswordItem) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertLogoPass")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.LoginPasswordItem) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_InsertLogoPass_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertLogoPass'
+type MockDatabase_InsertLogoPass_Call struct {
+	*mock.Call
+}
+
+// InsertLogoPass is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.LoginPasswordItem
+func (_e *MockDatabase_Expecter) InsertLogoPass(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_InsertLogoPass_Call {
+	return &MockDatabase_InsertLogoPass_Call{Call: _e.mock.On("InsertLogoPass", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_InsertLogoPass_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.LoginPasswordItem)) *MockDatabase_InsertLogoPass_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.LoginPasswordItem))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_InsertLogoPass_Call) Return(_a0 error) *MockDatabase_InsertLogoPass_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_InsertLogoPass_Call) RunAndReturn(run func(context.Context, int, types.LoginPasswordItem) error) *MockDatabase_InsertLogoPass_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertSSHKey provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) InsertSSHKey(_a0 context.Context, _a1 int, _a2 types.SSHKeyItem) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertSSHKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.SSHKeyItem) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_InsertSSHKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertSSHKey'
+type MockDatabase_InsertSSHKey_Call struct {
+	*mock.Call
+}
+
+// InsertSSHKey is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.SSHKeyItem
+func (_e *MockDatabase_Expecter) InsertSSHKey(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_InsertSSHKey_Call {
+	return &MockDatabase_InsertSSHKey_Call{Call: _e.mock.On("InsertSSHKey", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_InsertSSHKey_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.SSHKeyItem)) *MockDatabase_InsertSSHKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.SSHKeyItem))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_InsertSSHKey_Call) Return(_a0 error) *MockDatabase_InsertSSHKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_InsertSSHKey_Call) RunAndReturn(run func(context.Context, int, types.SSHKeyItem) error) *MockDatabase_InsertSSHKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertTOTP provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) InsertTOTP(_a0 context.Context, _a1 int, _a2 types.TOTPItem) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertTOTP")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.TOTPItem) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_InsertTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertTOTP'
+type MockDatabase_InsertTOTP_Call struct {
+	*mock.Call
+}
+
+// InsertTOTP is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.TOTPItem
+func (_e *MockDatabase_Expecter) InsertTOTP(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_InsertTOTP_Call {
+	return &MockDatabase_InsertTOTP_Call{Call: _e.mock.On("InsertTOTP", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_InsertTOTP_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.TOTPItem)) *MockDatabase_InsertTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.TOTPItem))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_InsertTOTP_Call) Return(_a0 error) *MockDatabase_InsertTOTP_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_InsertTOTP_Call) RunAndReturn(run func(context.Context, int, types.TOTPItem) error) *MockDatabase_InsertTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertText provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) InsertText(_a0 context.Context, _a1 int, _a2 types.TextItem) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertText")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.TextItem) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_InsertText_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertText'
+type MockDatabase_InsertText_Call struct {
+	*mock.Call
+}
+
+// InsertText is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.TextItem
+func (_e *MockDatabase_Expecter) InsertText(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_InsertText_Call {
+	return &MockDatabase_InsertText_Call{Call: _e.mock.On("InsertText", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_InsertText_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.TextItem)) *MockDatabase_InsertText_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.TextItem))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_InsertText_Call) Return(_a0 error) *MockDatabase_InsertText_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_InsertText_Call) RunAndReturn(run func(context.Context, int, types.TextItem) error) *MockDatabase_InsertText_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LeaveOrg provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) LeaveOrg(_a0 context.Context, _a1 int, _a2 int) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LeaveOrg")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_LeaveOrg_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LeaveOrg'
+type MockDatabase_LeaveOrg_Call struct {
+	*mock.Call
+}
+
+// LeaveOrg is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+func (_e *MockDatabase_Expecter) LeaveOrg(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_LeaveOrg_Call {
+	return &MockDatabase_LeaveOrg_Call{Call: _e.mock.On("LeaveOrg", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_LeaveOrg_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int)) *MockDatabase_LeaveOrg_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_LeaveOrg_Call) Return(_a0 error) *MockDatabase_LeaveOrg_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_LeaveOrg_Call) RunAndReturn(run func(context.Context, int, int) error) *MockDatabase_LeaveOrg_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAuditEvents provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) ListAuditEvents(_a0 context.Context, _a1 int, _a2 int, _a3 int) ([]types.AuditEvent, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAuditEvents")
+	}
+
+	var r0 []types.AuditEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]types.AuditEvent, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []types.AuditEvent); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.AuditEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ListAuditEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAuditEvents'
+type MockDatabase_ListAuditEvents_Call struct {
+	*mock.Call
+}
+
+// ListAuditEvents is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+//   - _a3 int
+func (_e *MockDatabase_Expecter) ListAuditEvents(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_ListAuditEvents_Call {
+	return &MockDatabase_ListAuditEvents_Call{Call: _e.mock.On("ListAuditEvents", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_ListAuditEvents_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int, _a3 int)) *MockDatabase_ListAuditEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ListAuditEvents_Call) Return(_a0 []types.AuditEvent, _a1 error) *MockDatabase_ListAuditEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ListAuditEvents_Call) RunAndReturn(run func(context.Context, int, int, int) ([]types.AuditEvent, error)) *MockDatabase_ListAuditEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListDevices provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) ListDevices(_a0 context.Context, _a1 int) ([]types.Device, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDevices")
+	}
+
+	var r0 []types.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]types.Device, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []types.Device); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ListDevices_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListDevices'
+type MockDatabase_ListDevices_Call struct {
+	*mock.Call
+}
+
+// ListDevices is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) ListDevices(_a0 interface{}, _a1 interface{}) *MockDatabase_ListDevices_Call {
+	return &MockDatabase_ListDevices_Call{Call: _e.mock.On("ListDevices", _a0, _a1)}
+}
+
+func (_c *MockDatabase_ListDevices_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_ListDevices_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ListDevices_Call) Return(_a0 []types.Device, _a1 error) *MockDatabase_ListDevices_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ListDevices_Call) RunAndReturn(run func(context.Context, int) ([]types.Device, error)) *MockDatabase_ListDevices_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListEmergencyContacts provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) ListEmergencyContacts(_a0 context.Context, _a1 int) ([]types.EmergencyAccess, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListEmergencyContacts")
+	}
+
+	var r0 []types.EmergencyAccess
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]types.EmergencyAccess, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []types.EmergencyAccess); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.EmergencyAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ListEmergencyContacts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListEmergencyContacts'
+type MockDatabase_ListEmergencyContacts_Call struct {
+	*mock.Call
+}
+
+// ListEmergencyContacts is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) ListEmergencyContacts(_a0 interface{}, _a1 interface{}) *MockDatabase_ListEmergencyContacts_Call {
+	return &MockDatabase_ListEmergencyContacts_Call{Call: _e.mock.On("ListEmergencyContacts", _a0, _a1)}
+}
+
+func (_c *MockDatabase_ListEmergencyContacts_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_ListEmergencyContacts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ListEmergencyContacts_Call) Return(_a0 []types.EmergencyAccess, _a1 error) *MockDatabase_ListEmergencyContacts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ListEmergencyContacts_Call) RunAndReturn(run func(context.Context, int) ([]types.EmergencyAccess, error)) *MockDatabase_ListEmergencyContacts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListEmergencyGrants provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) ListEmergencyGrants(_a0 context.Context, _a1 int) ([]types.EmergencyAccess, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListEmergencyGrants")
+	}
+
+	var r0 []types.EmergencyAccess
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]types.EmergencyAccess, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []types.EmergencyAccess); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.EmergencyAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ListEmergencyGrants_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListEmergencyGrants'
+type MockDatabase_ListEmergencyGrants_Call struct {
+	*mock.Call
+}
+
+// ListEmergencyGrants is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) ListEmergencyGrants(_a0 interface{}, _a1 interface{}) *MockDatabase_ListEmergencyGrants_Call {
+	return &MockDatabase_ListEmergencyGrants_Call{Call: _e.mock.On("ListEmergencyGrants", _a0, _a1)}
+}
+
+func (_c *MockDatabase_ListEmergencyGrants_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_ListEmergencyGrants_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ListEmergencyGrants_Call) Return(_a0 []types.EmergencyAccess, _a1 error) *MockDatabase_ListEmergencyGrants_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ListEmergencyGrants_Call) RunAndReturn(run func(context.Context, int) ([]types.EmergencyAccess, error)) *MockDatabase_ListEmergencyGrants_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListInvites provides a mock function with given fields: _a0
+func (_m *MockDatabase) ListInvites(_a0 context.Context) ([]types.Invite, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListInvites")
+	}
+
+	var r0 []types.Invite
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]types.Invite, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []types.Invite); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.Invite)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ListInvites_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListInvites'
+type MockDatabase_ListInvites_Call struct {
+	*mock.Call
+}
+
+// ListInvites is a helper method to define mock.On call
+//   - _a0 context.Context
+func (_e *MockDatabase_Expecter) ListInvites(_a0 interface{}) *MockDatabase_ListInvites_Call {
+	return &MockDatabase_ListInvites_Call{Call: _e.mock.On("ListInvites", _a0)}
+}
+
+func (_c *MockDatabase_ListInvites_Call) Run(run func(_a0 context.Context)) *MockDatabase_ListInvites_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ListInvites_Call) Return(_a0 []types.Invite, _a1 error) *MockDatabase_ListInvites_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ListInvites_Call) RunAndReturn(run func(context.Context) ([]types.Invite, error)) *MockDatabase_ListInvites_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMembers provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) ListMembers(_a0 context.Context, _a1 int) ([]types.Membership, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMembers")
+	}
+
+	var r0 []types.Membership
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]types.Membership, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []types.Membership); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.Membership)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ListMembers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMembers'
+type MockDatabase_ListMembers_Call struct {
+	*mock.Call
+}
+
+// ListMembers is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) ListMembers(_a0 interface{}, _a1 interface{}) *MockDatabase_ListMembers_Call {
+	return &MockDatabase_ListMembers_Call{Call: _e.mock.On("ListMembers", _a0, _a1)}
+}
+
+func (_c *MockDatabase_ListMembers_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_ListMembers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ListMembers_Call) Return(_a0 []types.Membership, _a1 error) *MockDatabase_ListMembers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ListMembers_Call) RunAndReturn(run func(context.Context, int) ([]types.Membership, error)) *MockDatabase_ListMembers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMemberships provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) ListMemberships(_a0 context.Context, _a1 int) ([]types.Membership, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMemberships")
+	}
+
+	var r0 []types.Membership
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]types.Membership, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []types.Membership); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.Membership)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ListMemberships_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMemberships'
+type MockDatabase_ListMemberships_Call struct {
+	*mock.Call
+}
+
+// ListMemberships is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) ListMemberships(_a0 interface{}, _a1 interface{}) *MockDatabase_ListMemberships_Call {
+	return &MockDatabase_ListMemberships_Call{Call: _e.mock.On("ListMemberships", _a0, _a1)}
+}
+
+func (_c *MockDatabase_ListMemberships_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_ListMemberships_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ListMemberships_Call) Return(_a0 []types.Membership, _a1 error) *MockDatabase_ListMemberships_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ListMemberships_Call) RunAndReturn(run func(context.Context, int) ([]types.Membership, error)) *MockDatabase_ListMemberships_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListOrgItems provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) ListOrgItems(_a0 context.Context, _a1 int, _a2 string, _a3 []string) ([]types.OrgItem, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrgItems")
+	}
+
+	var r0 []types.OrgItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, []string) ([]types.OrgItem, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, []string) []types.OrgItem); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.OrgItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, []string) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ListOrgItems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOrgItems'
+type MockDatabase_ListOrgItems_Call struct {
+	*mock.Call
+}
+
+// ListOrgItems is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 []string
+func (_e *MockDatabase_Expecter) ListOrgItems(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_ListOrgItems_Call {
+	return &MockDatabase_ListOrgItems_Call{Call: _e.mock.On("ListOrgItems", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_ListOrgItems_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 []string)) *MockDatabase_ListOrgItems_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].([]string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ListOrgItems_Call) Return(_a0 []types.OrgItem, _a1 error) *MockDatabase_ListOrgItems_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ListOrgItems_Call) RunAndReturn(run func(context.Context, int, string, []string) ([]types.OrgItem, error)) *MockDatabase_ListOrgItems_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSends provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) ListSends(_a0 context.Context, _a1 int) ([]types.Send, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSends")
+	}
+
+	var r0 []types.Send
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]types.Send, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []types.Send); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.Send)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ListSends_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSends'
+type MockDatabase_ListSends_Call struct {
+	*mock.Call
+}
+
+// ListSends is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) ListSends(_a0 interface{}, _a1 interface{}) *MockDatabase_ListSends_Call {
+	return &MockDatabase_ListSends_Call{Call: _e.mock.On("ListSends", _a0, _a1)}
+}
+
+func (_c *MockDatabase_ListSends_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_ListSends_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ListSends_Call) Return(_a0 []types.Send, _a1 error) *MockDatabase_ListSends_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ListSends_Call) RunAndReturn(run func(context.Context, int) ([]types.Send, error)) *MockDatabase_ListSends_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSessions provides a mock function with given fields: _a0, _a1
+func (_m *MockDatabase) ListSessions(_a0 context.Context, _a1 int) ([]types.Session, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSessions")
+	}
+
+	var r0 []types.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]types.Session, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []types.Session); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ListSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSessions'
+type MockDatabase_ListSessions_Call struct {
+	*mock.Call
+}
+
+// ListSessions is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+func (_e *MockDatabase_Expecter) ListSessions(_a0 interface{}, _a1 interface{}) *MockDatabase_ListSessions_Call {
+	return &MockDatabase_ListSessions_Call{Call: _e.mock.On("ListSessions", _a0, _a1)}
+}
+
+func (_c *MockDatabase_ListSessions_Call) Run(run func(_a0 context.Context, _a1 int)) *MockDatabase_ListSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ListSessions_Call) Return(_a0 []types.Session, _a1 error) *MockDatabase_ListSessions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ListSessions_Call) RunAndReturn(run func(context.Context, int) ([]types.Session, error)) *MockDatabase_ListSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LoginDevice provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) LoginDevice(_a0 context.Context, _a1 int, _a2 types.NewDevice, _a3 bool) (types.DeviceLogin, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoginDevice")
+	}
+
+	var r0 types.DeviceLogin
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.NewDevice, bool) (types.DeviceLogin, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.NewDevice, bool) types.DeviceLogin); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Get(0).(types.DeviceLogin)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, types.NewDevice, bool) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_LoginDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginDevice'
+type MockDatabase_LoginDevice_Call struct {
+	*mock.Call
+}
+
+// LoginDevice is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.NewDevice
+//   - _a3 bool
+func (_e *MockDatabase_Expecter) LoginDevice(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_LoginDevice_Call {
+	return &MockDatabase_LoginDevice_Call{Call: _e.mock.On("LoginDevice", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_LoginDevice_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.NewDevice, _a3 bool)) *MockDatabase_LoginDevice_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.NewDevice), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_LoginDevice_Call) Return(_a0 types.DeviceLogin, _a1 error) *MockDatabase_LoginDevice_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_LoginDevice_Call) RunAndReturn(run func(context.Context, int, types.NewDevice, bool) (types.DeviceLogin, error)) *MockDatabase_LoginDevice_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MigrateVaultKey provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) MigrateVaultKey(_a0 context.Context, _a1 int, _a2 types.VaultKeyMigration) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MigrateVaultKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.VaultKeyMigration) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_MigrateVaultKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MigrateVaultKey'
+type MockDatabase_MigrateVaultKey_Call struct {
+	*mock.Call
+}
+
+// MigrateVaultKey is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.VaultKeyMigration
+func (_e *MockDatabase_Expecter) MigrateVaultKey(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_MigrateVaultKey_Call {
+	return &MockDatabase_MigrateVaultKey_Call{Call: _e.mock.On("MigrateVaultKey", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_MigrateVaultKey_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.VaultKeyMigration)) *MockDatabase_MigrateVaultKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.VaultKeyMigration))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_MigrateVaultKey_Call) Return(_a0 error) *MockDatabase_MigrateVaultKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_MigrateVaultKey_Call) RunAndReturn(run func(context.Context, int, types.VaultKeyMigration) error) *MockDatabase_MigrateVaultKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MoveEmergencyAccess provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) MoveEmergencyAccess(_a0 context.Context, _a1 int, _a2 int, _a3 types.EmergencyStatus) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MoveEmergencyAccess")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, types.EmergencyStatus) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_MoveEmergencyAccess_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MoveEmergencyAccess'
+type MockDatabase_MoveEmergencyAccess_Call struct {
+	*mock.Call
+}
+
+// MoveEmergencyAccess is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+//   - _a3 types.EmergencyStatus
+func (_e *MockDatabase_Expecter) MoveEmergencyAccess(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_MoveEmergencyAccess_Call {
+	return &MockDatabase_MoveEmergencyAccess_Call{Call: _e.mock.On("MoveEmergencyAccess", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_MoveEmergencyAccess_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int, _a3 types.EmergencyStatus)) *MockDatabase_MoveEmergencyAccess_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(types.EmergencyStatus))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_MoveEmergencyAccess_Call) Return(_a0 error) *MockDatabase_MoveEmergencyAccess_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_MoveEmergencyAccess_Call) RunAndReturn(run func(context.Context, int, int, types.EmergencyStatus) error) *MockDatabase_MoveEmergencyAccess_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OpenSend provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) OpenSend(_a0 context.Context, _a1 string, _a2 func(*string) bool) (*types.SendContent, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OpenSend")
+	}
+
+	var r0 *types.SendContent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, func(*string) bool) (*types.SendContent, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, func(*string) bool) *types.SendContent); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.SendContent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, func(*string) bool) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_OpenSend_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OpenSend'
+type MockDatabase_OpenSend_Call struct {
+	*mock.Call
+}
+
+// OpenSend is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 string
+//   - _a2 func(*string) bool
+func (_e *MockDatabase_Expecter) OpenSend(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_OpenSend_Call {
+	return &MockDatabase_OpenSend_Call{Call: _e.mock.On("OpenSend", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_OpenSend_Call) Run(run func(_a0 context.Context, _a1 string, _a2 func(*string) bool)) *MockDatabase_OpenSend_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(func(*string) bool))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_OpenSend_Call) Return(_a0 *types.SendContent, _a1 error) *MockDatabase_OpenSend_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_OpenSend_Call) RunAndReturn(run func(context.Context, string, func(*string) bool) (*types.SendContent, error)) *MockDatabase_OpenSend_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PollDevice provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) PollDevice(_a0 context.Context, _a1 int, _a2 string) (types.DevicePollResult, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PollDevice")
+	}
+
+	var r0 types.DevicePollResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) (types.DevicePollResult, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) types.DevicePollResult); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(types.DevicePollResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_PollDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PollDevice'
+type MockDatabase_PollDevice_Call struct {
+	*mock.Call
+}
+
+// PollDevice is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+func (_e *MockDatabase_Expecter) PollDevice(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_PollDevice_Call {
+	return &MockDatabase_PollDevice_Call{Call: _e.mock.On("PollDevice", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_PollDevice_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_PollDevice_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_PollDevice_Call) Return(_a0 types.DevicePollResult, _a1 error) *MockDatabase_PollDevice_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_PollDevice_Call) RunAndReturn(run func(context.Context, int, string) (types.DevicePollResult, error)) *MockDatabase_PollDevice_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PutOrgItem provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) PutOrgItem(_a0 context.Context, _a1 int, _a2 types.OrgItem, _a3 []string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PutOrgItem")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.OrgItem, []string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_PutOrgItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PutOrgItem'
+type MockDatabase_PutOrgItem_Call struct {
+	*mock.Call
+}
+
+// PutOrgItem is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.OrgItem
+//   - _a3 []string
+func (_e *MockDatabase_Expecter) PutOrgItem(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_PutOrgItem_Call {
+	return &MockDatabase_PutOrgItem_Call{Call: _e.mock.On("PutOrgItem", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_PutOrgItem_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.OrgItem, _a3 []string)) *MockDatabase_PutOrgItem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.OrgItem), args[3].([]string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_PutOrgItem_Call) Return(_a0 error) *MockDatabase_PutOrgItem_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_PutOrgItem_Call) RunAndReturn(run func(context.Context, int, types.OrgItem, []string) error) *MockDatabase_PutOrgItem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecoverAccount provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *MockDatabase) RecoverAccount(_a0 context.Context, _a1 string, _a2 func(string) bool, _a3 string, _a4 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecoverAccount")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, func(string) bool, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_RecoverAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecoverAccount'
+type MockDatabase_RecoverAccount_Call struct {
+	*mock.Call
+}
+
+// RecoverAccount is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 string
+//   - _a2 func(string) bool
+//   - _a3 string
+//   - _a4 string
+func (_e *MockDatabase_Expecter) RecoverAccount(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}, _a4 interface{}) *MockDatabase_RecoverAccount_Call {
+	return &MockDatabase_RecoverAccount_Call{Call: _e.mock.On("RecoverAccount", _a0, _a1, _a2, _a3, _a4)}
+}
+
+func (_c *MockDatabase_RecoverAccount_Call) Run(run func(_a0 context.Context, _a1 string, _a2 func(string) bool, _a3 string, _a4 string)) *MockDatabase_RecoverAccount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(func(string) bool), args[3].(string), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_RecoverAccount_Call) Return(_a0 error) *MockDatabase_RecoverAccount_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_RecoverAccount_Call) RunAndReturn(run func(context.Context, string, func(string) bool, string, string) error) *MockDatabase_RecoverAccount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RejectDevice provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) RejectDevice(_a0 context.Context, _a1 int, _a2 int) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RejectDevice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_RejectDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RejectDevice'
+type MockDatabase_RejectDevice_Call struct {
+	*mock.Call
+}
+
+// RejectDevice is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+func (_e *MockDatabase_Expecter) RejectDevice(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_RejectDevice_Call {
+	return &MockDatabase_RejectDevice_Call{Call: _e.mock.On("RejectDevice", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_RejectDevice_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int)) *MockDatabase_RejectDevice_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_RejectDevice_Call) Return(_a0 error) *MockDatabase_RejectDevice_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_RejectDevice_Call) RunAndReturn(run func(context.Context, int, int) error) *MockDatabase_RejectDevice_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RejectEmergencyAccess provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) RejectEmergencyAccess(_a0 context.Context, _a1 int, _a2 int) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RejectEmergencyAccess")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_RejectEmergencyAccess_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RejectEmergencyAccess'
+type MockDatabase_RejectEmergencyAccess_Call struct {
+	*mock.Call
+}
+
+// RejectEmergencyAccess is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+func (_e *MockDatabase_Expecter) RejectEmergencyAccess(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_RejectEmergencyAccess_Call {
+	return &MockDatabase_RejectEmergencyAccess_Call{Call: _e.mock.On("RejectEmergencyAccess", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_RejectEmergencyAccess_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int)) *MockDatabase_RejectEmergencyAccess_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_RejectEmergencyAccess_Call) Return(_a0 error) *MockDatabase_RejectEmergencyAccess_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_RejectEmergencyAccess_Call) RunAndReturn(run func(context.Context, int, int) error) *MockDatabase_RejectEmergencyAccess_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveMember provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) RemoveMember(_a0 context.Context, _a1 int, _a2 string, _a3 types.KeyRotation) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, types.KeyRotation) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_RemoveMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveMember'
+type MockDatabase_RemoveMember_Call struct {
+	*mock.Call
+}
+
+// RemoveMember is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 types.KeyRotation
+func (_e *MockDatabase_Expecter) RemoveMember(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_RemoveMember_Call {
+	return &MockDatabase_RemoveMember_Call{Call: _e.mock.On("RemoveMember", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_RemoveMember_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 types.KeyRotation)) *MockDatabase_RemoveMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(types.KeyRotation))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_RemoveMember_Call) Return(_a0 error) *MockDatabase_RemoveMember_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_RemoveMember_Call) RunAndReturn(run func(context.Context, int, string, types.KeyRotation) error) *MockDatabase_RemoveMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReplaceAttachment provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4, _a5
+func (_m *MockDatabase) ReplaceAttachment(_a0 context.Context, _a1 int, _a2 string, _a3 int, _a4 types.Attachment, _a5 []byte) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4, _a5)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceAttachment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int, types.Attachment, []byte) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4, _a5)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_ReplaceAttachment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReplaceAttachment'
+type MockDatabase_ReplaceAttachment_Call struct {
+	*mock.Call
+}
+
+// ReplaceAttachment is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 int
+//   - _a4 types.Attachment
+//   - _a5 []byte
+func (_e *MockDatabase_Expecter) ReplaceAttachment(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}, _a4 interface{}, _a5 interface{}) *MockDatabase_ReplaceAttachment_Call {
+	return &MockDatabase_ReplaceAttachment_Call{Call: _e.mock.On("ReplaceAttachment", _a0, _a1, _a2, _a3, _a4, _a5)}
+}
+
+func (_c *MockDatabase_ReplaceAttachment_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 int, _a4 types.Attachment, _a5 []byte)) *MockDatabase_ReplaceAttachment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(int), args[4].(types.Attachment), args[5].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ReplaceAttachment_Call) Return(_a0 error) *MockDatabase_ReplaceAttachment_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_ReplaceAttachment_Call) RunAndReturn(run func(context.Context, int, string, int, types.Attachment, []byte) error) *MockDatabase_ReplaceAttachment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetUserDevices provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) ResetUserDevices(_a0 context.Context, _a1 int, _a2 string, _a3 time.Time) (int64, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetUserDevices")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, time.Time) (int64, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, time.Time) int64); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, time.Time) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ResetUserDevices_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetUserDevices'
+type MockDatabase_ResetUserDevices_Call struct {
+	*mock.Call
+}
+
+// ResetUserDevices is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 time.Time
+func (_e *MockDatabase_Expecter) ResetUserDevices(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_ResetUserDevices_Call {
+	return &MockDatabase_ResetUserDevices_Call{Call: _e.mock.On("ResetUserDevices", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_ResetUserDevices_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 time.Time)) *MockDatabase_ResetUserDevices_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ResetUserDevices_Call) Return(_a0 int64, _a1 error) *MockDatabase_ResetUserDevices_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ResetUserDevices_Call) RunAndReturn(run func(context.Context, int, string, time.Time) (int64, error)) *MockDatabase_ResetUserDevices_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeShare provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) RevokeShare(_a0 context.Context, _a1 int, _a2 string, _a3 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeShare")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_RevokeShare_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeShare'
+type MockDatabase_RevokeShare_Call struct {
+	*mock.Call
+}
+
+// RevokeShare is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 string
+func (_e *MockDatabase_Expecter) RevokeShare(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_RevokeShare_Call {
+	return &MockDatabase_RevokeShare_Call{Call: _e.mock.On("RevokeShare", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_RevokeShare_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 string)) *MockDatabase_RevokeShare_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_RevokeShare_Call) Return(_a0 error) *MockDatabase_RevokeShare_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_RevokeShare_Call) RunAndReturn(run func(context.Context, int, string, string) error) *MockDatabase_RevokeShare_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ScheduleUserDeletion provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) ScheduleUserDeletion(_a0 context.Context, _a1 int, _a2 time.Time) (time.Time, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScheduleUserDeletion")
+	}
+
+	var r0 time.Time
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) (time.Time, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) time.Time); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Time) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDatabase_ScheduleUserDeletion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ScheduleUserDeletion'
+type MockDatabase_ScheduleUserDeletion_Call struct {
+	*mock.Call
+}
+
+// ScheduleUserDeletion is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 time.Time
+func (_e *MockDatabase_Expecter) ScheduleUserDeletion(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_ScheduleUserDeletion_Call {
+	return &MockDatabase_ScheduleUserDeletion_Call{Call: _e.mock.On("ScheduleUserDeletion", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_ScheduleUserDeletion_Call) Run(run func(_a0 context.Context, _a1 int, _a2 time.Time)) *MockDatabase_ScheduleUserDeletion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_ScheduleUserDeletion_Call) Return(_a0 time.Time, _a1 error) *MockDatabase_ScheduleUserDeletion_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDatabase_ScheduleUserDeletion_Call) RunAndReturn(run func(context.Context, int, time.Time) (time.Time, error)) *MockDatabase_ScheduleUserDeletion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetMemberCollections provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) SetMemberCollections(_a0 context.Context, _a1 int, _a2 string, _a3 []string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMemberCollections")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, []string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_SetMemberCollections_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMemberCollections'
+type MockDatabase_SetMemberCollections_Call struct {
+	*mock.Call
+}
+
+// SetMemberCollections is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 []string
+func (_e *MockDatabase_Expecter) SetMemberCollections(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_SetMemberCollections_Call {
+	return &MockDatabase_SetMemberCollections_Call{Call: _e.mock.On("SetMemberCollections", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_SetMemberCollections_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 []string)) *MockDatabase_SetMemberCollections_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].([]string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_SetMemberCollections_Call) Return(_a0 error) *MockDatabase_SetMemberCollections_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_SetMemberCollections_Call) RunAndReturn(run func(context.Context, int, string, []string) error) *MockDatabase_SetMemberCollections_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetMemberRole provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) SetMemberRole(_a0 context.Context, _a1 int, _a2 string, _a3 types.Role) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMemberRole")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, types.Role) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_SetMemberRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMemberRole'
+type MockDatabase_SetMemberRole_Call struct {
+	*mock.Call
+}
+
+// SetMemberRole is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+//   - _a3 types.Role
+func (_e *MockDatabase_Expecter) SetMemberRole(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_SetMemberRole_Call {
+	return &MockDatabase_SetMemberRole_Call{Call: _e.mock.On("SetMemberRole", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_SetMemberRole_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 types.Role)) *MockDatabase_SetMemberRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(types.Role))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_SetMemberRole_Call) Return(_a0 error) *MockDatabase_SetMemberRole_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_SetMemberRole_Call) RunAndReturn(run func(context.Context, int, string, types.Role) error) *MockDatabase_SetMemberRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetPrivateKey provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) SetPrivateKey(_a0 context.Context, _a1 int, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPrivateKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_SetPrivateKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPrivateKey'
+type MockDatabase_SetPrivateKey_Call struct {
+	*mock.Call
+}
+
+// SetPrivateKey is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 string
+func (_e *MockDatabase_Expecter) SetPrivateKey(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_SetPrivateKey_Call {
+	return &MockDatabase_SetPrivateKey_Call{Call: _e.mock.On("SetPrivateKey", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_SetPrivateKey_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_SetPrivateKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_GetText_Call) Return(_a0 *types.TextData, _a1 error) *MockDatabase_GetText_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockDatabase_SetPrivateKey_Call) Return(_a0 error) *MockDatabase_SetPrivateKey_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_GetText_Call) RunAndReturn(run func(context.Context, int) (*types.TextData, error)) *MockDatabase_GetText_Call {
+func (_c *MockDatabase_SetPrivateKey_Call) RunAndReturn(run func(context.Context, int, string) error) *MockDatabase_SetPrivateKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetUserHashedPassword provides a mock function with given fields: _a0, _a1
-func (_m *MockDatabase) GetUserHashedPassword(_a0 context.Context, _a1 string) (string, error) {
-	ret := _m.Called(_a0, _a1)
+// SetRecoveryHash provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) SetRecoveryHash(_a0 context.Context, _a1 int, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUserHashedPassword")
-	}
-
-	var r0 string
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
-		return rf(_a0, _a1)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
-		r0 = rf(_a0, _a1)
-	} else {
-		r0 = ret.Get(0).(string)
+		panic("no return value specified for SetRecoveryHash")
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(_a0, _a1)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// MockDatabase_GetUserHashedPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserHashedPassword'
-type MockDatabase_GetUserHashedPassword_Call struct {
+// MockDatabase_SetRecoveryHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRecoveryHash'
+type MockDatabase_SetRecoveryHash_Call struct {
 	*mock.Call
 }
 
-// GetUserHashedPassword is a helper method to define mock.On call
+// SetRecoveryHash is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 string
-func (_e *MockDatabase_Expecter) GetUserHashedPassword(_a0 interface{}, _a1 interface{}) *MockDatabase_GetUserHashedPassword_Call {
-	return &MockDatabase_GetUserHashedPassword_Call{Call: _e.mock.On("GetUserHashedPassword", _a0, _a1)}
+//   - _a1 int
+//   - _a2 string
+func (_e *MockDatabase_Expecter) SetRecoveryHash(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_SetRecoveryHash_Call {
+	return &MockDatabase_SetRecoveryHash_Call{Call: _e.mock.On("SetRecoveryHash", _a0, _a1, _a2)}
 }
 
-func (_c *MockDatabase_GetUserHashedPassword_Call) Run(run func(_a0 context.Context, _a1 string)) *MockDatabase_GetUserHashedPassword_Call {
+func (_c *MockDatabase_SetRecoveryHash_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_SetRecoveryHash_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_GetUserHashedPassword_Call) Return(_a0 string, _a1 error) *MockDatabase_GetUserHashedPassword_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockDatabase_SetRecoveryHash_Call) Return(_a0 error) *MockDatabase_SetRecoveryHash_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_GetUserHashedPassword_Call) RunAndReturn(run func(context.Context, string) (string, error)) *MockDatabase_GetUserHashedPassword_Call {
+func (_c *MockDatabase_SetRecoveryHash_Call) RunAndReturn(run func(context.Context, int, string) error) *MockDatabase_SetRecoveryHash_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetUserID provides a mock function with given fields: _a0, _a1
-func (_m *MockDatabase) GetUserID(_a0 context.Context, _a1 string) (int, error) {
-	ret := _m.Called(_a0, _a1)
+// SetUserDisabled provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) SetUserDisabled(_a0 context.Context, _a1 int, _a2 bool) error {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUserID")
-	}
-
-	var r0 int
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
-		return rf(_a0, _a1)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
-		r0 = rf(_a0, _a1)
-	} else {
-		r0 = ret.Get(0).(int)
+		panic("no return value specified for SetUserDisabled")
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(_a0, _a1)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) error); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// MockDatabase_GetUserID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserID'
-type MockDatabase_GetUserID_Call struct {
+// MockDatabase_SetUserDisabled_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUserDisabled'
+type MockDatabase_SetUserDisabled_Call struct {
 	*mock.Call
 }
 
-// GetUserID is a helper method to define mock.On call
+// SetUserDisabled is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 string
-func (_e *MockDatabase_Expecter) GetUserID(_a0 interface{}, _a1 interface{}) *MockDatabase_GetUserID_Call {
-	return &MockDatabase_GetUserID_Call{Call: _e.mock.On("GetUserID", _a0, _a1)}
+//   - _a1 int
+//   - _a2 bool
+func (_e *MockDatabase_Expecter) SetUserDisabled(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_SetUserDisabled_Call {
+	return &MockDatabase_SetUserDisabled_Call{Call: _e.mock.On("SetUserDisabled", _a0, _a1, _a2)}
 }
 
-func (_c *MockDatabase_GetUserID_Call) Run(run func(_a0 context.Context, _a1 string)) *MockDatabase_GetUserID_Call {
+func (_c *MockDatabase_SetUserDisabled_Call) Run(run func(_a0 context.Context, _a1 int, _a2 bool)) *MockDatabase_SetUserDisabled_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(int), args[2].(bool))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_GetUserID_Call) Return(_a0 int, _a1 error) *MockDatabase_GetUserID_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockDatabase_SetUserDisabled_Call) Return(_a0 error) *MockDatabase_SetUserDisabled_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_GetUserID_Call) RunAndReturn(run func(context.Context, string) (int, error)) *MockDatabase_GetUserID_Call {
+func (_c *MockDatabase_SetUserDisabled_Call) RunAndReturn(run func(context.Context, int, bool) error) *MockDatabase_SetUserDisabled_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// InsertBinaryData provides a mock function with given fields: _a0, _a1, _a2
-func (_m *MockDatabase) InsertBinaryData(_a0 context.Context, _a1 int, _a2 types.BinaryItem) error {
+// SetUserKeys provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) SetUserKeys(_a0 context.Context, _a1 int, _a2 types.UserKeys) error {
 	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
-		panic("no return value specified for InsertBinaryData")
+		panic("no return value specified for SetUserKeys")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, types.BinaryItem) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.UserKeys) error); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
@@ -608,46 +4754,46 @@ func (_m *MockDatabase) InsertBinaryData(_a0 context.Context, _a1 int, _a2 types
 	return r0
 }
 
-// MockDatabase_InsertBinaryData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertBinaryData'
-type MockDatabase_InsertBinaryData_Call struct {
+// MockDatabase_SetUserKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUserKeys'
+type MockDatabase_SetUserKeys_Call struct {
 	*mock.Call
 }
 
-// InsertBinaryData is a helper method to define mock.On call
+// SetUserKeys is a helper method to define mock.On call
 //   - _a0 context.Context
 //   - _a1 int
-//   - _a2 types.BinaryItem
-func (_e *MockDatabase_Expecter) InsertBinaryData(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_InsertBinaryData_Call {
-	return &MockDatabase_InsertBinaryData_Call{Call: _e.mock.On("InsertBinaryData", _a0, _a1, _a2)}
+//   - _a2 types.UserKeys
+func (_e *MockDatabase_Expecter) SetUserKeys(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_SetUserKeys_Call {
+	return &MockDatabase_SetUserKeys_Call{Call: _e.mock.On("SetUserKeys", _a0, _a1, _a2)}
 }
 
-func (_c *MockDatabase_InsertBinaryData_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.BinaryItem)) *MockDatabase_InsertBinaryData_Call {
+func (_c *MockDatabase_SetUserKeys_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.UserKeys)) *MockDatabase_SetUserKeys_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(types.BinaryItem))
+		run(args[0].(context.Context), args[1].(int), args[2].(types.UserKeys))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_InsertBinaryData_Call) Return(_a0 error) *MockDatabase_InsertBinaryData_Call {
+func (_c *MockDatabase_SetUserKeys_Call) Return(_a0 error) *MockDatabase_SetUserKeys_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_InsertBinaryData_Call) RunAndReturn(run func(context.Context, int, types.BinaryItem) error) *MockDatabase_InsertBinaryData_Call {
+func (_c *MockDatabase_SetUserKeys_Call) RunAndReturn(run func(context.Context, int, types.UserKeys) error) *MockDatabase_SetUserKeys_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// InsertCreditCard provides a mock function with given fields: _a0, _a1, _a2
-func (_m *MockDatabase) InsertCreditCard(_a0 context.Context, _a1 int, _a2 types.CreditCardItem) error {
+// SetVaultKey provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) SetVaultKey(_a0 context.Context, _a1 int, _a2 string) error {
 	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
-		panic("no return value specified for InsertCreditCard")
+		panic("no return value specified for SetVaultKey")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, types.CreditCardItem) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
@@ -656,47 +4802,47 @@ func (_m *MockDatabase) InsertCreditCard(_a0 context.Context, _a1 int, _a2 types
 	return r0
 }
 
-// MockDatabase_InsertCreditCard_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertCreditCard'
-type MockDatabase_InsertCreditCard_Call struct {
+// MockDatabase_SetVaultKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetVaultKey'
+type MockDatabase_SetVaultKey_Call struct {
 	*mock.Call
 }
 
-// InsertCreditCard is a helper method to define mock.On call
+// SetVaultKey is a helper method to define mock.On call
 //   - _a0 context.Context
 //   - _a1 int
-//   - _a2 types.CreditCardItem
-func (_e *MockDatabase_Expecter) InsertCreditCard(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_InsertCreditCard_Call {
-	return &MockDatabase_InsertCreditCard_Call{Call: _e.mock.On("InsertCreditCard", _a0, _a1, _a2)}
+//   - _a2 string
+func (_e *MockDatabase_Expecter) SetVaultKey(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_SetVaultKey_Call {
+	return &MockDatabase_SetVaultKey_Call{Call: _e.mock.On("SetVaultKey", _a0, _a1, _a2)}
 }
 
-func (_c *MockDatabase_InsertCreditCard_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.CreditCardItem)) *MockDatabase_InsertCreditCard_Call {
+func (_c *MockDatabase_SetVaultKey_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string)) *MockDatabase_SetVaultKey_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(types.CreditCardItem))
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_InsertCreditCard_Call) Return(_a0 error) *MockDatabase_InsertCreditCard_Call {
+func (_c *MockDatabase_SetVaultKey_Call) Return(_a0 error) *MockDatabase_SetVaultKey_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_InsertCreditCard_Call) RunAndReturn(run func(context.Context, int, types.CreditCardItem) error) *MockDatabase_InsertCreditCard_Call {
+func (_c *MockDatabase_SetVaultKey_Call) RunAndReturn(run func(context.Context, int, string) error) *MockDatabase_SetVaultKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// InsertLogoPass provides a mock function with given fields: _a0, _a1, _a2
-func (_m *MockDatabase) InsertLogoPass(_a0 context.Context, _a1 int, _a2 types.LoginPasswordItem) error {
-	ret := _m.Called(_a0, _a1, _a2)
+// ShareItem provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) ShareItem(_a0 context.Context, _a1 int, _a2 string, _a3 types.Share) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	if len(ret) == 0 {
-		panic("no return value specified for InsertLogoPass")
+		panic("no return value specified for ShareItem")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, types.LoginPasswordItem) error); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, types.Share) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -704,47 +4850,48 @@ func (_m *MockDatabase) InsertLogoPass(_a0 context.Context, _a1 int, _a2 types.L
 	return r0
 }
 
-// MockDatabase_InsertLogoPass_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertLogoPass'
-type MockDatabase_InsertLogoPass_Call struct {
+// MockDatabase_ShareItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ShareItem'
+type MockDatabase_ShareItem_Call struct {
 	*mock.Call
 }
 
-// InsertLogoPass is a helper method to define mock.On call
+// ShareItem is a helper method to define mock.On call
 //   - _a0 context.Context
 //   - _a1 int
-//   - _a2 types.LoginPasswordItem
-func (_e *MockDatabase_Expecter) InsertLogoPass(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_InsertLogoPass_Call {
-	return &MockDatabase_InsertLogoPass_Call{Call: _e.mock.On("InsertLogoPass", _a0, _a1, _a2)}
+//   - _a2 string
+//   - _a3 types.Share
+func (_e *MockDatabase_Expecter) ShareItem(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_ShareItem_Call {
+	return &MockDatabase_ShareItem_Call{Call: _e.mock.On("ShareItem", _a0, _a1, _a2, _a3)}
 }
 
-func (_c *MockDatabase_InsertLogoPass_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.LoginPasswordItem)) *MockDatabase_InsertLogoPass_Call {
+func (_c *MockDatabase_ShareItem_Call) Run(run func(_a0 context.Context, _a1 int, _a2 string, _a3 types.Share)) *MockDatabase_ShareItem_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(types.LoginPasswordItem))
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(types.Share))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_InsertLogoPass_Call) Return(_a0 error) *MockDatabase_InsertLogoPass_Call {
+func (_c *MockDatabase_ShareItem_Call) Return(_a0 error) *MockDatabase_ShareItem_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_InsertLogoPass_Call) RunAndReturn(run func(context.Context, int, types.LoginPasswordItem) error) *MockDatabase_InsertLogoPass_Call {
+func (_c *MockDatabase_ShareItem_Call) RunAndReturn(run func(context.Context, int, string, types.Share) error) *MockDatabase_ShareItem_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// InsertText provides a mock function with given fields: _a0, _a1, _a2
-func (_m *MockDatabase) InsertText(_a0 context.Context, _a1 int, _a2 types.TextItem) error {
-	ret := _m.Called(_a0, _a1, _a2)
+// TouchSession provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) TouchSession(_a0 context.Context, _a1 string, _a2 int, _a3 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	if len(ret) == 0 {
-		panic("no return value specified for InsertText")
+		panic("no return value specified for TouchSession")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, types.TextItem) error); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -752,32 +4899,33 @@ func (_m *MockDatabase) InsertText(_a0 context.Context, _a1 int, _a2 types.TextI
 	return r0
 }
 
-// MockDatabase_InsertText_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertText'
-type MockDatabase_InsertText_Call struct {
+// MockDatabase_TouchSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchSession'
+type MockDatabase_TouchSession_Call struct {
 	*mock.Call
 }
 
-// InsertText is a helper method to define mock.On call
+// TouchSession is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 int
-//   - _a2 types.TextItem
-func (_e *MockDatabase_Expecter) InsertText(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_InsertText_Call {
-	return &MockDatabase_InsertText_Call{Call: _e.mock.On("InsertText", _a0, _a1, _a2)}
+//   - _a1 string
+//   - _a2 int
+//   - _a3 string
+func (_e *MockDatabase_Expecter) TouchSession(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_TouchSession_Call {
+	return &MockDatabase_TouchSession_Call{Call: _e.mock.On("TouchSession", _a0, _a1, _a2, _a3)}
 }
 
-func (_c *MockDatabase_InsertText_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.TextItem)) *MockDatabase_InsertText_Call {
+func (_c *MockDatabase_TouchSession_Call) Run(run func(_a0 context.Context, _a1 string, _a2 int, _a3 string)) *MockDatabase_TouchSession_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(types.TextItem))
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(string))
 	})
 	return _c
 }
 
-func (_c *MockDatabase_InsertText_Call) Return(_a0 error) *MockDatabase_InsertText_Call {
+func (_c *MockDatabase_TouchSession_Call) Return(_a0 error) *MockDatabase_TouchSession_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockDatabase_InsertText_Call) RunAndReturn(run func(context.Context, int, types.TextItem) error) *MockDatabase_InsertText_Call {
+func (_c *MockDatabase_TouchSession_Call) RunAndReturn(run func(context.Context, string, int, string) error) *MockDatabase_TouchSession_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -926,6 +5074,151 @@ func (_c *MockDatabase_UpdateLogoPass_Call) RunAndReturn(run func(context.Contex
 	return _c
 }
 
+// UpdateSSHKey provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) UpdateSSHKey(_a0 context.Context, _a1 int, _a2 types.SSHKeyItem) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSSHKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.SSHKeyItem) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_UpdateSSHKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateSSHKey'
+type MockDatabase_UpdateSSHKey_Call struct {
+	*mock.Call
+}
+
+// UpdateSSHKey is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.SSHKeyItem
+func (_e *MockDatabase_Expecter) UpdateSSHKey(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_UpdateSSHKey_Call {
+	return &MockDatabase_UpdateSSHKey_Call{Call: _e.mock.On("UpdateSSHKey", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_UpdateSSHKey_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.SSHKeyItem)) *MockDatabase_UpdateSSHKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.SSHKeyItem))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_UpdateSSHKey_Call) Return(_a0 error) *MockDatabase_UpdateSSHKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_UpdateSSHKey_Call) RunAndReturn(run func(context.Context, int, types.SSHKeyItem) error) *MockDatabase_UpdateSSHKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateShare provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MockDatabase) UpdateShare(_a0 context.Context, _a1 int, _a2 int, _a3 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateShare")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_UpdateShare_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateShare'
+type MockDatabase_UpdateShare_Call struct {
+	*mock.Call
+}
+
+// UpdateShare is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+//   - _a3 string
+func (_e *MockDatabase_Expecter) UpdateShare(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *MockDatabase_UpdateShare_Call {
+	return &MockDatabase_UpdateShare_Call{Call: _e.mock.On("UpdateShare", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *MockDatabase_UpdateShare_Call) Run(run func(_a0 context.Context, _a1 int, _a2 int, _a3 string)) *MockDatabase_UpdateShare_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_UpdateShare_Call) Return(_a0 error) *MockDatabase_UpdateShare_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_UpdateShare_Call) RunAndReturn(run func(context.Context, int, int, string) error) *MockDatabase_UpdateShare_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateTOTP provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockDatabase) UpdateTOTP(_a0 context.Context, _a1 int, _a2 types.TOTPItem) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTOTP")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, types.TOTPItem) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDatabase_UpdateTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateTOTP'
+type MockDatabase_UpdateTOTP_Call struct {
+	*mock.Call
+}
+
+// UpdateTOTP is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 types.TOTPItem
+func (_e *MockDatabase_Expecter) UpdateTOTP(_a0 interface{}, _a1 interface{}, _a2 interface{}) *MockDatabase_UpdateTOTP_Call {
+	return &MockDatabase_UpdateTOTP_Call{Call: _e.mock.On("UpdateTOTP", _a0, _a1, _a2)}
+}
+
+func (_c *MockDatabase_UpdateTOTP_Call) Run(run func(_a0 context.Context, _a1 int, _a2 types.TOTPItem)) *MockDatabase_UpdateTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(types.TOTPItem))
+	})
+	return _c
+}
+
+func (_c *MockDatabase_UpdateTOTP_Call) Return(_a0 error) *MockDatabase_UpdateTOTP_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDatabase_UpdateTOTP_Call) RunAndReturn(run func(context.Context, int, types.TOTPItem) error) *MockDatabase_UpdateTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateText provides a mock function with given fields: _a0, _a1, _a2
 func (_m *MockDatabase) UpdateText(_a0 context.Context, _a1 int, _a2 types.TextItem) error {
 	ret := _m.Called(_a0, _a1, _a2)
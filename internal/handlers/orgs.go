@@ -0,0 +1,421 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// HandleCreateOrg создаёт организацию. Тело запроса - types.Membership с названием организации
+// и ключом организации, зашифрованным открытым ключом создателя
+func (h *HandlerSet) HandleCreateOrg(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	var org types.Membership
+	err = decodeBody(req, &org)
+	if err != nil || org.Org == "" || org.OrgKey == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.CreateOrg(req.Context(), userID, org.Org, org.OrgKey)
+	if err != nil {
+		var keyExistsError *db.KeyExistsError
+		if errors.As(err, &keyExistsError) {
+			http.Error(w, "Organization already exists", http.StatusConflict)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// HandleListOrgs возвращает организации пользователя, включая неподтверждённые приглашения
+func (h *HandlerSet) HandleListOrgs(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	memberships, err := h.database.ListMemberships(req.Context(), userID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if memberships == nil {
+		memberships = []types.Membership{}
+	}
+	writeJSON(w, memberships)
+}
+
+// HandleDeleteOrg удаляет организацию вместе со всеми записями. Доступно владельцу
+func (h *HandlerSet) HandleDeleteOrg(w http.ResponseWriter, req *http.Request) {
+
+	membership, err := h.handleAuthorizeOrg(w, req, types.RoleOwner)
+	if err != nil {
+		return
+	}
+
+	err = h.database.DeleteOrg(req.Context(), membership.OrgID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+}
+
+// HandleOrgMembers возвращает участников организации с их открытыми ключами. Доступно и приглашённым,
+// чтобы до подтверждения было видно, с кем предстоит делить записи
+func (h *HandlerSet) HandleOrgMembers(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+	membership, err := h.getMembership(w, req, userID)
+	if err != nil {
+		return
+	}
+
+	members, err := h.database.ListMembers(req.Context(), membership.OrgID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	// ключ организации в чужой обёртке другим участникам не нужен
+	for i := range members {
+		members[i].OrgKey = ""
+	}
+	writeJSON(w, members)
+}
+
+// HandleInviteMember приглашает пользователя в организацию. Тело запроса - types.Membership с именем
+// пользователя, ролью и ключом организации, зашифрованным открытым ключом приглашённого
+func (h *HandlerSet) HandleInviteMember(w http.ResponseWriter, req *http.Request) {
+
+	membership, err := h.handleAuthorizeOrg(w, req, types.RoleAdmin)
+	if err != nil {
+		return
+	}
+
+	var invite types.Membership
+	err = decodeBody(req, &invite)
+	if err != nil || invite.Username == "" || !invite.Role.Valid() || invite.OrgKey == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+	if invite.Role == types.RoleOwner && membership.Role != types.RoleOwner {
+		http.Error(w, "Only owner can invite owners", http.StatusForbidden)
+		return
+	}
+
+	err = h.database.AddMember(req.Context(), membership.OrgID, invite.Username, invite.Role, invite.OrgKey)
+	if err != nil {
+		var userNotFound *db.UserNotFoundError
+		if errors.As(err, &userNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		var keyExistsError *db.KeyExistsError
+		if errors.As(err, &keyExistsError) {
+			http.Error(w, "Already a member", http.StatusConflict)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// HandleAcceptInvite подтверждает приглашение в организацию
+func (h *HandlerSet) HandleAcceptInvite(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	membership, err := h.getMembership(w, req, userID)
+	if err != nil {
+		return
+	}
+
+	err = h.database.AcceptInvite(req.Context(), membership.OrgID, userID)
+	if err != nil {
+		h.handleOrgError(w, err)
+		return
+	}
+}
+
+// HandleSetMemberRole меняет роль участника. Доступно владельцу, свою роль владелец поменять не может,
+// чтобы организация не осталась без владельца
+func (h *HandlerSet) HandleSetMemberRole(w http.ResponseWriter, req *http.Request) {
+
+	membership, err := h.handleAuthorizeOrg(w, req, types.RoleOwner)
+	if err != nil {
+		return
+	}
+
+	username := req.PathValue("username")
+	if username == membership.Username {
+		http.Error(w, "Cannot change own role", http.StatusBadRequest)
+		return
+	}
+
+	var change types.Membership
+	err = decodeBody(req, &change)
+	if err != nil || !change.Role.Valid() {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.SetMemberRole(req.Context(), membership.OrgID, username, change.Role)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+}
+
+// HandleRemoveMember исключает участника из организации или отзывает приглашение. Тело запроса - types.KeyRotation:
+// исключённый знает ключ организации, поэтому ключ меняется в той же транзакции. Исключать может администратор,
+// выйти из организации может любой участник, кроме владельца. Без ротации уходит только приглашённый,
+// не подтвердивший членство. Участник, ограниченный частью коллекций, тоже знает ключ, но не видит всех записей
+// и не может их перешифровать, поэтому исключить его может только администратор
+func (h *HandlerSet) HandleRemoveMember(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+	membership, err := h.getMembership(w, req, userID)
+	if err != nil {
+		return
+	}
+
+	username := req.PathValue("username")
+	if username != membership.Username && !(membership.Accepted && membership.Role.AtLeast(types.RoleAdmin)) {
+		http.Error(w, "Insufficient role", http.StatusForbidden)
+		return
+	}
+
+	if username == membership.Username && !membership.Accepted {
+		err = h.database.LeaveOrg(req.Context(), membership.OrgID, userID)
+		if err != nil {
+			h.handleOrgError(w, err)
+		}
+		return
+	}
+	if username == membership.Username && membership.Restricted() {
+		http.Error(w, "Restricted member must be removed by an administrator with key rotation", http.StatusForbidden)
+		return
+	}
+
+	members, err := h.database.ListMembers(req.Context(), membership.OrgID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	for _, m := range members {
+		if m.Username == username && m.Role == types.RoleOwner {
+			http.Error(w, "Owner cannot be removed", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var rotation types.KeyRotation
+	err = decodeBody(req, &rotation)
+	if err != nil {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.RemoveMember(req.Context(), membership.OrgID, username, rotation)
+	if err != nil {
+		h.handleOrgError(w, err)
+		return
+	}
+}
+
+// HandleSetMemberCollections ограничивает доступ участника коллекциями из тела запроса - types.MemberCollections.
+// Пустой список снимает ограничение. Доступно администратору, на владельцев и администраторов ограничение не действует
+func (h *HandlerSet) HandleSetMemberCollections(w http.ResponseWriter, req *http.Request) {
+
+	membership, err := h.handleAuthorizeOrg(w, req, types.RoleAdmin)
+	if err != nil {
+		return
+	}
+
+	var change types.MemberCollections
+	err = decodeBody(req, &change)
+	if err != nil || slices.Contains(change.Collections, "") {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.SetMemberCollections(req.Context(), membership.OrgID, req.PathValue("username"), change.Collections)
+	if err != nil {
+		h.handleOrgError(w, err)
+		return
+	}
+}
+
+// HandleOrgItems возвращает записи организации, параметр collection ограничивает выборку одной коллекцией
+func (h *HandlerSet) HandleOrgItems(w http.ResponseWriter, req *http.Request) {
+
+	membership, err := h.handleAuthorizeOrg(w, req, types.RoleReadOnly)
+	if err != nil {
+		return
+	}
+
+	items, err := h.database.ListOrgItems(req.Context(), membership.OrgID, req.URL.Query().Get("collection"), membership.AllowedCollections())
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []types.OrgItem{}
+	}
+	writeJSON(w, items)
+}
+
+// HandleGetOrgItem возвращает запись организации по ключу
+func (h *HandlerSet) HandleGetOrgItem(w http.ResponseWriter, req *http.Request) {
+
+	membership, err := h.handleAuthorizeOrg(w, req, types.RoleReadOnly)
+	if err != nil {
+		return
+	}
+
+	item, err := h.database.GetOrgItem(req.Context(), membership.OrgID, req.PathValue("key"), membership.AllowedCollections())
+	if err != nil {
+		h.handleOrgError(w, err)
+		return
+	}
+	writeJSON(w, item)
+}
+
+// HandlePutOrgItem сохраняет или заменяет запись организации. Доступно участникам с правом записи
+func (h *HandlerSet) HandlePutOrgItem(w http.ResponseWriter, req *http.Request) {
+
+	membership, err := h.handleAuthorizeOrg(w, req, types.RoleMember)
+	if err != nil {
+		return
+	}
+
+	var item types.OrgItem
+	err = decodeBody(req, &item)
+	if err != nil || item.Key == "" || item.Data == "" || item.Type == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	if !membership.CanAccess(item.Collection) {
+		http.Error(w, "No access to collection", http.StatusForbidden)
+		return
+	}
+
+	err = h.database.PutOrgItem(req.Context(), membership.OrgID, item, membership.AllowedCollections())
+	if err != nil {
+		h.handleOrgError(w, err)
+		return
+	}
+}
+
+// HandleDeleteOrgItem удаляет запись организации. Доступно участникам с правом записи
+func (h *HandlerSet) HandleDeleteOrgItem(w http.ResponseWriter, req *http.Request) {
+
+	membership, err := h.handleAuthorizeOrg(w, req, types.RoleMember)
+	if err != nil {
+		return
+	}
+
+	err = h.database.DeleteOrgItem(req.Context(), membership.OrgID, req.PathValue("key"), membership.AllowedCollections())
+	if err != nil {
+		h.handleOrgError(w, err)
+		return
+	}
+}
+
+// handleAuthorizeOrg проверяет, что пользователь подтвердил членство в организации из пути запроса
+// и его роль не ниже min. Чужая организация выглядит как несуществующая
+func (h *HandlerSet) handleAuthorizeOrg(w http.ResponseWriter, req *http.Request, min types.Role) (*types.Membership, error) {
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return nil, err
+	}
+	membership, err := h.getMembership(w, req, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !membership.Accepted {
+		http.Error(w, "Invite not accepted", http.StatusForbidden)
+		return nil, fmt.Errorf("invite not accepted")
+	}
+	if !membership.Role.AtLeast(min) {
+		http.Error(w, "Insufficient role", http.StatusForbidden)
+		return nil, fmt.Errorf("insufficient role")
+	}
+	return membership, nil
+}
+
+func (h *HandlerSet) getMembership(w http.ResponseWriter, req *http.Request, userID int) (*types.Membership, error) {
+	membership, err := h.database.GetMembership(req.Context(), userID, req.PathValue("org"))
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Organization not found", http.StatusNotFound)
+			return nil, err
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return nil, err
+	}
+	return membership, nil
+}
+
+func (h *HandlerSet) handleOrgError(w http.ResponseWriter, err error) {
+	var keyNotFound *db.KeyNotFoundError
+	if errors.As(err, &keyNotFound) {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	var permissionDenied *db.PermissionDeniedError
+	if errors.As(err, &permissionDenied) {
+		http.Error(w, "No access to collection", http.StatusForbidden)
+		return
+	}
+	var keyVersion *db.KeyVersionError
+	if errors.As(err, &keyVersion) {
+		http.Error(w, "Organization key has been rotated, reload it", http.StatusConflict)
+		return
+	}
+	var incomplete *db.IncompleteRotationError
+	if errors.As(err, &incomplete) {
+		http.Error(w, incomplete.Error(), http.StatusBadRequest)
+		return
+	}
+	fmt.Println(err.Error())
+	http.Error(w, "Something went wrong", http.StatusInternalServerError)
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+	"gotest.tools/assert"
+)
+
+func TestHandlerSet_HandlePutOrgItem(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		membership         *types.Membership
+		membershipErr      error
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"member", &types.Membership{OrgID: 7, Role: types.RoleMember, Accepted: true}, nil, nil, http.StatusOK},
+		{"readonly", &types.Membership{OrgID: 7, Role: types.RoleReadOnly, Accepted: true}, nil, nil, http.StatusForbidden},
+		{"invited", &types.Membership{OrgID: 7, Role: types.RoleAdmin}, nil, nil, http.StatusForbidden},
+		{"notMember", nil, &db.KeyNotFoundError{Key: "team"}, nil, http.StatusNotFound},
+		{"staleKey", &types.Membership{OrgID: 7, Role: types.RoleOwner, Accepted: true}, nil, &db.KeyVersionError{Current: 2, Got: 1}, http.StatusConflict},
+		{"otherCollection", &types.Membership{OrgID: 7, Role: types.RoleMember, Accepted: true, Collections: []string{"ops"}}, nil, nil, http.StatusForbidden},
+		{"replacesOtherCollection", &types.Membership{OrgID: 7, Role: types.RoleMember, Accepted: true}, nil, &db.PermissionDeniedError{Key: "db"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPut, "/api/org/team/items", []byte(`{"key": "db", "type": "text", "data": "enc", "key_version": 1}`))
+			req.SetPathValue("org", "team")
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().GetMembership(req.Context(), 1, "team").Return(tt.membership, tt.membershipErr)
+			mdb.EXPECT().PutOrgItem(req.Context(), 7, types.OrgItem{Key: "db", Type: types.TypeText, Data: "enc", KeyVersion: 1}, mock.Anything).Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandlePutOrgItem(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleInviteMember(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		role               types.Role
+		body               string
+		expectedStatusCode int
+	}{
+		{"adminInvitesMember", types.RoleAdmin, `{"username": "bob", "role": "member", "org_key": "wrapped"}`, http.StatusCreated},
+		{"adminInvitesOwner", types.RoleAdmin, `{"username": "bob", "role": "owner", "org_key": "wrapped"}`, http.StatusForbidden},
+		{"ownerInvitesOwner", types.RoleOwner, `{"username": "bob", "role": "owner", "org_key": "wrapped"}`, http.StatusCreated},
+		{"memberInvites", types.RoleMember, `{"username": "bob", "role": "member", "org_key": "wrapped"}`, http.StatusForbidden},
+		{"badRole", types.RoleOwner, `{"username": "bob", "role": "boss", "org_key": "wrapped"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPost, "/api/org/team/members", []byte(tt.body))
+			req.SetPathValue("org", "team")
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().GetMembership(req.Context(), 1, "team").Return(&types.Membership{OrgID: 7, Role: tt.role, Accepted: true}, nil)
+			mdb.EXPECT().AddMember(req.Context(), 7, "bob", mock.Anything, "wrapped").Return(nil)
+
+			w := httptest.NewRecorder()
+			h.HandleInviteMember(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleRemoveMember(t *testing.T) {
+
+	members := []types.Membership{
+		{Username: "user", Role: types.RoleMember},
+		{Username: "boss", Role: types.RoleOwner},
+		{Username: "bob", Role: types.RoleMember},
+	}
+	tests := []struct {
+		name               string
+		membership         types.Membership
+		target             string
+		dbErr              error
+		expectedStatusCode int
+		withoutRotation    bool
+	}{
+		{"leave", types.Membership{Role: types.RoleMember, Accepted: true}, "user", nil, http.StatusOK, false},
+		{"memberRemovesOther", types.Membership{Role: types.RoleMember, Accepted: true}, "bob", nil, http.StatusForbidden, false},
+		{"adminRemovesMember", types.Membership{Role: types.RoleAdmin, Accepted: true}, "bob", nil, http.StatusOK, false},
+		{"adminRemovesOwner", types.Membership{Role: types.RoleAdmin, Accepted: true}, "boss", nil, http.StatusBadRequest, false},
+		{"incompleteRotation", types.Membership{Role: types.RoleAdmin, Accepted: true}, "bob", &db.IncompleteRotationError{Reason: "keys"}, http.StatusBadRequest, false},
+		{"staleKey", types.Membership{Role: types.RoleAdmin, Accepted: true}, "bob", &db.KeyVersionError{Current: 3, Got: 2}, http.StatusConflict, false},
+		{"declineInvite", types.Membership{Role: types.RoleMember}, "user", nil, http.StatusOK, true},
+		{"acceptedMeanwhile", types.Membership{Role: types.RoleMember}, "user", &db.IncompleteRotationError{Reason: "rotate"}, http.StatusBadRequest, true},
+		{"restrictedLeaves", types.Membership{Role: types.RoleMember, Accepted: true, Collections: []string{"ops"}}, "user", nil, http.StatusForbidden, true},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodDelete, "/api/org/team/members/"+tt.target,
+				[]byte(`{"key_version": 2, "keys": {"user": "k1", "boss": "k2"}, "items": []}`))
+			req.SetPathValue("org", "team")
+			req.SetPathValue("username", tt.target)
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			membership := tt.membership
+			membership.OrgID, membership.Username = 7, "user"
+			mdb.EXPECT().GetMembership(req.Context(), 1, "team").Return(&membership, nil)
+			mdb.EXPECT().ListMembers(req.Context(), 7).Return(members, nil)
+			if tt.withoutRotation {
+				mdb.EXPECT().LeaveOrg(req.Context(), 7, 1).Return(tt.dbErr)
+			} else {
+				mdb.EXPECT().RemoveMember(req.Context(), 7, tt.target, types.KeyRotation{
+					KeyVersion: 2,
+					Keys:       map[string]string{"user": "k1", "boss": "k2"},
+					Items:      []types.OrgItem{},
+				}).Return(tt.dbErr)
+			}
+
+			w := httptest.NewRecorder()
+			h.HandleRemoveMember(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.withoutRotation {
+				mdb.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandlerSet_HandleOrgMembers(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		membership         *types.Membership
+		membershipErr      error
+		expectedStatusCode int
+	}{
+		{"member", &types.Membership{OrgID: 7, Role: types.RoleReadOnly, Accepted: true}, nil, http.StatusOK},
+		{"invited", &types.Membership{OrgID: 7, Role: types.RoleMember}, nil, http.StatusOK},
+		{"notMember", nil, &db.KeyNotFoundError{Key: "team"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodGet, "/api/org/team/members", nil)
+			req.SetPathValue("org", "team")
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().GetMembership(req.Context(), 1, "team").Return(tt.membership, tt.membershipErr)
+			mdb.EXPECT().ListMembers(req.Context(), 7).Return([]types.Membership{{Username: "boss", Role: types.RoleOwner, OrgKey: "wrapped"}}, nil)
+
+			w := httptest.NewRecorder()
+			h.HandleOrgMembers(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, `[{"org":"","username":"boss","role":"owner","accepted":false,"org_key":"","key_version":0}]`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerSet_HandleAcceptInvite(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", nil, http.StatusOK},
+		{"inviteGone", &db.KeyNotFoundError{Key: "7"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPost, "/api/org/team/accept", nil)
+			req.SetPathValue("org", "team")
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().GetMembership(req.Context(), 1, "team").Return(&types.Membership{OrgID: 7, Role: types.RoleMember}, nil)
+			mdb.EXPECT().AcceptInvite(req.Context(), 7, 1).Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleAcceptInvite(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleSetMemberCollections(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		role               types.Role
+		body               string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", types.RoleAdmin, `{"collections": ["ops", "dev"]}`, nil, http.StatusOK},
+		{"member", types.RoleMember, `{"collections": ["ops"]}`, nil, http.StatusForbidden},
+		{"emptyName", types.RoleAdmin, `{"collections": [""]}`, nil, http.StatusBadRequest},
+		{"notMember", types.RoleAdmin, `{"collections": ["ops"]}`, &db.KeyNotFoundError{Key: "bob"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPut, "/api/org/team/members/bob/collections", []byte(tt.body))
+			req.SetPathValue("org", "team")
+			req.SetPathValue("username", "bob")
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().GetMembership(req.Context(), 1, "team").Return(&types.Membership{OrgID: 7, Role: tt.role, Accepted: true}, nil)
+			mdb.EXPECT().SetMemberCollections(req.Context(), 7, "bob", []string{"ops", "dev"}).Return(tt.dbErr)
+			mdb.EXPECT().SetMemberCollections(req.Context(), 7, "bob", []string{"ops"}).Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleSetMemberCollections(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// HandleGetVaultKey возвращает ключ хранилища пользователя, зашифрованный его паролем
+func (h *HandlerSet) HandleGetVaultKey(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	key, err := h.database.GetVaultKey(req.Context(), userID)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Vault key not set", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, key)
+}
+
+// HandleMigrateVaultKey заменяет старый ключ хранилища, которым был пароль, случайным. Тело запроса -
+// types.VaultKeyMigration. Записи после этого перешифровывает клиент
+func (h *HandlerSet) HandleMigrateVaultKey(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	var migration types.VaultKeyMigration
+	err = decodeBody(req, &migration)
+	if err != nil || migration.Wrapped == "" || migration.LegacyKey == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.MigrateVaultKey(req.Context(), userID, migration)
+	if err != nil {
+		var keyExists *db.KeyExistsError
+		if errors.As(err, &keyExists) {
+			http.Error(w, "Vault key changed, unlock again", http.StatusConflict)
+			return
+		}
+		var incomplete *db.IncompleteRotationError
+		if errors.As(err, &incomplete) {
+			http.Error(w, incomplete.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	h.recordAudit(req, userID, types.AuditVaultKeyMigrate, "")
+}
+
+// HandleLegacyVault возвращает записи и вложения, ещё зашифрованные старым ключом хранилища,
+// и карты с открытым сроком действия
+func (h *HandlerSet) HandleLegacyVault(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	legacy, err := h.database.GetLegacyVault(req.Context(), userID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, legacy)
+}
+
+// HandleFinishVaultKeyMigration удаляет старый ключ хранилища, когда все записи перешифрованы
+func (h *HandlerSet) HandleFinishVaultKeyMigration(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	err = h.database.FinishVaultKeyMigration(req.Context(), userID)
+	if err != nil {
+		var incomplete *db.IncompleteRotationError
+		if errors.As(err, &incomplete) {
+			http.Error(w, incomplete.Error(), http.StatusConflict)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+}
+
+// HandleSetVaultKey сохраняет ключ хранилища пользователя, зашифрованный его паролем. Тело запроса - types.VaultKey.
+// Ключ задаётся один раз, повторная попытка получает 409
+func (h *HandlerSet) HandleSetVaultKey(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	var key types.VaultKey
+	err = decodeBody(req, &key)
+	if err != nil || key.Wrapped == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.SetVaultKey(req.Context(), userID, key.Wrapped)
+	if err != nil {
+		var keyExistsError *db.KeyExistsError
+		if errors.As(err, &keyExistsError) {
+			http.Error(w, "Vault key already set", http.StatusConflict)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+}
+
+// HandleSetRecovery включает восстановление доступа по ключу восстановления. Тело запроса - types.RecoveryVerifier,
+// на сервере сохраняется только хеш проверочного значения
+func (h *HandlerSet) HandleSetRecovery(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	var verifier types.RecoveryVerifier
+	err = decodeBody(req, &verifier)
+	if err != nil || verifier.Verifier == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	hashed, err := auth.HashPassword(verifier.Verifier)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	err = h.database.SetRecoveryHash(req.Context(), userID, hashed)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Vault key not set", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+}
+
+// HandleRecoverAccount задаёт новый пароль тому, кто знает ключ восстановления. Доступно без авторизации,
+// в случае успеха возвращает токен, как при входе. После нескольких неверных ключей подряд восстановление
+// временно блокируется
+func (h *HandlerSet) HandleRecoverAccount(w http.ResponseWriter, req *http.Request) {
+
+	var recovery types.AccountRecovery
+	err := decodeBody(req, &recovery)
+	if err != nil || recovery.Login == "" || recovery.Verifier == "" || recovery.Password == "" || recovery.Wrapped == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+	recovery.Login = h.canonicalUsername(req.Context(), recovery.Login)
+	if !h.checkPassword(w, recovery.Password, recovery.Login) {
+		return
+	}
+
+	hashed, err := auth.HashPassword(recovery.Password)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	err = h.database.RecoverAccount(req.Context(), recovery.Login, func(recoveryHash string) bool {
+		return auth.CheckPasswordHash(recovery.Verifier, recoveryHash)
+	}, hashed, recovery.Wrapped)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Recovery is not set up for this user", http.StatusNotFound)
+			return
+		}
+		var permissionDenied *db.PermissionDeniedError
+		if errors.As(err, &permissionDenied) {
+			h.recordAccountAudit(req, recovery.Login, types.AuditRecoverFailed)
+			http.Error(w, "Wrong recovery key", http.StatusUnauthorized)
+			return
+		}
+		var recoveryLocked *db.RecoveryLockedError
+		if errors.As(err, &recoveryLocked) {
+			h.recordAccountAudit(req, recovery.Login, types.AuditRecoverFailed)
+			http.Error(w, "Too many attempts, try again later", http.StatusTooManyRequests)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	h.recordAccountAudit(req, recovery.Login, types.AuditRecover)
+
+	started, err := h.startSession(w, req, recovery.Login, true)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+		return
+	}
+	if !started {
+		return
+	}
+
+	w.Header().Set("content-type", "text/plain")
+
+	_, err = w.Write([]byte("success"))
+	if err != nil {
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+	"gotest.tools/assert"
+)
+
+func TestHandlerSet_HandleRecoverAccount(t *testing.T) {
+
+	hash, err := auth.HashPassword("verifier")
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name               string
+		body               string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", `{"login": "user", "verifier": "verifier", "password": "new", "wrapped": "enc"}`, nil, http.StatusOK},
+		{"wrongVerifier", `{"login": "user", "verifier": "guess", "password": "new", "wrapped": "enc"}`, nil, http.StatusUnauthorized},
+		{"notSetUp", `{"login": "user", "verifier": "verifier", "password": "new", "wrapped": "enc"}`, &db.KeyNotFoundError{Key: "user"}, http.StatusNotFound},
+		{"locked", `{"login": "user", "verifier": "verifier", "password": "new", "wrapped": "enc"}`, &db.RecoveryLockedError{Username: "user"}, http.StatusTooManyRequests},
+		{"noWrapped", `{"login": "user", "verifier": "verifier", "password": "new"}`, nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req, _ := http.NewRequest(http.MethodPost, "/api/user/recover", strings.NewReader(tt.body))
+
+			mdb.EXPECT().RecoverAccount(req.Context(), "user", mock.Anything, mock.Anything, "enc").RunAndReturn(
+				func(_ context.Context, _ string, authorize func(string) bool, passwordHash string, _ string) error {
+					if tt.dbErr != nil {
+						return tt.dbErr
+					}
+					if !authorize(hash) {
+						return &db.PermissionDeniedError{Key: "user"}
+					}
+					assert.Assert(t, auth.CheckPasswordHash("new", passwordHash))
+					return nil
+				})
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().LoginDevice(req.Context(), 1, types.NewDevice{}, true).Return(types.DeviceLogin{Status: types.DeviceApproved}, nil)
+			mdb.EXPECT().CreateSession(req.Context(), 1, types.NewSession{}).Return(5, nil)
+
+			w := httptest.NewRecorder()
+			h.HandleRecoverAccount(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if w.Code == http.StatusOK {
+				assert.Equal(t, token, w.Header().Get("X-Auth-Token"))
+			}
+		})
+	}
+}
+
+func TestHandlerSet_HandleSetVaultKey(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		body               string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", `{"wrapped": "salt.sealed"}`, nil, http.StatusOK},
+		{"empty", `{}`, nil, http.StatusBadRequest},
+		{"exists", `{"wrapped": "salt.sealed"}`, &db.KeyExistsError{Key: "vault key"}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPut, "/api/user/vault_key", []byte(tt.body))
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().SetVaultKey(req.Context(), 1, "salt.sealed").Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleSetVaultKey(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleMigrateVaultKey(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		body               string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", `{"previous": "old", "wrapped": "new", "legacy_key": "legacy"}`, nil, http.StatusOK},
+		{"changed", `{"previous": "old", "wrapped": "new", "legacy_key": "legacy"}`, &db.KeyExistsError{Key: "vault key"}, http.StatusConflict},
+		{"incomplete", `{"previous": "old", "wrapped": "new", "legacy_key": "legacy"}`, &db.IncompleteRotationError{Reason: "private key must be re-encrypted"}, http.StatusBadRequest},
+		{"noLegacyKey", `{"previous": "old", "wrapped": "new"}`, nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPost, "/api/user/vault_key/migrate", []byte(tt.body))
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().MigrateVaultKey(req.Context(), 1, types.VaultKeyMigration{Previous: "old", Wrapped: "new", LegacyKey: "legacy"}).Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleMigrateVaultKey(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
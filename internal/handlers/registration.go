@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// Registration правила регистрации новых пользователей
+type Registration struct {
+	// Mode кто может регистрироваться, пустое значение - types.RegistrationOpen
+	Mode types.RegistrationMode
+	// Password требования к паролю, они же действуют при восстановлении доступа
+	Password auth.PasswordPolicy
+}
+
+// authData логин и пароль из запроса на вход или регистрацию. Invite - код приглашения при регистрации
+type authData struct {
+	Username string `json:"login"`
+	Password string `json:"password"`
+	Invite   string `json:"invite"`
+}
+
+// SetRegistration задаёт правила регистрации
+func (h *HandlerSet) SetRegistration(registration Registration) {
+	h.registration = registration
+}
+
+// checkRegistration проверяет, можно ли зарегистрироваться с такими данными, и возвращает канонический логин.
+// Если нельзя, пишет в ответ понятную пользователю причину
+func (h *HandlerSet) checkRegistration(w http.ResponseWriter, data authData) (string, bool) {
+	switch h.registration.Mode {
+	case types.RegistrationClosed:
+		http.Error(w, "Registration is closed", http.StatusForbidden)
+		return "", false
+	case types.RegistrationInvite:
+		if data.Invite == "" {
+			http.Error(w, types.InviteRequired, http.StatusForbidden)
+			return "", false
+		}
+	}
+
+	username, err := auth.NormalizeUsername(data.Username)
+	if err != nil {
+		http.Error(w, "Invalid login: "+err.Error(), http.StatusBadRequest)
+		return "", false
+	}
+	if !h.checkPassword(w, data.Password, username) {
+		return "", false
+	}
+	return username, true
+}
+
+// checkPassword проверяет пароль по требованиям сервера, нарушение - 400 с объяснением
+func (h *HandlerSet) checkPassword(w http.ResponseWriter, password string, username string) bool {
+	err := h.registration.Password.Check(password, username)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// canonicalUsername логин, под которым пользователь хранится в БД. Новые логины хранятся в каноническом виде,
+// а заведённые до появления правил - как их ввели при регистрации
+func (h *HandlerSet) canonicalUsername(ctx context.Context, username string) string {
+	normalized, err := auth.NormalizeUsername(username)
+	if err != nil || normalized == username {
+		return username
+	}
+	// сначала точное совпадение: иначе учётную запись "Alice" подменял бы другой пользователь "alice"
+	_, err = h.database.GetUserID(ctx, username)
+	if err == nil {
+		return username
+	}
+	return normalized
+}
+
+// HandleAdminCreateInvite создаёт приглашение. Код возвращается один раз, на сервере хранится только его хеш
+func (h *HandlerSet) HandleAdminCreateInvite(w http.ResponseWriter, req *http.Request) {
+
+	var invite types.NewInvite
+	err := decodeBody(req, &invite)
+	if err != nil {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+	if invite.MaxUses == 0 {
+		invite.MaxUses = 1
+	}
+	ttl := types.DefaultInviteTTL
+	if invite.ExpiresIn != "" {
+		ttl, err = time.ParseDuration(invite.ExpiresIn)
+	}
+	if err != nil || ttl <= 0 || invite.MaxUses < 0 {
+		http.Error(w, "Wrong max uses or expiry", http.StatusBadRequest)
+		return
+	}
+
+	code, err := newSecret()
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	expiresAt := time.Now().Add(ttl)
+	id, err := h.database.CreateInvite(req.Context(), hashSecret(code), invite.MaxUses, expiresAt, invite.Note)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, types.CreatedInvite{ID: id, Code: code, ExpiresAt: expiresAt})
+}
+
+// HandleAdminInvites действующие приглашения, без кодов
+func (h *HandlerSet) HandleAdminInvites(w http.ResponseWriter, req *http.Request) {
+
+	invites, err := h.database.ListInvites(req.Context())
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, invites)
+}
+
+// HandleAdminDeleteInvite отзывает приглашение
+func (h *HandlerSet) HandleAdminDeleteInvite(w http.ResponseWriter, req *http.Request) {
+
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Wrong id", http.StatusBadRequest)
+		return
+	}
+	err = h.database.DeleteInvite(req.Context(), id)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Invite not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+	}
+}
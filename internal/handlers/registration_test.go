@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+	"gotest.tools/assert"
+)
+
+func TestHandlerSet_HandleRegisterUser_Rules(t *testing.T) {
+	policy := auth.PasswordPolicy{MinLength: 10, MinScore: 3}
+	strong := "correct horse battery staple"
+	tests := []struct {
+		name               string
+		mode               types.RegistrationMode
+		body               string
+		inviteErr          error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{"closed", types.RegistrationClosed, `{"login": "alice", "password": "` + strong + `"}`, nil,
+			http.StatusForbidden, "Registration is closed\n"},
+		{"invite required", types.RegistrationInvite, `{"login": "alice", "password": "` + strong + `"}`, nil,
+			http.StatusForbidden, types.InviteRequired + "\n"},
+		{"invite", types.RegistrationInvite, `{"login": "Alice", "password": "` + strong + `", "invite": "code"}`, nil,
+			http.StatusOK, "success"},
+		{"invite used up", types.RegistrationInvite, `{"login": "alice", "password": "` + strong + `", "invite": "code"}`,
+			&db.InviteInvalidError{}, http.StatusForbidden, "Invite is invalid, expired or used up\n"},
+		{"bad login", types.RegistrationOpen, `{"login": "alice smith", "password": "` + strong + `"}`, nil,
+			http.StatusBadRequest, "Invalid login: " + auth.ErrUsernameInvalid.Error() + "\n"},
+		{"short password", types.RegistrationOpen, `{"login": "alice", "password": "x7#Kq"}`, nil,
+			http.StatusBadRequest, "Password is too short: at least 10 characters\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := &MockDatabase{}
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			h.SetRegistration(Registration{Mode: tt.mode, Password: policy})
+			req, _ := http.NewRequest(http.MethodPost, "/api/user/register", bytes.NewBufferString(tt.body))
+
+			mdb.EXPECT().CreateUserWithInvite(req.Context(), "alice", mock.Anything, hashSecret("code")).Return(tt.inviteErr)
+			mdb.EXPECT().GetUserID(req.Context(), "alice").Return(1, nil)
+			mdb.EXPECT().LoginDevice(req.Context(), 1, types.NewDevice{}, false).Return(types.DeviceLogin{Status: types.DeviceApproved}, nil)
+			mdb.EXPECT().CreateSession(req.Context(), 1, types.NewSession{}).Return(5, nil)
+
+			w := httptest.NewRecorder()
+			h.HandleRegisterUser(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mdb.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestHandlerSet_HandleLogin_LegacyUsername(t *testing.T) {
+	hash, _ := auth.HashPassword("pass")
+	tests := []struct {
+		name     string
+		login    string
+		existing string
+	}{
+		{"normalized", " Alice ", "alice"},
+		{"normalized without legacy", "Alice", "alice"},
+		// "Alice" заведена до правил: её логин не подменяется каноническим логином другого пользователя
+		{"legacy", "Alice", "Alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := &MockDatabase{}
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			body := `{"login": "` + tt.login + `", "password": "pass"}`
+			req, _ := http.NewRequest(http.MethodPost, "/api/user/login", bytes.NewBufferString(body))
+
+			if tt.existing != tt.login {
+				mdb.EXPECT().GetUserID(req.Context(), tt.login).Return(0, &db.UserNotFoundError{Username: tt.login})
+			}
+			mdb.EXPECT().GetUserID(req.Context(), tt.existing).Return(1, nil)
+			mdb.EXPECT().GetUserHashedPassword(req.Context(), tt.existing).Return(hash, nil)
+			mdb.EXPECT().LoginDevice(req.Context(), 1, types.NewDevice{}, false).Return(types.DeviceLogin{Status: types.DeviceApproved}, nil)
+			mdb.EXPECT().CreateSession(req.Context(), 1, types.NewSession{}).Return(5, nil)
+
+			w := httptest.NewRecorder()
+			h.HandleLogin(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleAdminCreateInvite(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		maxUses            int
+		expectedStatusCode int
+	}{
+		{"default", `{}`, 1, http.StatusOK},
+		{"uses and expiry", `{"max_uses": 5, "expires_in": "24h", "note": "team"}`, 5, http.StatusOK},
+		{"bad expiry", `{"expires_in": "tomorrow"}`, 1, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := &MockDatabase{}
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req, _ := http.NewRequest(http.MethodPost, "/api/admin/invites", bytes.NewBufferString(tt.body))
+
+			var codeHash string
+			mdb.EXPECT().CreateInvite(req.Context(), mock.Anything, tt.maxUses, mock.Anything, mock.Anything).
+				Run(func(_ context.Context, hash string, _ int, _ time.Time, _ string) { codeHash = hash }).Return(7, nil)
+
+			w := httptest.NewRecorder()
+			h.HandleAdminCreateInvite(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+			var created types.CreatedInvite
+			assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &created))
+			assert.Equal(t, 7, created.ID)
+			// на сервере остаётся только хеш кода
+			assert.Equal(t, hashSecret(created.Code), codeHash)
+		})
+	}
+}
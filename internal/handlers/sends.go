@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+const (
+	// MaxSendViews наибольшее число просмотров одноразовой ссылки
+	MaxSendViews = 100
+	// MaxSendTTL наибольший срок жизни одноразовой ссылки
+	MaxSendTTL = 30 * 24 * time.Hour
+	// MaxSendSize наибольший размер зашифрованного содержимого одноразовой ссылки
+	MaxSendSize = 256 << 10
+	sendIDBytes = 16
+)
+
+// sendPage страница для открытия одноразовой ссылки в браузере: ключ берётся из фрагмента ссылки,
+// содержимое расшифровывается через WebCrypto и на сервер не попадает
+//
+//go:embed send.html
+var sendPage []byte
+
+// HandleCreateSend создаёт одноразовую ссылку. Тело запроса - types.NewSend, в ответ возвращается types.Send
+func (h *HandlerSet) HandleCreateSend(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	var send types.NewSend
+	err = decodeBody(req, &send)
+	if err != nil || send.Data == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+	if len(send.Data) > MaxSendSize {
+		http.Error(w, "Data too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if send.MaxViews < 1 || send.MaxViews > MaxSendViews {
+		http.Error(w, fmt.Sprintf("max_views must be between 1 and %d", MaxSendViews), http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	if !send.ExpiresAt.After(now) || send.ExpiresAt.After(now.Add(MaxSendTTL)) {
+		http.Error(w, fmt.Sprintf("expires_at must be in the future and within %s", MaxSendTTL), http.StatusBadRequest)
+		return
+	}
+
+	var passwordHash *string
+	if send.Password != "" {
+		hashed, err := auth.HashPassword(send.Password)
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "Something went wrong", http.StatusInternalServerError)
+			return
+		}
+		passwordHash = &hashed
+	}
+
+	id, err := newSendID()
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	err = h.database.CreateSend(req.Context(), userID, id, send, passwordHash)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(types.Send{ID: id, MaxViews: send.MaxViews, ExpiresAt: send.ExpiresAt, HasPassword: passwordHash != nil})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+// HandleListSends возвращает действующие одноразовые ссылки пользователя
+func (h *HandlerSet) HandleListSends(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	sends, err := h.database.ListSends(req.Context(), userID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if sends == nil {
+		sends = []types.Send{}
+	}
+	writeJSON(w, sends)
+}
+
+// HandleDeleteSend удаляет одноразовую ссылку досрочно
+func (h *HandlerSet) HandleDeleteSend(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	err = h.database.DeleteSend(req.Context(), userID, req.PathValue("id"))
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+}
+
+// HandleSendInfo возвращает сведения об одноразовой ссылке без содержимого: нужен ли пароль и сколько осталось
+// просмотров. Доступно без авторизации, просмотр не засчитывается
+func (h *HandlerSet) HandleSendInfo(w http.ResponseWriter, req *http.Request) {
+
+	send, err := h.database.GetSend(req.Context(), req.PathValue("id"))
+	if err != nil {
+		h.handleSendError(w, err)
+		return
+	}
+	writeJSON(w, send)
+}
+
+// HandleOpenSend засчитывает просмотр и возвращает зашифрованное содержимое одноразовой ссылки.
+// Доступно без авторизации. Используется POST, чтобы превью ссылок в мессенджерах не тратили просмотры
+func (h *HandlerSet) HandleOpenSend(w http.ResponseWriter, req *http.Request) {
+
+	var access types.SendAccess
+	err := decodeBody(req, &access)
+	if err != nil {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	content, err := h.database.OpenSend(req.Context(), req.PathValue("id"), func(passwordHash *string) bool {
+		return passwordHash == nil || auth.CheckPasswordHash(access.Password, *passwordHash)
+	})
+	if err != nil {
+		h.handleSendError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, content)
+}
+
+// HandleSendPage отдаёт страницу для открытия одноразовой ссылки в браузере
+func (h *HandlerSet) HandleSendPage(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'")
+	_, err := w.Write(sendPage)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+func (h *HandlerSet) handleSendError(w http.ResponseWriter, err error) {
+	var keyNotFound *db.KeyNotFoundError
+	if errors.As(err, &keyNotFound) {
+		http.Error(w, "Send not found or expired", http.StatusNotFound)
+		return
+	}
+	var permissionDenied *db.PermissionDeniedError
+	if errors.As(err, &permissionDenied) {
+		http.Error(w, "Wrong password", http.StatusUnauthorized)
+		return
+	}
+	fmt.Println(err.Error())
+	http.Error(w, "Something went wrong", http.StatusInternalServerError)
+}
+
+func newSendID() (string, error) {
+	id := make([]byte, sendIDBytes)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(id), nil
+}
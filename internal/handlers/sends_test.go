@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+	"gotest.tools/assert"
+)
+
+func TestHandlerSet_HandleCreateSend(t *testing.T) {
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	tooFar := time.Now().Add(MaxSendTTL + time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name               string
+		body               string
+		expectedStatusCode int
+	}{
+		{"ok", fmt.Sprintf(`{"data": "enc", "max_views": 1, "expires_at": %q}`, future), http.StatusCreated},
+		{"noData", fmt.Sprintf(`{"max_views": 1, "expires_at": %q}`, future), http.StatusBadRequest},
+		{"noViews", fmt.Sprintf(`{"data": "enc", "max_views": 0, "expires_at": %q}`, future), http.StatusBadRequest},
+		{"tooManyViews", fmt.Sprintf(`{"data": "enc", "max_views": 1000, "expires_at": %q}`, future), http.StatusBadRequest},
+		{"expired", fmt.Sprintf(`{"data": "enc", "max_views": 1, "expires_at": %q}`, past), http.StatusBadRequest},
+		{"tooLong", fmt.Sprintf(`{"data": "enc", "max_views": 1, "expires_at": %q}`, tooFar), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPost, "/api/send", []byte(tt.body))
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().CreateSend(req.Context(), 1, mock.Anything, mock.Anything, (*string)(nil)).Return(nil)
+
+			w := httptest.NewRecorder()
+			h.HandleCreateSend(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if w.Code != http.StatusCreated {
+				return
+			}
+			var send types.Send
+			assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &send))
+			assert.Equal(t, 22, len(send.ID))
+			assert.Equal(t, 1, send.MaxViews)
+		})
+	}
+}
+
+func TestHandlerSet_HandleOpenSend(t *testing.T) {
+
+	hash, err := auth.HashPassword("letmein")
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name               string
+		passwordHash       *string
+		body               string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"noPassword", nil, `{}`, nil, http.StatusOK},
+		{"rightPassword", &hash, `{"password": "letmein"}`, nil, http.StatusOK},
+		{"wrongPassword", &hash, `{"password": "guess"}`, nil, http.StatusUnauthorized},
+		{"burned", nil, `{}`, &db.KeyNotFoundError{Key: "abc"}, http.StatusNotFound},
+		{"badBody", nil, `not json`, nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req, _ := http.NewRequest(http.MethodPost, "/api/send/abc/open", strings.NewReader(tt.body))
+			req.SetPathValue("id", "abc")
+
+			mdb.EXPECT().OpenSend(req.Context(), "abc", mock.Anything).RunAndReturn(
+				func(_ context.Context, _ string, authorize func(*string) bool) (*types.SendContent, error) {
+					if tt.dbErr != nil {
+						return nil, tt.dbErr
+					}
+					if !authorize(tt.passwordHash) {
+						return nil, &db.PermissionDeniedError{Key: "abc"}
+					}
+					return &types.SendContent{Data: "enc"}, nil
+				})
+
+			w := httptest.NewRecorder()
+			h.HandleOpenSend(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if w.Code == http.StatusOK {
+				assert.Equal(t, `{"data":"enc","views_left":0}`, w.Body.String())
+				assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
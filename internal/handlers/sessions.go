@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+const (
+	DeviceHeader        = "X-Device-Name"
+	ClientVersionHeader = "X-Client-Version"
+	DeviceKeyHeader     = "X-Device-Key"
+	// DeviceNonceHeader и DeviceProofHeader ответ на вызов /api/device/challenge, см. types.DeviceProof
+	DeviceNonceHeader = "X-Device-Nonce"
+	DeviceProofHeader = "X-Device-Proof"
+	// DeviceEnrollmentHeader код администратора для первого устройства после сброса устройств
+	DeviceEnrollmentHeader = "X-Device-Enrollment"
+)
+
+// maxDeviceInfo наибольшая длина имени устройства и версии клиента, остальное отбрасывается
+const maxDeviceInfo = 100
+
+// HandleSessions возвращает действующие сессии пользователя: устройства, на которых выполнен вход
+func (h *HandlerSet) HandleSessions(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	sessions, err := h.database.ListSessions(req.Context(), userID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if sessions == nil {
+		sessions = []types.Session{}
+	}
+	current, _ := auth.GetSessionID(req)
+	for i := range sessions {
+		sessions[i].Current = strconv.Itoa(sessions[i].ID) == current
+	}
+	writeJSON(w, sessions)
+}
+
+// HandleDeleteSession завершает сессию пользователя на другом устройстве или на текущем
+func (h *HandlerSet) HandleDeleteSession(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Wrong id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.DeleteSession(req.Context(), userID, id)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	h.recordAudit(req, userID, types.AuditSessionRevoke, strconv.Itoa(id))
+}
+
+// TouchSession проверка сессии для auth.AuthenticateMiddleware
+func (h *HandlerSet) TouchSession(ctx context.Context, username string, session string, remoteAddr string) error {
+	id, err := strconv.Atoi(session)
+	if err != nil {
+		return fmt.Errorf("token has no session")
+	}
+	return h.database.TouchSession(ctx, username, id, remoteAddr)
+}
+
+// startSession заводит сессию устройства, с которого выполнен вход, и отдаёт её токен. Вход с нового устройства
+// ждёт подтверждения: тогда отвечает 202 с запросом, по которому устройство узнаёт решение, и возвращает false.
+// trust - устройство подтверждается без запроса (восстановление доступа)
+func (h *HandlerSet) startSession(w http.ResponseWriter, req *http.Request, username string, trust bool) (bool, error) {
+	userID, err := h.database.GetUserID(req.Context(), username)
+	if err != nil {
+		return false, err
+	}
+
+	device := types.NewDevice{
+		PublicKey:  req.Header.Get(DeviceKeyHeader),
+		Name:       truncate(req.Header.Get(DeviceHeader), maxDeviceInfo),
+		RemoteAddr: req.RemoteAddr,
+	}
+	var secret string
+	if device.PublicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(device.PublicKey)
+		if err != nil || len(key) != deviceKeySize {
+			http.Error(w, "Wrong device key", http.StatusBadRequest)
+			return false, nil
+		}
+		// открытый ключ не секрет: без ответа на вызов любой, кто его знает, вошёл бы как подтверждённое устройство
+		if !h.verifyDeviceProof(key, req.Header.Get(DeviceNonceHeader), req.Header.Get(DeviceProofHeader)) {
+			http.Error(w, "Device key not proven", http.StatusUnauthorized)
+			return false, nil
+		}
+		secret, err = newSecret()
+		if err != nil {
+			return false, err
+		}
+		device.PollHash = hashSecret(secret)
+		if code := req.Header.Get(DeviceEnrollmentHeader); code != "" {
+			device.EnrollmentHash = hashSecret(code)
+		}
+	}
+
+	login, err := h.database.LoginDevice(req.Context(), userID, device, trust)
+	if err != nil {
+		var permissionDenied *db.PermissionDeniedError
+		if errors.As(err, &permissionDenied) {
+			http.Error(w, "Logins from new devices need approval, update the client", http.StatusForbidden)
+			return false, nil
+		}
+		var userDisabled *db.UserDisabledError
+		if errors.As(err, &userDisabled) {
+			http.Error(w, "Account disabled", http.StatusForbidden)
+			return false, nil
+		}
+		var enrollmentRequired *db.EnrollmentRequiredError
+		if errors.As(err, &enrollmentRequired) {
+			http.Error(w, types.EnrollmentRequired, http.StatusForbidden)
+			return false, nil
+		}
+		var deviceRejected *db.DeviceRejectedError
+		if errors.As(err, &deviceRejected) {
+			http.Error(w, "Login from this device was rejected, try again later", http.StatusForbidden)
+			return false, nil
+		}
+		var requestsLimit *db.DeviceRequestsLimitError
+		if errors.As(err, &requestsLimit) {
+			http.Error(w, "Too many logins are waiting for approval, try again later", http.StatusTooManyRequests)
+			return false, nil
+		}
+		return false, err
+	}
+	if login.Status == types.DevicePending {
+		h.appendAudit(req, types.AuditEvent{UserID: &userID, Actor: username, Action: types.AuditDeviceRequest,
+			Key: strconv.Itoa(login.ID)})
+		writeDeviceRequest(w, types.DeviceRequest{ID: login.ID, Secret: secret,
+			Fingerprint: types.DeviceFingerprint(device.PublicKey)})
+		return false, nil
+	}
+	return true, h.createSession(w, req, userID, username, login.ID)
+}
+
+func (h *HandlerSet) createSession(w http.ResponseWriter, req *http.Request, userID int, username string, deviceID int) error {
+	session := types.NewSession{
+		DeviceID:      deviceID,
+		Device:        truncate(req.Header.Get(DeviceHeader), maxDeviceInfo),
+		ClientVersion: truncate(req.Header.Get(ClientVersionHeader), maxDeviceInfo),
+		RemoteAddr:    req.RemoteAddr,
+	}
+	id, err := h.database.CreateSession(req.Context(), userID, session)
+	if err != nil {
+		return err
+	}
+	return auth.SetSessionToken(username, strconv.Itoa(id), w, h.secret)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+	"gotest.tools/assert"
+)
+
+func TestHandlerSet_HandleSessions(t *testing.T) {
+	mdb := &MockDatabase{}
+	h := &HandlerSet{secret: []byte("secret"), database: mdb}
+	req := authorizedRequest(http.MethodGet, "/api/user/sessions", nil)
+	const sessionKey auth.UserKey = "session"
+	req = req.WithContext(context.WithValue(req.Context(), sessionKey, "5"))
+
+	mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+	mdb.EXPECT().ListSessions(req.Context(), 1).Return([]types.Session{{ID: 5, Device: "laptop"}, {ID: 6, Device: "phone"}}, nil)
+
+	w := httptest.NewRecorder()
+	h.HandleSessions(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var sessions []types.Session
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+	assert.Equal(t, 2, len(sessions))
+	assert.Assert(t, sessions[0].Current)
+	assert.Assert(t, !sessions[1].Current)
+}
+
+func TestHandlerSet_HandleDeleteSession(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		id                 string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", "6", nil, http.StatusOK},
+		{"badID", "six", nil, http.StatusBadRequest},
+		{"notFound", "6", &db.KeyNotFoundError{Key: "6"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodDelete, "/api/user/sessions/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().DeleteSession(req.Context(), 1, 6).Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleDeleteSession(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_StartSession(t *testing.T) {
+	mdb := &MockDatabase{}
+	h := &HandlerSet{secret: []byte("secret"), database: mdb}
+	req, _ := http.NewRequest(http.MethodPost, "/api/user/login", bytes.NewBuffer(nil))
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set(DeviceHeader, "laptop")
+	req.Header.Set(ClientVersionHeader, "1.2.3")
+
+	mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+	mdb.EXPECT().LoginDevice(req.Context(), 1, types.NewDevice{Name: "laptop", RemoteAddr: "10.0.0.1:5000"}, false).Return(types.DeviceLogin{Status: types.DeviceApproved}, nil)
+	mdb.EXPECT().CreateSession(req.Context(), 1, types.NewSession{Device: "laptop", ClientVersion: "1.2.3", RemoteAddr: "10.0.0.1:5000"}).Return(5, nil)
+
+	w := httptest.NewRecorder()
+	started, err := h.startSession(w, req, "user", false)
+	assert.NilError(t, err)
+	assert.Assert(t, started)
+	assert.Equal(t, token, w.Header().Get(auth.AuthHeader))
+}
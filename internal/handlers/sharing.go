@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+)
+
+// HandleSetUserKeys сохраняет ключевую пару пользователя. Приватный ключ приходит зашифрованным на клиенте
+func (h *HandlerSet) HandleSetUserKeys(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	var keys types.UserKeys
+	err = decodeBody(req, &keys)
+	if err != nil || keys.PublicKey == "" || keys.PrivateKey == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.SetUserKeys(req.Context(), userID, keys)
+	if err != nil {
+		var keyExistsError *db.KeyExistsError
+		if errors.As(err, &keyExistsError) {
+			http.Error(w, "Keys already set", http.StatusConflict)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// HandleSetPrivateKey заменяет зашифрованный приватный ключ пользователя, открытый ключ остаётся прежним
+func (h *HandlerSet) HandleSetPrivateKey(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	var keys types.UserKeys
+	err = decodeBody(req, &keys)
+	if err != nil || keys.PrivateKey == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.SetPrivateKey(req.Context(), userID, keys.PrivateKey)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleGetUserKeys возвращает ключевую пару пользователя
+func (h *HandlerSet) HandleGetUserKeys(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	keys, err := h.database.GetUserKeys(req.Context(), userID)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, keys)
+}
+
+// HandleGetPublicKey возвращает открытый ключ другого пользователя
+func (h *HandlerSet) HandleGetPublicKey(w http.ResponseWriter, req *http.Request) {
+
+	_, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	username := req.PathValue("username")
+	if username == "" {
+		http.Error(w, "Username not passed", http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.database.GetPublicKey(req.Context(), username)
+	if err != nil {
+		var userNotFound *db.UserNotFoundError
+		if errors.As(err, &userNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "User has no keys", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, types.UserKeys{PublicKey: key})
+}
+
+// HandleShareItem делится записью с другим пользователем. Тело запроса - types.Share с получателем, правами,
+// зашифрованными для получателя и владельца ключами данных и зашифрованными данными записи
+func (h *HandlerSet) HandleShareItem(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+	username, _ := auth.GetAuthenticatedUser(req)
+
+	key := req.PathValue("key")
+	if key == "" {
+		http.Error(w, "Key not passed", http.StatusBadRequest)
+		return
+	}
+
+	var share types.Share
+	err = decodeBody(req, &share)
+	if err != nil || share.Recipient == "" || !share.Permission.Valid() ||
+		share.RecipientKey == "" || share.OwnerKey == "" || share.Data == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+	if share.Recipient == username {
+		http.Error(w, "Cannot share with yourself", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.ShareItem(req.Context(), userID, key, share)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		var userNotFound *db.UserNotFoundError
+		if errors.As(err, &userNotFound) {
+			http.Error(w, "Recipient not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// HandleItemShares возвращает список пользователей, с которыми владелец поделился записью
+func (h *HandlerSet) HandleItemShares(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	key := req.PathValue("key")
+	if key == "" {
+		http.Error(w, "Key not passed", http.StatusBadRequest)
+		return
+	}
+
+	shares, err := h.database.GetItemShares(req.Context(), userID, key)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if shares == nil {
+		shares = []types.Share{}
+	}
+	writeJSON(w, shares)
+}
+
+// HandleRevokeShare отзывает у пользователя доступ к записи
+func (h *HandlerSet) HandleRevokeShare(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	key := req.PathValue("key")
+	username := req.PathValue("username")
+	if key == "" || username == "" {
+		http.Error(w, "Key or username not passed", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.RevokeShare(req.Context(), userID, key, username)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+}
+
+// HandleSharedWithMe возвращает записи, которыми с пользователем поделились другие
+func (h *HandlerSet) HandleSharedWithMe(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	shares, err := h.database.GetSharedWithUser(req.Context(), userID)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if shares == nil {
+		shares = []types.Share{}
+	}
+	writeJSON(w, shares)
+}
+
+// HandleUpdateShare заменяет зашифрованные данные общей записи. Доступно владельцу и получателю с правом записи
+func (h *HandlerSet) HandleUpdateShare(w http.ResponseWriter, req *http.Request) {
+
+	userID, err := h.handleAuthorizeUser(w, req)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Error parsing id", http.StatusBadRequest)
+		return
+	}
+
+	var share types.Share
+	err = decodeBody(req, &share)
+	if err != nil || share.Data == "" {
+		http.Error(w, "Could not unmarshal body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.database.UpdateShare(req.Context(), userID, id, share.Data)
+	if err != nil {
+		var keyNotFound *db.KeyNotFoundError
+		if errors.As(err, &keyNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		var permissionDenied *db.PermissionDeniedError
+		if errors.As(err, &permissionDenied) {
+			http.Error(w, "Read-only share", http.StatusForbidden)
+			return
+		}
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+}
+
+func decodeBody(req *http.Request, v any) error {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Println(err.Error())
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	_, err = w.Write(data)
+	if err != nil {
+		http.Error(w, "Something went wrong",
+			http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wellywell/gophkeeper/internal/auth"
+	"github.com/wellywell/gophkeeper/internal/db"
+	"github.com/wellywell/gophkeeper/internal/types"
+	"gotest.tools/assert"
+)
+
+func authorizedRequest(method string, url string, body []byte) *http.Request {
+	req, _ := http.NewRequest(method, url, bytes.NewBuffer(body))
+	const contextKey auth.UserKey = "username"
+	ctx := context.WithValue(req.Context(), contextKey, "user")
+	return req.WithContext(ctx)
+}
+
+func TestHandlerSet_HandleSetUserKeys(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		body               string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", `{"public_key": "pub", "private_key": "priv"}`, nil, http.StatusCreated},
+		{"noPrivateKey", `{"public_key": "pub"}`, nil, http.StatusBadRequest},
+		{"exists", `{"public_key": "pub", "private_key": "priv"}`, &db.KeyExistsError{Key: "user keys"}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPut, "/api/user/keys", []byte(tt.body))
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().SetUserKeys(req.Context(), 1, types.UserKeys{PublicKey: "pub", PrivateKey: "priv"}).Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleSetUserKeys(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleSetPrivateKey(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		body               string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", `{"private_key": "priv"}`, nil, http.StatusOK},
+		{"noPrivateKey", `{"public_key": "pub"}`, nil, http.StatusBadRequest},
+		{"noKeys", `{"private_key": "priv"}`, &db.KeyNotFoundError{Key: "user keys"}, http.StatusNotFound},
+		{"dbError", `{"private_key": "priv"}`, fmt.Errorf("db is down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPut, "/api/user/keys/private", []byte(tt.body))
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().SetPrivateKey(req.Context(), 1, "priv").Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleSetPrivateKey(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleGetPublicKey(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		dbErr              error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{"ok", nil, http.StatusOK, `{"public_key":"pub"}`},
+		{"noUser", &db.UserNotFoundError{Username: "bob"}, http.StatusNotFound, "User not found\n"},
+		{"noKeys", &db.KeyNotFoundError{Key: "bob"}, http.StatusNotFound, "User has no keys\n"},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodGet, "/api/user/bob/public_key", nil)
+			req.SetPathValue("username", "bob")
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().GetPublicKey(req.Context(), "bob").Return("pub", tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleGetPublicKey(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandlerSet_HandleShareItem(t *testing.T) {
+
+	share := types.Share{Recipient: "bob", Permission: types.PermissionRead, RecipientKey: "rk", OwnerKey: "ok", Data: "data"}
+
+	tests := []struct {
+		name               string
+		body               string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", `{"recipient": "bob", "permission": "read", "recipient_key": "rk", "owner_key": "ok", "data": "data"}`, nil, http.StatusCreated},
+		{"badPermission", `{"recipient": "bob", "permission": "admin", "recipient_key": "rk", "owner_key": "ok", "data": "data"}`, nil, http.StatusBadRequest},
+		{"self", `{"recipient": "user", "permission": "read", "recipient_key": "rk", "owner_key": "ok", "data": "data"}`, nil, http.StatusBadRequest},
+		{"itemNotExists", `{"recipient": "bob", "permission": "read", "recipient_key": "rk", "owner_key": "ok", "data": "data"}`, &db.KeyNotFoundError{Key: "111"}, http.StatusNotFound},
+		{"recipientNotExists", `{"recipient": "bob", "permission": "read", "recipient_key": "rk", "owner_key": "ok", "data": "data"}`, &db.UserNotFoundError{Username: "bob"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPost, "/api/item/111/share", []byte(tt.body))
+			req.SetPathValue("key", "111")
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().ShareItem(req.Context(), 1, "111", share).Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleShareItem(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleSharedWithMe(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		shares       []types.Share
+		expectedBody string
+	}{
+		{"ok", []types.Share{{ID: 1, Owner: "alice", Recipient: "user", Key: "mail", Type: types.TypeLogoPass, Permission: types.PermissionWrite, RecipientKey: "rk", OwnerKey: "ok", Data: "data"}},
+			`[{"id":1,"owner":"alice","recipient":"user","key":"mail","type":"logopass","permission":"write","recipient_key":"rk","owner_key":"ok","data":"data"}]`},
+		{"empty", nil, `[]`},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodGet, "/api/shares", nil)
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().GetSharedWithUser(req.Context(), 1).Return(tt.shares, nil)
+
+			w := httptest.NewRecorder()
+			h.HandleSharedWithMe(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandlerSet_HandleRevokeShare(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", nil, http.StatusOK},
+		{"notExists", &db.KeyNotFoundError{Key: "111"}, http.StatusNotFound},
+		{"dbError", fmt.Errorf("error"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodDelete, "/api/item/111/share/bob", nil)
+			req.SetPathValue("key", "111")
+			req.SetPathValue("username", "bob")
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().RevokeShare(req.Context(), 1, "111", "bob").Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleRevokeShare(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandlerSet_HandleUpdateShare(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		id                 string
+		dbErr              error
+		expectedStatusCode int
+	}{
+		{"ok", "5", nil, http.StatusOK},
+		{"badID", "x", nil, http.StatusBadRequest},
+		{"notExists", "5", &db.KeyNotFoundError{Key: "5"}, http.StatusNotFound},
+		{"readOnly", "5", &db.PermissionDeniedError{Key: "5"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		mdb := &MockDatabase{}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HandlerSet{secret: []byte("secret"), database: mdb}
+			req := authorizedRequest(http.MethodPut, "/api/shares/"+tt.id, []byte(`{"data": "new"}`))
+			req.SetPathValue("id", tt.id)
+
+			mdb.EXPECT().GetUserID(req.Context(), "user").Return(1, nil)
+			mdb.EXPECT().UpdateShare(req.Context(), 1, 5, "new").Return(tt.dbErr)
+
+			w := httptest.NewRecorder()
+			h.HandleUpdateShare(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -36,8 +36,14 @@ func NewServer(conf config.ServerConfig, h handlers.HandlerSet, middlewares ...M
 
 	r.Post("/api/user/register", h.HandleRegisterUser)
 	r.Post("/api/user/login", h.HandleLogin)
+	r.Post("/api/user/recover", h.HandleRecoverAccount)
+	r.Get("/api/device/challenge", h.HandleDeviceChallenge)
+	r.Post("/api/device/{id}/poll", h.HandlePollDevice)
+	r.Get("/api/send/{id}", h.HandleSendInfo)
+	r.Post("/api/send/{id}/open", h.HandleOpenSend)
+	r.Get("/send/{id}", h.HandleSendPage)
 
-	authMiddleware := &auth.AuthenticateMiddleware{Secret: conf.Secret}
+	authMiddleware := &auth.AuthenticateMiddleware{Secret: conf.Secret, Sessions: &h}
 
 	r.Group(func(r chi.Router) {
 		r.Use(authMiddleware.Handle)
@@ -51,8 +57,86 @@ func NewServer(conf config.ServerConfig, h handlers.HandlerSet, middlewares ...M
 		r.Delete("/api/item/{key}", h.HandleDeleteItem)
 		r.Post("/api/item/text", h.HandleStoreText)
 		r.Put("/api/item/text", h.HandleUpdateText)
+		r.Post("/api/item/ssh_key", h.HandleStoreSSHKey)
+		r.Put("/api/item/ssh_key", h.HandleUpdateSSHKey)
+		r.Post("/api/item/totp", h.HandleStoreTOTP)
+		r.Put("/api/item/totp", h.HandleUpdateTOTP)
 		r.Get("/api/item/binary/{key}/download", h.HandleDownloadBinaryItem)
 		r.Get("/api/item/list", h.HandleItemList)
+		r.Get("/api/item/{key}/attachments", h.HandleAttachmentList)
+		r.Post("/api/item/{key}/attachments", h.HandleStoreAttachment)
+		r.Get("/api/item/{key}/attachments/{id}", h.HandleGetAttachment)
+		r.Put("/api/item/{key}/attachments/{id}", h.HandleReplaceAttachment)
+		r.Delete("/api/item/{key}/attachments/{id}", h.HandleDeleteAttachment)
+		r.Put("/api/user/keys", h.HandleSetUserKeys)
+		r.Get("/api/user/keys", h.HandleGetUserKeys)
+		r.Put("/api/user/keys/private", h.HandleSetPrivateKey)
+		r.Put("/api/user/vault_key", h.HandleSetVaultKey)
+		r.Get("/api/user/vault_key", h.HandleGetVaultKey)
+		r.Post("/api/user/vault_key/migrate", h.HandleMigrateVaultKey)
+		r.Get("/api/user/vault_key/legacy", h.HandleLegacyVault)
+		r.Delete("/api/user/vault_key/legacy", h.HandleFinishVaultKeyMigration)
+		r.Put("/api/user/recovery", h.HandleSetRecovery)
+		r.Get("/api/user/sessions", h.HandleSessions)
+		r.Delete("/api/user/sessions/{id}", h.HandleDeleteSession)
+		r.Delete("/api/user", h.HandleDeleteAccount)
+		r.Get("/api/user/deletion", h.HandleAccountDeletion)
+		r.Delete("/api/user/deletion", h.HandleCancelAccountDeletion)
+		r.Get("/api/user/devices", h.HandleDevices)
+		r.Post("/api/user/devices/{id}/approve", h.HandleApproveDevice)
+		r.Post("/api/user/devices/{id}/reject", h.HandleRejectDevice)
+		r.Delete("/api/user/devices/{id}", h.HandleDeleteDevice)
+		r.Get("/api/user/{username}/public_key", h.HandleGetPublicKey)
+		r.Post("/api/item/{key}/share", h.HandleShareItem)
+		r.Get("/api/item/{key}/shares", h.HandleItemShares)
+		r.Delete("/api/item/{key}/share/{username}", h.HandleRevokeShare)
+		r.Get("/api/shares", h.HandleSharedWithMe)
+		r.Put("/api/shares/{id}", h.HandleUpdateShare)
+		r.Post("/api/org", h.HandleCreateOrg)
+		r.Get("/api/org", h.HandleListOrgs)
+		r.Delete("/api/org/{org}", h.HandleDeleteOrg)
+		r.Post("/api/org/{org}/accept", h.HandleAcceptInvite)
+		r.Get("/api/org/{org}/members", h.HandleOrgMembers)
+		r.Post("/api/org/{org}/members", h.HandleInviteMember)
+		r.Put("/api/org/{org}/members/{username}", h.HandleSetMemberRole)
+		r.Delete("/api/org/{org}/members/{username}", h.HandleRemoveMember)
+		r.Put("/api/org/{org}/members/{username}/collections", h.HandleSetMemberCollections)
+		r.Get("/api/org/{org}/items", h.HandleOrgItems)
+		r.Put("/api/org/{org}/items", h.HandlePutOrgItem)
+		r.Get("/api/org/{org}/items/{key}", h.HandleGetOrgItem)
+		r.Delete("/api/org/{org}/items/{key}", h.HandleDeleteOrgItem)
+		r.Post("/api/send", h.HandleCreateSend)
+		r.Get("/api/send", h.HandleListSends)
+		r.Delete("/api/send/{id}", h.HandleDeleteSend)
+		r.Post("/api/emergency", h.HandleCreateEmergencyAccess)
+		r.Get("/api/emergency/contacts", h.HandleEmergencyContacts)
+		r.Get("/api/emergency/grants", h.HandleEmergencyGrants)
+		r.Delete("/api/emergency/{id}", h.HandleDeleteEmergencyAccess)
+		r.Post("/api/emergency/{id}/accept", h.HandleAcceptEmergencyAccess)
+		r.Post("/api/emergency/{id}/request", h.HandleRequestEmergencyAccess)
+		r.Post("/api/emergency/{id}/approve", h.HandleApproveEmergencyAccess)
+		r.Post("/api/emergency/{id}/reject", h.HandleRejectEmergencyAccess)
+		r.Get("/api/emergency/{id}/key", h.HandleEmergencyKey)
+		r.Get("/api/emergency/{id}/item/list", h.HandleEmergencyItemList)
+		r.Get("/api/emergency/{id}/item/{key}", h.HandleEmergencyGetItem)
+		r.Get("/api/emergency/{id}/item/binary/{key}/download", h.HandleEmergencyDownloadBinaryItem)
+		r.Get("/api/audit", h.HandleAuditLog)
+	})
+
+	adminMiddleware := &auth.AdminMiddleware{Token: conf.AdminToken}
+
+	r.Group(func(r chi.Router) {
+		r.Use(adminMiddleware.Handle)
+		r.Get("/api/admin/users", h.HandleAdminUsers)
+		r.Get("/api/admin/stats", h.HandleAdminStats)
+		r.Post("/api/admin/users/{username}/disable", h.HandleAdminDisableUser)
+		r.Post("/api/admin/users/{username}/enable", h.HandleAdminEnableUser)
+		r.Delete("/api/admin/users/{username}/sessions", h.HandleAdminLogoutUser)
+		r.Delete("/api/admin/users/{username}/devices", h.HandleAdminResetDevices)
+		r.Delete("/api/admin/users/{username}", h.HandleAdminDeleteUser)
+		r.Post("/api/admin/invites", h.HandleAdminCreateInvite)
+		r.Get("/api/admin/invites", h.HandleAdminInvites)
+		r.Delete("/api/admin/invites/{id}", h.HandleAdminDeleteInvite)
 	})
 
 	return &Server{server: http.Server{Addr: conf.RunAddress, Handler: r}, config: conf}
@@ -0,0 +1,184 @@
+// Package totp реализует генерацию одноразовых кодов по RFC 6238 и разбор otpauth:// URI
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDigits = 6
+	defaultPeriod = 30
+)
+
+var (
+	ErrInvalidURI    = errors.New("invalid otpauth uri")
+	ErrInvalidSecret = errors.New("invalid totp secret")
+)
+
+// Key параметры генерации TOTP-кодов
+type Key struct {
+	Secret    string
+	Issuer    string
+	Account   string
+	Algorithm string
+	Digits    int
+	Period    int
+}
+
+// Parse разбирает строку с otpauth:// URI, либо секретом в base32
+func Parse(s string) (*Key, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToLower(s), "otpauth://") {
+		return ParseURI(s)
+	}
+	key := &Key{Secret: normalizeSecret(s), Algorithm: "SHA1", Digits: defaultDigits, Period: defaultPeriod}
+	if _, err := key.secretBytes(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ParseURI разбирает otpauth://totp/ URI
+func ParseURI(uri string) (*Key, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURI, err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		return nil, ErrInvalidURI
+	}
+
+	q := u.Query()
+	key := &Key{
+		Secret:    normalizeSecret(q.Get("secret")),
+		Issuer:    q.Get("issuer"),
+		Algorithm: strings.ToUpper(q.Get("algorithm")),
+		Digits:    defaultDigits,
+		Period:    defaultPeriod,
+	}
+	if key.Algorithm == "" {
+		key.Algorithm = "SHA1"
+	}
+
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		if key.Issuer == "" {
+			key.Issuer = issuer
+		}
+		key.Account = strings.TrimSpace(account)
+	} else {
+		key.Account = label
+	}
+
+	if d := q.Get("digits"); d != "" {
+		key.Digits, err = strconv.Atoi(d)
+		if err != nil || key.Digits < 6 || key.Digits > 8 {
+			return nil, fmt.Errorf("%w: digits", ErrInvalidURI)
+		}
+	}
+	if p := q.Get("period"); p != "" {
+		key.Period, err = strconv.Atoi(p)
+		if err != nil || key.Period <= 0 {
+			return nil, fmt.Errorf("%w: period", ErrInvalidURI)
+		}
+	}
+	if _, err := key.hash(); err != nil {
+		return nil, err
+	}
+	if _, err := key.secretBytes(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// URI возвращает otpauth:// URI для ключа
+func (k *Key) URI() string {
+	q := url.Values{}
+	q.Set("secret", k.Secret)
+	if k.Issuer != "" {
+		q.Set("issuer", k.Issuer)
+	}
+	q.Set("algorithm", k.Algorithm)
+	q.Set("digits", strconv.Itoa(k.Digits))
+	q.Set("period", strconv.Itoa(k.Period))
+
+	label := k.Account
+	if k.Issuer != "" {
+		label = fmt.Sprintf("%s:%s", k.Issuer, k.Account)
+	}
+	u := url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + label, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// Code вычисляет код, действующий в момент времени t
+func (k *Key) Code(t time.Time) (string, error) {
+	secret, err := k.secretBytes()
+	if err != nil {
+		return "", err
+	}
+	h, err := k.hash()
+	if err != nil {
+		return "", err
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(t.Unix())/uint64(k.Period))
+
+	mac := hmac.New(h, secret)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range k.Digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", k.Digits, value%mod), nil
+}
+
+// Remaining возвращает количество секунд, в течение которых код, действующий в момент t, ещё будет валиден
+func (k *Key) Remaining(t time.Time) int {
+	return k.Period - int(t.Unix()%int64(k.Period))
+}
+
+func (k *Key) hash() (func() hash.Hash, error) {
+	switch k.Algorithm {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA512":
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported algorithm %s", ErrInvalidURI, k.Algorithm)
+}
+
+func (k *Key) secretBytes() ([]byte, error) {
+	if k.Secret == "" {
+		return nil, ErrInvalidSecret
+	}
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(k.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSecret, err)
+	}
+	return secret, nil
+}
+
+func normalizeSecret(secret string) string {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	return strings.TrimRight(secret, "=")
+}
@@ -0,0 +1,107 @@
+package totp
+
+import (
+	"encoding/base32"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func secret(s string) string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte(s))
+}
+
+// тестовые векторы из RFC 6238
+func TestKey_Code(t *testing.T) {
+	sha1Secret := secret("12345678901234567890")
+	sha256Secret := secret("12345678901234567890123456789012")
+	sha512Secret := secret("1234567890123456789012345678901234567890123456789012345678901234")
+
+	tests := []struct {
+		name string
+		key  Key
+		time int64
+		want string
+	}{
+		{"sha1 59", Key{Secret: sha1Secret, Algorithm: "SHA1", Digits: 8, Period: 30}, 59, "94287082"},
+		{"sha1 1111111109", Key{Secret: sha1Secret, Algorithm: "SHA1", Digits: 8, Period: 30}, 1111111109, "07081804"},
+		{"sha256 59", Key{Secret: sha256Secret, Algorithm: "SHA256", Digits: 8, Period: 30}, 59, "46119246"},
+		{"sha512 59", Key{Secret: sha512Secret, Algorithm: "SHA512", Digits: 8, Period: 30}, 59, "90693936"},
+		{"sha1 6 digits", Key{Secret: sha1Secret, Algorithm: "SHA1", Digits: 6, Period: 30}, 59, "287082"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.key.Code(time.Unix(tt.time, 0))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestKey_Remaining(t *testing.T) {
+	key := Key{Period: 30}
+	assert.Equal(t, 30, key.Remaining(time.Unix(60, 0)))
+	assert.Equal(t, 1, key.Remaining(time.Unix(59, 0)))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *Key
+		wantErr bool
+	}{
+		{"secret", "jbsw y3dp ehpk 3pxp", &Key{Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6, Period: 30}, false},
+		{"uri", "otpauth://totp/Example:alice@google.com?secret=JBSWY3DPEHPK3PXP&issuer=Example", &Key{Secret: "JBSWY3DPEHPK3PXP", Issuer: "Example", Account: "alice@google.com", Algorithm: "SHA1", Digits: 6, Period: 30}, false},
+		{"uri with params", "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&algorithm=SHA256&digits=8&period=60", &Key{Secret: "JBSWY3DPEHPK3PXP", Account: "alice", Algorithm: "SHA256", Digits: 8, Period: 60}, false},
+		{"hotp", "otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP", nil, true},
+		{"bad algorithm", "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&algorithm=MD5", nil, true},
+		{"bad secret", "not base32!", nil, true},
+		{"empty", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestKey_URI(t *testing.T) {
+	key := &Key{Secret: "JBSWY3DPEHPK3PXP", Issuer: "Example", Account: "alice@google.com", Algorithm: "SHA1", Digits: 6, Period: 30}
+	parsed, err := ParseURI(key.URI())
+	assert.NoError(t, err)
+	assert.Equal(t, key, parsed)
+}
+
+func TestReadQR(t *testing.T) {
+	uri := "otpauth://totp/Example:alice@google.com?secret=JBSWY3DPEHPK3PXP&issuer=Example"
+
+	matrix, err := qrcode.NewQRCodeWriter().Encode(uri, gozxing.BarcodeFormat_QR_CODE, 200, 200, nil)
+	require.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "qr.png")
+	f, err := os.Create(filename)
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(f, matrix))
+	require.NoError(t, f.Close())
+
+	got, err := ReadQR(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, uri, got)
+
+	_, err = ReadQR(filepath.Join(t.TempDir(), "missing.png"))
+	assert.Error(t, err)
+}
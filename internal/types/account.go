@@ -0,0 +1,16 @@
+package types
+
+import "time"
+
+// AccountDeletionGrace через сколько времени после запроса учётная запись удаляется. До этого запрос можно отменить
+const AccountDeletionGrace = 30 * 24 * time.Hour
+
+// DeleteAccount запрос на удаление учётной записи, подтверждённый паролем
+type DeleteAccount struct {
+	Password string `json:"password"`
+}
+
+// AccountDeletion запланированное удаление учётной записи
+type AccountDeletion struct {
+	DeleteAt time.Time `json:"delete_at"`
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// AdminUser учётная запись в списке администратора: только метаданные, без ключей и содержимого записей
+type AdminUser struct {
+	ID           int        `json:"id" db:"id"`
+	Username     string     `json:"username" db:"username"`
+	Items        int        `json:"items" db:"items"`
+	StorageBytes int64      `json:"storage_bytes" db:"storage_bytes"`
+	Sessions     int        `json:"sessions" db:"sessions"`
+	LastSeen     *time.Time `json:"last_seen,omitempty" db:"last_seen"`
+	Disabled     bool       `json:"disabled" db:"disabled"`
+	DeleteAt     *time.Time `json:"delete_at,omitempty" db:"delete_at"`
+}
+
+// String строковое представление учётной записи
+func (u AdminUser) String() string {
+	str := fmt.Sprintf("#%d %s: %d items, %d bytes, %d sessions", u.ID, u.Username, u.Items, u.StorageBytes, u.Sessions)
+	if u.LastSeen != nil {
+		str += ", last seen " + u.LastSeen.Local().Format(time.RFC3339)
+	}
+	if u.Disabled {
+		str += ", disabled"
+	}
+	if u.DeleteAt != nil {
+		str += ", deleted at " + u.DeleteAt.Local().Format(time.RFC3339)
+	}
+	return str
+}
+
+// AdminStats сводка по серверу для администратора
+type AdminStats struct {
+	Users            int   `json:"users" db:"users"`
+	DisabledUsers    int   `json:"disabled_users" db:"disabled_users"`
+	PendingDeletions int   `json:"pending_deletions" db:"pending_deletions"`
+	Items            int   `json:"items" db:"items"`
+	StorageBytes     int64 `json:"storage_bytes" db:"storage_bytes"`
+	Sessions         int   `json:"sessions" db:"sessions"`
+	Devices          int   `json:"devices" db:"devices"`
+	Shares           int   `json:"shares" db:"shares"`
+	Organizations    int   `json:"organizations" db:"organizations"`
+	Sends            int   `json:"sends" db:"sends"`
+}
+
+// AdminResult результат действия администратора: сколько строк оно затронуло
+type AdminResult struct {
+	Affected int64 `json:"affected"`
+}
@@ -0,0 +1,192 @@
+package types
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// AuditAction действие, записанное в журнал аудита
+type AuditAction string
+
+const (
+	AuditLogin            AuditAction = "login"
+	AuditLoginFailed      AuditAction = "login_failed"
+	AuditRegister         AuditAction = "register"
+	AuditRecover          AuditAction = "recover"
+	AuditRecoverFailed    AuditAction = "recover_failed"
+	AuditItemCreate       AuditAction = "item_create"
+	AuditItemRead         AuditAction = "item_read"
+	AuditItemUpdate       AuditAction = "item_update"
+	AuditItemDelete       AuditAction = "item_delete"
+	AuditItemDownload     AuditAction = "item_download"
+	AuditAttachmentCreate AuditAction = "attachment_create"
+	AuditAttachmentRead   AuditAction = "attachment_read"
+	AuditAttachmentUpdate AuditAction = "attachment_update"
+	AuditAttachmentDelete AuditAction = "attachment_delete"
+	AuditSessionRevoke    AuditAction = "session_revoke"
+	AuditDeviceRequest    AuditAction = "device_request"
+	AuditDeviceApprove    AuditAction = "device_approve"
+	AuditDeviceReject     AuditAction = "device_reject"
+	AuditDeviceDelete     AuditAction = "device_delete"
+	AuditDeletionRequest  AuditAction = "account_delete_request"
+	AuditDeletionCancel   AuditAction = "account_delete_cancel"
+	AuditAccountDelete    AuditAction = "account_delete"
+	AuditAccountDisable   AuditAction = "account_disable"
+	AuditAccountEnable    AuditAction = "account_enable"
+	AuditDevicesReset     AuditAction = "devices_reset"
+	AuditVaultKeyMigrate  AuditAction = "vault_key_migrate"
+)
+
+// Поля события с персональными данными. В хеш события входят не сами значения, а их хеши со случайной солью,
+// поэтому при удалении учётной записи значения можно стереть, не нарушая цепочку
+const (
+	AuditFieldUserID     = "user_id"
+	AuditFieldActor      = "actor"
+	AuditFieldKey        = "item_key"
+	AuditFieldRemoteAddr = "remote_addr"
+)
+
+var auditFields = []string{AuditFieldUserID, AuditFieldActor, AuditFieldKey, AuditFieldRemoteAddr}
+
+// AuditEvent событие журнала аудита. UserID - владелец учётной записи или хранилища, к которому относится событие,
+// Actor - пользователь, совершивший действие: при экстренном доступе это доверенное лицо.
+// Каждое событие сцеплено с предыдущим: Hash считается от PrevHash и полей события
+type AuditEvent struct {
+	ID         int64       `json:"id" db:"id"`
+	UserID     *int        `json:"-" db:"user_id"`
+	Actor      string      `json:"actor" db:"actor"`
+	Action     AuditAction `json:"action" db:"action"`
+	Key        string      `json:"key,omitempty" db:"item_key"`
+	RemoteAddr string      `json:"remote_addr,omitempty" db:"remote_addr"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+	PrevHash   string      `json:"prev_hash" db:"prev_hash"`
+	Hash       string      `json:"hash" db:"hash"`
+	// Salts соли персональных полей, nil - событие записано до их появления и хешируется по значениям
+	Salts map[string]string `json:"-" db:"salts"`
+	// Redacted хеши стёртых персональных полей
+	Redacted map[string]string `json:"-" db:"redacted"`
+}
+
+// NewAuditSalts случайные соли персональных полей нового события
+func NewAuditSalts() (map[string]string, error) {
+	salts := make(map[string]string, len(auditFields))
+	for _, field := range auditFields {
+		salt := make([]byte, 16)
+		_, err := rand.Read(salt)
+		if err != nil {
+			return nil, err
+		}
+		salts[field] = hex.EncodeToString(salt)
+	}
+	return salts, nil
+}
+
+// Digest хеш события, сцепленный с хешем предыдущего. Время берётся в UTC с точностью до микросекунд,
+// как его хранит БД
+func (e AuditEvent) Digest() string {
+	createdAt := e.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+	var payload []byte
+	if e.Salts == nil {
+		payload, _ = json.Marshal(struct {
+			ID         int64
+			UserID     *int
+			Actor      string
+			Action     AuditAction
+			Key        string
+			RemoteAddr string
+			CreatedAt  string
+		}{e.ID, e.UserID, e.Actor, e.Action, e.Key, e.RemoteAddr, createdAt})
+	} else {
+		fields := make(map[string]string, len(auditFields))
+		for _, field := range auditFields {
+			fields[field] = e.fieldHash(field)
+		}
+		payload, _ = json.Marshal(struct {
+			ID        int64
+			Action    AuditAction
+			CreatedAt string
+			Fields    map[string]string
+		}{e.ID, e.Action, createdAt, fields})
+	}
+
+	sum := sha256.Sum256(append([]byte(e.PrevHash+"\n"), payload...))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verifiable можно ли пересчитать хеш события. У событий без солей после стирания полей
+// проверяется только связь с соседними событиями
+func (e AuditEvent) Verifiable() bool {
+	return e.Salts != nil || len(e.Redacted) == 0
+}
+
+// Redact стирает персональные поля события. Хеши полей сохраняются в Redacted, а их соли удаляются,
+// так что хеш события не меняется, а стёртое значение нельзя подобрать по хешу
+func (e *AuditEvent) Redact(fields ...string) {
+	if e.Redacted == nil {
+		e.Redacted = make(map[string]string, len(fields))
+	}
+	for _, field := range fields {
+		if _, ok := e.Redacted[field]; ok {
+			continue
+		}
+		hash := ""
+		if e.Salts != nil {
+			hash = e.fieldHash(field)
+			delete(e.Salts, field)
+		}
+		e.Redacted[field] = hash
+		switch field {
+		case AuditFieldUserID:
+			e.UserID = nil
+		case AuditFieldActor:
+			e.Actor = ""
+		case AuditFieldKey:
+			e.Key = ""
+		case AuditFieldRemoteAddr:
+			e.RemoteAddr = ""
+		}
+	}
+}
+
+// fieldHash хеш персонального поля: сохранённый при стирании или посчитанный по значению и соли
+func (e AuditEvent) fieldHash(field string) string {
+	if hash, ok := e.Redacted[field]; ok {
+		return hash
+	}
+	var value string
+	switch field {
+	case AuditFieldUserID:
+		if e.UserID != nil {
+			value = strconv.Itoa(*e.UserID)
+		}
+	case AuditFieldActor:
+		value = e.Actor
+	case AuditFieldKey:
+		value = e.Key
+	case AuditFieldRemoteAddr:
+		value = e.RemoteAddr
+	}
+	sum := sha256.Sum256([]byte(e.Salts[field] + "\n" + value))
+	return hex.EncodeToString(sum[:])
+}
+
+// String строковое представление события
+func (e AuditEvent) String() string {
+	actor := e.Actor
+	if actor == "" {
+		actor = "[deleted user]"
+	}
+	s := fmt.Sprintf("%s %s by %s", e.CreatedAt.Local().Format(time.RFC3339), e.Action, actor)
+	if e.Key != "" {
+		s += fmt.Sprintf(": %s", e.Key)
+	}
+	if e.RemoteAddr != "" {
+		s += fmt.Sprintf(" from %s", e.RemoteAddr)
+	}
+	return s
+}
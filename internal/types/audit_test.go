@@ -0,0 +1,82 @@
+package types
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditEvent_Digest(t *testing.T) {
+	userID := 1
+	event := AuditEvent{ID: 7, UserID: &userID, Actor: "user", Action: AuditItemRead, Key: "key",
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC), PrevHash: "prev"}
+	digest := event.Digest()
+	assert.Len(t, digest, 64)
+
+	// БД возвращает время в другом поясе и с точностью до микросекунд
+	read := event
+	read.CreatedAt = event.CreatedAt.Truncate(time.Microsecond).In(time.FixedZone("MSK", 3*60*60))
+	assert.Equal(t, digest, read.Digest())
+
+	tampered := []func(e *AuditEvent){
+		func(e *AuditEvent) { e.PrevHash = "other" },
+		func(e *AuditEvent) { e.Actor = "other" },
+		func(e *AuditEvent) { e.Action = AuditItemDelete },
+		func(e *AuditEvent) { e.UserID = nil },
+		func(e *AuditEvent) { e.CreatedAt = e.CreatedAt.Add(time.Second) },
+	}
+	for _, tamper := range tampered {
+		e := event
+		tamper(&e)
+		assert.NotEqual(t, digest, e.Digest())
+	}
+}
+
+func TestAuditEvent_Redact(t *testing.T) {
+	userID := 1
+	salts, err := NewAuditSalts()
+	require.NoError(t, err)
+	event := AuditEvent{ID: 7, UserID: &userID, Actor: "user", Action: AuditItemRead, Key: "key", RemoteAddr: "10.0.0.1:1234",
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), PrevHash: "prev", Salts: salts}
+	event.Hash = event.Digest()
+
+	// значения персональных полей входят в хеш
+	for _, tamper := range []func(e *AuditEvent){
+		func(e *AuditEvent) { e.Actor = "other" },
+		func(e *AuditEvent) { e.Key = "other" },
+		func(e *AuditEvent) { e.RemoteAddr = "" },
+		func(e *AuditEvent) { e.UserID = nil },
+		func(e *AuditEvent) { e.Action = AuditItemDelete },
+	} {
+		e := event
+		tamper(&e)
+		assert.NotEqual(t, event.Hash, e.Digest())
+	}
+
+	// стирание не меняет хеш, а стёртое значение нельзя вернуть, не зная соли
+	partial := event
+	partial.Salts = maps.Clone(salts)
+	partial.Redact(AuditFieldActor, AuditFieldRemoteAddr)
+	assert.Equal(t, "", partial.Actor)
+	assert.Equal(t, "", partial.RemoteAddr)
+	assert.Equal(t, "key", partial.Key)
+	assert.NotContains(t, partial.Salts, AuditFieldActor)
+	assert.True(t, partial.Verifiable())
+	assert.Equal(t, event.Hash, partial.Digest())
+
+	partial.Redact(AuditFieldUserID, AuditFieldActor, AuditFieldKey, AuditFieldRemoteAddr)
+	assert.Nil(t, partial.UserID)
+	assert.Empty(t, partial.Salts)
+	assert.Equal(t, event.Hash, partial.Digest())
+	assert.Contains(t, partial.String(), "by [deleted user]")
+
+	// у событий без солей после стирания хеш не пересчитывается
+	legacy := AuditEvent{ID: 8, UserID: &userID, Actor: "user", Action: AuditLogin, PrevHash: event.Hash}
+	assert.True(t, legacy.Verifiable())
+	legacy.Redact(AuditFieldActor)
+	assert.Equal(t, "", legacy.Actor)
+	assert.False(t, legacy.Verifiable())
+}
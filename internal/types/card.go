@@ -0,0 +1,150 @@
+package types
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Платёжные системы, определяемые по номеру карты
+const (
+	BrandVisa       = "Visa"
+	BrandMastercard = "Mastercard"
+	BrandAmex       = "American Express"
+	BrandMIR        = "MIR"
+	BrandDiscover   = "Discover"
+	BrandJCB        = "JCB"
+	BrandUnionPay   = "UnionPay"
+	BrandDiners     = "Diners Club"
+	BrandMaestro    = "Maestro"
+	BrandUnknown    = "Unknown"
+)
+
+var (
+	ErrCardNumberDigits = errors.New("card number must contain digits only")
+	ErrCardNumberLength = errors.New("card number must be 12 to 19 digits long")
+	ErrCardNumberLuhn   = errors.New("invalid card number (checksum mismatch)")
+	ErrCVC              = errors.New("CVC must be 3 or 4 digits")
+)
+
+// brandRange диапазон префиксов номера карты (включительно) одной длины
+type brandRange struct {
+	from  int
+	to    int
+	brand string
+}
+
+// порядок важен: более узкие диапазоны проверяются раньше пересекающихся с ними широких
+var brandRanges = []brandRange{
+	{34, 34, BrandAmex},
+	{37, 37, BrandAmex},
+	{2200, 2204, BrandMIR},
+	{2221, 2720, BrandMastercard},
+	{51, 55, BrandMastercard},
+	{4, 4, BrandVisa},
+	{6011, 6011, BrandDiscover},
+	{644, 649, BrandDiscover},
+	{65, 65, BrandDiscover},
+	{3528, 3589, BrandJCB},
+	{62, 62, BrandUnionPay},
+	{300, 305, BrandDiners},
+	{36, 36, BrandDiners},
+	{38, 39, BrandDiners},
+	{50, 50, BrandMaestro},
+	{56, 58, BrandMaestro},
+	{63, 63, BrandMaestro},
+	{67, 67, BrandMaestro},
+}
+
+// NormalizeCardNumber убирает из номера карты пробелы и дефисы
+func NormalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+// CardBrand определяет платёжную систему по префиксу номера карты
+func CardBrand(number string) string {
+	number = NormalizeCardNumber(number)
+	for _, r := range brandRanges {
+		length := len(strconv.Itoa(r.from))
+		if len(number) < length {
+			continue
+		}
+		prefix, err := strconv.Atoi(number[:length])
+		if err != nil {
+			return BrandUnknown
+		}
+		if prefix >= r.from && prefix <= r.to {
+			return r.brand
+		}
+	}
+	return BrandUnknown
+}
+
+// LuhnValid проверяет контрольную цифру номера по алгоритму Луна
+func LuhnValid(number string) bool {
+	if number == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ValidateCardNumber проверяет номер карты: только цифры, длина от 12 до 19 цифр, корректная контрольная сумма
+func ValidateCardNumber(number string) error {
+	number = NormalizeCardNumber(number)
+	if !onlyDigits(number) {
+		return ErrCardNumberDigits
+	}
+	if len(number) < 12 || len(number) > 19 {
+		return ErrCardNumberLength
+	}
+	if !LuhnValid(number) {
+		return ErrCardNumberLuhn
+	}
+	return nil
+}
+
+// ValidateCVC проверяет код CVC/CID: 3 или 4 цифры
+func ValidateCVC(cvc string) error {
+	if !onlyDigits(cvc) || len(cvc) < 3 || len(cvc) > 4 {
+		return ErrCVC
+	}
+	return nil
+}
+
+// MaskCardNumber скрывает все цифры номера карты, кроме последних четырёх
+func MaskCardNumber(number string) string {
+	number = NormalizeCardNumber(number)
+	if len(number) <= 4 {
+		return strings.Repeat("*", len(number))
+	}
+	return "**** " + number[len(number)-4:]
+}
+
+func onlyDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardBrand(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"4111111111111111", BrandVisa},
+		{"5500 0000 0000 0004", BrandMastercard},
+		{"2221000000000009", BrandMastercard},
+		{"340000000000009", BrandAmex},
+		{"378282246310005", BrandAmex},
+		{"2200000000000004", BrandMIR},
+		{"6011000000000004", BrandDiscover},
+		{"3530111333300000", BrandJCB},
+		{"6200000000000005", BrandUnionPay},
+		{"30569309025904", BrandDiners},
+		{"6759649826438453", BrandMaestro},
+		{"9999999999999995", BrandUnknown},
+		{"", BrandUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			assert.Equal(t, tt.want, CardBrand(tt.number))
+		})
+	}
+}
+
+func TestValidateCardNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		number  string
+		wantErr error
+	}{
+		{"visa", "4111111111111111", nil},
+		{"with spaces", "4111 1111 1111 1111", nil},
+		{"amex 15 digits", "378282246310005", nil},
+		{"12 digits", "500000000009", nil},
+		{"19 digits", "6759649826438453000", ErrCardNumberLuhn},
+		{"19 digits valid", "4111111111111111110", nil},
+		{"checksum", "4111111111111112", ErrCardNumberLuhn},
+		{"too short", "41111111111", ErrCardNumberLength},
+		{"too long", "41111111111111111111", ErrCardNumberLength},
+		{"letters", "4111a11111111111", ErrCardNumberDigits},
+		{"empty", "", ErrCardNumberDigits},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, ValidateCardNumber(tt.number), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateCVC(t *testing.T) {
+	assert.NoError(t, ValidateCVC("123"))
+	assert.NoError(t, ValidateCVC("1234"))
+	assert.ErrorIs(t, ValidateCVC("12"), ErrCVC)
+	assert.ErrorIs(t, ValidateCVC("12345"), ErrCVC)
+	assert.ErrorIs(t, ValidateCVC("12a"), ErrCVC)
+}
+
+func TestMaskCardNumber(t *testing.T) {
+	assert.Equal(t, "**** 0005", MaskCardNumber("3782 8224 6310 005"))
+	assert.Equal(t, "***", MaskCardNumber("123"))
+}
@@ -0,0 +1,148 @@
+package types
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// DeviceRequestTimeout сколько времени запрос на вход с нового устройства ждёт подтверждения
+const DeviceRequestTimeout = 10 * time.Minute
+
+// DevicePendingLimit сколько запросов на вход с новых устройств может одновременно ждать подтверждения
+const DevicePendingLimit = 3
+
+// DeviceEnrollmentTTL сколько действует код администратора для первого устройства после сброса
+const DeviceEnrollmentTTL = 24 * time.Hour
+
+// EnrollmentRequired ответ сервера на вход после сброса устройств без действующего кода администратора
+const EnrollmentRequired = "Device enrollment code required"
+
+// DeviceEnrollment результат сброса устройств: сколько забыто и код, с которым пользователь подтвердит
+// следующее устройство. Код показывается один раз, на сервере хранится только его хеш
+type DeviceEnrollment struct {
+	Affected  int64     `json:"affected"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DeviceChallengeTimeout сколько времени действует вызов, которым устройство доказывает владение ключом
+const DeviceChallengeTimeout = 5 * time.Minute
+
+// DeviceStatus состояние устройства пользователя
+type DeviceStatus string
+
+const (
+	DevicePending  DeviceStatus = "pending"
+	DeviceApproved DeviceStatus = "approved"
+	DeviceRejected DeviceStatus = "rejected"
+)
+
+// NewDevice устройство, с которого выполняется вход. PublicKey - открытый ключ X25519 устройства в base64,
+// PollHash - хеш секрета, которым новое устройство узнаёт, подтверждён ли вход, EnrollmentHash - хеш кода
+// администратора, выданного при сбросе устройств
+type NewDevice struct {
+	PublicKey      string
+	Name           string
+	RemoteAddr     string
+	PollHash       string
+	EnrollmentHash string
+}
+
+// DeviceChallenge вызов сервера перед входом: устройство доказывает, что у него есть закрытый ключ,
+// вычисляя DeviceProof от Nonce и одноразового открытого ключа сервера ServerKey (X25519, base64)
+type DeviceChallenge struct {
+	Nonce     string `json:"nonce"`
+	ServerKey string `json:"server_key"`
+}
+
+// DeviceProof доказательство владения ключом устройства: HMAC-SHA256 от nonce на общем секрете X25519.
+// Устройство считает его своим закрытым ключом и открытым ключом сервера, сервер - наоборот
+func DeviceProof(private []byte, peerPublic []byte, nonce string) (string, error) {
+	shared, err := curve25519.X25519(private, peerPublic)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, shared)
+	mac.Write([]byte(nonce))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// DeviceLogin результат входа с устройства. ID 0 - клиент не прислал ключ устройства
+type DeviceLogin struct {
+	ID     int
+	Status DeviceStatus
+}
+
+// DeviceRequest ответ новому устройству: вход ждёт подтверждения с устройства, где уже выполнен вход
+type DeviceRequest struct {
+	ID          int    `json:"id"`
+	Secret      string `json:"secret"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// DevicePoll запрос нового устройства о состоянии входа
+type DevicePoll struct {
+	Secret string `json:"secret"`
+}
+
+// DeviceApproval подтверждённый вход: ключ хранилища, зашифрованный ключом нового устройства, если его передали
+type DeviceApproval struct {
+	Escrow string `json:"escrow,omitempty"`
+}
+
+// DevicePollResult состояние запроса на вход для нового устройства
+type DevicePollResult struct {
+	UserID   int
+	Username string
+	Status   DeviceStatus
+	Escrow   string
+}
+
+// Device устройство пользователя: подтверждённое или ожидающее подтверждения
+type Device struct {
+	ID         int          `json:"id" db:"id"`
+	Name       string       `json:"name" db:"name"`
+	PublicKey  string       `json:"public_key" db:"public_key"`
+	Status     DeviceStatus `json:"status" db:"status"`
+	RemoteAddr string       `json:"remote_addr" db:"remote_addr"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	ApprovedAt *time.Time   `json:"approved_at,omitempty" db:"approved_at"`
+}
+
+// String строковое представление устройства
+func (d Device) String() string {
+	name := d.Name
+	if name == "" {
+		name = "unknown device"
+	}
+	str := fmt.Sprintf("#%d %s [%s], %s, %s", d.ID, name, DeviceFingerprint(d.PublicKey), d.RemoteAddr, d.Status)
+	if d.ApprovedAt != nil {
+		str += " " + d.ApprovedAt.Local().Format(time.RFC3339)
+	} else {
+		str += ", requested " + d.CreatedAt.Local().Format(time.RFC3339)
+	}
+	return str
+}
+
+// DeviceFingerprint отпечаток открытого ключа устройства для сверки человеком: первые 8 байт SHA-256
+// группами по 4 шестнадцатеричных символа
+func DeviceFingerprint(publicKey string) string {
+	raw, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		raw = []byte(publicKey)
+	}
+	sum := sha256.Sum256(raw)
+	encoded := strings.ToUpper(hex.EncodeToString(sum[:8]))
+	groups := make([]string, 0, len(encoded)/4)
+	for i := 0; i < len(encoded); i += 4 {
+		groups = append(groups, encoded[i:i+4])
+	}
+	return strings.Join(groups, "-")
+}
@@ -0,0 +1,19 @@
+package types
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceFingerprint(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	fingerprint := DeviceFingerprint(key)
+	assert.Regexp(t, `^[0-9A-F]{4}(-[0-9A-F]{4}){3}$`, fingerprint)
+	assert.Equal(t, fingerprint, DeviceFingerprint(key))
+
+	other := make([]byte, 32)
+	other[0] = 1
+	assert.NotEqual(t, fingerprint, DeviceFingerprint(base64.StdEncoding.EncodeToString(other)))
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// EmergencyStatus состояние экстренного доступа
+type EmergencyStatus string
+
+const (
+	// EmergencyInvited владелец назначил доверенное лицо, оно ещё не согласилось
+	EmergencyInvited EmergencyStatus = "invited"
+	// EmergencyAccepted доверенное лицо согласилось и может запросить доступ
+	EmergencyAccepted EmergencyStatus = "accepted"
+	// EmergencyRequested доступ запрошен, идёт период ожидания, владелец может отклонить запрос
+	EmergencyRequested EmergencyStatus = "requested"
+	// EmergencyGranted доступ выдан: владелец одобрил запрос или период ожидания истёк
+	EmergencyGranted EmergencyStatus = "granted"
+)
+
+// MaxEmergencyWaitDays наибольший период ожидания экстренного доступа
+const MaxEmergencyWaitDays = 90
+
+// NewEmergencyAccess запрос на назначение доверенного лица. Escrow - ключ хранилища владельца,
+// зашифрованный открытым ключом доверенного лица, сервер его прочитать не может
+type NewEmergencyAccess struct {
+	Grantee  string `json:"grantee"`
+	WaitDays int    `json:"wait_days"`
+	Escrow   string `json:"escrow"`
+}
+
+// EmergencyAccess экстренный доступ доверенного лица Grantee к хранилищу владельца Grantor.
+// ReleaseAt - когда доступ будет выдан, если владелец не отклонит запрос
+type EmergencyAccess struct {
+	ID          int             `json:"id" db:"id"`
+	Grantor     string          `json:"grantor" db:"grantor"`
+	Grantee     string          `json:"grantee" db:"grantee"`
+	WaitDays    int             `json:"wait_days" db:"wait_days"`
+	Status      EmergencyStatus `json:"status" db:"status"`
+	RequestedAt *time.Time      `json:"requested_at,omitempty" db:"requested_at"`
+	RejectedAt  *time.Time      `json:"rejected_at,omitempty" db:"rejected_at"`
+	ReleaseAt   *time.Time      `json:"release_at,omitempty" db:"release_at"`
+}
+
+// String строковое представление экстренного доступа
+func (e EmergencyAccess) String() string {
+	s := fmt.Sprintf("#%d %s -> %s: %s, wait %d days", e.ID, e.Grantor, e.Grantee, e.Status, e.WaitDays)
+	if e.Status == EmergencyRequested && e.ReleaseAt != nil {
+		s += fmt.Sprintf(", released at %s unless rejected", e.ReleaseAt.Format(time.RFC3339))
+	}
+	if e.Status == EmergencyAccepted && e.RejectedAt != nil {
+		s += fmt.Sprintf(", last request rejected at %s", e.RejectedAt.Format(time.RFC3339))
+	}
+	return s
+}
+
+// EmergencyKey выданный доверенному лицу ключ хранилища владельца, зашифрованный открытым ключом доверенного лица
+type EmergencyKey struct {
+	Escrow string `json:"escrow"`
+}
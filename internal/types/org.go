@@ -0,0 +1,110 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Role роль участника организации
+type Role string
+
+const (
+	// RoleOwner владелец: всё, что может администратор, плюс смена ролей
+	RoleOwner Role = "owner"
+	// RoleAdmin администратор: приглашает и исключает участников, изменяет записи
+	RoleAdmin Role = "admin"
+	// RoleMember участник: читает и изменяет записи
+	RoleMember Role = "member"
+	// RoleReadOnly участник только для чтения
+	RoleReadOnly Role = "readonly"
+)
+
+var roleRanks = map[Role]int{RoleReadOnly: 1, RoleMember: 2, RoleAdmin: 3, RoleOwner: 4}
+
+// Valid проверяет, что роль из числа поддерживаемых
+func (r Role) Valid() bool {
+	return roleRanks[r] > 0
+}
+
+// AtLeast проверяет, что роль даёт не меньше прав, чем min
+func (r Role) AtLeast(min Role) bool {
+	return roleRanks[r] >= roleRanks[min]
+}
+
+// Membership членство пользователя в организации. OrgKey - ключ организации, зашифрованный открытым ключом
+// участника; KeyVersion - текущая версия ключа организации, она растёт при каждой ротации.
+// Collections - коллекции, которыми ограничен доступ участника, пустой список - доступ ко всем
+type Membership struct {
+	OrgID       int      `json:"-" db:"org_id"`
+	Org         string   `json:"org" db:"org"`
+	Username    string   `json:"username" db:"username"`
+	Role        Role     `json:"role" db:"role"`
+	Accepted    bool     `json:"accepted" db:"accepted"`
+	OrgKey      string   `json:"org_key" db:"org_key"`
+	KeyVersion  int      `json:"key_version" db:"key_version"`
+	PublicKey   *string  `json:"public_key,omitempty" db:"public_key"`
+	Collections []string `json:"collections,omitempty" db:"collections"`
+}
+
+// Restricted участник видит только часть коллекций. Владельцам и администраторам доступны все коллекции
+func (m Membership) Restricted() bool {
+	return !m.Role.AtLeast(RoleAdmin) && len(m.Collections) > 0
+}
+
+// CanAccess проверяет, что участнику доступна коллекция collection
+func (m Membership) CanAccess(collection string) bool {
+	return !m.Restricted() || slices.Contains(m.Collections, collection)
+}
+
+// AllowedCollections коллекции, доступные участнику, nil - доступны все
+func (m Membership) AllowedCollections() []string {
+	if !m.Restricted() {
+		return nil
+	}
+	return m.Collections
+}
+
+// String строковое представление членства без ключей
+func (m Membership) String() string {
+	status := ""
+	if !m.Accepted {
+		status = ", invited"
+	}
+	if m.Restricted() {
+		status += ", collections: " + strings.Join(m.Collections, ", ")
+	}
+	return fmt.Sprintf("%s: %s (%s%s)", m.Org, m.Username, m.Role, status)
+}
+
+// MemberCollections коллекции, которыми ограничивается доступ участника организации
+type MemberCollections struct {
+	Collections []string `json:"collections"`
+}
+
+// OrgItem запись в хранилище организации. Data - запись, зашифрованная ключом организации версии KeyVersion
+type OrgItem struct {
+	Key        string     `json:"key" db:"key"`
+	Collection string     `json:"collection" db:"collection"`
+	Type       ItemType   `json:"type" db:"item_type"`
+	Data       string     `json:"data" db:"data"`
+	KeyVersion int        `json:"key_version" db:"key_version"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// String строковое представление записи организации без данных
+func (i OrgItem) String() string {
+	if i.Collection == "" {
+		return fmt.Sprintf("%s (%s)", i.Key, i.Type)
+	}
+	return fmt.Sprintf("%s/%s (%s)", i.Collection, i.Key, i.Type)
+}
+
+// KeyRotation новый ключ организации, зашифрованный для каждого оставшегося участника, и все записи,
+// перешифрованные этим ключом. KeyVersion - версия ключа, от которой выполнялась ротация
+type KeyRotation struct {
+	KeyVersion int               `json:"key_version"`
+	Keys       map[string]string `json:"keys"`
+	Items      []OrgItem         `json:"items"`
+}
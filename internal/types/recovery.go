@@ -0,0 +1,61 @@
+package types
+
+import "time"
+
+// RecoveryAttempts после стольких неверных ключей восстановления подряд восстановление блокируется
+const RecoveryAttempts = 5
+
+// RecoveryLockout на сколько блокируется восстановление после исчерпания попыток
+const RecoveryLockout = 15 * time.Minute
+
+// VaultKey ключ хранилища, зашифрованный на клиенте ключом, выведенным из пароля пользователя.
+// LegacyKey заполнен, пока записи перешифровываются со старого ключа: это старый ключ, зашифрованный новым
+type VaultKey struct {
+	Wrapped   string `json:"wrapped"`
+	LegacyKey string `json:"legacy_key,omitempty"`
+}
+
+// VaultKeyMigration замена старого ключа хранилища (пароля учётных записей, созданных до ключа хранилища)
+// случайным. Previous - ключ, который клиент видел на сервере, пустой, если ключа не было. Вместе с ключом
+// заменяется всё, что было им зашифровано вне записей: закрытый ключ пользователя и ключи у доверенных лиц
+type VaultKeyMigration struct {
+	Previous   string            `json:"previous"`
+	Wrapped    string            `json:"wrapped"`
+	LegacyKey  string            `json:"legacy_key"`
+	PrivateKey string            `json:"private_key,omitempty"`
+	Escrows    []EmergencyEscrow `json:"escrows,omitempty"`
+}
+
+// EmergencyEscrow новый ключ хранилища, зашифрованный открытым ключом доверенного лица экстренного доступа ID
+type EmergencyEscrow struct {
+	ID     int    `json:"id"`
+	Escrow string `json:"escrow"`
+}
+
+// LegacyVault записи и вложения, ещё зашифрованные старым ключом хранилища, и кредитные карты, срок действия
+// которых сохранён до появления его шифрования и лежит на сервере открытым текстом
+type LegacyVault struct {
+	Items       []string           `json:"items"`
+	Attachments []LegacyAttachment `json:"attachments"`
+	Cards       []string           `json:"cards"`
+}
+
+// LegacyAttachment вложение ID записи Key, ещё зашифрованное старым ключом хранилища
+type LegacyAttachment struct {
+	Key string `json:"key" db:"key"`
+	ID  int    `json:"id" db:"id"`
+}
+
+// RecoveryVerifier проверочное значение, выведенное из ключа хранилища. Сервер хранит только его хеш
+// и по нему убеждается, что восстанавливающий доступ знает ключ хранилища
+type RecoveryVerifier struct {
+	Verifier string `json:"verifier"`
+}
+
+// AccountRecovery запрос на восстановление доступа: новый пароль и ключ хранилища, зашифрованный новым паролем
+type AccountRecovery struct {
+	Login    string `json:"login"`
+	Verifier string `json:"verifier"`
+	Password string `json:"password"`
+	Wrapped  string `json:"wrapped"`
+}
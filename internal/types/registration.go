@@ -0,0 +1,57 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// RegistrationMode кто может зарегистрироваться на сервере
+type RegistrationMode string
+
+const (
+	// RegistrationOpen регистрироваться может любой
+	RegistrationOpen RegistrationMode = "open"
+	// RegistrationInvite только по приглашению администратора
+	RegistrationInvite RegistrationMode = "invite"
+	// RegistrationClosed регистрация закрыта, учётные записи не создаются
+	RegistrationClosed RegistrationMode = "closed"
+)
+
+// InviteRequired ответ сервера на регистрацию без приглашения, когда оно обязательно
+const InviteRequired = "Registration requires an invite"
+
+// DefaultInviteTTL сколько действует приглашение, если срок не задан
+const DefaultInviteTTL = 7 * 24 * time.Hour
+
+// NewInvite запрос администратора на приглашение: сколько раз им можно воспользоваться и сколько оно действует
+type NewInvite struct {
+	MaxUses   int    `json:"max_uses"`
+	ExpiresIn string `json:"expires_in,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+// CreatedInvite созданное приглашение. Код показывается один раз, на сервере хранится только его хеш
+type CreatedInvite struct {
+	ID        int       `json:"id"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Invite приглашение в списке администратора, без кода
+type Invite struct {
+	ID        int       `json:"id" db:"id"`
+	Note      string    `json:"note" db:"note"`
+	MaxUses   int       `json:"max_uses" db:"max_uses"`
+	Uses      int       `json:"uses" db:"uses"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// String строковое представление приглашения
+func (i Invite) String() string {
+	str := fmt.Sprintf("#%d used %d of %d, expires %s", i.ID, i.Uses, i.MaxUses, i.ExpiresAt.Local().Format(time.RFC3339))
+	if i.Note != "" {
+		str += ": " + i.Note
+	}
+	return str
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewSend запрос на создание одноразовой ссылки. Data зашифрована на клиенте ключом, который передаётся
+// только во фрагменте ссылки и не попадает на сервер. Password - необязательный пароль для открытия ссылки
+type NewSend struct {
+	Data      string    `json:"data"`
+	MaxViews  int       `json:"max_views"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Password  string    `json:"password,omitempty"`
+}
+
+// Send одноразовая ссылка без содержимого
+type Send struct {
+	ID          string    `json:"id" db:"slug"`
+	MaxViews    int       `json:"max_views" db:"max_views"`
+	Views       int       `json:"views" db:"views"`
+	ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
+	HasPassword bool      `json:"has_password" db:"has_password"`
+}
+
+// String строковое представление одноразовой ссылки
+func (s Send) String() string {
+	return fmt.Sprintf("%s: viewed %d of %d, expires at %s", s.ID, s.Views, s.MaxViews, s.ExpiresAt.Format(time.RFC3339))
+}
+
+// SendContent зашифрованное содержимое одноразовой ссылки и число оставшихся просмотров
+type SendContent struct {
+	Data      string `json:"data"`
+	ViewsLeft int    `json:"views_left"`
+}
+
+// SendPasswordAttempts после стольких неверных паролей подряд одноразовая ссылка удаляется
+const SendPasswordAttempts = 5
+
+// SendAccess запрос на открытие одноразовой ссылки
+type SendAccess struct {
+	Password string `json:"password,omitempty"`
+}
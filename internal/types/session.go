@@ -0,0 +1,46 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// SessionIdleTimeout через сколько времени без запросов сессия истекает
+const SessionIdleTimeout = 7 * 24 * time.Hour
+
+// NewSession устройство, с которого выполнен вход. DeviceID 0 - устройство без ключа
+type NewSession struct {
+	DeviceID      int
+	Device        string
+	ClientVersion string
+	RemoteAddr    string
+}
+
+// Session сессия пользователя на одном устройстве. Current - сессия, из которой сделан запрос
+type Session struct {
+	ID            int       `json:"id" db:"id"`
+	DeviceID      *int      `json:"device_id,omitempty" db:"device_id"`
+	Device        string    `json:"device" db:"device"`
+	ClientVersion string    `json:"client_version" db:"client_version"`
+	RemoteAddr    string    `json:"remote_addr" db:"remote_addr"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	LastSeen      time.Time `json:"last_seen" db:"last_seen"`
+	Current       bool      `json:"current" db:"-"`
+}
+
+// String строковое представление сессии
+func (s Session) String() string {
+	device := s.Device
+	if device == "" {
+		device = "unknown device"
+	}
+	str := fmt.Sprintf("#%d %s", s.ID, device)
+	if s.ClientVersion != "" {
+		str += fmt.Sprintf(" (client %s)", s.ClientVersion)
+	}
+	str += fmt.Sprintf(", %s, last seen %s", s.RemoteAddr, s.LastSeen.Local().Format(time.RFC3339))
+	if s.Current {
+		str += ", this device"
+	}
+	return str
+}
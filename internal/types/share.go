@@ -0,0 +1,48 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// Permission права получателя на общую запись
+type Permission string
+
+const (
+	// PermissionRead получатель может только читать запись
+	PermissionRead Permission = "read"
+	// PermissionWrite получатель может изменять запись
+	PermissionWrite Permission = "write"
+)
+
+// Valid проверяет, что права из числа поддерживаемых
+func (p Permission) Valid() bool {
+	return p == PermissionRead || p == PermissionWrite
+}
+
+// UserKeys ключевая пара X25519 пользователя в base64. Приватный ключ зашифрован на клиенте ключом хранилища,
+// сервер его не расшифровывает
+type UserKeys struct {
+	PublicKey  string `json:"public_key" db:"public_key"`
+	PrivateKey string `json:"private_key,omitempty" db:"private_key"`
+}
+
+// Share запись, которой владелец поделился с получателем. Данные записи зашифрованы ключом данных,
+// ключ данных зашифрован открытыми ключами получателя (RecipientKey) и владельца (OwnerKey)
+type Share struct {
+	ID           int        `json:"id" db:"id"`
+	Owner        string     `json:"owner" db:"owner"`
+	Recipient    string     `json:"recipient" db:"recipient"`
+	Key          string     `json:"key" db:"key"`
+	Type         ItemType   `json:"type" db:"item_type"`
+	Permission   Permission `json:"permission" db:"permission"`
+	RecipientKey string     `json:"recipient_key" db:"recipient_key"`
+	OwnerKey     string     `json:"owner_key" db:"owner_key"`
+	Data         string     `json:"data" db:"data"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// String строковое представление общей записи без данных
+func (s Share) String() string {
+	return fmt.Sprintf("#%d %s (%s) from %s to %s, %s", s.ID, s.Key, s.Type, s.Owner, s.Recipient, s.Permission)
+}
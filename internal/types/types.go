@@ -2,11 +2,20 @@
 package types
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/wellywell/gophkeeper/internal/encrypt"
+	"github.com/wellywell/gophkeeper/internal/totp"
 )
 
 // ItemType определяет возможные типы данных для хранения на сервере
@@ -17,26 +26,138 @@ const (
 	TypeText       ItemType = "text"
 	TypeBinary     ItemType = "binary"
 	TypeLogoPass   ItemType = "logopass"
+	TypeSSHKey     ItemType = "ssh_key"
+	TypeTOTP       ItemType = "totp"
 )
 
-// Item - структура для хранения метаданных о любом объекте, хранимом на сервере
+// Item - структура для хранения метаданных о любом объекте, хранимом на сервере.
+// Срок действия (ExpiresAt, в формате ДатаФормат) и период ротации в днях (RotateDays) необязательны и шифруются на клиенте
 type Item struct {
-	Id   int      `db:"id"`
-	Key  string   `json:"key" db:"key"`
-	Info string   `json:"info" db:"info"`
-	Type ItemType `json:"type" db:"item_type"`
+	Id         int        `db:"id"`
+	Key        string     `json:"key" db:"key"`
+	Info       string     `json:"info" db:"info"`
+	Type       ItemType   `json:"type" db:"item_type"`
+	ExpiresAt  string     `json:"expires_at,omitempty" db:"expires_at"`
+	RotateDays string     `json:"rotate_days,omitempty" db:"rotate_days"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	// SecretChangedAt время последней смены самих данных записи: правка описания, сроков и вложений его не меняет
+	SecretChangedAt *time.Time `json:"secret_changed_at,omitempty" db:"secret_changed_at"`
+}
+
+// SecretChanged время последней смены данных записи. Старые серверы его не присылают, тогда - время изменения
+func (i Item) SecretChanged() *time.Time {
+	if i.SecretChangedAt != nil {
+		return i.SecretChangedAt
+	}
+	return i.UpdatedAt
+}
+
+// DateFormat формат даты для срока действия записи
+const DateFormat = "2006-01-02"
+
+// Encrypt зашифровывает срок действия и период ротации перед отправкой на сервер
+func (i *Item) Encrypt(key string) error {
+	expiresAt, err := encrypt.Encrypt(i.ExpiresAt, key)
+	if err != nil {
+		return err
+	}
+	rotateDays, err := encrypt.Encrypt(i.RotateDays, key)
+	if err != nil {
+		return err
+	}
+	i.ExpiresAt = expiresAt
+	i.RotateDays = rotateDays
+	return nil
+}
+
+// Decrypt расшифровывает срок действия и период ротации
+func (i *Item) Decrypt(key string) error {
+	expiresAt, err := encrypt.Decrypt(i.ExpiresAt, key)
+	if err != nil {
+		return err
+	}
+	rotateDays, err := encrypt.Decrypt(i.RotateDays, key)
+	if err != nil {
+		return err
+	}
+	i.ExpiresAt = expiresAt
+	i.RotateDays = rotateDays
+	return nil
 }
 
 // String метод для возвращения строкового представления Item
 func (i Item) String() string {
-	return fmt.Sprintf("\nKey: %s\nInfo: %s\nType: %s\n", i.Key, i.Info, i.Type)
+	result := fmt.Sprintf("\nKey: %s\nInfo: %s\nType: %s\n", i.Key, i.Info, i.Type)
+	if i.ExpiresAt != "" {
+		result += fmt.Sprintf("Expires at: %s\n", i.ExpiresAt)
+	}
+	if i.RotateDays != "" {
+		result += fmt.Sprintf("Rotate every: %s days\n", i.RotateDays)
+	}
+	return result
+}
+
+// Attachment метаданные вложения, прикреплённого к записи. Содержимое, имя и MIME-тип вложения шифруются на клиенте,
+// вложения прикреплённые до шифрования метаданных хранят имя и тип открыто (Encrypted = false)
+type Attachment struct {
+	ID        int    `json:"id" db:"id"`
+	Name      string `json:"name" db:"name"`
+	MimeType  string `json:"mime_type" db:"mime_type"`
+	Size      int    `json:"size" db:"size"`
+	Encrypted bool   `json:"encrypted" db:"encrypted"`
+}
+
+// CreatedAttachment ответ сервера на прикрепление вложения
+type CreatedAttachment struct {
+	ID int `json:"id"`
+}
+
+// Encrypt зашифровывает имя и MIME-тип вложения перед отправкой на сервер
+func (a *Attachment) Encrypt(key string) error {
+	name, err := encrypt.Encrypt(a.Name, key)
+	if err != nil {
+		return err
+	}
+	mimeType, err := encrypt.Encrypt(a.MimeType, key)
+	if err != nil {
+		return err
+	}
+	a.Name = name
+	a.MimeType = mimeType
+	a.Encrypted = true
+	return nil
 }
 
-// CreditCardData тип для хранения данных крединтных карт
+// Decrypt расшифровывает имя и MIME-тип вложения
+func (a *Attachment) Decrypt(key string) error {
+	if !a.Encrypted {
+		return nil
+	}
+	name, err := encrypt.Decrypt(a.Name, key)
+	if err != nil {
+		return err
+	}
+	mimeType, err := encrypt.Decrypt(a.MimeType, key)
+	if err != nil {
+		return err
+	}
+	a.Name = name
+	a.MimeType = mimeType
+	a.Encrypted = false
+	return nil
+}
+
+// String метод для возвращения строкового представления Attachment
+func (a Attachment) String() string {
+	return fmt.Sprintf("%s (%s, %d bytes)", a.Name, a.MimeType, a.Size)
+}
+
+// CreditCardData тип для хранения данных крединтных карт. Срок действия шифруется вместе с остальными полями,
+// ValidDate заполняется только для записей, сохранённых до появления шифрования срока действия
 type CreditCardData struct {
 	Number     string    `json:"number" db:"number"`
-	ValidMonth string    `json:"valid_month" db:"-"`
-	ValidYear  string    `json:"valid_year" db:"-"`
+	ValidMonth string    `json:"valid_month" db:"valid_month"`
+	ValidYear  string    `json:"valid_year" db:"valid_year"`
 	Name       string    `json:"name" db:"owner_name"`
 	CVC        string    `json:"cvc" db:"cvc"`
 	ValidDate  time.Time `db:"valid_till"`
@@ -59,9 +180,22 @@ func (c *CreditCardData) Encrypt(key string) error {
 		return err
 	}
 
+	month, err := encrypt.Encrypt(c.ValidMonth, key)
+	if err != nil {
+		return err
+	}
+
+	year, err := encrypt.Encrypt(c.ValidYear, key)
+	if err != nil {
+		return err
+	}
+
 	c.Number = num
 	c.Name = name
 	c.CVC = cvc
+	c.ValidMonth = month
+	c.ValidYear = year
+	c.ValidDate = time.Time{}
 
 	return nil
 }
@@ -83,22 +217,50 @@ func (c *CreditCardData) Decrypt(key string) error {
 		return err
 	}
 
+	month, err := encrypt.Decrypt(c.ValidMonth, key)
+	if err != nil {
+		return err
+	}
+
+	year, err := encrypt.Decrypt(c.ValidYear, key)
+	if err != nil {
+		return err
+	}
+
 	c.Number = num
 	c.Name = name
 	c.CVC = cvc
+	c.ValidMonth = month
+	c.ValidYear = year
+
+	if c.ValidMonth == "" && !c.ValidDate.IsZero() {
+		c.ValidMonth = strconv.Itoa(int(c.ValidDate.Month()))
+		c.ValidYear = strconv.Itoa(c.ValidDate.Year())
+	}
 
 	return nil
 }
 
-// String строковое представлени данных о кредитной карте
+// Brand платёжная система карты
+func (c *CreditCardData) Brand() string {
+	return CardBrand(c.Number)
+}
+
+// String строковое представлени данных о кредитной карте. Номер карты и CVC маскируются
 func (c *CreditCardData) String() string {
-	return fmt.Sprintf("\nNumber: %s\nValid: %s/%s\nName: %s\nCVC: %s\n", c.Number, c.ValidMonth, c.ValidYear, c.Name, c.CVC)
+	return fmt.Sprintf("\nBrand: %s\nNumber: %s\nValid: %s/%s\nName: %s\nCVC: %s\n", c.Brand(), MaskCardNumber(c.Number), c.ValidMonth, c.ValidYear, c.Name, strings.Repeat("*", len(c.CVC)))
+}
+
+// Reveal строковое представление данных о кредитной карте без маскирования
+func (c *CreditCardData) Reveal() string {
+	return fmt.Sprintf("\nBrand: %s\nNumber: %s\nValid: %s/%s\nName: %s\nCVC: %s\n", c.Brand(), c.Number, c.ValidMonth, c.ValidYear, c.Name, c.CVC)
 }
 
-// LoginPassword структура для хранения пароля и логина
+// LoginPassword структура для хранения пароля и логина, а также (опционально) TOTP-секрета для второго фактора
 type LoginPassword struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
+	TOTP     string `json:"totp,omitempty" db:"totp"`
 }
 
 // Encrypt зашифровывает пароль и логин перед передачей на сервер
@@ -110,11 +272,16 @@ func (l *LoginPassword) Encrypt(key string) error {
 	}
 	psswd, err := encrypt.Encrypt(l.Password, key)
 
+	if err != nil {
+		return err
+	}
+	totp, err := encrypt.Encrypt(l.TOTP, key)
 	if err != nil {
 		return err
 	}
 	l.Login = lg
 	l.Password = psswd
+	l.TOTP = totp
 
 	return nil
 }
@@ -128,20 +295,62 @@ func (l *LoginPassword) Decrypt(key string) error {
 	}
 	psswd, err := encrypt.Decrypt(l.Password, key)
 
+	if err != nil {
+		return err
+	}
+	totp, err := encrypt.Decrypt(l.TOTP, key)
 	if err != nil {
 		return err
 	}
 	l.Login = lg
 	l.Password = psswd
+	l.TOTP = totp
 
 	return nil
 }
 
 // String строкове представление логина и пароля
 func (l *LoginPassword) String() string {
+	if l.TOTP != "" {
+		return fmt.Sprintf("\nLogin: %s\nPassword: %s\nTOTP: %s\n", l.Login, l.Password, l.TOTP)
+	}
 	return fmt.Sprintf("\nLogin: %s\nPassword: %s\n", l.Login, l.Password)
 }
 
+// TOTPData тип для хранения секрета для генерации одноразовых кодов (otpauth:// URI, либо секрет в base32)
+type TOTPData struct {
+	Secret string `json:"secret" db:"secret"`
+}
+
+// Encrypt зашифровывает TOTP-секрет перед отправкой на сервер
+func (t *TOTPData) Encrypt(key string) error {
+	secret, err := encrypt.Encrypt(t.Secret, key)
+	if err != nil {
+		return err
+	}
+	t.Secret = secret
+	return nil
+}
+
+// Decrypt расшифровывает TOTP-секрет для клиента
+func (t *TOTPData) Decrypt(key string) error {
+	secret, err := encrypt.Decrypt(t.Secret, key)
+	if err != nil {
+		return err
+	}
+	t.Secret = secret
+	return nil
+}
+
+// String строковое представление TOTP-секрета. Сам секрет не показывается
+func (t *TOTPData) String() string {
+	key, err := totp.Parse(t.Secret)
+	if err != nil {
+		return "\nInvalid TOTP secret\n"
+	}
+	return fmt.Sprintf("\nIssuer: %s\nAccount: %s\n", key.Issuer, key.Account)
+}
+
 // TextData тип для хранения простых текстовых данных
 type TextData string
 
@@ -206,6 +415,144 @@ func (b *BinaryData) String() string {
 	return "Binary data"
 }
 
+// ErrChecksumMismatch ошибка проверки целостности расшифрованных бинарных данных
+var ErrChecksumMismatch = errors.New("binary data checksum mismatch")
+
+// BinaryMeta метаданные бинарных данных: имя исходного файла, MIME-тип, размер и SHA-256 исходных данных.
+// Имя файла и контрольная сумма шифруются, тип и размер хранятся открыто
+type BinaryMeta struct {
+	FileName    string `json:"file_name" db:"file_name"`
+	ContentType string `json:"content_type" db:"content_type"`
+	Size        int64  `json:"size" db:"size"`
+	SHA256      string `json:"sha256" db:"sha256"`
+}
+
+// NewBinaryMeta вычисляет метаданные для содержимого файла filename
+func NewBinaryMeta(filename string, data []byte) *BinaryMeta {
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	sum := sha256.Sum256(data)
+	return &BinaryMeta{
+		FileName:    filepath.Base(filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+}
+
+// Verify проверяет, что расшифрованные данные совпадают с исходными по размеру и контрольной сумме
+func (m *BinaryMeta) Verify(data []byte) error {
+	sum := sha256.Sum256(data)
+	if int64(len(data)) != m.Size || hex.EncodeToString(sum[:]) != m.SHA256 {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// Encrypt зашифровывает имя файла и контрольную сумму перед отправкой на сервер
+func (m *BinaryMeta) Encrypt(key string) error {
+	fileName, err := encrypt.Encrypt(m.FileName, key)
+	if err != nil {
+		return err
+	}
+	sum, err := encrypt.Encrypt(m.SHA256, key)
+	if err != nil {
+		return err
+	}
+	m.FileName = fileName
+	m.SHA256 = sum
+	return nil
+}
+
+// Decrypt расшифровывает имя файла и контрольную сумму
+func (m *BinaryMeta) Decrypt(key string) error {
+	fileName, err := encrypt.Decrypt(m.FileName, key)
+	if err != nil {
+		return err
+	}
+	sum, err := encrypt.Decrypt(m.SHA256, key)
+	if err != nil {
+		return err
+	}
+	m.FileName = fileName
+	m.SHA256 = sum
+	return nil
+}
+
+// String строковое представление, показываемое пользователю
+func (m *BinaryMeta) String() string {
+	return fmt.Sprintf("\nFile name: %s\nContent type: %s\nSize: %d bytes\nSHA-256: %s\n", m.FileName, m.ContentType, m.Size, m.SHA256)
+}
+
+// SSHKeyData тип для хранения SSH-ключа
+type SSHKeyData struct {
+	PrivateKey string `json:"private_key" db:"private_key"`
+	PublicKey  string `json:"public_key" db:"public_key"`
+	Passphrase string `json:"passphrase" db:"passphrase"`
+	Comment    string `json:"comment" db:"comment"`
+}
+
+// Encrypt зашифровывает SSH-ключ перед отправкой на сервер
+func (s *SSHKeyData) Encrypt(key string) error {
+	private, err := encrypt.Encrypt(s.PrivateKey, key)
+	if err != nil {
+		return err
+	}
+	public, err := encrypt.Encrypt(s.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	passphrase, err := encrypt.Encrypt(s.Passphrase, key)
+	if err != nil {
+		return err
+	}
+	comment, err := encrypt.Encrypt(s.Comment, key)
+	if err != nil {
+		return err
+	}
+
+	s.PrivateKey = private
+	s.PublicKey = public
+	s.Passphrase = passphrase
+	s.Comment = comment
+
+	return nil
+}
+
+// Decrypt расшифровывает SSH-ключ для клиента
+func (s *SSHKeyData) Decrypt(key string) error {
+	private, err := encrypt.Decrypt(s.PrivateKey, key)
+	if err != nil {
+		return err
+	}
+	public, err := encrypt.Decrypt(s.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	passphrase, err := encrypt.Decrypt(s.Passphrase, key)
+	if err != nil {
+		return err
+	}
+	comment, err := encrypt.Decrypt(s.Comment, key)
+	if err != nil {
+		return err
+	}
+
+	s.PrivateKey = private
+	s.PublicKey = public
+	s.Passphrase = passphrase
+	s.Comment = comment
+
+	return nil
+}
+
+// String строковое представление SSH-ключа. Приватный ключ не показывается
+func (s *SSHKeyData) String() string {
+	return fmt.Sprintf("\nPublic key: %s\nComment: %s\n", strings.TrimSpace(s.PublicKey), s.Comment)
+}
+
 // CreditCardItem структура для хранения данных о кредитной карте вместе с метаданными
 type CreditCardItem struct {
 	Item Item            `json:"item"`
@@ -226,8 +573,21 @@ type TextItem struct {
 
 // BinaryItem тип для хранения бинарных данных и метаданных
 type BinaryItem struct {
-	Item Item   `json:"item"`
-	Data []byte `json:"data"`
+	Item Item        `json:"item"`
+	Data []byte      `json:"data"`
+	Meta *BinaryMeta `json:"meta,omitempty"`
+}
+
+// SSHKeyItem тип для хранения SSH-ключа и метаданных
+type SSHKeyItem struct {
+	Item Item        `json:"item"`
+	Data *SSHKeyData `json:"data"`
+}
+
+// TOTPItem тип для хранения TOTP-секрета и метаданных
+type TOTPItem struct {
+	Item Item      `json:"item"`
+	Data *TOTPData `json:"data"`
 }
 
 // AnyItem тип для передачи любого типа данных (из поддерживаемых), без уточнения конкретного типа
@@ -238,7 +598,7 @@ type AnyItem struct {
 
 // ItemData интерфейс, определяющий ограничения для обобщенного типа GenericItem
 type ItemData interface {
-	*LoginPassword | *CreditCardData | *TextData | *BinaryData
+	*LoginPassword | *CreditCardData | *TextData | *BinaryData | *SSHKeyData | *TOTPData
 	String() string
 	Encrypt(string) error
 	Decrypt(string) error
@@ -246,8 +606,9 @@ type ItemData interface {
 
 // GenericItem обобщенный тип для хранения данных и метаданных
 type GenericItem[T ItemData] struct {
-	Item Item `json:"item"`
-	Data T    `json:"data"`
+	Item Item        `json:"item"`
+	Data T           `json:"data"`
+	Meta *BinaryMeta `json:"meta,omitempty"`
 }
 
 // ParseItem преобразует массив байтов в типизированный GenericItem и расшифровывает зашифрованные данные
@@ -262,5 +623,15 @@ func ParseItem[T ItemData](data []byte, decriptKey string) (*GenericItem[T], err
 	if err != nil {
 		return nil, err
 	}
+	err = item.Item.Decrypt(decriptKey)
+	if err != nil {
+		return nil, err
+	}
+	if item.Meta != nil {
+		err = item.Meta.Decrypt(decriptKey)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return item, nil
 }
@@ -4,6 +4,7 @@ package types
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -137,7 +138,11 @@ func TestLoginPassword_Encrypt_Decrypt(t *testing.T) {
 }
 
 func TestCreditCardData_String(t *testing.T) {
-	assert.Equal(t, "\nNumber: 1\nValid: 1/2000\nName: 1\nCVC: 1\n", creditCardItem.Data.String())
+	assert.Equal(t, "\nBrand: Unknown\nNumber: *\nValid: 1/2000\nName: 1\nCVC: *\n", creditCardItem.Data.String())
+
+	card := CreditCardData{Number: "4111111111111111", ValidMonth: "12", ValidYear: "2030", Name: "Ivan", CVC: "123"}
+	assert.Equal(t, "\nBrand: Visa\nNumber: **** 1111\nValid: 12/2030\nName: Ivan\nCVC: ***\n", card.String())
+	assert.Equal(t, "\nBrand: Visa\nNumber: 4111111111111111\nValid: 12/2030\nName: Ivan\nCVC: 123\n", card.Reveal())
 }
 
 func TestCreditCardData_Decrypt_Encrypt(t *testing.T) {
@@ -145,20 +150,163 @@ func TestCreditCardData_Decrypt_Encrypt(t *testing.T) {
 	copyItem.Item = creditCardItem.Item
 	*copyItem.Data = *creditCardItem.Data
 
-	expectEncrypted := "\nNumber: Pw==\nValid: 1/2000\nName: Pw==\nCVC: Pw==\n"
+	expectEncrypted := "\nBrand: Unknown\nNumber: Pw==\nValid: Pw==/PKYkog==\nName: Pw==\nCVC: Pw==\n"
 
 	err := copyItem.Data.Encrypt("secret")
 	assert.NoError(t, err)
-	assert.Equal(t, expectEncrypted, copyItem.Data.String())
-	assert.NotEqual(t, creditCardItem.Data.String(), copyItem.Data.String())
+	assert.Equal(t, expectEncrypted, copyItem.Data.Reveal())
+	assert.NotEqual(t, creditCardItem.Data.Reveal(), copyItem.Data.Reveal())
 
 	err = copyItem.Data.Decrypt("secret")
 	assert.NoError(t, err)
-	assert.NotEqual(t, expectEncrypted, copyItem.Data.String())
-	assert.Equal(t, creditCardItem.Data.String(), copyItem.Data.String())
+	assert.NotEqual(t, expectEncrypted, copyItem.Data.Reveal())
+	assert.Equal(t, creditCardItem.Data.Reveal(), copyItem.Data.Reveal())
 
 }
 
+func TestCreditCardData_Decrypt_LegacyExpiry(t *testing.T) {
+	card := CreditCardData{Number: "4111111111111111", Name: "Ivan", CVC: "123"}
+	err := card.Encrypt("secret")
+	assert.NoError(t, err)
+
+	card.ValidDate = time.Date(2001, 2, 1, 0, 0, 0, 0, time.UTC)
+	err = card.Decrypt("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", card.ValidMonth)
+	assert.Equal(t, "2001", card.ValidYear)
+}
+
 func TestItem_String(t *testing.T) {
 	assert.Equal(t, "text\n", textItem.Data.String())
 }
+
+func TestSSHKeyData_Encrypt_Decrypt(t *testing.T) {
+	key := SSHKeyData{PrivateKey: "private", PublicKey: "ssh-ed25519 AAAA", Passphrase: "pass", Comment: "me@host"}
+	copyKey := key
+
+	err := copyKey.Encrypt("secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, copyKey)
+
+	err = copyKey.Decrypt("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, key, copyKey)
+}
+
+func TestSSHKeyData_String(t *testing.T) {
+	key := SSHKeyData{PrivateKey: "private", PublicKey: "ssh-ed25519 AAAA\n", Passphrase: "pass", Comment: "me@host"}
+	assert.Equal(t, "\nPublic key: ssh-ed25519 AAAA\nComment: me@host\n", key.String())
+}
+
+func TestLoginPassword_TOTP(t *testing.T) {
+	data := LoginPassword{Login: "112", Password: "222", TOTP: "JBSWY3DPEHPK3PXP"}
+	copyData := data
+
+	err := copyData.Encrypt("secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, data.TOTP, copyData.TOTP)
+
+	err = copyData.Decrypt("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, data, copyData)
+	assert.Equal(t, "\nLogin: 112\nPassword: 222\nTOTP: JBSWY3DPEHPK3PXP\n", copyData.String())
+}
+
+func TestTOTPData_Encrypt_Decrypt(t *testing.T) {
+	data := TOTPData{Secret: "otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&issuer=Example"}
+	copyData := data
+
+	err := copyData.Encrypt("secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, data, copyData)
+
+	err = copyData.Decrypt("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, data, copyData)
+}
+
+func TestTOTPData_String(t *testing.T) {
+	data := TOTPData{Secret: "otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&issuer=Example"}
+	assert.Equal(t, "\nIssuer: Example\nAccount: alice\n", data.String())
+
+	data = TOTPData{Secret: "!!!"}
+	assert.Equal(t, "\nInvalid TOTP secret\n", data.String())
+}
+
+func TestBinaryMeta(t *testing.T) {
+	data := []byte("some text")
+	meta := NewBinaryMeta("/tmp/doc.txt", data)
+
+	assert.Equal(t, "doc.txt", meta.FileName)
+	assert.Equal(t, "text/plain; charset=utf-8", meta.ContentType)
+	assert.Equal(t, int64(9), meta.Size)
+	assert.Equal(t, "b94f6f125c79e3a5ffaa826f584c10d52ada669e6762051b826b55776d05aed2", meta.SHA256)
+
+	assert.NoError(t, meta.Verify(data))
+	assert.ErrorIs(t, meta.Verify([]byte("other text")), ErrChecksumMismatch)
+
+	copyMeta := *meta
+	err := copyMeta.Encrypt("secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, meta.FileName, copyMeta.FileName)
+	assert.NotEqual(t, meta.SHA256, copyMeta.SHA256)
+	assert.Equal(t, meta.Size, copyMeta.Size)
+
+	err = copyMeta.Decrypt("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, *meta, copyMeta)
+}
+
+func TestMembership_CanAccess(t *testing.T) {
+	member := Membership{Role: RoleMember, Collections: []string{"ops"}}
+	assert.True(t, member.Restricted())
+	assert.True(t, member.CanAccess("ops"))
+	assert.False(t, member.CanAccess(""))
+	assert.Equal(t, []string{"ops"}, member.AllowedCollections())
+
+	// администратору ограничение не мешает
+	admin := Membership{Role: RoleAdmin, Collections: []string{"ops"}}
+	assert.False(t, admin.Restricted())
+	assert.True(t, admin.CanAccess("hr"))
+	assert.Nil(t, admin.AllowedCollections())
+
+	assert.True(t, Membership{Role: RoleReadOnly}.CanAccess("hr"))
+}
+
+func TestAttachment_Encrypt_Decrypt(t *testing.T) {
+	attachment := Attachment{ID: 5, Name: "doc.pdf", MimeType: "application/pdf", Size: 4}
+	copyAttachment := attachment
+
+	err := copyAttachment.Encrypt("secret")
+	assert.NoError(t, err)
+	assert.True(t, copyAttachment.Encrypted)
+	assert.NotEqual(t, attachment.Name, copyAttachment.Name)
+	assert.NotEqual(t, attachment.MimeType, copyAttachment.MimeType)
+
+	err = copyAttachment.Decrypt("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, attachment, copyAttachment)
+
+	// вложения без шифрования метаданных остаются как есть
+	legacy := Attachment{ID: 6, Name: "doc.pdf", MimeType: "application/pdf"}
+	assert.NoError(t, legacy.Decrypt("secret"))
+	assert.Equal(t, "doc.pdf", legacy.Name)
+}
+
+func TestItem_Encrypt_Decrypt(t *testing.T) {
+	item := Item{Key: "1", Info: "info", Type: TypeLogoPass, ExpiresAt: "2030-01-01", RotateDays: "90"}
+	copyItem := item
+
+	err := copyItem.Encrypt("secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, item.ExpiresAt, copyItem.ExpiresAt)
+	assert.NotEqual(t, item.RotateDays, copyItem.RotateDays)
+	assert.Equal(t, item.Info, copyItem.Info)
+
+	err = copyItem.Decrypt("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, item, copyItem)
+
+	assert.Equal(t, "\nKey: 1\nInfo: info\nType: logopass\nExpires at: 2030-01-01\nRotate every: 90 days\n", item.String())
+	assert.Equal(t, "\nKey: 1\nInfo: info\nType: logopass\n", Item{Key: "1", Info: "info", Type: TypeLogoPass}.String())
+}